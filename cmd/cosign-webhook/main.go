@@ -0,0 +1,183 @@
+//
+// Copyright 2023 The Sigstore Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/spf13/cobra"
+	"k8s.io/client-go/dynamic"
+
+	"github.com/sigstore/cosign/v2/cmd/cosign/cli/options"
+	"github.com/sigstore/cosign/v2/cmd/cosign/cli/webhookcli"
+	"github.com/sigstore/cosign/v2/internal/tracing"
+	"github.com/sigstore/cosign/v2/pkg/cosign/kubernetes"
+	"github.com/sigstore/cosign/v2/pkg/webhook"
+
+	// Register the provider-specific plugins
+	_ "github.com/sigstore/sigstore/pkg/signature/kms/aws"
+	_ "github.com/sigstore/sigstore/pkg/signature/kms/azure"
+	_ "github.com/sigstore/sigstore/pkg/signature/kms/gcp"
+	_ "github.com/sigstore/sigstore/pkg/signature/kms/hashivault"
+)
+
+type webhookOptions struct {
+	Port          int
+	TLSCertFile   string
+	TLSKeyFile    string
+	NoMatchPolicy string
+	IgnoreTlog    bool
+	CacheTTL      time.Duration
+	Rekor         options.RekorOptions
+	Registry      options.RegistryOptions
+}
+
+func (o *webhookOptions) addFlags(cmd *cobra.Command) {
+	o.Rekor.AddFlags(cmd)
+	o.Registry.AddFlags(cmd)
+
+	cmd.Flags().IntVar(&o.Port, "port", 8443,
+		"port the webhook listens on")
+	cmd.Flags().StringVar(&o.TLSCertFile, "tls-cert-file", "",
+		"path to the PEM encoded TLS certificate served by the webhook")
+	cmd.Flags().StringVar(&o.TLSKeyFile, "tls-key-file", "",
+		"path to the PEM encoded private key of the TLS certificate")
+	cmd.Flags().StringVar(&o.NoMatchPolicy, "no-match-policy", "allow",
+		"whether images no ImagePolicy matches are admitted (allow) or denied (deny)")
+	cmd.Flags().BoolVar(&o.IgnoreTlog, "ignore-tlog", false,
+		"ignore transparency log verification, to be used when an artifact signature has not been uploaded to the transparency log.")
+	cmd.Flags().DurationVar(&o.CacheTTL, "cache-ttl", 0,
+		"how long to remember the images verified against a policy, e.g. 5m, so that pods using them skip their verification. 0 disables the cache")
+	_ = cmd.MarkFlagRequired("tls-cert-file")
+	_ = cmd.MarkFlagRequired("tls-key-file")
+}
+
+func main() {
+	o := &webhookOptions{}
+	cmd := &cobra.Command{
+		Use:   "cosign-webhook",
+		Short: "Admission webhook verifying the images of pods with cosign",
+		Long: `Validating admission webhook admitting pods only when their images satisfy
+the ImagePolicy resources of their namespace.
+
+An ImagePolicy lists the authorities, keys or keyless identities, trusted to
+sign the images it matches, and the attestations those images require along
+with the CUE or Rego policies they must pass.`,
+		Args:          cobra.NoArgs,
+		SilenceUsage:  true,
+		SilenceErrors: true,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			return run(cmd.Context(), o)
+		},
+	}
+	o.addFlags(cmd)
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	if err := cmd.ExecuteContext(ctx); err != nil {
+		log.Fatalf("error during command execution: %v", err)
+	}
+}
+
+func run(ctx context.Context, o *webhookOptions) error {
+	var denyUnmatched bool
+	switch o.NoMatchPolicy {
+	case "allow":
+	case "deny":
+		denyUnmatched = true
+	default:
+		return fmt.Errorf("invalid --no-match-policy %q, expected allow or deny", o.NoMatchPolicy)
+	}
+
+	shutdown, err := tracing.Configure(ctx, "cosign-webhook")
+	if err != nil {
+		return err
+	}
+	defer func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if err := shutdown(ctx); err != nil {
+			log.Printf("exporting traces: %v", err)
+		}
+	}()
+	if tracing.Enabled() {
+		options.WrapTransports(tracing.Transport)
+	}
+
+	metrics := webhookcli.NewMetrics()
+	verifier, err := webhookcli.NewVerifier(ctx, o.Registry, o.Rekor.URL, o.IgnoreTlog, true)
+	if err != nil {
+		return err
+	}
+	var v webhook.ImageVerifier = verifier
+	if o.CacheTTL > 0 {
+		v = webhook.NewCachingVerifier(v, o.CacheTTL, metrics)
+	}
+	config, err := kubernetes.Config()
+	if err != nil {
+		return err
+	}
+	client, err := dynamic.NewForConfig(config)
+	if err != nil {
+		return fmt.Errorf("creating kubernetes client: %w", err)
+	}
+
+	wh := &webhook.Webhook{
+		Policies:      webhook.NewPolicyLister(client),
+		Verifier:      metrics.Verifier(v),
+		DenyUnmatched: denyUnmatched,
+	}
+	mux := http.NewServeMux()
+	mux.Handle("/validate", wh)
+	mux.Handle("/mutate", wh.MutatingHandler())
+	mux.HandleFunc("/healthz", func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+	mux.Handle("/metrics", metrics.Handler())
+	server := &http.Server{
+		Addr:              fmt.Sprintf(":%d", o.Port),
+		Handler:           tracing.Handler(mux),
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	errCh := make(chan error, 1)
+	go func() {
+		log.Printf("cosign-webhook listening on %s", server.Addr)
+		errCh <- server.ListenAndServeTLS(o.TLSCertFile, o.TLSKeyFile)
+	}()
+	select {
+	case err := <-errCh:
+		return err
+	case <-ctx.Done():
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+		if err := server.Shutdown(shutdownCtx); err != nil {
+			return err
+		}
+		if err := <-errCh; !errors.Is(err, http.ErrServerClosed) {
+			return err
+		}
+		return nil
+	}
+}
@@ -16,11 +16,14 @@
 package cli
 
 import (
+	"errors"
 	"fmt"
 	"os"
 
 	"github.com/sigstore/cosign/v2/cmd/cosign/cli/attach"
+	"github.com/sigstore/cosign/v2/cmd/cosign/cli/generate"
 	"github.com/sigstore/cosign/v2/cmd/cosign/cli/options"
+	"github.com/sigstore/cosign/v2/cmd/cosign/cli/sign"
 	"github.com/spf13/cobra"
 )
 
@@ -43,9 +46,20 @@ func attachSignature() *cobra.Command {
 	o := &options.AttachSignatureOptions{}
 
 	cmd := &cobra.Command{
-		Use:              "signature",
-		Short:            "Attach signatures to the supplied container image",
-		Example:          "  cosign attach signature <image uri>",
+		Use:   "signature",
+		Short: "Attach signatures to the supplied container image",
+		Long: `Attach signatures to the supplied container image.
+
+The signature can be created by another system than the one attaching it, e.g.
+a signer isolated with its HSM from the registry, with
+'cosign sign --no-upload --output-signature <FILE> --output-payload <FILE>'.
+The payload records the digest of the signed image, and is checked to sign the
+digest of the image the signature is attached to.`,
+		Example: `  cosign attach signature <image uri>
+
+  # sign an image without uploading the signature, then attach it from another system
+  cosign sign --key cosign.key --no-upload --output-signature sig.b64 --output-payload payload.json <IMAGE DIGEST>
+  cosign attach signature --signature sig.b64 --payload payload.json <IMAGE DIGEST>`,
 		PersistentPreRun: options.BindViper,
 		Args:             cobra.ExactArgs(1),
 		RunE: func(cmd *cobra.Command, args []string) error {
@@ -62,10 +76,17 @@ func attachSBOM() *cobra.Command {
 	o := &options.AttachSBOMOptions{}
 
 	cmd := &cobra.Command{
-		Use:              "sbom",
-		Short:            "DEPRECATED: Attach sbom to the supplied container image",
-		Long:             "Attach sbom to the supplied container image\n\n" + options.SBOMAttachmentDeprecation,
-		Example:          "  cosign attach sbom <image uri>",
+		Use:   "sbom",
+		Short: "DEPRECATED: Attach sbom to the supplied container image",
+		Long:  "Attach sbom to the supplied container image\n\n" + options.SBOMAttachmentDeprecation,
+		Example: `  cosign attach sbom <image uri>
+
+  # attach a CycloneDX JSON sbom in a layer compressed with gzip
+  cosign attach sbom --sbom bom.json --type cyclonedx --compression gzip <image uri>
+
+  # attach an sbom and sign it, then verify it
+  cosign attach sbom --sbom bom.spdx --sign --key cosign.key <image uri>
+  cosign verify --attachment sbom --key cosign.pub <image uri>`,
 		Args:             cobra.ExactArgs(1),
 		PersistentPreRun: options.BindViper,
 		RunE: func(cmd *cobra.Command, args []string) error {
@@ -74,8 +95,58 @@ func attachSBOM() *cobra.Command {
 			if err != nil {
 				return err
 			}
-			fmt.Fprintf(os.Stderr, "WARNING: Attaching SBOMs this way does not sign them. To sign them, use 'cosign attest --predicate %s --key <key path>'.\n", o.SBOM)
-			return attach.SBOMCmd(cmd.Context(), o.Registry, o.RegistryExperimental, o.SBOM, mediaType, args[0])
+			if !o.Sign {
+				if o.Key != "" {
+					return errors.New("--key requires --sign")
+				}
+				fmt.Fprintf(os.Stderr, "WARNING: Attaching SBOMs this way does not sign them. To sign them, use --sign or 'cosign attest --predicate %s --key <key path>'.\n", o.SBOM)
+			} else if o.RegistryExperimental.RegistryReferrersMode == options.RegistryReferrersModeOCI11 {
+				return fmt.Errorf("--sign is not supported with --registry-referrers-mode=%s", options.RegistryReferrersModeOCI11)
+			}
+			if err := attach.SBOMCmd(cmd.Context(), o.Registry, o.RegistryExperimental, o.SBOM, mediaType, o.Compression, args[0]); err != nil {
+				return err
+			}
+			if !o.Sign {
+				return nil
+			}
+
+			oidcClientSecret, err := o.OIDC.ClientSecret()
+			if err != nil {
+				return err
+			}
+			ko := options.KeyOpts{
+				KeyRef:                   o.Key,
+				PassFunc:                 generate.GetPass,
+				FulcioURL:                o.Fulcio.URL,
+				IDToken:                  o.Fulcio.IdentityToken,
+				InsecureSkipFulcioVerify: o.Fulcio.InsecureSkipFulcioVerify,
+				RekorURL:                 o.Rekor.URL,
+				OIDCIssuer:               o.OIDC.Issuer,
+				OIDCClientID:             o.OIDC.ClientID,
+				OIDCClientSecret:         oidcClientSecret,
+				OIDCRedirectURL:          o.OIDC.RedirectURL,
+				OIDCScopes:               o.OIDC.Scopes,
+				OIDCDisableProviders:     o.OIDC.DisableAmbientProviders,
+				FulcioAuthFlow:           o.OIDC.Flow,
+				SPIFFESocket:             o.OIDC.SPIFFESocket,
+				OIDCProvider:             o.OIDC.Provider,
+				SkipConfirmation:         o.SkipConfirmation,
+			}
+			so := options.SignOptions{
+				Key:              o.Key,
+				Upload:           true,
+				TlogUpload:       o.TlogUpload,
+				Attachment:       "sbom",
+				SkipConfirmation: o.SkipConfirmation,
+				Rekor:            o.Rekor,
+				Fulcio:           o.Fulcio,
+				OIDC:             o.OIDC,
+				Registry:         o.Registry,
+			}
+			if err := sign.SignCmd(ro, ko, so, args); err != nil {
+				return fmt.Errorf("signing the sbom of %s: %w", args[0], err)
+			}
+			return nil
 		},
 	}
 
@@ -90,8 +161,17 @@ func attachAttestation() *cobra.Command {
 	cmd := &cobra.Command{
 		Use:   "attestation",
 		Short: "Attach attestation to the supplied container image",
+		Long: `Attach attestation to the supplied container image.
+
+The attestations are DSSE envelopes of in-toto statements created by other
+tools, e.g. Tekton Chains or the SLSA GitHub generator. Every envelope must be
+signed and state about the image, and is verified with --key when set before
+it is attached.`,
 		Example: `  cosign attach attestation --attestation <attestation file path> <image uri>
 
+  # attach an attestation after verifying its signature with the public key of its builder
+  cosign attach attestation --key builder.pub --attestation <attestation file path> <image uri>
+
   # attach attestations from multiple files to a container image
   cosign attach attestation --attestation <attestation file path> --attestation <attestation file path> <image uri>
 
@@ -103,7 +183,7 @@ func attachAttestation() *cobra.Command {
 		Args:             cobra.MinimumNArgs(1),
 		PersistentPreRun: options.BindViper,
 		RunE: func(cmd *cobra.Command, args []string) error {
-			return attach.AttestationCmd(cmd.Context(), o.Registry, o.Attestations, args[0])
+			return attach.AttestationCmd(cmd.Context(), o.Registry, o.Attestations, o.Key, args[0])
 		},
 	}
 
@@ -19,25 +19,41 @@ import (
 	"encoding/json"
 	"fmt"
 	"os"
+	"strings"
 
 	"github.com/google/go-containerregistry/pkg/name"
+	"github.com/in-toto/in-toto-golang/in_toto"
 	ssldsse "github.com/secure-systems-lab/go-securesystemslib/dsse"
 	"github.com/sigstore/cosign/v2/cmd/cosign/cli/options"
 	"github.com/sigstore/cosign/v2/internal/ui"
 	"github.com/sigstore/cosign/v2/pkg/oci/mutate"
 	ociremote "github.com/sigstore/cosign/v2/pkg/oci/remote"
 	"github.com/sigstore/cosign/v2/pkg/oci/static"
+	sigs "github.com/sigstore/cosign/v2/pkg/signature"
 	"github.com/sigstore/cosign/v2/pkg/types"
+	"github.com/sigstore/sigstore/pkg/signature"
+	"github.com/sigstore/sigstore/pkg/signature/dsse"
 )
 
-func AttestationCmd(ctx context.Context, regOpts options.RegistryOptions, signedPayloads []string, imageRef string) error {
+// AttestationCmd attaches the DSSE envelopes of the files signedPayloads,
+// e.g. created by Tekton Chains or the SLSA GitHub generator, to imageRef.
+// Every envelope must hold an in-toto statement about imageRef, and is
+// verified with the public key keyRef when set.
+func AttestationCmd(ctx context.Context, regOpts options.RegistryOptions, signedPayloads []string, keyRef, imageRef string) error {
 	ociremoteOpts, err := regOpts.ClientOpts(ctx)
 	if err != nil {
 		return fmt.Errorf("constructing client options: %w", err)
 	}
 
+	var verifier signature.Verifier
+	if keyRef != "" {
+		if verifier, err = sigs.PublicKeyFromKeyRef(ctx, keyRef); err != nil {
+			return fmt.Errorf("loading public key: %w", err)
+		}
+	}
+
 	for _, payload := range signedPayloads {
-		if err := attachAttestation(ctx, ociremoteOpts, payload, imageRef, regOpts.NameOptions()); err != nil {
+		if err := attachAttestation(ctx, ociremoteOpts, verifier, payload, imageRef, regOpts.NameOptions()); err != nil {
 			return fmt.Errorf("attaching payload from %s: %w", payload, err)
 		}
 	}
@@ -45,49 +61,50 @@ func AttestationCmd(ctx context.Context, regOpts options.RegistryOptions, signed
 	return nil
 }
 
-func attachAttestation(ctx context.Context, remoteOpts []ociremote.Option, signedPayload, imageRef string, nameOpts []name.Option) error {
+func attachAttestation(ctx context.Context, remoteOpts []ociremote.Option, verifier signature.Verifier, signedPayload, imageRef string, nameOpts []name.Option) error {
 	fmt.Fprintf(os.Stderr, "Using payload from: %s", signedPayload)
 	attestationFile, err := os.Open(signedPayload)
 	if err != nil {
 		return err
 	}
+	defer attestationFile.Close()
+
+	ref, err := name.ParseReference(imageRef, nameOpts...)
+	if err != nil {
+		return err
+	}
+	if _, ok := ref.(name.Digest); !ok {
+		msg := fmt.Sprintf(ui.TagReferenceMessage, imageRef)
+		ui.Warnf(ctx, msg)
+	}
+	digest, err := ociremote.ResolveDigest(ref, remoteOpts...)
+	if err != nil {
+		return err
+	}
 
-	env := ssldsse.Envelope{}
 	decoder := json.NewDecoder(attestationFile)
 	for decoder.More() {
+		env := ssldsse.Envelope{}
 		if err := decoder.Decode(&env); err != nil {
 			return err
 		}
-
-		payload, err := json.Marshal(env)
-		if err != nil {
+		if err := validateEnvelope(&env, digest); err != nil {
 			return err
 		}
-
-		if env.PayloadType != types.IntotoPayloadType {
-			return fmt.Errorf("invalid payloadType %s on envelope. Expected %s", env.PayloadType, types.IntotoPayloadType)
-		}
-
-		if len(env.Signatures) == 0 {
-			return fmt.Errorf("could not attach attestation without having signatures")
+		if verifier != nil {
+			dssev, err := ssldsse.NewEnvelopeVerifier(&dsse.VerifierAdapter{SignatureVerifier: verifier})
+			if err != nil {
+				return err
+			}
+			if _, err := dssev.Verify(ctx, &env); err != nil {
+				return fmt.Errorf("verifying the signature of the envelope: %w", err)
+			}
 		}
 
-		ref, err := name.ParseReference(imageRef, nameOpts...)
-		if err != nil {
-			return err
-		}
-		if _, ok := ref.(name.Digest); !ok {
-			msg := fmt.Sprintf(ui.TagReferenceMessage, imageRef)
-			ui.Warnf(ctx, msg)
-		}
-		digest, err := ociremote.ResolveDigest(ref, remoteOpts...)
+		payload, err := json.Marshal(env)
 		if err != nil {
 			return err
 		}
-		// Overwrite "ref" with a digest to avoid a race where we use a tag
-		// multiple times, and it potentially points to different things at
-		// each access.
-		ref = digest // nolint
 
 		opts := []static.Option{static.WithLayerMediaType(types.DssePayloadType)}
 		att, err := static.NewAttestation(payload, opts...)
@@ -113,3 +130,38 @@ func attachAttestation(ctx context.Context, remoteOpts []ociremote.Option, signe
 	}
 	return nil
 }
+
+// validateEnvelope checks that env is a signed DSSE envelope of an in-toto
+// statement, one of whose subjects is digest.
+func validateEnvelope(env *ssldsse.Envelope, digest name.Digest) error {
+	if env.PayloadType != types.IntotoPayloadType {
+		return fmt.Errorf("invalid payloadType %s on envelope. Expected %s", env.PayloadType, types.IntotoPayloadType)
+	}
+	if len(env.Signatures) == 0 {
+		return fmt.Errorf("could not attach attestation without having signatures")
+	}
+
+	payload, err := env.DecodeB64Payload()
+	if err != nil {
+		return fmt.Errorf("decoding envelope payload: %w", err)
+	}
+	var statement in_toto.StatementHeader
+	if err := json.Unmarshal(payload, &statement); err != nil {
+		return fmt.Errorf("decoding in-toto statement: %w", err)
+	}
+	// Accept the v1 statements of newer builders as well as v0.1 ones.
+	if !strings.HasPrefix(statement.Type, "https://in-toto.io/Statement/") {
+		return fmt.Errorf("invalid _type %q on statement. Expected %s", statement.Type, in_toto.StatementInTotoV01)
+	}
+	if statement.PredicateType == "" {
+		return fmt.Errorf("statement has no predicateType")
+	}
+
+	algorithm, hex, _ := strings.Cut(digest.DigestStr(), ":")
+	for _, s := range statement.Subject {
+		if s.Digest[algorithm] == hex {
+			return nil
+		}
+	}
+	return fmt.Errorf("none of the subjects of the statement is %s", digest.DigestStr())
+}
@@ -0,0 +1,85 @@
+// Copyright 2021 The Sigstore Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package attach
+
+import (
+	"encoding/base64"
+	"testing"
+
+	"github.com/google/go-containerregistry/pkg/name"
+	ssldsse "github.com/secure-systems-lab/go-securesystemslib/dsse"
+
+	"github.com/sigstore/cosign/v2/pkg/types"
+)
+
+func TestValidateEnvelope(t *testing.T) {
+	const hex = "0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef"
+	digest := name.MustParseReference("ghcr.io/example/app@sha256:" + hex).(name.Digest)
+	envelope := func(statement string) *ssldsse.Envelope {
+		return &ssldsse.Envelope{
+			PayloadType: types.IntotoPayloadType,
+			Payload:     base64.StdEncoding.EncodeToString([]byte(statement)),
+			Signatures:  []ssldsse.Signature{{Sig: "MEUCIQ=="}},
+		}
+	}
+	subject := `"subject": [{"name": "ghcr.io/example/app", "digest": {"sha256": "` + hex + `"}}]`
+
+	tests := []struct {
+		name    string
+		env     *ssldsse.Envelope
+		wantErr bool
+	}{{
+		name: "v0.1 statement",
+		env:  envelope(`{"_type": "https://in-toto.io/Statement/v0.1", "predicateType": "https://slsa.dev/provenance/v0.2", ` + subject + `}`),
+	}, {
+		name: "v1 statement",
+		env:  envelope(`{"_type": "https://in-toto.io/Statement/v1", "predicateType": "https://slsa.dev/provenance/v1", ` + subject + `}`),
+	}, {
+		name: "other subject",
+		env: envelope(`{"_type": "https://in-toto.io/Statement/v0.1", "predicateType": "https://slsa.dev/provenance/v0.2", ` +
+			`"subject": [{"name": "ghcr.io/example/app", "digest": {"sha256": "fedcba"}}]}`),
+		wantErr: true,
+	}, {
+		name:    "no predicate type",
+		env:     envelope(`{"_type": "https://in-toto.io/Statement/v0.1", ` + subject + `}`),
+		wantErr: true,
+	}, {
+		name:    "not a statement",
+		env:     envelope(`{"hello": "world"}`),
+		wantErr: true,
+	}, {
+		name:    "invalid payload",
+		env:     &ssldsse.Envelope{PayloadType: types.IntotoPayloadType, Payload: "!", Signatures: []ssldsse.Signature{{Sig: "MEUCIQ=="}}},
+		wantErr: true,
+	}, {
+		name: "unsigned",
+		env: &ssldsse.Envelope{
+			PayloadType: types.IntotoPayloadType,
+			Payload:     envelope(`{"_type": "https://in-toto.io/Statement/v0.1", "predicateType": "custom", ` + subject + `}`).Payload,
+		},
+		wantErr: true,
+	}, {
+		name:    "other payload type",
+		env:     &ssldsse.Envelope{PayloadType: "application/json", Signatures: []ssldsse.Signature{{Sig: "MEUCIQ=="}}},
+		wantErr: true,
+	}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := validateEnvelope(tt.env, digest); (err != nil) != tt.wantErr {
+				t.Errorf("validateEnvelope() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
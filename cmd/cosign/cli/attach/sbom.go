@@ -16,6 +16,8 @@
 package attach
 
 import (
+	"bytes"
+	"compress/gzip"
 	"context"
 	"errors"
 	"fmt"
@@ -38,19 +40,25 @@ import (
 	"github.com/sigstore/cosign/v2/internal/ui"
 	ociremote "github.com/sigstore/cosign/v2/pkg/oci/remote"
 	"github.com/sigstore/cosign/v2/pkg/oci/static"
+	ctypes "github.com/sigstore/cosign/v2/pkg/types"
 )
 
-func SBOMCmd(ctx context.Context, regOpts options.RegistryOptions, regExpOpts options.RegistryExperimentalOptions, sbomRef string, sbomType ocitypes.MediaType, imageRef string) error {
-	if regExpOpts.RegistryReferrersMode == options.RegistryReferrersModeOCI11 {
-		return sbomCmdOCIExperimental(ctx, regOpts, sbomRef, sbomType, imageRef)
-	}
-
-	ref, err := name.ParseReference(imageRef, regOpts.NameOptions()...)
+// SBOMCmd attaches the SBOM sbomRef of media type sbomType to imageRef, in a
+// layer compressed with compression, none (or empty) or gzip.
+func SBOMCmd(ctx context.Context, regOpts options.RegistryOptions, regExpOpts options.RegistryExperimentalOptions, sbomRef string, sbomType ocitypes.MediaType, compression, imageRef string) error {
+	b, err := sbomBytes(sbomRef)
 	if err != nil {
 		return err
 	}
+	if b, sbomType, err = compressSBOM(b, sbomType, compression); err != nil {
+		return err
+	}
 
-	b, err := sbomBytes(sbomRef)
+	if regExpOpts.RegistryReferrersMode == options.RegistryReferrersModeOCI11 {
+		return sbomCmdOCIExperimental(ctx, regOpts, b, sbomType, imageRef)
+	}
+
+	ref, err := name.ParseReference(imageRef, regOpts.NameOptions()...)
 	if err != nil {
 		return err
 	}
@@ -73,7 +81,7 @@ func SBOMCmd(ctx context.Context, regOpts options.RegistryOptions, regExpOpts op
 	return remote.Write(dstRef, img, regOpts.GetRegistryClientOpts(ctx)...)
 }
 
-func sbomCmdOCIExperimental(ctx context.Context, regOpts options.RegistryOptions, sbomRef string, sbomType ocitypes.MediaType, imageRef string) error {
+func sbomCmdOCIExperimental(ctx context.Context, regOpts options.RegistryOptions, b []byte, sbomType ocitypes.MediaType, imageRef string) error {
 	var dig name.Digest
 	ref, err := name.ParseReference(imageRef, regOpts.NameOptions()...)
 	if err != nil {
@@ -110,11 +118,6 @@ func sbomCmdOCIExperimental(ctx context.Context, regOpts options.RegistryOptions
 		return err
 	}
 
-	b, err := sbomBytes(sbomRef)
-	if err != nil {
-		return err
-	}
-
 	empty := mutate.MediaType(
 		mutate.ConfigMediaType(empty.Image, ocitypes.MediaType(artifactType)),
 		ocitypes.OCIManifestSchema1)
@@ -134,6 +137,27 @@ func sbomCmdOCIExperimental(ctx context.Context, regOpts options.RegistryOptions
 	return remote.Write(dstRef, att, regOpts.GetRegistryClientOpts(ctx)...)
 }
 
+// compressSBOM compresses the SBOM b of media type sbomType with
+// compression, returning the media type of the compressed SBOM.
+func compressSBOM(b []byte, sbomType ocitypes.MediaType, compression string) ([]byte, ocitypes.MediaType, error) {
+	switch compression {
+	case "", ctypes.NoCompression:
+		return b, sbomType, nil
+	case ctypes.GzipCompression:
+		var buf bytes.Buffer
+		zw := gzip.NewWriter(&buf)
+		if _, err := zw.Write(b); err != nil {
+			return nil, "", err
+		}
+		if err := zw.Close(); err != nil {
+			return nil, "", err
+		}
+		return buf.Bytes(), sbomType + ctypes.GzipMediaTypeSuffix, nil
+	default:
+		return nil, "", fmt.Errorf("unknown SBOM compression: %q, expected (none|gzip)", compression)
+	}
+}
+
 func sbomBytes(sbomRef string) ([]byte, error) {
 	// sbomRef can be "-", a string or a file.
 	switch signatureType(sbomRef) {
@@ -16,8 +16,11 @@
 package attach
 
 import (
+	"bytes"
 	"context"
+	"encoding/json"
 	"errors"
+	"fmt"
 	"io"
 	"os"
 	"path/filepath"
@@ -29,6 +32,7 @@ import (
 	"github.com/sigstore/cosign/v2/pkg/oci/mutate"
 	ociremote "github.com/sigstore/cosign/v2/pkg/oci/remote"
 	"github.com/sigstore/cosign/v2/pkg/oci/static"
+	sigPayload "github.com/sigstore/sigstore/pkg/signature/payload"
 )
 
 func SignatureCmd(ctx context.Context, regOpts options.RegistryOptions, sigRef, payloadRef, certRef, certChainRef, timeStampedSigRef, imageRef string) error {
@@ -59,11 +63,17 @@ func SignatureCmd(ctx context.Context, regOpts options.RegistryOptions, sigRef,
 	var payload []byte
 	if payloadRef == "" {
 		payload, err = cosign.ObsoletePayload(ctx, digest)
+		if err != nil {
+			return err
+		}
 	} else {
 		payload, err = os.ReadFile(filepath.Clean(payloadRef))
-	}
-	if err != nil {
-		return err
+		if err != nil {
+			return err
+		}
+		if err := checkPayloadDigest(payload, digest); err != nil {
+			return err
+		}
 	}
 
 	sig, err := static.NewSignature(payload, string(b64SigBytes))
@@ -117,6 +127,20 @@ func SignatureCmd(ctx context.Context, regOpts options.RegistryOptions, sigRef,
 	return ociremote.WriteSignatures(digest.Repository, newSE, ociremoteOpts...)
 }
 
+// checkPayloadDigest checks that payload, e.g. written by
+// 'cosign sign --output-payload', signs digest, so that a detached signature
+// isn't attached to another image.
+func checkPayloadDigest(payload []byte, digest name.Digest) error {
+	var p sigPayload.SimpleContainerImage
+	if err := json.Unmarshal(payload, &p); err != nil {
+		return fmt.Errorf("parsing payload: %w", err)
+	}
+	if got := p.Critical.Image.DockerManifestDigest; got != digest.DigestStr() {
+		return fmt.Errorf("payload signs digest %q, not %s", got, digest.DigestStr())
+	}
+	return nil
+}
+
 type SignatureArgType uint8
 
 const (
@@ -133,7 +157,9 @@ func signatureBytes(sigRef string) ([]byte, error) {
 	case RawSignature:
 		return []byte(sigRef), nil
 	case FileSignature:
-		return os.ReadFile(filepath.Clean(sigRef))
+		b, err := os.ReadFile(filepath.Clean(sigRef))
+		// Editors and other signers may end the file with a newline.
+		return bytes.TrimSpace(b), err
 	default:
 		return nil, errors.New("unknown signature arg type")
 	}
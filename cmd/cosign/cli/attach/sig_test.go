@@ -0,0 +1,60 @@
+//
+// Copyright 2023 The Sigstore Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package attach
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-containerregistry/pkg/name"
+
+	"github.com/sigstore/cosign/v2/pkg/cosign"
+)
+
+func TestCheckPayloadDigest(t *testing.T) {
+	digest := name.MustParseReference("ghcr.io/example/app@sha256:" + "0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef").(name.Digest)
+	other := name.MustParseReference("ghcr.io/example/app@sha256:" + "fedcba9876543210fedcba9876543210fedcba9876543210fedcba9876543210").(name.Digest)
+	payload, err := cosign.ObsoletePayload(context.Background(), digest)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := checkPayloadDigest(payload, digest); err != nil {
+		t.Errorf("checkPayloadDigest() of the signed digest = %v", err)
+	}
+	if err := checkPayloadDigest(payload, other); err == nil {
+		t.Error("checkPayloadDigest() of another digest succeeded")
+	}
+	if err := checkPayloadDigest([]byte("not json"), digest); err == nil {
+		t.Error("checkPayloadDigest() of an invalid payload succeeded")
+	}
+}
+
+func TestSignatureBytesTrimsFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "sig.b64")
+	if err := os.WriteFile(path, []byte("MEUCIQ==\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	b, err := signatureBytes(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := string(b), "MEUCIQ=="; got != want {
+		t.Errorf("signatureBytes() = %q, want %q", got, want)
+	}
+}
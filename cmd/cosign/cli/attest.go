@@ -39,6 +39,9 @@ func Attest() *cobra.Command {
   # attach an attestation to a container image with a local key pair file
   cosign attest --predicate <FILE> --type <TYPE> --key cosign.key <IMAGE>
 
+  # attach an attestation annotated with its owning team, see verify-attestation -a
+  cosign attest --predicate <FILE> --type <TYPE> --key cosign.key -a team=platform <IMAGE>
+
   # attach an attestation to a container image with a key pair stored in Azure Key Vault
   cosign attest --predicate <FILE> --type <TYPE> --key azurekms://[VAULT_NAME][VAULT_URI]/[KEY] <IMAGE>
 
@@ -54,11 +57,23 @@ func Attest() *cobra.Command {
   # attach an attestation to a container image with a local key pair file, including a certificate and certificate chain
   cosign attest --predicate <FILE> --type <TYPE> --key cosign.key --cert cosign.crt --cert-chain chain.crt <IMAGE>
 
+  # attach an attestation to a container image and write a bundle for offline verification with verify-attestation
+  cosign attest --predicate <FILE> --type <TYPE> --key cosign.key --bundle <BUNDLE> <IMAGE>
+
+  # attach an attestation to a container image, replacing the attestations of the same predicate type
+  cosign attest --predicate <FILE> --type <TYPE> --key cosign.key --replace <IMAGE>
+
+  # attach a vulnerability scan attestation from the JSON report of Trivy or Grype
+  cosign attest --predicate <SCAN_REPORT> --type vuln --key cosign.key <IMAGE>
+
   # attach an attestation to a container image which does not fully support OCI media types
   COSIGN_DOCKER_MEDIA_TYPES=1 cosign attest --predicate <FILE> --type <TYPE> --key cosign.key legacy-registry.example.com/my/image
 
   # supply attestation via stdin
-  echo <PAYLOAD> | cosign attest --predicate - <IMAGE>`,
+  echo <PAYLOAD> | cosign attest --predicate - <IMAGE>
+
+  # attach an attestation with a provenance document fetched from a URL, pinned by its digest
+  cosign attest --predicate https://<HOST>/provenance.json --predicate-sha256 <HEX_DIGEST> --type slsaprovenance1 --key cosign.key <IMAGE>`,
 
 		Args:             cobra.MinimumNArgs(1),
 		PersistentPreRun: options.BindViper,
@@ -80,21 +95,39 @@ func Attest() *cobra.Command {
 				OIDCClientID:             o.OIDC.ClientID,
 				OIDCClientSecret:         oidcClientSecret,
 				OIDCRedirectURL:          o.OIDC.RedirectURL,
+				OIDCScopes:               o.OIDC.Scopes,
 				OIDCProvider:             o.OIDC.Provider,
+				FulcioAuthFlow:           o.OIDC.Flow,
+				SPIFFESocket:             o.OIDC.SPIFFESocket,
+				OutputKey:                o.OutputKey,
 				SkipConfirmation:         o.SkipConfirmation,
+				TSAClientCACert:          o.TSAClientCACert,
+				TSAClientCert:            o.TSAClientCert,
+				TSAClientKey:             o.TSAClientKey,
+				TSAServerName:            o.TSAServerName,
 				TSAServerURL:             o.TSAServerURL,
 			}
+			annotations, err := o.AnnotationsMap()
+			if err != nil {
+				return err
+			}
 			attestCommand := attest.AttestCommand{
-				KeyOpts:         ko,
-				RegistryOptions: o.Registry,
-				CertPath:        o.Cert,
-				CertChainPath:   o.CertChain,
-				NoUpload:        o.NoUpload,
-				PredicatePath:   o.Predicate.Path,
-				PredicateType:   o.Predicate.Type,
-				Replace:         o.Replace,
-				Timeout:         ro.Timeout,
-				TlogUpload:      o.TlogUpload,
+				KeyOpts:              ko,
+				RegistryOptions:      o.Registry,
+				RegistryExperimental: o.RegistryExperimental,
+				LocalImage:           o.LocalImage,
+				CertPath:             o.Cert,
+				CertChainPath:        o.CertChain,
+				BundlePath:           o.BundlePath,
+				OutputCertificate:    o.OutputCertificate,
+				NoUpload:             o.NoUpload,
+				PredicatePath:        o.Predicate.Path,
+				PredicateSHA256:      o.Predicate.SHA256,
+				PredicateType:        o.Predicate.Type,
+				Replace:              o.Replace,
+				Annotations:          annotations.Annotations,
+				Timeout:              ro.Timeout,
+				TlogUpload:           o.TlogUpload,
 			}
 
 			for _, img := range args {
@@ -19,9 +19,12 @@ import (
 	"bytes"
 	"context"
 	_ "crypto/sha256" // for `crypto.SHA256`
+	"encoding/base64"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"os"
+	"path/filepath"
 	"time"
 
 	"github.com/google/go-containerregistry/pkg/name"
@@ -31,12 +34,13 @@ import (
 	"github.com/sigstore/cosign/v2/cmd/cosign/cli/rekor"
 	"github.com/sigstore/cosign/v2/cmd/cosign/cli/sign"
 	"github.com/sigstore/cosign/v2/internal/pkg/cosign/tsa"
-	tsaclient "github.com/sigstore/cosign/v2/internal/pkg/cosign/tsa/client"
 	"github.com/sigstore/cosign/v2/internal/ui"
 	"github.com/sigstore/cosign/v2/pkg/cosign"
 	"github.com/sigstore/cosign/v2/pkg/cosign/attestation"
 	cbundle "github.com/sigstore/cosign/v2/pkg/cosign/bundle"
 	cremote "github.com/sigstore/cosign/v2/pkg/cosign/remote"
+	"github.com/sigstore/cosign/v2/pkg/oci"
+	"github.com/sigstore/cosign/v2/pkg/oci/layout"
 	"github.com/sigstore/cosign/v2/pkg/oci/mutate"
 	ociremote "github.com/sigstore/cosign/v2/pkg/oci/remote"
 	"github.com/sigstore/cosign/v2/pkg/oci/static"
@@ -63,7 +67,7 @@ func uploadToTlog(ctx context.Context, sv *sign.SignerVerifier, rekorURL string,
 	if err != nil {
 		return nil, err
 	}
-	fmt.Fprintln(os.Stderr, "tlog entry created with index:", *entry.LogIndex)
+	ui.Infof(ctx, "tlog entry created with index: %d", *entry.LogIndex)
 	return cbundle.EntryToBundle(entry), nil
 }
 
@@ -71,15 +75,21 @@ func uploadToTlog(ctx context.Context, sv *sign.SignerVerifier, rekorURL string,
 type AttestCommand struct {
 	options.KeyOpts
 	options.RegistryOptions
-	CertPath      string
-	CertChainPath string
-	NoUpload      bool
-	PredicatePath string
-	PredicateType string
-	Replace       bool
-	Timeout       time.Duration
-	TlogUpload    bool
-	TSAServerURL  string
+	RegistryExperimental options.RegistryExperimentalOptions
+	LocalImage           bool
+	CertPath             string
+	CertChainPath        string
+	BundlePath           string
+	OutputCertificate    string
+	NoUpload             bool
+	PredicatePath        string
+	PredicateSHA256      string
+	PredicateType        string
+	Replace              bool
+	Annotations          map[string]interface{}
+	Timeout              time.Duration
+	TlogUpload           bool
+	TSAServerURL         string
 }
 
 // nolint
@@ -97,15 +107,6 @@ func (c *AttestCommand) Exec(ctx context.Context, imageRef string) error {
 	if err != nil {
 		return err
 	}
-	ref, err := name.ParseReference(imageRef, c.NameOptions()...)
-	if err != nil {
-		return fmt.Errorf("parsing reference: %w", err)
-	}
-	if _, ok := ref.(name.Digest); !ok {
-		msg := fmt.Sprintf(ui.TagReferenceMessage, imageRef)
-		ui.Warnf(ctx, msg)
-	}
-
 	if c.Timeout != 0 {
 		var cancelFn context.CancelFunc
 		ctx, cancelFn = context.WithTimeout(ctx, c.Timeout)
@@ -116,25 +117,63 @@ func (c *AttestCommand) Exec(ctx context.Context, imageRef string) error {
 	if err != nil {
 		return err
 	}
-	digest, err := ociremote.ResolveDigest(ref, ociremoteOpts...)
-	if err != nil {
-		return err
+
+	var digest name.Digest
+	var h v1.Hash
+	var repo string
+	var tlogRef name.Reference
+	var se oci.SignedEntity
+	if c.LocalImage {
+		se, err = layout.SignedEntity(imageRef)
+		if err != nil {
+			return fmt.Errorf("reading OCI layout: %w", err)
+		}
+		h, err = se.Digest()
+		if err != nil {
+			return err
+		}
+		// A local image has no repository yet, so the subject is named
+		// after the layout like blobs are named after their file.
+		repo = filepath.Base(filepath.Clean(imageRef))
+	} else {
+		ref, err := name.ParseReference(imageRef, c.NameOptions()...)
+		if err != nil {
+			return fmt.Errorf("parsing reference: %w", err)
+		}
+		if _, ok := ref.(name.Digest); !ok {
+			msg := fmt.Sprintf(ui.TagReferenceMessage, imageRef)
+			ui.Warnf(ctx, msg)
+		}
+		// Use the digest from here on to avoid a race where we use a tag
+		// multiple times, and it potentially points to different things at
+		// each access.
+		digest, err = ociremote.ResolveDigest(ref, ociremoteOpts...)
+		if err != nil {
+			return err
+		}
+		h, _ = v1.NewHash(digest.Identifier())
+		repo = digest.Repository.String()
+		tlogRef = digest
+		// We don't actually need to access the remote entity to attach things to it
+		// so we use a placeholder here.
+		se = ociremote.SignedUnknown(digest, ociremoteOpts...)
 	}
-	h, _ := v1.NewHash(digest.Identifier())
-	// Overwrite "ref" with a digest to avoid a race where we use a tag
-	// multiple times, and it potentially points to different things at
-	// each access.
-	ref = digest // nolint
 
 	sv, err := sign.SignerFromKeyOpts(ctx, c.CertPath, c.CertChainPath, c.KeyOpts)
 	if err != nil {
 		return fmt.Errorf("getting signer: %w", err)
 	}
 	defer sv.Close()
+	if c.OutputCertificate != "" && sv.Cert != nil {
+		if err := os.WriteFile(c.OutputCertificate, sv.Cert, 0600); err != nil {
+			return fmt.Errorf("create certificate file: %w", err)
+		}
+		ui.Infof(ctx, "Certificate written to %s", c.OutputCertificate)
+	}
 	wrapped := dsse.WrapSigner(sv, types.IntotoPayloadType)
 	dd := cremote.NewDupeDetector(sv)
 
-	predicate, err := predicateReader(c.PredicatePath)
+	predicate, err := predicateReader(ctx, c.PredicatePath, c.PredicateSHA256)
 	if err != nil {
 		return fmt.Errorf("getting predicate reader: %w", err)
 	}
@@ -144,7 +183,7 @@ func (c *AttestCommand) Exec(ctx context.Context, imageRef string) error {
 		Predicate: predicate,
 		Type:      c.PredicateType,
 		Digest:    h.Hex,
-		Repo:      digest.Repository.String(),
+		Repo:      repo,
 	})
 	if err != nil {
 		return err
@@ -170,7 +209,7 @@ func (c *AttestCommand) Exec(ctx context.Context, imageRef string) error {
 	}
 	if c.KeyOpts.TSAServerURL != "" {
 		// Here we get the response from the timestamped authority server
-		responseBytes, err := tsa.GetTimestampedSignature(signedPayload, tsaclient.NewTSAClient(c.KeyOpts.TSAServerURL))
+		responseBytes, err := tsa.GetTimestampedSignature(signedPayload, tsaClient(c.KeyOpts))
 		if err != nil {
 			return err
 		}
@@ -184,25 +223,40 @@ func (c *AttestCommand) Exec(ctx context.Context, imageRef string) error {
 		return err
 	}
 
-	predicateTypeAnnotation := map[string]string{
-		"predicateType": predicateType,
+	annotations := map[string]string{}
+	for k, v := range c.Annotations {
+		annotations[k] = fmt.Sprint(v)
+	}
+	if _, ok := annotations["predicateType"]; ok {
+		return errors.New("the predicateType annotation is reserved for the predicate type of the attestation")
 	}
 	// Add predicateType as manifest annotation
-	opts = append(opts, static.WithAnnotations(predicateTypeAnnotation))
+	annotations["predicateType"] = predicateType
+	if sv.KeyAttestation != nil {
+		annotations[cosign.PIVAttestationAnnotationKey] = string(sv.KeyAttestation)
+	}
+	opts = append(opts, static.WithAnnotations(annotations))
 
 	// Check whether we should be uploading to the transparency log
-	shouldUpload, err := sign.ShouldUploadToTlog(ctx, c.KeyOpts, digest, c.TlogUpload)
+	shouldUpload, err := sign.ShouldUploadToTlog(ctx, c.KeyOpts, tlogRef, c.TlogUpload)
 	if err != nil {
 		return fmt.Errorf("should upload to tlog: %w", err)
 	}
+	var rekorBundle *cbundle.RekorBundle
 	if shouldUpload {
-		bundle, err := uploadToTlog(ctx, sv, c.RekorURL, func(r *client.Rekor, b []byte) (*models.LogEntryAnon, error) {
+		rekorBundle, err = uploadToTlog(ctx, sv, c.RekorURL, func(r *client.Rekor, b []byte) (*models.LogEntryAnon, error) {
 			return cosign.TLogUploadDSSEEnvelope(ctx, r, signedPayload, b)
 		})
 		if err != nil {
 			return err
 		}
-		opts = append(opts, static.WithBundle(bundle))
+		opts = append(opts, static.WithBundle(rekorBundle))
+	}
+
+	if c.BundlePath != "" {
+		if err := writeBundle(ctx, sv, signedPayload, rekorBundle, c.BundlePath); err != nil {
+			return err
+		}
 	}
 
 	sig, err := static.NewAttestation(signedPayload, opts...)
@@ -210,10 +264,6 @@ func (c *AttestCommand) Exec(ctx context.Context, imageRef string) error {
 		return err
 	}
 
-	// We don't actually need to access the remote entity to attach things to it
-	// so we use a placeholder here.
-	se := ociremote.SignedUnknown(digest, ociremoteOpts...)
-
 	signOpts := []mutate.SignOption{
 		mutate.WithDupeDetector(dd),
 	}
@@ -229,6 +279,38 @@ func (c *AttestCommand) Exec(ctx context.Context, imageRef string) error {
 		return err
 	}
 
+	if c.LocalImage {
+		ui.Infof(ctx, "Saving attestation to: %s", imageRef)
+		return layout.WriteAttestations(imageRef, newSE)
+	}
+
+	// Publish the attestations associated with this entity (using OCI 1.1+ behavior)
+	if c.RegistryExperimental.RegistryReferrersMode == options.RegistryReferrersModeOCI11 {
+		return ociremote.WriteAttestationsExperimentalOCI(digest, newSE, ociremoteOpts...)
+	}
+
 	// Publish the attestations associated with this entity
 	return ociremote.WriteAttestations(digest.Repository, newSE, ociremoteOpts...)
 }
+
+// writeBundle stores the signed attestation together with its transparency log
+// bundle, so that it can be verified offline with `cosign verify-attestation --bundle`.
+func writeBundle(ctx context.Context, sv *sign.SignerVerifier, signedPayload []byte, rekorBundle *cbundle.RekorBundle, path string) error {
+	rekorBytes, err := sv.Bytes(ctx)
+	if err != nil {
+		return err
+	}
+	contents, err := json.Marshal(cosign.LocalSignedPayload{
+		Base64Signature: base64.StdEncoding.EncodeToString(signedPayload),
+		Cert:            base64.StdEncoding.EncodeToString(rekorBytes),
+		Bundle:          rekorBundle,
+	})
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(path, contents, 0600); err != nil {
+		return fmt.Errorf("create bundle file: %w", err)
+	}
+	ui.Infof(ctx, "Bundle wrote in the file %s", path)
+	return nil
+}
@@ -34,7 +34,6 @@ import (
 	"github.com/sigstore/cosign/v2/cmd/cosign/cli/rekor"
 	"github.com/sigstore/cosign/v2/cmd/cosign/cli/sign"
 	"github.com/sigstore/cosign/v2/internal/pkg/cosign/tsa"
-	"github.com/sigstore/cosign/v2/internal/pkg/cosign/tsa/client"
 	"github.com/sigstore/cosign/v2/pkg/cosign"
 	"github.com/sigstore/cosign/v2/pkg/cosign/attestation"
 	cbundle "github.com/sigstore/cosign/v2/pkg/cosign/bundle"
@@ -53,8 +52,9 @@ type AttestBlobCommand struct {
 
 	ArtifactHash string
 
-	PredicatePath string
-	PredicateType string
+	PredicatePath   string
+	PredicateSHA256 string
+	PredicateType   string
 
 	TlogUpload bool
 	Timeout    time.Duration
@@ -111,7 +111,7 @@ func (c *AttestBlobCommand) Exec(ctx context.Context, artifactPath string) error
 		hexDigest = c.ArtifactHash
 	}
 
-	predicate, err := predicateReader(c.PredicatePath)
+	predicate, err := predicateReader(ctx, c.PredicatePath, c.PredicateSHA256)
 	if err != nil {
 		return fmt.Errorf("getting predicate reader: %w", err)
 	}
@@ -148,7 +148,7 @@ func (c *AttestBlobCommand) Exec(ctx context.Context, artifactPath string) error
 
 	var rfc3161Timestamp *cbundle.RFC3161Timestamp
 	if c.TSAServerURL != "" {
-		respBytes, err := tsa.GetTimestampedSignature(sig, client.NewTSAClient(c.TSAServerURL))
+		respBytes, err := tsa.GetTimestampedSignature(sig, tsaClient(c.KeyOpts))
 		if err != nil {
 			return err
 		}
@@ -15,21 +15,94 @@
 package attest
 
 import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"fmt"
 	"io"
+	"net/http"
 	"os"
+	"strings"
+
+	"github.com/sigstore/cosign/v2/cmd/cosign/cli/options"
+	tsaclient "github.com/sigstore/cosign/v2/internal/pkg/cosign/tsa/client"
+	"github.com/sigstore/cosign/v2/internal/ui"
 )
 
-func predicateReader(predicatePath string) (io.ReadCloser, error) {
-	if predicatePath == "-" {
+// predicateReader opens the predicate at predicatePath, which is a file, an
+// http(s):// URL or - for standard input. When wantSHA256 is set, the
+// predicate is read in full and must match this hex-encoded digest.
+func predicateReader(ctx context.Context, predicatePath, wantSHA256 string) (io.ReadCloser, error) {
+	var rc io.ReadCloser
+	switch {
+	case predicatePath == "-":
 		fmt.Fprintln(os.Stderr, "Using payload from: standard input")
-		return os.Stdin, nil
+		rc = os.Stdin
+	case isURL(predicatePath):
+		fmt.Fprintln(os.Stderr, "Using payload from:", predicatePath)
+		if wantSHA256 == "" {
+			ui.Warnf(ctx, "the predicate at %s is not pinned, set --predicate-sha256 to make sure it is the one expected", predicatePath)
+		}
+		body, err := fetchPredicate(ctx, predicatePath)
+		if err != nil {
+			return nil, err
+		}
+		rc = body
+	default:
+		fmt.Fprintln(os.Stderr, "Using payload from:", predicatePath)
+		f, err := os.Open(predicatePath)
+		if err != nil {
+			return nil, err
+		}
+		rc = f
+	}
+	if wantSHA256 == "" {
+		return rc, nil
 	}
 
-	fmt.Fprintln(os.Stderr, "Using payload from:", predicatePath)
-	f, err := os.Open(predicatePath)
+	defer rc.Close()
+	predicate, err := io.ReadAll(rc)
+	if err != nil {
+		return nil, fmt.Errorf("reading predicate: %w", err)
+	}
+	sum := sha256.Sum256(predicate)
+	if got := hex.EncodeToString(sum[:]); !strings.EqualFold(got, wantSHA256) {
+		return nil, fmt.Errorf("predicate digest sha256:%s does not match --predicate-sha256 %s", got, wantSHA256)
+	}
+	return io.NopCloser(bytes.NewReader(predicate)), nil
+}
+
+func isURL(path string) bool {
+	return strings.HasPrefix(path, "https://") || strings.HasPrefix(path, "http://")
+}
+
+func fetchPredicate(ctx context.Context, url string) (io.ReadCloser, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
 	if err != nil {
 		return nil, err
 	}
-	return f, nil
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("fetching predicate: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		resp.Body.Close()
+		return nil, fmt.Errorf("fetching predicate: %s returned %s", url, resp.Status)
+	}
+	return resp.Body, nil
+}
+
+// tsaClient returns the client of the TSA at ko.TSAServerURL, connecting to
+// it with mTLS when a client certificate or CA is set.
+func tsaClient(ko options.KeyOpts) tsaclient.TimestampAuthorityClient {
+	if ko.TSAClientCACert == "" && ko.TSAClientCert == "" { // no mTLS params or custom CA
+		return tsaclient.NewTSAClient(ko.TSAServerURL)
+	}
+	return tsaclient.NewTSAClientMTLS(ko.TSAServerURL,
+		ko.TSAClientCACert,
+		ko.TSAClientCert,
+		ko.TSAClientKey,
+		ko.TSAServerName,
+	)
 }
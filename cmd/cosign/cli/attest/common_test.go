@@ -15,17 +15,36 @@
 package attest
 
 import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
 	"os"
 	"path"
 	"testing"
 
+	"github.com/sigstore/cosign/v2/cmd/cosign/cli/options"
+	tsaclient "github.com/sigstore/cosign/v2/internal/pkg/cosign/tsa/client"
 	"github.com/stretchr/testify/require"
 )
 
+// payloadSHA256 is the SHA-256 digest of "payload".
+const payloadSHA256 = "239f59ed55e737c77147cf55ad0c1b030b6d7ee748a7426952f9b852d5a935e5"
+
 func TestPredicateReader(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/payload.json" {
+			http.NotFound(w, r)
+			return
+		}
+		_, _ = w.Write([]byte("payload"))
+	}))
+	defer srv.Close()
+
 	cases := []struct {
 		name       string
 		path       string
+		sha256     string
 		wantErr    bool
 		wantStdin  bool
 		createFile bool
@@ -45,6 +64,39 @@ func TestPredicateReader(t *testing.T) {
 			path:    "payload.json",
 			wantErr: true,
 		},
+		{
+			name:       "pinned file",
+			path:       "payload.json",
+			sha256:     payloadSHA256,
+			createFile: true,
+		},
+		{
+			name:       "pinned file mismatch",
+			path:       "payload.json",
+			sha256:     "0000000000000000000000000000000000000000000000000000000000000000",
+			createFile: true,
+			wantErr:    true,
+		},
+		{
+			name: "url",
+			path: srv.URL + "/payload.json",
+		},
+		{
+			name:   "pinned url",
+			path:   srv.URL + "/payload.json",
+			sha256: payloadSHA256,
+		},
+		{
+			name:    "pinned url mismatch",
+			path:    srv.URL + "/payload.json",
+			sha256:  "0000000000000000000000000000000000000000000000000000000000000000",
+			wantErr: true,
+		},
+		{
+			name:    "url not found",
+			path:    srv.URL + "/missing.json",
+			wantErr: true,
+		},
 	}
 
 	for _, tc := range cases {
@@ -56,7 +108,7 @@ func TestPredicateReader(t *testing.T) {
 				require.NoError(t, err)
 			}
 
-			got, err := predicateReader(pf)
+			got, err := predicateReader(context.Background(), pf, tc.sha256)
 			if err == nil {
 				defer got.Close()
 			}
@@ -70,9 +122,33 @@ func TestPredicateReader(t *testing.T) {
 
 			if tc.wantStdin {
 				require.Same(t, os.Stdin, got)
-			} else {
-				require.NotSame(t, os.Stdin, got)
+				return
 			}
+			require.NotSame(t, os.Stdin, got)
+			b, err := io.ReadAll(got)
+			require.NoError(t, err)
+			require.Equal(t, "payload", string(b))
 		})
 	}
 }
+
+func TestTSAClient(t *testing.T) {
+	ko := options.KeyOpts{TSAServerURL: "https://tsa.example/api/v1/timestamp"}
+	got, ok := tsaClient(ko).(*tsaclient.TimestampAuthorityClientImpl)
+	require.True(t, ok)
+	require.Equal(t, ko.TSAServerURL, got.URL)
+	require.Empty(t, got.CACert)
+	require.Empty(t, got.Cert)
+
+	ko.TSAClientCACert = "ca.pem"
+	ko.TSAClientCert = "cert.pem"
+	ko.TSAClientKey = "key.pem"
+	ko.TSAServerName = "tsa.example"
+	got, ok = tsaClient(ko).(*tsaclient.TimestampAuthorityClientImpl)
+	require.True(t, ok)
+	require.Equal(t, ko.TSAServerURL, got.URL)
+	require.Equal(t, "ca.pem", got.CACert)
+	require.Equal(t, "cert.pem", got.Cert)
+	require.Equal(t, "key.pem", got.Key)
+	require.Equal(t, "tsa.example", got.ServerName)
+}
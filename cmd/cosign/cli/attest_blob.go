@@ -67,8 +67,16 @@ func AttestBlob() *cobra.Command {
 				OIDCClientID:             o.OIDC.ClientID,
 				OIDCClientSecret:         oidcClientSecret,
 				OIDCRedirectURL:          o.OIDC.RedirectURL,
+				OIDCScopes:               o.OIDC.Scopes,
 				OIDCProvider:             o.OIDC.Provider,
+				FulcioAuthFlow:           o.OIDC.Flow,
+				SPIFFESocket:             o.OIDC.SPIFFESocket,
+				OutputKey:                o.OutputKey,
 				SkipConfirmation:         o.SkipConfirmation,
+				TSAClientCACert:          o.TSAClientCACert,
+				TSAClientCert:            o.TSAClientCert,
+				TSAClientKey:             o.TSAClientKey,
+				TSAServerName:            o.TSAServerName,
 				TSAServerURL:             o.TSAServerURL,
 				RFC3161TimestampPath:     o.RFC3161TimestampPath,
 				BundlePath:               o.BundlePath,
@@ -81,6 +89,7 @@ func AttestBlob() *cobra.Command {
 				TlogUpload:        o.TlogUpload,
 				PredicateType:     o.Predicate.Type,
 				PredicatePath:     o.Predicate.Path,
+				PredicateSHA256:   o.Predicate.SHA256,
 				OutputSignature:   o.OutputSignature,
 				OutputAttestation: o.OutputAttestation,
 				OutputCertificate: o.OutputCertificate,
@@ -0,0 +1,60 @@
+//
+// Copyright 2023 The Sigstore Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cli
+
+import (
+	"github.com/spf13/cobra"
+
+	"github.com/sigstore/cosign/v2/cmd/cosign/cli/attestation"
+	"github.com/sigstore/cosign/v2/cmd/cosign/cli/options"
+)
+
+func Attestation() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "attestation",
+		Short: "Provides utilities for managing the attestations attached to an image",
+	}
+
+	cmd.AddCommand(
+		attestationRemove(),
+	)
+
+	return cmd
+}
+
+func attestationRemove() *cobra.Command {
+	o := &options.AttestationRemoveOptions{}
+
+	cmd := &cobra.Command{
+		Use:   "rm",
+		Short: "Remove the attestations of a predicate type from the supplied container image",
+		Example: `  cosign attestation rm --type <TYPE> <IMAGE>
+
+  # remove stale SLSA provenance attestations without prompting
+  cosign attestation rm --type slsaprovenance -f <IMAGE>
+
+  # remove attestations with a custom predicate type URI
+  cosign attestation rm --type https://example.com/predicate/v1 <IMAGE>`,
+		Args:             cobra.ExactArgs(1),
+		PersistentPreRun: options.BindViper,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return attestation.RemoveCmd(cmd.Context(), o.Registry, o.Predicate.Type, args[0], o.Force)
+		},
+	}
+
+	o.AddFlags(cmd)
+	return cmd
+}
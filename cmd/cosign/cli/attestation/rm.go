@@ -0,0 +1,108 @@
+//
+// Copyright 2023 The Sigstore Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package attestation
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/google/go-containerregistry/pkg/name"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
+
+	"github.com/sigstore/cosign/v2/cmd/cosign/cli/options"
+	"github.com/sigstore/cosign/v2/internal/ui"
+	cremote "github.com/sigstore/cosign/v2/pkg/cosign/remote"
+	"github.com/sigstore/cosign/v2/pkg/oci"
+	"github.com/sigstore/cosign/v2/pkg/oci/mutate"
+	ociremote "github.com/sigstore/cosign/v2/pkg/oci/remote"
+)
+
+// RemoveCmd removes the attestations with the given predicate type from
+// imageRef, keeping the attestations of every other type.
+func RemoveCmd(ctx context.Context, regOpts options.RegistryOptions, predicateType, imageRef string, force bool) error {
+	predicateURI, err := options.ParsePredicateType(predicateType)
+	if err != nil {
+		return err
+	}
+	if !force {
+		ui.Warnf(ctx, "this will remove all attestations with predicate type %s from the image", predicateURI)
+		if err := ui.ConfirmContinue(ctx); err != nil {
+			return err
+		}
+	}
+
+	ref, err := name.ParseReference(imageRef, regOpts.NameOptions()...)
+	if err != nil {
+		return err
+	}
+	ociremoteOpts, err := regOpts.ClientOpts(ctx)
+	if err != nil {
+		return err
+	}
+
+	se, err := ociremote.SignedEntity(ref, ociremoteOpts...)
+	if err != nil {
+		return err
+	}
+	atts, err := se.Attestations()
+	if err != nil {
+		return err
+	}
+	remaining, removed, err := cremote.RemoveAttestations(atts, predicateURI)
+	if err != nil {
+		return err
+	}
+	if removed == 0 {
+		ui.Infof(ctx, "No attestations with predicate type %s found on %s", predicateURI, imageRef)
+		return nil
+	}
+
+	kept, err := remaining.Get()
+	if err != nil {
+		return err
+	}
+	if len(kept) == 0 {
+		// Nothing is left to publish, drop the attestation image altogether.
+		attRef, err := ociremote.AttestationTag(ref, ociremoteOpts...)
+		if err != nil {
+			return err
+		}
+		if err := remote.Delete(attRef, regOpts.GetRegistryClientOpts(ctx)...); err != nil {
+			return fmt.Errorf("deleting %s: %w", attRef, err)
+		}
+	} else {
+		replaced, err := mutate.ReplaceSignatures(remaining)
+		if err != nil {
+			return err
+		}
+		if err := ociremote.WriteAttestations(ref.Context(), &replacedAttestations{SignedEntity: se, atts: replaced}, ociremoteOpts...); err != nil {
+			return err
+		}
+	}
+
+	ui.Infof(ctx, "Removed %d attestation(s) with predicate type %s from %s", removed, predicateURI, imageRef)
+	return nil
+}
+
+// replacedAttestations overrides the attestations of a signed entity.
+type replacedAttestations struct {
+	oci.SignedEntity
+	atts oci.Signatures
+}
+
+func (r *replacedAttestations) Attestations() (oci.Signatures, error) {
+	return r.atts, nil
+}
@@ -18,9 +18,7 @@ package cli
 import (
 	"context"
 	"errors"
-	"fmt"
 	"net/http"
-	"os"
 
 	"github.com/google/go-containerregistry/pkg/name"
 	"github.com/google/go-containerregistry/pkg/v1/remote"
@@ -36,13 +34,16 @@ func Clean() *cobra.Command {
 	c := &options.CleanOptions{}
 
 	cmd := &cobra.Command{
-		Use:              "clean",
-		Short:            "Remove all signatures from an image.",
-		Example:          "  cosign clean <IMAGE>",
+		Use:   "clean",
+		Short: "Remove signatures, attestations or SBOMs from an image.",
+		Example: `  cosign clean <IMAGE>
+
+  # list the attestations that would be removed, without removing them
+  cosign clean --type attestation --dry-run <IMAGE>`,
 		Args:             cobra.ExactArgs(1),
 		PersistentPreRun: options.BindViper,
 		RunE: func(cmd *cobra.Command, args []string) error {
-			return CleanCmd(cmd.Context(), c.Registry, c.CleanType, args[0], c.Force)
+			return CleanCmd(cmd.Context(), c.Registry, c.CleanType, args[0], c.Force, c.DryRun)
 		},
 	}
 
@@ -50,8 +51,8 @@ func Clean() *cobra.Command {
 	return cmd
 }
 
-func CleanCmd(ctx context.Context, regOpts options.RegistryOptions, cleanType options.CleanType, imageRef string, force bool) error {
-	if !force {
+func CleanCmd(ctx context.Context, regOpts options.RegistryOptions, cleanType options.CleanType, imageRef string, force, dryRun bool) error {
+	if !force && !dryRun {
 		ui.Warnf(ctx, prompt(cleanType))
 		if err := ui.ConfirmContinue(ctx); err != nil {
 			return err
@@ -94,6 +95,17 @@ func CleanCmd(ctx context.Context, regOpts options.RegistryOptions, cleanType op
 	}
 
 	for _, t := range cleanTags {
+		if dryRun {
+			if _, err := remote.Head(t, remoteOpts...); err != nil {
+				var te *transport.Error
+				if !errors.As(err, &te) || te.StatusCode != http.StatusNotFound {
+					ui.Warnf(ctx, "could not check %s from %s: %v", t, imageRef, err)
+				}
+				continue
+			}
+			ui.Infof(ctx, "Would remove %s from %s", t, imageRef)
+			continue
+		}
 		if err := remote.Delete(t, remoteOpts...); err != nil {
 			var te *transport.Error
 			if errors.As(err, &te) && te.StatusCode == http.StatusNotFound { //nolint: revive
@@ -101,10 +113,10 @@ func CleanCmd(ctx context.Context, regOpts options.RegistryOptions, cleanType op
 				// respond with a 404, which shouldn't be considered an
 				// error.
 			} else {
-				fmt.Fprintf(os.Stderr, "could not delete %s from %s\n: %v\n", t, imageRef, err)
+				ui.Warnf(ctx, "could not delete %s from %s: %v", t, imageRef, err)
 			}
 		} else {
-			fmt.Fprintf(os.Stderr, "Removed %s from %s\n", t, imageRef)
+			ui.Infof(ctx, "Removed %s from %s", t, imageRef)
 		}
 	}
 
@@ -22,11 +22,13 @@ import (
 	"github.com/google/go-containerregistry/pkg/logs"
 	"github.com/spf13/cobra"
 	"github.com/spf13/pflag"
+	"go.opentelemetry.io/otel/trace"
 	"sigs.k8s.io/release-utils/version"
 
 	cranecmd "github.com/google/go-containerregistry/cmd/crane/cmd"
 	"github.com/sigstore/cosign/v2/cmd/cosign/cli/options"
 	"github.com/sigstore/cosign/v2/cmd/cosign/cli/templates"
+	"github.com/sigstore/cosign/v2/internal/ui"
 	cobracompletefig "github.com/withfig/autocomplete-tools/integrations/cobra"
 )
 
@@ -58,8 +60,24 @@ func New() *cobra.Command {
 	)
 
 	cmd := &cobra.Command{
-		Use:               "cosign",
-		Short:             "A tool for Container Signing, Verification and Storage in an OCI registry.",
+		Use:   "cosign",
+		Short: "A tool for Container Signing, Verification and Storage in an OCI registry.",
+		Long: `A tool for Container Signing, Verification and Storage in an OCI registry.
+
+The flags that are not set on the command line default to, in order of
+precedence, their COSIGN_ environment variable, e.g. COSIGN_REKOR_URL for
+--rekor-url, the section of the command in the configuration file, and the
+top level keys of the configuration file. The configuration file is
+~/.cosign/config.yaml, or the YAML file of COSIGN_CONFIG, e.g.:
+
+  rekor-url: https://rekor.example.com
+  fulcio-url: https://fulcio.example.com
+  verify:
+    certificate-oidc-issuer: https://token.actions.githubusercontent.com
+    output: json
+  dockerfile:
+    verify:
+      base-image-only: true`,
 		DisableAutoGenTag: true,
 		SilenceUsage:      true, // Don't show usage on errors
 		PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
@@ -74,10 +92,25 @@ func New() *cobra.Command {
 				cmd.SetOut(out)
 			}
 
-			if ro.Verbose {
-				logs.Debug.SetOutput(os.Stderr)
+			format, err := ui.ParseFormat(ro.LogFormat)
+			if err != nil {
+				return err
+			}
+			level := ui.LevelInfo
+			if ro.Verbose || ro.Debug {
+				level = ui.LevelDebug
+			}
+			ui.Configure(level, format)
+			if ro.Debug {
+				logs.Debug.SetOutput(ui.DebugWriter())
 			}
 
+			if err := options.ConfigureTransports(ro); err != nil {
+				return err
+			}
+
+			trace.SpanFromContext(cmd.Context()).SetName(cmd.CommandPath())
+
 			return nil
 		},
 		PersistentPostRun: func(cmd *cobra.Command, args []string) {
@@ -95,29 +128,39 @@ func New() *cobra.Command {
 	cmd.AddCommand(Attach())
 	cmd.AddCommand(Attest())
 	cmd.AddCommand(AttestBlob())
+	cmd.AddCommand(Attestation())
 	cmd.AddCommand(Clean())
+	cmd.AddCommand(Commands())
 	cmd.AddCommand(Tree())
 	cmd.AddCommand(Completion())
 	cmd.AddCommand(Copy())
 	cmd.AddCommand(Dockerfile())
 	cmd.AddCommand(Download())
 	cmd.AddCommand(Generate())
+	cmd.AddCommand(GenerateAttestation())
 	cmd.AddCommand(GenerateKeyPair())
 	cmd.AddCommand(ImportKeyPair())
 	cmd.AddCommand(Initialize())
 	cmd.AddCommand(Load())
 	cmd.AddCommand(Manifest())
+	cmd.AddCommand(Monitor())
 	cmd.AddCommand(PIVTool())
 	cmd.AddCommand(PKCS11Tool())
+	cmd.AddCommand(Policy())
 	cmd.AddCommand(PublicKey())
+	cmd.AddCommand(Revocation())
 	cmd.AddCommand(Save())
+	cmd.AddCommand(Serve())
 	cmd.AddCommand(Sign())
 	cmd.AddCommand(SignBlob())
+	cmd.AddCommand(SignCommit())
 	cmd.AddCommand(Upload())
 	cmd.AddCommand(Verify())
 	cmd.AddCommand(VerifyAttestation())
 	cmd.AddCommand(VerifyBlob())
 	cmd.AddCommand(VerifyBlobAttestation())
+	cmd.AddCommand(VerifyCommit())
+	cmd.AddCommand(VerifyTree())
 	cmd.AddCommand(Triangulate())
 	cmd.AddCommand(Env())
 	cmd.AddCommand(version.WithFont("starwars"))
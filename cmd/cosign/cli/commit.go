@@ -0,0 +1,173 @@
+//
+// Copyright 2023 The Sigstore Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cli
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"github.com/sigstore/cosign/v2/cmd/cosign/cli/commit"
+	"github.com/sigstore/cosign/v2/cmd/cosign/cli/generate"
+	"github.com/sigstore/cosign/v2/cmd/cosign/cli/options"
+	"github.com/sigstore/cosign/v2/cmd/cosign/cli/verify"
+	"github.com/sigstore/cosign/v2/internal/ui"
+)
+
+func SignCommit() *cobra.Command {
+	o := &options.SignCommitOptions{}
+
+	cmd := &cobra.Command{
+		Use:   "sign-commit",
+		Short: "Sign Git commits and tags, recording the signatures in the transparency log",
+		Long: `Sign Git commits and tags, recording the signatures in the transparency log.
+
+The raw Git object of each commit or tag is signed, keylessly unless --key is
+set, and the signature is uploaded to Rekor like the signatures of images and
+blobs. Its bundle, the one 'cosign sign-blob --bundle' writes, is stored in
+the Git note of the commit or tag in --notes-ref, so that signing doesn't
+rewrite history. Push the notes along with the commits:
+
+  git push origin refs/notes/cosign`,
+		Example: `  cosign sign-commit [--key <key path>|<kms uri>] [<commit or tag>...]
+
+  # sign the current commit keylessly
+  cosign sign-commit
+
+  # sign a tag with a key
+  cosign sign-commit --key cosign.key v1.0.0`,
+		Args:             cobra.ArbitraryArgs,
+		PersistentPreRun: options.BindViper,
+		PreRunE: func(cmd *cobra.Command, args []string) error {
+			if options.NOf(o.Key, o.SecurityKey.Use) > 1 {
+				return &options.KeyParseError{}
+			}
+			return nil
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			oidcClientSecret, err := o.OIDC.ClientSecret()
+			if err != nil {
+				return err
+			}
+			ko := options.KeyOpts{
+				KeyRef:                         o.Key,
+				PassFunc:                       generate.GetPass,
+				Sk:                             o.SecurityKey.Use,
+				Slot:                           o.SecurityKey.Slot,
+				FulcioURL:                      o.Fulcio.URL,
+				IDToken:                        o.Fulcio.IdentityToken,
+				InsecureSkipFulcioVerify:       o.Fulcio.InsecureSkipFulcioVerify,
+				RekorURL:                       o.Rekor.URL,
+				OIDCIssuer:                     o.OIDC.Issuer,
+				OIDCClientID:                   o.OIDC.ClientID,
+				OIDCClientSecret:               oidcClientSecret,
+				OIDCRedirectURL:                o.OIDC.RedirectURL,
+				OIDCScopes:                     o.OIDC.Scopes,
+				OIDCDisableProviders:           o.OIDC.DisableAmbientProviders,
+				FulcioAuthFlow:                 o.OIDC.Flow,
+				SPIFFESocket:                   o.OIDC.SPIFFESocket,
+				SkipConfirmation:               o.SkipConfirmation,
+				IssueCertificateForExistingKey: o.IssueCertificate,
+			}
+
+			revs := args
+			if len(revs) == 0 {
+				revs = []string{"HEAD"}
+			}
+			for _, rev := range revs {
+				if err := commit.SignCmd(ro, ko, o.Git.Repo, o.Git.NotesRef, rev, o.TlogUpload); err != nil {
+					return fmt.Errorf("signing %s: %w", rev, err)
+				}
+			}
+			return nil
+		},
+	}
+
+	o.AddFlags(cmd)
+	return cmd
+}
+
+func VerifyCommit() *cobra.Command {
+	o := &options.VerifyCommitOptions{}
+
+	cmd := &cobra.Command{
+		Use:   "verify-commit",
+		Short: "Verify the signature of a Git commit or tag",
+		Long: `Verify the signature of a Git commit or tag made by 'cosign sign-commit'.
+
+The signature is read from the Git note of the commit or tag in --notes-ref,
+fetched with:
+
+  git fetch origin refs/notes/cosign:refs/notes/cosign
+
+and verified against the raw Git object as 'cosign verify-blob --bundle'
+verifies a blob, with the same flags.`,
+		Example: `  cosign verify-commit (--key <key path>|<key url>|<kms uri>)|(--certificate-identity <identity> --certificate-oidc-issuer <issuer>) [<commit or tag>]
+
+  # verify the current commit was signed by a GitHub Actions workflow of a repository
+  cosign verify-commit --certificate-identity-regexp '^https://github.com/myorg/myrepo/' --certificate-oidc-issuer https://token.actions.githubusercontent.com
+
+  # verify a tag was signed with a key
+  cosign verify-commit --key cosign.pub v1.0.0`,
+		Args:             cobra.MaximumNArgs(1),
+		PersistentPreRun: options.BindViper,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			rev := "HEAD"
+			if len(args) == 1 {
+				rev = args[0]
+			}
+			c := &commit.VerifyCmd{
+				VerifyBlobCmd: verify.VerifyBlobCmd{
+					KeyOpts: options.KeyOpts{
+						KeyRef:           o.Key,
+						Sk:               o.SecurityKey.Use,
+						Slot:             o.SecurityKey.Slot,
+						RekorURL:         o.Rekor.URL,
+						TSACertChainPath: o.CommonVerifyOptions.TSACertChainPath,
+					},
+					CertVerifyOptions:            o.CertVerify,
+					CertRef:                      o.CertVerify.Cert,
+					CertChain:                    o.CertVerify.CertChain,
+					CertGithubWorkflowTrigger:    o.CertVerify.CertGithubWorkflowTrigger,
+					CertGithubWorkflowSHA:        o.CertVerify.CertGithubWorkflowSha,
+					CertGithubWorkflowName:       o.CertVerify.CertGithubWorkflowName,
+					CertGithubWorkflowRepository: o.CertVerify.CertGithubWorkflowRepository,
+					CertGithubWorkflowRef:        o.CertVerify.CertGithubWorkflowRef,
+					IgnoreSCT:                    o.CertVerify.IgnoreSCT,
+					SCTRef:                       o.CertVerify.SCT,
+					Offline:                      o.CommonVerifyOptions.Offline,
+					IgnoreTlog:                   o.CommonVerifyOptions.IgnoreTlog,
+					RequireOnlineTlog:            o.CommonVerifyOptions.RequireOnlineTlog,
+					RekorCheckpoint:              o.CommonVerifyOptions.RekorCheckpoint,
+					RekorPublicKey:               o.CommonVerifyOptions.RekorPublicKey,
+					MaxSignatureAge:              o.CommonVerifyOptions.MaxSignatureAge,
+					Output:                       o.Output,
+				},
+				Repo:     o.Git.Repo,
+				NotesRef: o.Git.NotesRef,
+			}
+
+			ctx := cmd.Context()
+			if o.CommonVerifyOptions.IgnoreTlog {
+				ui.Warnf(ctx, fmt.Sprintf(ignoreTLogMessage, "commit"))
+			}
+			return c.Exec(ctx, rev)
+		},
+	}
+
+	o.AddFlags(cmd)
+	return cmd
+}
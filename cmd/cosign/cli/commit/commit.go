@@ -0,0 +1,214 @@
+//
+// Copyright 2023 The Sigstore Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package commit signs Git commits and tags keylessly, recording their
+// signatures in Rekor like the signatures of images and blobs. The signature
+// of an object is the bundle of 'cosign sign-blob --bundle' over the raw Git
+// object, stored as a Git note so that signing doesn't rewrite history.
+package commit
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/sigstore/cosign/v2/cmd/cosign/cli/options"
+	"github.com/sigstore/cosign/v2/cmd/cosign/cli/rekor"
+	"github.com/sigstore/cosign/v2/cmd/cosign/cli/sign"
+	"github.com/sigstore/cosign/v2/cmd/cosign/cli/verify"
+	"github.com/sigstore/cosign/v2/internal/ui"
+	"github.com/sigstore/cosign/v2/pkg/cosign"
+	cbundle "github.com/sigstore/cosign/v2/pkg/cosign/bundle"
+	"github.com/sigstore/sigstore/pkg/cryptoutils"
+	signatureoptions "github.com/sigstore/sigstore/pkg/signature/options"
+)
+
+// object is a Git commit or tag object.
+type object struct {
+	hash    string
+	typ     string
+	content []byte
+}
+
+// SignCmd signs the commit or tag rev of the repository repo, uploads the
+// signature to Rekor unless tlogUpload is false, and stores its bundle in
+// the note of rev in notesRef.
+func SignCmd(ro *options.RootOptions, ko options.KeyOpts, repo, notesRef, rev string, tlogUpload bool) error {
+	ctx, cancel := context.WithTimeout(context.Background(), ro.Timeout)
+	defer cancel()
+
+	obj, err := readObject(ctx, repo, rev)
+	if err != nil {
+		return err
+	}
+
+	sv, err := sign.SignerFromKeyOpts(ctx, "", "", ko)
+	if err != nil {
+		return err
+	}
+	defer sv.Close()
+
+	sig, err := sv.SignMessage(bytes.NewReader(obj.content), signatureoptions.WithContext(ctx))
+	if err != nil {
+		return fmt.Errorf("signing %s %s: %w", obj.typ, obj.hash, err)
+	}
+	digest := sha256.Sum256(obj.content)
+	b := cosign.LocalSignedPayload{
+		Base64Signature: base64.StdEncoding.EncodeToString(sig),
+		PayloadDigest:   "sha256:" + hex.EncodeToString(digest[:]),
+	}
+
+	signer, err := sv.Bytes(ctx)
+	if err != nil {
+		return err
+	}
+	// Like sign-blob, only certificates are written to the bundle: keys are
+	// passed to verify-commit with --key.
+	if certs, err := cryptoutils.UnmarshalCertificatesFromPEM(signer); err == nil && len(certs) == 1 {
+		b.Cert = base64.StdEncoding.EncodeToString(signer)
+		if len(sv.Chain) > 0 {
+			b.CertChain = base64.StdEncoding.EncodeToString(sv.Chain)
+		}
+	}
+
+	shouldUpload, err := sign.ShouldUploadToTlog(ctx, ko, nil, tlogUpload)
+	if err != nil {
+		return fmt.Errorf("upload to tlog: %w", err)
+	}
+	if shouldUpload {
+		rekorClient, err := rekor.NewClient(ko.RekorURL)
+		if err != nil {
+			return err
+		}
+		h := sha256.New()
+		h.Write(obj.content)
+		entry, err := cosign.TLogUpload(ctx, rekorClient, sig, h, signer)
+		if err != nil {
+			return err
+		}
+		ui.Infof(ctx, "tlog entry created with index: %d", *entry.LogIndex)
+		b.Bundle = cbundle.EntryToBundle(entry)
+	}
+
+	note, err := json.Marshal(b)
+	if err != nil {
+		return err
+	}
+	if _, err := git(ctx, repo, note, "notes", "--ref", notesRef, "add", "--force", "--file", "-", obj.hash); err != nil {
+		return err
+	}
+	ui.Infof(ctx, "Signed %s %s, push the signature with: git push origin %s", obj.typ, obj.hash, notesRef)
+	return nil
+}
+
+// VerifyCmd verifies the signature of a commit or tag stored in its note by
+// SignCmd, as verify-blob verifies a blob against its bundle.
+type VerifyCmd struct {
+	verify.VerifyBlobCmd
+	Repo     string
+	NotesRef string
+}
+
+// Exec verifies the signature of the commit or tag rev.
+func (c *VerifyCmd) Exec(ctx context.Context, rev string) error {
+	obj, err := readObject(ctx, c.Repo, rev)
+	if err != nil {
+		return err
+	}
+	note, err := git(ctx, c.Repo, nil, "notes", "--ref", c.NotesRef, "show", obj.hash)
+	if err != nil {
+		return fmt.Errorf("no signature of %s %s in %s: %w", obj.typ, obj.hash, c.NotesRef, err)
+	}
+
+	// verify-blob reads the object and the bundle from files, named after
+	// the object so that its results refer to it.
+	td, err := os.MkdirTemp("", "cosign-verify-commit")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(td)
+	objectPath := filepath.Join(td, obj.hash)
+	if err := os.WriteFile(objectPath, obj.content, 0600); err != nil {
+		return err
+	}
+	bundlePath := filepath.Join(td, obj.hash+".bundle")
+	if err := os.WriteFile(bundlePath, note, 0600); err != nil {
+		return err
+	}
+
+	v := c.VerifyBlobCmd
+	v.BundlePath = bundlePath
+	v.SigRef = ""
+	if err := v.Exec(ctx, objectPath); err != nil {
+		return fmt.Errorf("verifying %s %s: %w", obj.typ, obj.hash, err)
+	}
+	return nil
+}
+
+// readObject reads the commit or tag object rev of repo. Lightweight tags
+// resolve to the commit they point to.
+func readObject(ctx context.Context, repo, rev string) (*object, error) {
+	if rev == "" || strings.HasPrefix(rev, "-") {
+		return nil, fmt.Errorf("invalid revision %q", rev)
+	}
+	hash, err := git(ctx, repo, nil, "rev-parse", "--verify", "--quiet", rev+"^{object}")
+	if err != nil {
+		return nil, fmt.Errorf("unknown revision %s: %w", rev, err)
+	}
+	obj := &object{hash: strings.TrimSpace(string(hash))}
+	typ, err := git(ctx, repo, nil, "cat-file", "-t", obj.hash)
+	if err != nil {
+		return nil, err
+	}
+	obj.typ = strings.TrimSpace(string(typ))
+	if obj.typ != "commit" && obj.typ != "tag" {
+		return nil, fmt.Errorf("%s is a %s, only commits and tags are signed", rev, obj.typ)
+	}
+	if obj.content, err = git(ctx, repo, nil, "cat-file", obj.typ, obj.hash); err != nil {
+		return nil, err
+	}
+	return obj, nil
+}
+
+// git runs the git command args in repo with stdin as input, returning its
+// output.
+func git(ctx context.Context, repo string, stdin []byte, args ...string) ([]byte, error) {
+	cmd := exec.CommandContext(ctx, "git", append([]string{"-C", repo}, args...)...) //nolint:gosec
+	if stdin != nil {
+		cmd.Stdin = bytes.NewReader(stdin)
+	}
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return nil, fmt.Errorf("git %s: %w: %s", args[0], err, msg)
+		}
+		if errors.Is(err, exec.ErrNotFound) {
+			return nil, errors.New("git is required to sign and verify commits")
+		}
+		return nil, fmt.Errorf("git %s: %w", args[0], err)
+	}
+	return out, nil
+}
@@ -0,0 +1,147 @@
+//
+// Copyright 2023 The Sigstore Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package commit
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/sigstore/cosign/v2/cmd/cosign/cli/options"
+	"github.com/sigstore/cosign/v2/cmd/cosign/cli/verify"
+	"github.com/sigstore/cosign/v2/pkg/cosign"
+)
+
+const notesRef = "refs/notes/cosign"
+
+func pass(s string) cosign.PassFunc {
+	return func(_ bool) ([]byte, error) {
+		return []byte(s), nil
+	}
+}
+
+// newRepo returns a repository with a commit tagged v1 by an annotated tag,
+// and sets the identity of the commits of the test.
+func newRepo(t *testing.T) string {
+	t.Helper()
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+	for _, v := range []string{"GIT_AUTHOR", "GIT_COMMITTER"} {
+		t.Setenv(v+"_NAME", "test")
+		t.Setenv(v+"_EMAIL", "test@example.com")
+	}
+	repo := t.TempDir()
+	for _, args := range [][]string{
+		{"init", "--quiet"},
+		{"commit", "--quiet", "--allow-empty", "--message", "first"},
+		{"tag", "--annotate", "--message", "v1", "v1"},
+	} {
+		if _, err := git(context.Background(), repo, nil, args...); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return repo
+}
+
+func TestSignVerifyCommit(t *testing.T) {
+	repo := newRepo(t)
+	td := t.TempDir()
+	keys, err := cosign.GenerateKeyPair(pass("hunter2"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	otherKeys, err := cosign.GenerateKeyPair(pass("hunter2"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	priv, pub, otherPub := filepath.Join(td, "cosign.key"), filepath.Join(td, "cosign.pub"), filepath.Join(td, "other.pub")
+	for path, b := range map[string][]byte{priv: keys.PrivateBytes, pub: keys.PublicBytes, otherPub: otherKeys.PublicBytes} {
+		if err := os.WriteFile(path, b, 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// Signatures made with keys have no SCT, but CT log keys are loaded.
+	t.Setenv("SIGSTORE_CT_LOG_PUBLIC_KEY_FILE", pub)
+
+	ctx := context.Background()
+	ro := &options.RootOptions{Timeout: options.DefaultTimeout}
+	ko := options.KeyOpts{KeyRef: priv, PassFunc: pass("hunter2")}
+	verifier := func(key string) *VerifyCmd {
+		return &VerifyCmd{
+			VerifyBlobCmd: verify.VerifyBlobCmd{
+				KeyOpts:    options.KeyOpts{KeyRef: key},
+				IgnoreTlog: true,
+			},
+			Repo:     repo,
+			NotesRef: notesRef,
+		}
+	}
+
+	if err := verifier(pub).Exec(ctx, "HEAD"); err == nil || !strings.Contains(err.Error(), "no signature") {
+		t.Fatalf("Exec() of an unsigned commit error = %v, want no signature", err)
+	}
+
+	for _, rev := range []string{"HEAD", "v1"} {
+		if err := SignCmd(ro, ko, repo, notesRef, rev, false); err != nil {
+			t.Fatalf("SignCmd(%s): %v", rev, err)
+		}
+		if err := verifier(pub).Exec(ctx, rev); err != nil {
+			t.Fatalf("Exec(%s): %v", rev, err)
+		}
+		if err := verifier(otherPub).Exec(ctx, rev); err == nil {
+			t.Fatalf("Exec(%s) with another key succeeded", rev)
+		}
+	}
+
+	// The signature of a commit doesn't verify another commit.
+	note, err := git(ctx, repo, nil, "notes", "--ref", notesRef, "show", "HEAD")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := git(ctx, repo, nil, "commit", "--quiet", "--allow-empty", "--message", "second"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := git(ctx, repo, note, "notes", "--ref", notesRef, "add", "--file", "-", "HEAD"); err != nil {
+		t.Fatal(err)
+	}
+	if err := verifier(pub).Exec(ctx, "HEAD"); err == nil {
+		t.Fatal("Exec() with the signature of another commit succeeded")
+	}
+}
+
+func TestReadObject(t *testing.T) {
+	repo := newRepo(t)
+	ctx := context.Background()
+
+	for rev, want := range map[string]string{"HEAD": "commit", "v1": "tag"} {
+		obj, err := readObject(ctx, repo, rev)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if obj.typ != want {
+			t.Errorf("readObject(%s) type = %s, want %s", rev, obj.typ, want)
+		}
+	}
+	for _, rev := range []string{"HEAD^{tree}", "missing", "--all"} {
+		if _, err := readObject(ctx, repo, rev); err == nil {
+			t.Errorf("readObject(%s) succeeded", rev)
+		}
+	}
+}
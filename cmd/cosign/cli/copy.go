@@ -28,6 +28,9 @@ func Copy() *cobra.Command {
 	cmd := &cobra.Command{
 		Use:   "copy",
 		Short: "Copy the supplied container image and signatures.",
+		Long: `Copy the supplied container image together with its signatures, attestations and SBOMs, whether they are attached with tags or as OCI 1.1 referrers.
+
+The destination tag is updated last, once everything else was copied, so that it never points to an image whose signatures are missing, e.g. in registry promotion pipelines.`,
 		Example: `  cosign copy <source image> <destination image>
 
   # copy a container image and its signatures
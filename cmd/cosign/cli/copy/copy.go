@@ -19,7 +19,6 @@ import (
 	"errors"
 	"fmt"
 	"net/http"
-	"os"
 	"runtime"
 
 	"github.com/google/go-containerregistry/pkg/name"
@@ -27,6 +26,8 @@ import (
 	"github.com/google/go-containerregistry/pkg/v1/remote"
 	"github.com/google/go-containerregistry/pkg/v1/remote/transport"
 	"github.com/sigstore/cosign/v2/cmd/cosign/cli/options"
+	ociexperimental "github.com/sigstore/cosign/v2/internal/pkg/oci/remote"
+	"github.com/sigstore/cosign/v2/internal/ui"
 	"github.com/sigstore/cosign/v2/pkg/oci"
 	ociplatform "github.com/sigstore/cosign/v2/pkg/oci/platform"
 	ociremote "github.com/sigstore/cosign/v2/pkg/oci/remote"
@@ -99,9 +100,35 @@ func CopyCmd(ctx context.Context, regOpts options.RegistryOptions, srcImg, dstIm
 			return nil
 		}
 
+		// Copy the artifacts attached with OCI 1.1 referrers, their digests
+		// stay the same in the destination.
+		copyReferrers := func(attName string) error {
+			index, err := ociremote.Referrers(srcDigest, ociexperimental.ArtifactType(attName), ociRemoteOpts...)
+			if err != nil {
+				var te *transport.Error
+				if errors.As(err, &te) && te.StatusCode >= 400 && te.StatusCode < 500 {
+					// The registry serves neither the referrers API nor its
+					// fallback tag, so there is nothing to copy.
+					return nil
+				}
+				return err
+			}
+			for _, m := range index.Manifests {
+				src := srcRepoRef.Digest(m.Digest.String())
+				dst := dstRepoRef.Digest(m.Digest.String())
+				g.Go(func() error {
+					return remoteCopy(ctx, pusher, src, dst, force, remoteOpts...)
+				})
+			}
+			return nil
+		}
+
 		if err := copyTag(ociremote.SignatureTag); err != nil {
 			return err
 		}
+		if err := copyReferrers("sig"); err != nil {
+			return err
+		}
 
 		if sigOnly {
 			return nil
@@ -112,6 +139,11 @@ func CopyCmd(ctx context.Context, regOpts options.RegistryOptions, srcImg, dstIm
 				return err
 			}
 		}
+		for _, attName := range []string{"att", "sbom"} {
+			if err := copyReferrers(attName); err != nil {
+				return err
+			}
+		}
 
 		// Copy the entity itself.
 		g.Go(func() error {
@@ -175,6 +207,6 @@ func remoteCopy(ctx context.Context, pusher *remote.Pusher, src, dest name.Refer
 		}
 	}
 
-	fmt.Fprintf(os.Stderr, "Copying %s to %s...\n", src, dest)
+	ui.Infof(ctx, "Copying %s to %s...", src, dest)
 	return pusher.Push(ctx, dest, got)
 }
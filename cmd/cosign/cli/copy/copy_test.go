@@ -16,9 +16,23 @@ package copy
 
 import (
 	"context"
+	"io"
+	"log"
+	"net/http/httptest"
+	"net/url"
 	"testing"
 
+	"github.com/google/go-containerregistry/pkg/name"
+	"github.com/google/go-containerregistry/pkg/registry"
+	"github.com/google/go-containerregistry/pkg/v1/random"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
+
 	"github.com/sigstore/cosign/v2/cmd/cosign/cli/options"
+	ociexperimental "github.com/sigstore/cosign/v2/internal/pkg/oci/remote"
+	"github.com/sigstore/cosign/v2/pkg/oci/mutate"
+	ociremote "github.com/sigstore/cosign/v2/pkg/oci/remote"
+	"github.com/sigstore/cosign/v2/pkg/oci/signed"
+	"github.com/sigstore/cosign/v2/pkg/oci/static"
 )
 
 func TestCopyAttachmentTagPrefix(t *testing.T) {
@@ -50,3 +64,58 @@ func TestCopyPlatformOpt(t *testing.T) {
 		t.Fatal("failed to copy with platform")
 	}
 }
+
+func TestCopyReferrers(t *testing.T) {
+	ctx := context.Background()
+	s := httptest.NewServer(registry.New(registry.Logger(log.New(io.Discard, "", 0)), registry.WithReferrersSupport(true)))
+	defer s.Close()
+	u, err := url.Parse(s.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	img, err := random.Image(100, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	srcRef, err := name.ParseReference(u.Host + "/src:v1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := remote.Write(srcRef, img); err != nil {
+		t.Fatal(err)
+	}
+	h, err := img.Digest()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Attach an attestation as an OCI 1.1 referrer.
+	att, err := static.NewAttestation([]byte(`{"predicateType": "https://example.com/test"}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	se, err := mutate.AttachAttestationToImage(signed.Image(img), att)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := ociremote.WriteAttestationsExperimentalOCI(srcRef.Context().Digest(h.String()), se); err != nil {
+		t.Fatal(err)
+	}
+
+	dstRef, err := name.ParseReference(u.Host + "/dst:v1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := CopyCmd(ctx, options.RegistryOptions{}, srcRef.String(), dstRef.String(), false, false, ""); err != nil {
+		t.Fatalf("CopyCmd() = %v", err)
+	}
+
+	index, err := ociremote.Referrers(dstRef.Context().Digest(h.String()), ociexperimental.ArtifactType("att"))
+	if err != nil {
+		t.Fatalf("Referrers() = %v", err)
+	}
+	if got := len(index.Manifests); got != 1 {
+		t.Errorf("got %d attestation referrers in the destination, wanted 1", got)
+	}
+}
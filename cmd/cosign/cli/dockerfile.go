@@ -48,15 +48,25 @@ func dockerfileVerify() *cobra.Command {
 		Long: `Verify signature and annotations on images in a Dockerfile by checking claims
 against the transparency log.
 
-Shell-like variables in the Dockerfile's FROM lines will be substituted with values from the OS ENV.`,
+Every image of a FROM instruction is verified, once, except the stages built
+from a previous stage of a multi-stage Dockerfile and from scratch. Use
+--attestation-type to also verify their attestations, e.g. to fail a CI job when
+an unsigned base image, or one without provenance, is used.
+
+Shell-like variables in the Dockerfile's FROM lines are substituted with the
+values of the ARG instructions declared before the first FROM, overridden with
+--build-arg as by docker build, and then with values from the OS ENV.`,
 		Example: `  cosign dockerfile verify --key <key path>|<key url>|<kms uri> <path/to/Dockerfile>
 
   # verify cosign claims and signing certificates on the FROM images in the Dockerfile
   cosign dockerfile verify <path/to/Dockerfile>
 
-  # only verify the base image (the last FROM image)
+  # only verify the base image (the image of the last stage)
   cosign dockerfile verify --base-image-only <path/to/Dockerfile>
 
+  # verify the images built with a different version of the base image
+  cosign dockerfile verify --build-arg GO_VERSION=1.21 <path/to/Dockerfile>
+
   # additionally verify specified annotations
   cosign dockerfile verify -a key1=val1 -a key2=val2 <path/to/Dockerfile>
 
@@ -76,13 +86,22 @@ Shell-like variables in the Dockerfile's FROM lines will be substituted with val
   cosign dockerfile verify --key gcpkms://projects/[PROJECT]/locations/global/keyRings/[KEYRING]/cryptoKeys/[KEY] <path/to/Dockerfile>
 
   # verify images with public key stored in Hashicorp Vault
-  cosign dockerfile verify --key hashivault://[KEY] <path/to/Dockerfile>`,
+  cosign dockerfile verify --key hashivault://[KEY] <path/to/Dockerfile>
+
+  # verify the images signed by a GitHub workflow, and their SLSA provenance
+  cosign dockerfile verify --certificate-identity-regexp 'https://github.com/my-org/.*' \
+    --certificate-oidc-issuer https://token.actions.githubusercontent.com \
+    --attestation-type slsaprovenance --policy policy.cue <path/to/Dockerfile>`,
 		Args: cobra.ExactArgs(1),
 		RunE: func(cmd *cobra.Command, args []string) error {
 			annotations, err := o.AnnotationsMap()
 			if err != nil {
 				return err
 			}
+			buildArgs, err := o.BuildArgsMap()
+			if err != nil {
+				return err
+			}
 			v := &dockerfile.VerifyDockerfileCommand{
 				VerifyCommand: verify.VerifyCommand{
 					RegistryOptions:              o.Registry,
@@ -105,12 +124,71 @@ Shell-like variables in the Dockerfile's FROM lines will be substituted with val
 					Attachment:                   o.Attachment,
 					Annotations:                  annotations,
 					LocalImage:                   o.LocalImage,
+					ContinueOnError:              o.ContinueOnError,
+					Recursive:                    o.Recursive,
+					Platform:                     o.Platform,
+					OutputDigest:                 o.OutputDigest,
+					ThresholdPolicy:              o.ThresholdPolicy,
+					TrustPolicy:                  o.TrustPolicy,
+					PIVAttestation:               o.PIVAttestation,
 					Offline:                      o.CommonVerifyOptions.Offline,
 					TSACertChainPath:             o.CommonVerifyOptions.TSACertChainPath,
 					IgnoreTlog:                   o.CommonVerifyOptions.IgnoreTlog,
+					RequireOnlineTlog:            o.CommonVerifyOptions.RequireOnlineTlog,
+					RekorCheckpoint:              o.CommonVerifyOptions.RekorCheckpoint,
+					RekorPublicKey:               o.CommonVerifyOptions.RekorPublicKey,
+					MaxSignatureAge:              o.CommonVerifyOptions.MaxSignatureAge,
 					MaxWorkers:                   o.CommonVerifyOptions.MaxWorkers,
+					Record:                       o.Lockfile.Record,
+					Against:                      o.Lockfile.Against,
+					RevocationList:               o.Revocation.List,
+					RevocationListKey:            o.Revocation.Key,
+					RevocationListTTL:            o.Revocation.TTL,
 				},
-				BaseOnly: o.BaseImageOnly,
+				BaseOnly:  o.BaseImageOnly,
+				BuildArgs: buildArgs,
+			}
+
+			if len(o.AttestationTypes) > 0 {
+				v.VerifyAttestation = &verify.VerifyAttestationCommand{
+					RegistryOptions:              o.Registry,
+					CheckClaims:                  o.CheckClaims,
+					CertVerifyOptions:            o.CertVerify,
+					CertRef:                      o.CertVerify.Cert,
+					CertChain:                    o.CertVerify.CertChain,
+					CertGithubWorkflowTrigger:    o.CertVerify.CertGithubWorkflowTrigger,
+					CertGithubWorkflowSha:        o.CertVerify.CertGithubWorkflowSha,
+					CertGithubWorkflowName:       o.CertVerify.CertGithubWorkflowName,
+					CertGithubWorkflowRepository: o.CertVerify.CertGithubWorkflowRepository,
+					CertGithubWorkflowRef:        o.CertVerify.CertGithubWorkflowRef,
+					IgnoreSCT:                    o.CertVerify.IgnoreSCT,
+					SCTRef:                       o.CertVerify.SCT,
+					KeyRef:                       o.Key,
+					Sk:                           o.SecurityKey.Use,
+					Slot:                         o.SecurityKey.Slot,
+					RekorURL:                     o.Rekor.URL,
+					PredicateTypes:               o.AttestationTypes,
+					Policies:                     o.Policies,
+					PolicyEngine:                 options.PolicyEngineAuto,
+					Output:                       o.Output,
+					LocalImage:                   o.LocalImage,
+					ContinueOnError:              o.ContinueOnError,
+					Platform:                     o.Platform,
+					NameOptions:                  o.Registry.NameOptions(),
+					Offline:                      o.CommonVerifyOptions.Offline,
+					TSACertChainPath:             o.CommonVerifyOptions.TSACertChainPath,
+					IgnoreTlog:                   o.CommonVerifyOptions.IgnoreTlog,
+					RequireOnlineTlog:            o.CommonVerifyOptions.RequireOnlineTlog,
+					RekorCheckpoint:              o.CommonVerifyOptions.RekorCheckpoint,
+					RekorPublicKey:               o.CommonVerifyOptions.RekorPublicKey,
+					MaxSignatureAge:              o.CommonVerifyOptions.MaxSignatureAge,
+					MaxWorkers:                   o.CommonVerifyOptions.MaxWorkers,
+					RevocationList:               o.Revocation.List,
+					RevocationListKey:            o.Revocation.Key,
+					RevocationListTTL:            o.Revocation.TTL,
+				}
+			} else if len(o.Policies) > 0 {
+				return fmt.Errorf("--policy requires --attestation-type")
 			}
 
 			if o.CommonVerifyOptions.MaxWorkers == 0 {
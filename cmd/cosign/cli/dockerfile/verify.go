@@ -33,6 +33,12 @@ import (
 type VerifyDockerfileCommand struct {
 	verify.VerifyCommand
 	BaseOnly bool
+	// BuildArgs override the default values of the ARG instructions, as
+	// docker build --build-arg.
+	BuildArgs map[string]string
+	// VerifyAttestation, when set, also verifies the attestations of the
+	// images.
+	VerifyAttestation *verify.VerifyAttestationCommand
 }
 
 // Exec runs the verification command
@@ -47,51 +53,182 @@ func (c *VerifyDockerfileCommand) Exec(ctx context.Context, args []string) error
 	}
 	defer dockerfile.Close()
 
-	images, err := getImagesFromDockerfile(ctx, dockerfile)
+	images, base, err := getImagesFromDockerfile(ctx, dockerfile, c.BuildArgs)
 	if err != nil {
 		return fmt.Errorf("failed extracting images from Dockerfile: %w", err)
 	}
+	if c.BaseOnly {
+		images = nil
+		if base != "" {
+			images = []string{base}
+		}
+	}
 	if len(images) == 0 {
 		return errors.New("no images found in Dockerfile")
 	}
-	if c.BaseOnly {
-		images = images[len(images)-1:]
+	ui.Infof(ctx, "Extracted image(s): %s", strings.Join(images, ", "))
+
+	err = c.VerifyCommand.Exec(ctx, images)
+	if c.VerifyAttestation == nil || (err != nil && !c.ContinueOnError) {
+		return err
 	}
-	fmt.Fprintf(os.Stderr, "Extracted image(s): %s\n", strings.Join(images, ", "))
+	return errors.Join(err, c.VerifyAttestation.Exec(ctx, images))
+}
 
-	return c.VerifyCommand.Exec(ctx, images)
+// getImagesFromDockerfile returns the images of the FROM instructions of
+// dockerfile, once each, and the base image of its last stage. The stages
+// built from a previous stage and from scratch are skipped.
+//
+// Variables of FROM instructions are substituted with the ARG declared
+// before the first FROM, overridden by buildArgs, and then with the
+// environment.
+func getImagesFromDockerfile(ctx context.Context, dockerfile io.Reader, buildArgs map[string]string) ([]string, string, error) {
+	p := &dockerfileParser{
+		buildArgs: buildArgs,
+		args:      map[string]string{},
+		stages:    map[string]string{},
+	}
+	var (
+		images []string
+		seen   = map[string]bool{}
+		base   string
+	)
+	instructions, err := readInstructions(dockerfile)
+	if err != nil {
+		return nil, "", err
+	}
+	for _, line := range instructions {
+		fields := strings.Fields(line)
+		switch strings.ToUpper(fields[0]) {
+		case "ARG":
+			// ARG after the first FROM are scoped to their stage, and
+			// can't be used in FROM instructions.
+			if !p.inStage {
+				p.declareArgs(fields[1:])
+			}
+		case "FROM":
+			p.inStage = true
+			image, stage, err := p.from(fields[1:])
+			if err != nil {
+				return nil, "", err
+			}
+			switch resolved, ok := p.stages[strings.ToLower(image)]; {
+			case ok:
+				ui.Infof(ctx, "- stage %s ignored", image)
+				base = resolved
+			case image == "scratch":
+				ui.Infof(ctx, "- scratch image ignored")
+				base = ""
+			default:
+				if !seen[image] {
+					seen[image] = true
+					images = append(images, image)
+				}
+				base = image
+			}
+			if stage != "" {
+				p.stages[strings.ToLower(stage)] = base
+			}
+		}
+	}
+	return images, base, nil
 }
 
-func getImagesFromDockerfile(ctx context.Context, dockerfile io.Reader) ([]string, error) {
-	var images []string
+// readInstructions returns the instructions of dockerfile, joining the lines
+// continued with a backslash and skipping the comments and empty lines.
+func readInstructions(dockerfile io.Reader) ([]string, error) {
+	var (
+		instructions []string
+		current      strings.Builder
+	)
 	fileScanner := bufio.NewScanner(dockerfile)
 	for fileScanner.Scan() {
 		line := strings.TrimSpace(fileScanner.Text())
-		if strings.HasPrefix(strings.ToUpper(line), "FROM") {
-			switch image := getImageFromLine(line); image {
-			case "scratch":
-				ui.Infof(ctx, "- scratch image ignored")
-			default:
-				images = append(images, image)
-			}
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		if strings.HasSuffix(line, "\\") {
+			current.WriteString(strings.TrimSuffix(line, "\\"))
+			current.WriteString(" ")
+			continue
 		}
+		current.WriteString(line)
+		instructions = append(instructions, current.String())
+		current.Reset()
 	}
 	if err := fileScanner.Err(); err != nil {
 		return nil, err
 	}
-	return images, nil
+	if current.Len() > 0 {
+		instructions = append(instructions, current.String())
+	}
+	return instructions, nil
+}
+
+type dockerfileParser struct {
+	buildArgs map[string]string
+	// args are the values of the ARG declared before the first FROM.
+	args map[string]string
+	// stages are the base images of the named stages, by lowercase name.
+	stages  map[string]string
+	inStage bool
+}
+
+// declareArgs declares the ARG instruction of fields, e.g. VERSION=1.0.
+func (p *dockerfileParser) declareArgs(fields []string) {
+	for _, field := range fields {
+		name, value, hasDefault := strings.Cut(field, "=")
+		if v, ok := p.buildArgs[name]; ok {
+			p.args[name] = v
+		} else if hasDefault {
+			p.args[name] = p.expand(strings.Trim(value, `"'`))
+		}
+	}
+}
+
+// from returns the image and the stage name of the FROM instruction of
+// fields, e.g. --platform=linux/arm64 image AS stage.
+func (p *dockerfileParser) from(fields []string) (string, string, error) {
+	for len(fields) > 0 && strings.HasPrefix(fields[0], "--") {
+		fields = fields[1:]
+	}
+	if len(fields) == 0 {
+		return "", "", errors.New("FROM instruction without an image")
+	}
+	image := p.expand(fields[0])
+	if image == "" {
+		return "", "", fmt.Errorf("FROM %s: the image is empty after substituting its variables", fields[0])
+	}
+	var stage string
+	if len(fields) == 3 && strings.EqualFold(fields[1], "AS") {
+		stage = fields[2]
+	}
+	return image, stage, nil
 }
 
-func getImageFromLine(line string) string {
-	line = strings.TrimPrefix(line, "FROM") // Remove "FROM" prefix
-	line = os.ExpandEnv(line)               // Substitute templated vars
-	fields := strings.Fields(line)
-	for i := len(fields) - 1; i > 0; i-- {
-		// Remove the "AS" portion of line
-		if strings.EqualFold(fields[i], "AS") {
-			fields = fields[:i]
-			break
+// expand substitutes the $VAR, ${VAR}, ${VAR:-default} and ${VAR:+alternative}
+// variables of s.
+func (p *dockerfileParser) expand(s string) string {
+	return os.Expand(s, func(v string) string {
+		if name, def, ok := strings.Cut(v, ":-"); ok {
+			if value := p.lookup(name); value != "" {
+				return value
+			}
+			return def
+		}
+		if name, alt, ok := strings.Cut(v, ":+"); ok {
+			if p.lookup(name) != "" {
+				return alt
+			}
+			return ""
 		}
+		return p.lookup(v)
+	})
+}
+
+func (p *dockerfileParser) lookup(name string) string {
+	if v, ok := p.args[name]; ok {
+		return v
 	}
-	return fields[len(fields)-1] // The image should be the last portion of the line that remains
+	return os.Getenv(name)
 }
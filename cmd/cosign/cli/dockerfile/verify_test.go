@@ -26,7 +26,9 @@ func TestGetImagesFromDockerfile(t *testing.T) {
 		name         string
 		fileContents string
 		env          map[string]string
+		buildArgs    map[string]string
 		expected     []string
+		expectedBase string
 	}{
 		{
 			name:         "plain",
@@ -87,6 +89,39 @@ func TestGetImagesFromDockerfile(t *testing.T) {
 			},
 			expected: []string{"gcr.io/gauntlet/test/one", "gcr.io/gauntlet/test/two:latest", "gcr.io/gauntlet/test/runtime"},
 		},
+		{
+			name: "global args",
+			fileContents: `ARG REGISTRY=gcr.io
+			ARG VERSION=1.20
+			ARG IMAGE=${REGISTRY}/test/image:$VERSION
+			FROM $IMAGE
+			ARG VERSION=ignored
+			FROM ${REGISTRY}/test/${RUNTIME:-distroless}`,
+			buildArgs:    map[string]string{"VERSION": "1.21", "UNDECLARED": "ignored"},
+			expected:     []string{"gcr.io/test/image:1.21", "gcr.io/test/distroless"},
+			expectedBase: "gcr.io/test/distroless",
+		},
+		{
+			name: "stages",
+			fileContents: `# syntax=docker/dockerfile:1
+			FROM golang:1.21 AS build
+			RUN go build \
+			  -o /app
+			FROM build AS test
+			RUN go test ./...
+			FROM gcr.io/distroless/static AS runtime
+			FROM golang:1.21
+			FROM RUNTIME`,
+			expected:     []string{"golang:1.21", "gcr.io/distroless/static"},
+			expectedBase: "gcr.io/distroless/static",
+		},
+		{
+			name: "scratch",
+			fileContents: `FROM golang:1.21 AS build
+			FROM \
+			  scratch`,
+			expected: []string{"golang:1.21"},
+		},
 	}
 	for _, tc := range testCases {
 		t.Run(tc.name, func(t *testing.T) {
@@ -95,13 +130,16 @@ func TestGetImagesFromDockerfile(t *testing.T) {
 				defer os.Unsetenv(k)
 			}
 			ctx := context.Background()
-			got, err := getImagesFromDockerfile(ctx, strings.NewReader(tc.fileContents))
+			got, base, err := getImagesFromDockerfile(ctx, strings.NewReader(tc.fileContents), tc.buildArgs)
 			if err != nil {
 				t.Fatalf("getImagesFromDockerfile returned error: %v", err)
 			}
 			if !reflect.DeepEqual(tc.expected, got) {
 				t.Errorf("getImagesFromDockerfile returned %v, wanted %v", got, tc.expected)
 			}
+			if tc.expectedBase != "" && base != tc.expectedBase {
+				t.Errorf("getImagesFromDockerfile returned base %s, wanted %s", base, tc.expectedBase)
+			}
 		})
 	}
 }
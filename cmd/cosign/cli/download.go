@@ -89,9 +89,12 @@ func downloadAttestation() *cobra.Command {
 	ao := &options.AttestationDownloadOptions{}
 
 	cmd := &cobra.Command{
-		Use:              "attestation",
-		Short:            "Download in-toto attestations from the supplied container image",
-		Example:          "  cosign download attestation <image uri> [--predicate-type]",
+		Use:   "attestation",
+		Short: "Download in-toto attestations from the supplied container image",
+		Example: `  cosign download attestation <image uri> [--predicate-type]
+
+  # download the SLSA provenance attestations of an image to a directory
+  cosign download attestation --predicate-type slsaprovenance --output-dir <DIR> <image uri>`,
 		Args:             cobra.ExactArgs(1),
 		PersistentPreRun: options.BindViper,
 		RunE: func(cmd *cobra.Command, args []string) error {
@@ -17,11 +17,15 @@ package download
 
 import (
 	"context"
+	"crypto/sha256"
 	"encoding/json"
 	"fmt"
+	"os"
+	"path/filepath"
 
 	"github.com/google/go-containerregistry/pkg/name"
 	"github.com/sigstore/cosign/v2/cmd/cosign/cli/options"
+	"github.com/sigstore/cosign/v2/internal/ui"
 	"github.com/sigstore/cosign/v2/pkg/cosign"
 	"github.com/sigstore/cosign/v2/pkg/oci/platform"
 	ociremote "github.com/sigstore/cosign/v2/pkg/oci/remote"
@@ -60,6 +64,9 @@ func AttestationCmd(ctx context.Context, regOpts options.RegistryOptions, attOpt
 		return err
 	}
 
+	if attOptions.OutputDir != "" {
+		return writeAttestationFiles(ctx, attestations, attOptions.OutputDir)
+	}
 	for _, att := range attestations {
 		b, err := json.Marshal(att)
 		if err != nil {
@@ -69,3 +76,23 @@ func AttestationCmd(ctx context.Context, regOpts options.RegistryOptions, attOpt
 	}
 	return nil
 }
+
+// writeAttestationFiles writes each DSSE envelope to dir, naming the file
+// after the digest of its contents so repeated downloads are idempotent.
+func writeAttestationFiles(ctx context.Context, attestations []cosign.AttestationPayload, dir string) error {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return fmt.Errorf("creating output directory: %w", err)
+	}
+	for _, att := range attestations {
+		b, err := json.Marshal(att)
+		if err != nil {
+			return err
+		}
+		path := filepath.Join(dir, fmt.Sprintf("sha256-%x.json", sha256.Sum256(b)))
+		if err := os.WriteFile(path, b, 0600); err != nil {
+			return fmt.Errorf("writing attestation: %w", err)
+		}
+		ui.Infof(ctx, "Wrote attestation to %s", path)
+	}
+	return nil
+}
@@ -0,0 +1,59 @@
+//
+// Copyright 2023 The Sigstore Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package download
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/sigstore/cosign/v2/pkg/cosign"
+)
+
+func TestWriteAttestationFiles(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "attestations")
+	atts := []cosign.AttestationPayload{
+		{PayloadType: "application/vnd.in-toto+json", PayLoad: "Zmlyc3Q="},
+		{PayloadType: "application/vnd.in-toto+json", PayLoad: "c2Vjb25k"},
+	}
+
+	// Writing twice must not duplicate the files.
+	for i := 0; i < 2; i++ {
+		if err := writeAttestationFiles(context.Background(), atts, dir); err != nil {
+			t.Fatalf("writeAttestationFiles() = %v", err)
+		}
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != len(atts) {
+		t.Fatalf("got %d files, wanted %d", len(entries), len(atts))
+	}
+	for _, e := range entries {
+		b, err := os.ReadFile(filepath.Join(dir, e.Name()))
+		if err != nil {
+			t.Fatal(err)
+		}
+		var att cosign.AttestationPayload
+		if err := json.Unmarshal(b, &att); err != nil {
+			t.Errorf("%s is not an attestation envelope: %v", e.Name(), err)
+		}
+	}
+}
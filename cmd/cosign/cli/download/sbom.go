@@ -16,17 +16,24 @@
 package download
 
 import (
+	"bytes"
+	"compress/gzip"
 	"context"
 	"errors"
 	"fmt"
 	"io"
 	"os"
+	"strings"
 
 	"github.com/google/go-containerregistry/pkg/name"
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/types"
 	"github.com/sigstore/cosign/v2/cmd/cosign/cli/options"
+	"github.com/sigstore/cosign/v2/internal/ui"
 	"github.com/sigstore/cosign/v2/pkg/oci"
 	"github.com/sigstore/cosign/v2/pkg/oci/platform"
 	ociremote "github.com/sigstore/cosign/v2/pkg/oci/remote"
+	ctypes "github.com/sigstore/cosign/v2/pkg/types"
 )
 
 func SBOMCmd(
@@ -78,19 +85,68 @@ func SBOMCmd(
 	// "attach sbom" attaches a single static.NewFile
 	sboms := make([]string, 0, 1)
 
-	mt, err := file.FileMediaType()
-	if err != nil {
-		return nil, err
-	}
-
-	fmt.Fprintf(os.Stderr, "Found SBOM of media type: %s\n", mt)
-	sbom, err := file.Payload()
+	sbom, mt, err := sbomPayload(file)
 	if err != nil {
 		return nil, err
 	}
+	ui.Infof(ctx, "Found SBOM of media type: %s", mt)
 
 	sboms = append(sboms, string(sbom))
 	fmt.Fprint(out, string(sbom))
 
 	return sboms, nil
 }
+
+// sbomPayload returns the SBOM attached as file and its media type, checking
+// that the layer holding it matches its digest, and decompressing it when
+// the media type of the layer ends with +gzip.
+func sbomPayload(file oci.File) ([]byte, types.MediaType, error) {
+	layers, err := file.Layers()
+	if err != nil {
+		return nil, "", err
+	}
+	if len(layers) != 1 {
+		return nil, "", fmt.Errorf("expected exactly one layer in the SBOM attachment, got %d", len(layers))
+	}
+	layer := layers[0]
+	mt, err := layer.MediaType()
+	if err != nil {
+		return nil, "", err
+	}
+	want, err := layer.Digest()
+	if err != nil {
+		return nil, "", err
+	}
+
+	// Attachments are stored as they were attached, so the compressed stream
+	// of the layer is its raw content.
+	rc, err := layer.Compressed()
+	if err != nil {
+		return nil, "", err
+	}
+	defer rc.Close()
+	b, err := io.ReadAll(rc)
+	if err != nil {
+		return nil, "", err
+	}
+	got, _, err := v1.SHA256(bytes.NewReader(b))
+	if err != nil {
+		return nil, "", err
+	}
+	if got != want {
+		return nil, "", fmt.Errorf("SBOM layer has digest %s, expected %s", got, want)
+	}
+
+	if !strings.HasSuffix(string(mt), ctypes.GzipMediaTypeSuffix) {
+		return b, mt, nil
+	}
+	zr, err := gzip.NewReader(bytes.NewReader(b))
+	if err != nil {
+		return nil, "", fmt.Errorf("decompressing SBOM: %w", err)
+	}
+	defer zr.Close()
+	if b, err = io.ReadAll(zr); err != nil {
+		return nil, "", fmt.Errorf("decompressing SBOM: %w", err)
+	}
+	return b, types.MediaType(strings.TrimSuffix(string(mt), ctypes.GzipMediaTypeSuffix)), nil
+}
@@ -0,0 +1,79 @@
+// Copyright 2021 The Sigstore Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package download
+
+import (
+	"bytes"
+	"compress/gzip"
+	"testing"
+
+	"github.com/google/go-containerregistry/pkg/v1/types"
+
+	"github.com/sigstore/cosign/v2/pkg/oci/static"
+	ctypes "github.com/sigstore/cosign/v2/pkg/types"
+)
+
+func TestSBOMPayload(t *testing.T) {
+	const sbom = `{"spdxVersion": "SPDX-2.3"}`
+	var compressed bytes.Buffer
+	zw := gzip.NewWriter(&compressed)
+	if _, err := zw.Write([]byte(sbom)); err != nil {
+		t.Fatal(err)
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name      string
+		payload   []byte
+		mediaType types.MediaType
+		wantErr   bool
+	}{{
+		name:      "uncompressed",
+		payload:   []byte(sbom),
+		mediaType: ctypes.SPDXJSONMediaType,
+	}, {
+		name:      "gzip",
+		payload:   compressed.Bytes(),
+		mediaType: ctypes.SPDXJSONMediaType + ctypes.GzipMediaTypeSuffix,
+	}, {
+		name:      "invalid gzip",
+		payload:   []byte(sbom),
+		mediaType: ctypes.SPDXJSONMediaType + ctypes.GzipMediaTypeSuffix,
+		wantErr:   true,
+	}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			file, err := static.NewFile(tt.payload, static.WithLayerMediaType(tt.mediaType))
+			if err != nil {
+				t.Fatal(err)
+			}
+			got, mt, err := sbomPayload(file)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("sbomPayload() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if string(got) != sbom {
+				t.Errorf("sbomPayload() = %q, want %q", got, sbom)
+			}
+			if mt != ctypes.SPDXJSONMediaType {
+				t.Errorf("sbomPayload() media type = %s, want %s", mt, ctypes.SPDXJSONMediaType)
+			}
+		})
+	}
+}
@@ -20,27 +20,46 @@ import (
 	"crypto"
 	"crypto/x509"
 	"fmt"
+	"net/http"
 	"net/url"
 	"os"
 	"strings"
 
+	"github.com/coreos/go-oidc/v3/oidc"
 	"github.com/sigstore/cosign/v2/cmd/cosign/cli/options"
 	"github.com/sigstore/cosign/v2/cmd/cosign/cli/sign/privacy"
 	"github.com/sigstore/cosign/v2/internal/pkg/cosign/fulcio/fulcioroots"
+	"github.com/sigstore/cosign/v2/internal/tracing"
 	"github.com/sigstore/cosign/v2/internal/ui"
+	"github.com/sigstore/cosign/v2/pkg/cosign/env"
 	"github.com/sigstore/cosign/v2/pkg/providers"
+	"github.com/sigstore/cosign/v2/pkg/providers/spiffe"
 	"github.com/sigstore/fulcio/pkg/api"
 	"github.com/sigstore/sigstore/pkg/cryptoutils"
 	"github.com/sigstore/sigstore/pkg/oauthflow"
 	"github.com/sigstore/sigstore/pkg/signature"
+	"go.opentelemetry.io/otel/attribute"
 	"go.step.sm/crypto/jose"
+	"golang.org/x/oauth2"
 	"golang.org/x/term"
 )
 
+// OIDC flows getting the ID token sent to Fulcio, set with
+// options.KeyOpts.FulcioAuthFlow.
 const (
+	// FlowInteractive logs in with a browser.
+	FlowInteractive = "interactive"
+	// FlowDevice logs in with the OAuth 2.0 device authorization grant,
+	// printing a code to enter from another device, so it works on headless
+	// machines.
+	FlowDevice = "device"
+	// FlowToken uses the ID token supplied with --identity-token,
+	// SIGSTORE_ID_TOKEN or an ambient OIDC provider, e.g. of a CI system,
+	// without prompting.
+	FlowToken = "token"
+
+	// flowNormal is the former name of FlowInteractive.
 	flowNormal = "normal"
-	flowDevice = "device"
-	flowToken  = "token"
 )
 
 type oidcConnector interface {
@@ -48,11 +67,38 @@ type oidcConnector interface {
 }
 
 type realConnector struct {
-	flow oauthflow.TokenGetter
+	flow   oauthflow.TokenGetter
+	scopes []string
 }
 
 func (rf *realConnector) OIDConnect(url, clientID, secret, redirectURL string) (*oauthflow.OIDCIDToken, error) {
-	return oauthflow.OIDConnect(url, clientID, secret, redirectURL, rf.flow)
+	if _, ok := rf.flow.(*oauthflow.StaticTokenGetter); ok || len(rf.scopes) == 0 {
+		return oauthflow.OIDConnect(url, clientID, secret, redirectURL, rf.flow)
+	}
+	// oauthflow.OIDConnect always requests the openid and email scopes, which
+	// private issuers may not map to the claims Fulcio expects.
+	provider, err := oidc.NewProvider(context.Background(), url)
+	if err != nil {
+		return nil, err
+	}
+	return rf.flow.GetIDToken(provider, oauth2.Config{
+		ClientID:     clientID,
+		ClientSecret: secret,
+		Endpoint:     provider.Endpoint(),
+		Scopes:       oidcScopes(rf.scopes),
+		RedirectURL:  redirectURL,
+	})
+}
+
+// oidcScopes returns scopes, with the openid scope that makes the issuer
+// return an ID token.
+func oidcScopes(scopes []string) []string {
+	for _, s := range scopes {
+		if s == oidc.ScopeOpenID {
+			return scopes
+		}
+	}
+	return append([]string{oidc.ScopeOpenID}, scopes...)
 }
 
 func getCertForOauthID(sv signature.SignerVerifier, fc api.LegacyClient, connector oidcConnector, oidcIssuer, oidcClientID, oidcClientSecret, oidcRedirectURL string) (*api.CertificateResponse, error) {
@@ -86,20 +132,26 @@ func getCertForOauthID(sv signature.SignerVerifier, fc api.LegacyClient, connect
 }
 
 // GetCert returns the PEM-encoded signature of the OIDC identity returned as part of an interactive oauth2 flow plus the PEM-encoded cert chain.
-func GetCert(_ context.Context, sv signature.SignerVerifier, idToken, flow, oidcIssuer, oidcClientID, oidcClientSecret, oidcRedirectURL string, fClient api.LegacyClient) (*api.CertificateResponse, error) {
-	c := &realConnector{}
+// oidcScopes are requested with the ID token, openid and email when empty.
+func GetCert(ctx context.Context, sv signature.SignerVerifier, idToken, flow, oidcIssuer, oidcClientID, oidcClientSecret, oidcRedirectURL string, oidcScopes []string, fClient api.LegacyClient) (*api.CertificateResponse, error) {
+	c := &realConnector{scopes: oidcScopes}
 	switch flow {
-	case flowDevice:
+	case FlowDevice:
 		c.flow = oauthflow.NewDeviceFlowTokenGetterForIssuer(oidcIssuer)
-	case flowNormal:
+	case FlowInteractive, flowNormal:
 		c.flow = oauthflow.DefaultIDTokenGetter
-	case flowToken:
+	case FlowToken:
 		c.flow = &oauthflow.StaticTokenGetter{RawToken: idToken}
 	default:
 		return nil, fmt.Errorf("unsupported oauth flow: %s", flow)
 	}
 
-	return getCertForOauthID(sv, fClient, c, oidcIssuer, oidcClientID, oidcClientSecret, oidcRedirectURL)
+	// The Fulcio client doesn't take a context, so its requests are only
+	// traced by this span, which includes getting the OIDC token.
+	_, span := tracing.Start(ctx, "fulcio.GetCert", attribute.String("cosign.fulcio.flow", flow))
+	resp, err := getCertForOauthID(sv, fClient, c, oidcIssuer, oidcClientID, oidcClientSecret, oidcRedirectURL)
+	tracing.End(span, err)
+	return resp, err
 }
 
 type Signer struct {
@@ -115,13 +167,27 @@ func NewSigner(ctx context.Context, ko options.KeyOpts, signer signature.SignerV
 		return nil, fmt.Errorf("creating Fulcio client: %w", err)
 	}
 
+	switch ko.FulcioAuthFlow {
+	case "", FlowInteractive, FlowDevice, FlowToken, flowNormal:
+	default:
+		return nil, fmt.Errorf("unsupported OIDC flow %q, must be one of %s, %s or %s", ko.FulcioAuthFlow, FlowInteractive, FlowDevice, FlowToken)
+	}
+
 	idToken, err := idToken(ko.IDToken)
 	if err != nil {
 		return nil, fmt.Errorf("getting id token: %w", err)
 	}
+	// If token is not set in the options, get one from the SPIFFE Workload
+	// API or the provders, unless the user asked to log in.
+	useToken := ko.FulcioAuthFlow == "" || ko.FulcioAuthFlow == FlowToken
+	if idToken == "" && useToken && ko.SPIFFESocket != "" {
+		idToken, err = spiffe.FetchJWTSVID(ctx, ko.SPIFFESocket, "sigstore")
+		if err != nil {
+			return nil, fmt.Errorf("getting SPIFFE workload identity: %w", err)
+		}
+	}
 	var provider providers.Interface
-	// If token is not set in the options, get one from the provders
-	if idToken == "" && providers.Enabled(ctx) && !ko.OIDCDisableProviders {
+	if idToken == "" && useToken && providers.Enabled(ctx) && !ko.OIDCDisableProviders {
 		if ko.OIDCProvider != "" {
 			provider, err = providers.ProvideFrom(ctx, ko.OIDCProvider)
 			if err != nil {
@@ -136,19 +202,14 @@ func NewSigner(ctx context.Context, ko options.KeyOpts, signer signature.SignerV
 		}
 	}
 
+	flow, err := authFlow(ko.FulcioAuthFlow, idToken, term.IsTerminal(0))
+	if err != nil {
+		return nil, err
+	}
+
 	fmt.Fprintln(os.Stderr, "Retrieving signed certificate...")
 
-	var flow string
-	switch {
-	case ko.FulcioAuthFlow != "":
-		// Caller manually set flow option.
-		flow = ko.FulcioAuthFlow
-	case idToken != "":
-		flow = flowToken
-	case !term.IsTerminal(0):
-		fmt.Fprintln(os.Stderr, "Non-interactive mode detected, using device flow.")
-		flow = flowDevice
-	default:
+	if flow == FlowInteractive || flow == flowNormal {
 		var statementErr error
 		privacy.StatementOnce.Do(func() {
 			ui.Infof(ctx, privacy.Statement)
@@ -162,9 +223,8 @@ func NewSigner(ctx context.Context, ko options.KeyOpts, signer signature.SignerV
 		if statementErr != nil {
 			return nil, statementErr
 		}
-		flow = flowNormal
 	}
-	Resp, err := GetCert(ctx, signer, idToken, flow, ko.OIDCIssuer, ko.OIDCClientID, ko.OIDCClientSecret, ko.OIDCRedirectURL, fClient) // TODO, use the chain.
+	Resp, err := GetCert(ctx, signer, idToken, flow, ko.OIDCIssuer, ko.OIDCClientID, ko.OIDCClientSecret, ko.OIDCRedirectURL, ko.OIDCScopes, fClient) // TODO, use the chain.
 	if err != nil {
 		return nil, fmt.Errorf("retrieving cert: %w", err)
 	}
@@ -179,6 +239,25 @@ func NewSigner(ctx context.Context, ko options.KeyOpts, signer signature.SignerV
 	return f, nil
 }
 
+// authFlow returns the OIDC flow getting the ID token: flow when set by the
+// user, else the token flow when idToken was found, the device flow when not
+// interactive, and the interactive flow otherwise.
+func authFlow(flow, idToken string, interactive bool) (string, error) {
+	switch {
+	case flow == FlowToken && idToken == "":
+		return "", fmt.Errorf("the %s OIDC flow needs an ID token: set --identity-token or %s, or run where an ambient OIDC provider is available", FlowToken, env.VariableSigstoreIDToken)
+	case flow != "":
+		return flow, nil
+	case idToken != "":
+		return FlowToken, nil
+	case !interactive:
+		fmt.Fprintln(os.Stderr, "Non-interactive mode detected, using device flow.")
+		return FlowDevice, nil
+	default:
+		return FlowInteractive, nil
+	}
+}
+
 func (f *Signer) PublicKey(opts ...signature.PublicKeyOption) (crypto.PublicKey, error) { //nolint: revive
 	return f.SignerVerifier.PublicKey()
 }
@@ -198,6 +277,15 @@ func NewClient(fulcioURL string) (api.LegacyClient, error) {
 	if err != nil {
 		return nil, err
 	}
+	// api.NewClient doesn't take a transport and wraps http.DefaultTransport
+	// when it is created, so swap it in for the transport configured with
+	// --http-proxy, --cacert or --insecure-skip-tls-verify, logging its calls.
+	var t http.RoundTripper = http.DefaultTransport
+	if ct := options.Transport(options.EndpointFulcio); ct != nil {
+		t = ct
+	}
+	defer func(rt http.RoundTripper) { http.DefaultTransport = rt }(http.DefaultTransport)
+	http.DefaultTransport = ui.NewTransport(options.EndpointFulcio, options.WrapTransport(options.EndpointFulcio, t))
 	fClient := api.NewClient(fulcioServer, api.WithUserAgent(options.UserAgent()))
 	return fClient, nil
 }
@@ -26,6 +26,8 @@ import (
 	"errors"
 	"net/http"
 	"net/http/httptest"
+	"reflect"
+	"strings"
 	"testing"
 
 	"github.com/sigstore/cosign/v2/cmd/cosign/cli/options"
@@ -227,3 +229,62 @@ func TestNewSigner(t *testing.T) {
 		t.Fatalf("missing signer/verifier")
 	}
 }
+
+func TestAuthFlow(t *testing.T) {
+	tests := []struct {
+		name        string
+		flow        string
+		idToken     string
+		interactive bool
+		want        string
+		wantErr     bool
+	}{
+		{name: "token found", idToken: "token", interactive: true, want: FlowToken},
+		{name: "headless", want: FlowDevice},
+		{name: "terminal", interactive: true, want: FlowInteractive},
+		{name: "device requested", flow: FlowDevice, idToken: "token", want: FlowDevice},
+		{name: "interactive requested", flow: FlowInteractive, want: FlowInteractive},
+		{name: "token requested", flow: FlowToken, idToken: "token", want: FlowToken},
+		{name: "token requested without token", flow: FlowToken, interactive: true, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := authFlow(tt.flow, tt.idToken, tt.interactive)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("authFlow() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("authFlow() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNewSignerUnsupportedFlow(t *testing.T) {
+	ko := options.KeyOpts{
+		OIDCDisableProviders: true,
+		FulcioURL:            "https://fulcio.example.com",
+		FulcioAuthFlow:       "browser",
+	}
+	if _, err := NewSigner(context.TODO(), ko, nil); err == nil || !strings.Contains(err.Error(), "unsupported OIDC flow") {
+		t.Fatalf("NewSigner() error = %v, want unsupported OIDC flow", err)
+	}
+}
+
+func TestOIDCScopes(t *testing.T) {
+	tests := []struct {
+		name   string
+		scopes []string
+		want   []string
+	}{
+		{name: "openid added", scopes: []string{"email", "groups"}, want: []string{"openid", "email", "groups"}},
+		{name: "openid kept", scopes: []string{"profile", "openid"}, want: []string{"profile", "openid"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := oidcScopes(tt.scopes); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("oidcScopes() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
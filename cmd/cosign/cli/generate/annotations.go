@@ -0,0 +1,63 @@
+//
+// Copyright 2023 The Sigstore Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package generate
+
+import (
+	"fmt"
+)
+
+// Signature annotations describing the build, recorded by
+// ProvenanceAnnotations.
+const (
+	AnnotationGitCommit = "dev.sigstore.cosign/git-commit"
+	AnnotationCIRun     = "dev.sigstore.cosign/ci-run"
+	AnnotationBuilderID = "dev.sigstore.cosign/builder-id"
+)
+
+// ProvenanceAnnotations returns the git commit, the CI run, i.e. the URL of
+// the GitHub Actions run or GitLab job or the namespace/name of the Tekton
+// TaskRun, and the builder identity of the build running on the CI system
+// detected from the environment, to be signed as annotations. Those that the
+// environment doesn't tell are left out.
+func ProvenanceAnnotations() (map[string]string, error) {
+	return provenanceAnnotations(ciGetenv)
+}
+
+func provenanceAnnotations(getenv func(string) string) (map[string]string, error) {
+	p, err := findCIProvider(CIAuto, getenv)
+	if err != nil {
+		return nil, err
+	}
+	predicate, err := p.provenance(getenv)
+	if err != nil {
+		return nil, fmt.Errorf("reading the %s environment: %w", p.name, err)
+	}
+
+	annotations := map[string]string{}
+	for _, m := range predicate.Materials {
+		if commit := m.Digest["sha1"]; commit != "" {
+			annotations[AnnotationGitCommit] = commit
+			break
+		}
+	}
+	if predicate.Metadata != nil && predicate.Metadata.BuildInvocationID != "" {
+		annotations[AnnotationCIRun] = predicate.Metadata.BuildInvocationID
+	}
+	if predicate.Builder.ID != "" {
+		annotations[AnnotationBuilderID] = predicate.Builder.ID
+	}
+	return annotations, nil
+}
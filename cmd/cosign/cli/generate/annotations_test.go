@@ -0,0 +1,68 @@
+//
+// Copyright 2023 The Sigstore Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package generate
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestProvenanceAnnotations(t *testing.T) {
+	tests := []struct {
+		name    string
+		env     map[string]string
+		want    map[string]string
+		wantErr bool
+	}{{
+		name: "github actions",
+		env:  gitHubEnv,
+		want: map[string]string{
+			AnnotationGitCommit: "0123456789abcdef0123456789abcdef01234567",
+			AnnotationCIRun:     "https://github.com/sigstore/cosign/actions/runs/42/attempts/2",
+			AnnotationBuilderID: "https://github.com/sigstore/cosign/.github/workflows/build.yml@refs/heads/main",
+		},
+	}, {
+		name: "gitlab ci",
+		env:  gitLabEnv,
+		want: map[string]string{
+			AnnotationGitCommit: "0123456789abcdef0123456789abcdef01234567",
+			AnnotationCIRun:     "https://gitlab.com/sigstore/cosign/-/jobs/7",
+			AnnotationBuilderID: "https://gitlab.com/sigstore/cosign/-/runners/3",
+		},
+	}, {
+		name: "tekton without builder",
+		env:  tektonEnv,
+		want: map[string]string{
+			AnnotationGitCommit: "0123456789abcdef0123456789abcdef01234567",
+			AnnotationCIRun:     "ci/build-run",
+		},
+	}, {
+		name:    "no ci",
+		env:     map[string]string{},
+		wantErr: true,
+	}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := provenanceAnnotations(func(k string) string { return tt.env[k] })
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("provenanceAnnotations() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("provenanceAnnotations() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
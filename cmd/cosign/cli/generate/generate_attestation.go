@@ -0,0 +1,257 @@
+//
+// Copyright 2023 The Sigstore Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package generate
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	slsa02 "github.com/in-toto/in-toto-golang/in_toto/slsa_provenance/v0.2"
+)
+
+// CI systems that GenerateAttestationCmd describes the build of.
+const (
+	CIAuto          = "auto"
+	CIGitHubActions = "github-actions"
+	CIGitLab        = "gitlab-ci"
+	CITekton        = "tekton"
+)
+
+// Build types of the generated provenance.
+const (
+	buildTypeGitHubActions = "https://github.com/Attestations/GitHubActionsWorkflow@v1"
+	buildTypeTekton        = "tekton.dev/v1beta1/TaskRun"
+)
+
+// ciProvider fills a SLSA provenance predicate from the environment of the
+// CI system it is named after.
+type ciProvider struct {
+	name string
+	// detect tells whether the build runs on this CI system.
+	detect     func(getenv func(string) string) bool
+	provenance func(getenv func(string) string) (*slsa02.ProvenancePredicate, error)
+}
+
+var ciProviders = []ciProvider{{
+	name:       CIGitHubActions,
+	detect:     func(getenv func(string) string) bool { return getenv("GITHUB_ACTIONS") == "true" },
+	provenance: gitHubActionsProvenance,
+}, {
+	name:       CIGitLab,
+	detect:     func(getenv func(string) string) bool { return getenv("GITLAB_CI") == "true" },
+	provenance: gitLabProvenance,
+}, {
+	name:       CITekton,
+	detect:     func(getenv func(string) string) bool { return getenv("TEKTON_TASK_RUN") != "" },
+	provenance: tektonProvenance,
+}}
+
+// GenerateAttestationCmd writes to w a SLSA v0.2 provenance predicate that
+// describes the build running on the CI system named ci, or the one detected
+// from the environment when ci is CIAuto. builderID overrides the builder.id
+// derived from the environment when set.
+func GenerateAttestationCmd(ci, builderID string, w io.Writer) error {
+	return generateAttestation(ci, builderID, ciGetenv, w)
+}
+
+// ciGetenv reads the variables set by the CI system, not by the user of
+// cosign, so they aren't looked up through package env.
+func ciGetenv(key string) string {
+	return os.Getenv(key) //nolint:forbidigo
+}
+
+func generateAttestation(ci, builderID string, getenv func(string) string, w io.Writer) error {
+	p, err := findCIProvider(ci, getenv)
+	if err != nil {
+		return err
+	}
+	predicate, err := p.provenance(getenv)
+	if err != nil {
+		return fmt.Errorf("generating %s provenance: %w", p.name, err)
+	}
+	if builderID != "" {
+		predicate.Builder.ID = builderID
+	}
+	if predicate.Builder.ID == "" {
+		return fmt.Errorf("could not derive builder.id from the %s environment, set --builder-id", p.name)
+	}
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(predicate)
+}
+
+func findCIProvider(ci string, getenv func(string) string) (ciProvider, error) {
+	names := make([]string, 0, len(ciProviders))
+	for _, p := range ciProviders {
+		if ci == p.name || (ci == CIAuto && p.detect(getenv)) {
+			return p, nil
+		}
+		names = append(names, p.name)
+	}
+	if ci == CIAuto {
+		return ciProvider{}, fmt.Errorf("no supported CI system detected (%s), set --ci", strings.Join(names, ", "))
+	}
+	return ciProvider{}, fmt.Errorf("unsupported CI system %q, expected one of %s", ci, strings.Join(names, ", "))
+}
+
+// requireEnv returns the values of the variables names, failing on the
+// first one that is not set.
+func requireEnv(getenv func(string) string, names ...string) ([]string, error) {
+	values := make([]string, len(names))
+	for i, name := range names {
+		if values[i] = getenv(name); values[i] == "" {
+			return nil, fmt.Errorf("$%s is not set", name)
+		}
+	}
+	return values, nil
+}
+
+// envMap returns the values of the variables names that are set.
+func envMap(getenv func(string) string, names ...string) map[string]string {
+	m := map[string]string{}
+	for _, name := range names {
+		if v := getenv(name); v != "" {
+			m[name] = v
+		}
+	}
+	return m
+}
+
+// gitMaterial is the git commit the build ran from.
+func gitMaterial(repoURL, ref, commit string) slsa02.ProvenanceMaterial {
+	uri := "git+" + repoURL
+	if ref != "" {
+		uri += "@" + ref
+	}
+	return slsa02.ProvenanceMaterial{URI: uri, Digest: slsa02.DigestSet{"sha1": commit}}
+}
+
+func gitHubActionsProvenance(getenv func(string) string) (*slsa02.ProvenancePredicate, error) {
+	env, err := requireEnv(getenv, "GITHUB_SERVER_URL", "GITHUB_REPOSITORY", "GITHUB_SHA", "GITHUB_RUN_ID")
+	if err != nil {
+		return nil, err
+	}
+	server, repo, sha, runID := env[0], env[1], env[2], env[3]
+	ref := getenv("GITHUB_REF")
+	material := gitMaterial(server+"/"+repo, ref, sha)
+
+	// GITHUB_WORKFLOW_REF is OWNER/REPO/PATH@REF.
+	var builderID, entryPoint string
+	if workflowRef := getenv("GITHUB_WORKFLOW_REF"); workflowRef != "" {
+		builderID = server + "/" + workflowRef
+		entryPoint, _, _ = strings.Cut(strings.TrimPrefix(workflowRef, repo+"/"), "@")
+	}
+	invocationID := server + "/" + repo + "/actions/runs/" + runID
+	if attempt := getenv("GITHUB_RUN_ATTEMPT"); attempt != "" {
+		invocationID += "/attempts/" + attempt
+	}
+
+	return &slsa02.ProvenancePredicate{
+		Builder:   slsa02.ProvenanceBuilder{ID: builderID},
+		BuildType: buildTypeGitHubActions,
+		Invocation: slsa02.ProvenanceInvocation{
+			ConfigSource: slsa02.ConfigSource{
+				URI:        material.URI,
+				Digest:     material.Digest,
+				EntryPoint: entryPoint,
+			},
+			Environment: envMap(getenv, "GITHUB_ACTOR", "GITHUB_EVENT_NAME", "GITHUB_RUN_ID", "GITHUB_RUN_ATTEMPT",
+				"GITHUB_RUN_NUMBER", "GITHUB_WORKFLOW", "RUNNER_OS", "RUNNER_ARCH"),
+		},
+		Metadata:  &slsa02.ProvenanceMetadata{BuildInvocationID: invocationID},
+		Materials: []slsa02.ProvenanceMaterial{material},
+	}, nil
+}
+
+func gitLabProvenance(getenv func(string) string) (*slsa02.ProvenancePredicate, error) {
+	env, err := requireEnv(getenv, "CI_SERVER_URL", "CI_PROJECT_URL", "CI_PROJECT_PATH", "CI_COMMIT_SHA", "CI_JOB_URL")
+	if err != nil {
+		return nil, err
+	}
+	server, projectURL, projectPath, sha, jobURL := env[0], env[1], env[2], env[3], env[4]
+	material := gitMaterial(projectURL, getenv("CI_COMMIT_REF_NAME"), sha)
+
+	var builderID string
+	if runner := getenv("CI_RUNNER_ID"); runner != "" {
+		builderID = server + "/" + projectPath + "/-/runners/" + runner
+	}
+	entryPoint := getenv("CI_CONFIG_PATH")
+	if entryPoint == "" {
+		entryPoint = ".gitlab-ci.yml"
+	}
+	// GitLab documents the provenance of its runners at this address.
+	buildType := "https://gitlab.com/gitlab-org/gitlab-runner/-/blob/main/PROVENANCE.md"
+	if version := getenv("CI_RUNNER_VERSION"); version != "" {
+		buildType = "https://gitlab.com/gitlab-org/gitlab-runner/-/blob/v" + version + "/PROVENANCE.md"
+	}
+
+	return &slsa02.ProvenancePredicate{
+		Builder:   slsa02.ProvenanceBuilder{ID: builderID},
+		BuildType: buildType,
+		Invocation: slsa02.ProvenanceInvocation{
+			ConfigSource: slsa02.ConfigSource{
+				URI:        material.URI,
+				Digest:     material.Digest,
+				EntryPoint: entryPoint,
+			},
+			Environment: envMap(getenv, "CI_PIPELINE_ID", "CI_PIPELINE_SOURCE", "CI_JOB_ID", "CI_JOB_NAME",
+				"CI_JOB_STAGE", "CI_RUNNER_DESCRIPTION", "GITLAB_USER_LOGIN"),
+		},
+		Metadata:  &slsa02.ProvenanceMetadata{BuildInvocationID: jobURL},
+		Materials: []slsa02.ProvenanceMaterial{material},
+	}, nil
+}
+
+// tektonProvenance relies on variables that the task maps from its context
+// and parameters, as Tekton doesn't expose those to steps by itself. The
+// builder.id has to be set with --builder-id.
+//
+//	env:
+//	- name: TEKTON_PIPELINE_RUN
+//	  value: $(context.pipelineRun.name)
+//	- name: TEKTON_TASK_RUN
+//	  value: $(context.taskRun.name)
+//	- name: TEKTON_NAMESPACE
+//	  value: $(context.taskRun.namespace)
+//	- name: TEKTON_GIT_URL
+//	  value: $(params.git-url)
+//	- name: TEKTON_GIT_COMMIT
+//	  value: $(params.git-commit)
+func tektonProvenance(getenv func(string) string) (*slsa02.ProvenancePredicate, error) {
+	env, err := requireEnv(getenv, "TEKTON_TASK_RUN", "TEKTON_NAMESPACE")
+	if err != nil {
+		return nil, err
+	}
+	taskRun, namespace := env[0], env[1]
+
+	predicate := &slsa02.ProvenancePredicate{
+		BuildType: buildTypeTekton,
+		Invocation: slsa02.ProvenanceInvocation{
+			Environment: envMap(getenv, "TEKTON_PIPELINE_RUN", "TEKTON_TASK_RUN", "TEKTON_NAMESPACE"),
+		},
+		Metadata: &slsa02.ProvenanceMetadata{BuildInvocationID: namespace + "/" + taskRun},
+	}
+	if url, commit := getenv("TEKTON_GIT_URL"), getenv("TEKTON_GIT_COMMIT"); url != "" && commit != "" {
+		material := gitMaterial(url, "", commit)
+		predicate.Invocation.ConfigSource = slsa02.ConfigSource{URI: material.URI, Digest: material.Digest}
+		predicate.Materials = []slsa02.ProvenanceMaterial{material}
+	}
+	return predicate, nil
+}
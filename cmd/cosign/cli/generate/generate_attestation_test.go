@@ -0,0 +1,174 @@
+//
+// Copyright 2023 The Sigstore Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package generate
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	slsa02 "github.com/in-toto/in-toto-golang/in_toto/slsa_provenance/v0.2"
+)
+
+var gitHubEnv = map[string]string{
+	"GITHUB_ACTIONS":      "true",
+	"GITHUB_SERVER_URL":   "https://github.com",
+	"GITHUB_REPOSITORY":   "sigstore/cosign",
+	"GITHUB_SHA":          "0123456789abcdef0123456789abcdef01234567",
+	"GITHUB_REF":          "refs/heads/main",
+	"GITHUB_RUN_ID":       "42",
+	"GITHUB_RUN_ATTEMPT":  "2",
+	"GITHUB_WORKFLOW_REF": "sigstore/cosign/.github/workflows/build.yml@refs/heads/main",
+	"GITHUB_EVENT_NAME":   "push",
+}
+
+var gitLabEnv = map[string]string{
+	"GITLAB_CI":          "true",
+	"CI_SERVER_URL":      "https://gitlab.com",
+	"CI_PROJECT_URL":     "https://gitlab.com/sigstore/cosign",
+	"CI_PROJECT_PATH":    "sigstore/cosign",
+	"CI_COMMIT_SHA":      "0123456789abcdef0123456789abcdef01234567",
+	"CI_COMMIT_REF_NAME": "main",
+	"CI_JOB_URL":         "https://gitlab.com/sigstore/cosign/-/jobs/7",
+	"CI_JOB_ID":          "7",
+	"CI_RUNNER_ID":       "3",
+	"CI_RUNNER_VERSION":  "16.0.0",
+}
+
+var tektonEnv = map[string]string{
+	"TEKTON_TASK_RUN":   "build-run",
+	"TEKTON_NAMESPACE":  "ci",
+	"TEKTON_GIT_URL":    "https://github.com/sigstore/cosign",
+	"TEKTON_GIT_COMMIT": "0123456789abcdef0123456789abcdef01234567",
+}
+
+func TestGenerateAttestation(t *testing.T) {
+	material := func(uri string) []slsa02.ProvenanceMaterial {
+		return []slsa02.ProvenanceMaterial{{
+			URI:    uri,
+			Digest: slsa02.DigestSet{"sha1": "0123456789abcdef0123456789abcdef01234567"},
+		}}
+	}
+	tests := []struct {
+		name      string
+		ci        string
+		builderID string
+		env       map[string]string
+		want      slsa02.ProvenancePredicate
+		wantErr   bool
+	}{{
+		name: "github actions",
+		ci:   CIAuto,
+		env:  gitHubEnv,
+		want: slsa02.ProvenancePredicate{
+			Builder:   slsa02.ProvenanceBuilder{ID: "https://github.com/sigstore/cosign/.github/workflows/build.yml@refs/heads/main"},
+			BuildType: buildTypeGitHubActions,
+			Invocation: slsa02.ProvenanceInvocation{
+				ConfigSource: slsa02.ConfigSource{
+					URI:        "git+https://github.com/sigstore/cosign@refs/heads/main",
+					Digest:     slsa02.DigestSet{"sha1": "0123456789abcdef0123456789abcdef01234567"},
+					EntryPoint: ".github/workflows/build.yml",
+				},
+				Environment: map[string]interface{}{
+					"GITHUB_EVENT_NAME":  "push",
+					"GITHUB_RUN_ID":      "42",
+					"GITHUB_RUN_ATTEMPT": "2",
+				},
+			},
+			Metadata:  &slsa02.ProvenanceMetadata{BuildInvocationID: "https://github.com/sigstore/cosign/actions/runs/42/attempts/2"},
+			Materials: material("git+https://github.com/sigstore/cosign@refs/heads/main"),
+		},
+	}, {
+		name: "gitlab ci",
+		ci:   CIGitLab,
+		env:  gitLabEnv,
+		want: slsa02.ProvenancePredicate{
+			Builder:   slsa02.ProvenanceBuilder{ID: "https://gitlab.com/sigstore/cosign/-/runners/3"},
+			BuildType: "https://gitlab.com/gitlab-org/gitlab-runner/-/blob/v16.0.0/PROVENANCE.md",
+			Invocation: slsa02.ProvenanceInvocation{
+				ConfigSource: slsa02.ConfigSource{
+					URI:        "git+https://gitlab.com/sigstore/cosign@main",
+					Digest:     slsa02.DigestSet{"sha1": "0123456789abcdef0123456789abcdef01234567"},
+					EntryPoint: ".gitlab-ci.yml",
+				},
+				Environment: map[string]interface{}{"CI_JOB_ID": "7"},
+			},
+			Metadata:  &slsa02.ProvenanceMetadata{BuildInvocationID: "https://gitlab.com/sigstore/cosign/-/jobs/7"},
+			Materials: material("git+https://gitlab.com/sigstore/cosign@main"),
+		},
+	}, {
+		name:      "tekton",
+		ci:        CIAuto,
+		builderID: "https://tekton.example.com/builder",
+		env:       tektonEnv,
+		want: slsa02.ProvenancePredicate{
+			Builder:   slsa02.ProvenanceBuilder{ID: "https://tekton.example.com/builder"},
+			BuildType: buildTypeTekton,
+			Invocation: slsa02.ProvenanceInvocation{
+				ConfigSource: slsa02.ConfigSource{
+					URI:    "git+https://github.com/sigstore/cosign",
+					Digest: slsa02.DigestSet{"sha1": "0123456789abcdef0123456789abcdef01234567"},
+				},
+				Environment: map[string]interface{}{"TEKTON_TASK_RUN": "build-run", "TEKTON_NAMESPACE": "ci"},
+			},
+			Metadata:  &slsa02.ProvenanceMetadata{BuildInvocationID: "ci/build-run"},
+			Materials: material("git+https://github.com/sigstore/cosign"),
+		},
+	}, {
+		name:    "tekton without builder id",
+		ci:      CITekton,
+		env:     tektonEnv,
+		wantErr: true,
+	}, {
+		name:    "no ci detected",
+		ci:      CIAuto,
+		env:     map[string]string{},
+		wantErr: true,
+	}, {
+		name:    "unsupported ci",
+		ci:      "jenkins",
+		env:     gitHubEnv,
+		wantErr: true,
+	}, {
+		name:    "missing variable",
+		ci:      CIGitHubActions,
+		env:     map[string]string{"GITHUB_ACTIONS": "true"},
+		wantErr: true,
+	}}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			var out bytes.Buffer
+			err := generateAttestation(tc.ci, tc.builderID, func(name string) string { return tc.env[name] }, &out)
+			if tc.wantErr {
+				if err == nil {
+					t.Fatalf("expected an error, got %s", out.String())
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			var got slsa02.ProvenancePredicate
+			if err := json.Unmarshal(out.Bytes(), &got); err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, tc.want) {
+				t.Errorf("got %+v, wanted %+v", got, tc.want)
+			}
+		})
+	}
+}
@@ -25,6 +25,7 @@ import (
 	"strings"
 
 	"github.com/sigstore/cosign/v2/pkg/cosign/env"
+	"github.com/sigstore/cosign/v2/pkg/cosign/fidokey"
 	"github.com/sigstore/cosign/v2/pkg/cosign/git"
 	"github.com/sigstore/cosign/v2/pkg/cosign/git/github"
 	"github.com/sigstore/cosign/v2/pkg/cosign/git/gitlab"
@@ -33,8 +34,8 @@ import (
 	"github.com/sigstore/cosign/v2/internal/ui"
 	"github.com/sigstore/cosign/v2/pkg/cosign"
 	"github.com/sigstore/cosign/v2/pkg/cosign/kubernetes"
+	sigs "github.com/sigstore/cosign/v2/pkg/signature"
 	"github.com/sigstore/sigstore/pkg/cryptoutils"
-	"github.com/sigstore/sigstore/pkg/signature/kms"
 )
 
 var (
@@ -48,7 +49,7 @@ func GenerateKeyPairCmd(ctx context.Context, kmsVal string, outputKeyPrefixVal s
 	publicKeyFileName := outputKeyPrefixVal + ".pub"
 
 	if kmsVal != "" {
-		k, err := kms.Get(ctx, kmsVal, crypto.SHA256)
+		k, err := sigs.KMSFromKeyRef(ctx, kmsVal, crypto.SHA256)
 		if err != nil {
 			return err
 		}
@@ -107,6 +108,39 @@ func GenerateKeyPairCmd(ctx context.Context, kmsVal string, outputKeyPrefixVal s
 	return writeKeyFiles(privateKeyFileName, publicKeyFileName, keys)
 }
 
+// GenerateFIDOKeyCmd enrolls the attached FIDO2 security key for rpID and
+// writes the public key of the signing key derived from it. The private key
+// never leaves the security key, which must be tapped to sign.
+func GenerateFIDOKeyCmd(ctx context.Context, rpID string, outputKeyPrefixVal string) error {
+	publicKeyFileName := outputKeyPrefixVal + ".pub"
+
+	fileExists, err := icos.FileExists(publicKeyFileName)
+	if err != nil {
+		return fmt.Errorf("failed checking if %s exists: %w", publicKeyFileName, err)
+	}
+	if fileExists {
+		ui.Warnf(ctx, "File %s already exists. Overwrite?", publicKeyFileName)
+		if err := ui.ConfirmContinue(ctx); err != nil {
+			return err
+		}
+	}
+
+	pub, err := fidokey.Enroll(rpID)
+	if err != nil {
+		return fmt.Errorf("enrolling fido2 security key: %w", err)
+	}
+	pemBytes, err := cryptoutils.MarshalPublicKeyToPEM(pub)
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(publicKeyFileName, pemBytes, 0644); err != nil { // #nosec G306
+		return err
+	}
+	fmt.Fprintln(os.Stderr, "Public key written to", publicKeyFileName)
+	fmt.Fprintf(os.Stderr, "Sign with --key %s%s\n", fidokey.ReferenceScheme, rpID)
+	return nil
+}
+
 func writeKeyFiles(privateKeyFileName string, publicKeyFileName string, keys *cosign.KeysBytes) error {
 	// TODO: make sure the perms are locked down first.
 	if err := os.WriteFile(privateKeyFileName, keys.PrivateBytes, 0600); err != nil {
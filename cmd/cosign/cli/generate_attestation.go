@@ -0,0 +1,55 @@
+//
+// Copyright 2023 The Sigstore Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cli
+
+import (
+	"github.com/spf13/cobra"
+
+	"github.com/sigstore/cosign/v2/cmd/cosign/cli/generate"
+	"github.com/sigstore/cosign/v2/cmd/cosign/cli/options"
+)
+
+func GenerateAttestation() *cobra.Command {
+	o := &options.GenerateAttestationOptions{}
+
+	cmd := &cobra.Command{
+		Use:   "generate-attestation",
+		Short: "Generates a SLSA provenance predicate for the build running in CI.",
+		Long: `Generates a SLSA v0.2 provenance predicate from the environment of the CI system running the build,
+filling builder.id, the invocation and the git commit of the build as material. The predicate can be passed
+to "cosign attest --type slsaprovenance".
+
+GitHub Actions and GitLab CI are detected from their default environment variables. Tekton doesn't expose
+the build to its steps, so the task maps TEKTON_TASK_RUN and TEKTON_NAMESPACE, and optionally
+TEKTON_PIPELINE_RUN, TEKTON_GIT_URL and TEKTON_GIT_COMMIT, from its context and parameters.`,
+		Example: `  cosign generate-attestation [--ci <CI>] [--builder-id <URI>]
+
+  # attest an image with the provenance of the build running in CI
+  cosign generate-attestation | cosign attest --type slsaprovenance --predicate - --key cosign.key <IMAGE>
+
+  # generate the provenance of a Tekton task run
+  cosign generate-attestation --ci tekton --builder-id https://tekton.example.com/builders/default`,
+
+		Args:             cobra.NoArgs,
+		PersistentPreRun: options.BindViper,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			return generate.GenerateAttestationCmd(o.CI, o.BuilderID, cmd.OutOrStdout())
+		},
+	}
+
+	o.AddFlags(cmd)
+	return cmd
+}
@@ -16,6 +16,8 @@
 package cli
 
 import (
+	"errors"
+
 	"github.com/spf13/cobra"
 
 	"github.com/sigstore/cosign/v2/cmd/cosign/cli/generate"
@@ -61,12 +63,23 @@ func GenerateKeyPair() *cobra.Command {
   # generate a key-pair in GitLab with project id
   cosign generate-key-pair gitlab://[PROJECT_ID]
 
+  # enroll a FIDO2 security key, then sign with it
+  cosign generate-key-pair --fido
+  cosign sign --key fido2://sigstore.dev <IMAGE>
+
 CAVEATS:
   This command interactively prompts for a password. You can use
-  the COSIGN_PASSWORD environment variable to provide one.`,
+  the COSIGN_PASSWORD environment variable to provide one, and
+  COSIGN_FIDO2_PIN for the PIN of a FIDO2 security key.`,
 
 		PersistentPreRun: options.BindViper,
 		RunE: func(cmd *cobra.Command, args []string) error {
+			if o.FIDO {
+				if len(args) > 0 {
+					return errors.New("--fido cannot be used with a key reference")
+				}
+				return generate.GenerateFIDOKeyCmd(cmd.Context(), o.FIDORPID, o.OutputKeyPrefix)
+			}
 			return generate.GenerateKeyPairCmd(cmd.Context(), o.KMS, o.OutputKeyPrefix, args)
 		},
 	}
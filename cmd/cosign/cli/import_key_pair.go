@@ -27,8 +27,8 @@ func ImportKeyPair() *cobra.Command {
 
 	cmd := &cobra.Command{
 		Use:   "import-key-pair",
-		Short: "Imports a PEM-encoded RSA or EC private key.",
-		Long:  "Imports a PEM-encoded RSA or EC private key for signing.",
+		Short: "Imports a PEM-encoded RSA, EC or Ed25519 private key.",
+		Long:  "Imports a PEM-encoded RSA, EC or Ed25519 private key for signing. The key is encrypted with the cosign password format, so that existing keys can be used instead of generating new ones. The key can be encrypted with an age passphrase, or be an ASCII armored OpenPGP private key.",
 		Example: `  cosign import-key-pair  --key openssl.key --output-key-prefix my-key
 
   # import PEM-encoded RSA or EC private key and write to import-cosign.key and import-cosign.pub files
@@ -37,9 +37,22 @@ func ImportKeyPair() *cobra.Command {
   # import PEM-encoded RSA or EC private key and write to my-key.key and my-key.pub files
   cosign import-key-pair --key <key path> --output-key-prefix my-key
 
+  # import a PKCS #8 Ed25519 private key, e.g. from openssl genpkey -algorithm ed25519, and write to cosign.key and cosign.pub files
+  cosign import-key-pair --key <key path> --output-key-prefix cosign
+
+  # import a passphrase-encrypted age file holding a PEM-encoded private key
+  cosign import-key-pair --key <key path>.age
+
+  # import an ASCII armored OpenPGP RSA or ECDSA private key
+  gpg --export-secret-keys --armor <KEY ID> > key.asc
+  cosign import-key-pair --key key.asc
+
 CAVEATS:
   This command interactively prompts for a password. You can use
-  the COSIGN_PASSWORD environment variable to provide one.`,
+  the COSIGN_PASSWORD environment variable to provide one.
+
+  The passphrase of an age or OpenPGP key is prompted for first, the
+  password of the imported key pair next. COSIGN_PASSWORD provides both.`,
 		PersistentPreRun: options.BindViper,
 		RunE: func(cmd *cobra.Command, args []string) error {
 			return importkeypair.ImportKeyPairCmd(cmd.Context(), o.Key, o.OutputKeyPrefix, args)
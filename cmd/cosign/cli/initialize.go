@@ -35,7 +35,8 @@ The following options are used by default:
  - SigStore remote TUF repository is pulled from the CDN mirror at tuf-repo-cdn.sigstore.dev.
 
 To provide an out-of-band trusted initial root.json, use the -root flag with a file or URL reference.
-This will enable you to point cosign to a separate TUF root.
+This will enable you to point cosign to a separate TUF root. Pin its contents with -root-checksum,
+which is recommended when the root is fetched over http(s).
 
 Any updated TUF repository will be written to $HOME/.sigstore/root/.
 
@@ -50,10 +51,10 @@ cosign initialize
 cosign initialize -root <url>
 
 # initialize with an out-of-band root key file and custom repository mirror.
-cosign initialize -mirror <url> -root <url>`,
+cosign initialize -mirror <url> -root <url> -root-checksum <sha256>`,
 		PersistentPreRun: options.BindViper,
 		RunE: func(cmd *cobra.Command, args []string) error {
-			return initialize.DoInitialize(cmd.Context(), o.Root, o.Mirror)
+			return initialize.DoInitializeWithRootChecksum(cmd.Context(), o.Root, o.Mirror, o.RootChecksum)
 		},
 	}
 
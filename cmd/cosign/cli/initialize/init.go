@@ -17,23 +17,46 @@ package initialize
 
 import (
 	"context"
+	"crypto/sha256"
+	"crypto/sha512"
 	_ "embed" // To enable the `go:embed` directive.
+	"encoding/hex"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"strings"
 
+	"github.com/sigstore/cosign/v2/internal/ui"
 	"github.com/sigstore/cosign/v2/pkg/blob"
 	"github.com/sigstore/sigstore/pkg/tuf"
 )
 
 func DoInitialize(ctx context.Context, root, mirror string) error {
+	return DoInitializeWithRootChecksum(ctx, root, mirror, "")
+}
+
+// DoInitializeWithRootChecksum is DoInitialize, pinning the initial root to
+// rootChecksum when it is set. A root fetched over http(s) without a checksum
+// is trusted with a warning.
+func DoInitializeWithRootChecksum(ctx context.Context, root, mirror, rootChecksum string) error {
 	// Get the initial trusted root contents.
 	var rootFileBytes []byte
 	var err error
 	if root != "" {
+		if rootChecksum == "" && (strings.HasPrefix(root, "http://") || strings.HasPrefix(root, "https://")) {
+			ui.Warnf(ctx, "the root fetched from %s is trusted as is, pin it with --root-checksum", root)
+		}
 		rootFileBytes, err = blob.LoadFileOrURL(root)
 		if err != nil {
 			return err
 		}
+		if rootChecksum != "" {
+			if err := verifyRootChecksum(rootFileBytes, rootChecksum); err != nil {
+				return err
+			}
+		}
+	} else if rootChecksum != "" {
+		return errors.New("--root-checksum requires --root")
 	}
 
 	if err := tuf.Initialize(ctx, mirror, rootFileBytes); err != nil {
@@ -52,3 +75,27 @@ func DoInitialize(ctx context.Context, root, mirror string) error {
 	fmt.Println("Root status: \n", string(b))
 	return nil
 }
+
+// verifyRootChecksum checks root against checksum, a hex-encoded SHA-256
+// digest optionally prefixed with sha256: or sha512:.
+func verifyRootChecksum(root []byte, checksum string) error {
+	algo, want, found := strings.Cut(checksum, ":")
+	if !found {
+		algo, want = "sha256", checksum
+	}
+	var got []byte
+	switch algo {
+	case "sha256":
+		sum := sha256.Sum256(root)
+		got = sum[:]
+	case "sha512":
+		sum := sha512.Sum512(root)
+		got = sum[:]
+	default:
+		return fmt.Errorf("unsupported root checksum algorithm %q, expected sha256 or sha512", algo)
+	}
+	if !strings.EqualFold(hex.EncodeToString(got), want) {
+		return fmt.Errorf("root checksum mismatch: expected %s, got %s:%s", checksum, algo, hex.EncodeToString(got))
+	}
+	return nil
+}
@@ -0,0 +1,98 @@
+//
+// Copyright 2023 The Sigstore Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package initialize
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestVerifyRootChecksum(t *testing.T) {
+	root := []byte("root")
+	tests := []struct {
+		name     string
+		checksum string
+		wantErr  string
+	}{{
+		name:     "sha256",
+		checksum: "4813494d137e1631bba301d5acab6e7bb7aa74ce1185d456565ef51d737677b2",
+	}, {
+		name:     "sha256 upper case",
+		checksum: "4813494D137E1631BBA301D5ACAB6E7BB7AA74CE1185D456565EF51D737677B2",
+	}, {
+		name:     "sha256 prefix",
+		checksum: "sha256:4813494d137e1631bba301d5acab6e7bb7aa74ce1185d456565ef51d737677b2",
+	}, {
+		name:     "sha512 prefix",
+		checksum: "sha512:99adc231b045331e514a516b4b7680f588e3823213abe901738bc3ad67b2f6fcb3c64efb93d18002588d3ccc1a49efbae1ce20cb43df36b38651f11fa75678e8",
+	}, {
+		name:     "mismatch",
+		checksum: "0000000000000000000000000000000000000000000000000000000000000000",
+		wantErr:  "root checksum mismatch",
+	}, {
+		name:     "unsupported algorithm",
+		checksum: "md5:63a9f0ea7bb98050796b649e85481845",
+		wantErr:  "unsupported root checksum algorithm",
+	}}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			err := verifyRootChecksum(root, tc.checksum)
+			if tc.wantErr == "" {
+				if err != nil {
+					t.Fatalf("verifyRootChecksum() = %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tc.wantErr) {
+				t.Fatalf("verifyRootChecksum() = %v, want error containing %q", err, tc.wantErr)
+			}
+		})
+	}
+}
+
+func TestDoInitializeWithRootChecksum(t *testing.T) {
+	root := filepath.Join(t.TempDir(), "root.json")
+	if err := os.WriteFile(root, []byte("root"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name         string
+		root         string
+		rootChecksum string
+		wantErr      string
+	}{{
+		name:         "checksum without root",
+		rootChecksum: "4813494d137e1631bba301d5acab6e7bb7aa74ce1185d456565ef51d737677b2",
+		wantErr:      "--root-checksum requires --root",
+	}, {
+		name:         "checksum mismatch",
+		root:         root,
+		rootChecksum: "0000000000000000000000000000000000000000000000000000000000000000",
+		wantErr:      "root checksum mismatch",
+	}}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			err := DoInitializeWithRootChecksum(context.Background(), tc.root, "", tc.rootChecksum)
+			if err == nil || !strings.Contains(err.Error(), tc.wantErr) {
+				t.Fatalf("DoInitializeWithRootChecksum() = %v, want error containing %q", err, tc.wantErr)
+			}
+		})
+	}
+}
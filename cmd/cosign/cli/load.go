@@ -17,7 +17,9 @@ package cli
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"os"
 
 	"github.com/google/go-containerregistry/pkg/name"
 
@@ -32,10 +34,13 @@ func Load() *cobra.Command {
 	o := &options.LoadOptions{}
 
 	cmd := &cobra.Command{
-		Use:              "load",
-		Short:            "Load a signed image on disk to a remote registry",
-		Long:             "Load a signed image on disk to a remote registry",
-		Example:          `  cosign load --dir <path to directory> <IMAGE>`,
+		Use:   "load",
+		Short: "Load a signed image on disk to a remote registry",
+		Long:  "Load a signed image on disk, in a directory or tarball, to a remote registry",
+		Example: `  cosign load --dir <path to directory> <IMAGE>
+
+  # load an image with its signatures and attestations from a tarball written by 'cosign save --tarball'
+  cosign load --tarball <path to file> <IMAGE>`,
 		Args:             cobra.ExactArgs(1),
 		PersistentPreRun: options.BindViper,
 		RunE: func(cmd *cobra.Command, args []string) error {
@@ -53,8 +58,21 @@ func LoadCmd(ctx context.Context, opts options.LoadOptions, imageRef string) err
 		return fmt.Errorf("parsing image name %s: %w", imageRef, err)
 	}
 
+	dir := opts.Directory
+	if opts.Tarball != "" {
+		if dir, err = os.MkdirTemp("", "cosign-load"); err != nil {
+			return err
+		}
+		defer os.RemoveAll(dir)
+		if err := layout.ExtractTarball(opts.Tarball, dir); err != nil {
+			return fmt.Errorf("extracting %s: %w", opts.Tarball, err)
+		}
+	} else if dir == "" {
+		return errors.New("one of --dir or --tarball is required")
+	}
+
 	// get the signed image from disk
-	sii, err := layout.SignedImageIndex(opts.Directory)
+	sii, err := layout.SignedImageIndex(dir)
 	if err != nil {
 		return fmt.Errorf("signed image index: %w", err)
 	}
@@ -39,13 +39,23 @@ func Manifest() *cobra.Command {
 }
 
 func manifestVerify() *cobra.Command {
-	o := &options.VerifyOptions{}
+	o := &options.VerifyManifestOptions{}
 
 	cmd := &cobra.Command{
 		Use:   "verify",
 		Short: "Verify all signatures of images specified in the manifest",
 		Long: `Verify all signature of images in a Kubernetes resource manifest by checking claims
-against the transparency log.`,
+against the transparency log.
+
+The manifest is a YAML file of Kubernetes resources, a kustomization directory
+or a Helm chart directory. The images of a kustomization are those of its local
+resources, with its image overrides applied. The images of a Helm chart are
+those of its values, merged with the --values files; its templates are not
+rendered.
+
+To gate deployments in CI, --continue-on-error verifies every image and prints
+a summary of the failed ones, and --output json writes a verification result
+per image.`,
 		Example: `  cosign manifest verify --key <key path>|<key url>|<kms uri> <path/to/manifest>
 
   # verify cosign claims and signing certificates on images in the manifest
@@ -70,7 +80,13 @@ against the transparency log.`,
   cosign manifest verify --key gcpkms://projects/[PROJECT]/locations/global/keyRings/[KEYRING]/cryptoKeys/[KEY] <path/to/my-deployment.yaml>
 
   # verify images with public key stored in Hashicorp Vault
-  cosign manifest verify --key hashivault://[KEY] <path/to/my-deployment.yaml>`,
+  cosign manifest verify --key hashivault://[KEY] <path/to/my-deployment.yaml>
+
+  # verify every image of a kustomization, writing a verification result per image
+  cosign manifest verify --key cosign.pub --continue-on-error -o json <path/to/kustomization>
+
+  # verify the images of a Helm chart with production values, and their SLSA provenance
+  cosign manifest verify --key cosign.pub --values values-prod.yaml --attestation-type slsaprovenance --policy policy.cue <path/to/chart>`,
 		Args:             cobra.ExactArgs(1),
 		PersistentPreRun: options.BindViper,
 		RunE: func(cmd *cobra.Command, args []string) error {
@@ -79,6 +95,7 @@ against the transparency log.`,
 				return err
 			}
 			v := &manifest.VerifyManifestCommand{
+				ValuesFiles: o.Values,
 				VerifyCommand: verify.VerifyCommand{
 					RegistryOptions:              o.Registry,
 					CertVerifyOptions:            o.CertVerify,
@@ -100,13 +117,71 @@ against the transparency log.`,
 					Attachment:                   o.Attachment,
 					Annotations:                  annotations,
 					LocalImage:                   o.LocalImage,
+					ContinueOnError:              o.ContinueOnError,
+					Recursive:                    o.Recursive,
+					Platform:                     o.Platform,
+					OutputDigest:                 o.OutputDigest,
+					ThresholdPolicy:              o.ThresholdPolicy,
+					TrustPolicy:                  o.TrustPolicy,
+					PIVAttestation:               o.PIVAttestation,
 					Offline:                      o.CommonVerifyOptions.Offline,
 					TSACertChainPath:             o.CommonVerifyOptions.TSACertChainPath,
 					IgnoreTlog:                   o.CommonVerifyOptions.IgnoreTlog,
+					RequireOnlineTlog:            o.CommonVerifyOptions.RequireOnlineTlog,
+					RekorCheckpoint:              o.CommonVerifyOptions.RekorCheckpoint,
+					RekorPublicKey:               o.CommonVerifyOptions.RekorPublicKey,
+					MaxSignatureAge:              o.CommonVerifyOptions.MaxSignatureAge,
 					MaxWorkers:                   o.CommonVerifyOptions.MaxWorkers,
+					Record:                       o.Lockfile.Record,
+					Against:                      o.Lockfile.Against,
+					RevocationList:               o.Revocation.List,
+					RevocationListKey:            o.Revocation.Key,
+					RevocationListTTL:            o.Revocation.TTL,
 				},
 			}
 
+			if len(o.AttestationTypes) > 0 {
+				v.VerifyAttestation = &verify.VerifyAttestationCommand{
+					RegistryOptions:              o.Registry,
+					CheckClaims:                  o.CheckClaims,
+					CertVerifyOptions:            o.CertVerify,
+					CertRef:                      o.CertVerify.Cert,
+					CertChain:                    o.CertVerify.CertChain,
+					CertGithubWorkflowTrigger:    o.CertVerify.CertGithubWorkflowTrigger,
+					CertGithubWorkflowSha:        o.CertVerify.CertGithubWorkflowSha,
+					CertGithubWorkflowName:       o.CertVerify.CertGithubWorkflowName,
+					CertGithubWorkflowRepository: o.CertVerify.CertGithubWorkflowRepository,
+					CertGithubWorkflowRef:        o.CertVerify.CertGithubWorkflowRef,
+					IgnoreSCT:                    o.CertVerify.IgnoreSCT,
+					SCTRef:                       o.CertVerify.SCT,
+					KeyRef:                       o.Key,
+					Sk:                           o.SecurityKey.Use,
+					Slot:                         o.SecurityKey.Slot,
+					RekorURL:                     o.Rekor.URL,
+					PredicateTypes:               o.AttestationTypes,
+					Policies:                     o.Policies,
+					PolicyEngine:                 options.PolicyEngineAuto,
+					Output:                       o.Output,
+					LocalImage:                   o.LocalImage,
+					ContinueOnError:              o.ContinueOnError,
+					Platform:                     o.Platform,
+					NameOptions:                  o.Registry.NameOptions(),
+					Offline:                      o.CommonVerifyOptions.Offline,
+					TSACertChainPath:             o.CommonVerifyOptions.TSACertChainPath,
+					IgnoreTlog:                   o.CommonVerifyOptions.IgnoreTlog,
+					RequireOnlineTlog:            o.CommonVerifyOptions.RequireOnlineTlog,
+					RekorCheckpoint:              o.CommonVerifyOptions.RekorCheckpoint,
+					RekorPublicKey:               o.CommonVerifyOptions.RekorPublicKey,
+					MaxSignatureAge:              o.CommonVerifyOptions.MaxSignatureAge,
+					MaxWorkers:                   o.CommonVerifyOptions.MaxWorkers,
+					RevocationList:               o.Revocation.List,
+					RevocationListKey:            o.Revocation.Key,
+					RevocationListTTL:            o.Revocation.TTL,
+				}
+			} else if len(o.Policies) > 0 {
+				return fmt.Errorf("--policy requires --attestation-type")
+			}
+
 			if o.CommonVerifyOptions.MaxWorkers == 0 {
 				return fmt.Errorf("please set the --max-worker flag to a value that is greater than 0")
 			}
@@ -119,3 +194,46 @@ against the transparency log.`,
 
 	return cmd
 }
+
+func VerifyTree() *cobra.Command {
+	o := &options.VerifyTreeOptions{}
+
+	cmd := &cobra.Command{
+		Use:   "verify-tree",
+		Short: "Verify the images of the Kubernetes manifests of a directory tree against ClusterImagePolicy resources",
+		Long: `Verify the images referenced by the Kubernetes manifests of a directory tree,
+e.g. a Flux or Argo CD repository, against the ClusterImagePolicy resources
+enforced by cosign-webhook, before they are deployed.
+
+Every YAML file of the tree is read, except in hidden directories; files that
+aren't Kubernetes manifests are skipped. The images of a Helm chart are those of
+its values. Images are verified in parallel, and the summary written with
+--summary can be used as the report of a pull request check.`,
+		Example: `  cosign verify-tree --policy <path/to/policies.yaml> <path/to/tree>
+
+  # verify the images of a GitOps repository, failing images no policy matches
+  cosign verify-tree --policy policies.yaml --deny-unmatched clusters/production
+
+  # write the summary of a GitHub Actions job
+  cosign verify-tree --policy policies.yaml --summary "$GITHUB_STEP_SUMMARY" .`,
+		Args:             cobra.ExactArgs(1),
+		PersistentPreRun: options.BindViper,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			v := &manifest.VerifyTreeCommand{
+				RegistryOptions: o.Registry,
+				RekorURL:        o.Rekor.URL,
+				PolicyPath:      o.Policy,
+				DenyUnmatched:   o.DenyUnmatched,
+				IgnoreTlog:      o.IgnoreTlog,
+				MaxWorkers:      o.MaxWorkers,
+				SummaryPath:     o.Summary,
+				SummaryFormat:   o.SummaryFormat,
+			}
+			return v.Exec(cmd.Context(), args)
+		},
+	}
+
+	o.AddFlags(cmd)
+
+	return cmd
+}
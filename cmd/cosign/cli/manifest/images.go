@@ -0,0 +1,307 @@
+//
+// Copyright 2023 The Sigstore Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package manifest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"sigs.k8s.io/yaml"
+
+	"github.com/sigstore/cosign/v2/internal/ui"
+)
+
+// kustomizationFiles are the names kustomize reads a kustomization from.
+var kustomizationFiles = []string{"kustomization.yaml", "kustomization.yml", "Kustomization"}
+
+// getImagesFromPath returns the distinct images of the manifest at path,
+// which is a YAML file of Kubernetes resources, a kustomization directory
+// or a Helm chart directory. The values of Helm charts are merged with
+// valuesFiles, as by helm --values.
+func getImagesFromPath(ctx context.Context, path string, valuesFiles []string) ([]string, error) {
+	fi, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+	var images []string
+	switch {
+	case !fi.IsDir():
+		if len(valuesFiles) > 0 {
+			return nil, errors.New("values files can only be used with a Helm chart")
+		}
+		if err := isExtensionAllowed(path); err != nil {
+			return nil, fmt.Errorf("check if extension is valid: %w", err)
+		}
+		manifest, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("could not read manifest: %w", err)
+		}
+		images, err = getImagesFromYamlManifest(manifest)
+		if err != nil {
+			return nil, fmt.Errorf("unable to extract the container image references in the manifest %w", err)
+		}
+	case kustomizationFile(path) != "":
+		if len(valuesFiles) > 0 {
+			return nil, errors.New("values files can only be used with a Helm chart")
+		}
+		images, err = getImagesFromKustomization(ctx, path, map[string]bool{})
+	case fileExists(filepath.Join(path, "Chart.yaml")):
+		images, err = getImagesFromHelmChart(path, valuesFiles)
+	default:
+		return nil, fmt.Errorf("%s is neither a kustomization nor a Helm chart directory", path)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return unique(images), nil
+}
+
+// kustomization is the part of a kustomization listing resources and
+// images.
+type kustomization struct {
+	Resources []string
+	// Bases is the deprecated form of Resources.
+	Bases  []string
+	Images []kustomizeImage
+}
+
+// kustomizeImage overrides the images named Name.
+type kustomizeImage struct {
+	Name    string `json:"name"`
+	NewName string `json:"newName"`
+	NewTag  string `json:"newTag"`
+	Digest  string `json:"digest"`
+}
+
+// getImagesFromKustomization returns the images of the local resources of
+// the kustomization of dir, with its image overrides applied. Remote
+// resources are skipped. visited guards against cyclic kustomizations.
+func getImagesFromKustomization(ctx context.Context, dir string, visited map[string]bool) ([]string, error) {
+	abs, err := filepath.Abs(dir)
+	if err != nil {
+		return nil, err
+	}
+	if visited[abs] {
+		return nil, fmt.Errorf("kustomization %s includes itself", dir)
+	}
+	visited[abs] = true
+	defer delete(visited, abs)
+
+	file := kustomizationFile(dir)
+	b, err := os.ReadFile(file)
+	if err != nil {
+		return nil, fmt.Errorf("could not read kustomization: %w", err)
+	}
+	var k kustomization
+	if err := yaml.Unmarshal(b, &k); err != nil {
+		return nil, fmt.Errorf("unable to decode kustomization %s: %w", file, err)
+	}
+
+	var images []string
+	for _, r := range append(k.Resources, k.Bases...) {
+		if strings.Contains(r, "://") || strings.HasPrefix(r, "github.com/") {
+			ui.Warnf(ctx, "skipping remote resource %s of kustomization %s", r, file)
+			continue
+		}
+		path := filepath.Join(dir, r)
+		fi, err := os.Stat(path)
+		if err != nil {
+			return nil, fmt.Errorf("resource %s of kustomization %s: %w", r, file, err)
+		}
+		var resourceImages []string
+		if fi.IsDir() {
+			resourceImages, err = getImagesFromKustomization(ctx, path, visited)
+		} else {
+			var manifest []byte
+			if manifest, err = os.ReadFile(path); err == nil {
+				resourceImages, err = getImagesFromYamlManifest(manifest)
+			}
+		}
+		if err != nil {
+			return nil, fmt.Errorf("resource %s of kustomization %s: %w", r, file, err)
+		}
+		images = append(images, resourceImages...)
+	}
+	for i, image := range images {
+		images[i] = overrideImage(image, k.Images)
+	}
+	return images, nil
+}
+
+// overrideImage applies the first of overrides naming image to it.
+func overrideImage(image string, overrides []kustomizeImage) string {
+	name, tag, digest := splitImage(image)
+	for _, o := range overrides {
+		if o.Name != name {
+			continue
+		}
+		if o.NewName != "" {
+			name = o.NewName
+		}
+		switch {
+		case o.Digest != "":
+			return name + "@" + o.Digest
+		case o.NewTag != "":
+			return name + ":" + o.NewTag
+		case digest != "":
+			return name + "@" + digest
+		case tag != "":
+			return name + ":" + tag
+		default:
+			return name
+		}
+	}
+	return image
+}
+
+// splitImage splits image into its name, tag and digest, which may be empty.
+func splitImage(image string) (name, tag, digest string) {
+	name = image
+	if i := strings.Index(name, "@"); i >= 0 {
+		name, digest = name[:i], name[i+1:]
+	}
+	// A colon after the last slash separates the tag, others a registry port.
+	if i := strings.LastIndex(name, ":"); i > strings.LastIndex(name, "/") {
+		name, tag = name[:i], name[i+1:]
+	}
+	return name, tag, digest
+}
+
+// chart is the part of a Chart.yaml defaulting image tags.
+type chart struct {
+	AppVersion string `json:"appVersion"`
+}
+
+// getImagesFromHelmChart returns the images of the values of the Helm chart
+// of dir, merged with valuesFiles. Templates are not rendered, so only the
+// images given by values are found: image strings, and maps of a repository
+// with an optional registry and tag or digest, the tag defaulting to the
+// appVersion of the chart.
+func getImagesFromHelmChart(dir string, valuesFiles []string) ([]string, error) {
+	b, err := os.ReadFile(filepath.Join(dir, "Chart.yaml"))
+	if err != nil {
+		return nil, fmt.Errorf("could not read chart: %w", err)
+	}
+	var c chart
+	if err := yaml.Unmarshal(b, &c); err != nil {
+		return nil, fmt.Errorf("unable to decode Chart.yaml: %w", err)
+	}
+
+	values := map[string]interface{}{}
+	files := valuesFiles
+	if defaults := filepath.Join(dir, "values.yaml"); fileExists(defaults) {
+		files = append([]string{defaults}, valuesFiles...)
+	}
+	for _, f := range files {
+		b, err := os.ReadFile(f)
+		if err != nil {
+			return nil, fmt.Errorf("could not read values: %w", err)
+		}
+		v := map[string]interface{}{}
+		if err := yaml.Unmarshal(b, &v); err != nil {
+			return nil, fmt.Errorf("unable to decode values %s: %w", f, err)
+		}
+		mergeValues(values, v)
+	}
+	return imagesFromValues(values, c.AppVersion), nil
+}
+
+// mergeValues merges src into dst, the maps of both being merged and the
+// other values of src replacing those of dst.
+func mergeValues(dst, src map[string]interface{}) {
+	for k, v := range src {
+		srcMap, ok := v.(map[string]interface{})
+		dstMap, dstOk := dst[k].(map[string]interface{})
+		if ok && dstOk {
+			mergeValues(dstMap, srcMap)
+			continue
+		}
+		dst[k] = v
+	}
+}
+
+// imagesFromValues returns the images of the Helm values v, walking maps in
+// key order.
+func imagesFromValues(v interface{}, appVersion string) []string {
+	var images []string
+	switch v := v.(type) {
+	case map[string]interface{}:
+		if repository, ok := v["repository"].(string); ok && repository != "" {
+			image := repository
+			if registry, ok := v["registry"].(string); ok && registry != "" {
+				image = registry + "/" + image
+			}
+			tag := appVersion
+			if t, ok := v["tag"]; ok && t != nil && fmt.Sprint(t) != "" {
+				tag = fmt.Sprint(t)
+			}
+			if digest, ok := v["digest"].(string); ok && digest != "" {
+				image += "@" + digest
+			} else if tag != "" {
+				image += ":" + tag
+			}
+			return []string{image}
+		}
+		keys := make([]string, 0, len(v))
+		for k := range v {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			if image, ok := v[k].(string); ok && k == "image" && image != "" {
+				images = append(images, image)
+				continue
+			}
+			images = append(images, imagesFromValues(v[k], appVersion)...)
+		}
+	case []interface{}:
+		for _, e := range v {
+			images = append(images, imagesFromValues(e, appVersion)...)
+		}
+	}
+	return images
+}
+
+func kustomizationFile(dir string) string {
+	for _, name := range kustomizationFiles {
+		if path := filepath.Join(dir, name); fileExists(path) {
+			return path
+		}
+	}
+	return ""
+}
+
+func fileExists(path string) bool {
+	fi, err := os.Stat(path)
+	return err == nil && !fi.IsDir()
+}
+
+func unique(images []string) []string {
+	seen := map[string]bool{}
+	var result []string
+	for _, image := range images {
+		if !seen[image] {
+			seen[image] = true
+			result = append(result, image)
+		}
+	}
+	return result
+}
@@ -0,0 +1,192 @@
+//
+// Copyright 2023 The Sigstore Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package manifest
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func writeFiles(t *testing.T, dir string, files map[string]string) {
+	t.Helper()
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestGetImagesFromKustomization(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{
+		"base/kustomization.yaml": `
+resources:
+- pod.yaml
+- https://github.com/example/remote
+`,
+		"base/pod.yaml": singleContainerManifest,
+		"overlay/kustomization.yaml": `
+resources:
+- ../base
+- deployment.yaml
+images:
+- name: nginx
+  newName: registry.example.com/nginx
+  newTag: 1.25.0
+- name: busybox
+  digest: sha256:b5d6fe0712636ceb7430189de28819e195e8966372edfc2d9409d79402a0dc16
+`,
+		"overlay/deployment.yaml": `
+apiVersion: apps/v1
+kind: Deployment
+spec:
+  template:
+    spec:
+      containers:
+      - image: busybox:1.36
+      - image: localhost:5000/app:v1
+`,
+	})
+
+	got, err := getImagesFromPath(context.Background(), filepath.Join(dir, "overlay"), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{
+		"registry.example.com/nginx:1.25.0",
+		"busybox@sha256:b5d6fe0712636ceb7430189de28819e195e8966372edfc2d9409d79402a0dc16",
+		"localhost:5000/app:v1",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("getImagesFromPath() = %v, want %v", got, want)
+	}
+}
+
+func TestGetImagesFromKustomizationCycle(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{
+		"a/kustomization.yaml": "resources:\n- ../b\n",
+		"b/kustomization.yaml": "resources:\n- ../a\n",
+	})
+	if _, err := getImagesFromPath(context.Background(), filepath.Join(dir, "a"), nil); err == nil {
+		t.Error("getImagesFromPath() of a cyclic kustomization succeeded")
+	}
+}
+
+func TestGetImagesFromHelmChart(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{
+		"chart/Chart.yaml": "name: app\nversion: 0.1.0\nappVersion: 2.0.0\n",
+		"chart/values.yaml": `
+image:
+  repository: example/app
+  tag: ""
+sidecar:
+  image: busybox:1.36
+proxy:
+  image:
+    registry: ghcr.io
+    repository: example/proxy
+    tag: v1
+    digest: sha256:b5d6fe0712636ceb7430189de28819e195e8966372edfc2d9409d79402a0dc16
+`,
+		"prod.yaml": `
+image:
+  tag: 2.0.1
+sidecar:
+  image: busybox:1.36
+`,
+	})
+
+	chart := filepath.Join(dir, "chart")
+	tests := []struct {
+		name   string
+		values []string
+		want   []string
+	}{{
+		name: "defaults",
+		want: []string{
+			"example/app:2.0.0",
+			"ghcr.io/example/proxy@sha256:b5d6fe0712636ceb7430189de28819e195e8966372edfc2d9409d79402a0dc16",
+			"busybox:1.36",
+		},
+	}, {
+		name:   "values",
+		values: []string{filepath.Join(dir, "prod.yaml")},
+		want: []string{
+			"example/app:2.0.1",
+			"ghcr.io/example/proxy@sha256:b5d6fe0712636ceb7430189de28819e195e8966372edfc2d9409d79402a0dc16",
+			"busybox:1.36",
+		},
+	}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := getImagesFromPath(context.Background(), chart, tt.values)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("getImagesFromPath() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGetImagesFromPathErrors(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{
+		"pod.yaml":    singleContainerManifest,
+		"pod.json":    "{}",
+		"empty/.keep": "",
+	})
+	for _, tt := range []struct {
+		path   string
+		values []string
+	}{
+		{path: filepath.Join(dir, "pod.json")},
+		{path: filepath.Join(dir, "pod.yaml"), values: []string{"values.yaml"}},
+		{path: filepath.Join(dir, "empty")},
+		{path: filepath.Join(dir, "missing.yaml")},
+	} {
+		if _, err := getImagesFromPath(context.Background(), tt.path, tt.values); err == nil {
+			t.Errorf("getImagesFromPath(%s, %v) succeeded", tt.path, tt.values)
+		}
+	}
+}
+
+func TestSplitImage(t *testing.T) {
+	tests := []struct {
+		image, name, tag, digest string
+	}{
+		{image: "nginx", name: "nginx"},
+		{image: "nginx:1.25", name: "nginx", tag: "1.25"},
+		{image: "localhost:5000/nginx", name: "localhost:5000/nginx"},
+		{image: "localhost:5000/nginx:1.25@sha256:abc", name: "localhost:5000/nginx", tag: "1.25", digest: "sha256:abc"},
+	}
+	for _, tt := range tests {
+		name, tag, digest := splitImage(tt.image)
+		if name != tt.name || tag != tt.tag || digest != tt.digest {
+			t.Errorf("splitImage(%s) = %s, %s, %s, want %s, %s, %s", tt.image, name, tag, digest, tt.name, tt.tag, tt.digest)
+		}
+	}
+}
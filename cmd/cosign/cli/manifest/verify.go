@@ -21,18 +21,23 @@ import (
 	"flag"
 	"fmt"
 	"io"
-	"os"
 	"path/filepath"
 	"strings"
 
 	"k8s.io/apimachinery/pkg/util/yaml"
 
 	"github.com/sigstore/cosign/v2/cmd/cosign/cli/verify"
+	"github.com/sigstore/cosign/v2/internal/ui"
 )
 
 // VerifyManifestCommand verifies all image signatures on a supplied k8s resource
 type VerifyManifestCommand struct {
 	verify.VerifyCommand
+	// ValuesFiles are merged with the values of a Helm chart.
+	ValuesFiles []string
+	// VerifyAttestation, when set, also verifies the attestations of the
+	// images.
+	VerifyAttestation *verify.VerifyAttestationCommand
 }
 
 // Exec runs the verification command
@@ -41,27 +46,20 @@ func (c *VerifyManifestCommand) Exec(ctx context.Context, args []string) error {
 		return flag.ErrHelp
 	}
 
-	manifestPath := args[0]
-
-	err := isExtensionAllowed(manifestPath)
-	if err != nil {
-		return fmt.Errorf("check if extension is valid: %w", err)
-	}
-	manifest, err := os.ReadFile(manifestPath)
+	images, err := getImagesFromPath(ctx, args[0], c.ValuesFiles)
 	if err != nil {
-		return fmt.Errorf("could not read manifest: %w", err)
-	}
-
-	images, err := getImagesFromYamlManifest(manifest)
-	if err != nil {
-		return fmt.Errorf("unable to extract the container image references in the manifest %w", err)
+		return err
 	}
 	if len(images) == 0 {
 		return errors.New("no images found in manifest")
 	}
-	fmt.Fprintf(os.Stderr, "Extracted image(s): %s\n", strings.Join(images, ", "))
+	ui.Infof(ctx, "Extracted image(s): %s", strings.Join(images, ", "))
 
-	return c.VerifyCommand.Exec(ctx, images)
+	err = c.VerifyCommand.Exec(ctx, images)
+	if c.VerifyAttestation == nil || (err != nil && !c.ContinueOnError) {
+		return err
+	}
+	return errors.Join(err, c.VerifyAttestation.Exec(ctx, images))
 }
 
 // unionImagesKind is the union type that match PodSpec, PodSpecTemplate, and
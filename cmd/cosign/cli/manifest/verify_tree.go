@@ -0,0 +1,282 @@
+//
+// Copyright 2023 The Sigstore Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package manifest
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"golang.org/x/sync/errgroup"
+
+	"github.com/sigstore/cosign/v2/cmd/cosign/cli/options"
+	"github.com/sigstore/cosign/v2/cmd/cosign/cli/verify"
+	"github.com/sigstore/cosign/v2/cmd/cosign/cli/webhookcli"
+	"github.com/sigstore/cosign/v2/internal/pkg/cosign"
+	"github.com/sigstore/cosign/v2/internal/ui"
+	"github.com/sigstore/cosign/v2/pkg/webhook"
+)
+
+// Statuses of the images of a tree.
+const (
+	TreeImageVerified  = "verified"
+	TreeImageFailed    = "failed"
+	TreeImageUnmatched = "unmatched"
+)
+
+// VerifyTreeCommand verifies the images of the Kubernetes manifests of a
+// directory tree, e.g. a Flux or Argo CD repository, against the
+// ClusterImagePolicy resources cosign-webhook enforces.
+type VerifyTreeCommand struct {
+	options.RegistryOptions
+	RekorURL      string
+	PolicyPath    string
+	DenyUnmatched bool
+	IgnoreTlog    bool
+	MaxWorkers    int
+	SummaryPath   string
+	SummaryFormat string
+}
+
+// TreeImageResult is the verification result of an image of a tree.
+type TreeImageResult struct {
+	Image string `json:"image"`
+	// Files are the manifests referencing the image, relative to the root
+	// of the tree.
+	Files    []string `json:"files"`
+	Status   string   `json:"status"`
+	Policies []string `json:"policies,omitempty"`
+	Warnings []string `json:"warnings,omitempty"`
+	Error    string   `json:"error,omitempty"`
+}
+
+// Exec runs the verification command
+func (c *VerifyTreeCommand) Exec(ctx context.Context, args []string) error {
+	if len(args) != 1 {
+		return flag.ErrHelp
+	}
+	if c.SummaryFormat != "markdown" && c.SummaryFormat != "json" {
+		return fmt.Errorf("invalid --summary-format %q, expected markdown or json", c.SummaryFormat)
+	}
+
+	f, err := os.Open(c.PolicyPath)
+	if err != nil {
+		return fmt.Errorf("opening policy: %w", err)
+	}
+	defer f.Close()
+	policies, err := webhook.ReadClusterImagePolicies(f)
+	if err != nil {
+		return err
+	}
+	if len(policies) == 0 {
+		return fmt.Errorf("no ClusterImagePolicy in %s", c.PolicyPath)
+	}
+
+	files, err := getImagesFromTree(ctx, args[0])
+	if err != nil {
+		return err
+	}
+	if len(files) == 0 {
+		return fmt.Errorf("no images found in %s", args[0])
+	}
+
+	verifier, err := webhookcli.NewVerifier(ctx, c.RegistryOptions, c.RekorURL, c.IgnoreTlog, true)
+	if err != nil {
+		return err
+	}
+	wh := &webhook.Webhook{
+		Policies:      webhook.NewStaticPolicyLister(policies),
+		Verifier:      verifier,
+		DenyUnmatched: c.DenyUnmatched,
+	}
+	results := verifyTreeImages(ctx, wh, files, c.MaxWorkers)
+
+	failed := 0
+	for _, r := range results {
+		if r.Status == TreeImageFailed {
+			failed++
+		}
+	}
+	printTreeSummary(ctx, results)
+	if c.SummaryPath != "" {
+		if err := writeTreeSummary(c.SummaryPath, c.SummaryFormat, results); err != nil {
+			return err
+		}
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d of %d images failed verification", failed, len(results))
+	}
+	return nil
+}
+
+// getImagesFromTree returns the images of the YAML manifests under root,
+// each with the files referencing it. Hidden directories, e.g. .git, are
+// skipped. The images of a Helm chart are those of its values, and the YAML
+// files that aren't Kubernetes manifests are skipped.
+func getImagesFromTree(ctx context.Context, root string) (map[string][]string, error) {
+	files := map[string][]string{}
+	add := func(path string, images []string) {
+		rel, err := filepath.Rel(root, path)
+		if err != nil {
+			rel = path
+		}
+		for _, image := range unique(images) {
+			files[image] = append(files[image], filepath.ToSlash(rel))
+		}
+	}
+	err := filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			if path != root && strings.HasPrefix(d.Name(), ".") {
+				return filepath.SkipDir
+			}
+			if fileExists(filepath.Join(path, "Chart.yaml")) {
+				images, err := getImagesFromHelmChart(path, nil)
+				if err != nil {
+					return err
+				}
+				add(filepath.Join(path, "values.yaml"), images)
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if isExtensionAllowed(path) != nil {
+			return nil
+		}
+		manifest, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		images, err := getImagesFromYamlManifest(manifest)
+		if err != nil {
+			ui.Warnf(ctx, "skipping %s: %v", path, err)
+			return nil
+		}
+		add(path, images)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return files, nil
+}
+
+// verifyTreeImages verifies the images of files with wh, at most workers at
+// once, returning their results sorted by image.
+func verifyTreeImages(ctx context.Context, wh *webhook.Webhook, files map[string][]string, workers int) []TreeImageResult {
+	if workers <= 0 {
+		workers = cosign.DefaultMaxWorkers
+	}
+	results := make([]TreeImageResult, 0, len(files))
+	for image, f := range files {
+		results = append(results, TreeImageResult{Image: image, Files: f})
+	}
+	sort.Slice(results, func(i, j int) bool { return results[i].Image < results[j].Image })
+
+	var g errgroup.Group
+	g.SetLimit(workers)
+	for i := range results {
+		r := &results[i]
+		g.Go(func() error {
+			policies, warnings, err := wh.VerifyImage(ctx, "", r.Image)
+			r.Policies, r.Warnings = policies, warnings
+			switch {
+			case err != nil:
+				r.Status = TreeImageFailed
+				r.Error = err.Error()
+			case len(policies) == 0:
+				r.Status = TreeImageUnmatched
+			default:
+				r.Status = TreeImageVerified
+			}
+			return nil
+		})
+	}
+	_ = g.Wait()
+	return results
+}
+
+// printTreeSummary prints the status of every image of results.
+func printTreeSummary(ctx context.Context, results []TreeImageResult) {
+	rows := make([]verify.StatusRow, len(results))
+	for i, r := range results {
+		rows[i] = verify.StatusRow{Image: r.Image, Status: r.Status, Error: r.Error}
+	}
+	verify.PrintStatusTable(ctx, rows, "")
+}
+
+// writeTreeSummary writes results to path in format, markdown or json.
+func writeTreeSummary(path, format string, results []TreeImageResult) error {
+	var b []byte
+	if format == "json" {
+		var err error
+		if b, err = json.MarshalIndent(results, "", "  "); err != nil {
+			return err
+		}
+		b = append(b, '\n')
+	} else {
+		b = markdownTreeSummary(results)
+	}
+	if err := os.WriteFile(path, b, 0600); err != nil {
+		return fmt.Errorf("writing summary: %w", err)
+	}
+	return nil
+}
+
+// markdownTreeSummary renders results as a Markdown table, e.g. for the
+// summary of a pull request check.
+func markdownTreeSummary(results []TreeImageResult) []byte {
+	counts := map[string]int{}
+	for _, r := range results {
+		counts[r.Status]++
+	}
+	var b bytes.Buffer
+	fmt.Fprintf(&b, "## cosign verify-tree\n\n%d images: %d verified, %d failed, %d unmatched by any policy.\n\n",
+		len(results), counts[TreeImageVerified], counts[TreeImageFailed], counts[TreeImageUnmatched])
+	fmt.Fprintln(&b, "| Image | Status | Files | Details |")
+	fmt.Fprintln(&b, "| --- | --- | --- | --- |")
+	for _, r := range results {
+		details := r.Error
+		if details == "" {
+			details = strings.Join(append(append([]string{}, r.Policies...), r.Warnings...), "; ")
+		}
+		fmt.Fprintf(&b, "| `%s` | %s | %s | %s |\n", r.Image, r.Status, markdownCode(r.Files), markdownCell(details))
+	}
+	return b.Bytes()
+}
+
+func markdownCode(values []string) string {
+	quoted := make([]string, len(values))
+	for i, v := range values {
+		quoted[i] = "`" + v + "`"
+	}
+	return strings.Join(quoted, "<br>")
+}
+
+// markdownCell escapes s for a cell of a Markdown table.
+func markdownCell(s string) string {
+	s = strings.ReplaceAll(s, "|", `\|`)
+	return strings.ReplaceAll(s, "\n", "<br>")
+}
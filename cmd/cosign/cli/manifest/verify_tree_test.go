@@ -0,0 +1,147 @@
+//
+// Copyright 2023 The Sigstore Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package manifest
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/google/go-containerregistry/pkg/name"
+
+	"github.com/sigstore/cosign/v2/pkg/webhook"
+)
+
+func TestGetImagesFromTree(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{
+		"apps/nginx.yaml":                      singleContainerManifest,
+		"apps/nginx-canary.yml":                singleContainerManifest,
+		"apps/README.md":                       "nginx:latest",
+		"apps/chart/Chart.yaml":                "name: app\nversion: 0.1.0\nappVersion: 1.0.0\n",
+		"apps/chart/values.yaml":               "image:\n  repository: example/app\n",
+		"apps/chart/templates/deployment.yaml": "image: {{ .Values.image.repository }}\n",
+		"infra/not-a-manifest.yaml":            "- a\n- b\n",
+		".github/workflows/ci.yaml":            initContainerManifest,
+	})
+
+	got, err := getImagesFromTree(context.Background(), dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string][]string{
+		"nginx:1.21.1":      {"apps/nginx-canary.yml", "apps/nginx.yaml"},
+		"example/app:1.0.0": {"apps/chart/values.yaml"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("getImagesFromTree() = %v, want %v", got, want)
+	}
+}
+
+// fakeVerifier accepts the repositories in signed.
+type fakeVerifier struct {
+	signed map[string]bool
+}
+
+func (v *fakeVerifier) ResolveDigest(ref name.Reference) (name.Digest, error) {
+	return ref.Context().Digest("sha256:0000000000000000000000000000000000000000000000000000000000000000"), nil
+}
+
+func (v *fakeVerifier) Verify(_ context.Context, _ string, digest name.Digest, _ *webhook.ImagePolicySpec) ([]string, error) {
+	if !v.signed[digest.Context().Name()] {
+		return nil, errors.New("no matching signatures")
+	}
+	return nil, nil
+}
+
+func TestVerifyTreeImages(t *testing.T) {
+	policies, err := webhook.ReadClusterImagePolicies(strings.NewReader(`
+apiVersion: cosign.sigstore.dev/v1alpha1
+kind: ClusterImagePolicy
+metadata:
+  name: example
+spec:
+  images:
+  - glob: ghcr.io/example/**
+  authorities:
+  - key:
+      kms: gcpkms://projects/example/locations/global/keyRings/example/cryptoKeys/example
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	wh := &webhook.Webhook{
+		Policies: webhook.NewStaticPolicyLister(policies),
+		Verifier: &fakeVerifier{signed: map[string]bool{"ghcr.io/example/signed": true}},
+	}
+	files := map[string][]string{
+		"ghcr.io/example/signed:v1":   {"a.yaml"},
+		"ghcr.io/example/unsigned:v1": {"b.yaml"},
+		"nginx:1.25":                  {"c.yaml"},
+	}
+
+	results := verifyTreeImages(context.Background(), wh, files, 2)
+	var got []string
+	for _, r := range results {
+		got = append(got, r.Image+" "+r.Status)
+	}
+	want := []string{
+		"ghcr.io/example/signed:v1 verified",
+		"ghcr.io/example/unsigned:v1 failed",
+		"nginx:1.25 unmatched",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("verifyTreeImages() = %v, want %v", got, want)
+	}
+
+	summary := string(markdownTreeSummary(results))
+	for _, s := range []string{
+		"3 images: 1 verified, 1 failed, 1 unmatched by any policy.",
+		"| `ghcr.io/example/unsigned:v1` | failed | `b.yaml` | cluster image policy example: ",
+	} {
+		if !strings.Contains(summary, s) {
+			t.Errorf("summary %s does not contain %q", summary, s)
+		}
+	}
+}
+
+func TestVerifyTreeSummaryFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "summary.json")
+	results := []TreeImageResult{{Image: "nginx:1.25", Files: []string{"a.yaml"}, Status: TreeImageUnmatched}}
+	if err := writeTreeSummary(path, "json", results); err != nil {
+		t.Fatal(err)
+	}
+	b, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []TreeImageResult
+	if err := json.Unmarshal(b, &got); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(got, results) {
+		t.Errorf("summary = %v, want %v", got, results)
+	}
+
+	if err := (&VerifyTreeCommand{SummaryFormat: "html"}).Exec(context.Background(), []string{"."}); err == nil {
+		t.Error("Exec() with an invalid summary format succeeded")
+	}
+}
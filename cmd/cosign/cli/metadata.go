@@ -0,0 +1,159 @@
+//
+// Copyright 2023 The Sigstore Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+
+	"github.com/sigstore/cosign/v2/cmd/cosign/cli/options"
+)
+
+// Commands returns the hidden command describing the commands of cosign and
+// their flags, for the tools wrapping cosign.
+func Commands() *cobra.Command {
+	o := &options.CommandsOptions{}
+
+	cmd := &cobra.Command{
+		Use:    "commands",
+		Short:  "Prints the commands of cosign and their flags",
+		Hidden: true,
+		Args:   cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			root := cmd.Root()
+			if !o.JSON {
+				printCommands(cmd.OutOrStdout(), root)
+				return nil
+			}
+			enc := json.NewEncoder(cmd.OutOrStdout())
+			enc.SetIndent("", "  ")
+			return enc.Encode(describeCommand(root))
+		},
+	}
+
+	o.AddFlags(cmd)
+	return cmd
+}
+
+// commandMetadata describes a command, its flags and its sub-commands.
+type commandMetadata struct {
+	Path       string            `json:"path"`
+	Use        string            `json:"use"`
+	Aliases    []string          `json:"aliases,omitempty"`
+	Short      string            `json:"short"`
+	Long       string            `json:"long,omitempty"`
+	Example    string            `json:"example,omitempty"`
+	Deprecated string            `json:"deprecated,omitempty"`
+	Runnable   bool              `json:"runnable"`
+	Flags      []flagMetadata    `json:"flags,omitempty"`
+	Commands   []commandMetadata `json:"commands,omitempty"`
+}
+
+// flagMetadata describes a flag. The persistent flags of a command are
+// described once, on the command defining them, and the hidden and
+// deprecated flags aren't described.
+type flagMetadata struct {
+	Name       string `json:"name"`
+	Shorthand  string `json:"shorthand,omitempty"`
+	Type       string `json:"type"`
+	Default    string `json:"default,omitempty"`
+	Usage      string `json:"usage"`
+	Persistent bool   `json:"persistent,omitempty"`
+	Required   bool   `json:"required,omitempty"`
+	// MutuallyExclusive are the groups of flags this flag can't be set
+	// with, and RequiredTogether the groups it must be set with.
+	MutuallyExclusive [][]string `json:"mutuallyExclusive,omitempty"`
+	RequiredTogether  [][]string `json:"requiredTogether,omitempty"`
+}
+
+// Annotations of the flag groups, see cobra.Command.MarkFlagsMutuallyExclusive
+// and cobra.Command.MarkFlagsRequiredTogether.
+const (
+	mutuallyExclusiveAnnotation = "cobra_annotation_mutually_exclusive"
+	requiredTogetherAnnotation  = "cobra_annotation_required_if_others_set"
+)
+
+func describeCommand(cmd *cobra.Command) commandMetadata {
+	m := commandMetadata{
+		Path:       cmd.CommandPath(),
+		Use:        cmd.Use,
+		Aliases:    cmd.Aliases,
+		Short:      cmd.Short,
+		Long:       cmd.Long,
+		Example:    cmd.Example,
+		Deprecated: cmd.Deprecated,
+		Runnable:   cmd.Runnable(),
+	}
+	persistent := cmd.PersistentFlags()
+	cmd.LocalFlags().VisitAll(func(f *pflag.Flag) {
+		if f.Hidden {
+			return
+		}
+		m.Flags = append(m.Flags, describeFlag(f, persistent.Lookup(f.Name) != nil))
+	})
+	for _, c := range cmd.Commands() {
+		if c.Hidden || c.Name() == "help" {
+			continue
+		}
+		m.Commands = append(m.Commands, describeCommand(c))
+	}
+	return m
+}
+
+func describeFlag(f *pflag.Flag, persistent bool) flagMetadata {
+	m := flagMetadata{
+		Name:              f.Name,
+		Shorthand:         f.Shorthand,
+		Type:              f.Value.Type(),
+		Usage:             f.Usage,
+		Persistent:        persistent,
+		Required:          len(f.Annotations[cobra.BashCompOneRequiredFlag]) > 0 && f.Annotations[cobra.BashCompOneRequiredFlag][0] == "true",
+		MutuallyExclusive: flagGroups(f, mutuallyExclusiveAnnotation),
+		RequiredTogether:  flagGroups(f, requiredTogetherAnnotation),
+	}
+	if f.DefValue != "" && f.DefValue != "[]" && !(m.Type == "bool" && f.DefValue == "false") {
+		m.Default = f.DefValue
+	}
+	return m
+}
+
+// flagGroups returns the groups of flags of f in annotation, each listing
+// the names of its flags.
+func flagGroups(f *pflag.Flag, annotation string) [][]string {
+	var groups [][]string
+	for _, g := range f.Annotations[annotation] {
+		groups = append(groups, strings.Fields(g))
+	}
+	return groups
+}
+
+// printCommands prints the path of cmd and its sub-commands, one per line.
+func printCommands(w io.Writer, cmd *cobra.Command) {
+	if cmd.Runnable() {
+		fmt.Fprintln(w, cmd.CommandPath())
+	}
+	for _, c := range cmd.Commands() {
+		if c.Hidden || c.Name() == "help" {
+			continue
+		}
+		printCommands(w, c)
+	}
+}
@@ -0,0 +1,78 @@
+//
+// Copyright 2023 The Sigstore Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cli
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/spf13/cobra"
+)
+
+func testCommands() *cobra.Command {
+	root := &cobra.Command{Use: "cosign"}
+	root.PersistentFlags().String("output-file", "", "output file")
+
+	sign := &cobra.Command{Use: "sign", Short: "Sign", Run: func(*cobra.Command, []string) {}}
+	sign.Flags().String("key", "", "key")
+	sign.Flags().String("sk", "", "security key")
+	sign.Flags().String("hidden", "", "hidden")
+	_ = sign.Flags().MarkHidden("hidden")
+	sign.MarkFlagsMutuallyExclusive("key", "sk")
+
+	root.AddCommand(sign, &cobra.Command{Use: "internal", Hidden: true, Run: func(*cobra.Command, []string) {}})
+	return root
+}
+
+func TestDescribeCommand(t *testing.T) {
+	got := describeCommand(testCommands())
+
+	if len(got.Flags) != 1 || got.Flags[0].Name != "output-file" || !got.Flags[0].Persistent {
+		t.Errorf("root flags = %+v, want the persistent output-file", got.Flags)
+	}
+	if len(got.Commands) != 1 {
+		t.Fatalf("commands = %+v, want only sign", got.Commands)
+	}
+	sign := got.Commands[0]
+	if sign.Path != "cosign sign" || !sign.Runnable {
+		t.Errorf("sign = %+v", sign)
+	}
+	var names []string
+	for _, f := range sign.Flags {
+		names = append(names, f.Name)
+		if want := [][]string{{"key", "sk"}}; !reflect.DeepEqual(f.MutuallyExclusive, want) {
+			t.Errorf("%s mutually exclusive = %v, want %v", f.Name, f.MutuallyExclusive, want)
+		}
+	}
+	if want := []string{"key", "sk"}; !reflect.DeepEqual(names, want) {
+		t.Errorf("sign flags = %v, want %v", names, want)
+	}
+
+	if _, err := json.Marshal(got); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestPrintCommands(t *testing.T) {
+	var b bytes.Buffer
+	printCommands(&b, testCommands())
+	if got := strings.TrimSpace(b.String()); got != "cosign sign" {
+		t.Errorf("printCommands() = %q, want %q", got, "cosign sign")
+	}
+}
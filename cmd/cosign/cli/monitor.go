@@ -0,0 +1,82 @@
+//
+// Copyright 2023 The Sigstore Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cli
+
+import (
+	"github.com/spf13/cobra"
+
+	"github.com/sigstore/cosign/v2/cmd/cosign/cli/monitor"
+	"github.com/sigstore/cosign/v2/cmd/cosign/cli/options"
+)
+
+func Monitor() *cobra.Command {
+	o := &options.MonitorOptions{}
+
+	cmd := &cobra.Command{
+		Use:   "monitor",
+		Short: "Report the transparency log entries signed by watched keys or identities",
+		Long: `Report the transparency log entries signed by watched keys or identities.
+
+The monitor tails the Rekor log for new entries and prints those whose
+signature is verified by one of the --key public keys, or by a Fulcio
+certificate of the --certificate-identity, so that their owners detect
+signatures they didn't make, e.g. with a stolen key or a compromised account.
+Every signature of a DSSE envelope is checked, and the entries whose signers
+can't be parsed are reported with a warning. Each matching entry is also
+POSTed as JSON to the --webhook URL:
+
+  {"logIndex": 1234, "uuid": "...", "integratedTime": "...",
+   "signer": "identity alice@example.com (https://accounts.google.com)",
+   "subjects": ["alice@example.com"], "issuer": "https://accounts.google.com"}
+
+With --state-file the index of the next entry to check is recorded, so that a
+restarted monitor, or a scheduled 'cosign monitor --once', resumes where the
+previous run stopped and no entry is missed.`,
+		Example: `  cosign monitor (--key <KEY>... | --certificate-identity <IDENTITY>) [--certificate-oidc-issuer <ISSUER>] [--webhook <URL>] [--state-file <FILE>]
+
+  # report the signatures made with cosign.key
+  cosign monitor --key cosign.pub
+
+  # post the signatures made by alice@example.com, through any OIDC provider, to a chat webhook
+  cosign monitor --certificate-identity alice@example.com --webhook https://hooks.example.com/cosign
+
+  # check the entries added since the previous run, from a scheduled job
+  cosign monitor --key cosign.pub --state-file monitor.json --once`,
+		Args:             cobra.NoArgs,
+		PersistentPreRun: options.BindViper,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			c := &monitor.MonitorCommand{
+				RekorURL:             o.Rekor.URL,
+				Keys:                 o.Keys,
+				CertIdentity:         o.CertIdentity,
+				CertIdentityRegexp:   o.CertIdentityRegexp,
+				CertOidcIssuer:       o.CertOidcIssuer,
+				CertOidcIssuerRegexp: o.CertOidcIssuerRegexp,
+				StartIndex:           o.StartIndex,
+				Interval:             o.Interval,
+				StateFile:            o.StateFile,
+				Webhook:              o.Webhook,
+				Output:               o.Output,
+				Once:                 o.Once,
+			}
+			return c.Exec(cmd.Context())
+		},
+	}
+
+	o.AddFlags(cmd)
+
+	return cmd
+}
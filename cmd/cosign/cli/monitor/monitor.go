@@ -0,0 +1,366 @@
+//
+// Copyright 2023 The Sigstore Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package monitor tails the transparency log for the entries signed by
+// watched keys or identities, so that their owners detect signatures they
+// didn't make.
+package monitor
+
+import (
+	"bytes"
+	"context"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/sigstore/rekor/pkg/generated/client"
+	"github.com/sigstore/rekor/pkg/generated/client/tlog"
+	"github.com/sigstore/rekor/pkg/generated/models"
+	"github.com/sigstore/sigstore/pkg/cryptoutils"
+
+	"github.com/sigstore/cosign/v2/cmd/cosign/cli/rekor"
+	"github.com/sigstore/cosign/v2/internal/ui"
+	"github.com/sigstore/cosign/v2/pkg/cosign"
+	"github.com/sigstore/cosign/v2/pkg/cosign/rootpolicy"
+	sigs "github.com/sigstore/cosign/v2/pkg/signature"
+)
+
+// MonitorCommand reports the transparency log entries signed by the keys
+// or identity it watches.
+type MonitorCommand struct {
+	RekorURL             string
+	Keys                 []string
+	CertIdentity         string
+	CertIdentityRegexp   string
+	CertOidcIssuer       string
+	CertOidcIssuerRegexp string
+	// StartIndex is the first entry checked without a state file, or the
+	// end of the log when negative.
+	StartIndex int64
+	Interval   time.Duration
+	StateFile  string
+	Webhook    string
+	Output     string
+	Once       bool
+}
+
+// Event is a transparency log entry signed by a watched key or identity.
+type Event struct {
+	LogIndex       int64     `json:"logIndex"`
+	UUID           string    `json:"uuid"`
+	IntegratedTime time.Time `json:"integratedTime"`
+	// Signer is the watched key, e.g. key alice.pub, or identity that
+	// signed the entry.
+	Signer string `json:"signer"`
+	// Subjects and Issuer are those of the certificate of a keyless
+	// signature.
+	Subjects []string `json:"subjects,omitempty"`
+	Issuer   string   `json:"issuer,omitempty"`
+}
+
+// state is the content of the state file.
+type state struct {
+	NextIndex int64 `json:"nextIndex"`
+}
+
+// Exec checks the entries of the log until it is interrupted, or once with
+// c.Once.
+func (c *MonitorCommand) Exec(ctx context.Context) error {
+	if c.Output != "text" && c.Output != "json" {
+		return fmt.Errorf("unsupported output %q, expected text or json", c.Output)
+	}
+	if c.Interval <= 0 && !c.Once {
+		return errors.New("--interval must be positive")
+	}
+	w, err := c.watcher(ctx)
+	if err != nil {
+		return err
+	}
+	rekorClient, err := rekor.NewClient(c.RekorURL)
+	if err != nil {
+		return fmt.Errorf("creating Rekor client: %w", err)
+	}
+
+	ctx, stop := signal.NotifyContext(ctx, os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	next, err := c.startIndex(ctx, rekorClient)
+	if err != nil {
+		return err
+	}
+	ui.Infof(ctx, "Monitoring %s from log index %d", c.RekorURL, next)
+	for {
+		next, err = c.poll(ctx, rekorClient, w, next)
+		if err != nil {
+			if c.Once || ctx.Err() != nil {
+				return err
+			}
+			ui.Warnf(ctx, "Checking the log from index %d: %v", next, err)
+		}
+		if c.Once {
+			return nil
+		}
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-time.After(c.Interval):
+		}
+	}
+}
+
+// startIndex returns the index recorded in the state file, c.StartIndex,
+// or the current size of the log.
+func (c *MonitorCommand) startIndex(ctx context.Context, rekorClient *client.Rekor) (int64, error) {
+	if c.StateFile != "" {
+		b, err := os.ReadFile(filepath.Clean(c.StateFile))
+		switch {
+		case err == nil:
+			var s state
+			if err := json.Unmarshal(b, &s); err != nil {
+				return 0, fmt.Errorf("parsing state file %s: %w", c.StateFile, err)
+			}
+			return s.NextIndex, nil
+		case !errors.Is(err, os.ErrNotExist):
+			return 0, err
+		}
+	}
+	if c.StartIndex >= 0 {
+		return c.StartIndex, nil
+	}
+	return logSize(ctx, rekorClient)
+}
+
+// poll checks the entries from next to the end of the log, and returns the
+// index of the first entry it did not check.
+func (c *MonitorCommand) poll(ctx context.Context, rekorClient *client.Rekor, w *watcher, next int64) (int64, error) {
+	size, err := logSize(ctx, rekorClient)
+	if err != nil {
+		return next, err
+	}
+	for ; next < size; next++ {
+		uuid, e, err := cosign.GetTlogEntryByIndex(ctx, rekorClient, next)
+		if err != nil {
+			// Retry the entry at the next poll rather than skip it.
+			err = fmt.Errorf("fetching log index %d: %w", next, err)
+			return next, errors.Join(err, c.saveState(next))
+		}
+		event, ok, err := w.match(e)
+		if err != nil {
+			ui.Warnf(ctx, "log index %d may be signed by a watched key or identity: %v", next, err)
+		}
+		if ok {
+			event.LogIndex = next
+			event.UUID = uuid
+			if e.IntegratedTime != nil {
+				event.IntegratedTime = time.Unix(*e.IntegratedTime, 0).UTC()
+			}
+			c.alert(ctx, event)
+		}
+	}
+	return next, c.saveState(next)
+}
+
+// logSize returns the number of entries of the log, across its shards.
+func logSize(ctx context.Context, rekorClient *client.Rekor) (int64, error) {
+	resp, err := rekorClient.Tlog.GetLogInfo(tlog.NewGetLogInfoParamsWithContext(ctx))
+	if err != nil {
+		return 0, fmt.Errorf("getting log info: %w", err)
+	}
+	info := resp.Payload
+	if info.TreeSize == nil {
+		return 0, errors.New("log info without a tree size")
+	}
+	size := *info.TreeSize
+	for _, shard := range info.InactiveShards {
+		if shard.TreeSize != nil {
+			size += *shard.TreeSize
+		}
+	}
+	return size, nil
+}
+
+func (c *MonitorCommand) saveState(next int64) error {
+	if c.StateFile == "" {
+		return nil
+	}
+	b, err := json.Marshal(state{NextIndex: next})
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(c.StateFile, b, 0600); err != nil {
+		return fmt.Errorf("writing state file: %w", err)
+	}
+	return nil
+}
+
+// alert prints event and posts it to the webhook. Failing to post it is
+// only reported, so that the monitor keeps running.
+func (c *MonitorCommand) alert(ctx context.Context, event Event) {
+	b, err := json.Marshal(event)
+	if err != nil {
+		ui.Warnf(ctx, "Encoding the entry at log index %d: %v", event.LogIndex, err)
+		return
+	}
+	if c.Output == "json" {
+		fmt.Println(string(b))
+	} else {
+		fmt.Printf("Log index %d (%s) signed by %s at %s\n", event.LogIndex, event.UUID, event.Signer, event.IntegratedTime.Format(time.RFC3339))
+	}
+	if c.Webhook == "" {
+		return
+	}
+	if err := postEvent(ctx, c.Webhook, b); err != nil {
+		ui.Warnf(ctx, "Posting the entry at log index %d to the webhook: %v", event.LogIndex, err)
+	}
+}
+
+func postEvent(ctx context.Context, url string, body []byte) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, resp.Body)
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("unexpected status %s", resp.Status)
+	}
+	return nil
+}
+
+// watcher matches the entries signed by the watched keys or identity.
+type watcher struct {
+	// keys maps the key IDs of the watched keys, see rootpolicy.KeyID, to
+	// their references.
+	keys     map[string]string
+	identity *cosign.Identity
+}
+
+func (c *MonitorCommand) watcher(ctx context.Context) (*watcher, error) {
+	w := &watcher{keys: map[string]string{}}
+	for _, keyRef := range c.Keys {
+		v, err := sigs.PublicKeyFromKeyRef(ctx, keyRef)
+		if err != nil {
+			return nil, fmt.Errorf("loading public key %s: %w", keyRef, err)
+		}
+		keys, ok := v.(cosign.TrustedKeys)
+		if !ok {
+			keys = cosign.TrustedKeys{v}
+		}
+		for _, k := range keys {
+			pub, err := k.PublicKey()
+			if err != nil {
+				return nil, fmt.Errorf("loading public key %s: %w", keyRef, err)
+			}
+			id, err := rootpolicy.KeyID(pub)
+			if err != nil {
+				return nil, err
+			}
+			w.keys[id] = keyRef
+		}
+	}
+	if c.CertIdentity != "" || c.CertIdentityRegexp != "" {
+		w.identity = &cosign.Identity{
+			Subject:       c.CertIdentity,
+			SubjectRegExp: c.CertIdentityRegexp,
+			Issuer:        c.CertOidcIssuer,
+			IssuerRegExp:  c.CertOidcIssuerRegexp,
+		}
+	} else if c.CertOidcIssuer != "" || c.CertOidcIssuerRegexp != "" {
+		return nil, errors.New("--certificate-oidc-issuer and --certificate-oidc-issuer-regexp require --certificate-identity or --certificate-identity-regexp")
+	}
+	if len(w.keys) == 0 && w.identity == nil {
+		return nil, errors.New("--key, --certificate-identity or --certificate-identity-regexp is required")
+	}
+	return w, nil
+}
+
+// match returns the event of e when one of its signers, e.g. of the
+// signatures of a DSSE envelope, is a watched key or identity. It fails when
+// a signer can't be extracted or parsed, so that such entries are reported
+// rather than silently ignored.
+func (w *watcher) match(e *models.LogEntryAnon) (Event, bool, error) {
+	signers, err := cosign.TlogEntrySigners(e)
+	if err != nil {
+		return Event{}, false, fmt.Errorf("extracting the signers: %w", err)
+	}
+	var errs []error
+	for _, signer := range signers {
+		event, ok, err := w.matchSigner(signer)
+		if ok {
+			return event, true, nil
+		}
+		if err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return Event{}, false, errors.Join(errs...)
+}
+
+// matchSigner returns the event of signer, a PEM encoded public key or
+// certificate, when it is a watched key or identity.
+func (w *watcher) matchSigner(signer []byte) (Event, bool, error) {
+	block, _ := pem.Decode(signer)
+	if block == nil {
+		return Event{}, false, errors.New("signer is not PEM encoded")
+	}
+	if block.Type == "CERTIFICATE" {
+		if w.identity == nil {
+			return Event{}, false, nil
+		}
+		cert, err := x509.ParseCertificate(block.Bytes)
+		if err != nil {
+			return Event{}, false, fmt.Errorf("parsing certificate: %w", err)
+		}
+		if cosign.CheckCertificatePolicy(cert, &cosign.CheckOpts{Identities: []cosign.Identity{*w.identity}}) != nil {
+			return Event{}, false, nil
+		}
+		subjects := cryptoutils.GetSubjectAlternateNames(cert)
+		ce := cosign.CertExtensions{Cert: cert}
+		issuer := ce.GetIssuer()
+		return Event{
+			Signer:   fmt.Sprintf("identity %s (%s)", strings.Join(subjects, ", "), issuer),
+			Subjects: subjects,
+			Issuer:   issuer,
+		}, true, nil
+	}
+	pub, err := cryptoutils.UnmarshalPEMToPublicKey(signer)
+	if err != nil {
+		return Event{}, false, fmt.Errorf("parsing public key: %w", err)
+	}
+	id, err := rootpolicy.KeyID(pub)
+	if err != nil {
+		return Event{}, false, err
+	}
+	keyRef, ok := w.keys[id]
+	if !ok {
+		return Event{}, false, nil
+	}
+	return Event{Signer: "key " + keyRef}, true, nil
+}
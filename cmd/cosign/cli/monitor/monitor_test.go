@@ -0,0 +1,315 @@
+//
+// Copyright 2023 The Sigstore Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package monitor
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/sigstore/rekor/pkg/generated/models"
+	"github.com/sigstore/sigstore/pkg/cryptoutils"
+
+	"github.com/sigstore/cosign/v2/pkg/cosign"
+	"github.com/sigstore/cosign/v2/pkg/cosign/rootpolicy"
+)
+
+// hashedRekordBody returns the body of a hashedrekord entry signed by priv,
+// whose signature is verified by signer, the PEM encoded public key or
+// certificate of priv.
+func hashedRekordBody(t *testing.T, priv *ecdsa.PrivateKey, signer []byte) string {
+	t.Helper()
+	digest := sha256.Sum256([]byte("artifact"))
+	sig, err := ecdsa.SignASN1(rand.Reader, priv, digest[:])
+	if err != nil {
+		t.Fatal(err)
+	}
+	body := map[string]any{
+		"apiVersion": "0.0.1",
+		"kind":       "hashedrekord",
+		"spec": map[string]any{
+			"data": map[string]any{
+				"hash": map[string]string{"algorithm": "sha256", "value": hex.EncodeToString(digest[:])},
+			},
+			"signature": map[string]any{
+				"content":   base64.StdEncoding.EncodeToString(sig),
+				"publicKey": map[string]string{"content": base64.StdEncoding.EncodeToString(signer)},
+			},
+		},
+	}
+	b, err := json.Marshal(body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return base64.StdEncoding.EncodeToString(b)
+}
+
+// dsseBody returns the body of a dsse entry of an envelope with a signature
+// verified by each of signers.
+func dsseBody(t *testing.T, signers ...[]byte) string {
+	t.Helper()
+	hash := map[string]string{"algorithm": "sha256", "value": strings.Repeat("0", 64)}
+	var sigs []map[string]string
+	for _, signer := range signers {
+		sigs = append(sigs, map[string]string{
+			"signature": base64.StdEncoding.EncodeToString([]byte("signature")),
+			"verifier":  base64.StdEncoding.EncodeToString(signer),
+		})
+	}
+	body := map[string]any{
+		"apiVersion": "0.0.1",
+		"kind":       "dsse",
+		"spec": map[string]any{
+			"envelopeHash": hash,
+			"payloadHash":  hash,
+			"signatures":   sigs,
+		},
+	}
+	b, err := json.Marshal(body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return base64.StdEncoding.EncodeToString(b)
+}
+
+func newKey(t *testing.T) (*ecdsa.PrivateKey, []byte) {
+	t.Helper()
+	priv, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pem, err := cryptoutils.MarshalPublicKeyToPEM(priv.Public())
+	if err != nil {
+		t.Fatal(err)
+	}
+	return priv, pem
+}
+
+func newCertificate(t *testing.T, email string) (*ecdsa.PrivateKey, []byte) {
+	t.Helper()
+	priv, _ := newKey(t)
+	tmpl := &x509.Certificate{
+		SerialNumber:   big.NewInt(1),
+		Subject:        pkix.Name{CommonName: "sigstore"},
+		NotBefore:      time.Now().Add(-time.Minute),
+		NotAfter:       time.Now().Add(10 * time.Minute),
+		EmailAddresses: []string{email},
+		KeyUsage:       x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:    []x509.ExtKeyUsage{x509.ExtKeyUsageCodeSigning},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, priv.Public(), priv)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pem, err := cryptoutils.MarshalCertificateToPEM(cert)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return priv, pem
+}
+
+func TestWatcherMatch(t *testing.T) {
+	watched, watchedPEM := newKey(t)
+	other, otherPEM := newKey(t)
+	alice, alicePEM := newCertificate(t, "alice@example.com")
+	bob, bobPEM := newCertificate(t, "bob@example.com")
+	id, err := rootpolicy.KeyID(watched.Public())
+	if err != nil {
+		t.Fatal(err)
+	}
+	w := &watcher{
+		keys:     map[string]string{id: "cosign.pub"},
+		identity: &cosign.Identity{Subject: "alice@example.com"},
+	}
+
+	tests := []struct {
+		name       string
+		priv       *ecdsa.PrivateKey
+		signer     []byte
+		body       string
+		wantSigner string
+		wantErr    bool
+	}{{
+		name:       "watched key",
+		priv:       watched,
+		signer:     watchedPEM,
+		wantSigner: "key cosign.pub",
+	}, {
+		name:   "other key",
+		priv:   other,
+		signer: otherPEM,
+	}, {
+		name:       "watched identity",
+		priv:       alice,
+		signer:     alicePEM,
+		wantSigner: "identity alice@example.com ()",
+	}, {
+		name:   "other identity",
+		priv:   bob,
+		signer: bobPEM,
+	}, {
+		name:       "watched key among the signatures of an envelope",
+		body:       dsseBody(t, otherPEM, watchedPEM),
+		wantSigner: "key cosign.pub",
+	}, {
+		name: "other keys of an envelope",
+		body: dsseBody(t, otherPEM, bobPEM),
+	}, {
+		name:    "unparsable signer of an envelope",
+		body:    dsseBody(t, otherPEM, []byte("not a key")),
+		wantErr: true,
+	}, {
+		name:    "invalid body",
+		body:    base64.StdEncoding.EncodeToString([]byte(`{"kind": "rpm"}`)),
+		wantErr: true,
+	}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			body := tt.body
+			if body == "" {
+				body = hashedRekordBody(t, tt.priv, tt.signer)
+			}
+			event, ok, err := w.match(&models.LogEntryAnon{Body: body})
+			if ok != (tt.wantSigner != "") || event.Signer != tt.wantSigner {
+				t.Errorf("match() = %q, %v, want %q", event.Signer, ok, tt.wantSigner)
+			}
+			if (err != nil) != tt.wantErr {
+				t.Errorf("match() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+
+	t.Run("other issuer", func(t *testing.T) {
+		w := &watcher{identity: &cosign.Identity{Subject: "alice@example.com", Issuer: "https://accounts.google.com"}}
+		if _, ok, _ := w.match(&models.LogEntryAnon{Body: hashedRekordBody(t, alice, alicePEM)}); ok {
+			t.Error("match() of a certificate of another issuer = true")
+		}
+	})
+}
+
+// fakeRekor serves the log info and the entries of bodies by index.
+func fakeRekor(t *testing.T, bodies []string) *httptest.Server {
+	t.Helper()
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/api/v1/log":
+			fmt.Fprintf(w, `{"treeSize": %d, "rootHash": "%s", "signedTreeHead": "sth", "treeID": "1"}`, len(bodies), strings.Repeat("0", 64))
+		case "/api/v1/log/entries":
+			var i int
+			if _, err := fmt.Sscan(r.URL.Query().Get("logIndex"), &i); err != nil || i >= len(bodies) {
+				http.NotFound(w, r)
+				return
+			}
+			decoded, _ := base64.StdEncoding.DecodeString(bodies[i])
+			leaf := sha256.Sum256(append([]byte{0}, decoded...))
+			integrated := int64(1700000000 + i)
+			logIndex := int64(i)
+			_ = json.NewEncoder(w).Encode(models.LogEntry{
+				hex.EncodeToString(leaf[:]): models.LogEntryAnon{Body: bodies[i], IntegratedTime: &integrated, LogIndex: &logIndex},
+			})
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+}
+
+func TestExecOnce(t *testing.T) {
+	watched, watchedPEM := newKey(t)
+	other, otherPEM := newKey(t)
+	keyPath := filepath.Join(t.TempDir(), "cosign.pub")
+	if err := os.WriteFile(keyPath, watchedPEM, 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	rekorServer := fakeRekor(t, []string{
+		hashedRekordBody(t, other, otherPEM),
+		hashedRekordBody(t, watched, watchedPEM),
+		hashedRekordBody(t, other, otherPEM),
+	})
+	defer rekorServer.Close()
+
+	var mu sync.Mutex
+	var posted []Event
+	webhook := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		b, _ := io.ReadAll(r.Body)
+		var e Event
+		if err := json.Unmarshal(b, &e); err != nil {
+			t.Errorf("webhook body %s: %v", b, err)
+		}
+		mu.Lock()
+		posted = append(posted, e)
+		mu.Unlock()
+	}))
+	defer webhook.Close()
+
+	stateFile := filepath.Join(t.TempDir(), "monitor.json")
+	c := &MonitorCommand{
+		RekorURL:   rekorServer.URL,
+		Keys:       []string{keyPath},
+		StartIndex: 0,
+		StateFile:  stateFile,
+		Webhook:    webhook.URL,
+		Output:     "json",
+		Once:       true,
+	}
+	if err := c.Exec(context.Background()); err != nil {
+		t.Fatalf("Exec() = %v", err)
+	}
+	if len(posted) != 1 || posted[0].LogIndex != 1 || posted[0].Signer != "key "+keyPath {
+		t.Fatalf("posted events = %+v, want the entry at log index 1", posted)
+	}
+
+	b, err := os.ReadFile(stateFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var s state
+	if err := json.Unmarshal(b, &s); err != nil || s.NextIndex != 3 {
+		t.Errorf("state = %s, want nextIndex 3", b)
+	}
+
+	// A second run resumes from the state file, and finds nothing new.
+	posted = nil
+	if err := c.Exec(context.Background()); err != nil {
+		t.Fatalf("Exec() = %v", err)
+	}
+	if len(posted) != 0 {
+		t.Errorf("posted events = %+v, want none", posted)
+	}
+}
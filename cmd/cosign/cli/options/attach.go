@@ -64,6 +64,14 @@ type AttachSBOMOptions struct {
 	SBOM                 string
 	SBOMType             string
 	SBOMInputFormat      string
+	Compression          string
+	Sign                 bool
+	Key                  string
+	TlogUpload           bool
+	SkipConfirmation     bool
+	Rekor                RekorOptions
+	Fulcio               FulcioOptions
+	OIDC                 OIDCOptions
 	Registry             RegistryOptions
 	RegistryExperimental RegistryExperimentalOptions
 }
@@ -72,6 +80,9 @@ var _ Interface = (*AttachSBOMOptions)(nil)
 
 // AddFlags implements Interface
 func (o *AttachSBOMOptions) AddFlags(cmd *cobra.Command) {
+	o.Rekor.AddFlags(cmd)
+	o.Fulcio.AddFlags(cmd)
+	o.OIDC.AddFlags(cmd)
 	o.Registry.AddFlags(cmd)
 	o.RegistryExperimental.AddFlags(cmd)
 
@@ -84,6 +95,22 @@ func (o *AttachSBOMOptions) AddFlags(cmd *cobra.Command) {
 
 	cmd.Flags().StringVar(&o.SBOMInputFormat, "input-format", "",
 		"type of sbom input format (json|xml|text)")
+
+	cmd.Flags().StringVar(&o.Compression, "compression", ctypes.NoCompression,
+		"compression of the sbom layer (none|gzip). Compressed layers have the media type of the sbom suffixed with +gzip")
+
+	cmd.Flags().BoolVar(&o.Sign, "sign", false,
+		"sign the attached sbom with --key, or keylessly without it, binding it to the image, so that 'cosign verify --attachment sbom' detects an sbom tampered with or attached to another image")
+
+	cmd.Flags().StringVar(&o.Key, "key", "",
+		"path to the private key file, KMS URI or Kubernetes Secret to sign the sbom with, requires --sign")
+	_ = cmd.Flags().SetAnnotation("key", cobra.BashCompFilenameExt, []string{})
+
+	cmd.Flags().BoolVar(&o.TlogUpload, "tlog-upload", true,
+		"whether or not to upload the signature of the sbom to the tlog")
+
+	cmd.Flags().BoolVarP(&o.SkipConfirmation, "yes", "y", false,
+		"skip confirmation prompts for non-destructive operations")
 }
 
 func (o *AttachSBOMOptions) MediaType() (types.MediaType, error) {
@@ -122,6 +149,7 @@ func (o *AttachSBOMOptions) MediaType() (types.MediaType, error) {
 // AttachAttestationOptions is the top level wrapper for the attach attestation command.
 type AttachAttestationOptions struct {
 	Attestations []string
+	Key          string
 	Registry     RegistryOptions
 }
 
@@ -131,4 +159,8 @@ func (o *AttachAttestationOptions) AddFlags(cmd *cobra.Command) {
 
 	cmd.Flags().StringArrayVarP(&o.Attestations, "attestation", "", nil,
 		"path to the attestation envelope")
+
+	cmd.Flags().StringVar(&o.Key, "key", "",
+		"path to the public key file, KMS URI or Kubernetes Secret to verify the signature of the envelopes with before attaching them")
+	_ = cmd.Flags().SetAnnotation("key", cobra.BashCompFilenameExt, []string{"pub"})
 }
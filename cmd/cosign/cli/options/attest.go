@@ -21,22 +21,32 @@ import (
 
 // AttestOptions is the top level wrapper for the attest command.
 type AttestOptions struct {
-	Key              string
-	Cert             string
-	CertChain        string
-	NoUpload         bool
-	Recursive        bool
-	Replace          bool
-	SkipConfirmation bool
-	TlogUpload       bool
-	TSAServerURL     string
+	Key               string
+	Cert              string
+	CertChain         string
+	BundlePath        string
+	OutputCertificate string
+	OutputKey         string
+	NoUpload          bool
+	LocalImage        bool
+	Recursive         bool
+	Replace           bool
+	SkipConfirmation  bool
+	TlogUpload        bool
+	TSAClientCACert   string
+	TSAClientCert     string
+	TSAClientKey      string
+	TSAServerName     string
+	TSAServerURL      string
 
 	Rekor       RekorOptions
 	Fulcio      FulcioOptions
 	OIDC        OIDCOptions
 	SecurityKey SecurityKeyOptions
 	Predicate   PredicateLocalOptions
-	Registry    RegistryOptions
+	AnnotationOptions
+	Registry             RegistryOptions
+	RegistryExperimental RegistryExperimentalOptions
 }
 
 var _ Interface = (*AttestOptions)(nil)
@@ -49,6 +59,12 @@ func (o *AttestOptions) AddFlags(cmd *cobra.Command) {
 	o.OIDC.AddFlags(cmd)
 	o.Rekor.AddFlags(cmd)
 	o.Registry.AddFlags(cmd)
+	o.RegistryExperimental.AddFlags(cmd)
+
+	// The attestation annotations live next to the DSSE envelope, they are
+	// not part of the signed statement.
+	cmd.Flags().StringSliceVarP(&o.Annotations, "annotations", "a", nil,
+		"extra key=value pairs to annotate the attestation with, they are not covered by the signature")
 
 	cmd.Flags().StringVar(&o.Key, "key", "",
 		"path to the private key file, KMS URI or Kubernetes Secret")
@@ -65,14 +81,29 @@ func (o *AttestOptions) AddFlags(cmd *cobra.Command) {
 			"signing certificate and end with the root certificate. Included in the OCI Signature")
 	_ = cmd.Flags().SetAnnotation("certificate-chain", cobra.BashCompFilenameExt, []string{"cert"})
 
+	cmd.Flags().StringVar(&o.BundlePath, "bundle", "",
+		"write the signed attestation and its transparency log bundle to FILE, for offline verification with verify-attestation --bundle")
+	_ = cmd.Flags().SetAnnotation("bundle", cobra.BashCompFilenameExt, []string{})
+
+	cmd.Flags().StringVar(&o.OutputCertificate, "output-certificate", "",
+		"write the certificate to FILE")
+	_ = cmd.Flags().SetAnnotation("output-certificate", cobra.BashCompFilenameExt, []string{})
+
+	cmd.Flags().StringVar(&o.OutputKey, "output-key", "",
+		"write the ephemeral private key of keyless signing to FILE, encrypted with the key password, to sign with it and its certificate again with --key and --certificate until the certificate expires")
+	_ = cmd.Flags().SetAnnotation("output-key", cobra.BashCompFilenameExt, []string{})
+
 	cmd.Flags().BoolVar(&o.NoUpload, "no-upload", false,
 		"do not upload the generated attestation")
 
+	cmd.Flags().BoolVar(&o.LocalImage, "local-image", false,
+		"whether the specified image is a path to an image saved locally via 'cosign save' or another OCI layout, e.g. from 'crane pull --format oci'. The attestation is stored in the layout, see 'cosign load' to push it along with the image")
+
 	cmd.Flags().BoolVarP(&o.Recursive, "recursive", "r", false,
 		"if a multi-arch image is specified, additionally sign each discrete image")
 
 	cmd.Flags().BoolVarP(&o.Replace, "replace", "", false,
-		"")
+		"replace the existing attestations of the same predicate type instead of appending")
 
 	cmd.Flags().BoolVarP(&o.SkipConfirmation, "yes", "y", false,
 		"skip confirmation prompts for non-destructive operations")
@@ -80,6 +111,18 @@ func (o *AttestOptions) AddFlags(cmd *cobra.Command) {
 	cmd.Flags().BoolVar(&o.TlogUpload, "tlog-upload", true,
 		"whether or not to upload to the tlog")
 
+	cmd.Flags().StringVar(&o.TSAClientCACert, "timestamp-client-cacert", "",
+		"path to the X.509 CA certificate file in PEM format to be used for the connection to the TSA Server")
+
+	cmd.Flags().StringVar(&o.TSAClientCert, "timestamp-client-cert", "",
+		"path to the X.509 certificate file in PEM format to be used for the connection to the TSA Server")
+
+	cmd.Flags().StringVar(&o.TSAClientKey, "timestamp-client-key", "",
+		"path to the X.509 private key file in PEM format to be used, together with the 'timestamp-client-cert' value, for the connection to the TSA Server")
+
+	cmd.Flags().StringVar(&o.TSAServerName, "timestamp-server-name", "",
+		"SAN name to use as the 'ServerName' tls.Config field to verify the mTLS connection to the TSA Server")
+
 	cmd.Flags().StringVar(&o.TSAServerURL, "timestamp-server-url", "",
 		"url to the Timestamp RFC3161 server, default none. Must be the path to the API to request timestamp responses, e.g. https://freetsa.org/tsr")
 }
@@ -0,0 +1,38 @@
+//
+// Copyright 2023 The Sigstore Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package options
+
+import (
+	"github.com/spf13/cobra"
+)
+
+// AttestationRemoveOptions is the top level wrapper for the attestation rm command.
+type AttestationRemoveOptions struct {
+	Registry  RegistryOptions
+	Predicate PredicateOptions
+	Force     bool
+}
+
+var _ Interface = (*AttestationRemoveOptions)(nil)
+
+// AddFlags implements Interface
+func (o *AttestationRemoveOptions) AddFlags(cmd *cobra.Command) {
+	o.Registry.AddFlags(cmd)
+	o.Predicate.AddFlags(cmd)
+
+	cmd.Flags().BoolVarP(&o.Force, "force", "f", false,
+		"do not prompt for confirmation")
+}
@@ -0,0 +1,61 @@
+//
+// Copyright 2023 The Sigstore Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package options
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+// VerificationCacheOptions configures the on-disk cache of verification
+// results.
+type VerificationCacheOptions struct {
+	Dir string
+	TTL time.Duration
+}
+
+var _ Interface = (*VerificationCacheOptions)(nil)
+
+// AddFlags implements Interface
+func (o *VerificationCacheOptions) AddFlags(cmd *cobra.Command) {
+	cmd.Flags().DurationVar(&o.TTL, "cache-ttl", 0,
+		"cache the signatures verified for each image digest for this long, e.g. 1h, and reuse them when the digest is verified again "+
+			"with the same options instead of contacting the registry and the transparency log. 0 disables the cache")
+
+	cmd.Flags().StringVar(&o.Dir, "cache-dir", "",
+		"directory of the verification cache, defaults to cosign/verify in the user cache directory")
+	_ = cmd.Flags().SetAnnotation("cache-dir", cobra.BashCompSubdirsInDir, []string{})
+}
+
+// CacheDir returns the directory of the verification cache, or "" when the
+// cache is disabled.
+func (o *VerificationCacheOptions) CacheDir() (string, error) {
+	if o.TTL <= 0 {
+		return "", nil
+	}
+	if o.Dir != "" {
+		return o.Dir, nil
+	}
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return "", fmt.Errorf("locating the verification cache, set --cache-dir: %w", err)
+	}
+	return filepath.Join(dir, "cosign", "verify"), nil
+}
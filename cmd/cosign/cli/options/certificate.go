@@ -16,6 +16,8 @@ package options
 
 import (
 	"errors"
+	"fmt"
+	"regexp"
 
 	"github.com/sigstore/cosign/v2/pkg/cosign"
 	"github.com/spf13/cobra"
@@ -26,6 +28,9 @@ type CertVerifyOptions struct {
 	Cert                         string
 	CertIdentity                 string
 	CertIdentityRegexp           string
+	CertEmail                    string
+	CertURI                      string
+	CertURIRegexp                string
 	CertOidcIssuer               string
 	CertOidcIssuerRegexp         string
 	CertGithubWorkflowTrigger    string
@@ -34,6 +39,8 @@ type CertVerifyOptions struct {
 	CertGithubWorkflowRepository string
 	CertGithubWorkflowRef        string
 	CertChain                    string
+	CARoots                      string
+	CAIntermediates              string
 	SCT                          string
 	IgnoreSCT                    bool
 }
@@ -43,7 +50,7 @@ var _ Interface = (*RekorOptions)(nil)
 // AddFlags implements Interface
 func (o *CertVerifyOptions) AddFlags(cmd *cobra.Command) {
 	cmd.Flags().StringVar(&o.Cert, "certificate", "",
-		"path to the public certificate. The certificate will be verified against the Fulcio roots if the --certificate-chain option is not passed.")
+		"path to the public certificate. The certificate will be verified against the Fulcio roots, or the --ca-roots if set, if the --certificate-chain option is not passed. Its public key is used to verify the signatures.")
 	_ = cmd.Flags().SetAnnotation("certificate", cobra.BashCompFilenameExt, []string{"cert"})
 
 	cmd.Flags().StringVar(&o.CertIdentity, "certificate-identity", "",
@@ -52,6 +59,15 @@ func (o *CertVerifyOptions) AddFlags(cmd *cobra.Command) {
 	cmd.Flags().StringVar(&o.CertIdentityRegexp, "certificate-identity-regexp", "",
 		"A regular expression alternative to --certificate-identity. Accepts the Go regular expression syntax described at https://golang.org/s/re2syntax. Either --certificate-identity or --certificate-identity-regexp must be set for keyless flows.")
 
+	cmd.Flags().StringVar(&o.CertEmail, "certificate-email", "",
+		"The email address expected as a subject alternative name of a valid Fulcio certificate. Unlike --certificate-identity, it only matches email SANs.")
+
+	cmd.Flags().StringVar(&o.CertURI, "certificate-uri", "",
+		"The URI expected as a subject alternative name of a valid Fulcio certificate, e.g. the workflow URI of a GitHub Actions certificate. Unlike --certificate-identity, it only matches URI SANs.")
+
+	cmd.Flags().StringVar(&o.CertURIRegexp, "certificate-uri-regexp", "",
+		"A regular expression alternative to --certificate-uri, e.g. ^https://github.com/myorg/.*/.github/workflows/.* to allow every workflow of an organization. Accepts the Go regular expression syntax described at https://golang.org/s/re2syntax.")
+
 	cmd.Flags().StringVar(&o.CertOidcIssuer, "certificate-oidc-issuer", "",
 		"The OIDC issuer expected in a valid Fulcio certificate, e.g. https://token.actions.githubusercontent.com or https://oauth2.sigstore.dev/auth. Either --certificate-oidc-issuer or --certificate-oidc-issuer-regexp must be set for keyless flows.")
 
@@ -82,6 +98,16 @@ func (o *CertVerifyOptions) AddFlags(cmd *cobra.Command) {
 			"signing certificate and end with the root certificate")
 	_ = cmd.Flags().SetAnnotation("certificate-chain", cobra.BashCompFilenameExt, []string{"cert"})
 
+	cmd.Flags().StringVar(&o.CARoots, "ca-roots", "",
+		"path to a bundle FILE of root CA certificates in PEM format, trusted instead of the Fulcio roots to issue the "+
+			"signing certificates, e.g. for a private Fulcio deployment or your own PKI. Cannot be used with --certificate-chain")
+	_ = cmd.Flags().SetAnnotation("ca-roots", cobra.BashCompFilenameExt, []string{"cert"})
+
+	cmd.Flags().StringVar(&o.CAIntermediates, "ca-intermediates", "",
+		"path to a bundle FILE of intermediate CA certificates in PEM format, used with --ca-roots to build the "+
+			"certificate chain when the signature does not include it")
+	_ = cmd.Flags().SetAnnotation("ca-intermediates", cobra.BashCompFilenameExt, []string{"cert"})
+
 	cmd.Flags().StringVar(&o.SCT, "sct", "",
 		"path to a detached Signed Certificate Timestamp, formatted as a RFC6962 AddChainResponse struct. "+
 			"If a certificate contains an SCT, verification will check both the detached and embedded SCTs.")
@@ -91,11 +117,21 @@ func (o *CertVerifyOptions) AddFlags(cmd *cobra.Command) {
 }
 
 func (o *CertVerifyOptions) Identities() ([]cosign.Identity, error) {
-	if o.CertIdentity == "" && o.CertIdentityRegexp == "" {
-		return nil, errors.New("--certificate-identity or --certificate-identity-regexp is required for verification in keyless mode")
+	if o.CertIdentity == "" && o.CertIdentityRegexp == "" && o.CertEmail == "" && o.CertURI == "" && o.CertURIRegexp == "" {
+		return nil, errors.New("--certificate-identity, --certificate-identity-regexp, --certificate-email, --certificate-uri or --certificate-uri-regexp is required for verification in keyless mode")
 	}
 	if o.CertOidcIssuer == "" && o.CertOidcIssuerRegexp == "" {
 		return nil, errors.New("--certificate-oidc-issuer or --certificate-oidc-issuer-regexp is required for verification in keyless mode")
 	}
-	return []cosign.Identity{{IssuerRegExp: o.CertOidcIssuerRegexp, Issuer: o.CertOidcIssuer, SubjectRegExp: o.CertIdentityRegexp, Subject: o.CertIdentity}}, nil
+	// Report malformed expressions before fetching anything.
+	for _, re := range []struct{ flag, expr string }{
+		{"certificate-identity-regexp", o.CertIdentityRegexp},
+		{"certificate-oidc-issuer-regexp", o.CertOidcIssuerRegexp},
+		{"certificate-uri-regexp", o.CertURIRegexp},
+	} {
+		if _, err := regexp.Compile(re.expr); err != nil {
+			return nil, fmt.Errorf("invalid --%s: %w", re.flag, err)
+		}
+	}
+	return []cosign.Identity{{IssuerRegExp: o.CertOidcIssuerRegexp, Issuer: o.CertOidcIssuer, SubjectRegExp: o.CertIdentityRegexp, Subject: o.CertIdentity, Email: o.CertEmail, URI: o.CertURI, URIRegExp: o.CertURIRegexp}}, nil
 }
@@ -0,0 +1,69 @@
+//
+// Copyright 2023 The Sigstore Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package options
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestCertVerifyOptionsIdentities(t *testing.T) {
+	const issuer = "https://token.actions.githubusercontent.com"
+	tests := []struct {
+		name    string
+		opts    CertVerifyOptions
+		wantErr string
+	}{{
+		name: "identity",
+		opts: CertVerifyOptions{CertIdentity: "foo@example.com", CertOidcIssuer: issuer},
+	}, {
+		name: "uri regexp",
+		opts: CertVerifyOptions{CertURIRegexp: "^https://github.com/myorg/.*/.github/workflows/.*", CertOidcIssuer: issuer},
+	}, {
+		name:    "no identity",
+		opts:    CertVerifyOptions{CertOidcIssuer: issuer},
+		wantErr: "is required for verification in keyless mode",
+	}, {
+		name:    "no issuer",
+		opts:    CertVerifyOptions{CertEmail: "foo@example.com"},
+		wantErr: "--certificate-oidc-issuer or --certificate-oidc-issuer-regexp is required",
+	}, {
+		name:    "malformed identity regexp",
+		opts:    CertVerifyOptions{CertIdentityRegexp: "****", CertOidcIssuer: issuer},
+		wantErr: "invalid --certificate-identity-regexp",
+	}, {
+		name:    "malformed uri regexp",
+		opts:    CertVerifyOptions{CertURIRegexp: "(", CertOidcIssuerRegexp: ".*"},
+		wantErr: "invalid --certificate-uri-regexp",
+	}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			identities, err := tt.opts.Identities()
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("Identities() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Identities() = %v", err)
+			}
+			if len(identities) != 1 || identities[0].URIRegExp != tt.opts.CertURIRegexp || identities[0].Subject != tt.opts.CertIdentity {
+				t.Errorf("Identities() = %+v", identities)
+			}
+		})
+	}
+}
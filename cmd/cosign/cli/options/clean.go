@@ -58,6 +58,7 @@ type CleanOptions struct {
 	Registry  RegistryOptions
 	CleanType CleanType
 	Force     bool
+	DryRun    bool
 }
 
 var _ Interface = (*CleanOptions)(nil)
@@ -68,4 +69,5 @@ func (c *CleanOptions) AddFlags(cmd *cobra.Command) {
 	cmd.Flags().Var(&c.CleanType, "type", "a type of clean: <signature|attestation|sbom|all> (sbom is deprecated)")
 	// TODO(#2044): Rename to --skip-confirmation for consistency?
 	cmd.Flags().BoolVarP(&c.Force, "force", "f", false, "do not prompt for confirmation")
+	cmd.Flags().BoolVar(&c.DryRun, "dry-run", false, "list the signatures, attestations and SBOMs that would be removed, without removing them")
 }
@@ -0,0 +1,106 @@
+//
+// Copyright 2023 The Sigstore Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package options
+
+import (
+	"github.com/spf13/cobra"
+)
+
+// GitOptions is the wrapper for the flags locating the Git repository and
+// the notes holding the signatures of commits.
+type GitOptions struct {
+	Repo     string
+	NotesRef string
+}
+
+var _ Interface = (*GitOptions)(nil)
+
+// AddFlags implements Interface
+func (o *GitOptions) AddFlags(cmd *cobra.Command) {
+	cmd.Flags().StringVar(&o.Repo, "repo", ".",
+		"path to the Git repository")
+	_ = cmd.Flags().SetAnnotation("repo", cobra.BashCompSubdirsInDir, []string{})
+
+	cmd.Flags().StringVar(&o.NotesRef, "notes-ref", "refs/notes/cosign",
+		"Git notes ref holding the signatures of the commits and tags, which must be pushed and fetched along with them")
+}
+
+// SignCommitOptions is the top level wrapper for the sign-commit command.
+type SignCommitOptions struct {
+	Key              string
+	IssueCertificate bool
+	SkipConfirmation bool
+	TlogUpload       bool
+
+	SecurityKey SecurityKeyOptions
+	Fulcio      FulcioOptions
+	Rekor       RekorOptions
+	OIDC        OIDCOptions
+	Git         GitOptions
+}
+
+var _ Interface = (*SignCommitOptions)(nil)
+
+// AddFlags implements Interface
+func (o *SignCommitOptions) AddFlags(cmd *cobra.Command) {
+	o.SecurityKey.AddFlags(cmd)
+	o.Fulcio.AddFlags(cmd)
+	o.Rekor.AddFlags(cmd)
+	o.OIDC.AddFlags(cmd)
+	o.Git.AddFlags(cmd)
+
+	cmd.Flags().StringVar(&o.Key, "key", "",
+		"path to the private key file, KMS URI or Kubernetes Secret. Commits are signed keylessly when not set")
+	_ = cmd.Flags().SetAnnotation("key", cobra.BashCompFilenameExt, []string{})
+
+	cmd.Flags().BoolVar(&o.IssueCertificate, "issue-certificate", false,
+		"issue a code signing certificate from Fulcio, even if a key is provided")
+
+	cmd.Flags().BoolVarP(&o.SkipConfirmation, "yes", "y", false,
+		"skip confirmation prompts for non-destructive operations")
+
+	cmd.Flags().BoolVar(&o.TlogUpload, "tlog-upload", true,
+		"whether or not to upload to the tlog")
+}
+
+// VerifyCommitOptions is the top level wrapper for the verify-commit command.
+type VerifyCommitOptions struct {
+	Key    string
+	Output string
+
+	SecurityKey         SecurityKeyOptions
+	CertVerify          CertVerifyOptions
+	Rekor               RekorOptions
+	CommonVerifyOptions CommonVerifyOptions
+	Git                 GitOptions
+}
+
+var _ Interface = (*VerifyCommitOptions)(nil)
+
+// AddFlags implements Interface
+func (o *VerifyCommitOptions) AddFlags(cmd *cobra.Command) {
+	o.SecurityKey.AddFlags(cmd)
+	o.Rekor.AddFlags(cmd)
+	o.CertVerify.AddFlags(cmd)
+	o.CommonVerifyOptions.AddFlags(cmd)
+	o.Git.AddFlags(cmd)
+
+	cmd.Flags().StringVar(&o.Key, "key", "",
+		"path to the public key file, KMS URI or Kubernetes Secret. A file holding several PEM public keys, or a directory of .pub and .pem key files, trusts any of the keys")
+
+	cmd.Flags().StringVarP(&o.Output, "output", "o", "text",
+		"output format for the verification results (json|yaml|text). json and yaml write a list of VerificationResult documents")
+}
@@ -25,6 +25,7 @@ type SBOMDownloadOptions struct {
 type AttestationDownloadOptions struct {
 	PredicateType string // Predicate type of attestation to retrieve
 	Platform      string // Platform to download attestations
+	OutputDir     string // Directory to write the attestations to, instead of stdout
 }
 
 var _ Interface = (*SBOMDownloadOptions)(nil)
@@ -43,4 +44,7 @@ func (o *AttestationDownloadOptions) AddFlags(cmd *cobra.Command) {
 		"download attestation with matching predicateType")
 	cmd.Flags().StringVar(&o.Platform, "platform", "",
 		"download attestation for a specific platform image")
+	cmd.Flags().StringVar(&o.OutputDir, "output-dir", "",
+		"write each attestation envelope to a JSON file in DIR instead of printing them to stdout")
+	_ = cmd.Flags().SetAnnotation("output-dir", cobra.BashCompSubdirsInDir, []string{})
 }
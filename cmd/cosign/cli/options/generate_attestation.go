@@ -0,0 +1,37 @@
+//
+// Copyright 2023 The Sigstore Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package options
+
+import (
+	"github.com/spf13/cobra"
+)
+
+// GenerateAttestationOptions is the top level wrapper for the generate-attestation command.
+type GenerateAttestationOptions struct {
+	CI        string
+	BuilderID string
+}
+
+var _ Interface = (*GenerateAttestationOptions)(nil)
+
+// AddFlags implements Interface
+func (o *GenerateAttestationOptions) AddFlags(cmd *cobra.Command) {
+	cmd.Flags().StringVar(&o.CI, "ci", "auto",
+		"CI system to describe the build of (github-actions|gitlab-ci|tekton), or auto to detect it from the environment")
+
+	cmd.Flags().StringVar(&o.BuilderID, "builder-id", "",
+		"URI of the builder, overriding the builder.id derived from the environment. Required with tekton")
+}
@@ -17,6 +17,8 @@ package options
 
 import (
 	"github.com/spf13/cobra"
+
+	"github.com/sigstore/cosign/v2/pkg/cosign/fidokey"
 )
 
 // GenerateKeyPairOptions is the top level wrapper for the generate-key-pair command.
@@ -24,6 +26,8 @@ type GenerateKeyPairOptions struct {
 	// KMS Key Management Service
 	KMS             string
 	OutputKeyPrefix string
+	FIDO            bool
+	FIDORPID        string
 }
 
 var _ Interface = (*GenerateKeyPairOptions)(nil)
@@ -34,4 +38,9 @@ func (o *GenerateKeyPairOptions) AddFlags(cmd *cobra.Command) {
 		"create key pair in KMS service to use for signing")
 	cmd.Flags().StringVar(&o.OutputKeyPrefix, "output-key-prefix", "cosign",
 		"name used for generated .pub and .key files (defaults to `cosign`)")
+	cmd.Flags().BoolVar(&o.FIDO, "fido", false,
+		"enroll the attached FIDO2 security key, which must support hmac-secret and credProtect, to sign with --key fido2://[RP_ID]. Only the .pub file is written")
+	cmd.Flags().StringVar(&o.FIDORPID, "fido-rp-id", fidokey.DefaultRPID,
+		"relying party of the FIDO2 credential, to enroll several keys on the same security key")
+	cmd.MarkFlagsMutuallyExclusive("fido", "kms")
 }
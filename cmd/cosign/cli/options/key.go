@@ -29,9 +29,12 @@ type KeyOpts struct {
 	OIDCClientID         string
 	OIDCClientSecret     string
 	OIDCRedirectURL      string
-	OIDCDisableProviders bool   // Disable OIDC credential providers in keyless signer
-	OIDCProvider         string // Specify which OIDC credential provider to use for keyless signer
+	OIDCScopes           []string // Scopes requested with the ID token, openid and email when empty
+	OIDCDisableProviders bool     // Disable OIDC credential providers in keyless signer
+	OIDCProvider         string   // Specify which OIDC credential provider to use for keyless signer
+	SPIFFESocket         string   // SPIFFE Workload API socket to get a JWT-SVID from for keyless signer
 	BundlePath           string
+	OutputKey            string // File to write the ephemeral key of keyless signer to, to reuse its certificate
 	SkipConfirmation     bool
 	TSAClientCACert      string
 	TSAClientCert        string
@@ -22,6 +22,7 @@ import (
 // LoadOptions is the top level wrapper for the load command.
 type LoadOptions struct {
 	Directory string
+	Tarball   string
 	Registry  RegistryOptions
 }
 
@@ -33,5 +34,9 @@ func (o *LoadOptions) AddFlags(cmd *cobra.Command) {
 	cmd.Flags().StringVar(&o.Directory, "dir", "",
 		"path to directory where the signed image is stored on disk")
 	_ = cmd.Flags().SetAnnotation("dir", cobra.BashCompSubdirsInDir, []string{})
-	_ = cmd.MarkFlagRequired("dir")
+
+	cmd.Flags().StringVar(&o.Tarball, "tarball", "",
+		"path to a tar FILE written by 'cosign save --tarball' where the signed image is stored")
+	_ = cmd.Flags().SetAnnotation("tarball", cobra.BashCompFilenameExt, []string{"tar"})
+	cmd.MarkFlagsMutuallyExclusive("dir", "tarball")
 }
@@ -0,0 +1,44 @@
+//
+// Copyright 2023 The Sigstore Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package options
+
+import (
+	"github.com/spf13/cobra"
+)
+
+// VerifyLockfileOptions configures the lockfile recording the images
+// verified, and checking they didn't drift since.
+type VerifyLockfileOptions struct {
+	Record  string
+	Against string
+}
+
+var _ Interface = (*VerifyLockfileOptions)(nil)
+
+// AddFlags implements Interface
+func (o *VerifyLockfileOptions) AddFlags(cmd *cobra.Command) {
+	cmd.Flags().StringVar(&o.Record, "record", "",
+		"record the digest, key, signing identities and transparency log entries of each verified image in this lockfile FILE, "+
+			"replacing the previous records of the images and keeping the others")
+	_ = cmd.Flags().SetAnnotation("record", cobra.BashCompFilenameExt, []string{"json"})
+
+	cmd.Flags().StringVar(&o.Against, "against", "",
+		"fail when an image doesn't resolve to the digest recorded for it in this lockfile FILE by --record, e.g. when its tag was moved. "+
+			"Without images, every image of the lockfile is verified")
+	_ = cmd.Flags().SetAnnotation("against", cobra.BashCompFilenameExt, []string{"json"})
+
+	cmd.MarkFlagsMutuallyExclusive("record", "against")
+}
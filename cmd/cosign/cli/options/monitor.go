@@ -0,0 +1,85 @@
+//
+// Copyright 2023 The Sigstore Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package options
+
+import (
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+// MonitorOptions is the top level wrapper for the `monitor` command.
+type MonitorOptions struct {
+	Keys                 []string
+	CertIdentity         string
+	CertIdentityRegexp   string
+	CertOidcIssuer       string
+	CertOidcIssuerRegexp string
+	StartIndex           int64
+	Interval             time.Duration
+	StateFile            string
+	Webhook              string
+	Output               string
+	Once                 bool
+
+	Rekor RekorOptions
+}
+
+var _ Interface = (*MonitorOptions)(nil)
+
+// AddFlags implements Interface
+func (o *MonitorOptions) AddFlags(cmd *cobra.Command) {
+	o.Rekor.AddFlags(cmd)
+
+	cmd.Flags().StringSliceVar(&o.Keys, "key", nil,
+		"path to the public key file, KMS URI or Kubernetes Secret whose signatures are reported, may be repeated")
+	_ = cmd.Flags().SetAnnotation("key", cobra.BashCompFilenameExt, []string{"pub"})
+
+	cmd.Flags().StringVar(&o.CertIdentity, "certificate-identity", "",
+		"report the entries signed with a Fulcio certificate of this identity, e.g. an email address or a URI")
+
+	cmd.Flags().StringVar(&o.CertIdentityRegexp, "certificate-identity-regexp", "",
+		"A regular expression alternative to --certificate-identity. Accepts the Go regular expression syntax described at https://golang.org/s/re2syntax.")
+	cmd.MarkFlagsMutuallyExclusive("certificate-identity", "certificate-identity-regexp")
+
+	cmd.Flags().StringVar(&o.CertOidcIssuer, "certificate-oidc-issuer", "",
+		"only report the certificates of --certificate-identity issued by this OIDC issuer. Certificates of any issuer are reported when empty, "+
+			"to detect the use of the identity through another provider")
+
+	cmd.Flags().StringVar(&o.CertOidcIssuerRegexp, "certificate-oidc-issuer-regexp", "",
+		"A regular expression alternative to --certificate-oidc-issuer. Accepts the Go regular expression syntax described at https://golang.org/s/re2syntax.")
+	cmd.MarkFlagsMutuallyExclusive("certificate-oidc-issuer", "certificate-oidc-issuer-regexp")
+
+	cmd.Flags().Int64Var(&o.StartIndex, "start-index", -1,
+		"log index of the first entry checked, when there is no --state-file to resume from. Entries added from now on are checked when negative")
+
+	cmd.Flags().DurationVar(&o.Interval, "interval", time.Minute,
+		"how long to wait before looking for new entries again")
+
+	cmd.Flags().StringVar(&o.StateFile, "state-file", "",
+		"FILE recording the index of the next entry to check, so that a restarted monitor resumes where it stopped")
+	_ = cmd.Flags().SetAnnotation("state-file", cobra.BashCompFilenameExt, []string{"json"})
+
+	cmd.Flags().StringVar(&o.Webhook, "webhook", "",
+		"URL each matching entry is POSTed to as JSON, in addition to being printed")
+
+	cmd.Flags().StringVarP(&o.Output, "output", "o", "text",
+		"output format of the matching entries: text or json, one object per line")
+	_ = cmd.RegisterFlagCompletionFunc("output", cobra.FixedCompletions([]string{"text", "json"}, cobra.ShellCompDirectiveNoFileComp))
+
+	cmd.Flags().BoolVar(&o.Once, "once", false,
+		"check the entries added up to now and exit, e.g. from a scheduled job with --state-file, instead of running until interrupted")
+}
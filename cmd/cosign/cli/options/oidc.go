@@ -30,13 +30,22 @@ const DefaultOIDCIssuerURL = "https://oauth2.sigstore.dev/auth"
 type OIDCOptions struct {
 	Issuer                  string
 	ClientID                string
+	clientSecret            string
 	clientSecretFile        string
+	Scopes                  []string
 	RedirectURL             string
 	Provider                string
 	DisableAmbientProviders bool
+	Flow                    string
+	SPIFFESocket            string
 }
 
+// ClientSecret returns the OIDC client secret of --oidc-client-secret, or
+// read from the file of --oidc-client-secret-file.
 func (o *OIDCOptions) ClientSecret() (string, error) {
+	if o.clientSecret != "" {
+		return o.clientSecret, nil
+	}
 	if o.clientSecretFile != "" {
 		clientSecretBytes, err := os.ReadFile(o.clientSecretFile)
 		if err != nil {
@@ -62,9 +71,16 @@ func (o *OIDCOptions) AddFlags(cmd *cobra.Command) {
 	cmd.Flags().StringVar(&o.ClientID, "oidc-client-id", "sigstore",
 		"OIDC client ID for application")
 
+	cmd.Flags().StringVar(&o.clientSecret, "oidc-client-secret", "",
+		"OIDC client secret for application. Prefer --oidc-client-secret-file or the COSIGN_OIDC_CLIENT_SECRET environment variable, which don't expose the secret to other processes")
+
 	cmd.Flags().StringVar(&o.clientSecretFile, "oidc-client-secret-file", "",
 		"Path to file containing OIDC client secret for application")
 	_ = cmd.Flags().SetAnnotation("oidc-client-secret-file", cobra.BashCompFilenameExt, []string{})
+	cmd.MarkFlagsMutuallyExclusive("oidc-client-secret", "oidc-client-secret-file")
+
+	cmd.Flags().StringSliceVar(&o.Scopes, "oidc-scopes", nil,
+		"OIDC scopes requested with the ID token of the interactive flow (Optional). openid is always requested. The default scopes are openid and email.")
 
 	cmd.Flags().StringVar(&o.RedirectURL, "oidc-redirect-url", "",
 		"OIDC redirect URL (Optional). The default oidc-redirect-url is 'http://localhost:0/auth/callback'.")
@@ -74,4 +90,12 @@ func (o *OIDCOptions) AddFlags(cmd *cobra.Command) {
 
 	cmd.Flags().BoolVar(&o.DisableAmbientProviders, "oidc-disable-ambient-providers", false,
 		"Disable ambient OIDC providers. When true, ambient credentials will not be read")
+
+	cmd.Flags().StringVar(&o.Flow, "oidc-flow", "",
+		"OIDC flow used to get the ID token (Optional). Options include: [interactive, device, token]. interactive logs in with a browser, device prints a code to log in from another device, and token uses the token of --identity-token, SIGSTORE_ID_TOKEN or an ambient OIDC provider without prompting. If unset, token is used when a token is found, device when not running in a terminal, and interactive otherwise.")
+
+	_ = cmd.RegisterFlagCompletionFunc("oidc-flow", cobra.FixedCompletions([]string{"interactive", "device", "token"}, cobra.ShellCompDirectiveNoFileComp))
+
+	cmd.Flags().StringVar(&o.SPIFFESocket, "spiffe-socket", "",
+		"Path or address of the SPIFFE Workload API socket, e.g. unix:///run/spire/sockets/agent.sock, to get a JWT-SVID from as the ID token (Optional). The workload is then identified by its SPIFFE ID, without human interaction.")
 }
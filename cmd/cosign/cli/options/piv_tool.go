@@ -93,8 +93,10 @@ func (o *PIVToolUnblockOptions) AddFlags(cmd *cobra.Command) {
 
 // PIVToolAttestationOptions is the wrapper for `piv-tool attestation` related options.
 type PIVToolAttestationOptions struct {
-	Output string
-	Slot   string
+	Output                  string
+	Slot                    string
+	OutputDeviceCertificate string
+	OutputKeyCertificate    string
 }
 
 var _ Interface = (*PIVToolAttestationOptions)(nil)
@@ -106,6 +108,12 @@ func (o *PIVToolAttestationOptions) AddFlags(cmd *cobra.Command) {
 
 	cmd.Flags().StringVar(&o.Slot, "slot", "",
 		"Slot to use for generated key (authentication|signature|card-authentication|key-management)")
+
+	cmd.Flags().StringVar(&o.OutputDeviceCertificate, "output-device-certificate", "",
+		"write the PEM encoded device attestation certificate to FILE")
+
+	cmd.Flags().StringVar(&o.OutputKeyCertificate, "output-key-certificate", "",
+		"write the PEM encoded key attestation certificate to FILE")
 }
 
 // PIVToolGenerateKeyOptions is the wrapper for `piv-tool generate-key` related options.
@@ -115,6 +123,9 @@ type PIVToolGenerateKeyOptions struct {
 	Slot          string
 	PINPolicy     string
 	TouchPolicy   string
+
+	OutputDeviceCertificate string
+	OutputKeyCertificate    string
 }
 
 var _ Interface = (*PIVToolGenerateKeyOptions)(nil)
@@ -135,4 +146,10 @@ func (o *PIVToolGenerateKeyOptions) AddFlags(cmd *cobra.Command) {
 
 	cmd.Flags().StringVar(&o.TouchPolicy, "touch-policy", "",
 		"Touch policy for slot (never|always|cached)")
+
+	cmd.Flags().StringVar(&o.OutputDeviceCertificate, "output-device-certificate", "",
+		"write the PEM encoded device attestation certificate of the generated key to FILE")
+
+	cmd.Flags().StringVar(&o.OutputKeyCertificate, "output-key-certificate", "",
+		"write the PEM encoded key attestation certificate of the generated key to FILE")
 }
@@ -0,0 +1,145 @@
+//
+// Copyright 2023 The Sigstore Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package options
+
+import (
+	"github.com/spf13/cobra"
+
+	"github.com/sigstore/cosign/v2/pkg/cosign/rego"
+)
+
+// PolicyInitOptions is the top level wrapper for the `policy init` command.
+type PolicyInitOptions struct {
+	Namespace   string
+	Maintainers []string
+	Threshold   int
+	Expires     int
+	OutFile     string
+}
+
+var _ Interface = (*PolicyInitOptions)(nil)
+
+// AddFlags implements Interface
+func (o *PolicyInitOptions) AddFlags(cmd *cobra.Command) {
+	cmd.Flags().StringVar(&o.Namespace, "namespace", "",
+		"registry namespace the policy applies to, e.g. ghcr.io/myorg")
+	_ = cmd.MarkFlagRequired("namespace")
+
+	cmd.Flags().StringSliceVarP(&o.Maintainers, "maintainers", "m", nil,
+		"public keys of the maintainers, as accepted by --key, may be repeated")
+	_ = cmd.MarkFlagRequired("maintainers")
+
+	cmd.Flags().IntVar(&o.Threshold, "threshold", 1,
+		"number of maintainers that must sign the policy")
+
+	cmd.Flags().IntVar(&o.Expires, "expires", 365,
+		"number of days the policy is valid for")
+
+	cmd.Flags().StringVar(&o.OutFile, "out", "policy.json",
+		"output FILE for the unsigned policy, to be signed with 'cosign policy sign'")
+	_ = cmd.Flags().SetAnnotation("out", cobra.BashCompFilenameExt, []string{"json"})
+}
+
+// PolicySignOptions is the top level wrapper for the `policy sign` command.
+type PolicySignOptions struct {
+	Namespace  string
+	Key        string
+	PolicyFile string
+	Registry   RegistryOptions
+}
+
+var _ Interface = (*PolicySignOptions)(nil)
+
+// AddFlags implements Interface
+func (o *PolicySignOptions) AddFlags(cmd *cobra.Command) {
+	o.Registry.AddFlags(cmd)
+
+	cmd.Flags().StringVar(&o.Namespace, "namespace", "",
+		"registry namespace the policy applies to, e.g. ghcr.io/myorg")
+	_ = cmd.MarkFlagRequired("namespace")
+
+	cmd.Flags().StringVar(&o.Key, "key", "",
+		"path to the private key file, KMS URI or Kubernetes Secret of a maintainer")
+	_ = cmd.Flags().SetAnnotation("key", cobra.BashCompFilenameExt, []string{})
+	_ = cmd.MarkFlagRequired("key")
+
+	cmd.Flags().StringVar(&o.PolicyFile, "policy", "",
+		"policy FILE written by 'cosign policy init', the policy stored in the registry is signed when empty")
+	_ = cmd.Flags().SetAnnotation("policy", cobra.BashCompFilenameExt, []string{"json"})
+}
+
+// PolicyVerifyOptions is the top level wrapper for the `policy verify` command.
+type PolicyVerifyOptions struct {
+	Namespace string
+	Registry  RegistryOptions
+}
+
+var _ Interface = (*PolicyVerifyOptions)(nil)
+
+// AddFlags implements Interface
+func (o *PolicyVerifyOptions) AddFlags(cmd *cobra.Command) {
+	o.Registry.AddFlags(cmd)
+
+	cmd.Flags().StringVar(&o.Namespace, "namespace", "",
+		"registry namespace the policy applies to, e.g. ghcr.io/myorg")
+	_ = cmd.MarkFlagRequired("namespace")
+}
+
+// PolicyEvalOptions is the top level wrapper for the `policy eval` command.
+type PolicyEvalOptions struct {
+	Attestation  string
+	Type         string
+	Policies     []string
+	PolicyEngine string
+	RegoQuery    string
+	PolicyData   []string
+	Trace        bool
+	Output       string
+	Registry     RegistryOptions
+}
+
+var _ Interface = (*PolicyEvalOptions)(nil)
+
+// AddFlags implements Interface
+func (o *PolicyEvalOptions) AddFlags(cmd *cobra.Command) {
+	o.Registry.AddFlags(cmd)
+
+	cmd.Flags().StringVar(&o.Attestation, "attestation", "",
+		"FILE of DSSE envelopes, as written by 'cosign download attestation', or in-toto statements to evaluate instead of the attestations of an image. Use - for stdin")
+	_ = cmd.Flags().SetAnnotation("attestation", cobra.BashCompFilenameExt, []string{"json", "jsonl", "intoto.jsonl"})
+
+	cmd.Flags().StringVar(&o.Type, "type", PredicateAll,
+		"specify a predicate type (slsaprovenance|slsaprovenance02|slsaprovenance1|link|spdx|spdxjson|cyclonedx|vuln|custom) or an URI to only evaluate those attestations, or all")
+
+	cmd.Flags().StringSliceVar(&o.Policies, "policy", nil,
+		"CUE or Rego files or OPA bundles to evaluate, prefix with <predicate type>= to only apply a policy to that predicate type")
+	_ = cmd.MarkFlagRequired("policy")
+
+	cmd.Flags().StringVar(&o.PolicyEngine, "policy-engine", PolicyEngineAuto,
+		"policy engine used to evaluate the --policy files (auto|cue|rego), auto selects the engine based on the file extension, .tar.gz files being OPA bundles")
+
+	cmd.Flags().StringVar(&o.RegoQuery, "rego-query", rego.QUERY,
+		"Rego query evaluated against the attestation when validating with Rego policies, e.g. data.cosign.attestation.allow")
+
+	cmd.Flags().StringSliceVar(&o.PolicyData, "policy-data", nil,
+		"JSON or YAML data documents, or directories of them, loaded with the Rego policies")
+
+	cmd.Flags().BoolVar(&o.Trace, "trace", false,
+		"print the evaluation trace of the Rego policies for each attestation")
+
+	cmd.Flags().StringVarP(&o.Output, "output", "o", "text",
+		"output format for the policy results (json|yaml|text)")
+}
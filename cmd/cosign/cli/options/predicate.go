@@ -40,7 +40,11 @@ const (
 	PredicateVuln      = "vuln"
 )
 
-// PredicateTypeMap is the mapping between the predicate `type` option to predicate URI.
+// PredicateAll matches attestations of any predicate type when verifying.
+const PredicateAll = "all"
+
+// PredicateTypeMap is the mapping between the built-in predicate `type` options
+// and predicate URIs. Additional types are added with attestation.RegisterPredicateType.
 var PredicateTypeMap = map[string]string{
 	PredicateCustom:    attestation.CosignCustomProvenanceV01,
 	PredicateSLSA:      slsa02.PredicateSLSAProvenance,
@@ -68,7 +72,7 @@ func (o *PredicateOptions) AddFlags(cmd *cobra.Command) {
 
 // ParsePredicateType parses the predicate `type` flag passed into a predicate URI, or validates `type` is a valid URI.
 func ParsePredicateType(t string) (string, error) {
-	uri, ok := PredicateTypeMap[t]
+	uri, ok := LookupPredicateType(t)
 	if !ok {
 		if _, err := url.ParseRequestURI(t); err != nil {
 			return "", fmt.Errorf("invalid predicate type: %s", t)
@@ -78,10 +82,23 @@ func ParsePredicateType(t string) (string, error) {
 	return uri, nil
 }
 
+// LookupPredicateType returns the URI of the predicate type named t, either
+// built-in or registered with attestation.RegisterPredicateType.
+func LookupPredicateType(t string) (string, bool) {
+	if uri, ok := PredicateTypeMap[t]; ok {
+		return uri, true
+	}
+	if pt, ok := attestation.LookupPredicateType(t); ok {
+		return pt.URI, true
+	}
+	return "", false
+}
+
 // PredicateLocalOptions is the wrapper for predicate related options.
 type PredicateLocalOptions struct {
 	PredicateOptions
-	Path string
+	Path   string
+	SHA256 string
 }
 
 var _ Interface = (*PredicateLocalOptions)(nil)
@@ -91,18 +108,23 @@ func (o *PredicateLocalOptions) AddFlags(cmd *cobra.Command) {
 	o.PredicateOptions.AddFlags(cmd)
 
 	cmd.Flags().StringVar(&o.Path, "predicate", "",
-		"path to the predicate file.")
+		"path to the predicate file, an http(s):// URL to fetch it from or - to read it from standard input.")
 	_ = cmd.MarkFlagRequired("predicate")
+
+	cmd.Flags().StringVar(&o.SHA256, "predicate-sha256", "",
+		"hex-encoded SHA-256 digest the predicate must match, e.g. to pin a predicate fetched from a URL")
 }
 
 // PredicateRemoteOptions is the wrapper for remote predicate related options.
 type PredicateRemoteOptions struct {
-	PredicateOptions
+	Types []string
 }
 
 var _ Interface = (*PredicateRemoteOptions)(nil)
 
 // AddFlags implements Interface
 func (o *PredicateRemoteOptions) AddFlags(cmd *cobra.Command) {
-	o.PredicateOptions.AddFlags(cmd)
+	cmd.Flags().StringSliceVar(&o.Types, "type", []string{PredicateCustom},
+		"specify one or more predicate types (slsaprovenance|slsaprovenance02|slsaprovenance1|link|spdx|spdxjson|cyclonedx|vuln|custom) or URIs, "+
+			"may be repeated, or all to match every predicate type")
 }
@@ -23,7 +23,9 @@ import (
 type PublicKeyOptions struct {
 	Key         string
 	SecurityKey SecurityKeyOptions
+	Cert        string
 	OutFile     string
+	Output      string
 }
 
 var _ Interface = (*PublicKeyOptions)(nil)
@@ -36,7 +38,15 @@ func (o *PublicKeyOptions) AddFlags(cmd *cobra.Command) {
 		"path to the private key file, KMS URI or Kubernetes Secret")
 	_ = cmd.Flags().SetAnnotation("key", cobra.BashCompFilenameExt, []string{})
 
+	cmd.Flags().StringVar(&o.Cert, "certificate", "",
+		"path or URL of the X.509 certificate, e.g. issued by Fulcio, to get the public key of, in PEM or DER format")
+	_ = cmd.Flags().SetAnnotation("certificate", cobra.BashCompFilenameExt, []string{"cert", "crt", "pem"})
+
 	cmd.Flags().StringVar(&o.OutFile, "outfile", "",
 		"path to a payload file to use rather than generating one")
 	_ = cmd.Flags().SetAnnotation("outfile", cobra.BashCompFilenameExt, []string{})
+
+	cmd.Flags().StringVarP(&o.Output, "output", "o", "pem",
+		"output format of the public key: pem, der, jwk (JSON Web Key) or ssh (OpenSSH authorized_keys line)")
+	_ = cmd.RegisterFlagCompletionFunc("output", cobra.FixedCompletions([]string{"pem", "der", "jwk", "ssh"}, cobra.ShellCompDirectiveNoFileComp))
 }
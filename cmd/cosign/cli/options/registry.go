@@ -15,12 +15,17 @@
 package options
 
 import (
+	"bytes"
 	"context"
 	"crypto/tls"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
 	"net/http"
+	"os/exec"
+	"strings"
+	"time"
 
 	ecr "github.com/awslabs/amazon-ecr-credential-helper/ecr-login"
 	"github.com/chrismellard/docker-credential-acr-env/pkg/credhelper"
@@ -29,7 +34,9 @@ import (
 	"github.com/google/go-containerregistry/pkg/name"
 	"github.com/google/go-containerregistry/pkg/v1/google"
 	"github.com/google/go-containerregistry/pkg/v1/remote"
+	"github.com/google/go-containerregistry/pkg/v1/remote/transport"
 	alibabaacr "github.com/mozillazg/docker-credential-acr-helper/pkg/credhelper"
+	"github.com/sigstore/cosign/v2/internal/ui"
 	ociremote "github.com/sigstore/cosign/v2/pkg/oci/remote"
 	"github.com/spf13/cobra"
 )
@@ -45,6 +52,26 @@ type RegistryOptions struct {
 	RefOpts            ReferenceOptions
 	Keychain           Keychain
 
+	// Username, Password and Token are explicit credentials used for every
+	// registry, ahead of any keychain.
+	Username string
+	Password string
+	Token    string
+
+	// CredentialHelpers are the names of docker credential helpers
+	// (docker-credential-<name>) consulted before the default keychain.
+	CredentialHelpers []string
+
+	// Timeout bounds how long to wait for a registry to respond to a single
+	// request. Zero means no timeout.
+	Timeout time.Duration
+	// RetryCount is the number of times a request that failed with a
+	// retryable status is retried, waiting RetryBackoff before the first
+	// retry and doubling the wait each time. Zero keeps the defaults of the
+	// registry client.
+	RetryCount   int
+	RetryBackoff time.Duration
+
 	// RegistryClientOpts allows overriding the result of GetRegistryClientOpts.
 	RegistryClientOpts []remote.Option
 }
@@ -60,7 +87,31 @@ func (o *RegistryOptions) AddFlags(cmd *cobra.Command) {
 		"whether to allow using HTTP protocol while connecting to registries. Don't use this for anything but testing")
 
 	cmd.Flags().BoolVar(&o.KubernetesKeychain, "k8s-keychain", false,
-		"whether to use the kubernetes keychain instead of the default keychain (supports workload identity).")
+		"whether to also use the cloud provider keychains (Google, AWS ECR, Azure ACR, Alibaba ACR, GitHub) after the default keychain (supports workload identity).")
+
+	cmd.Flags().StringVar(&o.Username, "registry-username", "",
+		"username used to authenticate with every registry, instead of the credentials from the keychain")
+
+	cmd.Flags().StringVar(&o.Password, "registry-password", "",
+		"password used to authenticate with every registry, instead of the credentials from the keychain")
+
+	cmd.Flags().StringVar(&o.Token, "registry-token", "",
+		"registry (bearer) token used to authenticate with every registry, instead of the credentials from the keychain")
+
+	cmd.Flags().StringSliceVar(&o.CredentialHelpers, "registry-credential-helper", nil,
+		"name of a docker credential helper (the docker-credential-<name> binary on $PATH) to consult before the default keychain. May be repeated")
+
+	cmd.Flags().DurationVar(&o.Timeout, "registry-timeout", 0,
+		"how long to wait for a registry to respond to a single request (e.g. 30s). 0 means no timeout")
+
+	cmd.Flags().IntVar(&o.RetryCount, "registry-retries", 3,
+		"number of times to retry a registry request that failed with a 408, 429 or 5xx response or a temporary network error")
+
+	cmd.Flags().DurationVar(&o.RetryBackoff, "registry-retry-backoff", time.Second,
+		"how long to wait before the first retry of a registry request; the wait doubles on each following retry")
+
+	cmd.MarkFlagsRequiredTogether("registry-username", "registry-password")
+	cmd.MarkFlagsMutuallyExclusive("registry-password", "registry-token")
 
 	o.RefOpts.AddFlags(cmd)
 }
@@ -100,26 +151,56 @@ func (o *RegistryOptions) GetRegistryClientOpts(ctx context.Context) []remote.Op
 		remote.WithUserAgent(UserAgent()),
 	}
 
-	switch {
-	case o.Keychain != nil:
-		opts = append(opts, remote.WithAuthFromKeychain(o.Keychain))
-	case o.KubernetesKeychain:
-		kc := authn.NewMultiKeychain(
-			authn.DefaultKeychain,
-			google.Keychain,
-			authn.NewKeychainFromHelper(ecr.NewECRHelper(ecr.WithLogger(io.Discard))),
-			authn.NewKeychainFromHelper(credhelper.NewACRCredentialsHelper()),
-			authn.NewKeychainFromHelper(alibabaacr.NewACRHelper().WithLoggerOut(io.Discard)),
-			github.Keychain,
-		)
-		opts = append(opts, remote.WithAuthFromKeychain(kc))
-	default:
-		opts = append(opts, remote.WithAuthFromKeychain(authn.DefaultKeychain))
-	}
+	opts = append(opts, remote.WithAuthFromKeychain(o.keychain()))
 
+	t := Transport(EndpointRegistry)
 	if o.AllowInsecure {
-		opts = append(opts, remote.WithTransport(&http.Transport{TLSClientConfig: &tls.Config{InsecureSkipVerify: true}})) // #nosec G402
+		if t == nil {
+			t = &http.Transport{TLSClientConfig: &tls.Config{}}
+		}
+		t.TLSClientConfig.InsecureSkipVerify = true // #nosec G402
 	}
+	if o.Timeout > 0 {
+		if t == nil {
+			t = remote.DefaultTransport.(*http.Transport).Clone()
+		}
+		t.ResponseHeaderTimeout = o.Timeout
+		t.TLSHandshakeTimeout = o.Timeout
+	}
+	var rt http.RoundTripper = remote.DefaultTransport
+	if t != nil {
+		rt = t
+	}
+	rt = ui.NewTransport(EndpointRegistry, WrapTransport(EndpointRegistry, rt))
+
+	if o.RetryCount > 0 {
+		backoff := o.RetryBackoff
+		if backoff <= 0 {
+			backoff = time.Second
+		}
+		b := remote.Backoff{
+			Duration: backoff,
+			Factor:   2.0,
+			Jitter:   0.1,
+			Steps:    o.RetryCount + 1,
+		}
+		// The retry transport the registry client wraps around ours has a
+		// fixed backoff, so retry the statuses here and leave it only the
+		// temporary network errors.
+		rt = transport.NewRetry(rt,
+			transport.WithRetryBackoff(b),
+			transport.WithRetryStatusCodes(
+				http.StatusRequestTimeout,
+				http.StatusTooManyRequests,
+				http.StatusInternalServerError,
+				http.StatusBadGateway,
+				http.StatusServiceUnavailable,
+				http.StatusGatewayTimeout,
+			),
+		)
+		opts = append(opts, remote.WithRetryBackoff(b), remote.WithRetryStatusCodes())
+	}
+	opts = append(opts, remote.WithTransport(rt))
 
 	// Reuse a remote.Pusher and a remote.Puller for all operations that use these opts.
 	// This allows us to avoid re-authenticating for everying remote.Function we call,
@@ -136,6 +217,78 @@ func (o *RegistryOptions) GetRegistryClientOpts(ctx context.Context) []remote.Op
 	return opts
 }
 
+// keychain returns the chain of keychains used to authenticate with
+// registries. Explicit credentials come first, then the configured
+// credential helpers, then the default (docker config) keychain and,
+// with --k8s-keychain, the cloud provider keychains.
+func (o *RegistryOptions) keychain() authn.Keychain {
+	if o.Keychain != nil {
+		return o.Keychain
+	}
+
+	var kcs []authn.Keychain
+	if o.Username != "" || o.Password != "" || o.Token != "" {
+		kcs = append(kcs, staticKeychain{authn.FromConfig(authn.AuthConfig{
+			Username:      o.Username,
+			Password:      o.Password,
+			RegistryToken: o.Token,
+		})})
+	}
+	for _, h := range o.CredentialHelpers {
+		kcs = append(kcs, authn.NewKeychainFromHelper(execHelper{name: h}))
+	}
+	kcs = append(kcs, authn.DefaultKeychain)
+	if o.KubernetesKeychain {
+		kcs = append(kcs,
+			google.Keychain,
+			authn.NewKeychainFromHelper(ecr.NewECRHelper(ecr.WithLogger(io.Discard))),
+			authn.NewKeychainFromHelper(credhelper.NewACRCredentialsHelper()),
+			authn.NewKeychainFromHelper(alibabaacr.NewACRHelper().WithLoggerOut(io.Discard)),
+			github.Keychain,
+		)
+	}
+	if len(kcs) == 1 {
+		return kcs[0]
+	}
+	return authn.NewMultiKeychain(kcs...)
+}
+
+// staticKeychain resolves every registry to the same credentials.
+type staticKeychain struct {
+	auth authn.Authenticator
+}
+
+func (k staticKeychain) Resolve(authn.Resource) (authn.Authenticator, error) {
+	return k.auth, nil
+}
+
+// execHelper implements authn.Helper by running a docker credential helper
+// binary, following the docker-credential-helpers protocol.
+type execHelper struct {
+	name string
+}
+
+func (h execHelper) Get(serverURL string) (string, string, error) {
+	var stdout, stderr bytes.Buffer
+	cmd := exec.Command("docker-credential-"+h.name, "get") //nolint:gosec
+	cmd.Stdin = strings.NewReader(serverURL)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	// Errors, including "credentials not found", make the keychain fall
+	// through to the next one in the chain.
+	if err := cmd.Run(); err != nil {
+		return "", "", fmt.Errorf("running credential helper %q: %w: %s", h.name, err, strings.TrimSpace(stderr.String()))
+	}
+	var creds struct {
+		Username string
+		Secret   string
+	}
+	if err := json.Unmarshal(stdout.Bytes(), &creds); err != nil {
+		return "", "", fmt.Errorf("parsing output of credential helper %q: %w", h.name, err)
+	}
+	return creds.Username, creds.Secret, nil
+}
+
 type RegistryReferrersMode string
 
 const (
@@ -0,0 +1,163 @@
+//
+// Copyright 2023 The Sigstore Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package options
+
+import (
+	"context"
+	"io"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/google/go-containerregistry/pkg/authn"
+	"github.com/google/go-containerregistry/pkg/name"
+	"github.com/google/go-containerregistry/pkg/registry"
+	"github.com/google/go-containerregistry/pkg/v1/random"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
+)
+
+func TestRegistryKeychain(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("credential helper script requires a POSIX shell")
+	}
+	bin := t.TempDir()
+	helper := `#!/bin/sh
+read server
+if [ "$server" = "helper.example.com" ]; then
+  echo '{"ServerURL":"helper.example.com","Username":"helper-user","Secret":"helper-secret"}'
+  exit 0
+fi
+echo "credentials not found in native keychain"
+exit 1
+`
+	if err := os.WriteFile(filepath.Join(bin, "docker-credential-fake"), []byte(helper), 0o755); err != nil { //nolint:gosec
+		t.Fatal(err)
+	}
+	t.Setenv("PATH", bin)
+	t.Setenv("DOCKER_CONFIG", t.TempDir())
+
+	tests := []struct {
+		name string
+		opts RegistryOptions
+		repo string
+		want authn.AuthConfig
+	}{{
+		name: "username and password",
+		opts: RegistryOptions{Username: "user", Password: "pass"},
+		repo: "registry.example.com/foo",
+		want: authn.AuthConfig{Username: "user", Password: "pass"},
+	}, {
+		name: "token",
+		opts: RegistryOptions{Token: "tok"},
+		repo: "registry.example.com/foo",
+		want: authn.AuthConfig{RegistryToken: "tok"},
+	}, {
+		name: "explicit credentials win over helpers",
+		opts: RegistryOptions{Username: "user", Password: "pass", CredentialHelpers: []string{"fake"}},
+		repo: "helper.example.com/foo",
+		want: authn.AuthConfig{Username: "user", Password: "pass"},
+	}, {
+		name: "credential helper",
+		opts: RegistryOptions{CredentialHelpers: []string{"fake"}},
+		repo: "helper.example.com/foo",
+		want: authn.AuthConfig{Username: "helper-user", Password: "helper-secret"},
+	}, {
+		name: "credential helper without credentials",
+		opts: RegistryOptions{CredentialHelpers: []string{"fake"}},
+		repo: "registry.example.com/foo",
+		want: authn.AuthConfig{},
+	}, {
+		name: "missing credential helper",
+		opts: RegistryOptions{CredentialHelpers: []string{"missing"}},
+		repo: "helper.example.com/foo",
+		want: authn.AuthConfig{},
+	}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo, err := name.NewRepository(tt.repo)
+			if err != nil {
+				t.Fatal(err)
+			}
+			auth, err := tt.opts.keychain().Resolve(repo)
+			if err != nil {
+				t.Fatalf("Resolve() = %v", err)
+			}
+			got, err := auth.Authorization()
+			if err != nil {
+				t.Fatalf("Authorization() = %v", err)
+			}
+			if *got != tt.want {
+				t.Errorf("Authorization() = %+v, want %+v", *got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRegistryRetries(t *testing.T) {
+	reg := registry.New(registry.Logger(log.New(io.Discard, "", 0)))
+	var failures atomic.Int32
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.Contains(r.URL.Path, "/manifests/") && failures.Add(-1) >= 0 {
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		reg.ServeHTTP(w, r)
+	}))
+	defer s.Close()
+	u, err := url.Parse(s.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ref, err := name.ParseReference(u.Host + "/repo:latest")
+	if err != nil {
+		t.Fatal(err)
+	}
+	img, err := random.Image(10, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := remote.Write(ref, img); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name     string
+		retries  int
+		failures int32
+		wantErr  bool
+	}{
+		{name: "recovers after rate limiting", retries: 3, failures: 2},
+		{name: "gives up after the retries", retries: 1, failures: 2, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			failures.Store(tt.failures)
+			o := RegistryOptions{RetryCount: tt.retries, RetryBackoff: time.Millisecond, Timeout: 10 * time.Second}
+			_, err := remote.Head(ref, o.GetRegistryClientOpts(context.Background())...)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Head() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
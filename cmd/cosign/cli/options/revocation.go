@@ -0,0 +1,113 @@
+//
+// Copyright 2023 The Sigstore Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package options
+
+import (
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+// RevocationOptions configures the revocation list consulted to reject
+// revoked signatures.
+type RevocationOptions struct {
+	List string
+	Key  string
+	TTL  time.Duration
+}
+
+var _ Interface = (*RevocationOptions)(nil)
+
+// AddFlags implements Interface
+func (o *RevocationOptions) AddFlags(cmd *cobra.Command) {
+	cmd.Flags().StringVar(&o.List, "revocation-list", "",
+		"revocation list published by 'cosign revocation publish', as oci://REF, an https URL or a FILE. "+
+			"Images whose digest it lists, and signatures made by the keys it lists, fail verification")
+	_ = cmd.Flags().SetAnnotation("revocation-list", cobra.BashCompFilenameExt, []string{"json"})
+
+	cmd.Flags().StringVar(&o.Key, "revocation-list-key", "",
+		"path to the public key file, KMS URI or Kubernetes Secret trusted to sign the revocation list")
+	_ = cmd.Flags().SetAnnotation("revocation-list-key", cobra.BashCompFilenameExt, []string{"pub"})
+	cmd.MarkFlagsRequiredTogether("revocation-list", "revocation-list-key")
+
+	cmd.Flags().DurationVar(&o.TTL, "revocation-list-ttl", 5*time.Minute,
+		"reuse the revocation list cached in cosign/revocation in the user cache directory for this long before fetching it again. "+
+			"The cached list is also used, until it expires, when fetching it fails. 0 disables the cache")
+}
+
+// RevocationPublishOptions is the top level wrapper for the `revocation
+// publish` command.
+type RevocationPublishOptions struct {
+	Key         string
+	Digests     []string
+	RevokedKeys []string
+	KeyIDs      []string
+	From        string
+	Expires     int
+	OutFile     string
+	Registry    RegistryOptions
+}
+
+var _ Interface = (*RevocationPublishOptions)(nil)
+
+// AddFlags implements Interface
+func (o *RevocationPublishOptions) AddFlags(cmd *cobra.Command) {
+	o.Registry.AddFlags(cmd)
+
+	cmd.Flags().StringVar(&o.Key, "key", "",
+		"path to the private key file, KMS URI or Kubernetes Secret signing the revocation list")
+	_ = cmd.Flags().SetAnnotation("key", cobra.BashCompFilenameExt, []string{})
+	_ = cmd.MarkFlagRequired("key")
+
+	cmd.Flags().StringSliceVar(&o.Digests, "digest", nil,
+		"image digest to revoke, e.g. sha256:abc..., may be repeated")
+
+	cmd.Flags().StringSliceVar(&o.RevokedKeys, "revoked-key", nil,
+		"public key to revoke, as accepted by --key, may be repeated")
+	_ = cmd.Flags().SetAnnotation("revoked-key", cobra.BashCompFilenameExt, []string{"pub"})
+
+	cmd.Flags().StringSliceVar(&o.KeyIDs, "revoked-key-id", nil,
+		"key ID of a public key to revoke, the hex encoded SHA256 digest of its PKIX encoding, may be repeated")
+
+	cmd.Flags().StringVar(&o.From, "from", "",
+		"revocation list to extend, as oci://REF, an https URL or a FILE, which must be signed by --key")
+
+	cmd.Flags().IntVar(&o.Expires, "expires", 30,
+		"number of days the revocation list is valid for, verification fails once it expires")
+
+	cmd.Flags().StringVar(&o.OutFile, "out", "",
+		"write the signed revocation list to this FILE, e.g. to serve it at an https URL")
+	_ = cmd.Flags().SetAnnotation("out", cobra.BashCompFilenameExt, []string{"json"})
+}
+
+// RevocationVerifyOptions is the top level wrapper for the `revocation
+// verify` command.
+type RevocationVerifyOptions struct {
+	Key      string
+	Registry RegistryOptions
+}
+
+var _ Interface = (*RevocationVerifyOptions)(nil)
+
+// AddFlags implements Interface
+func (o *RevocationVerifyOptions) AddFlags(cmd *cobra.Command) {
+	o.Registry.AddFlags(cmd)
+
+	cmd.Flags().StringVar(&o.Key, "key", "",
+		"path to the public key file, KMS URI or Kubernetes Secret trusted to sign the revocation list")
+	_ = cmd.Flags().SetAnnotation("key", cobra.BashCompFilenameExt, []string{"pub"})
+	_ = cmd.MarkFlagRequired("key")
+}
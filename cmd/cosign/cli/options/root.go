@@ -16,14 +16,19 @@
 package options
 
 import (
+	"errors"
 	"fmt"
+	"io/fs"
 	"os"
+	"path/filepath"
 	"strings"
 	"time"
 
 	"github.com/spf13/cobra"
 	"github.com/spf13/pflag"
 	"github.com/spf13/viper"
+
+	"github.com/sigstore/cosign/v2/pkg/cosign/env"
 )
 
 const EnvPrefix = "COSIGN"
@@ -32,7 +37,13 @@ const EnvPrefix = "COSIGN"
 type RootOptions struct {
 	OutputFile string
 	Verbose    bool
+	Debug      bool
+	LogFormat  string
 	Timeout    time.Duration
+
+	HTTPProxies           []string
+	CACerts               []string
+	InsecureSkipTLSVerify []string
 }
 
 // DefaultTimeout specifies the default timeout for commands.
@@ -47,18 +58,85 @@ func (o *RootOptions) AddFlags(cmd *cobra.Command) {
 	_ = cmd.Flags().SetAnnotation("output-file", cobra.BashCompFilenameExt, []string{})
 
 	cmd.PersistentFlags().BoolVarP(&o.Verbose, "verbose", "d", false,
-		"log debug output")
+		"log debug output, including every call to the registry, fulcio, rekor, the timestamp authority and KMS with its duration")
+
+	cmd.PersistentFlags().BoolVar(&o.Debug, "debug", false,
+		"log the --verbose output and dump the HTTP requests and responses exchanged with registries")
+
+	cmd.PersistentFlags().StringVar(&o.LogFormat, "log-format", "text",
+		"format of the log output. allowed: text, json")
 
 	cmd.PersistentFlags().DurationVarP(&o.Timeout, "timeout", "t", DefaultTimeout,
 		"timeout for commands")
+
+	cmd.PersistentFlags().StringSliceVar(&o.HTTPProxies, "http-proxy", nil,
+		"[ENDPOINT=]URL of the HTTP(S) proxy used to reach the registry, fulcio and rekor, or only ENDPOINT. May be repeated. Defaults to the HTTP_PROXY/HTTPS_PROXY environment")
+
+	cmd.PersistentFlags().StringSliceVar(&o.CACerts, "cacert", nil,
+		"[ENDPOINT=]path to a PEM-encoded CA bundle trusted, in addition to the system roots, when connecting to the registry, fulcio and rekor, or only ENDPOINT. May be repeated")
+	_ = cmd.PersistentFlags().SetAnnotation("cacert", cobra.BashCompFilenameExt, []string{"pem", "crt"})
+
+	cmd.PersistentFlags().StringSliceVar(&o.InsecureSkipTLSVerify, "insecure-skip-tls-verify", nil,
+		"ENDPOINT (registry, fulcio, rekor or all) whose TLS certificate is not verified. Don't use this for anything but testing")
+	cmd.PersistentFlags().Lookup("insecure-skip-tls-verify").NoOptDefVal = "all"
 }
 
+// BindViper sets the flags of cmd that are not set on the command line from,
+// in order of precedence:
+//
+//   - their COSIGN_ environment variable, e.g. COSIGN_REKOR_URL for --rekor-url,
+//   - the section of the command in the configuration file, e.g. the
+//     rekor-url key of verify, or of dockerfile.verify for cosign dockerfile
+//     verify,
+//   - the top level keys of the configuration file, e.g. rekor-url.
+//
+// The configuration file is the YAML file of ConfigFile, when it exists.
 func BindViper(cmd *cobra.Command, args []string) {
-	callPersistentPreRun(cmd, args)
 	v := viper.New()
 	v.SetEnvPrefix(EnvPrefix)
 	v.AutomaticEnv()
-	bindFlags(cmd, v)
+	if err := readConfig(v); err != nil {
+		cmd.PrintErrln("Error:", err.Error())
+		os.Exit(1)
+	}
+	if err := bindFlags(cmd, v); err != nil {
+		cmd.PrintErrln("Error:", err.Error())
+		os.Exit(1)
+	}
+	// The parents use the flags they define, so they run once the flags are
+	// bound.
+	callPersistentPreRun(cmd, args)
+}
+
+// ConfigFile returns the path of the configuration file setting the default
+// values of the flags, COSIGN_CONFIG or ~/.cosign/config.yaml.
+func ConfigFile() string {
+	if p := env.Getenv(env.VariableConfig); p != "" {
+		return p
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(home, ".cosign", "config.yaml")
+}
+
+// readConfig reads the configuration file into v. Only a file set with
+// COSIGN_CONFIG must exist.
+func readConfig(v *viper.Viper) error {
+	path := ConfigFile()
+	if path == "" {
+		return nil
+	}
+	v.SetConfigFile(path)
+	v.SetConfigType("yaml")
+	if err := v.ReadInConfig(); err != nil {
+		if errors.Is(err, fs.ErrNotExist) && env.Getenv(env.VariableConfig) == "" {
+			return nil
+		}
+		return fmt.Errorf("reading configuration file %s: %w", path, err)
+	}
+	return nil
 }
 
 // callPersistentPreRun calls parent commands. PersistentPreRun
@@ -79,16 +157,61 @@ func callPersistentPreRun(cmd *cobra.Command, args []string) {
 	}
 }
 
-func bindFlags(cmd *cobra.Command, v *viper.Viper) {
+func bindFlags(cmd *cobra.Command, v *viper.Viper) error {
+	section := configSection(cmd)
+	var errs []error
 	cmd.Flags().VisitAll(func(f *pflag.Flag) {
 		if strings.Contains(f.Name, "-") {
 			_ = v.BindEnv(f.Name, flagToEnvVar(f.Name))
 		}
-		if !f.Changed && v.IsSet((f.Name)) {
-			val := v.Get(f.Name)
-			_ = cmd.Flags().Set(f.Name, fmt.Sprintf("%v", val))
+		if f.Changed {
+			return
+		}
+		key := f.Name
+		if _, ok := os.LookupEnv(flagToEnvVar(f.Name)); !ok && section != "" && v.IsSet(section+"."+f.Name) {
+			key = section + "." + f.Name
+		}
+		if !v.IsSet(key) {
+			return
+		}
+		if err := setFlag(cmd.Flags(), f, v.Get(key)); err != nil {
+			errs = append(errs, fmt.Errorf("invalid value for --%s: %w", f.Name, err))
 		}
 	})
+	return errors.Join(errs...)
+}
+
+// configSection returns the key of the section of cmd in the configuration
+// file, e.g. dockerfile.verify for cosign dockerfile verify.
+func configSection(cmd *cobra.Command) string {
+	var names []string
+	for c := cmd; c.HasParent(); c = c.Parent() {
+		names = append([]string{c.Name()}, names...)
+	}
+	return strings.Join(names, ".")
+}
+
+// setFlag sets f to val, read from the environment or the configuration
+// file, where lists are YAML sequences.
+func setFlag(flags *pflag.FlagSet, f *pflag.Flag, val interface{}) error {
+	switch val := val.(type) {
+	case map[string]interface{}:
+		// The section of a command named like the flag, e.g. policy.
+		return nil
+	case []interface{}:
+		if sv, ok := f.Value.(pflag.SliceValue); ok {
+			s := make([]string, 0, len(val))
+			for _, e := range val {
+				s = append(s, fmt.Sprintf("%v", e))
+			}
+			if err := sv.Replace(s); err != nil {
+				return err
+			}
+			f.Changed = true
+			return nil
+		}
+	}
+	return flags.Set(f.Name, fmt.Sprintf("%v", val))
 }
 
 func flagToEnvVar(f string) string {
@@ -16,9 +16,13 @@
 package options
 
 import (
+	"os"
+	"path/filepath"
 	"testing"
 
 	"github.com/google/go-cmp/cmp"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
 )
 
 func TestFlagToEnv(t *testing.T) {
@@ -57,3 +61,89 @@ func TestFlagToEnv(t *testing.T) {
 		})
 	}
 }
+
+func TestBindFlagsConfig(t *testing.T) {
+	config := filepath.Join(t.TempDir(), "config.yaml")
+	if err := os.WriteFile(config, []byte(`
+rekor-url: https://rekor.example.com
+timeout: 5m
+verify:
+  rekor-url: https://verify.rekor.example.com
+  annotations: [team=core, env=prod]
+policy:
+  init: {}
+`), 0600); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("COSIGN_CONFIG", config)
+	t.Setenv("COSIGN_TIMEOUT", "1m")
+
+	newCmd := func(name string) (*cobra.Command, *string, *[]string, *string) {
+		root := &cobra.Command{Use: "cosign"}
+		cmd := &cobra.Command{Use: name}
+		root.AddCommand(cmd)
+		rekorURL := cmd.Flags().String("rekor-url", "https://rekor.sigstore.dev", "")
+		annotations := cmd.Flags().StringSlice("annotations", nil, "")
+		cmd.Flags().String("timeout", "3m", "")
+		policy := cmd.Flags().String("policy", "", "")
+		return cmd, rekorURL, annotations, policy
+	}
+	bind := func(cmd *cobra.Command) {
+		v := viper.New()
+		v.SetEnvPrefix(EnvPrefix)
+		v.AutomaticEnv()
+		if err := readConfig(v); err != nil {
+			t.Fatal(err)
+		}
+		if err := bindFlags(cmd, v); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// The section of the command wins over the top level keys.
+	cmd, rekorURL, annotations, policy := newCmd("verify")
+	bind(cmd)
+	if *rekorURL != "https://verify.rekor.example.com" {
+		t.Errorf("rekor-url = %s, want the verify section's", *rekorURL)
+	}
+	if diff := cmp.Diff([]string{"team=core", "env=prod"}, *annotations); diff != "" {
+		t.Error(diff)
+	}
+	// The environment wins over the configuration file.
+	if got := cmd.Flags().Lookup("timeout").Value.String(); got != "1m" {
+		t.Errorf("timeout = %s, want 1m", got)
+	}
+	// Sections aren't flag values.
+	if *policy != "" {
+		t.Errorf("policy = %s, want unset", *policy)
+	}
+
+	// Other commands use the top level keys, and the command line wins.
+	cmd, rekorURL, _, _ = newCmd("sign")
+	bind(cmd)
+	if *rekorURL != "https://rekor.example.com" {
+		t.Errorf("rekor-url = %s, want the top level one", *rekorURL)
+	}
+	cmd, rekorURL, _, _ = newCmd("sign")
+	if err := cmd.Flags().Set("rekor-url", "https://flag.example.com"); err != nil {
+		t.Fatal(err)
+	}
+	bind(cmd)
+	if *rekorURL != "https://flag.example.com" {
+		t.Errorf("rekor-url = %s, want the flag's", *rekorURL)
+	}
+}
+
+func TestReadConfigMissing(t *testing.T) {
+	missing := filepath.Join(t.TempDir(), "config.yaml")
+	t.Setenv("COSIGN_CONFIG", missing)
+	if err := readConfig(viper.New()); err == nil {
+		t.Error("expected error for a missing COSIGN_CONFIG")
+	}
+
+	t.Setenv("COSIGN_CONFIG", "")
+	t.Setenv("HOME", t.TempDir())
+	if err := readConfig(viper.New()); err != nil {
+		t.Errorf("unexpected error without configuration file: %v", err)
+	}
+}
@@ -22,6 +22,7 @@ import (
 // SaveOptions is the top level wrapper for the load command.
 type SaveOptions struct {
 	Directory string
+	Tarball   string
 }
 
 var _ Interface = (*SaveOptions)(nil)
@@ -31,5 +32,9 @@ func (o *SaveOptions) AddFlags(cmd *cobra.Command) {
 	cmd.Flags().StringVar(&o.Directory, "dir", "",
 		"path to dir where the signed image should be stored on disk")
 	_ = cmd.Flags().SetAnnotation("dir", cobra.BashCompSubdirsInDir, []string{})
-	_ = cmd.MarkFlagRequired("dir")
+
+	cmd.Flags().StringVar(&o.Tarball, "tarball", "",
+		"path to a tar FILE the signed image should be stored in instead of a directory, e.g. to carry it into an air-gapped environment")
+	_ = cmd.Flags().SetAnnotation("tarball", cobra.BashCompFilenameExt, []string{"tar"})
+	cmd.MarkFlagsMutuallyExclusive("dir", "tarball")
 }
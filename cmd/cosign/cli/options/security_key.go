@@ -35,3 +35,27 @@ func (o *SecurityKeyOptions) AddFlags(cmd *cobra.Command) {
 	cmd.Flags().StringVar(&o.Slot, "slot", "",
 		"security key slot to use for generated key (default: signature) (authentication|signature|card-authentication|key-management)")
 }
+
+// PIVAttestationOptions is the wrapper for the options requiring signing keys
+// to be attested as generated on a PIV hardware token.
+type PIVAttestationOptions struct {
+	Roots         string
+	PINPolicies   []string
+	TouchPolicies []string
+}
+
+var _ Interface = (*PIVAttestationOptions)(nil)
+
+// AddFlags implements Interface
+func (o *PIVAttestationOptions) AddFlags(cmd *cobra.Command) {
+	cmd.Flags().StringVar(&o.Roots, "piv-attestation-roots", "",
+		"path or URL of the PEM-encoded PIV attestation root CAs, e.g. https://developers.yubico.com/PIV/Introduction/piv-attestation-ca.pem. "+
+			"Requires signatures to carry the attestation, recorded by 'cosign sign --sk', that their key was generated on a hardware token")
+	_ = cmd.Flags().SetAnnotation("piv-attestation-roots", cobra.BashCompFilenameExt, []string{"pem", "crt"})
+
+	cmd.Flags().StringSliceVar(&o.PINPolicies, "piv-pin-policy", nil,
+		"PIN policies allowed for the PIV attested key (never|once|always). Requires --piv-attestation-roots")
+
+	cmd.Flags().StringSliceVar(&o.TouchPolicies, "piv-touch-policy", nil,
+		"touch policies allowed for the PIV attested key (never|always|cached). Requires --piv-attestation-roots")
+}
@@ -0,0 +1,85 @@
+//
+// Copyright 2023 The Sigstore Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package options
+
+import (
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+// ServeOptions is the top level wrapper for the `serve` command.
+type ServeOptions struct {
+	Address              string
+	TLSCertFile          string
+	TLSKeyFile           string
+	Key                  string
+	CertIdentity         string
+	CertIdentityRegexp   string
+	CertOidcIssuer       string
+	CertOidcIssuerRegexp string
+	IgnoreTlog           bool
+	CacheTTL             time.Duration
+
+	Rekor    RekorOptions
+	Registry RegistryOptions
+}
+
+var _ Interface = (*ServeOptions)(nil)
+
+// AddFlags implements Interface
+func (o *ServeOptions) AddFlags(cmd *cobra.Command) {
+	o.Rekor.AddFlags(cmd)
+	o.Registry.AddFlags(cmd)
+
+	cmd.Flags().StringVar(&o.Address, "address", ":8080",
+		"address the server listens on")
+
+	cmd.Flags().StringVar(&o.TLSCertFile, "tls-cert-file", "",
+		"path to the PEM encoded TLS certificate served, instead of serving plain HTTP")
+	_ = cmd.Flags().SetAnnotation("tls-cert-file", cobra.BashCompFilenameExt, []string{"pem", "crt"})
+
+	cmd.Flags().StringVar(&o.TLSKeyFile, "tls-key-file", "",
+		"path to the PEM encoded private key of the TLS certificate")
+	_ = cmd.Flags().SetAnnotation("tls-key-file", cobra.BashCompFilenameExt, []string{"pem", "key"})
+	cmd.MarkFlagsRequiredTogether("tls-cert-file", "tls-key-file")
+
+	cmd.Flags().StringVar(&o.Key, "key", "",
+		"path to the public key file, KMS URI or Kubernetes Secret trusted to sign the images, loaded once at startup")
+	_ = cmd.Flags().SetAnnotation("key", cobra.BashCompFilenameExt, []string{"pub"})
+
+	cmd.Flags().StringVar(&o.CertIdentity, "certificate-identity", "",
+		"The identity expected in a valid Fulcio certificate. Valid values include email address, DNS names, IP addresses, and URIs. Either --certificate-identity or --certificate-identity-regexp must be set for keyless flows.")
+
+	cmd.Flags().StringVar(&o.CertIdentityRegexp, "certificate-identity-regexp", "",
+		"A regular expression alternative to --certificate-identity. Accepts the Go regular expression syntax described at https://golang.org/s/re2syntax. Either --certificate-identity or --certificate-identity-regexp must be set for keyless flows.")
+
+	cmd.Flags().StringVar(&o.CertOidcIssuer, "certificate-oidc-issuer", "",
+		"The OIDC issuer expected in a valid Fulcio certificate, e.g. https://token.actions.githubusercontent.com or https://oauth2.sigstore.dev/auth. Either --certificate-oidc-issuer or --certificate-oidc-issuer-regexp must be set for keyless flows.")
+
+	cmd.Flags().StringVar(&o.CertOidcIssuerRegexp, "certificate-oidc-issuer-regexp", "",
+		"A regular expression alternative to --certificate-oidc-issuer. Accepts the Go regular expression syntax described at https://golang.org/s/re2syntax. Either --certificate-oidc-issuer or --certificate-oidc-issuer-regexp must be set for keyless flows.")
+
+	cmd.MarkFlagsMutuallyExclusive("key", "certificate-identity")
+	cmd.MarkFlagsMutuallyExclusive("key", "certificate-identity-regexp")
+
+	cmd.Flags().BoolVar(&o.IgnoreTlog, "insecure-ignore-tlog", false,
+		"ignore transparency log verification, to be used when an artifact signature has not been uploaded to the transparency log. Artifacts "+
+			"cannot be publicly verified when not included in a log")
+
+	cmd.Flags().DurationVar(&o.CacheTTL, "cache-ttl", 0,
+		"how long to remember the images verified, e.g. 5m, so that verifying them again skips their signatures and attestations. 0 disables the cache")
+}
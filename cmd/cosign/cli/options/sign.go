@@ -17,6 +17,8 @@ package options
 
 import (
 	"github.com/spf13/cobra"
+
+	"github.com/sigstore/cosign/v2/internal/pkg/cosign"
 )
 
 // SignOptions is the top level wrapper for the sign command.
@@ -25,13 +27,16 @@ type SignOptions struct {
 	Cert                  string
 	CertChain             string
 	Upload                bool
+	NoUpload              bool
 	Output                string // deprecated: TODO remove when the output flag is fully deprecated
 	OutputSignature       string // TODO: this should be the root output file arg.
 	OutputPayload         string
 	OutputCertificate     string
+	OutputKey             string
 	PayloadPath           string
 	Recursive             bool
 	Attachment            string
+	LocalImage            bool
 	SkipConfirmation      bool
 	TlogUpload            bool
 	TSAClientCACert       string
@@ -41,6 +46,10 @@ type SignOptions struct {
 	TSAServerURL          string
 	IssueCertificate      bool
 	SignContainerIdentity string
+	MaxWorkers            int
+	AnnotationsFile       string
+	AutoAnnotate          bool
+	AttachToExisting      bool
 
 	Rekor       RekorOptions
 	Fulcio      FulcioOptions
@@ -81,6 +90,13 @@ func (o *SignOptions) AddFlags(cmd *cobra.Command) {
 	cmd.Flags().BoolVar(&o.Upload, "upload", true,
 		"whether to upload the signature")
 
+	cmd.Flags().BoolVar(&o.NoUpload, "no-upload", false,
+		"do not upload the signature, same as --upload=false, e.g. to write it with --output-signature and --output-payload and attach it later with 'cosign attach signature'")
+	cmd.MarkFlagsMutuallyExclusive("upload", "no-upload")
+
+	cmd.Flags().BoolVar(&o.LocalImage, "local-image", false,
+		"whether the specified image is a path to an image saved locally via 'cosign save' or another OCI layout, e.g. from 'crane pull --format oci'. The signature is stored in the layout, see 'cosign load' to push it along with the image. Requires --sign-container-identity")
+
 	cmd.Flags().StringVar(&o.OutputSignature, "output-signature", "",
 		"write the signature to FILE")
 	_ = cmd.Flags().SetAnnotation("output-signature", cobra.BashCompFilenameExt, []string{})
@@ -92,15 +108,24 @@ func (o *SignOptions) AddFlags(cmd *cobra.Command) {
 		"write the certificate to FILE")
 	_ = cmd.Flags().SetAnnotation("output-certificate", cobra.BashCompFilenameExt, []string{})
 
+	cmd.Flags().StringVar(&o.OutputKey, "output-key", "",
+		"write the ephemeral private key of keyless signing to FILE, encrypted with the key password, to sign with it and its certificate again with --key and --certificate until the certificate expires")
+	_ = cmd.Flags().SetAnnotation("output-key", cobra.BashCompFilenameExt, []string{})
+
 	cmd.Flags().StringVar(&o.PayloadPath, "payload", "",
 		"path to a payload file to use rather than generating one")
 	_ = cmd.Flags().SetAnnotation("payload", cobra.BashCompFilenameExt, []string{})
 
+	cmd.Flags().BoolVar(&o.AttachToExisting, "attach-to-existing", false,
+		"counter-sign the payload of the existing signature of the image instead of generating one, appending a signature of the same payload, "+
+			"e.g. for QA or security teams to approve a release signed by its builder. Use verify --threshold-policy to report every signer")
+	cmd.MarkFlagsMutuallyExclusive("attach-to-existing", "payload")
+
 	cmd.Flags().BoolVarP(&o.Recursive, "recursive", "r", false,
 		"if a multi-arch image is specified, additionally sign each discrete image")
 
 	cmd.Flags().StringVar(&o.Attachment, "attachment", "",
-		"DEPRECATED, related image attachment to sign (sbom), default none")
+		"related image attachment to sign, e.g. sbom (DEPRECATED), wasm or the name of any attachment tagged [AttachmentTagPrefix]sha256-[TargetImageDigest].[AttachmentName], default none")
 
 	cmd.Flags().BoolVarP(&o.SkipConfirmation, "yes", "y", false,
 		"skip confirmation prompts for non-destructive operations")
@@ -130,4 +155,17 @@ func (o *SignOptions) AddFlags(cmd *cobra.Command) {
 
 	cmd.Flags().StringVar(&o.SignContainerIdentity, "sign-container-identity", "",
 		"manually set the .critical.docker-reference field for the signed identity, which is useful when image proxies are being used where the pull reference should match the signature")
+
+	cmd.Flags().StringVar(&o.AnnotationsFile, "annotations-file", "",
+		"path to a YAML or JSON file of key: value annotations to sign, along with --annotations which override them")
+	_ = cmd.Flags().SetAnnotation("annotations-file", cobra.BashCompFilenameExt, []string{"yaml", "yml", "json"})
+
+	cmd.Flags().BoolVar(&o.AutoAnnotate, "auto-annotate", false,
+		"sign the git commit, CI run and builder identity of the build running on GitHub Actions, GitLab CI or Tekton as the "+
+			"dev.sigstore.cosign/git-commit, dev.sigstore.cosign/ci-run and dev.sigstore.cosign/builder-id annotations, "+
+			"overridden by --annotations-file and --annotations")
+
+	cmd.Flags().IntVar(&o.MaxWorkers, "max-workers", cosign.DefaultMaxWorkers,
+		"the amount of maximum workers for parallel executions, e.g. signing several images and uploading their transparency log entries at once. "+
+			"Images are signed one at a time with a security key or a PKCS11 token")
 }
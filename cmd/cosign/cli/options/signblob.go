@@ -27,6 +27,7 @@ type SignBlobOptions struct {
 	Output               string // deprecated: TODO remove when the output flag is fully deprecated
 	OutputSignature      string // TODO: this should be the root output file arg.
 	OutputCertificate    string
+	OutputKey            string
 	SecurityKey          SecurityKeyOptions
 	Fulcio               FulcioOptions
 	Rekor                RekorOptions
@@ -71,6 +72,10 @@ func (o *SignBlobOptions) AddFlags(cmd *cobra.Command) {
 		"write the certificate to FILE")
 	_ = cmd.Flags().SetAnnotation("key", cobra.BashCompFilenameExt, []string{})
 
+	cmd.Flags().StringVar(&o.OutputKey, "output-key", "",
+		"write the ephemeral private key of keyless signing to FILE, encrypted with the key password, to sign with it and its certificate again with --key and --certificate until the certificate expires")
+	_ = cmd.Flags().SetAnnotation("output-key", cobra.BashCompFilenameExt, []string{})
+
 	cmd.Flags().StringVar(&o.BundlePath, "bundle", "",
 		"write everything required to verify the blob to a FILE")
 	_ = cmd.Flags().SetAnnotation("bundle", cobra.BashCompFilenameExt, []string{})
@@ -0,0 +1,156 @@
+//
+// Copyright 2023 The Sigstore Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package options
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Endpoints whose HTTP transport can be configured with --http-proxy,
+// --cacert and --insecure-skip-tls-verify.
+const (
+	EndpointRegistry = "registry"
+	EndpointFulcio   = "fulcio"
+	EndpointRekor    = "rekor"
+)
+
+var endpoints = []string{EndpointRegistry, EndpointFulcio, EndpointRekor}
+
+// transports holds the transports configured by ConfigureTransports, keyed
+// by endpoint. Endpoints without an entry use the client library defaults.
+var transports map[string]*http.Transport
+
+// transportWrappers wrap the transport of every endpoint, in the order
+// they were added by WrapTransports.
+var transportWrappers []func(endpoint string, rt http.RoundTripper) http.RoundTripper
+
+// WrapTransports makes the clients created afterwards send their requests
+// through the transport returned by wrap, e.g. to measure their latency.
+// Each call adds a wrapper around those of the previous calls.
+func WrapTransports(wrap func(endpoint string, rt http.RoundTripper) http.RoundTripper) {
+	transportWrappers = append(transportWrappers, wrap)
+}
+
+// TransportsWrapped reports whether WrapTransports was called.
+func TransportsWrapped() bool {
+	return len(transportWrappers) > 0
+}
+
+// WrapTransport returns rt wrapped for endpoint by WrapTransports.
+func WrapTransport(endpoint string, rt http.RoundTripper) http.RoundTripper {
+	for _, wrap := range transportWrappers {
+		rt = wrap(endpoint, rt)
+	}
+	return rt
+}
+
+// Transport returns a copy of the HTTP transport configured for endpoint,
+// or nil when the flags leave the endpoint at its defaults.
+func Transport(endpoint string) *http.Transport {
+	if t, ok := transports[endpoint]; ok {
+		return t.Clone()
+	}
+	return nil
+}
+
+// ConfigureTransports builds the per-endpoint transports from the proxy and
+// TLS flags of o. Flag values may be prefixed with "ENDPOINT=" to apply to a
+// single endpoint; otherwise they apply to all of them.
+func ConfigureTransports(o *RootOptions) error {
+	cfg := map[string]*http.Transport{}
+	transport := func(endpoint string) *http.Transport {
+		t, ok := cfg[endpoint]
+		if !ok {
+			t = http.DefaultTransport.(*http.Transport).Clone()
+			t.TLSClientConfig = &tls.Config{MinVersion: tls.VersionTLS12}
+			cfg[endpoint] = t
+		}
+		return t
+	}
+
+	for _, v := range o.HTTPProxies {
+		scope, value := splitEndpoint(v)
+		proxy, err := url.Parse(value)
+		if err != nil {
+			return fmt.Errorf("parsing --http-proxy: %w", err)
+		}
+		for _, e := range scope {
+			transport(e).Proxy = http.ProxyURL(proxy)
+		}
+	}
+
+	for _, v := range o.CACerts {
+		scope, path := splitEndpoint(v)
+		pem, err := os.ReadFile(filepath.Clean(path))
+		if err != nil {
+			return fmt.Errorf("reading --cacert: %w", err)
+		}
+		for _, e := range scope {
+			t := transport(e)
+			if t.TLSClientConfig.RootCAs == nil {
+				pool, err := x509.SystemCertPool()
+				if err != nil {
+					pool = x509.NewCertPool()
+				}
+				t.TLSClientConfig.RootCAs = pool
+			}
+			if !t.TLSClientConfig.RootCAs.AppendCertsFromPEM(pem) {
+				return fmt.Errorf("no PEM-encoded certificates found in --cacert %s", path)
+			}
+		}
+	}
+
+	for _, v := range o.InsecureSkipTLSVerify {
+		scope := endpoints
+		if v != "all" {
+			if !isEndpoint(v) {
+				return fmt.Errorf("--insecure-skip-tls-verify: unknown endpoint %q, must be one of all, %s", v, strings.Join(endpoints, ", "))
+			}
+			scope = []string{v}
+		}
+		for _, e := range scope {
+			transport(e).TLSClientConfig.InsecureSkipVerify = true // #nosec G402
+		}
+	}
+
+	transports = cfg
+	return nil
+}
+
+// splitEndpoint splits a flag value of the form "ENDPOINT=VALUE", returning
+// the endpoints it applies to and the value.
+func splitEndpoint(v string) ([]string, string) {
+	if e, value, ok := strings.Cut(v, "="); ok && isEndpoint(e) {
+		return []string{e}, value
+	}
+	return endpoints, v
+}
+
+func isEndpoint(e string) bool {
+	for _, endpoint := range endpoints {
+		if e == endpoint {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,138 @@
+//
+// Copyright 2023 The Sigstore Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package options
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestConfigureTransports(t *testing.T) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tmpl := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "test CA"},
+		NotBefore:             time.Now(),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	caFile := filepath.Join(t.TempDir(), "ca.pem")
+	if err := os.WriteFile(caFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	notPEM := filepath.Join(t.TempDir(), "ca.txt")
+	if err := os.WriteFile(notPEM, []byte("not a certificate"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	ca, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name string
+		opts RootOptions
+		// want maps the endpoints expected to be configured to a check of
+		// their transport.
+		want    map[string]func(*http.Transport) bool
+		wantErr bool
+	}{{
+		name: "no flags",
+		want: map[string]func(*http.Transport) bool{},
+	}, {
+		name: "proxy for all endpoints",
+		opts: RootOptions{HTTPProxies: []string{"http://proxy.example.com:3128"}},
+		want: map[string]func(*http.Transport) bool{
+			EndpointRegistry: hasProxy("http://proxy.example.com:3128"),
+			EndpointFulcio:   hasProxy("http://proxy.example.com:3128"),
+			EndpointRekor:    hasProxy("http://proxy.example.com:3128"),
+		},
+	}, {
+		name: "scoped CA bundle",
+		opts: RootOptions{CACerts: []string{"registry=" + caFile}},
+		want: map[string]func(*http.Transport) bool{
+			EndpointRegistry: func(t *http.Transport) bool {
+				_, err := ca.Verify(x509.VerifyOptions{Roots: t.TLSClientConfig.RootCAs})
+				return err == nil
+			},
+		},
+	}, {
+		name: "insecure rekor",
+		opts: RootOptions{InsecureSkipTLSVerify: []string{"rekor"}},
+		want: map[string]func(*http.Transport) bool{
+			EndpointRekor: func(t *http.Transport) bool { return t.TLSClientConfig.InsecureSkipVerify },
+		},
+	}, {
+		name:    "unknown endpoint",
+		opts:    RootOptions{InsecureSkipTLSVerify: []string{"tuf"}},
+		wantErr: true,
+	}, {
+		name:    "CA bundle without certificates",
+		opts:    RootOptions{CACerts: []string{notPEM}},
+		wantErr: true,
+	}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			defer func() { transports = nil }()
+			err := ConfigureTransports(&tt.opts)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ConfigureTransports() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			for _, e := range endpoints {
+				check, ok := tt.want[e]
+				got := Transport(e)
+				if !ok {
+					if got != nil {
+						t.Errorf("Transport(%s) configured, want default", e)
+					}
+					continue
+				}
+				if got == nil || !check(got) {
+					t.Errorf("Transport(%s) not configured as expected", e)
+				}
+			}
+		})
+	}
+}
+
+func hasProxy(want string) func(*http.Transport) bool {
+	return func(t *http.Transport) bool {
+		req, _ := http.NewRequest(http.MethodGet, "https://example.com", nil)
+		u, err := t.Proxy(req)
+		return err == nil && u != nil && u.String() == want
+	}
+}
@@ -19,10 +19,14 @@ import "github.com/spf13/cobra"
 type TreeOptions struct {
 	Registry  RegistryOptions
 	CleanType string
+	Output    string
 }
 
 var _ Interface = (*TreeOptions)(nil)
 
 func (c *TreeOptions) AddFlags(cmd *cobra.Command) {
 	c.Registry.AddFlags(cmd)
+
+	cmd.Flags().StringVarP(&c.Output, "output", "o", "text",
+		"output format for the artifacts (json|text)")
 }
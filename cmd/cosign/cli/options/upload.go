@@ -43,6 +43,7 @@ func (o *UploadBlobOptions) AddFlags(cmd *cobra.Command) {
 // UploadWASMOptions is the top level wrapper for the `upload wasm` command.
 type UploadWASMOptions struct {
 	File     string
+	Attach   bool
 	Registry RegistryOptions
 }
 
@@ -56,4 +57,7 @@ func (o *UploadWASMOptions) AddFlags(cmd *cobra.Command) {
 		"path to the wasm file to upload")
 	_ = cmd.Flags().SetAnnotation("file", cobra.BashCompFilenameExt, []string{})
 	_ = cmd.MarkFlagRequired("file")
+
+	cmd.Flags().BoolVar(&o.Attach, "attach", false,
+		"upload the module as the wasm attachment of the image, tagged [AttachmentTagPrefix]sha256-[ImageDigest].wasm, to sign and verify it with --attachment wasm")
 }
@@ -16,9 +16,24 @@
 package options
 
 import (
+	"fmt"
+	"strings"
+	"time"
+
 	"github.com/spf13/cobra"
 
 	"github.com/sigstore/cosign/v2/internal/pkg/cosign"
+	"github.com/sigstore/cosign/v2/pkg/cosign/rego"
+)
+
+const (
+	// PolicyEngineAuto selects the policy engine from the policy file extension.
+	PolicyEngineAuto = "auto"
+	PolicyEngineCUE  = "cue"
+	PolicyEngineRego = "rego"
+	// PolicyEngineSLSA names the built-in checks of the --slsa-* flags in
+	// policy results. It can't be selected with --policy-engine.
+	PolicyEngineSLSA = "slsa"
 )
 
 type CommonVerifyOptions struct {
@@ -26,6 +41,11 @@ type CommonVerifyOptions struct {
 	TSACertChainPath string
 	IgnoreTlog       bool
 	MaxWorkers       int
+
+	RequireOnlineTlog bool
+	RekorCheckpoint   string
+	RekorPublicKey    string
+	MaxSignatureAge   time.Duration
 }
 
 func (o *CommonVerifyOptions) AddFlags(cmd *cobra.Command) {
@@ -40,26 +60,53 @@ func (o *CommonVerifyOptions) AddFlags(cmd *cobra.Command) {
 		"ignore transparency log verification, to be used when an artifact signature has not been uploaded to the transparency log. Artifacts "+
 			"cannot be publicly verified when not included in a log")
 
+	cmd.Flags().BoolVar(&o.RequireOnlineTlog, "require-online-tlog", false,
+		"always look signatures up in the transparency log and verify their inclusion, even when their bundle "+
+			"verifies offline against the Rekor public key")
+
+	cmd.Flags().StringVar(&o.RekorCheckpoint, "rekor-checkpoint", "",
+		"path to a signed Rekor checkpoint FILE, e.g. from 'rekor-cli loginfo', pinning a log state that transparency log entries "+
+			"must be consistent with. Entries are looked up online and their inclusion proven against the pinned checkpoint")
+	_ = cmd.Flags().SetAnnotation("rekor-checkpoint", cobra.BashCompFilenameExt, []string{})
+
+	cmd.Flags().StringVar(&o.RekorPublicKey, "rekor-public-key", "",
+		"path or URL of the PEM-encoded public key of the transparency log, e.g. of a private Rekor instance. May hold several keys. "+
+			"Overrides the keys from the TUF root and SIGSTORE_REKOR_PUBLIC_KEY")
+
+	cmd.Flags().DurationVar(&o.MaxSignatureAge, "max-signature-age", 0,
+		"reject signatures whose RFC3161 timestamp or transparency log entry is older than this, e.g. 168h to require signing weekly. "+
+			"0 disables the check")
+
 	cmd.Flags().IntVar(&o.MaxWorkers, "max-workers", cosign.DefaultMaxWorkers,
-		"the amount of maximum workers for parallel executions")
+		"the amount of maximum workers for parallel executions, e.g. verifying several images at once")
 }
 
 // VerifyOptions is the top level wrapper for the `verify` command.
 type VerifyOptions struct {
-	Key          string
-	CheckClaims  bool
-	Attachment   string
-	Output       string
-	SignatureRef string
-	PayloadRef   string
-	LocalImage   bool
+	Key             string
+	CheckClaims     bool
+	Attachment      string
+	Output          string
+	SignatureRef    string
+	PayloadRef      string
+	LocalImage      bool
+	ContinueOnError bool
+	Recursive       bool
+	Platform        string
+	OutputDigest    string
+	ThresholdPolicy string
+	TrustPolicy     string
 
 	CommonVerifyOptions CommonVerifyOptions
 	SecurityKey         SecurityKeyOptions
+	PIVAttestation      PIVAttestationOptions
 	CertVerify          CertVerifyOptions
 	Rekor               RekorOptions
 	Registry            RegistryOptions
 	SignatureDigest     SignatureDigestOptions
+	Cache               VerificationCacheOptions
+	Lockfile            VerifyLockfileOptions
+	Revocation          RevocationOptions
 
 	AnnotationOptions
 }
@@ -69,25 +116,29 @@ var _ Interface = (*VerifyOptions)(nil)
 // AddFlags implements Interface
 func (o *VerifyOptions) AddFlags(cmd *cobra.Command) {
 	o.SecurityKey.AddFlags(cmd)
+	o.PIVAttestation.AddFlags(cmd)
 	o.Rekor.AddFlags(cmd)
 	o.CertVerify.AddFlags(cmd)
 	o.Registry.AddFlags(cmd)
 	o.SignatureDigest.AddFlags(cmd)
 	o.AnnotationOptions.AddFlags(cmd)
 	o.CommonVerifyOptions.AddFlags(cmd)
+	o.Cache.AddFlags(cmd)
+	o.Lockfile.AddFlags(cmd)
+	o.Revocation.AddFlags(cmd)
 
 	cmd.Flags().StringVar(&o.Key, "key", "",
-		"path to the public key file, KMS URI or Kubernetes Secret")
+		"path to the public key file, KMS URI or Kubernetes Secret. A file holding several PEM public keys, or a directory of .pub and .pem key files, trusts any of the keys")
 	_ = cmd.Flags().SetAnnotation("key", cobra.BashCompFilenameExt, []string{})
 
 	cmd.Flags().BoolVar(&o.CheckClaims, "check-claims", true,
 		"whether to check the claims found")
 
 	cmd.Flags().StringVar(&o.Attachment, "attachment", "",
-		"DEPRECATED, related image attachment to verify (sbom), default none")
+		"related image attachment to verify, e.g. sbom (DEPRECATED), wasm (which must be a WASM module) or the name of any attachment tagged [AttachmentTagPrefix]sha256-[TargetImageDigest].[AttachmentName], default none")
 
-	cmd.Flags().StringVarP(&o.Output, "output", "o", "json",
-		"output format for the signing image information (json|text)")
+	cmd.Flags().StringVarP(&o.Output, "output", "o", "",
+		"output format for the verification results (json|yaml|text). json and yaml write a list of VerificationResult documents; by default, the signature payloads are printed as JSON")
 
 	cmd.Flags().StringVar(&o.SignatureRef, "signature", "",
 		"signature content or path or remote URL")
@@ -96,23 +147,67 @@ func (o *VerifyOptions) AddFlags(cmd *cobra.Command) {
 		"payload path or remote URL")
 
 	cmd.Flags().BoolVar(&o.LocalImage, "local-image", false,
-		"whether the specified image is a path to an image saved locally via 'cosign save'")
+		"whether the specified image is a path to an image saved locally via 'cosign save' or another OCI layout, e.g. from 'crane pull --format oci'")
+
+	cmd.Flags().BoolVar(&o.ContinueOnError, "continue-on-error", false,
+		"keep verifying the remaining images when one fails, print a summary of every image and fail at the end")
+
+	cmd.Flags().BoolVarP(&o.Recursive, "recursive", "r", false,
+		"if the image is a multi-arch image index, also verify each of its platform manifests, reporting the result of each platform")
+
+	cmd.Flags().StringVar(&o.Platform, "platform", "",
+		"only verify the image of this platform, e.g. linux/arm64, when the image is a multi-arch image index")
+
+	cmd.Flags().StringVar(&o.OutputDigest, "output-digest", "",
+		"write the IMAGE@sha256:DIGEST reference of each verified image to this FILE, one per line, so that the verified digest can be pinned. Use - for stdout")
+	_ = cmd.Flags().SetAnnotation("output-digest", cobra.BashCompFilenameExt, []string{})
+
+	cmd.Flags().StringVar(&o.ThresholdPolicy, "threshold-policy", "",
+		"path to a YAML or JSON policy FILE listing trusted keys and identities, of which at least a threshold number must have signed")
+	_ = cmd.Flags().SetAnnotation("threshold-policy", cobra.BashCompFilenameExt, []string{"yaml", "yml", "json"})
+
+	cmd.Flags().StringVar(&o.TrustPolicy, "trust-policy", "",
+		"registry NAMESPACE whose root-of-trust policy, managed with 'cosign policy', lists the trusted maintainer keys")
 }
 
 // VerifyAttestationOptions is the top level wrapper for the `verify attestation` command.
 type VerifyAttestationOptions struct {
-	Key         string
-	CheckClaims bool
-	Output      string
+	Key                  string
+	CheckClaims          bool
+	CheckPredicateExpiry bool
+	Output               string
 
 	CommonVerifyOptions CommonVerifyOptions
 	SecurityKey         SecurityKeyOptions
+	PIVAttestation      PIVAttestationOptions
 	Rekor               RekorOptions
 	CertVerify          CertVerifyOptions
 	Registry            RegistryOptions
 	Predicate           PredicateRemoteOptions
+	Revocation          RevocationOptions
 	Policies            []string
+	PolicyEngine        string
+	RegoQuery           string
+	PolicyData          []string
+	PolicyKey           string
+	AllowMissing        bool
 	LocalImage          bool
+	BundlePath          string
+	ContinueOnError     bool
+	Recursive           bool
+	Platform            string
+	OutputDigest        string
+	ThresholdPolicy     string
+	TrustPolicy         string
+	MaxAttestationSize  int64
+	Layout              string
+	LayoutKeys          []string
+	InspectionDir       string
+	SLSABuilderID       string
+	SLSASourceURI       string
+	SLSAMinLevel        int
+
+	AnnotationOptions
 }
 
 var _ Interface = (*VerifyAttestationOptions)(nil)
@@ -120,26 +215,96 @@ var _ Interface = (*VerifyAttestationOptions)(nil)
 // AddFlags implements Interface
 func (o *VerifyAttestationOptions) AddFlags(cmd *cobra.Command) {
 	o.SecurityKey.AddFlags(cmd)
+	o.PIVAttestation.AddFlags(cmd)
 	o.Rekor.AddFlags(cmd)
 	o.CertVerify.AddFlags(cmd)
 	o.Registry.AddFlags(cmd)
 	o.Predicate.AddFlags(cmd)
 	o.CommonVerifyOptions.AddFlags(cmd)
+	o.AnnotationOptions.AddFlags(cmd)
+	o.Revocation.AddFlags(cmd)
 
 	cmd.Flags().StringVar(&o.Key, "key", "",
-		"path to the public key file, KMS URI or Kubernetes Secret")
+		"path to the public key file, KMS URI or Kubernetes Secret. A file holding several PEM public keys, or a directory of .pub and .pem key files, trusts any of the keys")
 
 	cmd.Flags().BoolVar(&o.CheckClaims, "check-claims", true,
 		"whether to check the claims found")
 
 	cmd.Flags().StringSliceVar(&o.Policies, "policy", nil,
-		"specify CUE or Rego files will be using for validation")
+		"specify CUE or Rego files or OPA bundles will be using for validation, or an oci://<registry>/<repository>:<tag> artifact holding signed policy files, prefix with <predicate type>= to only apply a policy to that predicate type; CUE policies for SLSA provenance, SPDX JSON and link attestations are checked against the predicate schema first")
+
+	cmd.Flags().StringVar(&o.PolicyEngine, "policy-engine", PolicyEngineAuto,
+		"policy engine used to evaluate the --policy files (auto|cue|rego), auto selects the engine based on the file extension, .tar.gz files being OPA bundles")
+
+	cmd.Flags().StringVar(&o.RegoQuery, "rego-query", rego.QUERY,
+		"Rego query evaluated against the attestation when validating with Rego policies, e.g. data.cosign.attestation.allow")
+
+	cmd.Flags().StringSliceVar(&o.PolicyData, "policy-data", nil,
+		"JSON or YAML data documents, or directories of them, loaded with the Rego policies, e.g. a list of allowed registries. A file is merged at the root of the data document, the files of a directory under their relative directory")
+
+	cmd.Flags().StringVar(&o.PolicyKey, "policy-key", "",
+		"path to the public key file or KMS URI the oci:// policy bundles must be signed with. The .cue and .rego layers of a bundle, named by their org.opencontainers.image.title annotation, are evaluated as policies")
 
-	cmd.Flags().StringVarP(&o.Output, "output", "o", "json",
-		"output format for the signing image information (json|text)")
+	cmd.Flags().BoolVar(&o.AllowMissing, "allow-missing-attestations", false,
+		"do not fail when no attestation matches one of the requested predicate types")
+
+	cmd.Flags().StringVarP(&o.Output, "output", "o", "",
+		"output format for the verification results (json|yaml|sarif|text). json and yaml write a list of VerificationResult documents, including the policy evaluation results; sarif writes the policy violations as a SARIF log for code scanning; by default, the attestation payloads are printed as JSON")
 
 	cmd.Flags().BoolVar(&o.LocalImage, "local-image", false,
-		"whether the specified image is a path to an image saved locally via 'cosign save'")
+		"whether the specified image is a path to an image saved locally via 'cosign save' or another OCI layout, e.g. from 'crane pull --format oci'")
+
+	cmd.Flags().StringVar(&o.BundlePath, "bundle", "",
+		"path to a bundle FILE written by 'cosign attest --bundle', the attestation is verified offline against the image digest")
+
+	cmd.Flags().BoolVar(&o.ContinueOnError, "continue-on-error", false,
+		"keep verifying the remaining images when one fails, print a summary of every image and fail at the end")
+
+	cmd.Flags().BoolVarP(&o.Recursive, "recursive", "r", false,
+		"if the image is a multi-arch image index, also verify each of its platform manifests, reporting the result of each platform")
+
+	cmd.Flags().StringVar(&o.Platform, "platform", "",
+		"only verify the image of this platform, e.g. linux/arm64, when the image is a multi-arch image index")
+
+	cmd.Flags().StringVar(&o.OutputDigest, "output-digest", "",
+		"write the IMAGE@sha256:DIGEST reference of each verified image to this FILE, one per line, so that the verified digest can be pinned. Use - for stdout")
+	_ = cmd.Flags().SetAnnotation("output-digest", cobra.BashCompFilenameExt, []string{})
+
+	cmd.Flags().StringVar(&o.ThresholdPolicy, "threshold-policy", "",
+		"path to a YAML or JSON policy FILE listing trusted keys and identities, of which at least a threshold number must have signed")
+	_ = cmd.Flags().SetAnnotation("threshold-policy", cobra.BashCompFilenameExt, []string{"yaml", "yml", "json"})
+
+	cmd.Flags().StringVar(&o.TrustPolicy, "trust-policy", "",
+		"registry NAMESPACE whose root-of-trust policy, managed with 'cosign policy', lists the trusted maintainer keys")
+
+	cmd.Flags().BoolVar(&o.CheckPredicateExpiry, "check-predicate-expiry", false,
+		"reject attestations whose predicate has an \"expires\" RFC3339 time in the past")
+
+	cmd.Flags().Int64Var(&o.MaxAttestationSize, "max-attestation-size", cosign.DefaultMaxAttestationSize,
+		"reject attestations larger than this many bytes without downloading them, 0 disables the limit")
+
+	cmd.Flags().StringVar(&o.Layout, "layout", "",
+		"path to a signed in-toto layout FILE. The link attestations of the image are verified against the steps, inspections and key thresholds of the layout, "+
+			"each signed by a functionary key of its step, instead of against --key or --certificate")
+	_ = cmd.Flags().SetAnnotation("layout", cobra.BashCompFilenameExt, []string{"json", "layout"})
+
+	cmd.Flags().StringSliceVar(&o.LayoutKeys, "layout-key", nil,
+		"path to an in-toto public key FILE the --layout must be signed with, may be repeated")
+
+	cmd.Flags().StringVar(&o.InspectionDir, "layout-inspection-dir", "",
+		"directory the inspections of the --layout are run in, defaults to the current directory")
+
+	cmd.Flags().StringVar(&o.SLSABuilderID, "slsa-builder-id", "",
+		"builder.id the SLSA provenance attestations must name, e.g. https://github.com/slsa-framework/slsa-github-generator/.github/workflows/generator_generic_slsa3.yml, "+
+			"which also matches the ID followed by @ and a version")
+
+	cmd.Flags().StringVar(&o.SLSASourceURI, "slsa-source-uri", "",
+		"repository the SLSA provenance attestations must show the build ran from, e.g. git+https://github.com/sigstore/cosign, "+
+			"which also matches the URI followed by @ and a ref")
+
+	cmd.Flags().IntVar(&o.SLSAMinLevel, "slsa-min-level", 0,
+		"lowest SLSA build level the provenance attestations must show: 1 names the builder and build type, 2 also pins the source to a digest, "+
+			"3 also names the --slsa-builder-id, trusted to be hardened")
 }
 
 // VerifyBlobOptions is the top level wrapper for the `verify blob` command.
@@ -154,6 +319,7 @@ type VerifyBlobOptions struct {
 	CommonVerifyOptions CommonVerifyOptions
 
 	RFC3161TimestampPath string
+	Output               string
 }
 
 var _ Interface = (*VerifyBlobOptions)(nil)
@@ -166,22 +332,28 @@ func (o *VerifyBlobOptions) AddFlags(cmd *cobra.Command) {
 	o.CommonVerifyOptions.AddFlags(cmd)
 
 	cmd.Flags().StringVar(&o.Key, "key", "",
-		"path to the public key file, KMS URI or Kubernetes Secret")
+		"path to the public key file, KMS URI or Kubernetes Secret. A file holding several PEM public keys, or a directory of .pub and .pem key files, trusts any of the keys")
 
 	cmd.Flags().StringVar(&o.Signature, "signature", "",
 		"signature content or path or remote URL")
 
 	cmd.Flags().StringVar(&o.BundlePath, "bundle", "",
-		"path to bundle FILE")
+		"path to a bundle FILE written by 'cosign sign-blob --bundle', holding the signature, certificate chain, transparency log entry and digest of the blob to verify offline")
 
 	cmd.Flags().StringVar(&o.RFC3161TimestampPath, "rfc3161-timestamp", "",
 		"path to RFC3161 timestamp FILE")
+
+	cmd.Flags().StringVarP(&o.Output, "output", "o", "text",
+		"output format for the verification results (json|yaml|text). json and yaml write a list of VerificationResult documents")
 }
 
 // VerifyDockerfileOptions is the top level wrapper for the `dockerfile verify` command.
 type VerifyDockerfileOptions struct {
 	VerifyOptions
-	BaseImageOnly bool
+	BaseImageOnly    bool
+	BuildArgs        []string
+	AttestationTypes []string
+	Policies         []string
 }
 
 var _ Interface = (*VerifyDockerfileOptions)(nil)
@@ -191,7 +363,96 @@ func (o *VerifyDockerfileOptions) AddFlags(cmd *cobra.Command) {
 	o.VerifyOptions.AddFlags(cmd)
 
 	cmd.Flags().BoolVar(&o.BaseImageOnly, "base-image-only", false,
-		"only verify the base image (the last FROM image in the Dockerfile)")
+		"only verify the base image (the image the last stage of the Dockerfile is built from)")
+
+	cmd.Flags().StringArrayVar(&o.BuildArgs, "build-arg", nil,
+		"KEY=VALUE overriding the default value of an ARG declared before the first FROM, as docker build --build-arg")
+
+	cmd.Flags().StringSliceVar(&o.AttestationTypes, "attestation-type", nil,
+		"also verify the attestations of these predicate types of the images, e.g. slsaprovenance, after their signatures")
+
+	cmd.Flags().StringSliceVar(&o.Policies, "policy", nil,
+		"CUE or Rego files the attestations verified with --attestation-type must pass")
+}
+
+// BuildArgsMap returns the values of the --build-arg flags, by ARG name.
+func (o *VerifyDockerfileOptions) BuildArgsMap() (map[string]string, error) {
+	buildArgs := map[string]string{}
+	for _, arg := range o.BuildArgs {
+		name, value, ok := strings.Cut(arg, "=")
+		if !ok || name == "" {
+			return nil, fmt.Errorf("invalid --build-arg %q, expected KEY=VALUE", arg)
+		}
+		buildArgs[name] = value
+	}
+	return buildArgs, nil
+}
+
+// VerifyManifestOptions is the top level wrapper for the `manifest verify` command.
+type VerifyManifestOptions struct {
+	VerifyOptions
+	Values           []string
+	AttestationTypes []string
+	Policies         []string
+}
+
+var _ Interface = (*VerifyManifestOptions)(nil)
+
+// AddFlags implements Interface
+func (o *VerifyManifestOptions) AddFlags(cmd *cobra.Command) {
+	o.VerifyOptions.AddFlags(cmd)
+
+	cmd.Flags().StringSliceVar(&o.Values, "values", nil,
+		"Helm values files merged with the values of the chart, as by helm --values")
+	_ = cmd.Flags().SetAnnotation("values", cobra.BashCompFilenameExt, []string{"yaml", "yml"})
+
+	cmd.Flags().StringSliceVar(&o.AttestationTypes, "attestation-type", nil,
+		"also verify the attestations of these predicate types of the images, e.g. slsaprovenance, after their signatures")
+
+	cmd.Flags().StringSliceVar(&o.Policies, "policy", nil,
+		"CUE or Rego files the attestations verified with --attestation-type must pass")
+}
+
+// VerifyTreeOptions is the top level wrapper for the `verify-tree` command.
+type VerifyTreeOptions struct {
+	Policy        string
+	DenyUnmatched bool
+	IgnoreTlog    bool
+	MaxWorkers    int
+	Summary       string
+	SummaryFormat string
+
+	Rekor    RekorOptions
+	Registry RegistryOptions
+}
+
+var _ Interface = (*VerifyTreeOptions)(nil)
+
+// AddFlags implements Interface
+func (o *VerifyTreeOptions) AddFlags(cmd *cobra.Command) {
+	o.Rekor.AddFlags(cmd)
+	o.Registry.AddFlags(cmd)
+
+	cmd.Flags().StringVar(&o.Policy, "policy", "",
+		"path to a YAML FILE of ClusterImagePolicy documents, as enforced by cosign-webhook, the images must satisfy")
+	_ = cmd.Flags().SetAnnotation("policy", cobra.BashCompFilenameExt, []string{"yaml", "yml"})
+	_ = cmd.MarkFlagRequired("policy")
+
+	cmd.Flags().BoolVar(&o.DenyUnmatched, "deny-unmatched", false,
+		"fail the images no policy matches, which otherwise pass")
+
+	cmd.Flags().BoolVar(&o.IgnoreTlog, "insecure-ignore-tlog", false,
+		"ignore transparency log verification, to be used when an artifact signature has not been uploaded to the transparency log. Artifacts "+
+			"cannot be publicly verified when not included in a log")
+
+	cmd.Flags().IntVar(&o.MaxWorkers, "max-workers", cosign.DefaultMaxWorkers,
+		"the amount of maximum workers for parallel executions, e.g. verifying several images at once")
+
+	cmd.Flags().StringVar(&o.Summary, "summary", "",
+		"write the summary of the verification of every image to this FILE, e.g. $GITHUB_STEP_SUMMARY")
+
+	cmd.Flags().StringVar(&o.SummaryFormat, "summary-format", "markdown",
+		"format of the --summary file (markdown|json)")
 }
 
 // VerifyBlobAttestationOptions is the top level wrapper for the `verify-blob-attestation` command.
@@ -201,7 +462,12 @@ type VerifyBlobAttestationOptions struct {
 	BundlePath    string
 
 	PredicateOptions
-	CheckClaims bool
+	CheckClaims          bool
+	CheckPredicateExpiry bool
+	Policies             []string
+	PolicyEngine         string
+	RegoQuery            string
+	PolicyData           []string
 
 	SecurityKey         SecurityKeyOptions
 	CertVerify          CertVerifyOptions
@@ -209,6 +475,7 @@ type VerifyBlobAttestationOptions struct {
 	CommonVerifyOptions CommonVerifyOptions
 
 	RFC3161TimestampPath string
+	Output               string
 }
 
 var _ Interface = (*VerifyBlobOptions)(nil)
@@ -222,7 +489,7 @@ func (o *VerifyBlobAttestationOptions) AddFlags(cmd *cobra.Command) {
 	o.CommonVerifyOptions.AddFlags(cmd)
 
 	cmd.Flags().StringVar(&o.Key, "key", "",
-		"path to the public key file, KMS URI or Kubernetes Secret")
+		"path to the public key file, KMS URI or Kubernetes Secret. A file holding several PEM public keys, or a directory of .pub and .pem key files, trusts any of the keys")
 
 	cmd.Flags().StringVar(&o.SignaturePath, "signature", "",
 		"path to base64-encoded signature over attestation in DSSE format")
@@ -235,4 +502,22 @@ func (o *VerifyBlobAttestationOptions) AddFlags(cmd *cobra.Command) {
 
 	cmd.Flags().StringVar(&o.RFC3161TimestampPath, "rfc3161-timestamp", "",
 		"path to RFC3161 timestamp FILE")
+
+	cmd.Flags().StringSliceVar(&o.Policies, "policy", nil,
+		"specify CUE or Rego files or OPA bundles the attestation is validated against, prefix with <predicate type>= to only apply a policy to that predicate type; CUE policies for SLSA provenance, SPDX JSON and link attestations are checked against the predicate schema first")
+
+	cmd.Flags().StringVar(&o.PolicyEngine, "policy-engine", PolicyEngineAuto,
+		"policy engine used to evaluate the --policy files (auto|cue|rego), auto selects the engine based on the file extension, .tar.gz files being OPA bundles")
+
+	cmd.Flags().StringVar(&o.RegoQuery, "rego-query", rego.QUERY,
+		"Rego query evaluated against the attestation when validating with Rego policies, e.g. data.cosign.attestation.allow")
+
+	cmd.Flags().StringSliceVar(&o.PolicyData, "policy-data", nil,
+		"JSON or YAML data documents, or directories of them, loaded with the Rego policies, e.g. a list of allowed registries. A file is merged at the root of the data document, the files of a directory under their relative directory")
+
+	cmd.Flags().BoolVar(&o.CheckPredicateExpiry, "check-predicate-expiry", false,
+		"reject attestations whose predicate has an \"expires\" RFC3339 time in the past")
+
+	cmd.Flags().StringVarP(&o.Output, "output", "o", "text",
+		"output format for the verification results (json|yaml|text). json and yaml write a list of VerificationResult documents, including the policy evaluation results")
 }
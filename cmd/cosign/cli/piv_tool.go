@@ -19,6 +19,7 @@ package cli
 
 import (
 	"encoding/json"
+	"fmt"
 
 	"github.com/spf13/cobra"
 
@@ -26,8 +27,6 @@ import (
 	"github.com/sigstore/cosign/v2/cmd/cosign/cli/pivcli"
 )
 
-var pivToolForce bool
-
 func PIVTool() *cobra.Command {
 	cmd := &cobra.Command{
 		Use:   "piv-tool",
@@ -45,8 +44,7 @@ func PIVTool() *cobra.Command {
 	)
 
 	// TODO: drop -f in favor of --no-input only
-	// TODO: use the force flag.
-	cmd.PersistentFlags().BoolVarP(&pivToolForce, "no-input", "f", false,
+	cmd.PersistentFlags().BoolVarP(&pivcli.SkipConfirmation, "no-input", "f", false,
 		"skip warnings and confirmations")
 
 	return cmd
@@ -127,13 +125,23 @@ func pivToolAttestation() *cobra.Command {
 
 	cmd := &cobra.Command{
 		Use:   "attestation",
-		Short: "attestation contains commands to manage a hardware token",
-		Args:  cobra.ExactArgs(0),
+		Short: "attestation prints the device and key attestation certificates of a hardware token",
+		Long: `Prints and verifies the attestation certificates of the key in a slot of the hardware token: the device
+certificate, signed by the manufacturer, and the key certificate, signed by the device, which prove the key
+was generated on the token and can't be exported.`,
+		Example: `  cosign piv-tool attestation --slot signature [--output json] [--output-device-certificate <file>] [--output-key-certificate <file>]`,
+		Args:    cobra.ExactArgs(0),
 		RunE: func(cmd *cobra.Command, args []string) error {
+			if o.Output != "text" && o.Output != "json" {
+				return fmt.Errorf("invalid --output %q, expected text or json", o.Output)
+			}
 			a, err := pivcli.AttestationCmd(cmd.Context(), o.Slot)
+			if err != nil {
+				return err
+			}
 			switch o.Output {
 			case "text":
-				a.Output(cmd.OutOrStdout(), cmd.OutOrStderr())
+				a.Output(cmd.OutOrStdout(), cmd.ErrOrStderr())
 			case "json":
 				b, err := json.Marshal(a)
 				if err != nil {
@@ -141,7 +149,7 @@ func pivToolAttestation() *cobra.Command {
 				}
 				cmd.Println(string(b))
 			}
-			return err
+			return a.WriteCertificates(o.OutputDeviceCertificate, o.OutputKeyCertificate)
 		},
 	}
 
@@ -156,10 +164,16 @@ func pivToolGenerateKey() *cobra.Command {
 	cmd := &cobra.Command{
 		Use:   "generate-key",
 		Short: "generate-key generates a new signing key on the hardware token",
-		Args:  cobra.ExactArgs(0),
+		Long: `Generates a new ECDSA P-256 signing key in a slot of the hardware token, printing its public key and
+attestation. The PIN and touch policies default to the ones of the slot, e.g. always for the signature slot.`,
+		Example: `  cosign piv-tool generate-key --slot signature --pin-policy once --touch-policy cached [--random-management-key]
+
+  # provision a token non-interactively, keeping the attestation certificates to prove the key was generated on it
+  cosign piv-tool generate-key --no-input --output-device-certificate device.pem --output-key-certificate key.pem`,
+		Args: cobra.ExactArgs(0),
 		RunE: func(cmd *cobra.Command, args []string) error {
 			return pivcli.GenerateKeyCmd(cmd.Context(), o.ManagementKey, o.RandomKey,
-				o.Slot, o.PINPolicy, o.TouchPolicy)
+				o.Slot, o.PINPolicy, o.TouchPolicy, o.OutputDeviceCertificate, o.OutputKeyCertificate)
 		},
 	}
 
@@ -172,7 +186,10 @@ func pivToolResetKey() *cobra.Command {
 	cmd := &cobra.Command{
 		Use:   "reset",
 		Short: "reset resets the hardware token completely",
-		Args:  cobra.ExactArgs(0),
+		Long: `Resets the hardware token to its factory defaults, destroying its keys and certificates, and setting
+its PIN, PUK and management key back to their defaults.`,
+		Example: `  cosign piv-tool reset [--no-input]`,
+		Args:    cobra.ExactArgs(0),
 		RunE: func(cmd *cobra.Command, args []string) error {
 			return pivcli.ResetKeyCmd(cmd.Context())
 		},
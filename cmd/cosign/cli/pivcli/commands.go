@@ -23,7 +23,6 @@ import (
 	"crypto/x509"
 	"encoding/pem"
 	"errors"
-	"flag"
 	"fmt"
 	"io"
 	"os"
@@ -149,18 +148,41 @@ func (a *Attestations) Output(stdout, stderr io.Writer) {
 	fmt.Fprintln(stderr, "Verifying certificates...")
 
 	fmt.Fprintln(stderr, "Verified ok")
-	fmt.Println()
+	fmt.Fprintln(stderr)
 
 	fmt.Fprintln(stderr, "Device info:")
 	fmt.Fprintln(stdout, "  Issuer:", a.DeviceCert.Issuer)
 	fmt.Fprintln(stdout, "  Form factor:", formFactorString(a.KeyAttestation.Formfactor))
 	fmt.Fprintln(stdout, "  PIN Policy:", pinPolicyStr(a.KeyAttestation.PINPolicy))
+	fmt.Fprintln(stdout, "  Touch Policy:", touchPolicyStr(a.KeyAttestation.TouchPolicy))
 
 	fmt.Fprintf(stdout, "  Serial number: %d\n", a.KeyAttestation.Serial)
 	fmt.Fprintf(stdout, "  Version: %d.%d.%d\n", a.KeyAttestation.Version.Major, a.KeyAttestation.Version.Minor, a.KeyAttestation.Version.Patch)
 }
 
+// WriteCertificates writes the PEM encoded device and key attestation
+// certificates to deviceCertPath and keyCertPath, when not empty, so that the
+// key can be proven to have been generated on the token.
+func (a *Attestations) WriteCertificates(deviceCertPath, keyCertPath string) error {
+	for _, f := range []struct{ path, pem string }{
+		{deviceCertPath, a.DeviceCertPem},
+		{keyCertPath, a.KeyCertPem},
+	} {
+		if f.path == "" {
+			continue
+		}
+		if err := os.WriteFile(f.path, []byte(f.pem), 0600); err != nil {
+			return fmt.Errorf("writing attestation certificate: %w", err)
+		}
+		fmt.Fprintln(os.Stderr, "Attestation certificate written to", f.path)
+	}
+	return nil
+}
+
 func AttestationCmd(_ context.Context, slotArg string) (*Attestations, error) {
+	if pivkey.SlotForName(slotArg) == nil {
+		return nil, fmt.Errorf("invalid slot %q, expected authentication, signature, card-authentication or key-management", slotArg)
+	}
 	yk, err := pivkey.GetKeyWithSlot(slotArg)
 	if err != nil {
 		return nil, err
@@ -200,20 +222,21 @@ func toPem(c *x509.Certificate) string {
 	return string(b)
 }
 
-func GenerateKeyCmd(ctx context.Context, managementKey string, randomKey bool, slotArg string, pinPolicyArg string, touchPolicyArg string) error {
+func GenerateKeyCmd(ctx context.Context, managementKey string, randomKey bool, slotArg string, pinPolicyArg string, touchPolicyArg string,
+	deviceCertOutput string, keyCertOutput string) error {
 	slot := pivkey.SlotForName(slotArg)
 	if slot == nil {
-		return flag.ErrHelp
+		return fmt.Errorf("invalid slot %q, expected authentication, signature, card-authentication or key-management", slotArg)
 	}
 
 	pinPolicy := pivkey.PINPolicyForName(strings.ToLower(pinPolicyArg), *slot)
 	if pinPolicy < 0 {
-		return flag.ErrHelp
+		return fmt.Errorf("invalid PIN policy %q, expected never, once or always", pinPolicyArg)
 	}
 
 	touchPolicy := pivkey.TouchPolicyForName(strings.ToLower(touchPolicyArg), *slot)
 	if touchPolicy < 0 {
-		return flag.ErrHelp
+		return fmt.Errorf("invalid touch policy %q, expected never, always or cached", touchPolicyArg)
 	}
 
 	yk, err := pivkey.GetKey()
@@ -270,7 +293,7 @@ func GenerateKeyCmd(ctx context.Context, managementKey string, randomKey bool, s
 		return err
 	}
 	att.Output(os.Stdout, os.Stderr)
-	return nil
+	return att.WriteCertificates(deviceCertOutput, keyCertOutput)
 }
 
 func ResetKeyCmd(ctx context.Context) error {
@@ -283,7 +306,11 @@ func ResetKeyCmd(ctx context.Context) error {
 		return nil
 	}
 
-	return yk.Reset()
+	if err := yk.Reset(); err != nil {
+		return err
+	}
+	fmt.Fprintln(os.Stderr, "Reset the PIN, PUK and management key to their defaults")
+	return nil
 }
 
 func keyBytes(s string) (*[24]byte, error) {
@@ -298,7 +325,14 @@ func keyBytes(s string) (*[24]byte, error) {
 	return &ret, nil
 }
 
+// SkipConfirmation makes Confirm accept every prompt, so that tokens can be
+// provisioned non-interactively.
+var SkipConfirmation bool
+
 var Confirm = func(p string) bool {
+	if SkipConfirmation {
+		return true
+	}
 	prompt := promptui.Prompt{
 		Label:     p,
 		IsConfirm: true,
@@ -341,6 +375,19 @@ func formFactorString(ff piv.Formfactor) string {
 	}
 }
 
+func touchPolicyStr(tp piv.TouchPolicy) string {
+	switch tp {
+	case piv.TouchPolicyAlways:
+		return "Always"
+	case piv.TouchPolicyNever:
+		return "Never"
+	case piv.TouchPolicyCached:
+		return "Cached"
+	default:
+		return "unknown"
+	}
+}
+
 func pinPolicyStr(pp piv.PINPolicy) string {
 	switch pp {
 	case piv.PINPolicyAlways:
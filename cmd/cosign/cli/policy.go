@@ -0,0 +1,175 @@
+//
+// Copyright 2023 The Sigstore Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cli
+
+import (
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"github.com/sigstore/cosign/v2/cmd/cosign/cli/generate"
+	"github.com/sigstore/cosign/v2/cmd/cosign/cli/options"
+	"github.com/sigstore/cosign/v2/cmd/cosign/cli/policy"
+	"github.com/sigstore/cosign/v2/cmd/cosign/cli/verify"
+)
+
+func Policy() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "policy",
+		Short: "Manage root-of-trust policies listing the keys trusted to sign the images of a registry namespace, and evaluate attestation policies",
+		Long: `Manage root-of-trust policies listing the keys trusted to sign the images of a registry namespace.
+
+A policy names the maintainer keys of a namespace and how many of them must sign
+the policy itself. Once signed by enough maintainers it is stored in the
+registry at <NAMESPACE>/cosign-policy:latest, and 'cosign verify --trust-policy'
+trusts signatures made by any of the maintainer keys.
+
+'cosign policy eval' evaluates the CUE and Rego policies of
+'cosign verify-attestation --policy' against attestations without verifying
+them, to author and debug those policies.`,
+	}
+
+	cmd.AddCommand(
+		policyInit(),
+		policySign(),
+		policyVerify(),
+		policyEval(),
+	)
+
+	return cmd
+}
+
+func policyInit() *cobra.Command {
+	o := &options.PolicyInitOptions{}
+
+	cmd := &cobra.Command{
+		Use:   "init",
+		Short: "Create an unsigned root-of-trust policy for a registry namespace",
+		Example: `  cosign policy init --namespace <NAMESPACE> -m <KEY> [-m <KEY> ...] [--threshold <N>] [--expires <DAYS>]
+
+  # create a policy for ghcr.io/myorg trusting three maintainers, two of which must sign it
+  cosign policy init --namespace ghcr.io/myorg -m alice.pub -m bob.pub -m carol.pub --threshold 2
+
+  # create a policy valid for 90 days with a maintainer key stored in a KMS
+  cosign policy init --namespace ghcr.io/myorg -m gcpkms://projects/[PROJECT]/locations/global/keyRings/[KEYRING]/cryptoKeys/[KEY] --expires 90`,
+		Args:             cobra.NoArgs,
+		PersistentPreRun: options.BindViper,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return policy.InitCmd(cmd.Context(), *o)
+		},
+	}
+
+	o.AddFlags(cmd)
+
+	return cmd
+}
+
+func policySign() *cobra.Command {
+	o := &options.PolicySignOptions{}
+
+	cmd := &cobra.Command{
+		Use:   "sign",
+		Short: "Sign a root-of-trust policy with a maintainer key and upload it to the registry",
+		Example: `  cosign policy sign --namespace <NAMESPACE> --key <KEY> [--policy <FILE>]
+
+  # sign the policy created by 'cosign policy init' and upload it
+  cosign policy sign --namespace ghcr.io/myorg --key alice.key --policy policy.json
+
+  # add a signature to the policy already uploaded by another maintainer
+  cosign policy sign --namespace ghcr.io/myorg --key bob.key`,
+		Args:             cobra.NoArgs,
+		PersistentPreRun: options.BindViper,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return policy.SignCmd(cmd.Context(), *o, generate.GetPass)
+		},
+	}
+
+	o.AddFlags(cmd)
+
+	return cmd
+}
+
+func policyVerify() *cobra.Command {
+	o := &options.PolicyVerifyOptions{}
+
+	cmd := &cobra.Command{
+		Use:   "verify",
+		Short: "Verify the root-of-trust policy of a registry namespace and print its maintainer key IDs",
+		Example: `  cosign policy verify --namespace <NAMESPACE>
+
+  # verify the policy of ghcr.io/myorg
+  cosign policy verify --namespace ghcr.io/myorg`,
+		Args:             cobra.NoArgs,
+		PersistentPreRun: options.BindViper,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return policy.VerifyCmd(cmd.Context(), *o)
+		},
+	}
+
+	o.AddFlags(cmd)
+
+	return cmd
+}
+
+func policyEval() *cobra.Command {
+	o := &options.PolicyEvalOptions{}
+
+	cmd := &cobra.Command{
+		Use:   "eval",
+		Short: "Evaluate CUE and Rego policies against attestations without verifying their signatures",
+		Long: `Evaluate CUE and Rego policies against attestations without verifying their signatures.
+
+The attestations are read from a file with --attestation, or fetched from IMAGE.
+Each policy is evaluated against the input verify-attestation would give it, and
+whether it passed is printed with its violations. The signatures are NOT
+verified, so a passing policy says nothing about who made the attestations.`,
+		Example: `  cosign policy eval --policy <POLICY> [--type <PREDICATE_TYPE>] [--trace] (--attestation <FILE> | <IMAGE>)
+
+  # evaluate a CUE policy against the SLSA provenance attestations of an image
+  cosign policy eval --type slsaprovenance --policy provenance.cue <IMAGE>
+
+  # evaluate a Rego policy against attestations downloaded earlier and print why it fails
+  cosign download attestation <IMAGE> > attestations.jsonl
+  cosign policy eval --policy policy.rego --trace --attestation attestations.jsonl
+
+  # evaluate a policy against an unsigned in-toto statement while writing it
+  cosign policy eval --policy policy.rego --attestation statement.json`,
+		Args:             cobra.MaximumNArgs(1),
+		PersistentPreRun: options.BindViper,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			var image string
+			if len(args) > 0 {
+				image = args[0]
+			}
+			c := &verify.PolicyEvalCommand{
+				RegistryOptions: o.Registry,
+				AttestationPath: o.Attestation,
+				PredicateType:   o.Type,
+				Policies:        o.Policies,
+				PolicyEngine:    o.PolicyEngine,
+				RegoQuery:       o.RegoQuery,
+				PolicyData:      o.PolicyData,
+				Trace:           o.Trace,
+				Output:          o.Output,
+			}
+			return c.Exec(cmd.Context(), os.Stdout, image)
+		},
+	}
+
+	o.AddFlags(cmd)
+
+	return cmd
+}
@@ -0,0 +1,154 @@
+//
+// Copyright 2023 The Sigstore Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package policy
+
+import (
+	"context"
+	"crypto"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/sigstore/cosign/v2/cmd/cosign/cli/options"
+	"github.com/sigstore/cosign/v2/internal/ui"
+	"github.com/sigstore/cosign/v2/pkg/cosign"
+	"github.com/sigstore/cosign/v2/pkg/cosign/pkcs11key"
+	"github.com/sigstore/cosign/v2/pkg/cosign/rootpolicy"
+	sigs "github.com/sigstore/cosign/v2/pkg/signature"
+	signatureoptions "github.com/sigstore/sigstore/pkg/signature/options"
+)
+
+// InitCmd writes an unsigned root-of-trust policy for the namespace to
+// o.OutFile.
+func InitCmd(ctx context.Context, o options.PolicyInitOptions) error {
+	if len(o.Maintainers) == 0 {
+		return errors.New("at least one maintainer key is required")
+	}
+	maintainers := make([]crypto.PublicKey, 0, len(o.Maintainers))
+	for _, keyRef := range o.Maintainers {
+		v, err := sigs.PublicKeyFromKeyRef(ctx, keyRef)
+		if err != nil {
+			return fmt.Errorf("loading maintainer key %s: %w", keyRef, err)
+		}
+		// A file or directory of several keys adds each of them.
+		keys, ok := v.(cosign.TrustedKeys)
+		if !ok {
+			keys = cosign.TrustedKeys{v}
+		}
+		for _, k := range keys {
+			pub, err := k.PublicKey(signatureoptions.WithContext(ctx))
+			if err != nil {
+				return fmt.Errorf("loading maintainer key %s: %w", keyRef, err)
+			}
+			maintainers = append(maintainers, pub)
+		}
+	}
+	if o.Expires < 1 {
+		return fmt.Errorf("--expires must be at least one day, got %d", o.Expires)
+	}
+
+	sp, err := rootpolicy.New(o.Namespace, maintainers, o.Threshold, time.Now().AddDate(0, 0, o.Expires))
+	if err != nil {
+		return err
+	}
+	b, err := json.MarshalIndent(sp, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(o.OutFile, b, 0600); err != nil {
+		return fmt.Errorf("writing policy: %w", err)
+	}
+	ui.Infof(ctx, "Policy for %s with %d maintainers and a threshold of %d written to %s", o.Namespace, len(maintainers), o.Threshold, o.OutFile)
+	return nil
+}
+
+// SignCmd signs the policy of the namespace with a maintainer key and
+// uploads it to the registry. The policy is read from o.PolicyFile, or from
+// the registry to add a signature to an uploaded policy.
+func SignCmd(ctx context.Context, o options.PolicySignOptions, pf cosign.PassFunc) error {
+	ref, err := rootpolicy.Ref(o.Namespace, o.Registry.NameOptions()...)
+	if err != nil {
+		return err
+	}
+
+	var sp *rootpolicy.SignedPolicy
+	if o.PolicyFile != "" {
+		b, err := os.ReadFile(filepath.Clean(o.PolicyFile))
+		if err != nil {
+			return err
+		}
+		sp = &rootpolicy.SignedPolicy{}
+		if err := json.Unmarshal(b, sp); err != nil {
+			return fmt.Errorf("parsing policy %s: %w", o.PolicyFile, err)
+		}
+	} else {
+		if sp, err = rootpolicy.Fetch(ref, o.Registry.GetRegistryClientOpts(ctx)...); err != nil {
+			return err
+		}
+	}
+	if sp.Signed.Namespace != o.Namespace {
+		return fmt.Errorf("policy is for namespace %s, not %s", sp.Signed.Namespace, o.Namespace)
+	}
+
+	signer, err := sigs.SignerFromKeyRef(ctx, o.Key, pf)
+	if err != nil {
+		return fmt.Errorf("loading signing key: %w", err)
+	}
+	if pkcs11Key, ok := signer.(*pkcs11key.Key); ok {
+		defer pkcs11Key.Close()
+	}
+	if err := sp.Sign(signer); err != nil {
+		return fmt.Errorf("signing policy: %w", err)
+	}
+
+	if err := rootpolicy.Upload(ref, sp, o.Registry.GetRegistryClientOpts(ctx)...); err != nil {
+		return fmt.Errorf("uploading policy to %s: %w", ref, err)
+	}
+	ui.Infof(ctx, "Policy signed by %d of the %d required maintainers uploaded to %s", len(sp.Signatures), sp.Signed.Threshold, ref)
+	return nil
+}
+
+// VerifyCmd verifies the policy of the namespace stored in the registry and
+// prints the key IDs of its maintainers.
+func VerifyCmd(ctx context.Context, o options.PolicyVerifyOptions) error {
+	ref, err := rootpolicy.Ref(o.Namespace, o.Registry.NameOptions()...)
+	if err != nil {
+		return err
+	}
+	sp, err := rootpolicy.Fetch(ref, o.Registry.GetRegistryClientOpts(ctx)...)
+	if err != nil {
+		return err
+	}
+	if err := sp.Verify(o.Namespace, time.Now()); err != nil {
+		return fmt.Errorf("verifying policy %s: %w", ref, err)
+	}
+
+	ui.Infof(ctx, "Policy %s verified, signed by a threshold of %d maintainers, expires at %s",
+		ref, sp.Signed.Threshold, sp.Signed.Expires.Format(time.RFC3339))
+	ids := make([]string, 0, len(sp.Signed.Maintainers))
+	for id := range sp.Signed.Maintainers {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	for _, id := range ids {
+		fmt.Println(id)
+	}
+	return nil
+}
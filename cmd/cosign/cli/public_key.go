@@ -31,7 +31,7 @@ func PublicKey() *cobra.Command {
 	cmd := &cobra.Command{
 		Use:   "public-key",
 		Short: "Gets a public key from the key-pair.",
-		Long:  "Gets a public key from the key-pair and\nwrites to a specified file. By default, it will write to standard out.",
+		Long:  "Gets a public key from the key-pair, a security key or a certificate and\nwrites to a specified file. By default, it will write to standard out.\n\nThe public key is written in PEM format, or in DER, JWK or SSH format with --output.",
 		Example: `
   # extract public key from private key to a specified out file.
   cosign public-key --key <PRIVATE KEY FILE> --outfile <OUTPUT>
@@ -55,9 +55,24 @@ func PublicKey() *cobra.Command {
   cosign public-key --key gitlab://[OWNER]/[PROJECT_NAME] <IMAGE>
 
   # extract public key from GitLab with project id
-  cosign public-key --key gitlab://[PROJECT_ID] <IMAGE>`,
+  cosign public-key --key gitlab://[PROJECT_ID] <IMAGE>
+
+  # extract public key from a PKCS11 token
+  cosign public-key --key "pkcs11:token=[TOKEN];slot-id=[SLOT];object=[KEY]?module-path=[MODULE]"
+
+  # extract public key from the signature slot of a PIV hardware token
+  cosign public-key --sk --slot signature
+
+  # extract public key from a certificate issued by Fulcio
+  cosign public-key --certificate <CERT FILE>
+
+  # export public key from AWS KMS as a JSON Web Key
+  cosign public-key --key awskms://[ENDPOINT]/[ID/ALIAS/ARN] --output jwk
+
+  # export public key as an OpenSSH authorized_keys line
+  cosign public-key --key cosign.key --output ssh >> ~/.ssh/authorized_keys`,
 		PreRunE: func(cmd *cobra.Command, args []string) error {
-			if !options.OneOf(o.Key, o.SecurityKey.Use) {
+			if !options.OneOf(o.Key, o.SecurityKey.Use, o.Cert) {
 				return &options.KeyParseError{}
 			}
 			return nil
@@ -70,7 +85,7 @@ func PublicKey() *cobra.Command {
 			if o.OutFile != "" {
 				writer.Name = o.OutFile
 				var err error
-				f, err = os.OpenFile(o.OutFile, os.O_WRONLY|os.O_CREATE, 0600)
+				f, err = os.OpenFile(o.OutFile, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0600)
 				if err != nil {
 					return err
 				}
@@ -80,9 +95,11 @@ func PublicKey() *cobra.Command {
 				writer.Writer = os.Stdout
 			}
 			pk := publickey.Pkopts{
-				KeyRef: o.Key,
-				Sk:     o.SecurityKey.Use,
-				Slot:   o.SecurityKey.Slot,
+				KeyRef:  o.Key,
+				Sk:      o.SecurityKey.Use,
+				Slot:    o.SecurityKey.Slot,
+				CertRef: o.Cert,
+				Format:  o.Output,
 			}
 			return publickey.GetPublicKey(cmd.Context(), pk, writer, generate.GetPass)
 		},
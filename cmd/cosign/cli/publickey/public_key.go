@@ -17,18 +17,39 @@ package publickey
 
 import (
 	"context"
+	"crypto"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
 	"fmt"
 	"io"
 
+	"go.step.sm/crypto/jose"
+	"golang.org/x/crypto/ssh"
+
 	"github.com/sigstore/cosign/v2/internal/ui"
+	"github.com/sigstore/cosign/v2/pkg/blob"
 	"github.com/sigstore/cosign/v2/pkg/cosign"
 	"github.com/sigstore/cosign/v2/pkg/cosign/pivkey"
 	"github.com/sigstore/cosign/v2/pkg/cosign/pkcs11key"
 	sigs "github.com/sigstore/cosign/v2/pkg/signature"
-	"github.com/sigstore/sigstore/pkg/signature"
+	"github.com/sigstore/sigstore/pkg/cryptoutils"
 	signatureoptions "github.com/sigstore/sigstore/pkg/signature/options"
 )
 
+// Output formats of GetPublicKey.
+const (
+	// FormatPEM is a PEM encoded PKIX public key, like cosign.pub.
+	FormatPEM = "pem"
+	// FormatDER is a DER encoded PKIX public key.
+	FormatDER = "der"
+	// FormatJWK is a JSON Web Key, identified by its RFC 7638 thumbprint.
+	FormatJWK = "jwk"
+	// FormatSSH is an OpenSSH authorized_keys line.
+	FormatSSH = "ssh"
+)
+
 type NamedWriter struct {
 	Name string
 	io.Writer
@@ -38,10 +59,15 @@ type Pkopts struct {
 	KeyRef string
 	Sk     bool
 	Slot   string
+	// CertRef is the path or URL of a certificate, e.g. issued by Fulcio,
+	// to get the public key of.
+	CertRef string
+	// Format is the output format, FormatPEM when empty.
+	Format string
 }
 
 func GetPublicKey(ctx context.Context, opts Pkopts, writer NamedWriter, pf cosign.PassFunc) error {
-	var k signature.PublicKeyProvider
+	var pub crypto.PublicKey
 	switch {
 	case opts.KeyRef != "":
 		s, err := sigs.SignerFromKeyRef(ctx, opts.KeyRef, pf)
@@ -52,7 +78,10 @@ func GetPublicKey(ctx context.Context, opts Pkopts, writer NamedWriter, pf cosig
 		if ok {
 			defer pkcs11Key.Close()
 		}
-		k = s
+		pub, err = s.PublicKey(signatureoptions.WithContext(ctx))
+		if err != nil {
+			return err
+		}
 	case opts.Sk:
 		sk, err := pivkey.GetKeyWithSlot(opts.Slot)
 		if err != nil {
@@ -63,15 +92,26 @@ func GetPublicKey(ctx context.Context, opts Pkopts, writer NamedWriter, pf cosig
 		if err != nil {
 			return fmt.Errorf("initializing piv token verifier: %w", err)
 		}
-		k = pk
+		pub, err = pk.PublicKey()
+		if err != nil {
+			return err
+		}
+	case opts.CertRef != "":
+		var err error
+		pub, err = certificatePublicKey(opts.CertRef)
+		if err != nil {
+			return err
+		}
+	default:
+		return errors.New("one of a key, security key or certificate is required")
 	}
 
-	pemBytes, err := sigs.PublicKeyPem(k, signatureoptions.WithContext(ctx))
+	b, err := MarshalPublicKey(pub, opts.Format)
 	if err != nil {
 		return err
 	}
 
-	if _, err := writer.Write(pemBytes); err != nil {
+	if _, err := writer.Write(b); err != nil {
 		return err
 	}
 	if writer.Name != "" {
@@ -79,3 +119,59 @@ func GetPublicKey(ctx context.Context, opts Pkopts, writer NamedWriter, pf cosig
 	}
 	return nil
 }
+
+// MarshalPublicKey encodes pub in format, one of FormatPEM, FormatDER,
+// FormatJWK or FormatSSH. The empty format is FormatPEM.
+func MarshalPublicKey(pub crypto.PublicKey, format string) ([]byte, error) {
+	switch format {
+	case "", FormatPEM:
+		return cryptoutils.MarshalPublicKeyToPEM(pub)
+	case FormatDER:
+		return cryptoutils.MarshalPublicKeyToDER(pub)
+	case FormatJWK:
+		jwk := jose.JSONWebKey{Key: pub}
+		thumbprint, err := jwk.Thumbprint(crypto.SHA256)
+		if err != nil {
+			return nil, fmt.Errorf("computing JWK thumbprint: %w", err)
+		}
+		jwk.KeyID = base64.RawURLEncoding.EncodeToString(thumbprint)
+		b, err := jwk.MarshalJSON()
+		if err != nil {
+			return nil, fmt.Errorf("marshaling JWK: %w", err)
+		}
+		return append(b, '\n'), nil
+	case FormatSSH:
+		sshKey, err := ssh.NewPublicKey(pub)
+		if err != nil {
+			return nil, fmt.Errorf("converting to an SSH public key: %w", err)
+		}
+		return ssh.MarshalAuthorizedKey(sshKey), nil
+	default:
+		return nil, fmt.Errorf("unsupported public key format %q, must be one of %s, %s, %s or %s", format, FormatPEM, FormatDER, FormatJWK, FormatSSH)
+	}
+}
+
+// certificatePublicKey returns the public key of the PEM or DER encoded
+// certificate at ref, a path or URL. The first certificate of a chain is
+// the leaf.
+func certificatePublicKey(ref string) (crypto.PublicKey, error) {
+	b, err := blob.LoadFileOrURL(ref)
+	if err != nil {
+		return nil, fmt.Errorf("loading certificate: %w", err)
+	}
+	if block, _ := pem.Decode(b); block != nil {
+		certs, err := cryptoutils.UnmarshalCertificatesFromPEM(b)
+		if err != nil {
+			return nil, fmt.Errorf("parsing certificate: %w", err)
+		}
+		if len(certs) == 0 {
+			return nil, fmt.Errorf("no certificate found in %s", ref)
+		}
+		return certs[0].PublicKey, nil
+	}
+	cert, err := x509.ParseCertificate(b)
+	if err != nil {
+		return nil, fmt.Errorf("parsing certificate: %w", err)
+	}
+	return cert.PublicKey, nil
+}
@@ -19,11 +19,15 @@ import (
 	"bytes"
 	"context"
 	"crypto/rand"
+	"crypto/x509"
+	"encoding/json"
 	"os"
 	"path/filepath"
 	"testing"
 
 	"github.com/sigstore/cosign/v2/pkg/cosign"
+	"github.com/sigstore/cosign/v2/test"
+	"github.com/sigstore/sigstore/pkg/cryptoutils"
 )
 
 func pass(s string) cosign.PassFunc {
@@ -86,3 +90,67 @@ func TestPublicKeyBadPrivateKey(t *testing.T) {
 		t.Error("expected error getting public key!")
 	}
 }
+
+func TestMarshalPublicKey(t *testing.T) {
+	priv, err := cosign.GeneratePrivateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	pub := priv.Public()
+
+	for _, tt := range []struct {
+		format string
+		check  func([]byte) bool
+	}{
+		{FormatPEM, func(b []byte) bool { return bytes.HasPrefix(b, []byte("-----BEGIN PUBLIC KEY-----")) }},
+		{FormatDER, func(b []byte) bool {
+			k, err := x509.ParsePKIXPublicKey(b)
+			return err == nil && cryptoutils.EqualKeys(pub, k) == nil
+		}},
+		{FormatJWK, func(b []byte) bool {
+			var jwk map[string]string
+			return json.Unmarshal(b, &jwk) == nil && jwk["kty"] == "EC" && jwk["crv"] == "P-256" && jwk["kid"] != ""
+		}},
+		{FormatSSH, func(b []byte) bool { return bytes.HasPrefix(b, []byte("ecdsa-sha2-nistp256 ")) }},
+	} {
+		t.Run(tt.format, func(t *testing.T) {
+			b, err := MarshalPublicKey(pub, tt.format)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !tt.check(b) {
+				t.Errorf("unexpected %s public key: %s", tt.format, b)
+			}
+		})
+	}
+
+	if _, err := MarshalPublicKey(pub, "xml"); err == nil {
+		t.Error("expected error for an unsupported format")
+	}
+}
+
+func TestPublicKeyFromCertificate(t *testing.T) {
+	ctx := context.Background()
+	rootCert, rootKey, _ := test.GenerateRootCa()
+	leafCert, leafKey, _ := test.GenerateLeafCert("subject", "oidc-issuer", rootCert, rootKey)
+	pemChain, err := cryptoutils.MarshalCertificatesToPEM([]*x509.Certificate{leafCert, rootCert})
+	if err != nil {
+		t.Fatal(err)
+	}
+	f := filepath.Join(t.TempDir(), "cert.pem")
+	if err := os.WriteFile(f, pemChain, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	var out bytes.Buffer
+	if err := GetPublicKey(ctx, Pkopts{CertRef: f}, NamedWriter{"", &out}, nil); err != nil {
+		t.Fatal(err)
+	}
+	want, err := cryptoutils.MarshalPublicKeyToPEM(leafKey.Public())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(out.Bytes(), want) {
+		t.Fatalf("expect %s got %s", want, out.Bytes())
+	}
+}
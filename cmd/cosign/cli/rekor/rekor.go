@@ -15,16 +15,130 @@
 package rekor
 
 import (
+	"context"
+	"io"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/go-openapi/runtime"
+	httptransport "github.com/go-openapi/runtime/client"
+	"github.com/go-openapi/strfmt"
 	rekor "github.com/sigstore/rekor/pkg/client"
 	"github.com/sigstore/rekor/pkg/generated/client"
+	"github.com/sigstore/rekor/pkg/util"
 
 	"github.com/sigstore/cosign/v2/cmd/cosign/cli/options"
+	"github.com/sigstore/cosign/v2/internal/ui"
 )
 
 func NewClient(rekorURL string) (*client.Rekor, error) {
+	t := options.Transport(options.EndpointRekor)
+	if t == nil && (ui.DebugEnabled(context.Background()) || options.TransportsWrapped()) {
+		t = http.DefaultTransport.(*http.Transport).Clone()
+	}
+	if t != nil {
+		return newClientWithTransport(rekorURL, t)
+	}
 	rekorClient, err := rekor.GetRekorClient(rekorURL, rekor.WithUserAgent(options.UserAgent()))
 	if err != nil {
 		return nil, err
 	}
 	return rekorClient, nil
 }
+
+// newClientWithTransport mirrors rekor.GetRekorClient, which doesn't accept
+// a transport, for when --http-proxy, --cacert or --insecure-skip-tls-verify
+// configure the connection to rekor, or its calls are logged or measured.
+func newClientWithTransport(rekorURL string, t http.RoundTripper) (*client.Rekor, error) {
+	u, err := url.Parse(rekorURL)
+	if err != nil {
+		return nil, err
+	}
+	if u.Path == "" {
+		u.Path = client.DefaultBasePath
+	}
+	httpClient := &http.Client{Transport: userAgentTransport{RoundTripper: retryTransport{
+		RoundTripper: ui.NewTransport(options.EndpointRekor, options.WrapTransport(options.EndpointRekor, t)),
+		retries:      defaultRetries,
+		backoff:      defaultBackoff,
+	}}}
+	rt := httptransport.NewWithClient(u.Host, u.Path, []string{u.Scheme}, httpClient)
+	rt.Consumers["application/json"] = runtime.JSONConsumer()
+	rt.Consumers["application/x-pem-file"] = runtime.TextConsumer()
+	rt.Producers["application/json"] = runtime.JSONProducer()
+
+	registry := strfmt.Default
+	registry.Add("signedCheckpoint", &util.SignedNote{}, util.SignedCheckpointValidator)
+	return client.New(rt, registry), nil
+}
+
+type userAgentTransport struct {
+	http.RoundTripper
+}
+
+func (t userAgentTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	req = req.Clone(req.Context())
+	req.Header.Set("User-Agent", options.UserAgent())
+	return t.RoundTripper.RoundTrip(req)
+}
+
+const (
+	// defaultRetries matches the retry count of rekor.GetRekorClient.
+	defaultRetries = 3
+	defaultBackoff = time.Second
+)
+
+// retryTransport retries requests that failed to reach rekor or that rekor
+// could not serve at the time, doubling the wait after each attempt.
+type retryTransport struct {
+	http.RoundTripper
+	retries int
+	backoff time.Duration
+}
+
+func (t retryTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.Body != nil && req.GetBody == nil {
+		// The body can only be sent once.
+		return t.RoundTripper.RoundTrip(req)
+	}
+	wait := t.backoff
+	for attempt := 0; ; attempt++ {
+		if attempt > 0 && req.Body != nil {
+			body, err := req.GetBody()
+			if err != nil {
+				return nil, err
+			}
+			req = req.Clone(req.Context())
+			req.Body = body
+		}
+		resp, err := t.RoundTripper.RoundTrip(req)
+		if attempt >= t.retries || !shouldRetry(resp, err) {
+			return resp, err
+		}
+		if resp != nil {
+			_, _ = io.Copy(io.Discard, resp.Body)
+			resp.Body.Close()
+		}
+		select {
+		case <-req.Context().Done():
+			return nil, req.Context().Err()
+		case <-time.After(wait):
+		}
+		wait *= 2
+	}
+}
+
+func shouldRetry(resp *http.Response, err error) bool {
+	if err != nil {
+		return true
+	}
+	switch {
+	case resp.StatusCode == http.StatusRequestTimeout, resp.StatusCode == http.StatusTooManyRequests:
+		return true
+	case resp.StatusCode == http.StatusNotImplemented:
+		return false
+	default:
+		return resp.StatusCode >= http.StatusInternalServerError
+	}
+}
@@ -15,9 +15,12 @@
 package rekor
 
 import (
+	"io"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/sigstore/cosign/v2/cmd/cosign/cli/options"
 )
@@ -50,3 +53,69 @@ func TestNewClient(t *testing.T) {
 		t.Fatal("no requests were received")
 	}
 }
+
+func TestRetryTransport(t *testing.T) {
+	tests := []struct {
+		name      string
+		statuses  []int
+		wantCalls int
+		wantCode  int
+	}{{
+		name:      "success",
+		statuses:  []int{http.StatusOK},
+		wantCalls: 1,
+		wantCode:  http.StatusOK,
+	}, {
+		name:      "unavailable then success",
+		statuses:  []int{http.StatusServiceUnavailable, http.StatusTooManyRequests, http.StatusCreated},
+		wantCalls: 3,
+		wantCode:  http.StatusCreated,
+	}, {
+		name:      "always unavailable",
+		statuses:  []int{http.StatusBadGateway, http.StatusBadGateway, http.StatusBadGateway, http.StatusBadGateway, http.StatusOK},
+		wantCalls: 4,
+		wantCode:  http.StatusBadGateway,
+	}, {
+		name:      "client error",
+		statuses:  []int{http.StatusConflict, http.StatusOK},
+		wantCalls: 1,
+		wantCode:  http.StatusConflict,
+	}, {
+		name:      "not implemented",
+		statuses:  []int{http.StatusNotImplemented, http.StatusOK},
+		wantCalls: 1,
+		wantCode:  http.StatusNotImplemented,
+	}}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			calls := 0
+			testServer := httptest.NewServer(http.HandlerFunc(
+				func(w http.ResponseWriter, r *http.Request) {
+					body, _ := io.ReadAll(r.Body)
+					if string(body) != "entry" {
+						t.Errorf("attempt %d got body %q", calls, body)
+					}
+					w.WriteHeader(tc.statuses[calls])
+					calls++
+				}))
+			defer testServer.Close()
+
+			rt := retryTransport{RoundTripper: http.DefaultTransport, retries: defaultRetries, backoff: time.Millisecond}
+			req, err := http.NewRequest(http.MethodPost, testServer.URL, strings.NewReader("entry"))
+			if err != nil {
+				t.Fatal(err)
+			}
+			resp, err := rt.RoundTrip(req)
+			if err != nil {
+				t.Fatal(err)
+			}
+			resp.Body.Close()
+			if resp.StatusCode != tc.wantCode {
+				t.Errorf("got status %d, wanted %d", resp.StatusCode, tc.wantCode)
+			}
+			if calls != tc.wantCalls {
+				t.Errorf("got %d calls, wanted %d", calls, tc.wantCalls)
+			}
+		})
+	}
+}
@@ -0,0 +1,106 @@
+//
+// Copyright 2023 The Sigstore Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cli
+
+import (
+	"github.com/spf13/cobra"
+
+	"github.com/sigstore/cosign/v2/cmd/cosign/cli/generate"
+	"github.com/sigstore/cosign/v2/cmd/cosign/cli/options"
+	"github.com/sigstore/cosign/v2/cmd/cosign/cli/revocation"
+)
+
+func Revocation() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "revocation",
+		Short: "Publish and verify signed revocation lists of image digests and signing keys",
+		Long: `Publish and verify signed revocation lists of image digests and signing keys.
+
+A revocation list names the image digests and the public keys whose signatures
+must no longer be trusted, e.g. after a key was compromised, without deleting
+the images or their signatures from the registry. It is signed with a key and
+expires, so that it must be published again periodically.
+
+'cosign verify' and 'cosign verify-attestation' consult the list given with
+--revocation-list once verified with --revocation-list-key: images whose digest
+is revoked fail verification, and so do signatures made by a revoked key.`,
+	}
+
+	cmd.AddCommand(
+		revocationPublish(),
+		revocationVerify(),
+	)
+
+	return cmd
+}
+
+func revocationPublish() *cobra.Command {
+	o := &options.RevocationPublishOptions{}
+
+	cmd := &cobra.Command{
+		Use:   "publish",
+		Short: "Sign a revocation list and upload it to the registry or write it to a file",
+		Example: `  cosign revocation publish --key <KEY> [--digest <DIGEST>...] [--revoked-key <KEY>...] [--revoked-key-id <ID>...] [--from <LIST>] [--expires <DAYS>] [--out <FILE>] [<REFERENCE>]
+
+  # revoke an image digest and upload the list to the registry
+  cosign revocation publish --key revocation.key --digest sha256:6f8d... oci://ghcr.io/myorg/revocations:latest
+
+  # revoke a compromised key in addition to the digests already revoked
+  cosign revocation publish --key revocation.key --revoked-key alice.pub \
+    --from oci://ghcr.io/myorg/revocations:latest oci://ghcr.io/myorg/revocations:latest
+
+  # write the list to a file, to be served at an https URL
+  cosign revocation publish --key revocation.key --digest sha256:6f8d... --out revocations.json`,
+		Args:             cobra.MaximumNArgs(1),
+		PersistentPreRun: options.BindViper,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			destination := ""
+			if len(args) == 1 {
+				destination = args[0]
+			}
+			return revocation.PublishCmd(cmd.Context(), *o, destination, generate.GetPass)
+		},
+	}
+
+	o.AddFlags(cmd)
+
+	return cmd
+}
+
+func revocationVerify() *cobra.Command {
+	o := &options.RevocationVerifyOptions{}
+
+	cmd := &cobra.Command{
+		Use:   "verify",
+		Short: "Verify a revocation list and print the digests and key IDs it revokes",
+		Example: `  cosign revocation verify --key <KEY> <LIST>
+
+  # verify the list stored in the registry
+  cosign revocation verify --key revocation.pub oci://ghcr.io/myorg/revocations:latest
+
+  # verify the list served at an https URL
+  cosign revocation verify --key revocation.pub https://example.com/revocations.json`,
+		Args:             cobra.ExactArgs(1),
+		PersistentPreRun: options.BindViper,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return revocation.VerifyCmd(cmd.Context(), *o, args[0], cmd.OutOrStdout())
+		},
+	}
+
+	o.AddFlags(cmd)
+
+	return cmd
+}
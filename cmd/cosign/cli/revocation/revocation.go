@@ -0,0 +1,155 @@
+//
+// Copyright 2023 The Sigstore Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package revocation
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/google/go-containerregistry/pkg/name"
+	"github.com/sigstore/cosign/v2/cmd/cosign/cli/options"
+	"github.com/sigstore/cosign/v2/internal/ui"
+	"github.com/sigstore/cosign/v2/pkg/cosign"
+	"github.com/sigstore/cosign/v2/pkg/cosign/pkcs11key"
+	"github.com/sigstore/cosign/v2/pkg/cosign/revocation"
+	"github.com/sigstore/cosign/v2/pkg/cosign/rootpolicy"
+	sigs "github.com/sigstore/cosign/v2/pkg/signature"
+	signatureoptions "github.com/sigstore/sigstore/pkg/signature/options"
+)
+
+// PublishCmd signs a revocation list of the digests and keys of o, added to
+// those of the list at o.From, and uploads it to the registry at destination
+// and/or writes it to o.OutFile.
+func PublishCmd(ctx context.Context, o options.RevocationPublishOptions, destination string, pf cosign.PassFunc) error {
+	if destination == "" && o.OutFile == "" {
+		return errors.New("a registry reference to upload the revocation list to, or --out, is required")
+	}
+	if o.Expires < 1 {
+		return fmt.Errorf("--expires must be at least one day, got %d", o.Expires)
+	}
+	var ref name.Reference
+	if destination != "" {
+		var err error
+		if ref, err = name.ParseReference(strings.TrimPrefix(destination, revocation.RegistryPrefix), o.Registry.NameOptions()...); err != nil {
+			return err
+		}
+	}
+
+	// The key also verifies the list at o.From before it is extended.
+	signer, err := sigs.SignerVerifierFromKeyRef(ctx, o.Key, pf)
+	if err != nil {
+		return fmt.Errorf("loading signing key: %w", err)
+	}
+	if pkcs11Key, ok := signer.(*pkcs11key.Key); ok {
+		defer pkcs11Key.Close()
+	}
+
+	digests := o.Digests
+	keys := o.KeyIDs
+	for _, keyRef := range o.RevokedKeys {
+		v, err := sigs.PublicKeyFromKeyRef(ctx, keyRef)
+		if err != nil {
+			return fmt.Errorf("loading revoked key %s: %w", keyRef, err)
+		}
+		revoked, ok := v.(cosign.TrustedKeys)
+		if !ok {
+			revoked = cosign.TrustedKeys{v}
+		}
+		for _, k := range revoked {
+			pub, err := k.PublicKey(signatureoptions.WithContext(ctx))
+			if err != nil {
+				return fmt.Errorf("loading revoked key %s: %w", keyRef, err)
+			}
+			id, err := rootpolicy.KeyID(pub)
+			if err != nil {
+				return err
+			}
+			keys = append(keys, id)
+		}
+	}
+	now := time.Now()
+	if o.From != "" {
+		previous, _, err := revocation.Fetch(ctx, o.From, o.Registry.GetRegistryClientOpts(ctx)...)
+		if err != nil {
+			return err
+		}
+		if err := previous.Verify(signer, now); err != nil {
+			return fmt.Errorf("verifying revocation list %s: %w", o.From, err)
+		}
+		digests = append(previous.Signed.Digests, digests...)
+		keys = append(previous.Signed.Keys, keys...)
+	}
+	if len(digests) == 0 && len(keys) == 0 {
+		return errors.New("nothing to revoke, set --digest, --revoked-key or --revoked-key-id")
+	}
+
+	sl, err := revocation.New(digests, keys, now, now.AddDate(0, 0, o.Expires))
+	if err != nil {
+		return err
+	}
+	if err := sl.Sign(signer); err != nil {
+		return fmt.Errorf("signing revocation list: %w", err)
+	}
+
+	if o.OutFile != "" {
+		b, err := json.MarshalIndent(sl, "", "  ")
+		if err != nil {
+			return err
+		}
+		if err := os.WriteFile(o.OutFile, b, 0600); err != nil {
+			return fmt.Errorf("writing revocation list: %w", err)
+		}
+		ui.Infof(ctx, "Revocation list of %d digests and %d keys written to %s", len(sl.Signed.Digests), len(sl.Signed.Keys), o.OutFile)
+	}
+	if ref != nil {
+		if err := revocation.Upload(ref, sl, o.Registry.GetRegistryClientOpts(ctx)...); err != nil {
+			return fmt.Errorf("uploading revocation list to %s: %w", ref, err)
+		}
+		ui.Infof(ctx, "Revocation list of %d digests and %d keys uploaded to %s", len(sl.Signed.Digests), len(sl.Signed.Keys), ref)
+	}
+	return nil
+}
+
+// VerifyCmd verifies the revocation list at source and writes the list of
+// digests and key IDs it revokes to w as JSON.
+func VerifyCmd(ctx context.Context, o options.RevocationVerifyOptions, source string, w io.Writer) error {
+	verifier, err := sigs.PublicKeyFromKeyRef(ctx, o.Key)
+	if err != nil {
+		return fmt.Errorf("loading public key: %w", err)
+	}
+	sl, _, err := revocation.Fetch(ctx, source, o.Registry.GetRegistryClientOpts(ctx)...)
+	if err != nil {
+		return err
+	}
+	if err := sl.Verify(verifier, time.Now()); err != nil {
+		return fmt.Errorf("verifying revocation list %s: %w", source, err)
+	}
+
+	ui.Infof(ctx, "Revocation list %s verified, issued at %s, expires at %s",
+		source, sl.Signed.Issued.Format(time.RFC3339), sl.Signed.Expires.Format(time.RFC3339))
+	b, err := json.Marshal(sl.Signed)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintln(w, string(b))
+	return nil
+}
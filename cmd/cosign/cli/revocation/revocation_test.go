@@ -0,0 +1,94 @@
+//
+// Copyright 2023 The Sigstore Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package revocation
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/sigstore/cosign/v2/cmd/cosign/cli/options"
+	"github.com/sigstore/cosign/v2/pkg/cosign"
+	"github.com/sigstore/cosign/v2/pkg/cosign/revocation"
+)
+
+const (
+	digest1 = "sha256:6f8d3c36be2e2a7b3ee43cf9b1bb8ec47a0f6c3e5b8b8ee83c9fae15e0a8d1b7"
+	digest2 = "sha256:0a3bd6c9b25e7ab9d4da2c5b9ee4c5b4d8b15b1b6ec5de0b7e0f49f1ad8f7a2c"
+)
+
+func pass(_ bool) ([]byte, error) {
+	return []byte("hunter2"), nil
+}
+
+func TestPublishFrom(t *testing.T) {
+	td := t.TempDir()
+	keys, err := cosign.GenerateKeyPair(pass)
+	if err != nil {
+		t.Fatal(err)
+	}
+	otherKeys, err := cosign.GenerateKeyPair(pass)
+	if err != nil {
+		t.Fatal(err)
+	}
+	priv, pub, otherPriv := filepath.Join(td, "revocation.key"), filepath.Join(td, "revocation.pub"), filepath.Join(td, "other.key")
+	for path, b := range map[string][]byte{priv: keys.PrivateBytes, pub: keys.PublicBytes, otherPriv: otherKeys.PrivateBytes} {
+		if err := os.WriteFile(path, b, 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	ctx := context.Background()
+
+	first := filepath.Join(td, "first.json")
+	if err := PublishCmd(ctx, options.RevocationPublishOptions{
+		Key: priv, Digests: []string{digest1}, Expires: 1, OutFile: first,
+	}, "", pass); err != nil {
+		t.Fatalf("PublishCmd() = %v", err)
+	}
+
+	second := filepath.Join(td, "second.json")
+	if err := PublishCmd(ctx, options.RevocationPublishOptions{
+		Key: priv, Digests: []string{digest2}, From: first, Expires: 1, OutFile: second,
+	}, "", pass); err != nil {
+		t.Fatalf("PublishCmd(--from) = %v", err)
+	}
+
+	var out bytes.Buffer
+	if err := VerifyCmd(ctx, options.RevocationVerifyOptions{Key: pub}, second, &out); err != nil {
+		t.Fatalf("VerifyCmd() = %v", err)
+	}
+	var got revocation.List
+	if err := json.Unmarshal(out.Bytes(), &got); err != nil {
+		t.Fatalf("VerifyCmd() wrote %q: %v", out.String(), err)
+	}
+	// The digests are sorted.
+	if want := []string{digest2, digest1}; !reflect.DeepEqual(got.Digests, want) {
+		t.Errorf("VerifyCmd() digests = %v, want %v", got.Digests, want)
+	}
+
+	// A list signed by another key is not extended.
+	err = PublishCmd(ctx, options.RevocationPublishOptions{
+		Key: otherPriv, Digests: []string{digest2}, From: first, Expires: 1, OutFile: filepath.Join(td, "third.json"),
+	}, "", pass)
+	if err == nil || !strings.Contains(err.Error(), "verifying revocation list") {
+		t.Errorf("PublishCmd(--from) with another key = %v, want a verification error", err)
+	}
+}
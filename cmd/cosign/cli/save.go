@@ -19,6 +19,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"os"
 
 	"github.com/google/go-containerregistry/pkg/name"
 	"github.com/sigstore/cosign/v2/cmd/cosign/cli/options"
@@ -32,10 +33,13 @@ func Save() *cobra.Command {
 	o := &options.SaveOptions{}
 
 	cmd := &cobra.Command{
-		Use:              "save",
-		Short:            "Save the container image and associated signatures to disk at the specified directory.",
-		Long:             "Save the container image and associated signatures to disk at the specified directory.",
-		Example:          `  cosign save --dir <path to directory> <IMAGE>`,
+		Use:   "save",
+		Short: "Save the container image and associated signatures to disk at the specified directory.",
+		Long:  "Save the container image and associated signatures to disk at the specified directory, or in a single tarball.",
+		Example: `  cosign save --dir <path to directory> <IMAGE>
+
+  # save an image with its signatures and attestations in a tarball for an air-gapped environment
+  cosign save --tarball <path to file> <IMAGE>`,
 		Args:             cobra.ExactArgs(1),
 		PersistentPreRun: options.BindViper,
 		RunE: func(cmd *cobra.Command, args []string) error {
@@ -53,6 +57,27 @@ func SaveCmd(_ context.Context, opts options.SaveOptions, imageRef string) error
 		return fmt.Errorf("parsing image name %s: %w", imageRef, err)
 	}
 
+	if opts.Tarball == "" {
+		if opts.Directory == "" {
+			return errors.New("one of --dir or --tarball is required")
+		}
+		return saveLayout(ref, opts.Directory)
+	}
+	dir, err := os.MkdirTemp("", "cosign-save")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(dir)
+	if err := saveLayout(ref, dir); err != nil {
+		return err
+	}
+	return layout.WriteTarball(dir, opts.Tarball)
+}
+
+// saveLayout writes the image or image index ref with its signatures and
+// attestations to an OCI layout at dir.
+func saveLayout(ref name.Reference, dir string) error {
+
 	se, err := ociremote.SignedEntity(ref)
 	if err != nil {
 		return fmt.Errorf("signed entity: %w", err)
@@ -63,7 +88,7 @@ func SaveCmd(_ context.Context, opts options.SaveOptions, imageRef string) error
 		if err != nil {
 			return fmt.Errorf("getting signed image: %w", err)
 		}
-		return layout.WriteSignedImage(opts.Directory, si)
+		return layout.WriteSignedImage(dir, si)
 	}
 
 	if _, ok := se.(oci.SignedImageIndex); ok {
@@ -71,7 +96,7 @@ func SaveCmd(_ context.Context, opts options.SaveOptions, imageRef string) error
 		if err != nil {
 			return fmt.Errorf("getting signed image index: %w", err)
 		}
-		return layout.WriteSignedImageIndex(opts.Directory, sii)
+		return layout.WriteSignedImageIndex(dir, sii)
 	}
 	return errors.New("unknown signed entity")
 }
@@ -0,0 +1,91 @@
+//
+// Copyright 2023 The Sigstore Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cli
+
+import (
+	"github.com/spf13/cobra"
+
+	"github.com/sigstore/cosign/v2/cmd/cosign/cli/options"
+	"github.com/sigstore/cosign/v2/cmd/cosign/cli/serve"
+)
+
+func Serve() *cobra.Command {
+	o := &options.ServeOptions{}
+
+	cmd := &cobra.Command{
+		Use:   "serve",
+		Short: "Serve the verification of images and their attestations over HTTP",
+		Long: `Serve the verification of images and their attestations over HTTP, e.g. as the
+sidecar of a platform that would otherwise run 'cosign verify' per image.
+
+The key or keyless identity trusted to sign the images, the transparency log
+keys and the Fulcio roots are loaded once at startup, and the registry and
+Rekor clients are shared by every request.
+
+  POST /verify              {"image": "<IMAGE>"}
+  POST /verify-attestation  {"image": "<IMAGE>", "type": "<PREDICATE TYPE>",
+                             "policy": {"type": "cue|rego", "data": "<POLICY>"}}
+
+Both reply with {"image", "digest", "verified", "warnings", "error"}: a 200
+response whose verified is false, with the reason in error, when the image
+fails the verification, and a 4xx response to invalid requests. GET /healthz
+reports that the server is up, and GET /metrics serves the Prometheus metrics
+of the verifications and of the requests sent to the registry and Rekor.
+
+The server doesn't authenticate its clients: restrict who can reach it at the
+network level, e.g. with a network policy or an authenticating proxy. Rego
+policies of requests may not use the builtins reaching the network or the
+environment of the server, http.send, opa.runtime and net.*.
+
+When OTEL_EXPORTER_OTLP_ENDPOINT is set, every request is traced with
+OpenTelemetry, continuing the trace propagated in its headers if any.`,
+		Example: `  cosign serve --key <key path>|<kms uri> [--address <ADDRESS>]
+
+  # serve the verification of images signed with cosign.pub on port 8080
+  cosign serve --key cosign.pub
+
+  # serve over HTTPS the verification of images signed keylessly by a GitHub workflow
+  cosign serve --certificate-identity-regexp '^https://github.com/myorg/' \
+    --certificate-oidc-issuer https://token.actions.githubusercontent.com \
+    --address :8443 --tls-cert-file tls.crt --tls-key-file tls.key
+
+  # verify an image and its SLSA provenance
+  curl -d '{"image": "ghcr.io/myorg/app:v1", "type": "slsaprovenance"}' localhost:8080/verify-attestation`,
+		Args:             cobra.NoArgs,
+		PersistentPreRun: options.BindViper,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			c := &serve.ServeCommand{
+				RegistryOptions:      o.Registry,
+				RekorURL:             o.Rekor.URL,
+				Address:              o.Address,
+				TLSCertFile:          o.TLSCertFile,
+				TLSKeyFile:           o.TLSKeyFile,
+				Key:                  o.Key,
+				CertIdentity:         o.CertIdentity,
+				CertIdentityRegexp:   o.CertIdentityRegexp,
+				CertOidcIssuer:       o.CertOidcIssuer,
+				CertOidcIssuerRegexp: o.CertOidcIssuerRegexp,
+				IgnoreTlog:           o.IgnoreTlog,
+				CacheTTL:             o.CacheTTL,
+			}
+			return c.Exec(cmd.Context())
+		},
+	}
+
+	o.AddFlags(cmd)
+
+	return cmd
+}
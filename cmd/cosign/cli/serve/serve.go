@@ -0,0 +1,276 @@
+//
+// Copyright 2023 The Sigstore Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package serve
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/google/go-containerregistry/pkg/name"
+	"github.com/sigstore/sigstore/pkg/cryptoutils"
+
+	"github.com/sigstore/cosign/v2/cmd/cosign/cli/options"
+	"github.com/sigstore/cosign/v2/cmd/cosign/cli/webhookcli"
+	"github.com/sigstore/cosign/v2/internal/tracing"
+	"github.com/sigstore/cosign/v2/internal/ui"
+	"github.com/sigstore/cosign/v2/pkg/cosign"
+	"github.com/sigstore/cosign/v2/pkg/cosign/rego"
+	sigs "github.com/sigstore/cosign/v2/pkg/signature"
+	"github.com/sigstore/cosign/v2/pkg/webhook"
+)
+
+// maxRequestSize bounds the body of a request, which holds at most an
+// attestation policy.
+const maxRequestSize = 1 << 20
+
+// ServeCommand serves the verification of images and their attestations
+// over HTTP, against the key or keyless identity it was started with.
+type ServeCommand struct {
+	options.RegistryOptions
+	RekorURL             string
+	Address              string
+	TLSCertFile          string
+	TLSKeyFile           string
+	Key                  string
+	CertIdentity         string
+	CertIdentityRegexp   string
+	CertOidcIssuer       string
+	CertOidcIssuerRegexp string
+	IgnoreTlog           bool
+	CacheTTL             time.Duration
+}
+
+// Exec runs the server until it is interrupted.
+func (c *ServeCommand) Exec(ctx context.Context) error {
+	authorities, err := c.authorities(ctx)
+	if err != nil {
+		return err
+	}
+	metrics := webhookcli.NewMetrics()
+	verifier, err := webhookcli.NewVerifier(ctx, c.RegistryOptions, c.RekorURL, c.IgnoreTlog, c.Key == "")
+	if err != nil {
+		return err
+	}
+	var v webhook.ImageVerifier = verifier
+	if c.CacheTTL > 0 {
+		v = webhook.NewCachingVerifier(v, c.CacheTTL, metrics)
+	}
+	s := &Server{Verifier: metrics.Verifier(v), Authorities: authorities, Metrics: metrics}
+	server := &http.Server{
+		Addr:              c.Address,
+		Handler:           tracing.Handler(s.Handler()),
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	ctx, stop := signal.NotifyContext(ctx, os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	errCh := make(chan error, 1)
+	go func() {
+		ui.Infof(ctx, "cosign serve listening on %s", server.Addr)
+		if c.TLSCertFile != "" {
+			errCh <- server.ListenAndServeTLS(c.TLSCertFile, c.TLSKeyFile)
+			return
+		}
+		errCh <- server.ListenAndServe()
+	}()
+	select {
+	case err := <-errCh:
+		return err
+	case <-ctx.Done():
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+		if err := server.Shutdown(shutdownCtx); err != nil {
+			return err
+		}
+		if err := <-errCh; !errors.Is(err, http.ErrServerClosed) {
+			return err
+		}
+		return nil
+	}
+}
+
+// authorities returns the authorities trusted to sign the images, one for
+// each key of --key, loading the keys once so that requests don't reach the
+// KMS.
+func (c *ServeCommand) authorities(ctx context.Context) ([]webhook.Authority, error) {
+	if c.Key != "" {
+		verifier, err := sigs.PublicKeyFromKeyRef(ctx, c.Key)
+		if err != nil {
+			return nil, fmt.Errorf("loading public key: %w", err)
+		}
+		keys, ok := verifier.(cosign.TrustedKeys)
+		if !ok {
+			keys = cosign.TrustedKeys{verifier}
+		}
+		authorities := make([]webhook.Authority, 0, len(keys))
+		for _, k := range keys {
+			pub, err := k.PublicKey()
+			if err != nil {
+				return nil, err
+			}
+			pem, err := cryptoutils.MarshalPublicKeyToPEM(pub)
+			if err != nil {
+				return nil, err
+			}
+			authorities = append(authorities, webhook.Authority{Key: &webhook.KeyRef{Data: string(pem)}})
+		}
+		return authorities, nil
+	}
+	id := webhook.Identity{
+		Issuer:        c.CertOidcIssuer,
+		IssuerRegExp:  c.CertOidcIssuerRegexp,
+		Subject:       c.CertIdentity,
+		SubjectRegExp: c.CertIdentityRegexp,
+	}
+	if (id.Issuer == "" && id.IssuerRegExp == "") || (id.Subject == "" && id.SubjectRegExp == "") {
+		return nil, errors.New("--key, or --certificate-identity or --certificate-identity-regexp and " +
+			"--certificate-oidc-issuer or --certificate-oidc-issuer-regexp, are required")
+	}
+	return []webhook.Authority{{Keyless: &webhook.Keyless{Identities: []webhook.Identity{id}}}}, nil
+}
+
+// Server verifies images signed by one of Authorities, and their attestations. Its
+// Metrics, when set, are served on /metrics.
+type Server struct {
+	Verifier    webhook.ImageVerifier
+	Authorities []webhook.Authority
+	Metrics     *webhook.Metrics
+}
+
+// VerifyRequest is the body of a POST /verify request.
+type VerifyRequest struct {
+	Image string `json:"image"`
+}
+
+// VerifyAttestationRequest is the body of a POST /verify-attestation
+// request. Type is a predicate type as accepted by --type, defaulting to
+// custom, and the attestations of the type must pass Policy, when set.
+type VerifyAttestationRequest struct {
+	Image  string                     `json:"image"`
+	Type   string                     `json:"type,omitempty"`
+	Policy *webhook.AttestationPolicy `json:"policy,omitempty"`
+}
+
+// VerifyResponse is the result of a verification. Images failing the
+// verification are not Verified, with the reason in Error.
+type VerifyResponse struct {
+	Image    string   `json:"image"`
+	Digest   string   `json:"digest,omitempty"`
+	Verified bool     `json:"verified"`
+	Warnings []string `json:"warnings,omitempty"`
+	Error    string   `json:"error,omitempty"`
+}
+
+// Handler returns the handler of the API of s.
+func (s *Server) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/verify", func(w http.ResponseWriter, r *http.Request) {
+		var req VerifyRequest
+		if !decodeRequest(w, r, &req) {
+			return
+		}
+		s.verify(w, r, req.Image, nil)
+	})
+	mux.HandleFunc("/verify-attestation", func(w http.ResponseWriter, r *http.Request) {
+		var req VerifyAttestationRequest
+		if !decodeRequest(w, r, &req) {
+			return
+		}
+		if req.Type == "" {
+			req.Type = "custom"
+		}
+		// The policy comes from an unauthenticated client, it must not
+		// reach the network nor read the environment of the server.
+		if req.Policy != nil && req.Policy.Type == "rego" {
+			if err := rego.CheckUntrustedModule(req.Policy.Data); err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+		}
+		s.verify(w, r, req.Image, []webhook.Attestation{{PredicateType: req.Type, Policy: req.Policy}})
+	})
+	mux.HandleFunc("/healthz", func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+	if s.Metrics != nil {
+		mux.Handle("/metrics", s.Metrics.Handler())
+	}
+	return mux
+}
+
+// decodeRequest decodes the JSON body of the POST request r into v,
+// replying with an error and returning false when it can't.
+func decodeRequest(w http.ResponseWriter, r *http.Request, v interface{}) bool {
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		http.Error(w, "only POST is allowed", http.StatusMethodNotAllowed)
+		return false
+	}
+	dec := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxRequestSize))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(v); err != nil {
+		http.Error(w, fmt.Sprintf("decoding request: %v", err), http.StatusBadRequest)
+		return false
+	}
+	return true
+}
+
+// verify replies with the verification of the signatures of image by the
+// authority of s, and of its attestations.
+func (s *Server) verify(w http.ResponseWriter, r *http.Request, image string, attestations []webhook.Attestation) {
+	spec := &webhook.ImagePolicySpec{
+		Images:       []webhook.ImagePattern{{Glob: "**"}},
+		Authorities:  s.Authorities,
+		Attestations: attestations,
+	}
+	if err := spec.Validate(); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if image == "" {
+		http.Error(w, "image is required", http.StatusBadRequest)
+		return
+	}
+	ref, err := name.ParseReference(image)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	resp := VerifyResponse{Image: image}
+	digest, err := s.Verifier.ResolveDigest(ref)
+	if err == nil {
+		resp.Digest = digest.DigestStr()
+		resp.Warnings, err = s.Verifier.Verify(r.Context(), "", digest, spec)
+	} else {
+		err = fmt.Errorf("resolving digest: %w", err)
+	}
+	if err != nil {
+		resp.Error = err.Error()
+	} else {
+		resp.Verified = true
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(resp)
+}
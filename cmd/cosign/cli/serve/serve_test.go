@@ -0,0 +1,217 @@
+//
+// Copyright 2023 The Sigstore Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package serve
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/google/go-containerregistry/pkg/name"
+	"github.com/sigstore/sigstore/pkg/cryptoutils"
+
+	"github.com/sigstore/cosign/v2/pkg/webhook"
+)
+
+const testDigest = "sha256:0000000000000000000000000000000000000000000000000000000000000000"
+
+// fakeVerifier accepts the repositories in signed, and the attestations of
+// the predicate types in attested.
+type fakeVerifier struct {
+	signed   map[string]bool
+	attested map[string]bool
+}
+
+func (v *fakeVerifier) ResolveDigest(ref name.Reference) (name.Digest, error) {
+	return ref.Context().Digest(testDigest), nil
+}
+
+func (v *fakeVerifier) Verify(_ context.Context, _ string, digest name.Digest, spec *webhook.ImagePolicySpec) ([]string, error) {
+	if !v.signed[digest.Context().Name()] {
+		return nil, errors.New("no matching signatures")
+	}
+	for _, a := range spec.Attestations {
+		if !v.attested[a.PredicateType] {
+			return nil, errors.New("no matching attestations")
+		}
+	}
+	return nil, nil
+}
+
+func TestServer(t *testing.T) {
+	s := &Server{
+		Verifier: &fakeVerifier{
+			signed:   map[string]bool{"ghcr.io/example/signed": true},
+			attested: map[string]bool{"slsaprovenance": true},
+		},
+		Authorities: []webhook.Authority{{Key: &webhook.KeyRef{Data: "pem"}}},
+	}
+	server := httptest.NewServer(s.Handler())
+	defer server.Close()
+
+	tests := []struct {
+		name       string
+		path       string
+		body       string
+		wantStatus int
+		want       VerifyResponse
+	}{{
+		name:       "signed",
+		path:       "/verify",
+		body:       `{"image": "ghcr.io/example/signed:v1"}`,
+		wantStatus: http.StatusOK,
+		want:       VerifyResponse{Image: "ghcr.io/example/signed:v1", Digest: testDigest, Verified: true},
+	}, {
+		name:       "unsigned",
+		path:       "/verify",
+		body:       `{"image": "ghcr.io/example/unsigned:v1"}`,
+		wantStatus: http.StatusOK,
+		want:       VerifyResponse{Image: "ghcr.io/example/unsigned:v1", Digest: testDigest, Error: "no matching signatures"},
+	}, {
+		name:       "attested",
+		path:       "/verify-attestation",
+		body:       `{"image": "ghcr.io/example/signed:v1", "type": "slsaprovenance"}`,
+		wantStatus: http.StatusOK,
+		want:       VerifyResponse{Image: "ghcr.io/example/signed:v1", Digest: testDigest, Verified: true},
+	}, {
+		name:       "default attestation type",
+		path:       "/verify-attestation",
+		body:       `{"image": "ghcr.io/example/signed:v1"}`,
+		wantStatus: http.StatusOK,
+		want:       VerifyResponse{Image: "ghcr.io/example/signed:v1", Digest: testDigest, Error: "no matching attestations"},
+	}, {
+		name:       "invalid policy type",
+		path:       "/verify-attestation",
+		body:       `{"image": "ghcr.io/example/signed:v1", "policy": {"type": "yaml", "data": ""}}`,
+		wantStatus: http.StatusBadRequest,
+	}, {
+		name:       "rego policy calling http.send",
+		path:       "/verify-attestation",
+		body:       `{"image": "ghcr.io/example/signed:v1", "type": "slsaprovenance", "policy": {"type": "rego", "data": "package sigstore\nisCompliant { http.send({\"method\": \"get\", \"url\": \"http://169.254.169.254/\"}).status_code == 200 }"}}`,
+		wantStatus: http.StatusBadRequest,
+	}, {
+		name:       "rego policy",
+		path:       "/verify-attestation",
+		body:       `{"image": "ghcr.io/example/signed:v1", "type": "slsaprovenance", "policy": {"type": "rego", "data": "package sigstore\nisCompliant { true }"}}`,
+		wantStatus: http.StatusOK,
+		want:       VerifyResponse{Image: "ghcr.io/example/signed:v1", Digest: testDigest, Verified: true},
+	}, {
+		name:       "missing image",
+		path:       "/verify",
+		body:       `{}`,
+		wantStatus: http.StatusBadRequest,
+	}, {
+		name:       "invalid image",
+		path:       "/verify",
+		body:       `{"image": "ghcr.io/Example:v1"}`,
+		wantStatus: http.StatusBadRequest,
+	}, {
+		name:       "unknown field",
+		path:       "/verify",
+		body:       `{"image": "ghcr.io/example/signed:v1", "key": "cosign.pub"}`,
+		wantStatus: http.StatusBadRequest,
+	}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp, err := http.Post(server.URL+tt.path, "application/json", strings.NewReader(tt.body))
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer resp.Body.Close()
+			if resp.StatusCode != tt.wantStatus {
+				t.Fatalf("status = %d, want %d", resp.StatusCode, tt.wantStatus)
+			}
+			if resp.StatusCode != http.StatusOK {
+				return
+			}
+			var got VerifyResponse
+			if err := json.NewDecoder(resp.Body).Decode(&got); err != nil {
+				t.Fatal(err)
+			}
+			if got.Image != tt.want.Image || got.Digest != tt.want.Digest || got.Verified != tt.want.Verified || got.Error != tt.want.Error {
+				t.Errorf("response = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+
+	resp, err := http.Get(server.URL + "/verify")
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusMethodNotAllowed {
+		t.Errorf("GET /verify status = %d, want %d", resp.StatusCode, http.StatusMethodNotAllowed)
+	}
+}
+
+func TestAuthorities(t *testing.T) {
+	c := &ServeCommand{CertIdentity: "me@example.com"}
+	if _, err := c.authorities(context.Background()); err == nil {
+		t.Error("authorities() without an OIDC issuer succeeded")
+	}
+
+	c.CertOidcIssuer = "https://accounts.google.com"
+	a, err := c.authorities(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := webhook.Identity{Issuer: "https://accounts.google.com", Subject: "me@example.com"}
+	if len(a) != 1 || a[0].Keyless == nil || len(a[0].Keyless.Identities) != 1 || a[0].Keyless.Identities[0] != want {
+		t.Errorf("authorities() = %+v, want keyless identity %+v", a, want)
+	}
+}
+
+func TestAuthoritiesTrustedKeys(t *testing.T) {
+	var pems []string
+	for i := 0; i < 2; i++ {
+		priv, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+		if err != nil {
+			t.Fatal(err)
+		}
+		pem, err := cryptoutils.MarshalPublicKeyToPEM(priv.Public())
+		if err != nil {
+			t.Fatal(err)
+		}
+		pems = append(pems, string(pem))
+	}
+	keyFile := filepath.Join(t.TempDir(), "keys.pub")
+	if err := os.WriteFile(keyFile, []byte(strings.Join(pems, "")), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	c := &ServeCommand{Key: keyFile}
+	a, err := c.authorities(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(a) != len(pems) {
+		t.Fatalf("authorities() returned %d authorities, want one for each of the %d keys", len(a), len(pems))
+	}
+	for i, pem := range pems {
+		if a[i].Key == nil || a[i].Key.Data != pem {
+			t.Errorf("authorities()[%d] = %+v, want key %q", i, a[i], pem)
+		}
+	}
+}
@@ -24,6 +24,7 @@ import (
 	"github.com/sigstore/cosign/v2/cmd/cosign/cli/generate"
 	"github.com/sigstore/cosign/v2/cmd/cosign/cli/options"
 	"github.com/sigstore/cosign/v2/cmd/cosign/cli/sign"
+	ociremote "github.com/sigstore/cosign/v2/pkg/oci/remote"
 )
 
 func Sign() *cobra.Command {
@@ -43,6 +44,23 @@ race conditions or (worse) malicious tampering.
   # sign a container image with the Sigstore OIDC flow
   cosign sign <IMAGE DIGEST>
 
+  # sign a container image on a headless machine, logging in from another device
+  cosign sign --oidc-flow device <IMAGE DIGEST>
+
+  # sign a container image with an ID token of the CI OIDC provider, without prompting
+  cosign sign --oidc-flow token --identity-token "$(cat /var/run/sigstore/token)" <IMAGE DIGEST>
+
+  # sign a container image from a workload with its SPIFFE ID, using a JWT-SVID of the SPIRE agent as ID token
+  cosign sign --spiffe-socket unix:///run/spire/sockets/agent.sock <IMAGE DIGEST>
+
+  # sign a container image with a private Fulcio and its OIDC issuer, e.g. Dex or Keycloak, configured by the COSIGN_ equivalents of the flags
+  COSIGN_FULCIO_URL=https://fulcio.example.com COSIGN_OIDC_ISSUER=https://keycloak.example.com/realms/sigstore \
+    COSIGN_OIDC_CLIENT_ID=cosign COSIGN_OIDC_CLIENT_SECRET=<secret> cosign sign <IMAGE DIGEST>
+
+  # sign many container images with a single OIDC login, reusing the ephemeral key and certificate until the certificate expires
+  cosign sign --output-key ephemeral.key --output-certificate ephemeral.crt <IMAGE DIGEST>
+  cosign sign --key ephemeral.key --certificate ephemeral.crt <OTHER IMAGE DIGEST>
+
   # sign a container image with a local key pair file
   cosign sign --key cosign.key <IMAGE DIGEST>
 
@@ -52,6 +70,9 @@ race conditions or (worse) malicious tampering.
   # sign a container image and add annotations
   cosign sign --key cosign.key -a key1=value1 -a key2=value2 <IMAGE DIGEST>
 
+  # sign a container image with the annotations of a file and the git commit, CI run and builder of its build
+  cosign sign --key cosign.key --annotations-file annotations.yaml --auto-annotate <IMAGE DIGEST>
+
   # sign a container image with a key stored in an environment variable
   cosign sign --key env://[ENV_VAR] <IMAGE DIGEST>
 
@@ -70,6 +91,10 @@ race conditions or (worse) malicious tampering.
   # sign a container image with a key pair stored in a Kubernetes secret
   cosign sign --key k8s://[NAMESPACE]/[KEY] <IMAGE DIGEST>
 
+  # sign a container image with an OpenSSH private key, or a key held by the SSH agent
+  cosign sign --key ~/.ssh/id_ed25519 <IMAGE DIGEST>
+  cosign sign --key ssh-agent://[SHA256 FINGERPRINT|COMMENT] <IMAGE DIGEST>
+
   # sign a container image with a key, attaching a certificate and certificate chain
   cosign sign --key cosign.key --cert cosign.crt --cert-chain chain.crt <IMAGE DIGEST>
 
@@ -83,7 +108,19 @@ race conditions or (worse) malicious tampering.
   cosign sign --key cosign.key --tlog-upload=false <IMAGE DIGEST>
 
   # sign a container image by manually setting the container image identity
-  cosign sign --sign-container-identity <NEW IMAGE DIGEST> <IMAGE DIGEST>`,
+  cosign sign --sign-container-identity <NEW IMAGE DIGEST> <IMAGE DIGEST>
+
+  # sign an OCI artifact other than a container image, e.g. a Helm chart or WASM module
+  cosign sign --key cosign.key <ARTIFACT DIGEST>
+
+  # counter-sign the payload signed by the builder of a release, e.g. as the QA team,
+  # then verify both signed it with a threshold policy listing the builder and QA
+  cosign sign --key qa.key --attach-to-existing <IMAGE DIGEST>
+  cosign verify --threshold-policy release-policy.yaml <IMAGE DIGEST>
+
+  # sign an image saved in a local OCI layout without network access, then push both
+  cosign sign --key cosign.key --tlog-upload=false --local-image --sign-container-identity <IMAGE> <LAYOUT DIR>
+  cosign load --dir <LAYOUT DIR> <IMAGE>`,
 
 		Args:             cobra.MinimumNArgs(1),
 		PersistentPreRun: options.BindViper,
@@ -94,7 +131,12 @@ race conditions or (worse) malicious tampering.
 			case "":
 				break
 			default:
-				return fmt.Errorf("specified image attachment %s not specified. Can be 'sbom'", o.Attachment)
+				if _, err := ociremote.AttachmentTagSuffix(o.Attachment); err != nil {
+					return err
+				}
+			}
+			if o.NoUpload {
+				o.Upload = false
 			}
 			oidcClientSecret, err := o.OIDC.ClientSecret()
 			if err != nil {
@@ -113,7 +155,11 @@ race conditions or (worse) malicious tampering.
 				OIDCClientID:                   o.OIDC.ClientID,
 				OIDCClientSecret:               oidcClientSecret,
 				OIDCRedirectURL:                o.OIDC.RedirectURL,
+				OIDCScopes:                     o.OIDC.Scopes,
 				OIDCDisableProviders:           o.OIDC.DisableAmbientProviders,
+				FulcioAuthFlow:                 o.OIDC.Flow,
+				SPIFFESocket:                   o.OIDC.SPIFFESocket,
+				OutputKey:                      o.OutputKey,
 				OIDCProvider:                   o.OIDC.Provider,
 				SkipConfirmation:               o.SkipConfirmation,
 				TSAClientCACert:                o.TSAClientCACert,
@@ -20,19 +20,23 @@ import (
 	"context"
 	"crypto"
 	"crypto/x509"
+	"encoding/json"
 	"encoding/pem"
 	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
 	"strings"
+	"time"
 
 	"github.com/google/go-containerregistry/pkg/name"
 	v1 "github.com/google/go-containerregistry/pkg/v1"
 	"github.com/google/go-containerregistry/pkg/v1/remote"
+	"github.com/nozzle/throttler"
 
 	"github.com/sigstore/cosign/v2/cmd/cosign/cli/fulcio"
 	"github.com/sigstore/cosign/v2/cmd/cosign/cli/fulcio/fulcioverifier"
+	"github.com/sigstore/cosign/v2/cmd/cosign/cli/generate"
 	"github.com/sigstore/cosign/v2/cmd/cosign/cli/options"
 	"github.com/sigstore/cosign/v2/cmd/cosign/cli/rekor"
 	"github.com/sigstore/cosign/v2/cmd/cosign/cli/sign/privacy"
@@ -48,22 +52,28 @@ import (
 	"github.com/sigstore/cosign/v2/pkg/cosign/pkcs11key"
 	cremote "github.com/sigstore/cosign/v2/pkg/cosign/remote"
 	"github.com/sigstore/cosign/v2/pkg/oci"
+	"github.com/sigstore/cosign/v2/pkg/oci/layout"
 	"github.com/sigstore/cosign/v2/pkg/oci/mutate"
 	ociremote "github.com/sigstore/cosign/v2/pkg/oci/remote"
 	"github.com/sigstore/cosign/v2/pkg/oci/walk"
 	sigs "github.com/sigstore/cosign/v2/pkg/signature"
+	rekorclient "github.com/sigstore/rekor/pkg/generated/client"
 	"github.com/sigstore/sigstore/pkg/cryptoutils"
 	"github.com/sigstore/sigstore/pkg/signature"
 	signatureoptions "github.com/sigstore/sigstore/pkg/signature/options"
 	sigPayload "github.com/sigstore/sigstore/pkg/signature/payload"
+	"sigs.k8s.io/yaml"
 
 	// Loads OIDC providers
 	_ "github.com/sigstore/cosign/v2/pkg/providers/all"
 )
 
+// statementErr is the answer to the privacy statement confirmation. It is
+// kept for the whole run as images may be signed concurrently.
+var statementErr error
+
 func ShouldUploadToTlog(ctx context.Context, ko options.KeyOpts, ref name.Reference, tlogUpload bool) (bool, error) {
 	upload := shouldUploadToTlog(ctx, ko, ref, tlogUpload)
-	var statementErr error
 	if upload {
 		privacy.StatementOnce.Do(func() {
 			ui.Infof(ctx, privacy.Statement)
@@ -104,14 +114,28 @@ func shouldUploadToTlog(ctx context.Context, ko options.KeyOpts, ref name.Refere
 	return true
 }
 
+// AttachedToAnnotationKey is the annotation recording, in the signatures of
+// an attachment such as an SBOM, the digest of the image it is attached to,
+// so that the signed attachment can't be passed off as the one of another
+// image.
+const AttachedToAnnotationKey = "dev.sigstore.cosign/attached-to"
+
+// attachedToAnnotations returns annotations along with the
+// AttachedToAnnotationKey of digest.
+func attachedToAnnotations(annotations map[string]interface{}, digest name.Digest) map[string]interface{} {
+	withDigest := make(map[string]interface{}, len(annotations)+1)
+	for k, v := range annotations {
+		withDigest[k] = v
+	}
+	withDigest[AttachedToAnnotationKey] = digest.String()
+	return withDigest
+}
+
 func GetAttachedImageRef(ref name.Reference, attachment string, opts ...ociremote.Option) (name.Reference, error) {
 	if attachment == "" {
 		return ref, nil
 	}
-	if attachment == "sbom" {
-		return ociremote.SBOMTag(ref, opts...)
-	}
-	return nil, fmt.Errorf("unknown attachment type %s", attachment)
+	return ociremote.AttachmentTag(ref, attachment, opts...)
 }
 
 // ParseOCIReference parses a string reference to an OCI image into a reference, warning if the reference did not include a digest.
@@ -162,16 +186,58 @@ func SignCmd(ro *options.RootOptions, ko options.KeyOpts, signOpts options.SignO
 	if err != nil {
 		return fmt.Errorf("constructing client options: %w", err)
 	}
-	am, err := signOpts.AnnotationsMap()
+	annotations, err := signAnnotations(signOpts)
 	if err != nil {
 		return fmt.Errorf("getting annotations: %w", err)
 	}
-	annotations := am.Annotations
-	for _, inputImg := range imgs {
+	if signOpts.AttachToExisting && (len(annotations) > 0 || signOpts.SignContainerIdentity != "") {
+		return errors.New("--attach-to-existing signs the existing payload, it cannot be used with annotations or --sign-container-identity")
+	}
+	// Sign every image with the same client, so that the connections to the
+	// transparency log are reused.
+	var rClient *rekorclient.Rekor
+	if signOpts.TlogUpload {
+		if rClient, err = rekor.NewClient(ko.RekorURL); err != nil {
+			return err
+		}
+	}
+	if signOpts.LocalImage {
+		if signOpts.Recursive || signOpts.Attachment != "" {
+			return errors.New("--local-image cannot be used with --recursive or --attachment")
+		}
+		var signed []signedDigest
+		for _, path := range imgs {
+			sd, err := signLocalImage(ctx, path, staticPayload, ko, signOpts, annotations, dd, sv, rClient)
+			if err != nil {
+				return fmt.Errorf("signing local image %s: %w", path, err)
+			}
+			signed = append(signed, sd)
+		}
+		printSignSummary(ctx, signed)
+		return nil
+	}
+
+	workers := signOpts.MaxWorkers
+	if ko.Sk || strings.HasPrefix(ko.KeyRef, "pkcs11:") {
+		// Hardware tokens sign one payload at a time.
+		workers = 1
+	}
+	signed := make([][]signedDigest, len(imgs))
+	err = signConcurrently(ctx, len(imgs), workers, func(ctx context.Context, i int) error {
+		inputImg := imgs[i]
 		ref, err := ParseOCIReference(ctx, inputImg, regOpts.NameOptions()...)
 		if err != nil {
 			return err
 		}
+		imgAnnotations := annotations
+		if signOpts.Attachment != "" {
+			digest, err := ociremote.ResolveDigest(ref, opts...)
+			if err != nil {
+				return fmt.Errorf("resolving digest of %s: %w", inputImg, err)
+			}
+			imgAnnotations = attachedToAnnotations(annotations, digest)
+			ref = digest
+		}
 		ref, err = GetAttachedImageRef(ref, signOpts.Attachment, opts...)
 		if err != nil {
 			return fmt.Errorf("unable to resolve attachment %s for image %s", signOpts.Attachment, inputImg)
@@ -184,11 +250,12 @@ func SignCmd(ro *options.RootOptions, ko options.KeyOpts, signOpts options.SignO
 			} else if err != nil {
 				return fmt.Errorf("accessing image: %w", err)
 			}
-			err = signDigest(ctx, digest, staticPayload, ko, signOpts, annotations, dd, sv, se)
+			sd, err := signDigest(ctx, digest, staticPayload, ko, signOpts, imgAnnotations, dd, sv, rClient, se, "")
 			if err != nil {
 				return fmt.Errorf("signing digest: %w", err)
 			}
-			continue
+			signed[i] = append(signed[i], sd)
+			return nil
 		}
 
 		se, err := ociremote.SignedEntity(ref, opts...)
@@ -203,23 +270,175 @@ func SignCmd(ro *options.RootOptions, ko options.KeyOpts, signOpts options.SignO
 				return fmt.Errorf("computing digest: %w", err)
 			}
 			digest := ref.Context().Digest(d.String())
-			err = signDigest(ctx, digest, staticPayload, ko, signOpts, annotations, dd, sv, se)
+			sd, err := signDigest(ctx, digest, staticPayload, ko, signOpts, imgAnnotations, dd, sv, rClient, se, "")
 			if err != nil {
 				return fmt.Errorf("signing digest: %w", err)
 			}
+			signed[i] = append(signed[i], sd)
 			return ErrDone
 		}); err != nil {
 			return fmt.Errorf("recursively signing: %w", err)
 		}
+		return nil
+	})
+	if err != nil {
+		return err
 	}
 
+	var all []signedDigest
+	for _, sds := range signed {
+		all = append(all, sds...)
+	}
+	printSignSummary(ctx, all)
 	return nil
 }
 
+// signedDigest is a digest signed by SignCmd with its signature.
+type signedDigest struct {
+	digest name.Digest
+	sig    oci.Signature
+}
+
+// signConcurrently calls sign for each of the n images with at most workers
+// at once. The first failure cancels the images not signed yet.
+// printAttachHint tells how to attach the signature of digest written to
+// sigPath, e.g. from another system than the one that signed it.
+func printAttachHint(ctx context.Context, digest name.Digest, sigPath, payloadPath, certPath string) {
+	if payloadPath == "" {
+		ui.Warnf(ctx, "The signed payload of %s is not written, set --output-payload to attach the signature later", digest)
+		return
+	}
+	args := []string{"--signature", sigPath, "--payload", payloadPath}
+	if certPath != "" {
+		args = append(args, "--certificate", certPath)
+	}
+	ui.Infof(ctx, "Attach the signature with: cosign attach signature %s %s", strings.Join(args, " "), digest)
+}
+
+// signAnnotations returns the annotations to sign: those describing the
+// build with --auto-annotate, overridden by those of --annotations-file, then
+// by those of --annotations.
+func signAnnotations(signOpts options.SignOptions) (map[string]interface{}, error) {
+	annotations := map[string]interface{}{}
+	if signOpts.AutoAnnotate {
+		build, err := generate.ProvenanceAnnotations()
+		if err != nil {
+			return nil, fmt.Errorf("--auto-annotate: %w", err)
+		}
+		for k, v := range build {
+			annotations[k] = v
+		}
+	}
+	if signOpts.AnnotationsFile != "" {
+		b, err := os.ReadFile(filepath.Clean(signOpts.AnnotationsFile))
+		if err != nil {
+			return nil, fmt.Errorf("reading annotations file: %w", err)
+		}
+		// Annotations are strings, as --annotations matches them when
+		// verifying.
+		var file map[string]string
+		if err := yaml.Unmarshal(b, &file); err != nil {
+			return nil, fmt.Errorf("parsing annotations file %s, expected string values: %w", signOpts.AnnotationsFile, err)
+		}
+		for k, v := range file {
+			annotations[k] = v
+		}
+	}
+	am, err := signOpts.AnnotationsMap()
+	if err != nil {
+		return nil, err
+	}
+	for k, v := range am.Annotations {
+		annotations[k] = v
+	}
+	if len(annotations) == 0 {
+		return nil, nil
+	}
+	return annotations, nil
+}
+
+func signConcurrently(ctx context.Context, n, workers int, sign func(ctx context.Context, i int) error) error {
+	if workers <= 0 {
+		workers = icos.DefaultMaxWorkers
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	errs := make([]error, n)
+	t := throttler.New(workers, n)
+	for i := 0; i < n; i++ {
+		go func(i int) {
+			if ctx.Err() != nil {
+				// Another image failed.
+				t.Done(nil)
+				return
+			}
+			err := sign(ctx, i)
+			if err != nil && !(errors.Is(err, context.Canceled) && ctx.Err() != nil) {
+				errs[i] = err
+				cancel()
+			}
+			t.Done(err)
+		}(i)
+
+		// wait till workers are available
+		t.Throttle()
+	}
+	return errors.Join(errs...)
+}
+
+// printSignSummary logs the transparency log entry of each signature.
+func printSignSummary(ctx context.Context, signed []signedDigest) {
+	if len(signed) == 0 {
+		return
+	}
+	ui.Infof(ctx, "\nSigned %d digest(s):", len(signed))
+	for _, sd := range signed {
+		b, err := sd.sig.Bundle()
+		if err != nil || b == nil {
+			ui.Infof(ctx, "  %s: not uploaded to the transparency log", sd.digest)
+			continue
+		}
+		ui.Infof(ctx, "  %s: transparency log index %d", sd.digest, b.Payload.LogIndex)
+	}
+}
+
+// signLocalImage signs the image or image index saved in the OCI layout at
+// path and stores the signature in the layout.
+func signLocalImage(ctx context.Context, path string, payload []byte, ko options.KeyOpts, signOpts options.SignOptions,
+	annotations map[string]interface{},
+	dd mutate.DupeDetector, sv *SignerVerifier, rClient *rekorclient.Rekor) (signedDigest, error) {
+	// The payload names the repository the image is pushed to later on,
+	// which a local image cannot tell.
+	if signOpts.SignContainerIdentity == "" {
+		return signedDigest{}, errors.New("--sign-container-identity must name the repository the image will be pushed to")
+	}
+	repo, err := name.NewRepository(signOpts.SignContainerIdentity, signOpts.Registry.NameOptions()...)
+	if err != nil {
+		return signedDigest{}, fmt.Errorf("parsing --sign-container-identity: %w", err)
+	}
+	se, err := layout.SignedEntity(path)
+	if err != nil {
+		return signedDigest{}, fmt.Errorf("reading OCI layout: %w", err)
+	}
+	h, err := se.Digest()
+	if err != nil {
+		return signedDigest{}, fmt.Errorf("computing digest: %w", err)
+	}
+	return signDigest(ctx, repo.Digest(h.String()), payload, ko, signOpts, annotations, dd, sv, rClient, se, path)
+}
+
+// signDigest signs digest and attaches the signature to se, which is then
+// saved to the OCI layout at layoutPath if set, or pushed to the registry.
 func signDigest(ctx context.Context, digest name.Digest, payload []byte, ko options.KeyOpts, signOpts options.SignOptions,
 	annotations map[string]interface{},
-	dd mutate.DupeDetector, sv *SignerVerifier, se oci.SignedEntity) error {
+	dd mutate.DupeDetector, sv *SignerVerifier, rClient *rekorclient.Rekor, se oci.SignedEntity, layoutPath string) (signedDigest, error) {
 	var err error
+	if signOpts.AttachToExisting {
+		if payload, err = existingPayload(digest, se); err != nil {
+			return signedDigest{}, err
+		}
+	}
 	// The payload can be passed to skip generation.
 	if len(payload) == 0 {
 		payload, err = (&sigPayload.Cosign{
@@ -228,7 +447,7 @@ func signDigest(ctx context.Context, digest name.Digest, payload []byte, ko opti
 			Annotations:     annotations,
 		}).MarshalJSON()
 		if err != nil {
-			return fmt.Errorf("payload: %w", err)
+			return signedDigest{}, fmt.Errorf("payload: %w", err)
 		}
 	}
 
@@ -252,24 +471,30 @@ func signDigest(ctx context.Context, digest name.Digest, payload []byte, ko opti
 	}
 	shouldUpload, err := ShouldUploadToTlog(ctx, ko, digest, signOpts.TlogUpload)
 	if err != nil {
-		return fmt.Errorf("should upload to tlog: %w", err)
+		return signedDigest{}, fmt.Errorf("should upload to tlog: %w", err)
 	}
 	if shouldUpload {
-		rClient, err := rekor.NewClient(ko.RekorURL)
-		if err != nil {
-			return err
+		if rClient == nil {
+			if rClient, err = rekor.NewClient(ko.RekorURL); err != nil {
+				return signedDigest{}, err
+			}
 		}
 		s = irekor.NewSigner(s, rClient)
 	}
 
 	ociSig, _, err := s.Sign(ctx, bytes.NewReader(payload))
 	if err != nil {
-		return err
+		return signedDigest{}, err
+	}
+	if sv.KeyAttestation != nil {
+		if ociSig, err = withKeyAttestation(ociSig, sv.KeyAttestation); err != nil {
+			return signedDigest{}, err
+		}
 	}
 
 	b64sig, err := ociSig.Base64Signature()
 	if err != nil {
-		return err
+		return signedDigest{}, err
 	}
 
 	outputSignature := signOpts.OutputSignature
@@ -279,7 +504,7 @@ func signDigest(ctx context.Context, digest name.Digest, payload []byte, ko opti
 			outputSignature = fmt.Sprintf("%s-%s", outputSignature, strings.Replace(digest.DigestStr(), ":", "-", 1))
 		}
 		if err := os.WriteFile(outputSignature, []byte(b64sig), 0600); err != nil {
-			return fmt.Errorf("create signature file: %w", err)
+			return signedDigest{}, fmt.Errorf("create signature file: %w", err)
 		}
 	}
 	outputPayload := signOpts.OutputPayload
@@ -289,37 +514,46 @@ func signDigest(ctx context.Context, digest name.Digest, payload []byte, ko opti
 			outputPayload = fmt.Sprintf("%s-%s", outputPayload, strings.Replace(digest.DigestStr(), ":", "-", 1))
 		}
 		if err := os.WriteFile(outputPayload, payload, 0600); err != nil {
-			return fmt.Errorf("create payload file: %w", err)
+			return signedDigest{}, fmt.Errorf("create payload file: %w", err)
 		}
 	}
 
 	if signOpts.OutputCertificate != "" {
 		rekorBytes, err := sv.Bytes(ctx)
 		if err != nil {
-			return fmt.Errorf("create certificate file: %w", err)
+			return signedDigest{}, fmt.Errorf("create certificate file: %w", err)
 		}
 
 		if err := os.WriteFile(signOpts.OutputCertificate, rekorBytes, 0600); err != nil {
-			return fmt.Errorf("create certificate file: %w", err)
+			return signedDigest{}, fmt.Errorf("create certificate file: %w", err)
 		}
 		// TODO: maybe accept a --b64 flag as well?
 		ui.Infof(ctx, "Certificate wrote in the file %s", signOpts.OutputCertificate)
 	}
 
+	signed := signedDigest{digest: digest, sig: ociSig}
 	if !signOpts.Upload {
-		return nil
+		if outputSignature != "" {
+			printAttachHint(ctx, digest, outputSignature, outputPayload, signOpts.OutputCertificate)
+		}
+		return signed, nil
 	}
 
 	// Attach the signature to the entity.
 	newSE, err := mutate.AttachSignatureToEntity(se, ociSig, mutate.WithDupeDetector(dd))
 	if err != nil {
-		return err
+		return signedDigest{}, err
+	}
+
+	if layoutPath != "" {
+		ui.Infof(ctx, "Saving signature to: %s", layoutPath)
+		return signed, layout.WriteSignatures(layoutPath, newSE)
 	}
 
 	// Publish the signatures associated with this entity
 	walkOpts, err := signOpts.Registry.ClientOpts(ctx)
 	if err != nil {
-		return fmt.Errorf("constructing client options: %w", err)
+		return signedDigest{}, fmt.Errorf("constructing client options: %w", err)
 	}
 
 	// Check if we are overriding the signatures repository location
@@ -332,11 +566,61 @@ func signDigest(ctx context.Context, digest name.Digest, payload []byte, ko opti
 
 	// Publish the signatures associated with this entity (using OCI 1.1+ behavior)
 	if signOpts.RegistryExperimental.RegistryReferrersMode == options.RegistryReferrersModeOCI11 {
-		return ociremote.WriteSignaturesExperimentalOCI(digest, newSE, walkOpts...)
+		return signed, ociremote.WriteSignaturesExperimentalOCI(digest, newSE, walkOpts...)
 	}
 
 	// Publish the signatures associated with this entity
-	return ociremote.WriteSignatures(digest.Repository, newSE, walkOpts...)
+	return signed, ociremote.WriteSignatures(digest.Repository, newSE, walkOpts...)
+}
+
+// existingPayload returns the payload signed by the existing signatures of
+// se, whose digest is digest, to counter-sign it. The signatures must all
+// sign the same payload, claiming digest.
+func existingPayload(digest name.Digest, se oci.SignedEntity) ([]byte, error) {
+	sigs, err := se.Signatures()
+	if err != nil {
+		return nil, fmt.Errorf("fetching the signatures of %s: %w", digest, err)
+	}
+	existing, err := sigs.Get()
+	if err != nil {
+		return nil, fmt.Errorf("fetching the signatures of %s: %w", digest, err)
+	}
+	var payload []byte
+	for _, sig := range existing {
+		p, err := sig.Payload()
+		if err != nil {
+			return nil, fmt.Errorf("fetching payload: %w", err)
+		}
+		if payload != nil {
+			if !bytes.Equal(p, payload) {
+				return nil, fmt.Errorf("the signatures of %s sign different payloads, sign the one to counter-sign with --payload instead", digest)
+			}
+			continue
+		}
+		var sci sigPayload.SimpleContainerImage
+		if err := json.Unmarshal(p, &sci); err != nil {
+			return nil, fmt.Errorf("parsing the payload of an existing signature: %w", err)
+		}
+		if sci.Critical.Image.DockerManifestDigest != digest.DigestStr() {
+			return nil, fmt.Errorf("the existing signature of %s claims the digest %s", digest, sci.Critical.Image.DockerManifestDigest)
+		}
+		payload = p
+	}
+	if payload == nil {
+		return nil, fmt.Errorf("%s has no existing signature to counter-sign", digest)
+	}
+	return payload, nil
+}
+
+// withKeyAttestation adds the PIV key attestation of the signing key to the
+// annotations of sig.
+func withKeyAttestation(sig oci.Signature, attestation []byte) (oci.Signature, error) {
+	annotations, err := sig.Annotations()
+	if err != nil {
+		return nil, err
+	}
+	annotations[cosign.PIVAttestationAnnotationKey] = string(attestation)
+	return mutate.Signature(sig, mutate.WithAnnotations(annotations))
 }
 
 func signerFromSecurityKey(ctx context.Context, keySlot string) (*SignerVerifier, error) {
@@ -368,11 +652,35 @@ func signerFromSecurityKey(ctx context.Context, keySlot string) (*SignerVerifier
 
 	return &SignerVerifier{
 		Cert:           pemBytes,
+		KeyAttestation: pivKeyAttestation(ctx, sk),
 		SignerVerifier: sv,
 		close:          sk.Close,
 	}, nil
 }
 
+// pivKeyAttestation returns the PEM encoded key and device attestation
+// certificates of the key of sk, so that verifiers can require it to have
+// been generated on the token. Tokens that can't attest their keys are
+// reported with a warning.
+func pivKeyAttestation(ctx context.Context, sk *pivkey.Key) []byte {
+	keyCert, err := sk.Attest()
+	if err != nil {
+		ui.Warnf(ctx, "no key attestation retrieved from the PIV token: %v", err)
+		return nil
+	}
+	deviceCert, err := sk.GetAttestationCertificate()
+	if err != nil {
+		ui.Warnf(ctx, "no device attestation certificate retrieved from the PIV token: %v", err)
+		return nil
+	}
+	pemBytes, err := cryptoutils.MarshalCertificatesToPEM([]*x509.Certificate{keyCert, deviceCert})
+	if err != nil {
+		ui.Warnf(ctx, "encoding the PIV key attestation: %v", err)
+		return nil
+	}
+	return pemBytes
+}
+
 func signerFromKeyRef(ctx context.Context, certPath, certChainPath, keyRef string, passFunc cosign.PassFunc) (*SignerVerifier, error) {
 	k, err := sigs.SignerVerifierFromKeyRef(ctx, keyRef, passFunc)
 	if err != nil {
@@ -434,6 +742,10 @@ func signerFromKeyRef(ctx context.Context, certPath, certChainPath, keyRef strin
 		if err != nil {
 			return nil, fmt.Errorf("parse x509 certificate: %w", err)
 		}
+		// Reusing a short-lived certificate is only valid until it expires.
+		if time.Now().After(parsedCert.NotAfter) {
+			return nil, fmt.Errorf("certificate %s expired at %s", certPath, parsedCert.NotAfter.Format(time.RFC3339))
+		}
 		pk, err := k.PublicKey()
 		if err != nil {
 			return nil, fmt.Errorf("get public key: %w", err)
@@ -515,9 +827,25 @@ func signerFromNewKey() (*SignerVerifier, error) {
 
 	return &SignerVerifier{
 		SignerVerifier: sv,
+		ephemeralKey:   privKey,
 	}, nil
 }
 
+// writeEphemeralKey writes key to path, encrypted with the password of pf,
+// so that its certificate can be reused with --key and --certificate until
+// it expires.
+func writeEphemeralKey(ctx context.Context, path string, key crypto.Signer, pf cosign.PassFunc) error {
+	keys, err := cosign.EncryptPrivateKey(key, pf)
+	if err != nil {
+		return fmt.Errorf("encrypting ephemeral key: %w", err)
+	}
+	if err := os.WriteFile(path, keys.PrivateBytes, 0600); err != nil {
+		return fmt.Errorf("writing ephemeral key: %w", err)
+	}
+	ui.Infof(ctx, "Ephemeral key written to %s", path)
+	return nil
+}
+
 func keylessSigner(ctx context.Context, ko options.KeyOpts, sv *SignerVerifier) (*SignerVerifier, error) {
 	var (
 		k   *fulcio.Signer
@@ -537,6 +865,7 @@ func keylessSigner(ctx context.Context, ko options.KeyOpts, sv *SignerVerifier)
 	return &SignerVerifier{
 		Cert:           k.Cert,
 		Chain:          k.Chain,
+		KeyAttestation: sv.KeyAttestation,
 		SignerVerifier: k,
 	}, nil
 }
@@ -545,6 +874,9 @@ func SignerFromKeyOpts(ctx context.Context, certPath string, certChainPath strin
 	var sv *SignerVerifier
 	var err error
 	genKey := false
+	if ko.OutputKey != "" && (ko.Sk || ko.KeyRef != "") {
+		return nil, errors.New("--output-key writes the ephemeral key of keyless signing, it can't be used with --key or --sk")
+	}
 	switch {
 	case ko.Sk:
 		sv, err = signerFromSecurityKey(ctx, ko.Slot)
@@ -560,7 +892,16 @@ func SignerFromKeyOpts(ctx context.Context, certPath string, certChainPath strin
 	}
 
 	if ko.IssueCertificateForExistingKey || genKey {
-		return keylessSigner(ctx, ko, sv)
+		ksv, err := keylessSigner(ctx, ko, sv)
+		if err != nil {
+			return nil, err
+		}
+		if ko.OutputKey != "" {
+			if err := writeEphemeralKey(ctx, ko.OutputKey, sv.ephemeralKey, ko.PassFunc); err != nil {
+				return nil, err
+			}
+		}
+		return ksv, nil
 	}
 
 	return sv, nil
@@ -569,8 +910,13 @@ func SignerFromKeyOpts(ctx context.Context, certPath string, certChainPath strin
 type SignerVerifier struct {
 	Cert  []byte
 	Chain []byte
+	// KeyAttestation is the PEM encoded attestation that the key was
+	// generated on a PIV hardware token, see cosign.PIVAttestationAnnotationKey.
+	KeyAttestation []byte
 	signature.SignerVerifier
 	close func()
+	// ephemeralKey is the private key generated for a Fulcio certificate.
+	ephemeralKey crypto.Signer
 }
 
 func (c *SignerVerifier) Close() {
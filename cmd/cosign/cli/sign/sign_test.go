@@ -18,14 +18,21 @@ package sign
 import (
 	"context"
 	"crypto/ecdsa"
+	"crypto/rand"
 	"crypto/x509"
 	"encoding/pem"
 	"errors"
+	"math/big"
 	"os"
+	"path/filepath"
 	"reflect"
 	"strings"
+	"sync"
 	"testing"
+	"time"
 
+	"github.com/google/go-containerregistry/pkg/name"
+	"github.com/google/go-containerregistry/pkg/v1/random"
 	"github.com/stretchr/testify/assert"
 
 	"github.com/secure-systems-lab/go-securesystemslib/encrypted"
@@ -33,8 +40,13 @@ import (
 	"github.com/sigstore/cosign/v2/cmd/cosign/cli/options"
 	"github.com/sigstore/cosign/v2/internal/ui"
 	"github.com/sigstore/cosign/v2/pkg/cosign"
+	"github.com/sigstore/cosign/v2/pkg/oci"
+	"github.com/sigstore/cosign/v2/pkg/oci/mutate"
+	"github.com/sigstore/cosign/v2/pkg/oci/signed"
+	"github.com/sigstore/cosign/v2/pkg/oci/static"
 	"github.com/sigstore/cosign/v2/test"
 	"github.com/sigstore/sigstore/pkg/cryptoutils"
+	sigPayload "github.com/sigstore/sigstore/pkg/signature/payload"
 )
 
 func pass(s string) cosign.PassFunc {
@@ -229,3 +241,217 @@ func Test_ParseOCIReference(t *testing.T) {
 		}
 	}
 }
+
+func Test_signConcurrently(t *testing.T) {
+	errSign := errors.New("signing failed")
+	tests := []struct {
+		name    string
+		workers int
+		fail    int
+		wantErr bool
+	}{
+		{name: "all signed", workers: 3},
+		{name: "default workers", workers: 0},
+		{name: "single worker fails", workers: 1, fail: 2, wantErr: true},
+		{name: "first fails", workers: 2, fail: 0, wantErr: true},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			const n = 8
+			var mu sync.Mutex
+			signed := map[int]bool{}
+			err := signConcurrently(context.Background(), n, tc.workers, func(ctx context.Context, i int) error {
+				if tc.wantErr && i == tc.fail {
+					return errSign
+				}
+				mu.Lock()
+				signed[i] = true
+				mu.Unlock()
+				return nil
+			})
+			if tc.wantErr {
+				if !errors.Is(err, errSign) {
+					t.Fatalf("got error %v, wanted %v", err, errSign)
+				}
+				if tc.workers == 1 && len(signed) != tc.fail {
+					t.Errorf("signed %d images after the failure, wanted none", len(signed)-tc.fail)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(signed) != n {
+				t.Errorf("signed %d images, wanted %d", len(signed), n)
+			}
+		})
+	}
+}
+
+func Test_signAnnotations(t *testing.T) {
+	for k, v := range map[string]string{
+		"GITHUB_ACTIONS":      "true",
+		"GITHUB_SERVER_URL":   "https://github.com",
+		"GITHUB_REPOSITORY":   "sigstore/cosign",
+		"GITHUB_SHA":          "0123456789abcdef0123456789abcdef01234567",
+		"GITHUB_RUN_ID":       "42",
+		"GITHUB_RUN_ATTEMPT":  "",
+		"GITHUB_WORKFLOW_REF": "sigstore/cosign/.github/workflows/build.yml@refs/heads/main",
+	} {
+		t.Setenv(k, v)
+	}
+	file := t.TempDir() + "/annotations.yaml"
+	if err := os.WriteFile(file, []byte("team: release\ncomponent: api\ndev.sigstore.cosign/ci-run: from-file\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name     string
+		signOpts options.SignOptions
+		want     map[string]interface{}
+		wantErr  bool
+	}{{
+		name: "none",
+	}, {
+		name: "file overridden by flags",
+		signOpts: options.SignOptions{
+			AnnotationsFile:   file,
+			AnnotationOptions: options.AnnotationOptions{Annotations: []string{"component=web"}},
+		},
+		want: map[string]interface{}{
+			"team":                       "release",
+			"component":                  "web",
+			"dev.sigstore.cosign/ci-run": "from-file",
+		},
+	}, {
+		name:     "auto overridden by file",
+		signOpts: options.SignOptions{AutoAnnotate: true, AnnotationsFile: file},
+		want: map[string]interface{}{
+			"team":                       "release",
+			"component":                  "api",
+			generate.AnnotationGitCommit: "0123456789abcdef0123456789abcdef01234567",
+			generate.AnnotationCIRun:     "from-file",
+			generate.AnnotationBuilderID: "https://github.com/sigstore/cosign/.github/workflows/build.yml@refs/heads/main",
+		},
+	}, {
+		name:     "missing file",
+		signOpts: options.SignOptions{AnnotationsFile: file + ".missing"},
+		wantErr:  true,
+	}}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := signAnnotations(tc.signOpts)
+			if (err != nil) != tc.wantErr {
+				t.Fatalf("signAnnotations() error = %v, wantErr %v", err, tc.wantErr)
+			}
+			if !reflect.DeepEqual(got, tc.want) {
+				t.Errorf("signAnnotations() = %v, want %v", got, tc.want)
+			}
+		})
+	}
+}
+
+func Test_writeEphemeralKey(t *testing.T) {
+	ctx := context.Background()
+	sv, err := signerFromNewKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyFile := filepath.Join(t.TempDir(), "ephemeral.key")
+	if err := writeEphemeralKey(ctx, keyFile, sv.ephemeralKey, pass("foo")); err != nil {
+		t.Fatal(err)
+	}
+
+	reused, err := signerFromKeyRef(ctx, "", "", keyFile, pass("foo"))
+	if err != nil {
+		t.Fatalf("unexpected error reusing the ephemeral key: %v", err)
+	}
+	pubKey, err := reused.PublicKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := cryptoutils.EqualKeys(sv.ephemeralKey.Public(), pubKey); err != nil {
+		t.Fatalf("reused key differs from the ephemeral key: %v", err)
+	}
+
+	// The certificate of the key can't be reused once it expired.
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(-time.Minute),
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, sv.ephemeralKey.Public(), sv.ephemeralKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	certFile := filepath.Join(t.TempDir(), "ephemeral.crt")
+	if err := os.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := signerFromKeyRef(ctx, certFile, "", keyFile, pass("foo")); err == nil || !strings.Contains(err.Error(), "expired") {
+		t.Fatalf("expected expired certificate error, got %v", err)
+	}
+}
+
+func TestSignerFromKeyOptsOutputKeyWithKey(t *testing.T) {
+	ko := options.KeyOpts{KeyRef: "cosign.key", OutputKey: "ephemeral.key"}
+	if _, err := SignerFromKeyOpts(context.Background(), "", "", ko); err == nil || !strings.Contains(err.Error(), "--output-key") {
+		t.Fatalf("expected --output-key error, got %v", err)
+	}
+}
+
+func Test_existingPayload(t *testing.T) {
+	img, err := random.Image(10, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	h, err := img.Digest()
+	if err != nil {
+		t.Fatal(err)
+	}
+	digest, err := name.NewDigest("ghcr.io/example/app@" + h.String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	other := name.MustParseReference("ghcr.io/example/app@sha256:d131624e6f5d8695e9aea7a0439f7bac0fcc50051282e0c3d4d627cab8845ba5").(name.Digest)
+
+	newPayload := func(d name.Digest, annotations map[string]interface{}) []byte {
+		p, err := (&sigPayload.Cosign{Image: d, Annotations: annotations}).MarshalJSON()
+		if err != nil {
+			t.Fatal(err)
+		}
+		return p
+	}
+	withSignatures := func(payloads ...[]byte) oci.SignedEntity {
+		var se oci.SignedImage = signed.Image(img)
+		for _, p := range payloads {
+			sig, err := static.NewSignature(p, "c2lnbmF0dXJl")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if se, err = mutate.AttachSignatureToImage(se, sig); err != nil {
+				t.Fatal(err)
+			}
+		}
+		return se
+	}
+
+	builder := newPayload(digest, map[string]interface{}{"builder": "ci"})
+	got, err := existingPayload(digest, withSignatures(builder, builder))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(got) != string(builder) {
+		t.Errorf("existingPayload() = %s, want %s", got, builder)
+	}
+
+	for desc, se := range map[string]oci.SignedEntity{
+		"unsigned":           withSignatures(),
+		"different payloads": withSignatures(builder, newPayload(digest, nil)),
+		"other digest":       withSignatures(newPayload(other, nil)),
+	} {
+		if _, err := existingPayload(digest, se); err == nil {
+			t.Errorf("existingPayload() of an image %s succeeded", desc)
+		}
+	}
+}
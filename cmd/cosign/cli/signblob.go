@@ -54,7 +54,10 @@ func SignBlob() *cobra.Command {
   cosign sign-blob --key gcpkms://projects/[PROJECT]/locations/global/keyRings/[KEYRING]/cryptoKeys/[KEY] <FILE>
 
   # sign a blob with a key pair stored in Hashicorp Vault
-  cosign sign-blob --key hashivault://[KEY] <FILE>`,
+  cosign sign-blob --key hashivault://[KEY] <FILE>
+
+  # sign a blob and write a bundle to verify it offline, e.g. archived alongside a release
+  cosign sign-blob --bundle <FILE>.bundle <FILE>`,
 		Args:             cobra.MinimumNArgs(1),
 		PersistentPreRun: options.BindViper,
 		PreRunE: func(cmd *cobra.Command, args []string) error {
@@ -81,7 +84,11 @@ func SignBlob() *cobra.Command {
 				OIDCClientID:                   o.OIDC.ClientID,
 				OIDCClientSecret:               oidcClientSecret,
 				OIDCRedirectURL:                o.OIDC.RedirectURL,
+				OIDCScopes:                     o.OIDC.Scopes,
 				OIDCDisableProviders:           o.OIDC.DisableAmbientProviders,
+				FulcioAuthFlow:                 o.OIDC.Flow,
+				SPIFFESocket:                   o.OIDC.SPIFFESocket,
+				OutputKey:                      o.OutputKey,
 				BundlePath:                     o.BundlePath,
 				SkipConfirmation:               o.SkipConfirmation,
 				TSAClientCACert:                o.TSAClientCACert,
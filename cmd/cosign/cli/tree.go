@@ -17,15 +17,18 @@ package cli
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
+	"io"
 	"os"
-
-	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"sort"
 
 	"github.com/google/go-containerregistry/pkg/name"
 	"github.com/spf13/cobra"
 
 	"github.com/sigstore/cosign/v2/cmd/cosign/cli/options"
+	"github.com/sigstore/cosign/v2/pkg/cosign"
+	"github.com/sigstore/cosign/v2/pkg/oci"
 	ociremote "github.com/sigstore/cosign/v2/pkg/oci/remote"
 )
 
@@ -33,13 +36,16 @@ func Tree() *cobra.Command {
 	c := &options.TreeOptions{}
 
 	cmd := &cobra.Command{
-		Use:              "tree",
-		Short:            "Display supply chain security related artifacts for an image such as signatures, SBOMs and attestations",
-		Example:          "  cosign tree <IMAGE>",
+		Use:   "tree",
+		Short: "Display supply chain security related artifacts for an image such as signatures, SBOMs and attestations",
+		Example: `  cosign tree <IMAGE>
+
+  # print the artifacts as JSON, e.g. to check in a script what is attached to an image
+  cosign tree --output json <IMAGE>`,
 		Args:             cobra.ExactArgs(1),
 		PersistentPreRun: options.BindViper,
 		RunE: func(cmd *cobra.Command, args []string) error {
-			return TreeCmd(cmd.Context(), c.Registry, args[0])
+			return TreeCmd(cmd.Context(), c.Registry, c.Output, args[0])
 		},
 	}
 
@@ -47,8 +53,35 @@ func Tree() *cobra.Command {
 	return cmd
 }
 
-func TreeCmd(ctx context.Context, regOpts options.RegistryOptions, imageRef string) error {
-	scsaMap := map[name.Tag][]v1.Layer{}
+// treeReport lists the artifacts attached to an image.
+type treeReport struct {
+	Image        string         `json:"image"`
+	Signatures   *treeArtifacts `json:"signatures,omitempty"`
+	Attestations *treeArtifacts `json:"attestations,omitempty"`
+	SBOMs        *treeArtifacts `json:"sboms,omitempty"`
+}
+
+// treeArtifacts are the layers of the image at an attachment tag.
+type treeArtifacts struct {
+	Tag    string      `json:"tag"`
+	Layers []treeLayer `json:"layers"`
+}
+
+type treeLayer struct {
+	Digest string `json:"digest"`
+	// PredicateType is the predicate type of an attestation.
+	PredicateType string `json:"predicateType,omitempty"`
+	// RekorLogIndex is the log index of the transparency log entry, when
+	// the signature has a Rekor bundle.
+	RekorLogIndex *int64 `json:"rekorLogIndex,omitempty"`
+	// RFC3161Timestamp is set when the signature has a signed timestamp.
+	RFC3161Timestamp bool `json:"rfc3161Timestamp,omitempty"`
+}
+
+func TreeCmd(ctx context.Context, regOpts options.RegistryOptions, output, imageRef string) error {
+	if output != "text" && output != "json" {
+		return fmt.Errorf("unsupported output format %q, expected text or json", output)
+	}
 	ref, err := name.ParseReference(imageRef, regOpts.NameOptions()...)
 	if err != nil {
 		return err
@@ -58,7 +91,7 @@ func TreeCmd(ctx context.Context, regOpts options.RegistryOptions, imageRef stri
 	if err != nil {
 		return err
 	}
-	fmt.Fprintf(os.Stdout, "📦 Supply Chain Security Related artifacts for an image: %s\n", ref.String())
+	report := treeReport{Image: ref.String()}
 
 	simg, err := ociremote.SignedEntity(ref, remoteOpts...)
 	if err != nil {
@@ -72,13 +105,9 @@ func TreeCmd(ctx context.Context, regOpts options.RegistryOptions, imageRef stri
 
 	atts, err := simg.Attestations()
 	if err == nil {
-		layers, err := atts.Layers()
-		if err != nil {
+		if report.Attestations, err = signatureArtifacts(attRef, atts, true); err != nil {
 			return err
 		}
-		if len(layers) > 0 {
-			scsaMap[attRef] = layers
-		}
 	}
 
 	sigRef, err := ociremote.SignatureTag(ref, remoteOpts...)
@@ -88,13 +117,9 @@ func TreeCmd(ctx context.Context, regOpts options.RegistryOptions, imageRef stri
 
 	sigs, err := simg.Signatures()
 	if err == nil {
-		layers, err := sigs.Layers()
-		if err != nil {
+		if report.Signatures, err = signatureArtifacts(sigRef, sigs, false); err != nil {
 			return err
 		}
-		if len(layers) > 0 {
-			scsaMap[sigRef] = layers
-		}
 	}
 
 	sbomRef, err := ociremote.SBOMTag(ref, remoteOpts...)
@@ -109,48 +134,114 @@ func TreeCmd(ctx context.Context, regOpts options.RegistryOptions, imageRef stri
 			return err
 		}
 		if len(layers) > 0 {
-			scsaMap[sbomRef] = layers
+			report.SBOMs = &treeArtifacts{Tag: sbomRef.String()}
+			for _, l := range layers {
+				digest, err := l.Digest()
+				if err != nil {
+					return err
+				}
+				report.SBOMs.Layers = append(report.SBOMs.Layers, treeLayer{Digest: digest.String()})
+			}
 		}
 	}
 
-	if len(scsaMap) == 0 {
-		fmt.Fprintf(os.Stdout, "No Supply Chain Security Related Artifacts artifacts found for image %s\n, start creating one with simply running"+
-			"$ cosign sign <img>", ref.String())
+	if output == "json" {
+		b, err := json.MarshalIndent(report, "", "  ")
+		if err != nil {
+			return err
+		}
+		fmt.Fprintln(os.Stdout, string(b))
 		return nil
 	}
+	printTree(os.Stdout, report)
+	return nil
+}
 
-	for t, k := range scsaMap {
-		switch t {
-		case sigRef:
-			fmt.Fprintf(os.Stdout, "└── 🔐 Signatures for an image tag: %s\n", t.String())
-		case sbomRef:
-			fmt.Fprintf(os.Stdout, "└── 📦 SBOMs for an image tag: %s\n", t.String())
-		case attRef:
-			fmt.Fprintf(os.Stdout, "└── 💾 Attestations for an image tag: %s\n", t.String())
+// signatureArtifacts returns the signatures or attestations at tag, or nil if
+// there are none.
+func signatureArtifacts(tag name.Tag, sigs oci.Signatures, attestations bool) (*treeArtifacts, error) {
+	sl, err := sigs.Get()
+	if err != nil {
+		return nil, err
+	}
+	if len(sl) == 0 {
+		return nil, nil
+	}
+	artifacts := &treeArtifacts{Tag: tag.String()}
+	for _, sig := range sl {
+		digest, err := sig.Digest()
+		if err != nil {
+			return nil, err
 		}
-
-		if err := printLayers(k); err != nil {
-			return err
+		l := treeLayer{Digest: digest.String()}
+		if attestations {
+			l.PredicateType = "unknown"
+			if as, err := cosign.DecodeAttestationStatement(sig); err == nil {
+				l.PredicateType = as.Statement.PredicateType
+			}
+		}
+		if b, err := sig.Bundle(); err == nil && b != nil {
+			l.RekorLogIndex = &b.Payload.LogIndex
 		}
+		if ts, err := sig.RFC3161Timestamp(); err == nil && ts != nil {
+			l.RFC3161Timestamp = true
+		}
+		artifacts.Layers = append(artifacts.Layers, l)
 	}
+	return artifacts, nil
+}
 
-	return nil
+func printTree(w io.Writer, report treeReport) {
+	fmt.Fprintf(w, "📦 Supply Chain Security Related artifacts for an image: %s\n", report.Image)
+	if report.Signatures == nil && report.Attestations == nil && report.SBOMs == nil {
+		fmt.Fprintf(w, "No Supply Chain Security Related Artifacts artifacts found for image %s\n, start creating one with simply running"+
+			"$ cosign sign <img>", report.Image)
+		return
+	}
+
+	if a := report.Signatures; a != nil {
+		fmt.Fprintf(w, "└── 🔐 Signatures for an image tag: %s\n", a.Tag)
+		printLayers(w, "   ", a.Layers)
+	}
+	if a := report.Attestations; a != nil {
+		fmt.Fprintf(w, "└── 💾 Attestations for an image tag: %s\n", a.Tag)
+		byType := map[string][]treeLayer{}
+		for _, l := range a.Layers {
+			byType[l.PredicateType] = append(byType[l.PredicateType], l)
+		}
+		types := make([]string, 0, len(byType))
+		for t := range byType {
+			types = append(types, t)
+		}
+		sort.Strings(types)
+		for i, t := range types {
+			sym, indent := "   ├──", "   │  "
+			if i == len(types)-1 {
+				sym, indent = "   └──", "      "
+			}
+			fmt.Fprintf(w, "%s 📜 %s\n", sym, t)
+			printLayers(w, indent, byType[t])
+		}
+	}
+	if a := report.SBOMs; a != nil {
+		fmt.Fprintf(w, "└── 📦 SBOMs for an image tag: %s\n", a.Tag)
+		printLayers(w, "   ", a.Layers)
+	}
 }
 
-func printLayers(layers []v1.Layer) error {
+func printLayers(w io.Writer, indent string, layers []treeLayer) {
 	for i, l := range layers {
-		last := i == len(layers)-1
-		var sym string
-		if last {
-			sym = "   └──"
-		} else {
-			sym = "   ├──"
+		sym := "├──"
+		if i == len(layers)-1 {
+			sym = "└──"
 		}
-		digest, err := l.Digest()
-		if err != nil {
-			return err
+		var notes string
+		if l.RekorLogIndex != nil {
+			notes += fmt.Sprintf(" (rekor log index %d)", *l.RekorLogIndex)
 		}
-		fmt.Printf("%s 🍒 %s\n", sym, digest)
+		if l.RFC3161Timestamp {
+			notes += " (timestamped)"
+		}
+		fmt.Fprintf(w, "%s%s 🍒 %s%s\n", indent, sym, l.Digest, notes)
 	}
-	return nil
 }
@@ -0,0 +1,55 @@
+//
+// Copyright 2023 The Sigstore Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cli
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestPrintTree(t *testing.T) {
+	logIndex := int64(42)
+	report := treeReport{
+		Image: "example.com/app:v1",
+		Signatures: &treeArtifacts{
+			Tag:    "example.com/app:sha256-abc.sig",
+			Layers: []treeLayer{{Digest: "sha256:1", RekorLogIndex: &logIndex}, {Digest: "sha256:2", RFC3161Timestamp: true}},
+		},
+		Attestations: &treeArtifacts{
+			Tag: "example.com/app:sha256-abc.att",
+			Layers: []treeLayer{
+				{Digest: "sha256:3", PredicateType: "https://spdx.dev/Document"},
+				{Digest: "sha256:4", PredicateType: "https://slsa.dev/provenance/v0.2"},
+			},
+		},
+	}
+
+	var buf bytes.Buffer
+	printTree(&buf, report)
+	want := `📦 Supply Chain Security Related artifacts for an image: example.com/app:v1
+└── 🔐 Signatures for an image tag: example.com/app:sha256-abc.sig
+   ├── 🍒 sha256:1 (rekor log index 42)
+   └── 🍒 sha256:2 (timestamped)
+└── 💾 Attestations for an image tag: example.com/app:sha256-abc.att
+   ├── 📜 https://slsa.dev/provenance/v0.2
+   │  └── 🍒 sha256:4
+   └── 📜 https://spdx.dev/Document
+      └── 🍒 sha256:3
+`
+	if got := buf.String(); got != want {
+		t.Errorf("printTree() =\n%s\nwant\n%s", got, want)
+	}
+}
@@ -28,15 +28,21 @@ func Triangulate() *cobra.Command {
 	o := &options.TriangulateOptions{}
 
 	cmd := &cobra.Command{
-		Use:              "triangulate",
-		Short:            "Outputs the located cosign image reference. This is the location cosign stores the specified artifact type.",
-		Example:          "  cosign triangulate <IMAGE>",
+		Use:   "triangulate",
+		Short: "Outputs the located cosign image reference. This is the location cosign stores the specified artifact type.",
+		Example: `  cosign triangulate <IMAGE>
+
+  # locate the attestations of an image
+  cosign triangulate --type attestation <IMAGE>
+
+  # locate the WASM module attached to an image
+  cosign triangulate --type wasm <IMAGE>`,
 		PersistentPreRun: options.BindViper,
 		RunE: func(cmd *cobra.Command, args []string) error {
 			if len(args) != 1 {
 				return flag.ErrHelp
 			}
-			return triangulate.MungeCmd(cmd.Context(), o.Registry, args[0], o.Type)
+			return triangulate.MungeCmd(cmd.Context(), o.Registry, args[0], o.Type, cmd.OutOrStdout())
 		},
 	}
 
@@ -18,6 +18,7 @@ package triangulate
 import (
 	"context"
 	"fmt"
+	"io"
 	"os"
 
 	"github.com/google/go-containerregistry/pkg/name"
@@ -26,7 +27,10 @@ import (
 	ociremote "github.com/sigstore/cosign/v2/pkg/oci/remote"
 )
 
-func MungeCmd(ctx context.Context, regOpts options.RegistryOptions, imageRef string, attachmentType string) error {
+// MungeCmd writes to w the tag holding the attachment of the image at
+// imageRef of type attachmentType: a signature, an attestation or the name
+// of an attachment, e.g. sbom or wasm.
+func MungeCmd(ctx context.Context, regOpts options.RegistryOptions, imageRef string, attachmentType string, w io.Writer) error {
 	ref, err := name.ParseReference(imageRef, regOpts.NameOptions()...)
 	if err != nil {
 		return err
@@ -47,12 +51,12 @@ func MungeCmd(ctx context.Context, regOpts options.RegistryOptions, imageRef str
 	case cosign.Attestation:
 		dstRef, err = ociremote.AttestationTag(ref, ociremoteOpts...)
 	default:
-		err = fmt.Errorf("unknown attachment type %s", attachmentType)
+		dstRef, err = ociremote.AttachmentTag(ref, attachmentType, ociremoteOpts...)
 	}
 	if err != nil {
 		return err
 	}
 
-	fmt.Println(dstRef.Name())
+	fmt.Fprintln(w, dstRef.Name())
 	return nil
 }
@@ -0,0 +1,57 @@
+//
+// Copyright 2023 The Sigstore Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package triangulate
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/sigstore/cosign/v2/cmd/cosign/cli/options"
+)
+
+func TestMungeCmd(t *testing.T) {
+	const (
+		repo   = "ghcr.io/example/app"
+		digest = "sha256:0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef"
+		tag    = repo + ":sha256-0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef"
+	)
+	tests := []struct {
+		attachmentType string
+		want           string
+		wantErr        bool
+	}{
+		{attachmentType: "signature", want: tag + ".sig"},
+		{attachmentType: "attestation", want: tag + ".att"},
+		{attachmentType: "sbom", want: tag + ".sbom"},
+		{attachmentType: "wasm", want: tag + ".wasm"},
+		{attachmentType: "sig", wantErr: true},
+		{attachmentType: "not/valid", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.attachmentType, func(t *testing.T) {
+			var out bytes.Buffer
+			err := MungeCmd(context.Background(), options.RegistryOptions{}, repo+"@"+digest, tt.attachmentType, &out)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("MungeCmd() error = %v, wantErr %t", err, tt.wantErr)
+			}
+			if got := strings.TrimSpace(out.String()); got != tt.want {
+				t.Errorf("MungeCmd() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
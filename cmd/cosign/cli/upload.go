@@ -90,13 +90,18 @@ func uploadWASM() *cobra.Command {
 	o := &options.UploadWASMOptions{}
 
 	cmd := &cobra.Command{
-		Use:              "wasm",
-		Short:            "Upload a wasm module to the supplied container image reference",
-		Example:          "  cosign upload wasm -f foo.wasm <image uri>",
+		Use:   "wasm",
+		Short: "Upload a wasm module to the supplied container image reference",
+		Example: `  cosign upload wasm -f foo.wasm <image uri>
+
+  # attach a wasm module to an image, then sign and verify it
+  cosign upload wasm --attach -f foo.wasm <image uri>
+  cosign sign --key cosign.key --attachment wasm <image uri>
+  cosign verify --key cosign.pub --attachment wasm <image uri>`,
 		Args:             cobra.ExactArgs(1),
 		PersistentPreRun: options.BindViper,
 		RunE: func(cmd *cobra.Command, args []string) error {
-			return upload.WasmCmd(cmd.Context(), o.Registry, o.File, args[0])
+			return upload.WasmCmd(cmd.Context(), o.Registry, o.File, args[0], o.Attach)
 		},
 	}
 
@@ -19,12 +19,12 @@ import (
 	"context"
 	"errors"
 	"fmt"
-	"os"
 
 	"github.com/google/go-containerregistry/pkg/name"
 	"github.com/google/go-containerregistry/pkg/v1/types"
 
 	"github.com/sigstore/cosign/v2/cmd/cosign/cli/options"
+	"github.com/sigstore/cosign/v2/internal/ui"
 	cremote "github.com/sigstore/cosign/v2/pkg/cosign/remote"
 )
 
@@ -51,9 +51,9 @@ func BlobCmd(ctx context.Context, regOpts options.RegistryOptions, files []cremo
 		return errors.New("no files uploaded?")
 	}
 	if len(files) > 1 {
-		fmt.Fprintf(os.Stderr, "Uploading multi-platform index to %s\n", dgstAddr)
+		ui.Infof(ctx, "Uploading multi-platform index to %s", dgstAddr)
 	} else {
-		fmt.Fprintln(os.Stderr, "Uploaded image to:")
+		ui.Infof(ctx, "Uploaded image to:")
 		fmt.Println(dgstAddr)
 	}
 	return nil
@@ -23,24 +23,46 @@ import (
 	"github.com/google/go-containerregistry/pkg/name"
 	"github.com/google/go-containerregistry/pkg/v1/remote"
 	"github.com/sigstore/cosign/v2/cmd/cosign/cli/options"
+	"github.com/sigstore/cosign/v2/internal/ui"
+	"github.com/sigstore/cosign/v2/pkg/cosign/wasm"
+	ociremote "github.com/sigstore/cosign/v2/pkg/oci/remote"
 	"github.com/sigstore/cosign/v2/pkg/oci/static"
 	"github.com/sigstore/cosign/v2/pkg/types"
 )
 
-func WasmCmd(ctx context.Context, regOpts options.RegistryOptions, wasmPath, imageRef string) error {
+// WasmCmd uploads the wasm module wasmPath to imageRef or, when attach is
+// set, as the wasm attachment of the image imageRef.
+func WasmCmd(ctx context.Context, regOpts options.RegistryOptions, wasmPath, imageRef string, attach bool) error {
 	b, err := os.ReadFile(wasmPath)
 	if err != nil {
 		return err
 	}
 
-	ref, err := name.ParseReference(imageRef, regOpts.NameOptions()...)
+	var ref name.Reference
+	ref, err = name.ParseReference(imageRef, regOpts.NameOptions()...)
 	if err != nil {
 		return err
 	}
-	fmt.Fprintf(os.Stderr, "Uploading wasm file from [%s] to [%s].\n", wasmPath, ref.Name())
+	if attach {
+		ociremoteOpts, err := regOpts.ClientOpts(ctx)
+		if err != nil {
+			return fmt.Errorf("constructing client options: %w", err)
+		}
+		digest, err := ociremote.ResolveDigest(ref, ociremoteOpts...)
+		if err != nil {
+			return fmt.Errorf("resolving digest of %s: %w", ref, err)
+		}
+		if ref, err = ociremote.AttachmentTag(digest, "wasm", ociremoteOpts...); err != nil {
+			return err
+		}
+	}
 	img, err := static.NewFile(b, static.WithLayerMediaType(types.WasmLayerMediaType), static.WithConfigMediaType(types.WasmConfigMediaType))
 	if err != nil {
 		return err
 	}
+	if _, err := wasm.Content(img); err != nil {
+		return fmt.Errorf("%s: %w", wasmPath, err)
+	}
+	ui.Infof(ctx, "Uploading wasm file from [%s] to [%s].", wasmPath, ref.Name())
 	return remote.Write(ref, img, regOpts.GetRegistryClientOpts(ctx)...)
 }
@@ -25,6 +25,7 @@ import (
 	"github.com/sigstore/cosign/v2/cmd/cosign/cli/options"
 	"github.com/sigstore/cosign/v2/cmd/cosign/cli/verify"
 	"github.com/sigstore/cosign/v2/internal/ui"
+	"github.com/sigstore/cosign/v2/pkg/policy"
 )
 
 const ignoreTLogMessage = "Skipping tlog verification is an insecure practice that lacks of transparency and auditability verification for the %s."
@@ -45,12 +46,31 @@ against the transparency log.`,
   # verify multiple images
   cosign verify <IMAGE_1> <IMAGE_2> ...
 
+  # verify every image even if some fail and print a summary
+  cosign verify --continue-on-error <IMAGE_1> <IMAGE_2> ...
+
+  # reuse the signatures verified for the same digest and options within the last hour
+  cosign verify --key cosign.pub --cache-ttl 1h <IMAGE>
+
   # additionally verify specified annotations
   cosign verify -a key1=val1 -a key2=val2 <IMAGE>
 
   # verify image with an on-disk public key
   cosign verify --key cosign.pub <IMAGE>
 
+  # verify image with any of several trusted public keys, e.g. the old and the new key while rotating keys
+  cat old.pub new.pub > trusted.pub
+  cosign verify --key trusted.pub <IMAGE>
+
+  # verify image was signed by at least as many of the keys and identities of a policy file as its threshold
+  cosign verify --threshold-policy release-policy.yaml <IMAGE>
+
+  # verify image was signed by a maintainer key of the root-of-trust policy of its registry namespace
+  cosign verify --trust-policy ghcr.io/myorg ghcr.io/myorg/app:v1
+
+  # verify the WASM module attached to an image with 'cosign upload wasm --attach'
+  cosign verify --key cosign.pub --attachment wasm <IMAGE>
+
   # verify image with an on-disk public key, manually specifying the
   # signature digest algorithm
   cosign verify --key cosign.pub --signature-digest-algorithm sha512 <IMAGE>
@@ -61,10 +81,16 @@ against the transparency log.`,
   # verify image with local certificate and certificate chain
   cosign verify --cert cosign.crt --cert-chain chain.crt <IMAGE>
 
+  # verify image with a code-signing certificate issued by your own PKI, which carries no OIDC issuer
+  cosign verify --certificate signer.crt --certificate-chain chain.crt --certificate-email signer@example.com --certificate-oidc-issuer-regexp '.*' <IMAGE>
+
   # verify image using keyless verification with the given certificate
   # chain and identity parameters, without Fulcio roots (for BYO PKI):
   cosign verify --cert-chain chain.crt --certificate-oidc-issuer https://issuer.example.com --certificate-identity foo@example.com <IMAGE>
 
+  # verify image using keyless verification against the CA of a private Fulcio deployment
+  cosign verify --ca-roots fulcio-root.pem --ca-intermediates fulcio-intermediate.pem --certificate-oidc-issuer https://issuer.example.com --certificate-identity foo@example.com <IMAGE>
+
   # verify image with public key provided by URL
   cosign verify --key https://host.for/[FILE] <IMAGE>
 
@@ -74,9 +100,24 @@ against the transparency log.`,
   # verify image with public key stored in Google Cloud KMS
   cosign verify --key gcpkms://projects/[PROJECT]/locations/global/keyRings/[KEYRING]/cryptoKeys/[KEY] <IMAGE>
 
+  # verify image with public key stored in Azure Key Vault
+  cosign verify --key azurekms://[VAULT_NAME][VAULT_URI]/[KEY] <IMAGE>
+
+  # verify image with public key stored in AWS KMS, referenced by key ID, alias or (multi-Region) key ARN
+  cosign verify --key awskms://[ENDPOINT]/[ID/ALIAS/ARN] <IMAGE>
+
   # verify image with public key stored in Hashicorp Vault
   cosign verify --key hashivault://[KEY] <IMAGE>
 
+  # verify image with a pinned version of a key stored in a Hashicorp Vault enterprise namespace
+  cosign verify --key hashivault://[NAMESPACE]/[KEY]?version=[VERSION] <IMAGE>
+
+  # verify image with an OpenSSH public key
+  cosign verify --key ~/.ssh/id_ed25519.pub <IMAGE>
+
+  # verify image was signed with a key generated on a Yubikey that requires touching it to sign
+  cosign verify --key cosign.pub --piv-attestation-roots https://developers.yubico.com/PIV/Introduction/piv-attestation-ca.pem --piv-touch-policy always,cached <IMAGE>
+
   # verify image with public key stored in a Kubernetes secret
   cosign verify --key k8s://[NAMESPACE]/[KEY] <IMAGE>
 
@@ -84,9 +125,42 @@ against the transparency log.`,
   cosign verify --key gitlab://[OWNER]/[PROJECT_NAME] <IMAGE>
 
   # verify image with public key stored in GitLab with project id
-  cosign verify --key gitlab://[PROJECT_ID] <IMAGE>`,
+  cosign verify --key gitlab://[PROJECT_ID] <IMAGE>
 
-		Args:             cobra.MinimumNArgs(1),
+  # verify the signatures of an OCI artifact other than a container image, e.g. a Helm chart
+  cosign verify --key cosign.pub <ARTIFACT DIGEST>
+
+  # verify a multi-arch image index and the image of each of its platforms
+  cosign verify --key cosign.pub --recursive <IMAGE INDEX>
+
+  # verify only the linux/arm64 image of a multi-arch image index
+  cosign verify --key cosign.pub --platform linux/arm64 <IMAGE INDEX>
+
+  # verify an image and write the digest that was verified, to pin it in deployment manifests
+  cosign verify --key cosign.pub --output-digest verified.txt <IMAGE>
+
+  # verify an image of the local Docker daemon, by the digest the daemon recorded for it
+  cosign verify --key cosign.pub docker-daemon://<IMAGE>
+
+  # verify an image and write its VerificationResult as JSON, e.g. for CI
+  cosign verify --key cosign.pub --output json <IMAGE>
+
+  # verify the images of a release and record the digests verified in a lockfile
+  cosign verify --key cosign.pub --record lock.json <IMAGE> <IMAGE>
+
+  # verify the images of the lockfile again, failing when a tag no longer resolves to the recorded digest
+  cosign verify --key cosign.pub --against lock.json
+
+  # verify an image, rejecting the digests and keys revoked by a signed revocation list
+  cosign verify --key cosign.pub --revocation-list oci://<REGISTRY>/<ORG>/revocations:latest --revocation-list-key revocation.pub <IMAGE>`,
+
+		Args: func(cmd *cobra.Command, args []string) error {
+			// Without images, --against verifies the images of the lockfile.
+			if o.Lockfile.Against != "" {
+				return nil
+			}
+			return cobra.MinimumNArgs(1)(cmd, args)
+		},
 		PersistentPreRun: options.BindViper,
 		RunE: func(cmd *cobra.Command, args []string) error {
 			annotations, err := o.AnnotationsMap()
@@ -123,10 +197,30 @@ against the transparency log.`,
 				SignatureRef:                 o.SignatureRef,
 				PayloadRef:                   o.PayloadRef,
 				LocalImage:                   o.LocalImage,
+				ContinueOnError:              o.ContinueOnError,
+				Recursive:                    o.Recursive,
+				Platform:                     o.Platform,
+				OutputDigest:                 o.OutputDigest,
+				ThresholdPolicy:              o.ThresholdPolicy,
+				TrustPolicy:                  o.TrustPolicy,
+				PIVAttestation:               o.PIVAttestation,
 				Offline:                      o.CommonVerifyOptions.Offline,
 				TSACertChainPath:             o.CommonVerifyOptions.TSACertChainPath,
 				IgnoreTlog:                   o.CommonVerifyOptions.IgnoreTlog,
+				RequireOnlineTlog:            o.CommonVerifyOptions.RequireOnlineTlog,
+				RekorCheckpoint:              o.CommonVerifyOptions.RekorCheckpoint,
+				RekorPublicKey:               o.CommonVerifyOptions.RekorPublicKey,
+				MaxSignatureAge:              o.CommonVerifyOptions.MaxSignatureAge,
 				MaxWorkers:                   o.CommonVerifyOptions.MaxWorkers,
+				CacheTTL:                     o.Cache.TTL,
+				Record:                       o.Lockfile.Record,
+				Against:                      o.Lockfile.Against,
+				RevocationList:               o.Revocation.List,
+				RevocationListKey:            o.Revocation.Key,
+				RevocationListTTL:            o.Revocation.TTL,
+			}
+			if v.CacheDir, err = o.Cache.CacheDir(); err != nil {
+				return err
 			}
 
 			if o.CommonVerifyOptions.MaxWorkers == 0 {
@@ -164,6 +258,12 @@ against the transparency log.`,
   # verify cosign attestations on the image against the transparency log
   cosign verify-attestation <IMAGE>
 
+  # verify keyless attestations made by a GitHub Actions workflow
+  cosign verify-attestation --certificate-uri https://github.com/<OWNER>/<REPO>/.github/workflows/<WORKFLOW>@refs/heads/main --certificate-oidc-issuer https://token.actions.githubusercontent.com <IMAGE>
+
+  # verify keyless attestations made by any workflow of a GitHub organization
+  cosign verify-attestation --certificate-uri-regexp '^https://github.com/<OWNER>/.*/.github/workflows/.*' --certificate-oidc-issuer https://token.actions.githubusercontent.com <IMAGE>
+
   # verify multiple images
   cosign verify-attestation <IMAGE_1> <IMAGE_2> ...
 
@@ -176,12 +276,24 @@ against the transparency log.`,
   # verify image attestations with an on-disk signed image from 'cosign save'
   cosign verify-attestation --key cosign.pub --local-image <PATH>
 
+  # verify an image attestation offline with a bundle from 'cosign attest --bundle'
+  cosign verify-attestation --key cosign.pub --bundle <BUNDLE> <IMAGE>@sha256:<DIGEST>
+
   # verify image with public key provided by URL
   cosign verify-attestation --key https://host.for/<FILE> <IMAGE>
 
+  # verify image attestations with a code-signing certificate issued by your own PKI, which carries no OIDC issuer
+  cosign verify-attestation --certificate signer.crt --certificate-chain chain.crt --certificate-email signer@example.com --certificate-oidc-issuer-regexp '.*' <IMAGE>
+
   # verify image with public key stored in Google Cloud KMS
   cosign verify-attestation --key gcpkms://projects/<PROJECT>/locations/global/keyRings/<KEYRING>/cryptoKeys/<KEY> <IMAGE>
 
+  # verify image with public key stored in Azure Key Vault
+  cosign verify-attestation --key azurekms://<VAULT_NAME><VAULT_URI>/<KEY> <IMAGE>
+
+  # verify image with public key stored in AWS KMS, referenced by key ID, alias or (multi-Region) key ARN
+  cosign verify-attestation --key awskms://<ENDPOINT>/<ID/ALIAS/ARN> <IMAGE>
+
   # verify image with public key stored in Hashicorp Vault
   cosign verify-attestation --key hashivault:///<KEY> <IMAGE>
 
@@ -195,14 +307,55 @@ against the transparency log.`,
   cosign verify-attestation --key cosign.pub --type <PREDICATE_TYPE> --policy <REGO_POLICY> <IMAGE>
 
   # verify image with public key and validate attestation based on CUE policy
-  cosign verify-attestation --key cosign.pub --type <PREDICATE_TYPE> --policy <CUE_POLICY> <IMAGE>`,
+  cosign verify-attestation --key cosign.pub --type <PREDICATE_TYPE> --policy <CUE_POLICY> <IMAGE>
+
+  # verify SLSA provenance and SPDX attestations with a policy for each predicate type
+  cosign verify-attestation --key cosign.pub --type slsaprovenance --type spdx --policy slsaprovenance=<CUE_POLICY> --policy spdx=<REGO_POLICY> <IMAGE>
+
+  # verify image attestations against a policy that also checks the signer, given to policies under input.verification
+  # along with the image digest, the Rekor entry and the annotations of the attestation
+  cosign verify-attestation --certificate-identity-regexp <IDENTITY> --certificate-oidc-issuer <ISSUER> --type slsaprovenance --policy <CUE_POLICY> <IMAGE>
+
+  # verify a CycloneDX SBOM attestation, including those with a versioned https://cyclonedx.org/bom/v1.x predicate type
+  cosign verify-attestation --key cosign.pub --type cyclonedx --policy <CUE_POLICY> <IMAGE>
+
+  # verify image attestations and emit a JSON report including the policy evaluation results
+  cosign verify-attestation --key cosign.pub --type <PREDICATE_TYPE> --policy <CUE_POLICY> --output json <IMAGE>
+
+  # verify image attestations against a Rego policy and write the violations as SARIF for code scanning
+  cosign verify-attestation --key cosign.pub --type <PREDICATE_TYPE> --policy <REGO_POLICY> --output sarif <IMAGE> > cosign.sarif
+
+  # verify image attestations against an OPA bundle, with the allowed registries as an external data document
+  cosign verify-attestation --key cosign.pub --type <PREDICATE_TYPE> --policy bundle.tar.gz --policy-data allowed-registries.json <IMAGE>
+
+  # verify image attestations made within the last week that have not expired
+  cosign verify-attestation --key cosign.pub --type <PREDICATE_TYPE> --max-signature-age 168h --check-predicate-expiry <IMAGE>
+
+  # verify image attestations against the policies of a signed policy bundle published to a registry
+  cosign verify-attestation --key cosign.pub --type <PREDICATE_TYPE> --policy oci://<REGISTRY>/<ORG>/policies:v1 --policy-key policy.pub <IMAGE>
+
+  # verify the link attestations of an image against an in-toto layout signed by the project owner
+  cosign verify-attestation --layout root.layout --layout-key owner.pub <IMAGE>
+
+  # verify that the image was built from its repository by a trusted SLSA level 3 builder, without a policy file
+  cosign verify-attestation --key cosign.pub --type slsaprovenance --slsa-builder-id <BUILDER_ID> --slsa-source-uri git+https://github.com/<ORG>/<REPO> --slsa-min-level 3 <IMAGE>
+
+  # verify image attestations, rejecting the digests and keys revoked by a revocation list served over https
+  cosign verify-attestation --key cosign.pub --type <PREDICATE_TYPE> --revocation-list https://example.com/revocations.json --revocation-list-key revocation.pub <IMAGE>`,
 
 		Args:             cobra.MinimumNArgs(1),
 		PersistentPreRun: options.BindViper,
 		RunE: func(cmd *cobra.Command, args []string) error {
+			annotations, err := o.AnnotationsMap()
+			if err != nil {
+				return err
+			}
+
 			v := &verify.VerifyAttestationCommand{
 				RegistryOptions:              o.Registry,
 				CheckClaims:                  o.CheckClaims,
+				CheckPredicateExpiry:         o.CheckPredicateExpiry,
+				MaxAttestationSize:           o.MaxAttestationSize,
 				CertVerifyOptions:            o.CertVerify,
 				CertRef:                      o.CertVerify.Cert,
 				CertChain:                    o.CertVerify.CertChain,
@@ -218,14 +371,43 @@ against the transparency log.`,
 				Slot:                         o.SecurityKey.Slot,
 				Output:                       o.Output,
 				RekorURL:                     o.Rekor.URL,
-				PredicateType:                o.Predicate.Type,
+				PredicateTypes:               o.Predicate.Types,
 				Policies:                     o.Policies,
+				PolicyEngine:                 o.PolicyEngine,
+				RegoQuery:                    o.RegoQuery,
+				PolicyData:                   o.PolicyData,
+				PolicyKey:                    o.PolicyKey,
+				AllowMissingAttestations:     o.AllowMissing,
+				Annotations:                  annotations,
 				LocalImage:                   o.LocalImage,
+				ContinueOnError:              o.ContinueOnError,
+				Recursive:                    o.Recursive,
+				Platform:                     o.Platform,
+				OutputDigest:                 o.OutputDigest,
+				ThresholdPolicy:              o.ThresholdPolicy,
+				TrustPolicy:                  o.TrustPolicy,
+				PIVAttestation:               o.PIVAttestation,
+				Layout:                       o.Layout,
+				LayoutKeys:                   o.LayoutKeys,
+				InspectionDir:                o.InspectionDir,
+				BundlePath:                   o.BundlePath,
 				NameOptions:                  o.Registry.NameOptions(),
 				Offline:                      o.CommonVerifyOptions.Offline,
 				TSACertChainPath:             o.CommonVerifyOptions.TSACertChainPath,
 				IgnoreTlog:                   o.CommonVerifyOptions.IgnoreTlog,
+				RequireOnlineTlog:            o.CommonVerifyOptions.RequireOnlineTlog,
+				RekorCheckpoint:              o.CommonVerifyOptions.RekorCheckpoint,
+				RekorPublicKey:               o.CommonVerifyOptions.RekorPublicKey,
+				MaxSignatureAge:              o.CommonVerifyOptions.MaxSignatureAge,
 				MaxWorkers:                   o.CommonVerifyOptions.MaxWorkers,
+				SLSA: policy.SLSARequirements{
+					BuilderID: o.SLSABuilderID,
+					SourceURI: o.SLSASourceURI,
+					MinLevel:  o.SLSAMinLevel,
+				},
+				RevocationList:    o.Revocation.List,
+				RevocationListKey: o.Revocation.Key,
+				RevocationListTTL: o.Revocation.TTL,
 			}
 
 			if o.CommonVerifyOptions.MaxWorkers == 0 {
@@ -292,6 +474,12 @@ The blob may be specified as a path to a file or - for stdin.`,
 
   # Verify a signature against a certificate
   cosign verify-blob --certificate <cert> --signature $sig <blob>
+
+  # Verify a signature and write its VerificationResult, with the certificate identity and transparency log entry, as YAML
+  cosign verify-blob --certificate <cert> --signature $sig --output yaml <blob>
+
+  # Verify a blob offline against the bundle written by sign-blob --bundle
+  cosign verify-blob --bundle <blob>.bundle --offline --certificate-identity <identity> --certificate-oidc-issuer <issuer> <blob>
 `,
 
 		Args:             cobra.ExactArgs(1),
@@ -321,6 +509,11 @@ The blob may be specified as a path to a file or - for stdin.`,
 				SCTRef:                       o.CertVerify.SCT,
 				Offline:                      o.CommonVerifyOptions.Offline,
 				IgnoreTlog:                   o.CommonVerifyOptions.IgnoreTlog,
+				RequireOnlineTlog:            o.CommonVerifyOptions.RequireOnlineTlog,
+				RekorCheckpoint:              o.CommonVerifyOptions.RekorCheckpoint,
+				RekorPublicKey:               o.CommonVerifyOptions.RekorPublicKey,
+				MaxSignatureAge:              o.CommonVerifyOptions.MaxSignatureAge,
+				Output:                       o.Output,
 			}
 
 			ctx := cmd.Context()
@@ -353,6 +546,9 @@ The blob may be specified as a path to a file.`,
   # Verify a simple blob attestation with a DSSE style signature
   cosign verify-blob-attestation --key cosign.pub (--signature <sig path>|<sig url>)[path to BLOB]
 
+  # Verify a blob attestation and validate its SLSA provenance predicate against a CUE policy
+  cosign verify-blob-attestation --key cosign.pub --signature <sig path> --type slsaprovenance --policy <CUE_POLICY> [path to BLOB]
+
 `,
 
 		Args:             cobra.MaximumNArgs(1),
@@ -371,6 +567,11 @@ The blob may be specified as a path to a file.`,
 				KeyOpts:                      ko,
 				PredicateType:                o.PredicateOptions.Type,
 				CheckClaims:                  o.CheckClaims,
+				CheckPredicateExpiry:         o.CheckPredicateExpiry,
+				Policies:                     o.Policies,
+				PolicyEngine:                 o.PolicyEngine,
+				RegoQuery:                    o.RegoQuery,
+				PolicyData:                   o.PolicyData,
 				SignaturePath:                o.SignaturePath,
 				CertVerifyOptions:            o.CertVerify,
 				CertRef:                      o.CertVerify.Cert,
@@ -384,6 +585,11 @@ The blob may be specified as a path to a file.`,
 				SCTRef:                       o.CertVerify.SCT,
 				Offline:                      o.CommonVerifyOptions.Offline,
 				IgnoreTlog:                   o.CommonVerifyOptions.IgnoreTlog,
+				RequireOnlineTlog:            o.CommonVerifyOptions.RequireOnlineTlog,
+				RekorCheckpoint:              o.CommonVerifyOptions.RekorCheckpoint,
+				RekorPublicKey:               o.CommonVerifyOptions.RekorPublicKey,
+				MaxSignatureAge:              o.CommonVerifyOptions.MaxSignatureAge,
+				Output:                       o.Output,
 			}
 			// We only use the blob if we are checking claims.
 			if len(args) == 0 && o.CheckClaims {
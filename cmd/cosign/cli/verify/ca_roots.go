@@ -0,0 +1,74 @@
+//
+// Copyright 2023 The Sigstore Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package verify
+
+import (
+	"crypto/x509"
+	"errors"
+	"fmt"
+
+	"github.com/sigstore/cosign/v2/cmd/cosign/cli/fulcio"
+)
+
+// getRoots returns the root and intermediate CA certificates trusted to issue
+// signing certificates. These are read from the caRoots and caIntermediates
+// PEM files for private Fulcio deployments or other PKIs, and are the Fulcio
+// ones otherwise.
+func getRoots(caRoots, caIntermediates string) (*x509.CertPool, *x509.CertPool, error) {
+	if caRoots == "" {
+		if caIntermediates != "" {
+			return nil, nil, errors.New("--ca-intermediates requires --ca-roots")
+		}
+		// This performs an online fetch of the Fulcio roots. This is needed
+		// for verifying keyless certificates (both online and offline).
+		roots, err := fulcio.GetRoots()
+		if err != nil {
+			return nil, nil, fmt.Errorf("getting Fulcio roots: %w", err)
+		}
+		intermediates, err := fulcio.GetIntermediates()
+		if err != nil {
+			return nil, nil, fmt.Errorf("getting Fulcio intermediates: %w", err)
+		}
+		return roots, intermediates, nil
+	}
+
+	roots, err := loadCertPool(caRoots)
+	if err != nil {
+		return nil, nil, fmt.Errorf("loading CA roots: %w", err)
+	}
+	var intermediates *x509.CertPool
+	if caIntermediates != "" {
+		if intermediates, err = loadCertPool(caIntermediates); err != nil {
+			return nil, nil, fmt.Errorf("loading CA intermediates: %w", err)
+		}
+	}
+	return roots, intermediates, nil
+}
+
+func loadCertPool(path string) (*x509.CertPool, error) {
+	certs, err := loadCertChainFromFileOrURL(path)
+	if err != nil {
+		return nil, err
+	}
+	if len(certs) == 0 {
+		return nil, fmt.Errorf("no certificates found in %s", path)
+	}
+	pool := x509.NewCertPool()
+	for _, cert := range certs {
+		pool.AddCert(cert)
+	}
+	return pool, nil
+}
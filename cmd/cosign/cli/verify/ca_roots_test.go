@@ -0,0 +1,61 @@
+//
+// Copyright 2023 The Sigstore Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package verify
+
+import (
+	"crypto/x509"
+	"testing"
+
+	"github.com/sigstore/cosign/v2/test"
+	"github.com/sigstore/sigstore/pkg/cryptoutils"
+)
+
+func TestGetRootsFromFiles(t *testing.T) {
+	td := t.TempDir()
+	rootCert, rootPriv, _ := test.GenerateRootCa()
+	subCert, subPriv, _ := test.GenerateSubordinateCa(rootCert, rootPriv)
+	leafCert, _, _ := test.GenerateLeafCert("subject@mail.com", "oidc-issuer", subCert, subPriv)
+	otherRoot, _, _ := test.GenerateRootCa()
+
+	rootPEM, _ := cryptoutils.MarshalCertificateToPEM(rootCert)
+	subPEM, _ := cryptoutils.MarshalCertificateToPEM(subCert)
+	otherPEM, _ := cryptoutils.MarshalCertificateToPEM(otherRoot)
+	rootsPath := writeBlobFile(t, td, string(rootPEM)+string(otherPEM), "roots.pem")
+	subPath := writeBlobFile(t, td, string(subPEM), "intermediates.pem")
+	emptyPath := writeBlobFile(t, td, "", "empty.pem")
+
+	roots, intermediates, err := getRoots(rootsPath, subPath)
+	if err != nil {
+		t.Fatalf("getRoots() = %v", err)
+	}
+	if _, err := leafCert.Verify(x509.VerifyOptions{
+		Roots:         roots,
+		Intermediates: intermediates,
+		KeyUsages:     []x509.ExtKeyUsage{x509.ExtKeyUsageCodeSigning},
+	}); err != nil {
+		t.Errorf("leaf certificate does not chain to the CA files: %v", err)
+	}
+
+	if _, intermediates, err := getRoots(rootsPath, ""); err != nil || intermediates != nil {
+		t.Errorf("getRoots() without intermediates = %v, %v, want nil intermediates", intermediates, err)
+	}
+	if _, _, err := getRoots("", subPath); err == nil {
+		t.Error("getRoots() with intermediates but no roots succeeded")
+	}
+	if _, _, err := getRoots(emptyPath, ""); err == nil {
+		t.Error("getRoots() with an empty roots file succeeded")
+	}
+}
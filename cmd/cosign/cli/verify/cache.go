@@ -0,0 +1,313 @@
+//
+// Copyright 2023 The Sigstore Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package verify
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/google/go-containerregistry/pkg/v1/types"
+	"github.com/sigstore/cosign/v2/cmd/cosign/cli/options"
+	"github.com/sigstore/cosign/v2/pkg/cosign"
+	"github.com/sigstore/cosign/v2/pkg/cosign/bundle"
+	"github.com/sigstore/cosign/v2/pkg/oci"
+	"github.com/sigstore/cosign/v2/pkg/oci/static"
+	"github.com/sigstore/sigstore/pkg/cryptoutils"
+	"github.com/sigstore/sigstore/pkg/signature"
+)
+
+// verificationCache stores the signatures verified for an image digest, so
+// that verifying the digest again with the same policy before the entry
+// expires skips the registry and the transparency log. Entries are keyed by
+// the digest and a hash of everything that decides whether a signature is
+// accepted, so changing any verification option misses the cache.
+type verificationCache struct {
+	dir    string
+	policy string
+	ttl    time.Duration
+	now    func() time.Time
+}
+
+type cacheEntry struct {
+	Digest         string            `json:"digest"`
+	Policy         string            `json:"policy"`
+	Expires        time.Time         `json:"expires"`
+	BundleVerified bool              `json:"bundleVerified"`
+	Signatures     []cachedSignature `json:"signatures"`
+	Signers        []string          `json:"signers,omitempty"`
+}
+
+// cachedSignature holds what is needed to rebuild a verified signature with
+// the static package.
+type cachedSignature struct {
+	Payload          []byte                   `json:"payload"`
+	Base64Signature  string                   `json:"base64Signature"`
+	MediaType        types.MediaType          `json:"mediaType,omitempty"`
+	Annotations      map[string]string        `json:"annotations,omitempty"`
+	Certificate      []byte                   `json:"certificate,omitempty"`
+	Chain            []byte                   `json:"chain,omitempty"`
+	Bundle           *bundle.RekorBundle      `json:"bundle,omitempty"`
+	RFC3161Timestamp *bundle.RFC3161Timestamp `json:"rfc3161Timestamp,omitempty"`
+}
+
+// newVerificationCache returns a cache in dir for the verification policy
+// described by policy, which is hashed into the key of every entry.
+func newVerificationCache(dir string, ttl time.Duration, policy interface{}) (*verificationCache, error) {
+	b, err := json.Marshal(policy)
+	if err != nil {
+		return nil, fmt.Errorf("hashing verification policy: %w", err)
+	}
+	sum := sha256.Sum256(b)
+	return &verificationCache{
+		dir:    dir,
+		policy: hex.EncodeToString(sum[:]),
+		ttl:    ttl,
+		now:    time.Now,
+	}, nil
+}
+
+func (c *verificationCache) path(digest string) string {
+	sum := sha256.Sum256([]byte(c.policy + "\n" + digest))
+	return filepath.Join(c.dir, hex.EncodeToString(sum[:])+".json")
+}
+
+// get returns the verification cached for digest, without its ref and
+// digest, and ok false when there is no unexpired entry.
+func (c *verificationCache) get(digest string) (v *signatureVerification, ok bool, err error) {
+	b, err := os.ReadFile(c.path(digest))
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, err
+	}
+	var entry cacheEntry
+	if err := json.Unmarshal(b, &entry); err != nil {
+		return nil, false, fmt.Errorf("decoding cache entry: %w", err)
+	}
+	if entry.Digest != digest || entry.Policy != c.policy || !c.now().Before(entry.Expires) || len(entry.Signatures) == 0 {
+		return nil, false, nil
+	}
+	v = &signatureVerification{bundleVerified: entry.BundleVerified, signers: entry.Signers}
+	for _, cs := range entry.Signatures {
+		opts := []static.Option{
+			static.WithLayerMediaType(cs.MediaType),
+			static.WithAnnotations(cs.Annotations),
+			static.WithBundle(cs.Bundle),
+			static.WithRFC3161Timestamp(cs.RFC3161Timestamp),
+		}
+		if cs.Certificate != nil {
+			opts = append(opts, static.WithCertChain(cs.Certificate, cs.Chain))
+		}
+		sig, err := static.NewSignature(cs.Payload, cs.Base64Signature, opts...)
+		if err != nil {
+			return nil, false, err
+		}
+		v.verified = append(v.verified, sig)
+	}
+	return v, true, nil
+}
+
+// put caches the verification v of digest.
+func (c *verificationCache) put(digest string, v *signatureVerification) error {
+	entry := cacheEntry{
+		Digest:         digest,
+		Policy:         c.policy,
+		Expires:        c.now().Add(c.ttl).UTC(),
+		BundleVerified: v.bundleVerified,
+		Signers:        v.signers,
+	}
+	for _, sig := range v.verified {
+		cs, err := newCachedSignature(sig)
+		if err != nil {
+			return err
+		}
+		entry.Signatures = append(entry.Signatures, cs)
+	}
+	b, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(c.dir, 0o700); err != nil {
+		return err
+	}
+	// Write to a temporary file first, so that concurrent readers never
+	// see a partial entry.
+	f, err := os.CreateTemp(c.dir, ".entry-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(f.Name())
+	if _, err := f.Write(b); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	return os.Rename(f.Name(), c.path(digest))
+}
+
+func newCachedSignature(sig oci.Signature) (cachedSignature, error) {
+	var cs cachedSignature
+	var err error
+	if cs.Payload, err = sig.Payload(); err != nil {
+		return cs, err
+	}
+	if cs.Base64Signature, err = sig.Base64Signature(); err != nil {
+		return cs, err
+	}
+	if cs.MediaType, err = sig.MediaType(); err != nil {
+		return cs, err
+	}
+	if cs.Annotations, err = sig.Annotations(); err != nil {
+		return cs, err
+	}
+	if cs.Bundle, err = sig.Bundle(); err != nil {
+		return cs, err
+	}
+	if cs.RFC3161Timestamp, err = sig.RFC3161Timestamp(); err != nil {
+		return cs, err
+	}
+	cert, err := sig.Cert()
+	if err != nil {
+		return cs, err
+	}
+	if cert != nil {
+		if cs.Certificate, err = cryptoutils.MarshalCertificateToPEM(cert); err != nil {
+			return cs, err
+		}
+		chain, err := sig.Chain()
+		if err != nil {
+			return cs, err
+		}
+		if cs.Chain, err = cryptoutils.MarshalCertificatesToPEM(chain); err != nil {
+			return cs, err
+		}
+	}
+	return cs, nil
+}
+
+// verificationPolicy holds the options of a VerifyCommand that decide
+// whether a signature is accepted. Files and the trust policy are
+// represented by the digest of their content, and keys by their PEM encoding.
+type verificationPolicy struct {
+	CertVerify        options.CertVerifyOptions
+	CheckClaims       bool
+	Key               string
+	PublicKey         string
+	Certificate       string
+	CertChain         string
+	CARoots           string
+	CAIntermediates   string
+	SCT               string
+	Sk                bool
+	Slot              string
+	RekorURL          string
+	Attachment        string
+	Annotations       map[string]interface{}
+	SignatureRef      string
+	PayloadRef        string
+	HashAlgorithm     string
+	Offline           bool
+	TSACertChain      string
+	IgnoreTlog        bool
+	RequireOnlineTlog bool
+	RekorCheckpoint   string
+	RekorPublicKey    string
+	MaxSignatureAge   time.Duration
+	ThresholdPolicy   string
+	TrustPolicy       string
+	PIVAttestation    options.PIVAttestationOptions
+}
+
+// newCache returns the verification cache for the options of c, whose
+// public key, if any, has been loaded into co.
+func (c *VerifyCommand) newCache(dir string, co *cosign.CheckOpts) (*verificationCache, error) {
+	policy := verificationPolicy{
+		CertVerify:        c.CertVerifyOptions,
+		CheckClaims:       c.CheckClaims,
+		Key:               c.KeyRef,
+		Certificate:       fileDigest(c.CertRef),
+		CertChain:         fileDigest(c.CertChain),
+		CARoots:           fileDigest(c.CARoots),
+		CAIntermediates:   fileDigest(c.CAIntermediates),
+		SCT:               fileDigest(c.SCTRef),
+		Sk:                c.Sk,
+		Slot:              c.Slot,
+		RekorURL:          c.RekorURL,
+		Attachment:        c.Attachment,
+		Annotations:       c.Annotations.Annotations,
+		SignatureRef:      c.SignatureRef,
+		PayloadRef:        c.PayloadRef,
+		HashAlgorithm:     c.HashAlgorithm.String(),
+		Offline:           c.Offline,
+		TSACertChain:      fileDigest(c.TSACertChainPath),
+		IgnoreTlog:        c.IgnoreTlog,
+		RequireOnlineTlog: c.RequireOnlineTlog,
+		RekorCheckpoint:   fileDigest(c.RekorCheckpoint),
+		RekorPublicKey:    c.RekorPublicKey + fileDigest(c.RekorPublicKey),
+		MaxSignatureAge:   c.MaxSignatureAge,
+		ThresholdPolicy:   fileDigest(c.ThresholdPolicy),
+		TrustPolicy:       c.TrustPolicy + c.trustPolicyDigest,
+		PIVAttestation: options.PIVAttestationOptions{
+			Roots:         c.PIVAttestation.Roots + fileDigest(c.PIVAttestation.Roots),
+			PINPolicies:   c.PIVAttestation.PINPolicies,
+			TouchPolicies: c.PIVAttestation.TouchPolicies,
+		},
+	}
+	if co.SigVerifier != nil {
+		verifiers := []signature.Verifier{co.SigVerifier}
+		if keys, ok := co.SigVerifier.(cosign.TrustedKeys); ok {
+			verifiers = keys
+		}
+		for _, v := range verifiers {
+			pub, err := v.PublicKey()
+			if err != nil {
+				return nil, fmt.Errorf("hashing verification policy: %w", err)
+			}
+			pem, err := cryptoutils.MarshalPublicKeyToPEM(pub)
+			if err != nil {
+				return nil, fmt.Errorf("hashing verification policy: %w", err)
+			}
+			policy.PublicKey += string(pem)
+		}
+	}
+	return newVerificationCache(dir, c.CacheTTL, policy)
+}
+
+// fileDigest returns the sha256 digest of the file at path, so that editing a
+// file referenced by the verification options changes the policy hash, or
+// "" when path is not a readable local file, e.g. a URL or KMS reference.
+func fileDigest(path string) string {
+	if path == "" {
+		return ""
+	}
+	b, err := os.ReadFile(filepath.Clean(path))
+	if err != nil {
+		return ""
+	}
+	sum := sha256.Sum256(b)
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,129 @@
+//
+// Copyright 2023 The Sigstore Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package verify
+
+import (
+	"os"
+	"testing"
+	"time"
+
+	"github.com/sigstore/cosign/v2/pkg/cosign"
+	"github.com/sigstore/cosign/v2/pkg/cosign/bundle"
+	"github.com/sigstore/cosign/v2/pkg/oci"
+	"github.com/sigstore/cosign/v2/pkg/oci/static"
+)
+
+func TestVerificationCache(t *testing.T) {
+	dir := t.TempDir()
+	const digest = "sha256:0000000000000000000000000000000000000000000000000000000000000000"
+	now := time.Date(2023, 6, 1, 12, 0, 0, 0, time.UTC)
+
+	newCache := func(t *testing.T, policy verificationPolicy) *verificationCache {
+		t.Helper()
+		c, err := newVerificationCache(dir, time.Hour, policy)
+		if err != nil {
+			t.Fatal(err)
+		}
+		c.now = func() time.Time { return now }
+		return c
+	}
+
+	sig, err := static.NewSignature([]byte(`{"critical":{}}`), "c2lnbmF0dXJl",
+		static.WithAnnotations(map[string]string{"foo": "bar"}),
+		static.WithBundle(&bundle.RekorBundle{Payload: bundle.RekorPayload{LogIndex: 42}}))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	c := newCache(t, verificationPolicy{Key: "cosign.pub"})
+	if _, ok, err := c.get(digest); ok || err != nil {
+		t.Fatalf("get() on an empty cache = %t, %v", ok, err)
+	}
+	if err := c.put(digest, &signatureVerification{verified: []oci.Signature{sig}, signers: []string{"key cosign.pub"}, bundleVerified: true}); err != nil {
+		t.Fatalf("put() = %v", err)
+	}
+
+	v, ok, err := c.get(digest)
+	if !ok || err != nil {
+		t.Fatalf("get() = %t, %v", ok, err)
+	}
+	verified := v.verified
+	if !v.bundleVerified || len(verified) != 1 || len(v.signers) != 1 {
+		t.Fatalf("get() = %d signatures, signers %v, bundle verified %t", len(verified), v.signers, v.bundleVerified)
+	}
+	payload, _ := verified[0].Payload()
+	b64sig, _ := verified[0].Base64Signature()
+	ann, _ := verified[0].Annotations()
+	b, _ := verified[0].Bundle()
+	if string(payload) != `{"critical":{}}` || b64sig != "c2lnbmF0dXJl" || ann["foo"] != "bar" || b == nil || b.Payload.LogIndex != 42 {
+		t.Errorf("cached signature = %s %s %v %v", payload, b64sig, ann, b)
+	}
+
+	if _, ok, _ := c.get("sha256:1111111111111111111111111111111111111111111111111111111111111111"); ok {
+		t.Error("get() of another digest hit the cache")
+	}
+	if _, ok, _ := newCache(t, verificationPolicy{Key: "other.pub"}).get(digest); ok {
+		t.Error("get() with another policy hit the cache")
+	}
+
+	now = now.Add(2 * time.Hour)
+	if _, ok, _ := c.get(digest); ok {
+		t.Error("get() of an expired entry hit the cache")
+	}
+}
+
+func TestVerificationCacheCorruptEntry(t *testing.T) {
+	c, err := newVerificationCache(t.TempDir(), time.Hour, verificationPolicy{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	const digest = "sha256:0000000000000000000000000000000000000000000000000000000000000000"
+	if err := os.WriteFile(c.path(digest), []byte("{"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok, err := c.get(digest); ok || err == nil {
+		t.Errorf("get() of a corrupt entry = %t, %v, want an error", ok, err)
+	}
+}
+
+func TestNewCacheTrustPolicy(t *testing.T) {
+	dir := t.TempDir()
+	const digest = "sha256:0000000000000000000000000000000000000000000000000000000000000000"
+
+	newCache := func(t *testing.T, policyDigest string) *verificationCache {
+		t.Helper()
+		c := &VerifyCommand{TrustPolicy: "registry.example.com/team", CacheTTL: time.Hour, trustPolicyDigest: policyDigest}
+		cache, err := c.newCache(dir, &cosign.CheckOpts{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return cache
+	}
+
+	sig, err := static.NewSignature([]byte(`{"critical":{}}`), "c2lnbmF0dXJl")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := newCache(t, "aaaa").put(digest, &signatureVerification{verified: []oci.Signature{sig}}); err != nil {
+		t.Fatalf("put() = %v", err)
+	}
+	if _, ok, _ := newCache(t, "aaaa").get(digest); !ok {
+		t.Error("get() with the same trust policy missed the cache")
+	}
+	if _, ok, _ := newCache(t, "bbbb").get(digest); ok {
+		t.Error("get() after the trust policy changed hit the cache")
+	}
+}
@@ -0,0 +1,254 @@
+//
+// Copyright 2023 The Sigstore Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package verify
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/google/go-containerregistry/pkg/name"
+	"github.com/nozzle/throttler"
+
+	"github.com/sigstore/cosign/v2/internal/pkg/cosign"
+	"github.com/sigstore/cosign/v2/internal/pkg/oci/daemon"
+	"github.com/sigstore/cosign/v2/internal/ui"
+	"github.com/sigstore/cosign/v2/pkg/oci"
+	ociplatform "github.com/sigstore/cosign/v2/pkg/oci/platform"
+	ociremote "github.com/sigstore/cosign/v2/pkg/oci/remote"
+)
+
+// verifyImagesConcurrently calls verify for every image with at most workers
+// images being verified at once. Unless continueOnError is set, the images that
+// have not started yet are skipped once an image fails. The returned errors are
+// indexed like images.
+func verifyImagesConcurrently(ctx context.Context, images []string, workers int, continueOnError bool, verify func(ctx context.Context, i int, image string) error) []error {
+	if workers <= 0 {
+		workers = cosign.DefaultMaxWorkers
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	errs := make([]error, len(images))
+	t := throttler.New(workers, len(images))
+	for i, image := range images {
+		go func(i int, image string) {
+			if ctx.Err() != nil {
+				// Another image failed.
+				t.Done(nil)
+				return
+			}
+			err := verify(ctx, i, image)
+			if err != nil && !(errors.Is(err, context.Canceled) && ctx.Err() != nil) {
+				errs[i] = err
+				if !continueOnError {
+					cancel()
+				}
+			}
+			t.Done(err)
+		}(i, image)
+
+		// wait till workers are available
+		t.Throttle()
+	}
+	return errs
+}
+
+// joinImageErrors combines the verification errors of images. The error of a
+// single image is returned as is, otherwise each error names its image.
+func joinImageErrors(images []string, errs []error) error {
+	if len(images) == 1 {
+		return errs[0]
+	}
+	var failed []error
+	for i, err := range errs {
+		if err != nil {
+			failed = append(failed, fmt.Errorf("%s: %w", images[i], err))
+		}
+	}
+	return errors.Join(failed...)
+}
+
+// printVerificationSummary prints the verification status of every image, so
+// that the result of a batch verification can be read at a glance.
+func printVerificationSummary(ctx context.Context, images []string, errs []error) {
+	rows := make([]StatusRow, len(images))
+	failed := 0
+	for i, image := range images {
+		rows[i] = StatusRow{Image: image, Status: "verified"}
+		if errs[i] != nil {
+			failed++
+			rows[i].Status, rows[i].Error = "failed", errs[i].Error()
+		}
+	}
+	PrintStatusTable(ctx, rows, fmt.Sprintf("%d of %d images failed verification", failed, len(images)))
+}
+
+// StatusRow is the verification status of an image, printed by
+// PrintStatusTable.
+type StatusRow struct {
+	Image  string
+	Status string
+	Error  string
+}
+
+// PrintStatusTable prints rows as a table followed by footer. Only the first
+// line of each error is printed, to keep the table readable.
+func PrintStatusTable(ctx context.Context, rows []StatusRow, footer string) {
+	var b bytes.Buffer
+	w := tabwriter.NewWriter(&b, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "IMAGE\tSTATUS\tERROR")
+	for _, r := range rows {
+		msg, _, _ := strings.Cut(r.Error, "\n")
+		fmt.Fprintf(w, "%s\t%s\t%s\n", r.Image, r.Status, msg)
+	}
+	_ = w.Flush()
+	ui.Infof(ctx, "\n%s%s", b.String(), footer)
+}
+
+// expandImageIndexes returns images with the manifests of every image index
+// among them added after the index, so that each platform is verified on its
+// own. platforms holds the platform of each of the returned images that was
+// found in an index, or "" for the images that were given.
+func expandImageIndexes(images []string, nameOpts []name.Option, opts ...ociremote.Option) (expanded, platforms []string, err error) {
+	for _, img := range images {
+		ref, err := name.ParseReference(img, nameOpts...)
+		if err != nil {
+			return nil, nil, fmt.Errorf("parsing reference: %w", err)
+		}
+		expanded = append(expanded, img)
+		platforms = append(platforms, "")
+		if err := appendIndexManifests(ref, "", opts, &expanded, &platforms); err != nil {
+			return nil, nil, fmt.Errorf("expanding image index %s: %w", img, err)
+		}
+	}
+	return expanded, platforms, nil
+}
+
+// appendIndexManifests appends the manifests of ref, and of its nested
+// indexes, if ref is an image index.
+func appendIndexManifests(ref name.Reference, platform string, opts []ociremote.Option, expanded, platforms *[]string) error {
+	se, err := ociremote.SignedEntity(ref, opts...)
+	if err != nil {
+		return err
+	}
+	sii, ok := se.(oci.SignedImageIndex)
+	if !ok {
+		return nil
+	}
+	im, err := sii.IndexManifest()
+	if err != nil {
+		return err
+	}
+	for _, m := range im.Manifests {
+		child := ref.Context().Digest(m.Digest.String())
+		p := platform
+		if m.Platform != nil {
+			p = m.Platform.String()
+		}
+		if m.MediaType.IsIndex() {
+			if err := appendIndexManifests(child, p, opts, expanded, platforms); err != nil {
+				return err
+			}
+			continue
+		}
+		*expanded = append(*expanded, child.String())
+		*platforms = append(*platforms, p)
+	}
+	return nil
+}
+
+// withPlatform appends the platform of the i-th image, if any, to ref.
+func withPlatform(ref string, platforms []string, i int) string {
+	if i < len(platforms) && platforms[i] != "" {
+		return fmt.Sprintf("%s (%s)", ref, platforms[i])
+	}
+	return ref
+}
+
+// resolvePlatformImages replaces each of images, which must be image indexes,
+// with the digest of its manifest for platform, so that only that platform is
+// verified. The returned platforms hold the platform of each image.
+func resolvePlatformImages(images []string, platform string, nameOpts []name.Option, opts ...ociremote.Option) (resolved, platforms []string, err error) {
+	for _, img := range images {
+		ref, err := name.ParseReference(img, nameOpts...)
+		if err != nil {
+			return nil, nil, fmt.Errorf("parsing reference: %w", err)
+		}
+		se, err := ociremote.SignedEntity(ref, opts...)
+		if err != nil {
+			return nil, nil, fmt.Errorf("fetching %s: %w", img, err)
+		}
+		se, err = ociplatform.SignedEntityForPlatform(se, platform)
+		if err != nil {
+			return nil, nil, fmt.Errorf("resolving platform %s of %s: %w", platform, img, err)
+		}
+		d, err := se.Digest()
+		if err != nil {
+			return nil, nil, fmt.Errorf("getting digest of %s for platform %s: %w", img, platform, err)
+		}
+		resolved = append(resolved, ref.Context().Digest(d.String()).String())
+		platforms = append(platforms, platform)
+	}
+	return resolved, platforms, nil
+}
+
+// writeVerifiedDigests writes each of the non-empty digests on its own line to
+// path, or to stdout if path is "-".
+func writeVerifiedDigests(path string, digests []string) error {
+	var b strings.Builder
+	for _, d := range digests {
+		if d != "" {
+			fmt.Fprintln(&b, d)
+		}
+	}
+	if path == "-" {
+		_, err := fmt.Fprint(os.Stdout, b.String())
+		return err
+	}
+	if err := os.WriteFile(path, []byte(b.String()), 0600); err != nil {
+		return fmt.Errorf("writing verified digests: %w", err)
+	}
+	return nil
+}
+
+// resolveDaemonImages replaces each of images given as docker-daemon://IMAGE
+// with the digest the local Docker daemon recorded for it, so that the image
+// the daemon would run is the one that is verified.
+func resolveDaemonImages(ctx context.Context, images []string, nameOpts []name.Option) ([]string, error) {
+	resolved := make([]string, len(images))
+	for i, img := range images {
+		resolved[i] = img
+		if strings.HasPrefix(img, daemon.ContainerdPrefix) {
+			return nil, fmt.Errorf("%s: images in the containerd image store are not supported, export the image to an OCI layout and use --local-image", img)
+		}
+		ref, ok := strings.CutPrefix(img, daemon.Prefix)
+		if !ok {
+			continue
+		}
+		d, err := daemon.ResolveDigest(ctx, ref, nameOpts...)
+		if err != nil {
+			return nil, fmt.Errorf("resolving %s: %w", img, err)
+		}
+		ui.Infof(ctx, "Resolved %s to %s", img, d.String())
+		resolved[i] = d.String()
+	}
+	return resolved, nil
+}
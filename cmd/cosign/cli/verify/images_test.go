@@ -0,0 +1,313 @@
+//
+// Copyright 2023 The Sigstore Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package verify
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/google/go-containerregistry/pkg/name"
+	"github.com/google/go-containerregistry/pkg/registry"
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/empty"
+	"github.com/google/go-containerregistry/pkg/v1/mutate"
+	"github.com/google/go-containerregistry/pkg/v1/random"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
+
+	"github.com/sigstore/cosign/v2/internal/ui"
+)
+
+func TestVerifyImagesConcurrentlyBounded(t *testing.T) {
+	images := []string{"a", "b", "c", "d", "e", "f"}
+	var running, maxRunning int32
+	var mu sync.Mutex
+	seen := map[string]bool{}
+
+	errs := verifyImagesConcurrently(context.Background(), images, 2, false, func(_ context.Context, i int, image string) error {
+		n := atomic.AddInt32(&running, 1)
+		defer atomic.AddInt32(&running, -1)
+		for {
+			m := atomic.LoadInt32(&maxRunning)
+			if n <= m || atomic.CompareAndSwapInt32(&maxRunning, m, n) {
+				break
+			}
+		}
+		time.Sleep(10 * time.Millisecond)
+		mu.Lock()
+		seen[image] = images[i] == image
+		mu.Unlock()
+		return nil
+	})
+
+	if max := atomic.LoadInt32(&maxRunning); max > 2 {
+		t.Errorf("%d images were verified at once, want at most 2", max)
+	}
+	for i, image := range images {
+		if errs[i] != nil {
+			t.Errorf("unexpected error for %s: %v", image, errs[i])
+		}
+		if !seen[image] {
+			t.Errorf("%s was not verified with its index", image)
+		}
+	}
+}
+
+func TestVerifyImagesConcurrentlyFailFast(t *testing.T) {
+	images := []string{"bad", "b", "c", "d"}
+	var verified int32
+
+	errs := verifyImagesConcurrently(context.Background(), images, 1, false, func(ctx context.Context, _ int, image string) error {
+		atomic.AddInt32(&verified, 1)
+		if image == "bad" {
+			return errors.New("no matching signatures")
+		}
+		return ctx.Err()
+	})
+
+	if errs[0] == nil {
+		t.Fatal("expected an error for the first image")
+	}
+	for i, err := range errs[1:] {
+		if err != nil {
+			t.Errorf("skipped image %s reported error %v", images[i+1], err)
+		}
+	}
+	if n := atomic.LoadInt32(&verified); n != 1 {
+		t.Errorf("%d images were verified after the first failure, want none", n-1)
+	}
+}
+
+func TestVerifyImagesConcurrentlyContinueOnError(t *testing.T) {
+	images := []string{"a", "bad", "c", "worse"}
+
+	errs := verifyImagesConcurrently(context.Background(), images, 1, true, func(ctx context.Context, _ int, image string) error {
+		if strings.HasPrefix(image, "bad") || image == "worse" {
+			return errors.New("no matching signatures")
+		}
+		return ctx.Err()
+	})
+
+	for i, image := range images {
+		wantErr := image == "bad" || image == "worse"
+		if (errs[i] != nil) != wantErr {
+			t.Errorf("%s: error = %v, wantErr %t", image, errs[i], wantErr)
+		}
+	}
+}
+
+func TestPrintVerificationSummary(t *testing.T) {
+	images := []string{"example.com/app:v1", "example.com/db:v2"}
+	errs := []error{nil, errors.New("no matching signatures:\nsignature mismatch")}
+
+	out := ui.RunWithTestCtx(func(ctx context.Context, _ ui.WriteFunc) {
+		printVerificationSummary(ctx, images, errs)
+	})
+
+	for _, want := range []string{
+		"IMAGE               STATUS    ERROR",
+		"example.com/app:v1  verified",
+		"example.com/db:v2   failed    no matching signatures:",
+		"1 of 2 images failed verification",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("summary %q does not contain %q", out, want)
+		}
+	}
+	if strings.Contains(out, "signature mismatch") {
+		t.Errorf("summary %q should only contain the first line of the errors", out)
+	}
+}
+
+func TestJoinImageErrors(t *testing.T) {
+	errA := errors.New("no matching signatures")
+	errC := errors.New("manifest unknown")
+
+	if err := joinImageErrors([]string{"a"}, []error{errA}); err != errA {
+		t.Errorf("joinImageErrors() = %v, want the error of the single image", err)
+	}
+	if err := joinImageErrors([]string{"a", "b"}, []error{nil, nil}); err != nil {
+		t.Errorf("joinImageErrors() = %v, want nil", err)
+	}
+
+	err := joinImageErrors([]string{"a", "b", "c"}, []error{errA, nil, errC})
+	if !errors.Is(err, errA) || !errors.Is(err, errC) {
+		t.Fatalf("joinImageErrors() = %v, want both errors", err)
+	}
+	for _, want := range []string{"a: no matching signatures", "c: manifest unknown"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("joinImageErrors() = %q, want it to contain %q", err, want)
+		}
+	}
+}
+
+func TestExpandImageIndexes(t *testing.T) {
+	s := httptest.NewServer(registry.New(registry.Logger(log.New(io.Discard, "", 0))))
+	defer s.Close()
+	u, err := url.Parse(s.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var adds []mutate.IndexAddendum
+	for _, arch := range []string{"amd64", "arm64"} {
+		img, err := random.Image(100, 1)
+		if err != nil {
+			t.Fatal(err)
+		}
+		adds = append(adds, mutate.IndexAddendum{
+			Add:        img,
+			Descriptor: v1.Descriptor{Platform: &v1.Platform{OS: "linux", Architecture: arch}},
+		})
+	}
+	idx := mutate.AppendManifests(empty.Index, adds...)
+	idxRef, err := name.ParseReference(u.Host + "/app:multi")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := remote.WriteIndex(idxRef, idx); err != nil {
+		t.Fatal(err)
+	}
+	img, err := random.Image(100, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	imgRef, err := name.ParseReference(u.Host + "/app:single")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := remote.Write(imgRef, img); err != nil {
+		t.Fatal(err)
+	}
+
+	images, platforms, err := expandImageIndexes([]string{idxRef.String(), imgRef.String()}, nil)
+	if err != nil {
+		t.Fatalf("expandImageIndexes() = %v", err)
+	}
+	im, err := idx.IndexManifest()
+	if err != nil {
+		t.Fatal(err)
+	}
+	wantImages := []string{
+		idxRef.String(),
+		idxRef.Context().Digest(im.Manifests[0].Digest.String()).String(),
+		idxRef.Context().Digest(im.Manifests[1].Digest.String()).String(),
+		imgRef.String(),
+	}
+	wantPlatforms := []string{"", "linux/amd64", "linux/arm64", ""}
+	if strings.Join(images, ",") != strings.Join(wantImages, ",") {
+		t.Errorf("images = %v, want %v", images, wantImages)
+	}
+	if strings.Join(platforms, ",") != strings.Join(wantPlatforms, ",") {
+		t.Errorf("platforms = %v, want %v", platforms, wantPlatforms)
+	}
+	if got := withPlatform(images[1], platforms, 1); got != images[1]+" (linux/amd64)" {
+		t.Errorf("withPlatform() = %s", got)
+	}
+}
+
+func TestResolvePlatformImages(t *testing.T) {
+	s := httptest.NewServer(registry.New(registry.Logger(log.New(io.Discard, "", 0))))
+	defer s.Close()
+	u, err := url.Parse(s.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var adds []mutate.IndexAddendum
+	for _, arch := range []string{"amd64", "arm64"} {
+		img, err := random.Image(100, 1)
+		if err != nil {
+			t.Fatal(err)
+		}
+		adds = append(adds, mutate.IndexAddendum{
+			Add:        img,
+			Descriptor: v1.Descriptor{Platform: &v1.Platform{OS: "linux", Architecture: arch}},
+		})
+	}
+	idx := mutate.AppendManifests(empty.Index, adds...)
+	idxRef, err := name.ParseReference(u.Host + "/app:multi")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := remote.WriteIndex(idxRef, idx); err != nil {
+		t.Fatal(err)
+	}
+	img, err := random.Image(100, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	imgRef, err := name.ParseReference(u.Host + "/app:single")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := remote.Write(imgRef, img); err != nil {
+		t.Fatal(err)
+	}
+
+	images, platforms, err := resolvePlatformImages([]string{idxRef.String()}, "linux/arm64", nil)
+	if err != nil {
+		t.Fatalf("resolvePlatformImages() = %v", err)
+	}
+	im, err := idx.IndexManifest()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := idxRef.Context().Digest(im.Manifests[1].Digest.String()).String()
+	if len(images) != 1 || images[0] != want {
+		t.Errorf("images = %v, want [%s]", images, want)
+	}
+	if len(platforms) != 1 || platforms[0] != "linux/arm64" {
+		t.Errorf("platforms = %v, want [linux/arm64]", platforms)
+	}
+
+	if _, _, err := resolvePlatformImages([]string{idxRef.String()}, "linux/s390x", nil); err == nil {
+		t.Error("resolvePlatformImages() with a missing platform succeeded")
+	}
+	if _, _, err := resolvePlatformImages([]string{imgRef.String()}, "linux/arm64", nil); err == nil {
+		t.Error("resolvePlatformImages() with a single-platform image succeeded")
+	}
+}
+
+func TestWriteVerifiedDigests(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "digests")
+	digests := []string{
+		"example.com/app@sha256:1111111111111111111111111111111111111111111111111111111111111111",
+		"",
+		"example.com/app@sha256:2222222222222222222222222222222222222222222222222222222222222222",
+	}
+	if err := writeVerifiedDigests(path, digests); err != nil {
+		t.Fatalf("writeVerifiedDigests() = %v", err)
+	}
+	got, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := digests[0] + "\n" + digests[2] + "\n"
+	if string(got) != want {
+		t.Errorf("digests file = %q, want %q", got, want)
+	}
+}
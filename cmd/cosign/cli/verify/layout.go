@@ -0,0 +1,232 @@
+//
+// Copyright 2023 The Sigstore Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package verify
+
+import (
+	"context"
+	"crypto"
+	"crypto/ed25519"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/google/go-containerregistry/pkg/name"
+	"github.com/in-toto/in-toto-golang/in_toto"
+	"github.com/sigstore/sigstore/pkg/cryptoutils"
+	"github.com/sigstore/sigstore/pkg/signature"
+
+	"github.com/sigstore/cosign/v2/internal/ui"
+	"github.com/sigstore/cosign/v2/pkg/cosign"
+	"github.com/sigstore/cosign/v2/pkg/cosign/attestation"
+	"github.com/sigstore/cosign/v2/pkg/oci"
+)
+
+// layoutVerifier verifies the link attestations of an image against the
+// steps, inspections and key thresholds of an in-toto layout.
+type layoutVerifier struct {
+	path   string
+	layout in_toto.Layout
+	// functionaries are the verifiers of the keys of the layout by key ID.
+	functionaries map[string]signature.Verifier
+	inspectionDir string
+}
+
+// newLayoutVerifier loads the layout at path, which must be signed by all
+// the in-toto public keys at keyPaths and not have expired.
+func newLayoutVerifier(path string, keyPaths []string, inspectionDir string) (*layoutVerifier, error) {
+	mb, err := in_toto.LoadMetadata(path)
+	if err != nil {
+		return nil, fmt.Errorf("loading layout %s: %w", path, err)
+	}
+	keys := make(map[string]in_toto.Key, len(keyPaths))
+	for _, p := range keyPaths {
+		var k in_toto.Key
+		if err := k.LoadKeyDefaults(p); err != nil {
+			return nil, fmt.Errorf("loading layout key %s: %w", p, err)
+		}
+		keys[k.KeyID] = k
+	}
+	if err := in_toto.VerifyLayoutSignatures(mb, keys); err != nil {
+		return nil, fmt.Errorf("verifying the signatures of layout %s: %w", path, err)
+	}
+	layout, ok := mb.GetPayload().(in_toto.Layout)
+	if !ok {
+		return nil, fmt.Errorf("%s is not an in-toto layout", path)
+	}
+	if err := in_toto.VerifyLayoutExpiration(layout); err != nil {
+		return nil, fmt.Errorf("layout %s: %w", path, err)
+	}
+
+	l := &layoutVerifier{
+		path:          path,
+		layout:        layout,
+		functionaries: make(map[string]signature.Verifier, len(layout.Keys)),
+		inspectionDir: inspectionDir,
+	}
+	for keyID, k := range layout.Keys {
+		v, err := functionaryVerifier(k)
+		if err != nil {
+			return nil, fmt.Errorf("loading functionary key %s of layout %s: %w", keyID, path, err)
+		}
+		l.functionaries[keyID] = v
+	}
+	for _, step := range layout.Steps {
+		for _, keyID := range step.PubKeys {
+			if _, ok := l.functionaries[keyID]; !ok {
+				return nil, fmt.Errorf("step %s of layout %s: key %s is not one of the layout keys", step.Name, path, keyID)
+			}
+		}
+	}
+	return l, nil
+}
+
+// functionaryVerifier returns a verifier for the public key of k. in-toto
+// stores RSA and ECDSA keys PEM-encoded and ed25519 keys hex-encoded.
+func functionaryVerifier(k in_toto.Key) (signature.Verifier, error) {
+	var pub crypto.PublicKey
+	if k.KeyType == "ed25519" {
+		b, err := hex.DecodeString(k.KeyVal.Public)
+		if err != nil {
+			return nil, err
+		}
+		if len(b) != ed25519.PublicKeySize {
+			return nil, fmt.Errorf("invalid ed25519 public key size %d", len(b))
+		}
+		pub = ed25519.PublicKey(b)
+	} else {
+		var err error
+		if pub, err = cryptoutils.UnmarshalPEMToPublicKey([]byte(k.KeyVal.Public)); err != nil {
+			return nil, err
+		}
+	}
+	return signature.LoadVerifier(pub, crypto.SHA256)
+}
+
+// verify verifies the link attestations of digest, each of them signed by
+// a functionary of its step and checked like any other attestation with co.
+// It returns the link attestations that counted towards the thresholds.
+func (l *layoutVerifier) verify(ctx context.Context, digest name.Digest, co *cosign.CheckOpts) ([]oci.Signature, bool, error) {
+	stepsMetadata := make(map[string]map[string]in_toto.Metadata, len(l.layout.Steps))
+	var verified []oci.Signature
+	bundleVerified := true
+	for _, step := range l.layout.Steps {
+		links := map[string]in_toto.Metadata{}
+		var errs []error
+		for _, keyID := range step.PubKeys {
+			keyCo := *co
+			keyCo.SigVerifier = l.functionaries[keyID]
+			atts, bv, err := cosign.VerifyImageAttestations(ctx, digest, &keyCo)
+			if err != nil {
+				errs = append(errs, fmt.Errorf("key %s: %w", keyID, err))
+				continue
+			}
+			for _, att := range atts {
+				link, ok, err := stepLink(att, step.Name)
+				if err != nil {
+					return nil, false, err
+				}
+				if !ok {
+					continue
+				}
+				if _, seen := links[keyID]; !seen {
+					links[keyID] = &in_toto.Metablock{Signed: link}
+					verified = append(verified, att)
+					bundleVerified = bundleVerified && bv
+				}
+			}
+		}
+		if len(links) == 0 || len(links) < step.Threshold {
+			err := fmt.Errorf("step %s: %d of the %d required functionaries signed a link", step.Name, len(links), step.Threshold)
+			if len(errs) > 0 {
+				err = fmt.Errorf("%w: %w", err, errors.Join(errs...))
+			}
+			return nil, false, err
+		}
+		stepsMetadata[step.Name] = links
+		warnCommandMismatch(ctx, step, links)
+	}
+
+	reduced, err := in_toto.ReduceStepsMetadata(l.layout, stepsMetadata)
+	if err != nil {
+		return nil, false, err
+	}
+	steps := make([]interface{}, 0, len(l.layout.Steps))
+	for _, step := range l.layout.Steps {
+		steps = append(steps, step)
+	}
+	if err := in_toto.VerifyArtifacts(steps, reduced); err != nil {
+		return nil, false, fmt.Errorf("verifying the artifact rules of the steps: %w", err)
+	}
+
+	if len(l.layout.Inspect) > 0 {
+		inspected, err := in_toto.RunInspections(l.layout, l.inspectionDir, false, false)
+		if err != nil {
+			return nil, false, fmt.Errorf("running inspections: %w", err)
+		}
+		// Inspection rules may match the artifacts of the steps too.
+		for name, link := range reduced {
+			inspected[name] = link
+		}
+		inspections := make([]interface{}, 0, len(l.layout.Inspect))
+		for _, inspection := range l.layout.Inspect {
+			inspections = append(inspections, inspection)
+		}
+		if err := in_toto.VerifyArtifacts(inspections, inspected); err != nil {
+			return nil, false, fmt.Errorf("verifying the artifact rules of the inspections: %w", err)
+		}
+	}
+	return verified, bundleVerified, nil
+}
+
+// stepLink returns the in-toto link of att if it is a link attestation of
+// the step named step.
+func stepLink(att oci.Signature, step string) (in_toto.Link, bool, error) {
+	envelope, err := att.Payload()
+	if err != nil {
+		return in_toto.Link{}, false, err
+	}
+	statement, err := attestation.DecodeEnvelopePayload(envelope)
+	if err != nil {
+		return in_toto.Link{}, false, err
+	}
+	var header struct {
+		PredicateType string `json:"predicateType"`
+	}
+	if err := json.Unmarshal(statement, &header); err != nil {
+		return in_toto.Link{}, false, fmt.Errorf("unmarshaling statement: %w", err)
+	}
+	if header.PredicateType != in_toto.PredicateLinkV1 {
+		return in_toto.Link{}, false, nil
+	}
+	var ls in_toto.LinkStatement
+	if err := json.Unmarshal(statement, &ls); err != nil {
+		return in_toto.Link{}, false, fmt.Errorf("unmarshaling link statement: %w", err)
+	}
+	return ls.Predicate, ls.Predicate.Name == step, nil
+}
+
+// warnCommandMismatch warns about links that report another command than
+// the one their step expects, which in-toto doesn't fail verification for.
+func warnCommandMismatch(ctx context.Context, step in_toto.Step, links map[string]in_toto.Metadata) {
+	expected := strings.Join(step.ExpectedCommand, " ")
+	for keyID, link := range links {
+		if got := strings.Join(link.GetPayload().(in_toto.Link).Command, " "); got != expected {
+			ui.Warnf(ctx, "step %s expects command %q, the link signed by %s reports %q", step.Name, expected, keyID, got)
+		}
+	}
+}
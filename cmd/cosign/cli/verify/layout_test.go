@@ -0,0 +1,199 @@
+//
+// Copyright 2023 The Sigstore Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package verify
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/in-toto/in-toto-golang/in_toto"
+	"github.com/sigstore/sigstore/pkg/cryptoutils"
+
+	"github.com/sigstore/cosign/v2/pkg/oci/static"
+)
+
+// writeInTotoKey generates an ECDSA key pair and returns the in-toto key
+// loaded from its private key, and the path of its public key.
+func writeInTotoKey(t *testing.T, dir, name string) (in_toto.Key, string) {
+	t.Helper()
+	priv, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	privPEM, err := cryptoutils.MarshalPrivateKeyToPEM(priv)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pubPEM, err := cryptoutils.MarshalPublicKeyToPEM(priv.Public())
+	if err != nil {
+		t.Fatal(err)
+	}
+	privPath := filepath.Join(dir, name)
+	pubPath := privPath + ".pub"
+	if err := os.WriteFile(privPath, privPEM, 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(pubPath, pubPEM, 0600); err != nil {
+		t.Fatal(err)
+	}
+	var k in_toto.Key
+	if err := k.LoadKeyDefaults(privPath); err != nil {
+		t.Fatal(err)
+	}
+	return k, pubPath
+}
+
+func TestNewLayoutVerifier(t *testing.T) {
+	dir := t.TempDir()
+	owner, ownerPub := writeInTotoKey(t, dir, "owner")
+	_, otherPub := writeInTotoKey(t, dir, "other")
+	functionary, _ := writeInTotoKey(t, dir, "functionary")
+	functionary.KeyVal.Private = ""
+
+	step := in_toto.Step{
+		Type:      "step",
+		PubKeys:   []string{functionary.KeyID},
+		Threshold: 1,
+		SupplyChainItem: in_toto.SupplyChainItem{
+			Name: "build",
+		},
+	}
+	writeLayout := func(t *testing.T, expires time.Time, steps ...in_toto.Step) string {
+		t.Helper()
+		mb := in_toto.Metablock{Signed: in_toto.Layout{
+			Type:    "layout",
+			Steps:   steps,
+			Keys:    map[string]in_toto.Key{functionary.KeyID: functionary},
+			Expires: expires.UTC().Format(time.RFC3339),
+		}}
+		if err := mb.Sign(owner); err != nil {
+			t.Fatal(err)
+		}
+		path := filepath.Join(t.TempDir(), "root.layout")
+		if err := mb.Dump(path); err != nil {
+			t.Fatal(err)
+		}
+		return path
+	}
+	unknownKeyStep := step
+	unknownKeyStep.PubKeys = []string{"unknown"}
+
+	tests := []struct {
+		name    string
+		layout  string
+		keys    []string
+		wantErr string
+	}{{
+		name:   "valid",
+		layout: writeLayout(t, time.Now().Add(time.Hour), step),
+		keys:   []string{ownerPub},
+	}, {
+		name:    "signed by another key",
+		layout:  writeLayout(t, time.Now().Add(time.Hour), step),
+		keys:    []string{otherPub},
+		wantErr: "verifying the signatures of layout",
+	}, {
+		name:    "expired",
+		layout:  writeLayout(t, time.Now().Add(-time.Hour), step),
+		keys:    []string{ownerPub},
+		wantErr: "expired",
+	}, {
+		name:    "step key not in layout",
+		layout:  writeLayout(t, time.Now().Add(time.Hour), unknownKeyStep),
+		keys:    []string{ownerPub},
+		wantErr: "key unknown is not one of the layout keys",
+	}}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			l, err := newLayoutVerifier(tc.layout, tc.keys, "")
+			if tc.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tc.wantErr) {
+					t.Fatalf("got error %v, wanted %q", err, tc.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if l.functionaries[functionary.KeyID] == nil {
+				t.Errorf("no verifier for functionary key %s", functionary.KeyID)
+			}
+		})
+	}
+}
+
+func TestStepLink(t *testing.T) {
+	attestation := func(t *testing.T, statement interface{}) []byte {
+		t.Helper()
+		payload, err := json.Marshal(statement)
+		if err != nil {
+			t.Fatal(err)
+		}
+		envelope, err := json.Marshal(map[string]string{
+			"payloadType": "application/vnd.in-toto+json",
+			"payload":     base64.StdEncoding.EncodeToString(payload),
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return envelope
+	}
+	link := in_toto.LinkStatement{
+		StatementHeader: in_toto.StatementHeader{PredicateType: in_toto.PredicateLinkV1},
+		Predicate:       in_toto.Link{Type: "link", Name: "build", Command: []string{"make"}},
+	}
+	provenance := in_toto.Statement{
+		StatementHeader: in_toto.StatementHeader{PredicateType: "https://slsa.dev/provenance/v0.2"},
+		Predicate:       map[string]string{"name": "build"},
+	}
+
+	tests := []struct {
+		name      string
+		statement interface{}
+		step      string
+		wantOK    bool
+	}{
+		{name: "link of the step", statement: link, step: "build", wantOK: true},
+		{name: "link of another step", statement: link, step: "test"},
+		{name: "not a link", statement: provenance, step: "build"},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			att, err := static.NewAttestation(attestation(t, tc.statement))
+			if err != nil {
+				t.Fatal(err)
+			}
+			got, ok, err := stepLink(att, tc.step)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if ok != tc.wantOK {
+				t.Fatalf("got ok %t, wanted %t", ok, tc.wantOK)
+			}
+			if ok && got.Name != tc.step {
+				t.Errorf("got link of step %s, wanted %s", got.Name, tc.step)
+			}
+		})
+	}
+}
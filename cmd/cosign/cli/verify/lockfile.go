@@ -0,0 +1,146 @@
+//
+// Copyright 2023 The Sigstore Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package verify
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+)
+
+// LockfileSchemaVersion is the version of the Lockfile schema.
+const LockfileSchemaVersion = "v1"
+
+// Lockfile records the images verified with verify --record, so that
+// verify --against can detect when their tags no longer resolve to the
+// digests that were verified, e.g. in a deployed environment.
+type Lockfile struct {
+	SchemaVersion string        `json:"schemaVersion"`
+	Images        []LockedImage `json:"images"`
+}
+
+// LockedImage is the record of a verified image: the digest its reference
+// resolved to, the key it was verified with, if any, and its verified
+// signatures, without their payloads.
+type LockedImage struct {
+	Image      string            `json:"image"`
+	Digest     string            `json:"digest"`
+	Key        string            `json:"key,omitempty"`
+	Signatures []SignatureResult `json:"signatures,omitempty"`
+	VerifiedAt string            `json:"verifiedAt"`
+}
+
+// loadLockfile reads the lockfile at path. A missing lockfile is empty when
+// allowMissing.
+func loadLockfile(path string, allowMissing bool) (*Lockfile, error) {
+	b, err := os.ReadFile(path)
+	if allowMissing && errors.Is(err, os.ErrNotExist) {
+		return &Lockfile{SchemaVersion: LockfileSchemaVersion}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("reading lockfile: %w", err)
+	}
+	var l Lockfile
+	if err := json.Unmarshal(b, &l); err != nil {
+		return nil, fmt.Errorf("parsing lockfile %s: %w", path, err)
+	}
+	if l.SchemaVersion != LockfileSchemaVersion {
+		return nil, fmt.Errorf("lockfile %s has unsupported schema version %q", path, l.SchemaVersion)
+	}
+	return &l, nil
+}
+
+// write writes l to path.
+func (l *Lockfile) write(path string) error {
+	b, err := json.MarshalIndent(l, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(path, append(b, '\n'), 0600); err != nil {
+		return fmt.Errorf("writing lockfile: %w", err)
+	}
+	return nil
+}
+
+// images returns the references of the images of l.
+func (l *Lockfile) images() []string {
+	images := make([]string, len(l.Images))
+	for i, img := range l.Images {
+		images[i] = img.Image
+	}
+	return images
+}
+
+func (l *Lockfile) lookup(image string) *LockedImage {
+	for i := range l.Images {
+		if l.Images[i].Image == image {
+			return &l.Images[i]
+		}
+	}
+	return nil
+}
+
+// record replaces the record of the image of img, or adds it.
+func (l *Lockfile) record(img LockedImage) {
+	if locked := l.lookup(img.Image); locked != nil {
+		*locked = img
+		return
+	}
+	l.Images = append(l.Images, img)
+}
+
+// checkDrift fails when image isn't recorded in l, or when digest, the
+// reference by digest it resolved to, isn't the recorded one.
+func (l *Lockfile) checkDrift(image, digest string) error {
+	locked := l.lookup(image)
+	if locked == nil {
+		return fmt.Errorf("%s is not recorded in the lockfile", image)
+	}
+	if d := digestOf(digest); d != locked.Digest {
+		return fmt.Errorf("%s drifted: it resolves to %s, but %s was recorded", image, d, locked.Digest)
+	}
+	return nil
+}
+
+// locked returns the record of image, whose signatures were verified by v
+// with keyRef, if any.
+func (v *signatureVerification) locked(image, keyRef string) (LockedImage, error) {
+	img := LockedImage{
+		Image:      image,
+		Digest:     digestOf(v.digest),
+		Key:        keyRef,
+		VerifiedAt: time.Now().UTC().Format(time.RFC3339),
+	}
+	for _, sig := range v.verified {
+		sr, err := newSignatureResult(sig, false)
+		if err != nil {
+			return LockedImage{}, err
+		}
+		img.Signatures = append(img.Signatures, sr)
+	}
+	return img, nil
+}
+
+// digestOf returns the sha256:... digest of a reference by digest.
+func digestOf(ref string) string {
+	if _, d, ok := strings.Cut(ref, "@"); ok {
+		return d
+	}
+	return ref
+}
@@ -0,0 +1,75 @@
+//
+// Copyright 2023 The Sigstore Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package verify
+
+import (
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const (
+	lockedDigest  = "sha256:d131624e6f5d8695e9aea7a0439f7bac0fcc50051282e0c3d4d627cab8845ba5"
+	driftedDigest = "sha256:4a6d1b1f3f2e3e5c7f1c0b0f1b5b8a9c2f7e6d5c4b3a29180706050403020100"
+	lockedImage   = "ghcr.io/example/app:v1"
+	unlockedImage = "ghcr.io/example/other:v1"
+)
+
+func TestLockfile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "lock.json")
+
+	if _, err := loadLockfile(path, false); err == nil {
+		t.Fatal("loadLockfile() of a missing lockfile succeeded")
+	}
+	lock, err := loadLockfile(path, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	v := &signatureVerification{digest: "ghcr.io/example/app@" + driftedDigest}
+	img, err := v.locked(lockedImage, "cosign.pub")
+	if err != nil {
+		t.Fatal(err)
+	}
+	lock.record(img)
+	img.Digest = lockedDigest
+	lock.record(img)
+	if err := lock.write(path); err != nil {
+		t.Fatal(err)
+	}
+
+	lock, err = loadLockfile(path, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := lock.images(), []string{lockedImage}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("images() = %v, want %v", got, want)
+	}
+	if got := lock.Images[0]; got.Digest != lockedDigest || got.Key != "cosign.pub" {
+		t.Errorf("recorded %+v, want the digest %s and the key cosign.pub", got, lockedDigest)
+	}
+
+	if err := lock.checkDrift(lockedImage, "ghcr.io/example/app@"+lockedDigest); err != nil {
+		t.Errorf("checkDrift() = %v", err)
+	}
+	if err := lock.checkDrift(lockedImage, "ghcr.io/example/app@"+driftedDigest); err == nil || !strings.Contains(err.Error(), "drifted") {
+		t.Errorf("checkDrift() of a moved tag = %v, want it drifted", err)
+	}
+	if err := lock.checkDrift(unlockedImage, "ghcr.io/example/other@"+lockedDigest); err == nil {
+		t.Error("checkDrift() of an image not recorded succeeded")
+	}
+}
@@ -0,0 +1,152 @@
+//
+// Copyright 2023 The Sigstore Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package verify
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/google/go-containerregistry/pkg/name"
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+
+	"github.com/sigstore/cosign/v2/cmd/cosign/cli/options"
+	"github.com/sigstore/cosign/v2/pkg/cosign"
+	ociremote "github.com/sigstore/cosign/v2/pkg/oci/remote"
+)
+
+const (
+	// policyBundlePrefix marks a --policy value as a reference to an OCI
+	// artifact holding policy files.
+	policyBundlePrefix = "oci://"
+	// policyTitleAnnotation names the file of a layer, as set by ORAS.
+	policyTitleAnnotation = "org.opencontainers.image.title"
+)
+
+func isPolicyBundle(path string) bool {
+	return strings.HasPrefix(path, policyBundlePrefix)
+}
+
+func hasPolicyBundles(bindings []policyBinding) bool {
+	for _, b := range bindings {
+		if isPolicyBundle(b.path) {
+			return true
+		}
+	}
+	return false
+}
+
+// fetchPolicyBundles replaces the bindings of policy bundles with a binding
+// for each of the policy files of the bundle, written to dir. The signatures
+// of a bundle are verified with co before its files are read, and the files
+// are read from the digest that was verified.
+func fetchPolicyBundles(ctx context.Context, bindings []policyBinding, dir string, nameOpts []name.Option, co *cosign.CheckOpts) ([]policyBinding, error) {
+	var resolved []policyBinding
+	for i, b := range bindings {
+		if !isPolicyBundle(b.path) {
+			resolved = append(resolved, b)
+			continue
+		}
+		ref, err := name.ParseReference(strings.TrimPrefix(b.path, policyBundlePrefix), nameOpts...)
+		if err != nil {
+			return nil, fmt.Errorf("parsing policy bundle reference: %w", err)
+		}
+		digest, err := ociremote.ResolveDigest(ref, co.RegistryClientOpts...)
+		if err != nil {
+			return nil, fmt.Errorf("resolving policy bundle %s: %w", ref, err)
+		}
+		if _, _, err := cosign.VerifyImageSignatures(ctx, digest, co); err != nil {
+			return nil, fmt.Errorf("verifying policy bundle %s: %w", ref, err)
+		}
+		img, err := ociremote.SignedImage(digest, co.RegistryClientOpts...)
+		if err != nil {
+			return nil, fmt.Errorf("fetching policy bundle %s: %w", ref, err)
+		}
+		bundleDir := filepath.Join(dir, fmt.Sprint(i))
+		if err := os.Mkdir(bundleDir, 0o700); err != nil {
+			return nil, err
+		}
+		paths, err := writePolicyBundle(img, bundleDir)
+		if err != nil {
+			return nil, fmt.Errorf("reading policy bundle %s: %w", ref, err)
+		}
+		for _, path := range paths {
+			engine, err := policyEngine(path, options.PolicyEngineAuto)
+			if err != nil {
+				return nil, err
+			}
+			resolved = append(resolved, policyBinding{predicateType: b.predicateType, path: path, engine: engine})
+		}
+	}
+	return resolved, nil
+}
+
+// writePolicyBundle writes the CUE and Rego files of img to dir, and returns
+// their paths. Layers are named after their title annotation, other layers
+// are ignored.
+func writePolicyBundle(img v1.Image, dir string) ([]string, error) {
+	m, err := img.Manifest()
+	if err != nil {
+		return nil, err
+	}
+	var paths []string
+	for _, desc := range m.Layers {
+		title := desc.Annotations[policyTitleAnnotation]
+		switch filepath.Ext(title) {
+		case ".cue", ".rego":
+		default:
+			continue
+		}
+		// The title comes from the registry, don't let it escape dir.
+		if title != filepath.Base(title) || strings.ContainsAny(title, `/\`) {
+			return nil, fmt.Errorf("invalid policy file name %q", title)
+		}
+		path := filepath.Join(dir, title)
+		layer, err := img.LayerByDigest(desc.Digest)
+		if err != nil {
+			return nil, err
+		}
+		if err := writeLayer(layer, path); err != nil {
+			return nil, err
+		}
+		paths = append(paths, path)
+	}
+	if len(paths) == 0 {
+		return nil, errors.New("no .cue or .rego policy files found")
+	}
+	return paths, nil
+}
+
+func writeLayer(layer v1.Layer, path string) error {
+	rc, err := layer.Compressed()
+	if err != nil {
+		return err
+	}
+	defer rc.Close()
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o600)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(f, rc); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
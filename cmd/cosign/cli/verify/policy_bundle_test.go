@@ -0,0 +1,93 @@
+//
+// Copyright 2023 The Sigstore Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package verify
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/empty"
+	"github.com/google/go-containerregistry/pkg/v1/mutate"
+	"github.com/google/go-containerregistry/pkg/v1/static"
+	"github.com/google/go-containerregistry/pkg/v1/types"
+)
+
+func policyBundleImage(t *testing.T, files map[string]string) v1.Image {
+	t.Helper()
+	img := empty.Image
+	for title, body := range files {
+		var err error
+		img, err = mutate.Append(img, mutate.Addendum{
+			Layer:       static.NewLayer([]byte(body), types.MediaType("application/vnd.cncf.openpolicyagent.policy.layer.v1+rego")),
+			Annotations: map[string]string{policyTitleAnnotation: title},
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	return img
+}
+
+func TestWritePolicyBundle(t *testing.T) {
+	tests := []struct {
+		name      string
+		files     map[string]string
+		wantFiles []string
+		wantErr   bool
+	}{{
+		name: "policies",
+		files: map[string]string{
+			"builder.cue": `predicate: builder: id: "https://example.com/builder"`,
+			"vuln.rego":   "package signature\n\ndefault allow = true\n",
+			"README.md":   "not a policy",
+		},
+		wantFiles: []string{"builder.cue", "vuln.rego"},
+	}, {
+		name:    "no policies",
+		files:   map[string]string{"README.md": "not a policy"},
+		wantErr: true,
+	}, {
+		name:    "path traversal",
+		files:   map[string]string{"../escape.cue": `predicate: {}`},
+		wantErr: true,
+	}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			paths, err := writePolicyBundle(policyBundleImage(t, tt.files), dir)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("writePolicyBundle() = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if len(paths) != len(tt.wantFiles) {
+				t.Fatalf("writePolicyBundle() = %v, want %v", paths, tt.wantFiles)
+			}
+			for _, f := range tt.wantFiles {
+				b, err := os.ReadFile(filepath.Join(dir, f))
+				if err != nil {
+					t.Fatal(err)
+				}
+				if string(b) != tt.files[f] {
+					t.Errorf("%s = %q, want %q", f, b, tt.files[f])
+				}
+			}
+		})
+	}
+}
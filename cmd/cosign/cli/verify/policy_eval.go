@@ -0,0 +1,280 @@
+//
+// Copyright 2023 The Sigstore Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package verify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/google/go-containerregistry/pkg/name"
+
+	"github.com/sigstore/cosign/v2/cmd/cosign/cli/options"
+	"github.com/sigstore/cosign/v2/pkg/cosign/attestation"
+	"github.com/sigstore/cosign/v2/pkg/cosign/rego"
+	"github.com/sigstore/cosign/v2/pkg/oci"
+	ociremote "github.com/sigstore/cosign/v2/pkg/oci/remote"
+	"github.com/sigstore/cosign/v2/pkg/oci/static"
+	"github.com/sigstore/cosign/v2/pkg/policy"
+	"github.com/sigstore/cosign/v2/pkg/types"
+)
+
+// PolicyEvalCommand evaluates policies against attestations read from a file
+// or fetched from an image, without verifying their signatures, to author and
+// debug the policies given to verify-attestation.
+type PolicyEvalCommand struct {
+	options.RegistryOptions
+	AttestationPath string
+	PredicateType   string
+	Policies        []string
+	PolicyEngine    string
+	RegoQuery       string
+	PolicyData      []string
+	Trace           bool
+	Output          string
+}
+
+// Exec evaluates the policies against the attestations of imageRef, or of
+// the attestation file when imageRef is empty, and writes the results to w.
+func (c *PolicyEvalCommand) Exec(ctx context.Context, w io.Writer, imageRef string) error {
+	if (imageRef == "") == (c.AttestationPath == "") {
+		return errors.New("provide either an image or an attestation file with --attestation")
+	}
+	if len(c.Policies) == 0 {
+		return errors.New("at least one --policy is required")
+	}
+	if err := checkOutput(c.Output); err != nil {
+		return err
+	}
+	predicateType := c.PredicateType
+	if predicateType == "" {
+		predicateType = options.PredicateAll
+	}
+	bindings, err := parsePolicyBindings(c.Policies, c.PolicyEngine)
+	if err != nil {
+		return err
+	}
+	if hasPolicyBundles(bindings) {
+		return errors.New("oci:// policy bundles can only be evaluated by verify-attestation, which verifies them")
+	}
+	if err := checkPolicySchemas(bindings, []string{predicateType}); err != nil {
+		return err
+	}
+
+	var atts []oci.Signature
+	var digest string
+	if imageRef != "" {
+		atts, digest, err = c.fetchAttestations(ctx, imageRef)
+	} else {
+		atts, err = readAttestations(c.AttestationPath)
+	}
+	if err != nil {
+		return err
+	}
+
+	// Traces are only readable next to the text output.
+	traceW := w
+	if isStructuredOutput(c.Output) {
+		traceW = os.Stderr
+	}
+	var results []AttestationResult
+	failed := 0
+	for _, att := range atts {
+		envelope, err := att.Payload()
+		if err != nil {
+			return fmt.Errorf("getting payload: %w", err)
+		}
+		statement, err := attestation.DecodeEnvelopePayload(envelope)
+		if err != nil {
+			return err
+		}
+		payload, gotPredicateType, err := policy.StatementToPayloadJSON(predicateType, statement)
+		if err != nil {
+			return err
+		}
+		if len(payload) == 0 {
+			continue
+		}
+		result, err := newAttestationResult(gotPredicateType, att, statement)
+		if err != nil {
+			return err
+		}
+		if payload, err = policyInput(payload, att, result, imageRef, digest); err != nil {
+			return err
+		}
+		if errs := validatePolicies(ctx, payload, gotPredicateType, bindings, c.RegoQuery, c.PolicyData, &result); len(errs) > 0 {
+			failed++
+		}
+		results = append(results, result)
+		if c.Trace {
+			if err := c.trace(traceW, payload, gotPredicateType, bindings); err != nil {
+				return err
+			}
+		}
+	}
+	if len(results) == 0 {
+		return fmt.Errorf("none of the %d attestations matched the predicate type %s", len(atts), predicateType)
+	}
+
+	if err := writePolicyEvalResults(w, c.Output, results); err != nil {
+		return err
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d of %d attestations failed the policies", failed, len(results))
+	}
+	return nil
+}
+
+// fetchAttestations returns the attestations of imageRef, and the digest
+// they were fetched from, without verifying them.
+func (c *PolicyEvalCommand) fetchAttestations(ctx context.Context, imageRef string) ([]oci.Signature, string, error) {
+	ref, err := name.ParseReference(imageRef, c.RegistryOptions.NameOptions()...)
+	if err != nil {
+		return nil, "", err
+	}
+	opts, err := c.RegistryOptions.ClientOpts(ctx)
+	if err != nil {
+		return nil, "", fmt.Errorf("constructing client options: %w", err)
+	}
+	d, err := ociremote.ResolveDigest(ref, opts...)
+	if err != nil {
+		return nil, "", fmt.Errorf("resolving digest of %s: %w", imageRef, err)
+	}
+	se, err := ociremote.SignedEntity(d, opts...)
+	if err != nil {
+		return nil, "", err
+	}
+	atts, err := se.Attestations()
+	if err != nil {
+		return nil, "", err
+	}
+	l, err := atts.Get()
+	if err != nil {
+		return nil, "", fmt.Errorf("fetching attestations: %w", err)
+	}
+	if len(l) == 0 {
+		return nil, "", fmt.Errorf("found no attestations for %s", imageRef)
+	}
+	return l, d.DigestStr(), nil
+}
+
+// readAttestations reads the attestations in the file at path, or stdin for
+// "-". The file holds DSSE envelopes, as written by 'cosign download
+// attestation', or in-toto statements, as JSON values one after another.
+func readAttestations(path string) ([]oci.Signature, error) {
+	var r io.Reader = os.Stdin
+	if path != "-" {
+		f, err := os.Open(path)
+		if err != nil {
+			return nil, err
+		}
+		defer f.Close()
+		r = f
+	}
+	var atts []oci.Signature
+	dec := json.NewDecoder(r)
+	for {
+		var raw json.RawMessage
+		if err := dec.Decode(&raw); err == io.EOF {
+			break
+		} else if err != nil {
+			return nil, fmt.Errorf("reading attestation %d: %w", len(atts)+1, err)
+		}
+		envelope, err := attestationEnvelope(raw)
+		if err != nil {
+			return nil, fmt.Errorf("reading attestation %d: %w", len(atts)+1, err)
+		}
+		att, err := static.NewAttestation(envelope)
+		if err != nil {
+			return nil, err
+		}
+		atts = append(atts, att)
+	}
+	if len(atts) == 0 {
+		return nil, fmt.Errorf("found no attestations in %s", path)
+	}
+	return atts, nil
+}
+
+// attestationEnvelope returns raw, or an unsigned envelope for it when raw
+// is an in-toto statement rather than a DSSE envelope.
+func attestationEnvelope(raw []byte) ([]byte, error) {
+	var v struct {
+		Payload       string `json:"payload"`
+		PredicateType string `json:"predicateType"`
+	}
+	if err := json.Unmarshal(raw, &v); err != nil {
+		return nil, err
+	}
+	switch {
+	case v.Payload != "":
+		return raw, nil
+	case v.PredicateType != "":
+		return json.Marshal(struct {
+			PayloadType string   `json:"payloadType"`
+			Payload     []byte   `json:"payload"`
+			Signatures  []string `json:"signatures"`
+		}{types.IntotoPayloadType, raw, []string{}})
+	default:
+		return nil, errors.New("neither a DSSE envelope nor an in-toto statement")
+	}
+}
+
+// trace writes the evaluation trace of the Rego policies bound to
+// predicateType, CUE has no equivalent.
+func (c *PolicyEvalCommand) trace(w io.Writer, payload []byte, predicateType string, bindings []policyBinding) error {
+	for _, b := range bindings {
+		if b.engine != options.PolicyEngineRego || !b.matches(predicateType) {
+			continue
+		}
+		fmt.Fprintf(w, "Trace of %s for %s:\n", b.path, predicateType)
+		if err := rego.Trace(w, payload, append([]string{b.path}, c.PolicyData...), c.RegoQuery); err != nil {
+			return fmt.Errorf("tracing %s: %w", b.path, err)
+		}
+	}
+	return nil
+}
+
+// writePolicyEvalResults writes the results as JSON or YAML, or as a list of
+// the policies that passed or failed for each attestation.
+func writePolicyEvalResults(w io.Writer, output string, results []AttestationResult) error {
+	if isStructuredOutput(output) {
+		if err := writeStructured(w, output, results); err != nil {
+			return fmt.Errorf("marshaling results: %w", err)
+		}
+		return nil
+	}
+	var buf bytes.Buffer
+	for i, r := range results {
+		fmt.Fprintf(&buf, "Attestation %d: %s\n", i+1, r.PredicateType)
+		for _, p := range r.Policies {
+			status := "PASS"
+			if !p.Passed {
+				status = "FAIL"
+			}
+			fmt.Fprintf(&buf, "  %s %s policy %s\n", status, p.Engine, p.Policy)
+			for _, v := range p.Violations {
+				fmt.Fprintf(&buf, "    - %s\n", v)
+			}
+		}
+	}
+	_, err := w.Write(buf.Bytes())
+	return err
+}
@@ -0,0 +1,133 @@
+//
+// Copyright 2023 The Sigstore Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package verify
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestPolicyEvalCommand(t *testing.T) {
+	tests := []struct {
+		name     string
+		policies []string
+		typ      string
+		want     []string
+		wantErr  bool
+	}{{
+		name:     "passing policies",
+		policies: []string{"testdata/slsa-builder.cue", "testdata/slsa-builder.rego"},
+		want: []string{
+			"Attestation 1: https://slsa.dev/provenance/v0.2",
+			"PASS cue policy testdata/slsa-builder.cue",
+			"PASS rego policy testdata/slsa-builder.rego",
+		},
+	}, {
+		name:     "failing policy",
+		policies: []string{"testdata/slsa-buildtype.cue"},
+		want: []string{
+			"FAIL cue policy testdata/slsa-buildtype.cue",
+			"    - predicate.buildType: ",
+		},
+		wantErr: true,
+	}, {
+		name:     "no matching attestation",
+		policies: []string{"testdata/slsa-builder.cue"},
+		typ:      "spdx",
+		wantErr:  true,
+	}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := &PolicyEvalCommand{
+				AttestationPath: "testdata/slsa-statement.json",
+				PredicateType:   tt.typ,
+				Policies:        tt.policies,
+				Output:          "text",
+			}
+			var out bytes.Buffer
+			err := c.Exec(context.Background(), &out, "")
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Exec() = %v, wantErr %v", err, tt.wantErr)
+			}
+			for _, want := range tt.want {
+				if !strings.Contains(out.String(), want) {
+					t.Errorf("output does not contain %q:\n%s", want, out.String())
+				}
+			}
+		})
+	}
+}
+
+func TestReadAttestations(t *testing.T) {
+	statement, err := os.ReadFile("testdata/slsa-statement.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var compact bytes.Buffer
+	if err := json.Compact(&compact, statement); err != nil {
+		t.Fatal(err)
+	}
+	envelope := `{"payloadType":"application/vnd.in-toto+json","payload":"` + base64.StdEncoding.EncodeToString(compact.Bytes()) + `","signatures":[{"sig":"c2ln"}]}`
+
+	tests := []struct {
+		name    string
+		content string
+		want    int
+		wantErr bool
+	}{
+		{name: "statement", content: string(statement), want: 1},
+		{name: "envelopes as json lines", content: envelope + "\n" + envelope + "\n", want: 2},
+		{name: "statement and envelope", content: compact.String() + "\n" + envelope, want: 2},
+		{name: "empty", content: "", wantErr: true},
+		{name: "not an attestation", content: `{"foo":"bar"}`, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "attestations.jsonl")
+			if err := os.WriteFile(path, []byte(tt.content), 0o600); err != nil {
+				t.Fatal(err)
+			}
+			atts, err := readAttestations(path)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("readAttestations() = %v, wantErr %v", err, tt.wantErr)
+			}
+			if len(atts) != tt.want {
+				t.Fatalf("readAttestations() returned %d attestations, want %d", len(atts), tt.want)
+			}
+			for _, att := range atts {
+				p, err := att.Payload()
+				if err != nil {
+					t.Fatal(err)
+				}
+				var env struct {
+					Payload []byte `json:"payload"`
+				}
+				if err := json.Unmarshal(p, &env); err != nil {
+					t.Fatal(err)
+				}
+				if !bytes.Equal(env.Payload, compact.Bytes()) && !bytes.Equal(env.Payload, bytes.TrimSpace(statement)) {
+					t.Errorf("unexpected statement %s", env.Payload)
+				}
+			}
+		})
+	}
+}
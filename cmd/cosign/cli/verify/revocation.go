@@ -0,0 +1,221 @@
+//
+// Copyright 2023 The Sigstore Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package verify
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/sigstore/cosign/v2/cmd/cosign/cli/options"
+	"github.com/sigstore/cosign/v2/internal/ui"
+	"github.com/sigstore/cosign/v2/pkg/cosign"
+	"github.com/sigstore/cosign/v2/pkg/cosign/revocation"
+	"github.com/sigstore/cosign/v2/pkg/oci"
+	sigs "github.com/sigstore/cosign/v2/pkg/signature"
+	"github.com/sigstore/sigstore/pkg/signature"
+)
+
+// revocations rejects the signatures revoked by a revocation list: those of
+// a revoked image digest, and those made by a revoked key. A nil
+// *revocations revokes nothing.
+type revocations struct {
+	list *revocation.List
+}
+
+// loadRevocations fetches the revocation list at source and verifies it was
+// signed by keyRef. A list fetched less than ttl ago is reused from the cache
+// in cacheDir, and a cached list that hasn't expired is used, with a
+// warning, when fetching it fails.
+func loadRevocations(ctx context.Context, source, keyRef string, ttl time.Duration, cacheDir string, regOpts options.RegistryOptions) (*revocations, error) {
+	if keyRef == "" {
+		return nil, errors.New("--revocation-list requires --revocation-list-key")
+	}
+	verifier, err := sigs.PublicKeyFromKeyRef(ctx, keyRef)
+	if err != nil {
+		return nil, fmt.Errorf("loading revocation list key: %w", err)
+	}
+	now := time.Now()
+	cached := ""
+	if cacheDir != "" {
+		sum := sha256.Sum256([]byte(source))
+		cached = filepath.Join(cacheDir, hex.EncodeToString(sum[:])+".json")
+	}
+
+	// cachedList returns the cached list if it is verified and, when fresh
+	// is set, was fetched less than ttl ago.
+	cachedList := func(fresh bool) *revocation.SignedList {
+		if cached == "" {
+			return nil
+		}
+		fi, err := os.Stat(cached)
+		if err != nil || (fresh && now.Sub(fi.ModTime()) >= ttl) {
+			return nil
+		}
+		b, err := os.ReadFile(cached)
+		if err != nil {
+			return nil
+		}
+		sl, err := revocation.Parse(b)
+		if err != nil || sl.Verify(verifier, now) != nil {
+			return nil
+		}
+		return sl
+	}
+
+	if ttl > 0 {
+		if sl := cachedList(true); sl != nil {
+			return &revocations{list: &sl.Signed}, nil
+		}
+	}
+	sl, b, err := revocation.Fetch(ctx, source, regOpts.GetRegistryClientOpts(ctx)...)
+	if err == nil {
+		if err = sl.Verify(verifier, now); err != nil {
+			err = fmt.Errorf("verifying revocation list %s: %w", source, err)
+		}
+	}
+	if err != nil {
+		if sl := cachedList(false); sl != nil {
+			ui.Warnf(ctx, "Using the cached revocation list of %s: %v", source, err)
+			return &revocations{list: &sl.Signed}, nil
+		}
+		return nil, err
+	}
+	if cached != "" {
+		if err := os.MkdirAll(cacheDir, 0o700); err == nil {
+			err = os.WriteFile(cached, b, 0o600)
+		}
+		if err != nil {
+			ui.Warnf(ctx, "Caching the revocation list of %s: %v", source, err)
+		}
+	}
+	return &revocations{list: &sl.Signed}, nil
+}
+
+// revocationCacheDir returns the directory revocation lists are cached in,
+// or "" when the cache is disabled.
+func revocationCacheDir(ttl time.Duration) string {
+	if ttl <= 0 {
+		return ""
+	}
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(dir, "cosign", "revocation")
+}
+
+// revoked reports whether the key of v is revoked.
+func (r *revocations) revoked(v signature.Verifier) (bool, error) {
+	pub, err := v.PublicKey()
+	if err != nil {
+		return false, err
+	}
+	return r.list.KeyRevoked(pub)
+}
+
+// verifier returns v without its revoked keys. It fails when every key of v
+// is revoked.
+func (r *revocations) verifier(v signature.Verifier) (signature.Verifier, error) {
+	if r == nil || v == nil {
+		return v, nil
+	}
+	keys, ok := v.(cosign.TrustedKeys)
+	if !ok {
+		keys = cosign.TrustedKeys{v}
+	}
+	var trusted cosign.TrustedKeys
+	for _, k := range keys {
+		revoked, err := r.revoked(k)
+		if err != nil {
+			return nil, err
+		}
+		if !revoked {
+			trusted = append(trusted, k)
+		}
+	}
+	switch {
+	case len(trusted) == 0 && !ok:
+		return nil, errors.New("the key is revoked by the revocation list")
+	case len(trusted) == 0:
+		return nil, errors.New("all the trusted keys are revoked by the revocation list")
+	case !ok:
+		return v, nil
+	}
+	return trusted, nil
+}
+
+// threshold removes the signers of t whose key is revoked, so that they
+// don't count towards its threshold.
+func (r *revocations) threshold(t *thresholdVerifier) error {
+	if r == nil || t == nil {
+		return nil
+	}
+	var signers []thresholdSigner
+	for _, s := range t.signers {
+		if s.verifier != nil {
+			revoked, err := r.revoked(s.verifier)
+			if err != nil {
+				return fmt.Errorf("%s: %w", s.name, err)
+			}
+			if revoked {
+				continue
+			}
+		}
+		signers = append(signers, s)
+	}
+	t.signers = signers
+	return nil
+}
+
+// filter fails if the image digest of ref, e.g. ghcr.io/myorg/app@sha256:...,
+// is revoked, and otherwise returns the verified signatures whose
+// certificate key isn't revoked. It fails when they are all revoked.
+func (r *revocations) filter(ref string, verified []oci.Signature) ([]oci.Signature, error) {
+	if r == nil {
+		return verified, nil
+	}
+	if i := strings.LastIndex(ref, "@"); i >= 0 && r.list.DigestRevoked(ref[i+1:]) {
+		return nil, fmt.Errorf("%s is revoked by the revocation list", ref[i+1:])
+	}
+	var kept []oci.Signature
+	for _, sig := range verified {
+		cert, err := sig.Cert()
+		if err != nil {
+			return nil, err
+		}
+		if cert != nil {
+			revoked, err := r.list.KeyRevoked(cert.PublicKey)
+			if err != nil {
+				return nil, err
+			}
+			if revoked {
+				continue
+			}
+		}
+		kept = append(kept, sig)
+	}
+	if len(kept) == 0 && len(verified) > 0 {
+		return nil, errors.New("all the verified signatures were made by keys revoked by the revocation list")
+	}
+	return kept, nil
+}
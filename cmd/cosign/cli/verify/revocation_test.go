@@ -0,0 +1,216 @@
+//
+// Copyright 2023 The Sigstore Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package verify
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/sigstore/sigstore/pkg/cryptoutils"
+	"github.com/sigstore/sigstore/pkg/signature"
+
+	"github.com/sigstore/cosign/v2/cmd/cosign/cli/options"
+	"github.com/sigstore/cosign/v2/pkg/cosign"
+	"github.com/sigstore/cosign/v2/pkg/cosign/revocation"
+	"github.com/sigstore/cosign/v2/pkg/cosign/rootpolicy"
+	"github.com/sigstore/cosign/v2/pkg/oci"
+	"github.com/sigstore/cosign/v2/pkg/oci/static"
+)
+
+const revokedDigest = "sha256:6f8d3c36be2e2a7b3ee43cf9b1bb8ec47a0f6c3e5b8b8ee83c9fae15e0a8d1b7"
+
+func newRevocationSigner(t *testing.T) signature.SignerVerifier {
+	t.Helper()
+	priv, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sv, err := signature.LoadECDSASignerVerifier(priv, crypto.SHA256)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return sv
+}
+
+// writeRevocationList writes the list of revokedDigest and the key of
+// revoked, signed by publisher, to dir and returns its path and the path
+// of the public key of publisher.
+func writeRevocationList(t *testing.T, dir string, publisher, revoked signature.SignerVerifier) (string, string) {
+	t.Helper()
+	pub, err := revoked.PublicKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	id, err := rootpolicy.KeyID(pub)
+	if err != nil {
+		t.Fatal(err)
+	}
+	now := time.Now()
+	sl, err := revocation.New([]string{revokedDigest}, []string{id}, now, now.Add(time.Hour))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := sl.Sign(publisher); err != nil {
+		t.Fatal(err)
+	}
+	b, err := json.Marshal(sl)
+	if err != nil {
+		t.Fatal(err)
+	}
+	listPath := filepath.Join(dir, "revocations.json")
+	if err := os.WriteFile(listPath, b, 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	publisherPub, err := publisher.PublicKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	pem, err := cryptoutils.MarshalPublicKeyToPEM(publisherPub)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyPath := filepath.Join(dir, "revocation.pub")
+	if err := os.WriteFile(keyPath, pem, 0600); err != nil {
+		t.Fatal(err)
+	}
+	return listPath, keyPath
+}
+
+func TestLoadRevocations(t *testing.T) {
+	ctx := context.Background()
+	dir, cacheDir := t.TempDir(), t.TempDir()
+	publisher, revoked := newRevocationSigner(t), newRevocationSigner(t)
+	listPath, keyPath := writeRevocationList(t, dir, publisher, revoked)
+
+	r, err := loadRevocations(ctx, listPath, keyPath, time.Hour, cacheDir, options.RegistryOptions{})
+	if err != nil {
+		t.Fatalf("loadRevocations() = %v", err)
+	}
+	if !r.list.DigestRevoked(revokedDigest) {
+		t.Errorf("loadRevocations() did not return the revoked digest")
+	}
+
+	if _, err := loadRevocations(ctx, listPath, "", time.Hour, cacheDir, options.RegistryOptions{}); err == nil {
+		t.Error("loadRevocations() without a key succeeded")
+	}
+	_, otherKeyPath := writeRevocationList(t, t.TempDir(), newRevocationSigner(t), revoked)
+	if _, err := loadRevocations(ctx, listPath, otherKeyPath, time.Hour, "", options.RegistryOptions{}); err == nil {
+		t.Error("loadRevocations() of a list signed by another key succeeded")
+	}
+
+	// The cached list is used once the source is gone, whether it is still
+	// fresh or only unexpired.
+	if err := os.Remove(listPath); err != nil {
+		t.Fatal(err)
+	}
+	for _, ttl := range []time.Duration{time.Hour, 0} {
+		if r, err := loadRevocations(ctx, listPath, keyPath, ttl, cacheDir, options.RegistryOptions{}); err != nil || !r.list.DigestRevoked(revokedDigest) {
+			t.Errorf("loadRevocations() with ttl %s from the cache = %v", ttl, err)
+		}
+	}
+	if _, err := loadRevocations(ctx, listPath, keyPath, time.Hour, t.TempDir(), options.RegistryOptions{}); err == nil {
+		t.Error("loadRevocations() of a missing list without a cache succeeded")
+	}
+}
+
+func TestRevocations(t *testing.T) {
+	dir := t.TempDir()
+	publisher, revoked, trusted := newRevocationSigner(t), newRevocationSigner(t), newRevocationSigner(t)
+	listPath, keyPath := writeRevocationList(t, dir, publisher, revoked)
+	r, err := loadRevocations(context.Background(), listPath, keyPath, 0, "", options.RegistryOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Run("verifier", func(t *testing.T) {
+		if _, err := r.verifier(revoked); err == nil || !strings.Contains(err.Error(), "revoked") {
+			t.Errorf("verifier() of a revoked key = %v, want revoked error", err)
+		}
+		if v, err := r.verifier(trusted); err != nil || v != trusted {
+			t.Errorf("verifier() of a trusted key = %v, %v", v, err)
+		}
+		v, err := r.verifier(cosign.TrustedKeys{revoked, trusted})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if keys := v.(cosign.TrustedKeys); len(keys) != 1 || keys[0] != trusted {
+			t.Errorf("verifier() = %v, want the trusted key only", keys)
+		}
+		if _, err := r.verifier(cosign.TrustedKeys{revoked}); err == nil {
+			t.Error("verifier() with every key revoked succeeded")
+		}
+	})
+
+	t.Run("threshold", func(t *testing.T) {
+		tv := &thresholdVerifier{threshold: 1, signers: []thresholdSigner{
+			{name: "key revoked.pub", verifier: revoked},
+			{name: "key trusted.pub", verifier: trusted},
+			{name: "identity carol@example.com", identity: &cosign.Identity{Subject: "carol@example.com"}},
+		}}
+		if err := r.threshold(tv); err != nil {
+			t.Fatal(err)
+		}
+		var names []string
+		for _, s := range tv.signers {
+			names = append(names, s.name)
+		}
+		if got := strings.Join(names, ", "); got != "key trusted.pub, identity carol@example.com" {
+			t.Errorf("threshold() signers = %s", got)
+		}
+	})
+
+	t.Run("filter", func(t *testing.T) {
+		sig, err := static.NewSignature([]byte("payload"), "c2lnbmF0dXJl")
+		if err != nil {
+			t.Fatal(err)
+		}
+		verified := []oci.Signature{sig}
+		if _, err := r.filter("ghcr.io/myorg/app@"+revokedDigest, verified); err == nil {
+			t.Error("filter() of a revoked digest succeeded")
+		}
+		other := "ghcr.io/myorg/app@sha256:" + strings.Repeat("0", 64)
+		if got, err := r.filter(other, verified); err != nil || len(got) != 1 {
+			t.Errorf("filter() = %d signatures, %v, want 1", len(got), err)
+		}
+		var none *revocations
+		if got, err := none.filter("ghcr.io/myorg/app@"+revokedDigest, verified); err != nil || len(got) != 1 {
+			t.Errorf("nil filter() = %d signatures, %v, want 1", len(got), err)
+		}
+	})
+}
+
+func TestRevocationListLocalImage(t *testing.T) {
+	ctx := context.Background()
+	const want = "--revocation-list cannot be used with --local-image"
+	v := &VerifyCommand{LocalImage: true, RevocationList: "revocations.json"}
+	if err := v.Exec(ctx, []string{"image"}); err == nil || err.Error() != want {
+		t.Errorf("verify Exec() = %v, want %q", err, want)
+	}
+	va := &VerifyAttestationCommand{LocalImage: true, RevocationList: "revocations.json"}
+	if err := va.Exec(ctx, []string{"image"}); err == nil || err.Error() != want {
+		t.Errorf("verify-attestation Exec() = %v, want %q", err, want)
+	}
+}
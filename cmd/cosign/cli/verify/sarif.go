@@ -0,0 +1,180 @@
+//
+// Copyright 2023 The Sigstore Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package verify
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"path/filepath"
+
+	"sigs.k8s.io/release-utils/version"
+
+	"github.com/sigstore/cosign/v2/pkg/policy"
+)
+
+const (
+	sarifSchema  = "https://json.schemastore.org/sarif-2.1.0.json"
+	sarifVersion = "2.1.0"
+)
+
+// The subset of SARIF 2.1.0 written by WriteSARIF.
+// See: https://docs.oasis-open.org/sarif/sarif/v2.1.0/sarif-v2.1.0.html
+type sarifLog struct {
+	Schema  string     `json:"$schema"`
+	Version string     `json:"version"`
+	Runs    []sarifRun `json:"runs"`
+}
+
+type sarifRun struct {
+	Tool    sarifTool     `json:"tool"`
+	Results []sarifResult `json:"results"`
+}
+
+type sarifTool struct {
+	Driver sarifDriver `json:"driver"`
+}
+
+type sarifDriver struct {
+	Name           string      `json:"name"`
+	Version        string      `json:"version,omitempty"`
+	InformationURI string      `json:"informationUri"`
+	Rules          []sarifRule `json:"rules"`
+}
+
+type sarifRule struct {
+	ID               string       `json:"id"`
+	ShortDescription sarifMessage `json:"shortDescription"`
+}
+
+type sarifMessage struct {
+	Text string `json:"text"`
+}
+
+type sarifResult struct {
+	RuleID     string            `json:"ruleId"`
+	RuleIndex  int               `json:"ruleIndex"`
+	Level      string            `json:"level"`
+	Message    sarifMessage      `json:"message"`
+	Locations  []sarifLocation   `json:"locations"`
+	Properties map[string]string `json:"properties,omitempty"`
+}
+
+type sarifLocation struct {
+	PhysicalLocation sarifPhysicalLocation `json:"physicalLocation"`
+}
+
+type sarifPhysicalLocation struct {
+	ArtifactLocation sarifArtifactLocation `json:"artifactLocation"`
+}
+
+type sarifArtifactLocation struct {
+	URI string `json:"uri"`
+}
+
+// WriteSARIF writes the policy violations found in results as a SARIF log,
+// for code scanning dashboards. Every failed rule of a policy becomes a
+// result located in the policy file, with the image and predicate type it
+// failed for in its message. Rules are identified by the policy engine, the
+// policy file and the Rego rule or CUE field path.
+func WriteSARIF(w io.Writer, results []VerificationResult) error {
+	run := sarifRun{
+		Tool: sarifTool{Driver: sarifDriver{
+			Name:           "cosign",
+			Version:        version.GetVersionInfo().GitVersion,
+			InformationURI: "https://github.com/sigstore/cosign",
+			Rules:          []sarifRule{},
+		}},
+		Results: []sarifResult{},
+	}
+	ruleIndex := map[string]int{}
+	for _, r := range results {
+		subject := r.Image
+		if subject == "" {
+			subject = r.Blob
+		}
+		for _, att := range r.Attestations {
+			for _, p := range att.Policies {
+				if p.Passed {
+					continue
+				}
+				violations := p.Violations
+				if len(violations) == 0 {
+					violations = []policy.Violation{{Message: "policy failed"}}
+				}
+				for _, v := range violations {
+					id := sarifRuleID(p, v)
+					idx, ok := ruleIndex[id]
+					if !ok {
+						idx = len(run.Tool.Driver.Rules)
+						ruleIndex[id] = idx
+						run.Tool.Driver.Rules = append(run.Tool.Driver.Rules, sarifRule{
+							ID:               id,
+							ShortDescription: sarifMessage{Text: fmt.Sprintf("%s policy %s", p.Engine, sarifRuleName(p, v))},
+						})
+					}
+					properties := map[string]string{
+						"predicateType": att.PredicateType,
+					}
+					if r.Image != "" {
+						properties["image"] = r.Image
+					}
+					if r.Blob != "" {
+						properties["blob"] = r.Blob
+					}
+					if r.Digest != "" {
+						properties["digest"] = r.Digest
+					}
+					run.Results = append(run.Results, sarifResult{
+						RuleID:    id,
+						RuleIndex: idx,
+						Level:     "error",
+						Message:   sarifMessage{Text: fmt.Sprintf("%s (%s): %s", subject, att.PredicateType, v.String())},
+						Locations: []sarifLocation{{
+							PhysicalLocation: sarifPhysicalLocation{
+								ArtifactLocation: sarifArtifactLocation{URI: filepath.ToSlash(p.Policy)},
+							},
+						}},
+						Properties: properties,
+					})
+				}
+			}
+		}
+	}
+
+	b, err := json.MarshalIndent(sarifLog{
+		Schema:  sarifSchema,
+		Version: sarifVersion,
+		Runs:    []sarifRun{run},
+	}, "", "  ")
+	if err != nil {
+		return fmt.Errorf("marshaling SARIF log: %w", err)
+	}
+	_, err = fmt.Fprintln(w, string(b))
+	return err
+}
+
+func sarifRuleID(p policy.PolicyResult, v policy.Violation) string {
+	return fmt.Sprintf("%s/%s", p.Engine, sarifRuleName(p, v))
+}
+
+func sarifRuleName(p policy.PolicyResult, v policy.Violation) string {
+	name := filepath.ToSlash(p.Policy)
+	if v.Rule != "" {
+		name += "#" + v.Rule
+	}
+	return name
+}
@@ -0,0 +1,121 @@
+//
+// Copyright 2023 The Sigstore Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package verify
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/sigstore/cosign/v2/pkg/policy"
+)
+
+func TestWriteSARIF(t *testing.T) {
+	failed := policy.PolicyResult{
+		Policy: "policies/vuln.rego",
+		Engine: "rego",
+		Violations: []policy.Violation{
+			{Rule: "deny", Message: "critical vulnerability found"},
+			{Rule: "deny", Message: "scanner is too old"},
+		},
+	}
+	results := []VerificationResult{{
+		Image:  "example.com/app:v1",
+		Digest: "sha256:abc",
+		Attestations: []AttestationResult{{
+			PredicateType: "vuln",
+			Policies: []policy.PolicyResult{
+				{Policy: "policies/ok.cue", Engine: "cue", Passed: true},
+				failed,
+			},
+		}},
+	}, {
+		Image: "example.com/other:v1",
+		Attestations: []AttestationResult{{
+			PredicateType: "vuln",
+			Policies:      []policy.PolicyResult{{Policy: "policies/broken.cue", Engine: "cue"}},
+		}},
+	}}
+
+	var buf bytes.Buffer
+	if err := WriteSARIF(&buf, results); err != nil {
+		t.Fatalf("WriteSARIF() = %v", err)
+	}
+	var log sarifLog
+	if err := json.Unmarshal(buf.Bytes(), &log); err != nil {
+		t.Fatalf("unmarshaling SARIF log: %v", err)
+	}
+	if log.Version != sarifVersion || len(log.Runs) != 1 {
+		t.Fatalf("got version %s with %d runs", log.Version, len(log.Runs))
+	}
+	run := log.Runs[0]
+
+	wantRules := []string{"rego/policies/vuln.rego#deny", "cue/policies/broken.cue"}
+	if len(run.Tool.Driver.Rules) != len(wantRules) {
+		t.Fatalf("got %d rules, want %d", len(run.Tool.Driver.Rules), len(wantRules))
+	}
+	for i, id := range wantRules {
+		if got := run.Tool.Driver.Rules[i].ID; got != id {
+			t.Errorf("rule %d = %s, want %s", i, got, id)
+		}
+	}
+
+	tests := []struct {
+		ruleID    string
+		ruleIndex int
+		uri       string
+		message   string
+	}{
+		{"rego/policies/vuln.rego#deny", 0, "policies/vuln.rego", "example.com/app:v1 (vuln): deny: critical vulnerability found"},
+		{"rego/policies/vuln.rego#deny", 0, "policies/vuln.rego", "example.com/app:v1 (vuln): deny: scanner is too old"},
+		{"cue/policies/broken.cue", 1, "policies/broken.cue", "example.com/other:v1 (vuln): policy failed"},
+	}
+	if len(run.Results) != len(tests) {
+		t.Fatalf("got %d results, want %d", len(run.Results), len(tests))
+	}
+	for i, tt := range tests {
+		got := run.Results[i]
+		if got.RuleID != tt.ruleID || got.RuleIndex != tt.ruleIndex {
+			t.Errorf("result %d rule = %s (%d), want %s (%d)", i, got.RuleID, got.RuleIndex, tt.ruleID, tt.ruleIndex)
+		}
+		if got.Level != "error" {
+			t.Errorf("result %d level = %s, want error", i, got.Level)
+		}
+		if got.Message.Text != tt.message {
+			t.Errorf("result %d message = %q, want %q", i, got.Message.Text, tt.message)
+		}
+		if len(got.Locations) != 1 || got.Locations[0].PhysicalLocation.ArtifactLocation.URI != tt.uri {
+			t.Errorf("result %d locations = %+v, want %s", i, got.Locations, tt.uri)
+		}
+	}
+	if got := run.Results[0].Properties["digest"]; got != "sha256:abc" {
+		t.Errorf("digest property = %q, want sha256:abc", got)
+	}
+}
+
+func TestWriteSARIFNoViolations(t *testing.T) {
+	var buf bytes.Buffer
+	if err := WriteSARIF(&buf, nil); err != nil {
+		t.Fatalf("WriteSARIF() = %v", err)
+	}
+	var log sarifLog
+	if err := json.Unmarshal(buf.Bytes(), &log); err != nil {
+		t.Fatalf("unmarshaling SARIF log: %v", err)
+	}
+	if len(log.Runs) != 1 || log.Runs[0].Results == nil || len(log.Runs[0].Results) != 0 {
+		t.Errorf("got runs %+v, want one run without results", log.Runs)
+	}
+}
@@ -0,0 +1,7 @@
+package signature
+
+default allow = false
+
+allow {
+	input.predicate.metadata.component.name == "ghcr.io/example/other"
+}
@@ -0,0 +1,5 @@
+package cosign.attestation
+
+allow {
+	startswith(input.predicate.builder.id, "https://github.com/slsa-framework/slsa-github-generator/")
+}
@@ -0,0 +1,2 @@
+predicate: builder: id: =~"^https://github.com/slsa-framework/slsa-github-generator/"
+predicate: buildr: id: =~"^https://github.com/slsa-framework/slsa-github-generator/"
@@ -0,0 +1,2 @@
+predicateType: "https://slsa.dev/provenance/v0.2"
+predicate: builder: id: =~"^https://github.com/slsa-framework/slsa-github-generator/"
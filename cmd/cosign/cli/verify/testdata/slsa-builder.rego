@@ -0,0 +1,6 @@
+package signature
+
+allow {
+	input.predicateType == "https://slsa.dev/provenance/v0.2"
+	startswith(input.predicate.builder.id, "https://github.com/slsa-framework/slsa-github-generator/")
+}
@@ -0,0 +1 @@
+predicate: buildType: "https://example.com/unexpected@v1"
@@ -0,0 +1,7 @@
+package signature
+
+default allow = false
+
+allow {
+	input.predicate.buildType == "https://example.com/unexpected@v1"
+}
@@ -0,0 +1,4 @@
+predicateType: "https://cosign.sigstore.dev/attestation/vuln/v1"
+predicate: scanner: result: Results: [...{
+	Vulnerabilities?: [...{Severity: !="CRITICAL"}]
+}]
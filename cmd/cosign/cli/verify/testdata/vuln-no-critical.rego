@@ -0,0 +1,12 @@
+package signature
+
+deny[msg] {
+	vuln := input.predicate.scanner.result.Results[_].Vulnerabilities[_]
+	vuln.Severity == "CRITICAL"
+	msg := sprintf("%s in %s is critical", [vuln.VulnerabilityID, vuln.PkgName])
+}
+
+allow {
+	input.predicateType == "https://cosign.sigstore.dev/attestation/vuln/v1"
+	count(deny) == 0
+}
@@ -0,0 +1,261 @@
+//
+// Copyright 2023 The Sigstore Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package verify
+
+import (
+	"context"
+	"crypto"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/sigstore/cosign/v2/pkg/cosign"
+	"github.com/sigstore/cosign/v2/pkg/cosign/rootpolicy"
+	"github.com/sigstore/cosign/v2/pkg/oci"
+	sigs "github.com/sigstore/cosign/v2/pkg/signature"
+	"github.com/sigstore/sigstore/pkg/signature"
+	"k8s.io/apimachinery/pkg/util/yaml"
+)
+
+// ThresholdPolicy requires that at least Threshold of the trusted signers
+// signed an image or attestation, e.g. for releases approved by several
+// maintainers. It is read from a YAML or JSON file:
+//
+//	threshold: 2
+//	keys:
+//	  - alice.pub
+//	  - gcpkms://projects/p/locations/global/keyRings/r/cryptoKeys/bob
+//	identities:
+//	  - issuer: https://accounts.google.com
+//	    subject: carol@example.com
+type ThresholdPolicy struct {
+	// Threshold is the number of distinct signers required.
+	Threshold int `json:"threshold"`
+	// Keys are references to trusted public keys, as accepted by --key.
+	Keys []string `json:"keys,omitempty"`
+	// Identities are trusted keyless signers, each matched like the
+	// --certificate-* flags.
+	Identities []cosign.Identity `json:"identities,omitempty"`
+}
+
+// LoadThresholdPolicy reads and validates the threshold policy at path.
+func LoadThresholdPolicy(path string) (*ThresholdPolicy, error) {
+	f, err := os.Open(filepath.Clean(path))
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var p ThresholdPolicy
+	if err := yaml.NewYAMLOrJSONDecoder(f, 4096).Decode(&p); err != nil {
+		return nil, fmt.Errorf("parsing threshold policy %s: %w", path, err)
+	}
+	if err := p.validate(); err != nil {
+		return nil, fmt.Errorf("invalid threshold policy %s: %w", path, err)
+	}
+	return &p, nil
+}
+
+func (p *ThresholdPolicy) validate() error {
+	n := len(p.Keys) + len(p.Identities)
+	if p.Threshold < 1 || p.Threshold > n {
+		return fmt.Errorf("threshold must be between 1 and the number of signers %d, got %d", n, p.Threshold)
+	}
+	for i, id := range p.Identities {
+		if id.Issuer == "" && id.IssuerRegExp == "" {
+			return fmt.Errorf("identity %d has no issuer or issuerRegExp", i+1)
+		}
+		if id.Subject == "" && id.SubjectRegExp == "" && id.Email == "" && id.URI == "" && id.URIRegExp == "" {
+			return fmt.Errorf("identity %d has no subject, subjectRegExp, email, uri or uriRegExp", i+1)
+		}
+	}
+	return nil
+}
+
+// thresholdSigner is one of the trusted signers of a threshold policy.
+type thresholdSigner struct {
+	name     string
+	verifier signature.Verifier
+	identity *cosign.Identity
+}
+
+// checkOpts returns a copy of co that only trusts s.
+func (s *thresholdSigner) checkOpts(co *cosign.CheckOpts) *cosign.CheckOpts {
+	signerCo := *co
+	signerCo.SigVerifier = s.verifier
+	signerCo.Identities = nil
+	if s.identity != nil {
+		signerCo.Identities = []cosign.Identity{*s.identity}
+	}
+	return &signerCo
+}
+
+// thresholdVerifier verifies signatures against a threshold policy.
+type thresholdVerifier struct {
+	threshold int
+	signers   []thresholdSigner
+}
+
+func newThresholdVerifier(ctx context.Context, p *ThresholdPolicy, hashAlgorithm crypto.Hash) (*thresholdVerifier, error) {
+	t := &thresholdVerifier{threshold: p.Threshold}
+	// Keys are told apart by their ID rather than their reference, which
+	// may differ for the same key, e.g. a copy of a file or a KMS alias.
+	seen := map[string]string{}
+	for _, k := range p.Keys {
+		v, err := sigs.PublicKeyFromKeyRefWithHashAlgo(ctx, k, hashAlgorithm)
+		if err != nil {
+			return nil, fmt.Errorf("loading public key %s: %w", k, err)
+		}
+		pub, err := v.PublicKey()
+		if err != nil {
+			return nil, fmt.Errorf("loading public key %s: %w", k, err)
+		}
+		id, err := rootpolicy.KeyID(pub)
+		if err != nil {
+			return nil, fmt.Errorf("loading public key %s: %w", k, err)
+		}
+		if other, ok := seen[id]; ok {
+			return nil, fmt.Errorf("keys %s and %s are the same key", other, k)
+		}
+		seen[id] = k
+		t.signers = append(t.signers, thresholdSigner{name: "key " + k, verifier: v})
+	}
+	for i := range p.Identities {
+		id := p.Identities[i]
+		t.signers = append(t.signers, thresholdSigner{name: identityName(id), identity: &id})
+	}
+	return t, nil
+}
+
+// identityName names the signer id, e.g. identity alice@example.com
+// (https://accounts.google.com).
+func identityName(id cosign.Identity) string {
+	subject := id.Subject
+	for _, s := range []string{id.Email, id.URI, id.SubjectRegExp, id.URIRegExp} {
+		if subject == "" {
+			subject = s
+		}
+	}
+	issuer := id.Issuer
+	if issuer == "" {
+		issuer = id.IssuerRegExp
+	}
+	return fmt.Sprintf("identity %s (%s)", subject, issuer)
+}
+
+// verifyFunc verifies the signatures of an image with co.
+type verifyFunc func(co *cosign.CheckOpts) ([]oci.Signature, bool, error)
+
+// verify runs verify once for each of the signers and succeeds if enough of
+// them verified. Each signer must be matched to a signature of its own, so
+// that a signature matching several identities is not counted twice. A nil t
+// runs verify with co unchanged.
+func (t *thresholdVerifier) verify(co *cosign.CheckOpts, verify verifyFunc) ([]oci.Signature, bool, error) {
+	verified, _, bundleVerified, err := t.verifySigners(co, verify)
+	return verified, bundleVerified, err
+}
+
+// verifySigners is verify, also returning the names of every signer whose
+// signature was verified, e.g. the builder and the teams that counter-signed
+// a release. A nil t returns no signers.
+func (t *thresholdVerifier) verifySigners(co *cosign.CheckOpts, verify verifyFunc) ([]oci.Signature, []string, bool, error) {
+	if t == nil {
+		verified, bundleVerified, err := verify(co)
+		return verified, nil, bundleVerified, err
+	}
+
+	var verified []oci.Signature
+	bundleVerified := true
+	seen := map[string]bool{}
+	var signedBy [][]string
+	var signers []string
+	var errs []error
+	for i := range t.signers {
+		s := &t.signers[i]
+		signatures, bv, err := verify(s.checkOpts(co))
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", s.name, err))
+			continue
+		}
+		keys := make([]string, 0, len(signatures))
+		for _, sig := range signatures {
+			k, err := signatureKey(sig)
+			if err != nil {
+				return nil, nil, false, err
+			}
+			if !seen[k] {
+				seen[k] = true
+				verified = append(verified, sig)
+			}
+			keys = append(keys, k)
+		}
+		signedBy = append(signedBy, keys)
+		signers = append(signers, s.name)
+		bundleVerified = bundleVerified && bv
+	}
+
+	if n := distinctSigners(signedBy); n < t.threshold {
+		err := fmt.Errorf("%d of the %d required signers signed", n, t.threshold)
+		if len(errs) > 0 {
+			err = fmt.Errorf("%w: %w", err, errors.Join(errs...))
+		}
+		return nil, nil, false, err
+	}
+	return verified, signers, bundleVerified, nil
+}
+
+// signatureKey identifies sig by its payload and signature, as the signatures
+// counter-signing a payload share its digest.
+func signatureKey(sig oci.Signature) (string, error) {
+	d, err := sig.Digest()
+	if err != nil {
+		return "", err
+	}
+	b64sig, err := sig.Base64Signature()
+	if err != nil {
+		return "", err
+	}
+	return d.String() + "/" + b64sig, nil
+}
+
+// distinctSigners returns the largest number of signers that can each be
+// matched to a signature of their own, signedBy holding the signatures of
+// each signer.
+func distinctSigners(signedBy [][]string) int {
+	owner := map[string]int{}
+	var match func(i int, visited map[string]bool) bool
+	match = func(i int, visited map[string]bool) bool {
+		for _, d := range signedBy[i] {
+			if visited[d] {
+				continue
+			}
+			visited[d] = true
+			if o, ok := owner[d]; !ok || match(o, visited) {
+				owner[d] = i
+				return true
+			}
+		}
+		return false
+	}
+	n := 0
+	for i := range signedBy {
+		if match(i, map[string]bool{}) {
+			n++
+		}
+	}
+	return n
+}
@@ -0,0 +1,192 @@
+//
+// Copyright 2023 The Sigstore Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package verify
+
+import (
+	"context"
+	"crypto"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/sigstore/cosign/v2/pkg/cosign"
+	"github.com/sigstore/cosign/v2/pkg/oci"
+	"github.com/sigstore/cosign/v2/pkg/oci/static"
+)
+
+func TestLoadThresholdPolicy(t *testing.T) {
+	tests := []struct {
+		name    string
+		policy  string
+		wantErr string
+	}{{
+		name: "yaml",
+		policy: `threshold: 2
+keys:
+  - alice.pub
+identities:
+  - issuer: https://accounts.google.com
+    subject: carol@example.com
+`,
+	}, {
+		name:   "json",
+		policy: `{"threshold": 1, "identities": [{"issuerRegExp": ".*", "email": "carol@example.com"}]}`,
+	}, {
+		name:    "threshold too large",
+		policy:  `{"threshold": 2, "keys": ["alice.pub"]}`,
+		wantErr: "threshold must be between 1 and the number of signers 1, got 2",
+	}, {
+		name:    "no threshold",
+		policy:  `{"keys": ["alice.pub"]}`,
+		wantErr: "threshold must be between",
+	}, {
+		name:    "identity without issuer",
+		policy:  `{"threshold": 1, "identities": [{"subject": "carol@example.com"}]}`,
+		wantErr: "identity 1 has no issuer",
+	}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "policy")
+			if err := os.WriteFile(path, []byte(tt.policy), 0600); err != nil {
+				t.Fatal(err)
+			}
+			p, err := LoadThresholdPolicy(path)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("LoadThresholdPolicy() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("LoadThresholdPolicy() = %v", err)
+			}
+			if len(p.Keys)+len(p.Identities) < p.Threshold {
+				t.Errorf("LoadThresholdPolicy() = %+v", p)
+			}
+		})
+	}
+}
+
+func TestNewThresholdVerifierDuplicateKey(t *testing.T) {
+	keys, err := cosign.GenerateKeyPair(func(bool) ([]byte, error) { return nil, nil })
+	if err != nil {
+		t.Fatal(err)
+	}
+	dir := t.TempDir()
+	alice, copied := filepath.Join(dir, "alice.pub"), filepath.Join(dir, "copy.pub")
+	for _, path := range []string{alice, copied} {
+		if err := os.WriteFile(path, keys.PublicBytes, 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	p := &ThresholdPolicy{Threshold: 2, Keys: []string{alice, copied}}
+	if _, err := newThresholdVerifier(context.Background(), p, crypto.SHA256); err == nil || !strings.Contains(err.Error(), "are the same key") {
+		t.Errorf("newThresholdVerifier() error = %v, want the keys rejected as the same", err)
+	}
+}
+
+func TestThresholdVerifier(t *testing.T) {
+	newSig := func(s string) oci.Signature {
+		sig, err := static.NewSignature([]byte(s), "c2lnbmF0dXJl")
+		if err != nil {
+			t.Fatal(err)
+		}
+		return sig
+	}
+	sig1, sig2 := newSig("one"), newSig("two")
+	// counterSig counter-signs the payload of sig1.
+	counterSig, err := static.NewSignature([]byte("one"), "Y291bnRlci1zaWduYXR1cmU=")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Each signer is an identity, the verified signatures are keyed by subject.
+	signed := map[string][]oci.Signature{
+		"alice": {sig1},
+		"bob":   {sig2},
+		"both":  {sig1, sig2},
+		"carol": {sig1},
+		"qa":    {counterSig},
+	}
+	verify := func(co *cosign.CheckOpts) ([]oci.Signature, bool, error) {
+		if sigs := signed[co.Identities[0].Subject]; len(sigs) > 0 {
+			return sigs, true, nil
+		}
+		return nil, false, errors.New("no matching signatures")
+	}
+	newVerifier := func(threshold int, subjects ...string) *thresholdVerifier {
+		tv := &thresholdVerifier{threshold: threshold}
+		for _, s := range subjects {
+			tv.signers = append(tv.signers, thresholdSigner{name: s, identity: &cosign.Identity{Subject: s}})
+		}
+		return tv
+	}
+
+	tests := []struct {
+		name        string
+		verifier    *thresholdVerifier
+		want        int
+		wantSigners []string
+		wantErr     string
+	}{{
+		name:        "enough signers",
+		verifier:    newVerifier(2, "alice", "bob", "dave"),
+		want:        2,
+		wantSigners: []string{"alice", "bob"},
+	}, {
+		name:     "not enough signers",
+		verifier: newVerifier(2, "alice", "dave"),
+		wantErr:  "1 of the 2 required signers signed",
+	}, {
+		name:     "signature counted once",
+		verifier: newVerifier(2, "alice", "carol"),
+		wantErr:  "1 of the 2 required signers signed",
+	}, {
+		name:        "signers sharing signatures",
+		verifier:    newVerifier(2, "both", "alice"),
+		want:        2,
+		wantSigners: []string{"both", "alice"},
+	}, {
+		name:        "counter-signed payload",
+		verifier:    newVerifier(2, "alice", "qa", "dave"),
+		want:        2,
+		wantSigners: []string{"alice", "qa"},
+	}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			verified, signers, bundleVerified, err := tt.verifier.verifySigners(&cosign.CheckOpts{}, verify)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("verify() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("verify() = %v", err)
+			}
+			if len(verified) != tt.want || !bundleVerified {
+				t.Errorf("verify() = %d signatures, bundle verified %t", len(verified), bundleVerified)
+			}
+			if !reflect.DeepEqual(signers, tt.wantSigners) {
+				t.Errorf("verify() signers = %v, want %v", signers, tt.wantSigners)
+			}
+		})
+	}
+}
@@ -0,0 +1,57 @@
+//
+// Copyright 2023 The Sigstore Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package verify
+
+import (
+	"context"
+	"crypto"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/sigstore/cosign/v2/cmd/cosign/cli/options"
+	"github.com/sigstore/cosign/v2/pkg/cosign"
+	"github.com/sigstore/cosign/v2/pkg/cosign/rootpolicy"
+)
+
+// trustPolicyKeys fetches and verifies the root-of-trust policy of namespace,
+// and returns its maintainer keys, any of which may sign, and the sha256
+// digest of the policy.
+func trustPolicyKeys(ctx context.Context, namespace string, regOpts options.RegistryOptions, hashAlgorithm crypto.Hash) (cosign.TrustedKeys, string, error) {
+	ref, err := rootpolicy.Ref(namespace, regOpts.NameOptions()...)
+	if err != nil {
+		return nil, "", err
+	}
+	sp, err := rootpolicy.Fetch(ref, regOpts.GetRegistryClientOpts(ctx)...)
+	if err != nil {
+		return nil, "", err
+	}
+	if err := sp.Verify(namespace, time.Now()); err != nil {
+		return nil, "", fmt.Errorf("verifying policy %s: %w", ref, err)
+	}
+	verifiers, err := sp.Signed.Verifiers(hashAlgorithm)
+	if err != nil {
+		return nil, "", err
+	}
+	b, err := json.Marshal(sp)
+	if err != nil {
+		return nil, "", err
+	}
+	sum := sha256.Sum256(b)
+	return cosign.TrustedKeys(verifiers), hex.EncodeToString(sum[:]), nil
+}
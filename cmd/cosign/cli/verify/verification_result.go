@@ -0,0 +1,312 @@
+//
+// Copyright 2023 The Sigstore Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package verify
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"sigs.k8s.io/yaml"
+
+	"github.com/sigstore/cosign/v2/pkg/cosign"
+	"github.com/sigstore/cosign/v2/pkg/oci"
+	"github.com/sigstore/cosign/v2/pkg/policy"
+	sigs "github.com/sigstore/cosign/v2/pkg/signature"
+)
+
+// VerificationResultSchemaVersion is the version of the VerificationResult
+// schema. Fields may be added within a version, but are never renamed or
+// removed, nor change meaning.
+const VerificationResultSchemaVersion = "v1"
+
+// VerificationResult is the machine-readable result of verifying a single
+// image or blob, written as a list by verify, verify-attestation,
+// verify-blob and verify-blob-attestation with --output json or yaml:
+//
+//   - schemaVersion: always VerificationResultSchemaVersion.
+//   - image or blob: the image reference or blob path as given.
+//   - digest: the sha256 digest that was verified, when known.
+//   - platform: the platform of the image, with --platform or --recursive.
+//   - verified: whether the verification succeeded; error holds why not.
+//   - signatures: the verified signatures (verify, verify-blob).
+//   - signers: the signers of the --threshold-policy whose signatures were
+//     verified, e.g. a builder and the teams that counter-signed (verify).
+//   - predicateTypes, missingPredicateTypes and attestations: the requested
+//     predicate types, those no attestation was found for, and the matching
+//     attestations with the outcome of every policy evaluated against them
+//     (verify-attestation, verify-blob-attestation).
+//
+// Signatures and attestations record the identity of the certificate they
+// were verified with and their transparency log entry, when there is one.
+type VerificationResult struct {
+	SchemaVersion         string              `json:"schemaVersion"`
+	Image                 string              `json:"image,omitempty"`
+	Blob                  string              `json:"blob,omitempty"`
+	Digest                string              `json:"digest,omitempty"`
+	Platform              string              `json:"platform,omitempty"`
+	Verified              bool                `json:"verified"`
+	Error                 string              `json:"error,omitempty"`
+	Signatures            []SignatureResult   `json:"signatures,omitempty"`
+	Signers               []string            `json:"signers,omitempty"`
+	PredicateTypes        []string            `json:"predicateTypes,omitempty"`
+	MissingPredicateTypes []string            `json:"missingPredicateTypes,omitempty"`
+	Attestations          []AttestationResult `json:"attestations,omitempty"`
+}
+
+// SignatureResult is a verified signature. Payload is the signed payload,
+// omitted for blobs.
+type SignatureResult struct {
+	Identity *CertificateIdentity `json:"identity,omitempty"`
+	Rekor    *RekorEntry          `json:"rekor,omitempty"`
+	Payload  json.RawMessage      `json:"payload,omitempty"`
+}
+
+// CertificateIdentity is the identity of the certificate a signature or
+// attestation was verified with.
+type CertificateIdentity struct {
+	Subject string `json:"subject"`
+	Issuer  string `json:"issuer,omitempty"`
+}
+
+// RekorEntry locates a signature or attestation in the transparency log.
+type RekorEntry struct {
+	LogIndex       int64  `json:"logIndex"`
+	LogID          string `json:"logID"`
+	IntegratedTime string `json:"integratedTime"`
+}
+
+// AttestationResult holds a verified attestation matching one of the requested
+// predicate types together with the outcome of each policy evaluated against it.
+// PredicateDigest is the sha256 digest of the predicate of its statement.
+type AttestationResult struct {
+	PredicateType   string                `json:"predicateType"`
+	PredicateDigest string                `json:"predicateDigest,omitempty"`
+	Identity        *CertificateIdentity  `json:"identity,omitempty"`
+	Rekor           *RekorEntry           `json:"rekor,omitempty"`
+	Envelope        json.RawMessage       `json:"envelope"`
+	Policies        []policy.PolicyResult `json:"policies,omitempty"`
+	Passed          bool                  `json:"passed"`
+}
+
+// PolicyVerification is the context an attestation was verified in, given to
+// the CUE and Rego policies under the policy.VerificationField of their
+// input, e.g. input.verification.identity.issuer in Rego:
+//
+//   - image: the image reference as given, omitted for blobs.
+//   - digest: the sha256 digest of the image or blob whose attestation was
+//     verified.
+//   - identity: the subject and issuer of the signing certificate, omitted
+//     for attestations signed with a key.
+//   - rekor: the logIndex, logID and integratedTime of the transparency log
+//     entry, omitted when there is none.
+//   - annotations: the annotations of the attestation.
+type PolicyVerification struct {
+	Image       string               `json:"image,omitempty"`
+	Digest      string               `json:"digest,omitempty"`
+	Identity    *CertificateIdentity `json:"identity,omitempty"`
+	Rekor       *RekorEntry          `json:"rekor,omitempty"`
+	Annotations map[string]string    `json:"annotations,omitempty"`
+}
+
+// policyInput returns the policy input for the attestation att of result,
+// the payload with the PolicyVerification of att. digest may be a reference
+// by digest.
+func policyInput(payload []byte, att oci.Signature, result AttestationResult, image, digest string) ([]byte, error) {
+	annotations, err := att.Annotations()
+	if err != nil {
+		return nil, fmt.Errorf("reading annotations: %w", err)
+	}
+	if _, d, ok := strings.Cut(digest, "@"); ok {
+		digest = d
+	}
+	return policy.WithVerification(payload, PolicyVerification{
+		Image:       image,
+		Digest:      digest,
+		Identity:    result.Identity,
+		Rekor:       result.Rekor,
+		Annotations: annotations,
+	})
+}
+
+func newVerificationResult() VerificationResult {
+	return VerificationResult{SchemaVersion: VerificationResultSchemaVersion}
+}
+
+// finish records the outcome of the verification in r.
+func (r *VerificationResult) finish(err error) {
+	r.Verified = err == nil
+	if err != nil {
+		r.Error = err.Error()
+	}
+}
+
+func newSignatureResult(sig oci.Signature, withPayload bool) (SignatureResult, error) {
+	var result SignatureResult
+	var err error
+	if result.Identity, result.Rekor, err = signatureProvenance(sig); err != nil {
+		return SignatureResult{}, err
+	}
+	if withPayload {
+		if result.Payload, err = sig.Payload(); err != nil {
+			return SignatureResult{}, fmt.Errorf("fetching payload: %w", err)
+		}
+	}
+	return result, nil
+}
+
+// newAttestationResult returns the result for att, whose envelope holds the
+// in-toto statement.
+func newAttestationResult(predicateType string, att oci.Signature, statement []byte) (AttestationResult, error) {
+	p, err := att.Payload()
+	if err != nil {
+		return AttestationResult{}, fmt.Errorf("fetching payload: %w", err)
+	}
+	identity, rekor, err := signatureProvenance(att)
+	if err != nil {
+		return AttestationResult{}, err
+	}
+	return AttestationResult{
+		PredicateType:   predicateType,
+		PredicateDigest: predicateDigest(statement),
+		Identity:        identity,
+		Rekor:           rekor,
+		Envelope:        p,
+		Passed:          true,
+	}, nil
+}
+
+func (r *AttestationResult) addPolicyResult(pr policy.PolicyResult) {
+	if !pr.Passed {
+		r.Passed = false
+	}
+	r.Policies = append(r.Policies, pr)
+}
+
+// signatureProvenance returns the certificate identity and transparency log
+// entry of sig, each nil when sig has none.
+func signatureProvenance(sig oci.Signature) (*CertificateIdentity, *RekorEntry, error) {
+	var identity *CertificateIdentity
+	cert, err := sig.Cert()
+	if err != nil {
+		return nil, nil, fmt.Errorf("reading certificate: %w", err)
+	}
+	if cert != nil {
+		ce := cosign.CertExtensions{Cert: cert}
+		identity = &CertificateIdentity{Subject: sigs.CertSubject(cert), Issuer: ce.GetIssuer()}
+	}
+	var rekor *RekorEntry
+	b, err := sig.Bundle()
+	if err != nil {
+		return nil, nil, fmt.Errorf("reading transparency log bundle: %w", err)
+	}
+	if b != nil {
+		rekor = &RekorEntry{
+			LogIndex:       b.Payload.LogIndex,
+			LogID:          b.Payload.LogID,
+			IntegratedTime: time.Unix(b.Payload.IntegratedTime, 0).UTC().Format(time.RFC3339),
+		}
+	}
+	return identity, rekor, nil
+}
+
+// predicateDigest returns the sha256 digest of the predicate of the in-toto
+// statement, or "" when it can't be decoded.
+func predicateDigest(statement []byte) string {
+	var s struct {
+		Predicate json.RawMessage `json:"predicate"`
+	}
+	if err := json.Unmarshal(statement, &s); err != nil || len(s.Predicate) == 0 {
+		return ""
+	}
+	sum := sha256.Sum256(s.Predicate)
+	return "sha256:" + hex.EncodeToString(sum[:])
+}
+
+// isStructuredOutput reports whether output requests VerificationResults,
+// or a SARIF log built from them.
+func isStructuredOutput(output string) bool {
+	return output == "json" || output == "yaml" || output == "sarif"
+}
+
+// checkOutput validates the value of --output. The empty value is accepted
+// for the default of each command; extra lists the formats beyond json, yaml
+// and text that the command supports.
+func checkOutput(output string, extra ...string) error {
+	formats := append([]string{"json", "yaml", "text"}, extra...)
+	if output == "" {
+		return nil
+	}
+	for _, f := range formats {
+		if output == f {
+			return nil
+		}
+	}
+	return fmt.Errorf("unsupported output format %q, must be one of %s", output, strings.Join(formats, ", "))
+}
+
+// WriteVerificationResults writes the results as indented JSON or as YAML,
+// depending on output.
+func WriteVerificationResults(w io.Writer, output string, results []VerificationResult) error {
+	if results == nil {
+		results = []VerificationResult{}
+	}
+	if err := writeStructured(w, output, results); err != nil {
+		return fmt.Errorf("marshaling verification results: %w", err)
+	}
+	return nil
+}
+
+// writeStructured writes v as indented JSON or as YAML, depending on output.
+func writeStructured(w io.Writer, output string, v any) error {
+	b, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return err
+	}
+	if output == "yaml" {
+		if b, err = yaml.JSONToYAML(b); err != nil {
+			return err
+		}
+		_, err = w.Write(b)
+		return err
+	}
+	_, err = fmt.Fprintln(w, string(b))
+	return err
+}
+
+// finishWithResults writes the results when structured output was requested,
+// so that callers get the verification details even when verification
+// fails, and then returns err.
+func finishWithResults(w io.Writer, output string, results []VerificationResult, err error) error {
+	if !isStructuredOutput(output) {
+		return err
+	}
+	var werr error
+	if output == "sarif" {
+		werr = WriteSARIF(w, results)
+	} else {
+		werr = WriteVerificationResults(w, output, results)
+	}
+	if werr != nil {
+		return errors.Join(err, werr)
+	}
+	return err
+}
@@ -0,0 +1,151 @@
+// Copyright 2023 the Sigstore Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package verify
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"sigs.k8s.io/yaml"
+
+	"github.com/sigstore/cosign/v2/pkg/oci/static"
+	"github.com/sigstore/cosign/v2/pkg/policy"
+)
+
+func TestVerificationResult(t *testing.T) {
+	result := AttestationResult{
+		PredicateType: "https://slsa.dev/provenance/v0.2",
+		Envelope:      json.RawMessage(`{"payloadType":"application/vnd.in-toto+json"}`),
+		Passed:        true,
+	}
+	passed := policy.PolicyResult{Policy: "policy.cue", Engine: "cue", Passed: true}
+	failed := policy.PolicyResult{
+		Policy:     "policy.rego",
+		Engine:     "rego",
+		Violations: []policy.Violation{{Rule: "data.signature.deny", Message: "builder is not trusted"}},
+	}
+	result.addPolicyResult(passed)
+	if !result.Passed {
+		t.Fatal("expected result to pass with no policy errors")
+	}
+	result.addPolicyResult(failed)
+	if result.Passed {
+		t.Fatal("expected result to fail after a failed policy")
+	}
+
+	report := newVerificationResult()
+	report.Image = "example.com/image"
+	report.PredicateTypes = []string{"slsaprovenance"}
+	report.Attestations = []AttestationResult{result}
+	report.finish(errors.New("1 validation errors occurred"))
+
+	for _, output := range []string{"json", "yaml"} {
+		t.Run(output, func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := WriteVerificationResults(&buf, output, []VerificationResult{report}); err != nil {
+				t.Fatalf("WriteVerificationResults() = %v", err)
+			}
+
+			var got []VerificationResult
+			if err := yaml.Unmarshal(buf.Bytes(), &got); err != nil {
+				t.Fatalf("unmarshaling results: %v", err)
+			}
+			if len(got) != 1 {
+				t.Fatalf("got %d results, want 1", len(got))
+			}
+			if got[0].SchemaVersion != VerificationResultSchemaVersion || got[0].Verified || got[0].Error == "" {
+				t.Errorf("unexpected result: %+v", got[0])
+			}
+			want := []policy.PolicyResult{passed, failed}
+			if diff := cmp.Diff(want, got[0].Attestations[0].Policies); diff != "" {
+				t.Errorf("unexpected policy reports (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestCheckOutput(t *testing.T) {
+	for _, output := range []string{"", "json", "yaml", "text"} {
+		if err := checkOutput(output); err != nil {
+			t.Errorf("checkOutput(%q) = %v", output, err)
+		}
+	}
+	if err := checkOutput("xml"); err == nil {
+		t.Error("checkOutput(xml) succeeded, want error")
+	}
+	if err := checkOutput("sarif"); err == nil {
+		t.Error("checkOutput(sarif) succeeded, want error")
+	}
+	if err := checkOutput("sarif", "sarif"); err != nil {
+		t.Errorf("checkOutput(sarif, sarif) = %v", err)
+	}
+}
+
+func TestPredicateDigest(t *testing.T) {
+	statement := `{"_type":"https://in-toto.io/Statement/v0.1","predicateType":"custom","predicate":{"foo":"bar"}}`
+	sum := sha256.Sum256([]byte(`{"foo":"bar"}`))
+	want := "sha256:" + hex.EncodeToString(sum[:])
+	if got := predicateDigest([]byte(statement)); got != want {
+		t.Errorf("predicateDigest() = %s, want %s", got, want)
+	}
+	if got := predicateDigest([]byte("not a statement")); got != "" {
+		t.Errorf("predicateDigest() = %s, want empty", got)
+	}
+}
+
+func TestPolicyInput(t *testing.T) {
+	att, err := static.NewAttestation([]byte("{}"), static.WithAnnotations(map[string]string{"env": "prod"}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	result := AttestationResult{
+		Identity: &CertificateIdentity{Subject: "https://github.com/org/repo/.github/workflows/release.yml@refs/heads/main", Issuer: "https://token.actions.githubusercontent.com"},
+		Rekor:    &RekorEntry{LogIndex: 42, LogID: "c0d23d6a", IntegratedTime: "2023-05-01T00:00:00Z"},
+	}
+	payload := []byte(`{"predicateType":"custom","predicate":{"foo":"bar"}}`)
+	got, err := policyInput(payload, att, result, "registry.example.com/app:v1", "registry.example.com/app@sha256:abc")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var input struct {
+		PredicateType string             `json:"predicateType"`
+		Verification  PolicyVerification `json:"verification"`
+	}
+	if err := json.Unmarshal(got, &input); err != nil {
+		t.Fatal(err)
+	}
+	if input.PredicateType != "custom" {
+		t.Errorf("predicateType = %q, want custom", input.PredicateType)
+	}
+	v := input.Verification
+	if v.Image != "registry.example.com/app:v1" || v.Digest != "sha256:abc" {
+		t.Errorf("image, digest = %q, %q", v.Image, v.Digest)
+	}
+	if diff := cmp.Diff(result.Identity, v.Identity); diff != "" {
+		t.Errorf("unexpected identity (-want +got):\n%s", diff)
+	}
+	if diff := cmp.Diff(result.Rekor, v.Rekor); diff != "" {
+		t.Errorf("unexpected rekor entry (-want +got):\n%s", diff)
+	}
+	if v.Annotations["env"] != "prod" {
+		t.Errorf("annotations = %v, want env=prod", v.Annotations)
+	}
+}
@@ -27,9 +27,10 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
+	"time"
 
 	"github.com/google/go-containerregistry/pkg/name"
-	"github.com/sigstore/cosign/v2/cmd/cosign/cli/fulcio"
 	"github.com/sigstore/cosign/v2/cmd/cosign/cli/options"
 	"github.com/sigstore/cosign/v2/cmd/cosign/cli/rekor"
 	"github.com/sigstore/cosign/v2/cmd/cosign/cli/sign"
@@ -40,8 +41,11 @@ import (
 	"github.com/sigstore/cosign/v2/pkg/cosign"
 	"github.com/sigstore/cosign/v2/pkg/cosign/pivkey"
 	"github.com/sigstore/cosign/v2/pkg/cosign/pkcs11key"
+	"github.com/sigstore/cosign/v2/pkg/cosign/wasm"
 	"github.com/sigstore/cosign/v2/pkg/oci"
+	ociremote "github.com/sigstore/cosign/v2/pkg/oci/remote"
 	sigs "github.com/sigstore/cosign/v2/pkg/signature"
+	"github.com/sigstore/rekor/pkg/util"
 	"github.com/sigstore/sigstore/pkg/cryptoutils"
 	"github.com/sigstore/sigstore/pkg/signature"
 	"github.com/sigstore/sigstore/pkg/signature/payload"
@@ -67,6 +71,9 @@ type VerifyCommand struct {
 	Sk                           bool
 	Slot                         string
 	Output                       string
+	Recursive                    bool
+	Platform                     string
+	OutputDigest                 string
 	RekorURL                     string
 	Attachment                   string
 	Annotations                  sigs.AnnotationsMap
@@ -74,26 +81,75 @@ type VerifyCommand struct {
 	PayloadRef                   string
 	HashAlgorithm                crypto.Hash
 	LocalImage                   bool
+	ContinueOnError              bool
 	NameOptions                  []name.Option
 	Offline                      bool
 	TSACertChainPath             string
 	IgnoreTlog                   bool
+	RequireOnlineTlog            bool
+	RekorCheckpoint              string
+	RekorPublicKey               string
+	MaxSignatureAge              time.Duration
 	MaxWorkers                   int
+	ThresholdPolicy              string
+	TrustPolicy                  string
+	PIVAttestation               options.PIVAttestationOptions
+	CacheDir                     string
+	CacheTTL                     time.Duration
+	// Record and Against are the paths of the lockfiles the verified images
+	// are recorded in, and checked against.
+	Record  string
+	Against string
+	// RevocationList is the source of a revocation list signed by
+	// RevocationListKey, cached for RevocationListTTL.
+	RevocationList    string
+	RevocationListKey string
+	RevocationListTTL time.Duration
+
+	threshold   *thresholdVerifier
+	cache       *verificationCache
+	revocations *revocations
+	// trustPolicyDigest is the digest of the trust policy fetched for
+	// TrustPolicy.
+	trustPolicyDigest string
 }
 
 // Exec runs the verification command
 func (c *VerifyCommand) Exec(ctx context.Context, images []string) (err error) {
+	if (c.Record != "" || c.Against != "") && (c.LocalImage || c.Recursive || c.Platform != "") {
+		return errors.New("--record and --against cannot be used with --local-image, --recursive or --platform")
+	}
+	// Revocation lists revoke digests, which local images aren't verified by.
+	if c.RevocationList != "" && c.LocalImage {
+		return errors.New("--revocation-list cannot be used with --local-image")
+	}
+	var against *Lockfile
+	if c.Against != "" {
+		if against, err = loadLockfile(c.Against, false); err != nil {
+			return err
+		}
+		if len(images) == 0 {
+			images = against.images()
+		}
+	}
+
 	if len(images) == 0 {
 		return flag.ErrHelp
 	}
 
+	if err := checkOutput(c.Output); err != nil {
+		return err
+	}
+
 	switch c.Attachment {
 	case "sbom":
 		fmt.Fprintln(os.Stderr, options.SBOMAttachmentDeprecation)
 	case "":
 		break
 	default:
-		return flag.ErrHelp
+		if _, err := ociremote.AttachmentTagSuffix(c.Attachment); err != nil {
+			return err
+		}
 	}
 
 	// always default to sha256 if the algorithm hasn't been explicitly set
@@ -101,8 +157,18 @@ func (c *VerifyCommand) Exec(ctx context.Context, images []string) (err error) {
 		c.HashAlgorithm = crypto.SHA256
 	}
 
+	if c.ThresholdPolicy != "" && options.NOf(c.KeyRef, c.Sk, c.CertRef) > 0 {
+		return errors.New("--threshold-policy cannot be used with --key, --sk or --certificate")
+	}
+	if c.TrustPolicy != "" && options.NOf(c.KeyRef, c.Sk, c.CertRef, c.ThresholdPolicy) > 0 {
+		return errors.New("--trust-policy cannot be used with --key, --sk, --certificate or --threshold-policy")
+	}
+	if c.CARoots != "" && c.CertChain != "" {
+		return errors.New("--ca-roots cannot be used with --certificate-chain")
+	}
+
 	var identities []cosign.Identity
-	if c.KeyRef == "" {
+	if c.KeyRef == "" && c.ThresholdPolicy == "" && c.TrustPolicy == "" {
 		identities, err = c.Identities()
 		if err != nil {
 			return err
@@ -114,6 +180,37 @@ func (c *VerifyCommand) Exec(ctx context.Context, images []string) (err error) {
 		return fmt.Errorf("constructing client options: %w", err)
 	}
 
+	if c.OutputDigest != "" && c.LocalImage {
+		return errors.New("--output-digest cannot be used with --local-image")
+	}
+
+	if !c.LocalImage {
+		images, err = resolveDaemonImages(ctx, images, c.NameOptions)
+		if err != nil {
+			return err
+		}
+	}
+
+	var platforms []string
+	if c.Recursive {
+		if c.LocalImage {
+			return errors.New("--recursive cannot be used with --local-image")
+		}
+		images, platforms, err = expandImageIndexes(images, c.NameOptions, ociremoteOpts...)
+		if err != nil {
+			return err
+		}
+	}
+	if c.Platform != "" {
+		if c.Recursive || c.LocalImage {
+			return errors.New("--platform cannot be used with --recursive or --local-image")
+		}
+		images, platforms, err = resolvePlatformImages(images, c.Platform, c.NameOptions, ociremoteOpts...)
+		if err != nil {
+			return err
+		}
+	}
+
 	co := &cosign.CheckOpts{
 		Annotations:                  c.Annotations.Annotations,
 		RegistryClientOpts:           ociremoteOpts,
@@ -128,11 +225,16 @@ func (c *VerifyCommand) Exec(ctx context.Context, images []string) (err error) {
 		Identities:                   identities,
 		Offline:                      c.Offline,
 		IgnoreTlog:                   c.IgnoreTlog,
+		RequireOnlineTlog:            c.RequireOnlineTlog,
+		MaxSignatureAge:              c.MaxSignatureAge,
 		MaxWorkers:                   c.MaxWorkers,
 	}
 	if c.CheckClaims {
 		co.ClaimVerifier = cosign.SimpleClaimVerifier
 	}
+	if co.PIVAttestation, err = loadPIVAttestationPolicy(c.PIVAttestation); err != nil {
+		return err
+	}
 
 	if c.TSACertChainPath != "" {
 		_, err := os.Stat(c.TSACertChainPath)
@@ -159,6 +261,10 @@ func (c *VerifyCommand) Exec(ctx context.Context, images []string) (err error) {
 		co.TSARootCertificates = roots
 	}
 
+	if (c.RequireOnlineTlog || c.RekorCheckpoint != "") && (c.Offline || c.IgnoreTlog) {
+		return errors.New("--require-online-tlog and --rekor-checkpoint cannot be used with --offline or --insecure-ignore-tlog")
+	}
+
 	if !c.IgnoreTlog {
 		if c.RekorURL != "" {
 			rekorClient, err := rekor.NewClient(c.RekorURL)
@@ -169,12 +275,17 @@ func (c *VerifyCommand) Exec(ctx context.Context, images []string) (err error) {
 		}
 		// This performs an online fetch of the Rekor public keys, but this is needed
 		// for verifying tlog entries (both online and offline).
-		co.RekorPubKeys, err = cosign.GetRekorPubs(ctx)
+		co.RekorPubKeys, err = getRekorPubs(ctx, c.RekorPublicKey)
 		if err != nil {
 			return fmt.Errorf("getting Rekor public keys: %w", err)
 		}
+		if c.RekorCheckpoint != "" {
+			if co.RekorCheckpoint, err = loadRekorCheckpoint(c.RekorCheckpoint); err != nil {
+				return err
+			}
+		}
 	}
-	if keylessVerification(c.KeyRef, c.Sk) {
+	if keylessVerification(c.KeyRef, c.Sk) && c.TrustPolicy == "" {
 		if c.CertChain != "" {
 			chain, err := loadCertChainFromFileOrURL(c.CertChain)
 			if err != nil {
@@ -189,15 +300,9 @@ func (c *VerifyCommand) Exec(ctx context.Context, images []string) (err error) {
 				}
 			}
 		} else {
-			// This performs an online fetch of the Fulcio roots. This is needed
-			// for verifying keyless certificates (both online and offline).
-			co.RootCerts, err = fulcio.GetRoots()
-			if err != nil {
-				return fmt.Errorf("getting Fulcio roots: %w", err)
-			}
-			co.IntermediateCerts, err = fulcio.GetIntermediates()
+			co.RootCerts, co.IntermediateCerts, err = getRoots(c.CARoots, c.CAIntermediates)
 			if err != nil {
-				return fmt.Errorf("getting Fulcio intermediates: %w", err)
+				return err
 			}
 		}
 	}
@@ -240,14 +345,10 @@ func (c *VerifyCommand) Exec(ctx context.Context, images []string) (err error) {
 			return err
 		}
 		if c.CertChain == "" {
-			// If no certChain is passed, the Fulcio root certificate will be used
-			co.RootCerts, err = fulcio.GetRoots()
-			if err != nil {
-				return fmt.Errorf("getting Fulcio roots: %w", err)
-			}
-			co.IntermediateCerts, err = fulcio.GetIntermediates()
+			// If no certChain is passed, the --ca-roots or Fulcio root certificates will be used
+			co.RootCerts, co.IntermediateCerts, err = getRoots(c.CARoots, c.CAIntermediates)
 			if err != nil {
-				return fmt.Errorf("getting Fulcio intermediates: %w", err)
+				return err
 			}
 			pubKey, err = cosign.ValidateAndUnpackCert(cert, co)
 			if err != nil {
@@ -271,48 +372,287 @@ func (c *VerifyCommand) Exec(ctx context.Context, images []string) (err error) {
 			}
 			co.SCT = sct
 		}
+	case c.TrustPolicy != "":
+		pubKey, c.trustPolicyDigest, err = trustPolicyKeys(ctx, c.TrustPolicy, c.RegistryOptions, c.HashAlgorithm)
+		if err != nil {
+			return fmt.Errorf("loading trust policy: %w", err)
+		}
 	}
 	co.SigVerifier = pubKey
 
+	if c.ThresholdPolicy != "" {
+		thresholdPolicy, err := LoadThresholdPolicy(c.ThresholdPolicy)
+		if err != nil {
+			return err
+		}
+		if c.threshold, err = newThresholdVerifier(ctx, thresholdPolicy, c.HashAlgorithm); err != nil {
+			return err
+		}
+	}
+
+	if c.RevocationList != "" {
+		if c.revocations, err = loadRevocations(ctx, c.RevocationList, c.RevocationListKey, c.RevocationListTTL, revocationCacheDir(c.RevocationListTTL), c.RegistryOptions); err != nil {
+			return err
+		}
+		if co.SigVerifier, err = c.revocations.verifier(co.SigVerifier); err != nil {
+			return err
+		}
+		if err := c.revocations.threshold(c.threshold); err != nil {
+			return err
+		}
+	}
+
+	if c.CacheDir != "" && !c.LocalImage {
+		if c.cache, err = c.newCache(c.CacheDir, co); err != nil {
+			return err
+		}
+	}
+
 	// NB: There are only 2 kinds of verification right now:
 	// 1. You gave us the public key explicitly to verify against so co.SigVerifier is non-nil or,
 	// 2. We’re going to find an x509 certificate on the signature and verify against
 	//    Fulcio root trust (or user supplied root trust)
 	// TODO(nsmith5): Refactor this verification logic to pass back _how_ verification
 	// was performed so we don't need to use this fragile logic here.
-	fulcioVerified := (co.SigVerifier == nil)
-
-	for _, img := range images {
-		if c.LocalImage {
-			verified, bundleVerified, err := cosign.VerifyLocalImageSignatures(ctx, img, co)
+	fulcioVerified := (co.SigVerifier == nil && c.threshold == nil)
+
+	results := make([]*signatureVerification, len(images))
+	errs := verifyImagesConcurrently(ctx, images, c.MaxWorkers, c.ContinueOnError, func(ctx context.Context, i int, img string) error {
+		var err error
+		results[i], err = c.verifyImage(ctx, img, co)
+		if err == nil {
+			results[i].verified, err = c.revocations.filter(results[i].digest, results[i].verified)
+		}
+		if err == nil && against != nil {
+			err = against.checkDrift(img, results[i].digest)
+		}
+		return err
+	})
+
+	// Print the results in the order the images were given.
+	var reports []VerificationResult
+	for i, r := range results {
+		if isStructuredOutput(c.Output) && (r != nil || errs[i] != nil) {
+			report, err := r.result(images[i], errs[i])
 			if err != nil {
 				return err
 			}
-			PrintVerificationHeader(ctx, img, co, bundleVerified, fulcioVerified)
-			PrintVerification(ctx, verified, c.Output)
-		} else {
-			ref, err := name.ParseReference(img, c.NameOptions...)
-			if err != nil {
-				return fmt.Errorf("parsing reference: %w", err)
+			if platforms != nil {
+				report.Platform = platforms[i]
 			}
-			ref, err = sign.GetAttachedImageRef(ref, c.Attachment, ociremoteOpts...)
-			if err != nil {
-				return fmt.Errorf("resolving attachment type %s for image %s: %w", c.Attachment, img, err)
+			reports = append(reports, report)
+		}
+		if r == nil || errs[i] != nil {
+			continue
+		}
+		PrintVerificationHeader(ctx, withPlatform(r.ref, platforms, i), co, r.bundleVerified, fulcioVerified)
+		if c.threshold != nil {
+			ui.Infof(ctx, "  - The signatures of at least %d of the signers of the threshold policy were verified", c.threshold.threshold)
+			ui.Infof(ctx, "  - Signed by: %s", strings.Join(r.signers, ", "))
+		}
+		if c.TrustPolicy != "" {
+			ui.Infof(ctx, "  - The signatures were made by maintainer keys of the trust policy of %s", c.TrustPolicy)
+		}
+		if !isStructuredOutput(c.Output) {
+			PrintVerification(ctx, r.verified, c.Output)
+		}
+	}
+	if c.ContinueOnError {
+		printVerificationSummary(ctx, images, errs)
+	}
+
+	if c.OutputDigest != "" {
+		digests := make([]string, len(results))
+		for i, r := range results {
+			if r != nil && errs[i] == nil {
+				digests[i] = r.digest
 			}
+		}
+		if err := writeVerifiedDigests(c.OutputDigest, digests); err != nil {
+			return err
+		}
+	}
+
+	if c.Record != "" {
+		if err := c.record(images, results, errs); err != nil {
+			return err
+		}
+	}
 
-			verified, bundleVerified, err := cosign.VerifyImageSignatures(ctx, ref, co)
+	return finishWithResults(os.Stdout, c.Output, reports, joinImageErrors(images, errs))
+}
+
+// record records the images verified in the lockfile of c.Record.
+func (c *VerifyCommand) record(images []string, results []*signatureVerification, errs []error) error {
+	lock, err := loadLockfile(c.Record, true)
+	if err != nil {
+		return err
+	}
+	for i, r := range results {
+		if r == nil || errs[i] != nil {
+			continue
+		}
+		img, err := r.locked(images[i], c.KeyRef)
+		if err != nil {
+			return err
+		}
+		lock.record(img)
+	}
+	return lock.write(c.Record)
+}
+
+// signatureVerification is the outcome of verifying the signatures of an image.
+type signatureVerification struct {
+	ref      string
+	digest   string
+	verified []oci.Signature
+	// signers are the names of the signers of the threshold policy whose
+	// signatures were verified.
+	signers        []string
+	bundleVerified bool
+}
+
+// result returns the VerificationResult of verifying image, which is
+// reported as failed with verifyErr. v is nil when the verification failed
+// before any signature was checked.
+func (v *signatureVerification) result(image string, verifyErr error) (VerificationResult, error) {
+	result := newVerificationResult()
+	result.Image = image
+	if v != nil {
+		result.Digest = v.digest
+		result.Signers = v.signers
+		for _, sig := range v.verified {
+			sr, err := newSignatureResult(sig, true)
 			if err != nil {
-				return cosignError.WrapError(err)
+				return VerificationResult{}, err
 			}
+			result.Signatures = append(result.Signatures, sr)
+		}
+	}
+	result.finish(verifyErr)
+	return result, nil
+}
+
+// imageCheckOpts returns a copy of co for the verification of a single
+// image. Verification reassigns the certificate pools of the options, but
+// never adds to them, so a shallow copy keeps concurrent images apart.
+func imageCheckOpts(co *cosign.CheckOpts) *cosign.CheckOpts {
+	imageCo := *co
+	return &imageCo
+}
+
+func (c *VerifyCommand) verifyImage(ctx context.Context, img string, co *cosign.CheckOpts) (*signatureVerification, error) {
+	co = imageCheckOpts(co)
+
+	if c.LocalImage {
+		verified, signers, bundleVerified, err := c.threshold.verifySigners(co, func(co *cosign.CheckOpts) ([]oci.Signature, bool, error) {
+			return cosign.VerifyLocalImageSignatures(ctx, img, co)
+		})
+		if err != nil {
+			return nil, err
+		}
+		return &signatureVerification{ref: img, verified: verified, signers: signers, bundleVerified: bundleVerified}, nil
+	}
+
+	ref, err := name.ParseReference(img, c.NameOptions...)
+	if err != nil {
+		return nil, fmt.Errorf("parsing reference: %w", err)
+	}
+	// Verify the digest the reference points to now, so that the reported
+	// digest is the one whose signatures were checked even if the tag moves.
+	digest, err := ociremote.ResolveDigest(ref, co.RegistryClientOpts...)
+	if err != nil {
+		return nil, fmt.Errorf("resolving digest of %s: %w", img, err)
+	}
+	verifyRef, err := sign.GetAttachedImageRef(digest, c.Attachment, co.RegistryClientOpts...)
+	if err != nil {
+		return nil, fmt.Errorf("resolving attachment type %s for image %s: %w", c.Attachment, img, err)
+	}
+	if c.Attachment != "" {
+		ref = verifyRef
+	}
+	// The attachment itself isn't cached, so check it on cache hits too.
+	if c.Attachment == "wasm" {
+		if err := checkWasmAttachment(verifyRef, co); err != nil {
+			return nil, err
+		}
+	}
+
+	if c.cache != nil {
+		cached, ok, err := c.cache.get(digest.String())
+		if err != nil {
+			ui.Warnf(ctx, "Ignoring the verification cache entry of %s: %v", digest, err)
+		}
+		if ok {
+			ui.Debugf(ctx, "Using the cached verification of %s", digest)
+			cached.ref, cached.digest = ref.Name(), digest.String()
+			return cached, nil
+		}
+	}
 
-			PrintVerificationHeader(ctx, ref.Name(), co, bundleVerified, fulcioVerified)
-			PrintVerification(ctx, verified, c.Output)
+	verified, signers, bundleVerified, err := c.threshold.verifySigners(co, func(co *cosign.CheckOpts) ([]oci.Signature, bool, error) {
+		verified, bundleVerified, err := cosign.VerifyImageSignatures(ctx, verifyRef, co)
+		if err != nil || c.Attachment == "" {
+			return verified, bundleVerified, err
+		}
+		verified, err = attachedTo(ctx, verified, digest)
+		return verified, bundleVerified, err
+	})
+	if err != nil {
+		return nil, cosignError.WrapError(err)
+	}
+	v := &signatureVerification{ref: ref.Name(), digest: digest.String(), verified: verified, signers: signers, bundleVerified: bundleVerified}
+	if c.cache != nil {
+		if err := c.cache.put(digest.String(), v); err != nil {
+			ui.Warnf(ctx, "Caching the verification of %s: %v", digest, err)
 		}
 	}
+	return v, nil
+}
 
+// checkWasmAttachment fails when the attachment ref isn't a WASM module,
+// which runtimes loading it with package wasm would refuse.
+func checkWasmAttachment(ref name.Reference, co *cosign.CheckOpts) error {
+	img, err := ociremote.SignedImage(ref, co.RegistryClientOpts...)
+	if err != nil {
+		return fmt.Errorf("fetching wasm attachment %s: %w", ref, err)
+	}
+	if _, err := wasm.Layer(img); err != nil {
+		return fmt.Errorf("wasm attachment %s: %w", ref, err)
+	}
 	return nil
 }
 
+// attachedTo returns the signatures of an attachment that record it is
+// attached to digest, failing when none does. Signatures that don't record
+// the image, made before cosign did, are kept with a warning.
+func attachedTo(ctx context.Context, verified []oci.Signature, digest name.Digest) ([]oci.Signature, error) {
+	var kept []oci.Signature
+	for _, sig := range verified {
+		b, err := sig.Payload()
+		if err != nil {
+			return nil, err
+		}
+		var p payload.SimpleContainerImage
+		if err := json.Unmarshal(b, &p); err != nil {
+			return nil, fmt.Errorf("parsing signature payload: %w", err)
+		}
+		attached, ok := p.Optional[sign.AttachedToAnnotationKey]
+		if !ok {
+			ui.Warnf(ctx, "A signature of the attachment of %s doesn't record the image it is attached to, sign the attachment again to bind it to the image", digest)
+			kept = append(kept, sig)
+			continue
+		}
+		if attached == digest.String() {
+			kept = append(kept, sig)
+		}
+	}
+	if len(kept) == 0 {
+		return nil, fmt.Errorf("none of the signatures of the attachment records it is attached to %s", digest)
+	}
+	return kept, nil
+}
+
 func PrintVerificationHeader(ctx context.Context, imgRef string, co *cosign.CheckOpts, bundleVerified, fulcioVerified bool) {
 	ui.Infof(ctx, "\nVerification for %s --", imgRef)
 	ui.Infof(ctx, "The following checks were performed on each of these signatures:")
@@ -368,9 +708,14 @@ func PrintVerification(ctx context.Context, verified []oci.Signature, output str
 				}
 			}
 
+			if bundle, err := sig.Bundle(); err == nil && bundle != nil {
+				ui.Infof(ctx, "Transparency log index: %d, integrated at %s", bundle.Payload.LogIndex,
+					time.Unix(bundle.Payload.IntegratedTime, 0).UTC().Format(time.RFC3339))
+			}
+
 			p, err := sig.Payload()
 			if err != nil {
-				fmt.Fprintf(os.Stderr, "Error fetching payload: %v", err)
+				ui.Warnf(ctx, "Error fetching payload: %v", err)
 				return
 			}
 			fmt.Println(string(p))
@@ -381,7 +726,7 @@ func PrintVerification(ctx context.Context, verified []oci.Signature, output str
 		for _, sig := range verified {
 			p, err := sig.Payload()
 			if err != nil {
-				fmt.Fprintf(os.Stderr, "Error fetching payload: %v", err)
+				ui.Warnf(ctx, "Error fetching payload: %v", err)
 				return
 			}
 
@@ -489,6 +834,60 @@ func loadCertChainFromFileOrURL(path string) ([]*x509.Certificate, error) {
 	return certs, nil
 }
 
+// loadPIVAttestationPolicy returns the policy of o, or nil when it doesn't
+// require PIV attestations.
+func loadPIVAttestationPolicy(o options.PIVAttestationOptions) (*cosign.PIVAttestationPolicy, error) {
+	if o.Roots == "" {
+		if len(o.PINPolicies) > 0 || len(o.TouchPolicies) > 0 {
+			return nil, errors.New("--piv-pin-policy and --piv-touch-policy require --piv-attestation-roots")
+		}
+		return nil, nil
+	}
+	for _, p := range o.PINPolicies {
+		if p != "never" && p != "once" && p != "always" {
+			return nil, fmt.Errorf("invalid --piv-pin-policy %q, expected never, once or always", p)
+		}
+	}
+	for _, p := range o.TouchPolicies {
+		if p != "never" && p != "always" && p != "cached" {
+			return nil, fmt.Errorf("invalid --piv-touch-policy %q, expected never, always or cached", p)
+		}
+	}
+	roots, err := loadCertChainFromFileOrURL(o.Roots)
+	if err != nil {
+		return nil, fmt.Errorf("loading PIV attestation roots: %w", err)
+	}
+	if len(roots) == 0 {
+		return nil, fmt.Errorf("no PIV attestation roots found in %s", o.Roots)
+	}
+	pool := x509.NewCertPool()
+	for _, root := range roots {
+		pool.AddCert(root)
+	}
+	return &cosign.PIVAttestationPolicy{
+		Roots:         pool,
+		PINPolicies:   o.PINPolicies,
+		TouchPolicies: o.TouchPolicies,
+	}, nil
+}
+
+func loadRekorCheckpoint(path string) (*util.SignedCheckpoint, error) {
+	raw, err := os.ReadFile(filepath.Clean(path))
+	if err != nil {
+		return nil, fmt.Errorf("reading rekor checkpoint: %w", err)
+	}
+	return cosign.ParseCheckpoint(raw)
+}
+
+// getRekorPubs returns the Rekor public keys at keyRef, a file or URL, and
+// falls back to those of the TUF root.
+func getRekorPubs(ctx context.Context, keyRef string) (*cosign.TrustedTransparencyLogPubKeys, error) {
+	if keyRef != "" {
+		return cosign.LoadRekorPubs(keyRef)
+	}
+	return cosign.GetRekorPubs(ctx)
+}
+
 func keylessVerification(keyRef string, sk bool) bool {
 	if keyRef != "" {
 		return false
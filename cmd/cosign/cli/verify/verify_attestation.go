@@ -17,25 +17,29 @@ package verify
 
 import (
 	"context"
+	"crypto"
 	"errors"
 	"flag"
 	"fmt"
+	"net/url"
 	"os"
 	"path/filepath"
 	"strings"
+	"time"
 
 	"github.com/google/go-containerregistry/pkg/name"
-	"github.com/sigstore/cosign/v2/cmd/cosign/cli/fulcio"
+	v1 "github.com/google/go-containerregistry/pkg/v1"
 	"github.com/sigstore/cosign/v2/cmd/cosign/cli/options"
 	"github.com/sigstore/cosign/v2/cmd/cosign/cli/rekor"
 	"github.com/sigstore/cosign/v2/internal/pkg/cosign/tsa"
 	"github.com/sigstore/cosign/v2/internal/ui"
 	"github.com/sigstore/cosign/v2/pkg/cosign"
-	"github.com/sigstore/cosign/v2/pkg/cosign/cue"
+	"github.com/sigstore/cosign/v2/pkg/cosign/attestation"
 	"github.com/sigstore/cosign/v2/pkg/cosign/pivkey"
 	"github.com/sigstore/cosign/v2/pkg/cosign/pkcs11key"
 	"github.com/sigstore/cosign/v2/pkg/cosign/rego"
 	"github.com/sigstore/cosign/v2/pkg/oci"
+	ociremote "github.com/sigstore/cosign/v2/pkg/oci/remote"
 	"github.com/sigstore/cosign/v2/pkg/policy"
 	sigs "github.com/sigstore/cosign/v2/pkg/signature"
 )
@@ -46,6 +50,11 @@ type VerifyAttestationCommand struct {
 	options.RegistryOptions
 	options.CertVerifyOptions
 	CheckClaims                  bool
+	CheckPredicateExpiry         bool
+	MaxAttestationSize           int64
+	Recursive                    bool
+	Platform                     string
+	OutputDigest                 string
 	KeyRef                       string
 	CertRef                      string
 	CertGithubWorkflowTrigger    string
@@ -61,16 +70,47 @@ type VerifyAttestationCommand struct {
 	Output                       string
 	RekorURL                     string
 	PredicateType                string
+	PredicateTypes               []string
 	Policies                     []string
+	PolicyEngine                 string
+	RegoQuery                    string
+	PolicyData                   []string
+	PolicyKey                    string
+	AllowMissingAttestations     bool
+	Annotations                  sigs.AnnotationsMap
 	LocalImage                   bool
+	ContinueOnError              bool
+	BundlePath                   string
 	NameOptions                  []name.Option
 	Offline                      bool
 	TSACertChainPath             string
 	IgnoreTlog                   bool
+	RequireOnlineTlog            bool
+	RekorCheckpoint              string
+	RekorPublicKey               string
+	MaxSignatureAge              time.Duration
 	MaxWorkers                   int
+	ThresholdPolicy              string
+	TrustPolicy                  string
+	PIVAttestation               options.PIVAttestationOptions
+	Layout                       string
+	LayoutKeys                   []string
+	InspectionDir                string
+	SLSA                         policy.SLSARequirements
+	// RevocationList is the source of a revocation list signed by
+	// RevocationListKey, cached for RevocationListTTL.
+	RevocationList    string
+	RevocationListKey string
+	RevocationListTTL time.Duration
+
+	threshold   *thresholdVerifier
+	layout      *layoutVerifier
+	revocations *revocations
 }
 
-// Exec runs the verification command
+// Exec runs the verification command. PredicateTypes takes precedence over
+// PredicateType when set, allowing several predicate types, or
+// options.PredicateAll, to be verified at once.
 func (c *VerifyAttestationCommand) Exec(ctx context.Context, images []string) (err error) {
 	if len(images) == 0 {
 		return flag.ErrHelp
@@ -81,8 +121,43 @@ func (c *VerifyAttestationCommand) Exec(ctx context.Context, images []string) (e
 		return &options.KeyParseError{}
 	}
 
+	if err := checkOutput(c.Output, "sarif"); err != nil {
+		return err
+	}
+
+	if c.BundlePath != "" && (len(images) > 1 || c.LocalImage) {
+		return errors.New("--bundle can only be used to verify a single remote image")
+	}
+	if c.RevocationList != "" && c.LocalImage {
+		return errors.New("--revocation-list cannot be used with --local-image")
+	}
+
+	if c.ThresholdPolicy != "" && options.NOf(c.KeyRef, c.Sk, c.CertRef) > 0 {
+		return errors.New("--threshold-policy cannot be used with --key, --sk or --certificate")
+	}
+	if c.TrustPolicy != "" && options.NOf(c.KeyRef, c.Sk, c.CertRef, c.ThresholdPolicy) > 0 {
+		return errors.New("--trust-policy cannot be used with --key, --sk, --certificate or --threshold-policy")
+	}
+	if c.CARoots != "" && c.CertChain != "" {
+		return errors.New("--ca-roots cannot be used with --certificate-chain")
+	}
+	if err := c.SLSA.Validate(); err != nil {
+		return fmt.Errorf("invalid --slsa-min-level: %w", err)
+	}
+	if c.Layout != "" {
+		if options.NOf(c.KeyRef, c.Sk, c.CertRef, c.ThresholdPolicy, c.TrustPolicy) > 0 {
+			return errors.New("--layout cannot be used with --key, --sk, --certificate, --threshold-policy or --trust-policy")
+		}
+		if c.LocalImage || c.BundlePath != "" {
+			return errors.New("--layout cannot be used with --local-image or --bundle")
+		}
+		if len(c.LayoutKeys) == 0 {
+			return errors.New("--layout requires at least one --layout-key")
+		}
+	}
+
 	var identities []cosign.Identity
-	if c.KeyRef == "" {
+	if c.KeyRef == "" && c.ThresholdPolicy == "" && c.TrustPolicy == "" && c.Layout == "" {
 		identities, err = c.Identities()
 		if err != nil {
 			return err
@@ -94,6 +169,37 @@ func (c *VerifyAttestationCommand) Exec(ctx context.Context, images []string) (e
 		return fmt.Errorf("constructing client options: %w", err)
 	}
 
+	if c.OutputDigest != "" && c.LocalImage {
+		return errors.New("--output-digest cannot be used with --local-image")
+	}
+
+	if !c.LocalImage {
+		images, err = resolveDaemonImages(ctx, images, c.NameOptions)
+		if err != nil {
+			return err
+		}
+	}
+
+	var platforms []string
+	if c.Recursive {
+		if c.LocalImage || c.BundlePath != "" {
+			return errors.New("--recursive cannot be used with --local-image or --bundle")
+		}
+		images, platforms, err = expandImageIndexes(images, c.NameOptions, ociremoteOpts...)
+		if err != nil {
+			return err
+		}
+	}
+	if c.Platform != "" {
+		if c.Recursive || c.LocalImage || c.BundlePath != "" {
+			return errors.New("--platform cannot be used with --recursive or --local-image or --bundle")
+		}
+		images, platforms, err = resolvePlatformImages(images, c.Platform, c.NameOptions, ociremoteOpts...)
+		if err != nil {
+			return err
+		}
+	}
+
 	co := &cosign.CheckOpts{
 		RegistryClientOpts:           ociremoteOpts,
 		CertGithubWorkflowTrigger:    c.CertGithubWorkflowTrigger,
@@ -103,13 +209,21 @@ func (c *VerifyAttestationCommand) Exec(ctx context.Context, images []string) (e
 		CertGithubWorkflowRef:        c.CertGithubWorkflowRef,
 		IgnoreSCT:                    c.IgnoreSCT,
 		Identities:                   identities,
-		Offline:                      c.Offline,
+		Annotations:                  c.Annotations.Annotations,
+		Offline:                      c.Offline || c.BundlePath != "",
 		IgnoreTlog:                   c.IgnoreTlog,
+		RequireOnlineTlog:            c.RequireOnlineTlog,
+		MaxSignatureAge:              c.MaxSignatureAge,
+		CheckPredicateExpiry:         c.CheckPredicateExpiry,
+		MaxAttestationSize:           c.MaxAttestationSize,
 		MaxWorkers:                   c.MaxWorkers,
 	}
 	if c.CheckClaims {
 		co.ClaimVerifier = cosign.IntotoSubjectClaimVerifier
 	}
+	if co.PIVAttestation, err = loadPIVAttestationPolicy(c.PIVAttestation); err != nil {
+		return err
+	}
 	// Ignore Signed Certificate Timestamp if the flag is set or a key is provided
 	if !c.IgnoreSCT || c.KeyRef != "" {
 		co.CTLogPubKeys, err = cosign.GetCTLogPubs(ctx)
@@ -142,6 +256,10 @@ func (c *VerifyAttestationCommand) Exec(ctx context.Context, images []string) (e
 		co.TSAIntermediateCertificates = intermediates
 		co.TSARootCertificates = roots
 	}
+	if (c.RequireOnlineTlog || c.RekorCheckpoint != "") && (c.Offline || c.IgnoreTlog) {
+		return errors.New("--require-online-tlog and --rekor-checkpoint cannot be used with --offline or --insecure-ignore-tlog")
+	}
+
 	if !c.IgnoreTlog {
 		if c.RekorURL != "" {
 			rekorClient, err := rekor.NewClient(c.RekorURL)
@@ -152,21 +270,20 @@ func (c *VerifyAttestationCommand) Exec(ctx context.Context, images []string) (e
 		}
 		// This performs an online fetch of the Rekor public keys, but this is needed
 		// for verifying tlog entries (both online and offline).
-		co.RekorPubKeys, err = cosign.GetRekorPubs(ctx)
+		co.RekorPubKeys, err = getRekorPubs(ctx, c.RekorPublicKey)
 		if err != nil {
 			return fmt.Errorf("getting Rekor public keys: %w", err)
 		}
-	}
-	if keylessVerification(c.KeyRef, c.Sk) {
-		// This performs an online fetch of the Fulcio roots. This is needed
-		// for verifying keyless certificates (both online and offline).
-		co.RootCerts, err = fulcio.GetRoots()
-		if err != nil {
-			return fmt.Errorf("getting Fulcio roots: %w", err)
+		if c.RekorCheckpoint != "" {
+			if co.RekorCheckpoint, err = loadRekorCheckpoint(c.RekorCheckpoint); err != nil {
+				return err
+			}
 		}
-		co.IntermediateCerts, err = fulcio.GetIntermediates()
+	}
+	if keylessVerification(c.KeyRef, c.Sk) && c.TrustPolicy == "" && c.Layout == "" {
+		co.RootCerts, co.IntermediateCerts, err = getRoots(c.CARoots, c.CAIntermediates)
 		if err != nil {
-			return fmt.Errorf("getting Fulcio intermediates: %w", err)
+			return err
 		}
 	}
 	keyRef := c.KeyRef
@@ -198,14 +315,10 @@ func (c *VerifyAttestationCommand) Exec(ctx context.Context, images []string) (e
 			return fmt.Errorf("loading certificate from reference: %w", err)
 		}
 		if c.CertChain == "" {
-			// If no certChain is passed, the Fulcio root certificate will be used
-			co.RootCerts, err = fulcio.GetRoots()
+			// If no certChain is passed, the --ca-roots or Fulcio root certificates will be used
+			co.RootCerts, co.IntermediateCerts, err = getRoots(c.CARoots, c.CAIntermediates)
 			if err != nil {
-				return fmt.Errorf("getting Fulcio roots: %w", err)
-			}
-			co.IntermediateCerts, err = fulcio.GetIntermediates()
-			if err != nil {
-				return fmt.Errorf("getting Fulcio intermediates: %w", err)
+				return err
 			}
 			co.SigVerifier, err = cosign.ValidateAndUnpackCert(cert, co)
 			if err != nil {
@@ -229,6 +342,37 @@ func (c *VerifyAttestationCommand) Exec(ctx context.Context, images []string) (e
 			}
 			co.SCT = sct
 		}
+	case c.TrustPolicy != "":
+		co.SigVerifier, _, err = trustPolicyKeys(ctx, c.TrustPolicy, c.RegistryOptions, crypto.SHA256)
+		if err != nil {
+			return fmt.Errorf("loading trust policy: %w", err)
+		}
+	}
+
+	if c.ThresholdPolicy != "" {
+		thresholdPolicy, err := LoadThresholdPolicy(c.ThresholdPolicy)
+		if err != nil {
+			return err
+		}
+		if c.threshold, err = newThresholdVerifier(ctx, thresholdPolicy, crypto.SHA256); err != nil {
+			return err
+		}
+	}
+	if c.RevocationList != "" {
+		if c.revocations, err = loadRevocations(ctx, c.RevocationList, c.RevocationListKey, c.RevocationListTTL, revocationCacheDir(c.RevocationListTTL), c.RegistryOptions); err != nil {
+			return err
+		}
+		if co.SigVerifier, err = c.revocations.verifier(co.SigVerifier); err != nil {
+			return err
+		}
+		if err := c.revocations.threshold(c.threshold); err != nil {
+			return err
+		}
+	}
+	if c.Layout != "" {
+		if c.layout, err = newLayoutVerifier(c.Layout, c.LayoutKeys, c.InspectionDir); err != nil {
+			return err
+		}
 	}
 
 	// NB: There are only 2 kinds of verification right now:
@@ -236,97 +380,450 @@ func (c *VerifyAttestationCommand) Exec(ctx context.Context, images []string) (e
 	// 2. We're going to find an x509 certificate on the signature and verify against Fulcio root trust
 	// TODO(nsmith5): Refactor this verification logic to pass back _how_ verification
 	// was performed so we don't need to use this fragile logic here.
-	fulcioVerified := (co.SigVerifier == nil)
+	fulcioVerified := (co.SigVerifier == nil && c.threshold == nil && c.layout == nil)
+
+	predicateTypes := c.PredicateTypes
+	if len(predicateTypes) == 0 {
+		predicateTypes = []string{c.PredicateType}
+	}
+	if c.layout != nil {
+		// The layout only checks link attestations.
+		predicateTypes = []string{options.PredicateLink}
+	}
+	if c.SLSA.Enabled() && !hasSLSAProvenance(predicateTypes) {
+		return errors.New("--slsa-builder-id, --slsa-source-uri and --slsa-min-level require --type slsaprovenance, slsaprovenance1 or all")
+	}
+	bindings, err := parsePolicyBindings(c.Policies, c.PolicyEngine)
+	if err != nil {
+		return err
+	}
+	if hasPolicyBundles(bindings) {
+		dir, err := os.MkdirTemp("", "cosign-policy-")
+		if err != nil {
+			return err
+		}
+		defer os.RemoveAll(dir)
+		if bindings, err = c.fetchPolicyBundles(ctx, bindings, dir, co); err != nil {
+			return err
+		}
+	}
+	if err := checkPolicySchemas(bindings, predicateTypes); err != nil {
+		return err
+	}
 
-	for _, imageRef := range images {
-		var verified []oci.Signature
-		var bundleVerified bool
+	results := make([]*attestationVerification, len(images))
+	errs := verifyImagesConcurrently(ctx, images, c.MaxWorkers, c.ContinueOnError, func(ctx context.Context, i int, imageRef string) error {
+		var err error
+		results[i], err = c.verifyImage(ctx, imageRef, co, predicateTypes, bindings)
+		return err
+	})
 
-		if c.LocalImage {
-			verified, bundleVerified, err = cosign.VerifyLocalImageAttestations(ctx, imageRef, co)
-			if err != nil {
-				return err
+	// Report the results in the order the images were given.
+	var reports []VerificationResult
+	for i, imageRef := range images {
+		r := results[i]
+		if isStructuredOutput(c.Output) && (r != nil || errs[i] != nil) {
+			report := newVerificationResult()
+			if r != nil {
+				report = r.report
 			}
-		} else {
-			ref, err := name.ParseReference(imageRef, c.NameOptions...)
-			if err != nil {
-				return err
+			report.Image = imageRef
+			if platforms != nil {
+				report.Platform = platforms[i]
 			}
+			report.finish(errs[i])
+			reports = append(reports, report)
+		}
+		if r == nil || errs[i] != nil || len(r.checked) == 0 {
+			continue
+		}
+		PrintVerificationHeader(ctx, withPlatform(imageRef, platforms, i), co, r.bundleVerified, fulcioVerified)
+		if c.threshold != nil {
+			ui.Infof(ctx, "  - The signatures of at least %d of the signers of the threshold policy were verified", c.threshold.threshold)
+		}
+		if c.TrustPolicy != "" {
+			ui.Infof(ctx, "  - The signatures were made by maintainer keys of the trust policy of %s", c.TrustPolicy)
+		}
+		if c.layout != nil {
+			ui.Infof(ctx, "  - The link attestations were signed by the functionaries of each step of the layout %s and match its artifact rules", c.Layout)
+		}
+		if !isStructuredOutput(c.Output) {
+			// The attestations are always JSON, so use the raw "text" mode for outputting them instead of conversion
+			PrintVerification(ctx, r.checked, "text")
+		}
+	}
+	if c.ContinueOnError {
+		printVerificationSummary(ctx, images, errs)
+	}
 
-			verified, bundleVerified, err = cosign.VerifyImageAttestations(ctx, ref, co)
-			if err != nil {
-				return err
+	if c.OutputDigest != "" {
+		digests := make([]string, len(results))
+		for i, r := range results {
+			if r != nil && errs[i] == nil {
+				digests[i] = r.report.Digest
 			}
 		}
+		if err := writeVerifiedDigests(c.OutputDigest, digests); err != nil {
+			return err
+		}
+	}
 
-		var cuePolicies, regoPolicies []string
+	return finishWithResults(os.Stdout, c.Output, reports, joinImageErrors(images, errs))
+}
 
-		for _, policy := range c.Policies {
-			switch filepath.Ext(policy) {
-			case ".rego":
-				regoPolicies = append(regoPolicies, policy)
-			case ".cue":
-				cuePolicies = append(cuePolicies, policy)
-			default:
-				return errors.New("invalid policy format, expected .cue or .rego")
-			}
+// attestationVerification is the outcome of verifying the attestations of an image.
+type attestationVerification struct {
+	report         VerificationResult
+	checked        []oci.Signature
+	bundleVerified bool
+}
+
+// verifyImage verifies the attestations of imageRef and evaluates the policies
+// against those matching predicateTypes. The result is returned whenever the
+// policies were evaluated, even if the image fails verification.
+func (c *VerifyAttestationCommand) verifyImage(ctx context.Context, imageRef string, co *cosign.CheckOpts, predicateTypes []string, bindings []policyBinding) (*attestationVerification, error) {
+	co = imageCheckOpts(co)
+
+	var verified []oci.Signature
+	var bundleVerified bool
+	var digest string
+	var err error
+
+	switch {
+	case c.LocalImage:
+		verified, bundleVerified, err = c.threshold.verify(co, func(co *cosign.CheckOpts) ([]oci.Signature, bool, error) {
+			return cosign.VerifyLocalImageAttestations(ctx, imageRef, co)
+		})
+		if err != nil {
+			return nil, err
+		}
+	case c.BundlePath != "":
+		verified, bundleVerified, err = c.threshold.verify(co, func(co *cosign.CheckOpts) ([]oci.Signature, bool, error) {
+			return c.verifyBundle(ctx, imageRef, co)
+		})
+		if err != nil {
+			return nil, err
+		}
+		// verifyBundle only accepts references by digest.
+		d, err := name.NewDigest(imageRef, c.NameOptions...)
+		if err != nil {
+			return nil, err
+		}
+		digest = d.String()
+	default:
+		ref, err := name.ParseReference(imageRef, c.NameOptions...)
+		if err != nil {
+			return nil, err
+		}
+		// Verify the digest the reference points to now, so that the
+		// reported digest is the one whose attestations were checked.
+		d, err := ociremote.ResolveDigest(ref, co.RegistryClientOpts...)
+		if err != nil {
+			return nil, fmt.Errorf("resolving digest of %s: %w", imageRef, err)
+		}
+		digest = d.String()
+
+		if c.layout != nil {
+			verified, bundleVerified, err = c.layout.verify(ctx, d, co)
+		} else {
+			verified, bundleVerified, err = c.threshold.verify(co, func(co *cosign.CheckOpts) ([]oci.Signature, bool, error) {
+				return cosign.VerifyImageAttestations(ctx, d, co)
+			})
 		}
+		if err != nil {
+			return nil, err
+		}
+	}
+	if verified, err = c.revocations.filter(digest, verified); err != nil {
+		return nil, err
+	}
 
-		var checked []oci.Signature
-		var validationErrors []error
-		// To aid in determining if there's a mismatch in what predicateType
-		// we're looking for and what we checked, keep track of them here so
-		// that we can help the user figure out if there's a typo, etc.
-		checkedPredicateTypes := []string{}
-		for _, vp := range verified {
-			payload, gotPredicateType, err := policy.AttestationToPayloadJSON(ctx, c.PredicateType, vp)
+	result := &attestationVerification{
+		report: VerificationResult{
+			SchemaVersion:  VerificationResultSchemaVersion,
+			Image:          imageRef,
+			Digest:         digest,
+			PredicateTypes: predicateTypes,
+		},
+		bundleVerified: bundleVerified,
+	}
+	report := &result.report
+	var validationErrors []error
+	// To aid in determining if there's a mismatch in what predicateType
+	// we're looking for and what we checked, keep track of them here so
+	// that we can help the user figure out if there's a typo, etc.
+	checkedPredicateTypes := []string{}
+	// Number of attestations found for each of the requested predicate types.
+	matched := make(map[string]int, len(predicateTypes))
+	for _, vp := range verified {
+		envelope, err := vp.Payload()
+		if err != nil {
+			return nil, fmt.Errorf("getting payload: %w", err)
+		}
+		statement, err := attestation.DecodeEnvelopePayload(envelope)
+		if err != nil {
+			return nil, fmt.Errorf("converting to consumable policy validation: %w", err)
+		}
+		var payload []byte
+		var gotPredicateType string
+		for _, predicateType := range predicateTypes {
+			payload, gotPredicateType, err = policy.StatementToPayloadJSON(predicateType, statement)
 			if err != nil {
-				return fmt.Errorf("converting to consumable policy validation: %w", err)
+				return nil, fmt.Errorf("converting to consumable policy validation: %w", err)
 			}
-			checkedPredicateTypes = append(checkedPredicateTypes, gotPredicateType)
-			if len(payload) == 0 {
-				// This is not the predicate type we're looking for.
-				continue
+			if len(payload) > 0 {
+				matched[predicateType]++
+				break
 			}
+		}
+		checkedPredicateTypes = append(checkedPredicateTypes, gotPredicateType)
+		if len(payload) == 0 {
+			// This is not the predicate type we're looking for.
+			continue
+		}
 
-			if len(cuePolicies) > 0 {
-				ui.Infof(ctx, "will be validating against CUE policies: %v", cuePolicies)
-				cueValidationErr := cue.ValidateJSON(payload, cuePolicies)
-				if cueValidationErr != nil {
-					validationErrors = append(validationErrors, cueValidationErr)
-					continue
-				}
-			}
+		attResult, err := newAttestationResult(gotPredicateType, vp, statement)
+		if err != nil {
+			return nil, err
+		}
+		if payload, err = policyInput(payload, vp, attResult, imageRef, digest); err != nil {
+			return nil, err
+		}
 
-			if len(regoPolicies) > 0 {
-				ui.Infof(ctx, "will be validating against Rego policies: %v", regoPolicies)
-				regoValidationErrs := rego.ValidateJSON(payload, regoPolicies)
-				if len(regoValidationErrs) > 0 {
-					validationErrors = append(validationErrors, regoValidationErrs...)
-					continue
-				}
+		validationErrors = append(validationErrors, validatePolicies(ctx, payload, gotPredicateType, bindings, c.RegoQuery, c.PolicyData, &attResult)...)
+		if c.SLSA.Enabled() && policy.IsSLSAProvenance(gotPredicateType) {
+			pr := policy.EvaluateSLSA(payload, gotPredicateType, c.SLSA)
+			if err := pr.Err(); err != nil {
+				validationErrors = append(validationErrors, err)
 			}
+			attResult.addPolicyResult(pr)
+		}
 
-			checked = append(checked, vp)
+		report.Attestations = append(report.Attestations, attResult)
+		if attResult.Passed {
+			result.checked = append(result.checked, vp)
 		}
+	}
+	report.MissingPredicateTypes = missingPredicateTypes(predicateTypes, matched)
 
-		if len(validationErrors) > 0 {
-			ui.Infof(ctx, "There are %d number of errors occurred during the validation:\n", len(validationErrors))
-			for _, v := range validationErrors {
-				ui.Infof(ctx, "- %v", v)
-			}
-			return fmt.Errorf("%d validation errors occurred", len(validationErrors))
+	if len(validationErrors) > 0 {
+		ui.Infof(ctx, "There are %d number of errors occurred during the validation:\n", len(validationErrors))
+		for _, v := range validationErrors {
+			ui.Infof(ctx, "- %v", v)
 		}
+		return result, fmt.Errorf("%d validation errors occurred", len(validationErrors))
+	}
 
-		if len(checked) == 0 {
-			return fmt.Errorf("none of the attestations matched the predicate type: %s, found: %s", c.PredicateType, strings.Join(checkedPredicateTypes, ","))
+	if len(report.MissingPredicateTypes) > 0 {
+		err := fmt.Errorf("none of the attestations matched the predicate type: %s, found: %s", strings.Join(report.MissingPredicateTypes, ","), strings.Join(checkedPredicateTypes, ","))
+		if !c.AllowMissingAttestations {
+			return result, err
 		}
+		ui.Warnf(ctx, "%v", err)
+	}
+	return result, nil
+}
 
-		// TODO: add CUE validation report to `PrintVerificationHeader`.
-		PrintVerificationHeader(ctx, imageRef, co, bundleVerified, fulcioVerified)
-		// The attestations are always JSON, so use the raw "text" mode for outputting them instead of conversion
-		PrintVerification(ctx, checked, "text")
+// verifyBundle verifies the attestation stored in the local bundle against
+// imageRef, which must be a digest so the registry is never accessed.
+func (c *VerifyAttestationCommand) verifyBundle(ctx context.Context, imageRef string, co *cosign.CheckOpts) ([]oci.Signature, bool, error) {
+	digest, err := name.NewDigest(imageRef, c.NameOptions...)
+	if err != nil {
+		return nil, false, fmt.Errorf("--bundle requires an image reference by digest: %w", err)
+	}
+	h, err := v1.NewHash(digest.DigestStr())
+	if err != nil {
+		return nil, false, err
 	}
+	b, err := cosign.FetchLocalSignedPayloadFromPath(c.BundlePath)
+	if err != nil {
+		return nil, false, err
+	}
+	return cosign.VerifyImageAttestationBundle(ctx, b, h, co)
+}
 
+// fetchPolicyBundles fetches the policy bundles of bindings, verifying them
+// with --policy-key, and writes their policy files to dir. The registry and
+// transparency log settings of co are reused.
+func (c *VerifyAttestationCommand) fetchPolicyBundles(ctx context.Context, bindings []policyBinding, dir string, co *cosign.CheckOpts) ([]policyBinding, error) {
+	if c.PolicyKey == "" {
+		return nil, errors.New("--policy-key is required to verify oci:// policy bundles")
+	}
+	verifier, err := sigs.PublicKeyFromKeyRef(ctx, c.PolicyKey)
+	if err != nil {
+		return nil, fmt.Errorf("loading policy key: %w", err)
+	}
+	policyCO := &cosign.CheckOpts{
+		RegistryClientOpts: co.RegistryClientOpts,
+		SigVerifier:        verifier,
+		ClaimVerifier:      cosign.SimpleClaimVerifier,
+		RekorClient:        co.RekorClient,
+		RekorPubKeys:       co.RekorPubKeys,
+		CTLogPubKeys:       co.CTLogPubKeys,
+		IgnoreTlog:         co.IgnoreTlog,
+		Offline:            co.Offline,
+	}
+	return fetchPolicyBundles(ctx, bindings, dir, c.NameOptions, policyCO)
+}
+
+// missingPredicateTypes returns the requested predicate types for which no
+// attestation was found. options.PredicateAll is missing only when no
+// attestation matched at all.
+func missingPredicateTypes(predicateTypes []string, matched map[string]int) []string {
+	var missing []string
+	for _, t := range predicateTypes {
+		if matched[t] == 0 {
+			missing = append(missing, t)
+		}
+	}
+	return missing
+}
+
+// policyEngine returns the engine used to evaluate the policy at path. Unless
+// an engine was explicitly requested, it is detected from the file extension.
+func policyEngine(path, engine string) (string, error) {
+	switch engine {
+	case "", options.PolicyEngineAuto:
+		if rego.IsBundle(path) {
+			return options.PolicyEngineRego, nil
+		}
+		switch filepath.Ext(path) {
+		case ".rego":
+			return options.PolicyEngineRego, nil
+		case ".cue":
+			return options.PolicyEngineCUE, nil
+		default:
+			return "", errors.New("invalid policy format, expected .cue, .rego or an OPA bundle .tar.gz")
+		}
+	case options.PolicyEngineCUE, options.PolicyEngineRego:
+		return engine, nil
+	default:
+		return "", fmt.Errorf("invalid policy engine %q, expected auto, cue or rego", engine)
+	}
+}
+
+// hasSLSAProvenance tells whether predicateTypes select SLSA provenance
+// attestations.
+func hasSLSAProvenance(predicateTypes []string) bool {
+	for _, t := range predicateTypes {
+		if t == options.PredicateAll {
+			return true
+		}
+		if uri, err := options.ParsePredicateType(t); err == nil && policy.IsSLSAProvenance(uri) {
+			return true
+		}
+	}
+	return false
+}
+
+// policyBinding is a policy file evaluated with the given engine. When
+// predicateType is set, the policy only applies to attestations of that
+// predicate type.
+type policyBinding struct {
+	predicateType string
+	path          string
+	engine        string
+}
+
+// matches reports whether the policy applies to an attestation whose
+// statement has the given predicateType. A policy bound to a registered
+// predicate type matches it like the type does, e.g. every versioned URI of
+// a CycloneDX BOM.
+func (b policyBinding) matches(predicateType string) bool {
+	if b.predicateType == "" {
+		return true
+	}
+	pt, ok := attestation.LookupPredicateTypeURI(b.predicateType)
+	if !ok || pt.URI != b.predicateType {
+		// Bound to a URI of its own, use it as is.
+		pt = attestation.PredicateType{URI: b.predicateType}
+	}
+	return pt.Matches(predicateType)
+}
+
+// parsePolicyBindings parses the --policy values, which are either a path to
+// a policy file or a <predicate type>=<path> binding, e.g. slsaprovenance=provenance.cue.
+func parsePolicyBindings(policies []string, engine string) ([]policyBinding, error) {
+	bindings := make([]policyBinding, 0, len(policies))
+	for _, p := range policies {
+		b := policyBinding{path: p}
+		if t, path, ok := strings.Cut(p, "="); ok && isPredicateType(t) {
+			predicateURI, err := options.ParsePredicateType(t)
+			if err != nil {
+				return nil, err
+			}
+			b.predicateType = predicateURI
+			b.path = path
+		}
+		if isPolicyBundle(b.path) {
+			// The engine of each file is detected once the bundle is fetched.
+			bindings = append(bindings, b)
+			continue
+		}
+		e, err := policyEngine(b.path, engine)
+		if err != nil {
+			return nil, err
+		}
+		b.engine = e
+		bindings = append(bindings, b)
+	}
+	return bindings, nil
+}
+
+// checkPolicySchemas checks the CUE policies against the schema of the
+// predicate type they are bound to, or of every requested predicate type for
+// unbound policies, before any attestation is fetched.
+func checkPolicySchemas(bindings []policyBinding, predicateTypes []string) error {
+	for _, b := range bindings {
+		if b.engine != options.PolicyEngineCUE {
+			continue
+		}
+		types := predicateTypes
+		if b.predicateType != "" {
+			types = []string{b.predicateType}
+		}
+		for _, t := range types {
+			if err := policy.CheckCUESchema(b.path, t); err != nil {
+				return err
+			}
+		}
+	}
 	return nil
 }
+
+// isPredicateType reports whether t is a known predicate type or a URI with a
+// scheme, as opposed to the beginning of a path containing '='.
+func isPredicateType(t string) bool {
+	if _, ok := options.LookupPredicateType(t); ok {
+		return true
+	}
+	u, err := url.ParseRequestURI(t)
+	return err == nil && u.Scheme != "" && u.Host != ""
+}
+
+// validatePolicies evaluates every policy bound to predicateType against the
+// attestation payload, recording the outcome of each one in result. Rego
+// policies are evaluated with regoQuery, or rego.QUERY when it is empty, and
+// the policyData documents.
+func validatePolicies(ctx context.Context, payload []byte, predicateType string, bindings []policyBinding, regoQuery string, policyData []string, result *AttestationResult) []error {
+	var validationErrors []error
+	for _, b := range bindings {
+		if !b.matches(predicateType) {
+			continue
+		}
+		var pr policy.PolicyResult
+		switch b.engine {
+		case options.PolicyEngineCUE:
+			ui.Infof(ctx, "will be validating %s against CUE policy: %s", predicateType, b.path)
+			pr = policy.EvaluateCUE(payload, b.path)
+		case options.PolicyEngineRego:
+			ui.Infof(ctx, "will be validating %s against Rego policy: %s", predicateType, b.path)
+			pr = policy.EvaluateRego(payload, b.path, regoQuery, policyData)
+		}
+		if err := pr.Err(); err != nil {
+			validationErrors = append(validationErrors, err)
+		}
+		result.addPolicyResult(pr)
+	}
+	return validationErrors
+}
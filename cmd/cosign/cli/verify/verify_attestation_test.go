@@ -16,9 +16,14 @@ package verify
 
 import (
 	"context"
+	"os"
+	"strings"
 	"testing"
 
+	slsa02 "github.com/in-toto/in-toto-golang/in_toto/slsa_provenance/v0.2"
+
 	"github.com/sigstore/cosign/v2/cmd/cosign/cli/options"
+	"github.com/sigstore/cosign/v2/pkg/cosign/attestation"
 )
 
 func TestVerifyAttestationMissingSubject(t *testing.T) {
@@ -52,3 +57,315 @@ func TestVerifyAttestationMissingIssuer(t *testing.T) {
 		t.Fatal("verifyAttestation expected 'need --certificate-oidc-issuer'")
 	}
 }
+
+func TestPolicyEngine(t *testing.T) {
+	tests := []struct {
+		name    string
+		path    string
+		engine  string
+		want    string
+		wantErr bool
+	}{
+		{name: "auto cue", path: "policy.cue", engine: options.PolicyEngineAuto, want: options.PolicyEngineCUE},
+		{name: "auto rego", path: "policy.rego", engine: options.PolicyEngineAuto, want: options.PolicyEngineRego},
+		{name: "empty engine defaults to auto", path: "policy.rego", want: options.PolicyEngineRego},
+		{name: "auto opa bundle", path: "bundle.tar.gz", engine: options.PolicyEngineAuto, want: options.PolicyEngineRego},
+		{name: "auto unknown extension", path: "policy.json", engine: options.PolicyEngineAuto, wantErr: true},
+		{name: "explicit rego", path: "policy.txt", engine: options.PolicyEngineRego, want: options.PolicyEngineRego},
+		{name: "explicit cue overrides extension", path: "policy.rego", engine: options.PolicyEngineCUE, want: options.PolicyEngineCUE},
+		{name: "unknown engine", path: "policy.cue", engine: "kyverno", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := policyEngine(tt.path, tt.engine)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("policyEngine() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("policyEngine() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidatePoliciesSLSA(t *testing.T) {
+	payload, err := os.ReadFile("testdata/slsa-statement.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name         string
+		cuePolicies  []string
+		regoPolicies []string
+		regoQuery    string
+		wantErrs     bool
+	}{
+		{
+			name:         "both engines pass",
+			cuePolicies:  []string{"testdata/slsa-builder.cue"},
+			regoPolicies: []string{"testdata/slsa-builder.rego"},
+		},
+		{
+			name:        "cue fails",
+			cuePolicies: []string{"testdata/slsa-buildtype.cue"},
+			wantErrs:    true,
+		},
+		{
+			name:         "rego fails",
+			regoPolicies: []string{"testdata/slsa-buildtype.rego"},
+			wantErrs:     true,
+		},
+		{
+			name:         "rego custom query",
+			regoPolicies: []string{"testdata/slsa-builder-custom-query.rego"},
+			regoQuery:    "data.cosign.attestation.allow",
+		},
+		{
+			name:         "rego custom query undefined",
+			regoPolicies: []string{"testdata/slsa-builder.rego"},
+			regoQuery:    "data.cosign.attestation.allow",
+			wantErrs:     true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := AttestationResult{Passed: true}
+			var policies []string
+			policies = append(policies, tt.cuePolicies...)
+			policies = append(policies, tt.regoPolicies...)
+			bindings, err := parsePolicyBindings(policies, options.PolicyEngineAuto)
+			if err != nil {
+				t.Fatal(err)
+			}
+			errs := validatePolicies(context.Background(), payload, slsa02.PredicateSLSAProvenance, bindings, tt.regoQuery, nil, &result)
+			if (len(errs) > 0) != tt.wantErrs {
+				t.Fatalf("validatePolicies() errors = %v, wantErrs %v", errs, tt.wantErrs)
+			}
+			if result.Passed == tt.wantErrs {
+				t.Errorf("result.Passed = %v, want %v", result.Passed, !tt.wantErrs)
+			}
+			if got, want := len(result.Policies), len(tt.cuePolicies)+len(tt.regoPolicies); got != want {
+				t.Errorf("got %d policy reports, want %d", got, want)
+			}
+		})
+	}
+}
+
+func TestValidatePoliciesVuln(t *testing.T) {
+	tests := []struct {
+		name      string
+		statement string
+		wantErrs  bool
+	}{
+		{
+			name:      "no critical vulnerabilities",
+			statement: "testdata/vuln-statement.json",
+		},
+		{
+			name:      "critical vulnerability",
+			statement: "testdata/vuln-statement-critical.json",
+			wantErrs:  true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			payload, err := os.ReadFile(tt.statement)
+			if err != nil {
+				t.Fatal(err)
+			}
+			bindings, err := parsePolicyBindings([]string{"testdata/vuln-no-critical.cue", "testdata/vuln-no-critical.rego"}, options.PolicyEngineAuto)
+			if err != nil {
+				t.Fatal(err)
+			}
+			result := AttestationResult{Passed: true}
+			errs := validatePolicies(context.Background(), payload, attestation.CosignVulnProvenanceV01, bindings, "", nil, &result)
+			if (len(errs) > 0) != tt.wantErrs {
+				t.Fatalf("validatePolicies() errors = %v, wantErrs %v", errs, tt.wantErrs)
+			}
+			if !tt.wantErrs {
+				return
+			}
+			// Both engines must reject the payload, and the Rego deny rule
+			// names the offending CVE.
+			if len(errs) != 2 {
+				t.Errorf("got %d errors, want 2: %v", len(errs), errs)
+			}
+			if !strings.Contains(errs[len(errs)-1].Error(), "CVE-2022-37434") {
+				t.Errorf("expected the critical CVE in %v", errs[len(errs)-1])
+			}
+		})
+	}
+}
+
+func TestParsePolicyBindings(t *testing.T) {
+	bindings, err := parsePolicyBindings([]string{
+		"policy.cue",
+		"slsaprovenance=provenance.cue",
+		"https://spdx.dev/Document=sbom.rego",
+		"dir/with=sign.rego",
+		"slsaprovenance=oci://registry.example.com/policies:v1",
+	}, options.PolicyEngineAuto)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []policyBinding{
+		{path: "policy.cue", engine: options.PolicyEngineCUE},
+		{predicateType: slsa02.PredicateSLSAProvenance, path: "provenance.cue", engine: options.PolicyEngineCUE},
+		{predicateType: "https://spdx.dev/Document", path: "sbom.rego", engine: options.PolicyEngineRego},
+		{path: "dir/with=sign.rego", engine: options.PolicyEngineRego},
+		{predicateType: slsa02.PredicateSLSAProvenance, path: "oci://registry.example.com/policies:v1"},
+	}
+	if len(bindings) != len(want) {
+		t.Fatalf("got %d bindings, want %d", len(bindings), len(want))
+	}
+	for i := range want {
+		if bindings[i] != want[i] {
+			t.Errorf("binding %d = %+v, want %+v", i, bindings[i], want[i])
+		}
+	}
+
+	if _, err := parsePolicyBindings([]string{"spdx=sbom.json"}, options.PolicyEngineAuto); err == nil {
+		t.Error("expected an error for a binding with an unknown policy extension")
+	}
+}
+
+func TestValidatePoliciesBoundToPredicateType(t *testing.T) {
+	payload, err := os.ReadFile("testdata/slsa-statement.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The failing policy is bound to SPDX, so it must not apply to the SLSA statement.
+	bindings, err := parsePolicyBindings([]string{
+		"slsaprovenance=testdata/slsa-builder.cue",
+		"spdx=testdata/slsa-buildtype.rego",
+	}, options.PolicyEngineAuto)
+	if err != nil {
+		t.Fatal(err)
+	}
+	result := AttestationResult{Passed: true}
+	if errs := validatePolicies(context.Background(), payload, slsa02.PredicateSLSAProvenance, bindings, "", nil, &result); len(errs) > 0 {
+		t.Fatalf("validatePolicies() = %v", errs)
+	}
+	if len(result.Policies) != 1 {
+		t.Errorf("expected 1 policy to be evaluated, got %d", len(result.Policies))
+	}
+}
+
+func TestValidatePoliciesBoundToCycloneDX(t *testing.T) {
+	payload, err := os.ReadFile("testdata/cyclonedx-statement.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The statement is of the versioned type https://cyclonedx.org/bom/v1.4,
+	// which the policy bound to cyclonedx must still be evaluated against.
+	bindings, err := parsePolicyBindings([]string{"cyclonedx=testdata/cyclonedx-component.rego"}, options.PolicyEngineAuto)
+	if err != nil {
+		t.Fatal(err)
+	}
+	result := AttestationResult{Passed: true}
+	errs := validatePolicies(context.Background(), payload, "https://cyclonedx.org/bom/v1.4", bindings, "", nil, &result)
+	if len(errs) != 1 {
+		t.Fatalf("validatePolicies() = %v, want the error of the bound policy", errs)
+	}
+	if result.Passed || len(result.Policies) != 1 {
+		t.Errorf("result = %+v, want 1 failed policy", result)
+	}
+}
+
+func TestPolicyBindingMatches(t *testing.T) {
+	tests := []struct {
+		binding       string
+		predicateType string
+		want          bool
+	}{
+		{"cyclonedx", "https://cyclonedx.org/bom", true},
+		{"cyclonedx", "https://cyclonedx.org/bom/v1.5", true},
+		{"https://cyclonedx.org/bom/v1.4", "https://cyclonedx.org/bom/v1.4", true},
+		{"https://cyclonedx.org/bom/v1.4", "https://cyclonedx.org/bom/v1.5", false},
+		{"slsaprovenance", slsa02.PredicateSLSAProvenance, true},
+		{"slsaprovenance", "https://cyclonedx.org/bom/v1.4", false},
+		{"https://example.com/custom", "https://example.com/custom", true},
+		{"https://example.com/custom", "https://example.com/custom/v2", false},
+	}
+	for _, tt := range tests {
+		uri, err := options.ParsePredicateType(tt.binding)
+		if err != nil {
+			t.Fatal(err)
+		}
+		b := policyBinding{predicateType: uri}
+		if got := b.matches(tt.predicateType); got != tt.want {
+			t.Errorf("binding %s matches(%s) = %v, want %v", tt.binding, tt.predicateType, got, tt.want)
+		}
+	}
+	if !(policyBinding{}).matches("https://example.com/any") {
+		t.Error("an unbound policy must match every predicate type")
+	}
+}
+
+func TestCheckPolicySchemas(t *testing.T) {
+	tests := []struct {
+		name     string
+		policies []string
+		types    []string
+		wantErr  bool
+	}{
+		{name: "matching policy", policies: []string{"testdata/slsa-builder.cue"}, types: []string{"slsaprovenance"}},
+		{name: "typo in field path", policies: []string{"testdata/slsa-builder-typo.cue"}, types: []string{"slsaprovenance"}, wantErr: true},
+		{name: "typo in bound policy", policies: []string{"slsaprovenance=testdata/slsa-builder-typo.cue"}, types: []string{"vuln"}, wantErr: true},
+		{name: "no schema for predicate type", policies: []string{"testdata/vuln-no-critical.cue"}, types: []string{"vuln"}},
+		{name: "rego is not checked", policies: []string{"testdata/slsa-builder.rego"}, types: []string{"slsaprovenance"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			bindings, err := parsePolicyBindings(tt.policies, options.PolicyEngineAuto)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := checkPolicySchemas(bindings, tt.types); (err != nil) != tt.wantErr {
+				t.Errorf("checkPolicySchemas() = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestMissingPredicateTypes(t *testing.T) {
+	tests := []struct {
+		name    string
+		types   []string
+		matched map[string]int
+		want    []string
+	}{
+		{name: "all matched", types: []string{"slsaprovenance", "spdx"}, matched: map[string]int{"slsaprovenance": 1, "spdx": 2}},
+		{name: "one missing", types: []string{"slsaprovenance", "spdx"}, matched: map[string]int{"slsaprovenance": 1}, want: []string{"spdx"}},
+		{name: "nothing matched", types: []string{"custom"}, matched: map[string]int{}, want: []string{"custom"}},
+		{name: "all with matches", types: []string{options.PredicateAll}, matched: map[string]int{options.PredicateAll: 3}},
+		{name: "all without matches", types: []string{options.PredicateAll}, matched: map[string]int{}, want: []string{options.PredicateAll}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := missingPredicateTypes(tt.types, tt.matched)
+			if strings.Join(got, ",") != strings.Join(tt.want, ",") {
+				t.Errorf("missingPredicateTypes() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestHasSLSAProvenance(t *testing.T) {
+	tests := []struct {
+		predicateTypes []string
+		want           bool
+	}{
+		{predicateTypes: []string{"custom"}},
+		{predicateTypes: []string{"spdxjson", "slsaprovenance"}, want: true},
+		{predicateTypes: []string{"slsaprovenance1"}, want: true},
+		{predicateTypes: []string{"https://slsa.dev/provenance/v1"}, want: true},
+		{predicateTypes: []string{"all"}, want: true},
+	}
+	for _, tc := range tests {
+		if got := hasSLSAProvenance(tc.predicateTypes); got != tc.want {
+			t.Errorf("hasSLSAProvenance(%v) = %t, wanted %t", tc.predicateTypes, got, tc.want)
+		}
+	}
+}
@@ -18,16 +18,18 @@ package verify
 import (
 	"context"
 	"crypto"
+	"crypto/sha256"
 	"crypto/x509"
 	"encoding/base64"
+	"encoding/hex"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
 	"os"
 	"path/filepath"
+	"time"
 
-	"github.com/sigstore/cosign/v2/cmd/cosign/cli/fulcio"
 	"github.com/sigstore/cosign/v2/cmd/cosign/cli/options"
 	"github.com/sigstore/cosign/v2/cmd/cosign/cli/rekor"
 	"github.com/sigstore/cosign/v2/internal/pkg/cosign/tsa"
@@ -64,10 +66,28 @@ type VerifyBlobCmd struct {
 	SCTRef                       string
 	Offline                      bool
 	IgnoreTlog                   bool
+	RequireOnlineTlog            bool
+	RekorCheckpoint              string
+	RekorPublicKey               string
+	MaxSignatureAge              time.Duration
+	Output                       string
 }
 
-// nolint
+// Exec verifies the signature of the blob at blobRef. With --output json or
+// yaml, the VerificationResult is written to stdout.
 func (c *VerifyBlobCmd) Exec(ctx context.Context, blobRef string) error {
+	if err := checkOutput(c.Output); err != nil {
+		return err
+	}
+	result := newVerificationResult()
+	result.Blob = blobRef
+	err := c.verify(ctx, blobRef, &result)
+	result.finish(err)
+	return finishWithResults(os.Stdout, c.Output, []VerificationResult{result}, err)
+}
+
+// nolint
+func (c *VerifyBlobCmd) verify(ctx context.Context, blobRef string, result *VerificationResult) error {
 	var cert *x509.Certificate
 	opts := make([]static.Option, 0)
 
@@ -81,6 +101,10 @@ func (c *VerifyBlobCmd) Exec(ctx context.Context, blobRef string) error {
 		return &options.PubKeyParseError{}
 	}
 
+	if c.CARoots != "" && c.CertChain != "" {
+		return errors.New("--ca-roots cannot be used with --certificate-chain")
+	}
+
 	var identities []cosign.Identity
 	var err error
 	if c.KeyRef == "" {
@@ -110,6 +134,8 @@ func (c *VerifyBlobCmd) Exec(ctx context.Context, blobRef string) error {
 		Identities:                   identities,
 		Offline:                      c.Offline,
 		IgnoreTlog:                   c.IgnoreTlog,
+		RequireOnlineTlog:            c.RequireOnlineTlog,
+		MaxSignatureAge:              c.MaxSignatureAge,
 	}
 	if c.RFC3161TimestampPath != "" && c.KeyOpts.TSACertChainPath == "" {
 		return fmt.Errorf("timestamp-certificate-chain is required to validate a RFC3161 timestamp")
@@ -139,6 +165,10 @@ func (c *VerifyBlobCmd) Exec(ctx context.Context, blobRef string) error {
 		co.TSARootCertificates = roots
 	}
 
+	if (c.RequireOnlineTlog || c.RekorCheckpoint != "") && (c.Offline || c.IgnoreTlog) {
+		return errors.New("--require-online-tlog and --rekor-checkpoint cannot be used with --offline or --insecure-ignore-tlog")
+	}
+
 	if !c.IgnoreTlog {
 		if c.RekorURL != "" {
 			rekorClient, err := rekor.NewClient(c.RekorURL)
@@ -149,23 +179,22 @@ func (c *VerifyBlobCmd) Exec(ctx context.Context, blobRef string) error {
 		}
 		// This performs an online fetch of the Rekor public keys, but this is needed
 		// for verifying tlog entries (both online and offline).
-		co.RekorPubKeys, err = cosign.GetRekorPubs(ctx)
+		co.RekorPubKeys, err = getRekorPubs(ctx, c.RekorPublicKey)
 		if err != nil {
 			return fmt.Errorf("getting Rekor public keys: %w", err)
 		}
+		if c.RekorCheckpoint != "" {
+			if co.RekorCheckpoint, err = loadRekorCheckpoint(c.RekorCheckpoint); err != nil {
+				return err
+			}
+		}
 	}
 	if keylessVerification(c.KeyRef, c.Sk) {
-		// Use default TUF roots if a cert chain is not provided.
-		// This performs an online fetch of the Fulcio roots. This is needed
-		// for verifying keyless certificates (both online and offline).
+		// Use the --ca-roots or default TUF roots if a cert chain is not provided.
 		if c.CertChain == "" {
-			co.RootCerts, err = fulcio.GetRoots()
-			if err != nil {
-				return fmt.Errorf("getting Fulcio roots: %w", err)
-			}
-			co.IntermediateCerts, err = fulcio.GetIntermediates()
+			co.RootCerts, co.IntermediateCerts, err = getRoots(c.CARoots, c.CAIntermediates)
 			if err != nil {
-				return fmt.Errorf("getting Fulcio intermediates: %w", err)
+				return err
 			}
 		}
 	}
@@ -197,11 +226,17 @@ func (c *VerifyBlobCmd) Exec(ctx context.Context, blobRef string) error {
 			return err
 		}
 	}
+	var bundleChain []*x509.Certificate
 	if c.BundlePath != "" {
 		b, err := cosign.FetchLocalSignedPayloadFromPath(c.BundlePath)
 		if err != nil {
 			return err
 		}
+		if b.PayloadDigest != "" {
+			if digest := fmt.Sprintf("sha256:%x", sha256.Sum256(blobBytes)); b.PayloadDigest != digest {
+				return fmt.Errorf("blob digest %s does not match the digest %s in the bundle", digest, b.PayloadDigest)
+			}
+		}
 		// A certificate is required in the bundle unless we specified with
 		//  --key, --sk, or --certificate.
 		if b.Cert == "" && co.SigVerifier == nil && cert == nil {
@@ -229,6 +264,22 @@ func (c *VerifyBlobCmd) Exec(ctx context.Context, blobRef string) error {
 			}
 			cert = bundleCert
 		}
+		// The chain of the bundle only provides the intermediates: the root must
+		// still be one of the trusted roots.
+		if b.CertChain != "" && c.CertChain == "" {
+			chainBytes := []byte(b.CertChain)
+			if isb64(chainBytes) {
+				chainBytes, _ = base64.StdEncoding.DecodeString(b.CertChain)
+			}
+			bundleChain, err = cryptoutils.UnmarshalCertificatesFromPEM(chainBytes)
+			if err != nil {
+				return fmt.Errorf("loading certificate chain from bundle: %w", err)
+			}
+		}
+		// An RFC3161 timestamp can only be verified with the TSA certificate chain.
+		if b.RFC3161Timestamp != nil && c.RFC3161TimestampPath == "" && c.KeyOpts.TSACertChainPath != "" {
+			opts = append(opts, static.WithRFC3161Timestamp(b.RFC3161Timestamp))
+		}
 		opts = append(opts, static.WithBundle(b.Bundle))
 	}
 	if c.RFC3161TimestampPath != "" {
@@ -272,6 +323,11 @@ func (c *VerifyBlobCmd) Exec(ctx context.Context, blobRef string) error {
 		if err != nil {
 			return err
 		}
+	} else if len(bundleChain) > 0 {
+		chainPEM, err = cryptoutils.MarshalCertificatesToPEM(bundleChain)
+		if err != nil {
+			return err
+		}
 	}
 
 	// Gather the cert for the signature and add the cert along with the
@@ -301,6 +357,14 @@ func (c *VerifyBlobCmd) Exec(ctx context.Context, blobRef string) error {
 		return err
 	}
 
+	digest := sha256.Sum256(blobBytes)
+	result.Digest = "sha256:" + hex.EncodeToString(digest[:])
+	sr, err := newSignatureResult(signature, false)
+	if err != nil {
+		return err
+	}
+	result.Signatures = []SignatureResult{sr}
+
 	ui.Infof(ctx, "Verified OK")
 	return nil
 }
@@ -28,15 +28,17 @@ import (
 	"io"
 	"os"
 	"path/filepath"
+	"time"
 
 	v1 "github.com/google/go-containerregistry/pkg/v1"
-	"github.com/sigstore/cosign/v2/cmd/cosign/cli/fulcio"
 	"github.com/sigstore/cosign/v2/cmd/cosign/cli/options"
 	"github.com/sigstore/cosign/v2/cmd/cosign/cli/rekor"
 	internal "github.com/sigstore/cosign/v2/internal/pkg/cosign"
 	"github.com/sigstore/cosign/v2/internal/pkg/cosign/tsa"
+	"github.com/sigstore/cosign/v2/internal/ui"
 	"github.com/sigstore/cosign/v2/pkg/blob"
 	"github.com/sigstore/cosign/v2/pkg/cosign"
+	"github.com/sigstore/cosign/v2/pkg/cosign/attestation"
 	"github.com/sigstore/cosign/v2/pkg/cosign/bundle"
 	"github.com/sigstore/cosign/v2/pkg/cosign/pivkey"
 	"github.com/sigstore/cosign/v2/pkg/cosign/pkcs11key"
@@ -61,20 +63,41 @@ type VerifyBlobAttestationCommand struct {
 	CertGithubWorkflowRepository string
 	CertGithubWorkflowRef        string
 
-	IgnoreSCT  bool
-	SCTRef     string
-	Offline    bool
-	IgnoreTlog bool
+	IgnoreSCT         bool
+	SCTRef            string
+	Offline           bool
+	IgnoreTlog        bool
+	RequireOnlineTlog bool
+	RekorCheckpoint   string
+	RekorPublicKey    string
+	MaxSignatureAge   time.Duration
 
-	CheckClaims   bool
-	PredicateType string
-	// TODO: Add policies
+	CheckClaims          bool
+	CheckPredicateExpiry bool
+	PredicateType        string
+	Policies             []string
+	PolicyEngine         string
+	RegoQuery            string
+	PolicyData           []string
 
 	SignaturePath string // Path to the signature
+	Output        string
 }
 
-// Exec runs the verification command
-func (c *VerifyBlobAttestationCommand) Exec(ctx context.Context, artifactPath string) (err error) {
+// Exec runs the verification command. With --output json or yaml, the
+// VerificationResult is written to stdout.
+func (c *VerifyBlobAttestationCommand) Exec(ctx context.Context, artifactPath string) error {
+	if err := checkOutput(c.Output); err != nil {
+		return err
+	}
+	result := newVerificationResult()
+	result.Blob = artifactPath
+	err := c.verify(ctx, artifactPath, &result)
+	result.finish(err)
+	return finishWithResults(os.Stdout, c.Output, []VerificationResult{result}, err)
+}
+
+func (c *VerifyBlobAttestationCommand) verify(ctx context.Context, artifactPath string, result *VerificationResult) (err error) {
 	if options.NOf(c.SignaturePath, c.BundlePath) == 0 {
 		return fmt.Errorf("please specify path to the DSSE envelope signature via --signature or --bundle")
 	}
@@ -89,6 +112,25 @@ func (c *VerifyBlobAttestationCommand) Exec(ctx context.Context, artifactPath st
 		return &options.KeyParseError{}
 	}
 
+	if c.CARoots != "" && c.CertChain != "" {
+		return errors.New("--ca-roots cannot be used with --certificate-chain")
+	}
+
+	predicateType := c.PredicateType
+	if predicateType == "" {
+		predicateType = options.PredicateAll
+	}
+	bindings, err := parsePolicyBindings(c.Policies, c.PolicyEngine)
+	if err != nil {
+		return err
+	}
+	if hasPolicyBundles(bindings) {
+		return errors.New("oci:// policy bundles are not supported when verifying blobs")
+	}
+	if err := checkPolicySchemas(bindings, []string{predicateType}); err != nil {
+		return err
+	}
+
 	var identities []cosign.Identity
 	if c.KeyRef == "" {
 		identities, err = c.Identities()
@@ -107,6 +149,9 @@ func (c *VerifyBlobAttestationCommand) Exec(ctx context.Context, artifactPath st
 		IgnoreSCT:                    c.IgnoreSCT,
 		Offline:                      c.Offline,
 		IgnoreTlog:                   c.IgnoreTlog,
+		RequireOnlineTlog:            c.RequireOnlineTlog,
+		MaxSignatureAge:              c.MaxSignatureAge,
+		CheckPredicateExpiry:         c.CheckPredicateExpiry,
 	}
 	var h v1.Hash
 	if c.CheckClaims {
@@ -127,6 +172,7 @@ func (c *VerifyBlobAttestationCommand) Exec(ctx context.Context, artifactPath st
 			Hex:       hex.EncodeToString(digest),
 			Algorithm: "sha256",
 		}
+		result.Digest = h.String()
 		co.ClaimVerifier = cosign.IntotoSubjectClaimVerifier
 	}
 
@@ -159,6 +205,10 @@ func (c *VerifyBlobAttestationCommand) Exec(ctx context.Context, artifactPath st
 		co.TSARootCertificates = roots
 	}
 
+	if (c.RequireOnlineTlog || c.RekorCheckpoint != "") && (c.Offline || c.IgnoreTlog) {
+		return errors.New("--require-online-tlog and --rekor-checkpoint cannot be used with --offline or --insecure-ignore-tlog")
+	}
+
 	if !c.IgnoreTlog {
 		if c.RekorURL != "" {
 			rekorClient, err := rekor.NewClient(c.RekorURL)
@@ -169,23 +219,22 @@ func (c *VerifyBlobAttestationCommand) Exec(ctx context.Context, artifactPath st
 		}
 		// This performs an online fetch of the Rekor public keys, but this is needed
 		// for verifying tlog entries (both online and offline).
-		co.RekorPubKeys, err = cosign.GetRekorPubs(ctx)
+		co.RekorPubKeys, err = getRekorPubs(ctx, c.RekorPublicKey)
 		if err != nil {
 			return fmt.Errorf("getting Rekor public keys: %w", err)
 		}
+		if c.RekorCheckpoint != "" {
+			if co.RekorCheckpoint, err = loadRekorCheckpoint(c.RekorCheckpoint); err != nil {
+				return err
+			}
+		}
 	}
 	if keylessVerification(c.KeyRef, c.Sk) {
-		// Use default TUF roots if a cert chain is not provided.
-		// This performs an online fetch of the Fulcio roots. This is needed
-		// for verifying keyless certificates (both online and offline).
+		// Use the --ca-roots or default TUF roots if a cert chain is not provided.
 		if c.CertChain == "" {
-			co.RootCerts, err = fulcio.GetRoots()
-			if err != nil {
-				return fmt.Errorf("getting Fulcio roots: %w", err)
-			}
-			co.IntermediateCerts, err = fulcio.GetIntermediates()
+			co.RootCerts, co.IntermediateCerts, err = getRoots(c.CARoots, c.CAIntermediates)
 			if err != nil {
-				return fmt.Errorf("getting Fulcio intermediates: %w", err)
+				return err
 			}
 		}
 	}
@@ -341,8 +390,34 @@ func (c *VerifyBlobAttestationCommand) Exec(ctx context.Context, artifactPath st
 
 	// This checks the predicate type -- if no error is returned and no payload is, then
 	// the attestation is not of the given predicate type.
-	if b, gotPredicateType, err := policy.AttestationToPayloadJSON(ctx, c.PredicateType, signature); b == nil && err == nil {
-		return fmt.Errorf("invalid predicate type, expected %s got %s", c.PredicateType, gotPredicateType)
+	statement, err := attestation.DecodeEnvelopePayload(encodedSig)
+	if err != nil {
+		return fmt.Errorf("converting to consumable policy validation: %w", err)
+	}
+	b, gotPredicateType, err := policy.StatementToPayloadJSON(predicateType, statement)
+	if b == nil && err == nil {
+		return fmt.Errorf("invalid predicate type, expected %s got %s", predicateType, gotPredicateType)
+	}
+	if err != nil {
+		return fmt.Errorf("converting to consumable policy validation: %w", err)
+	}
+
+	result.PredicateTypes = []string{predicateType}
+	attResult, err := newAttestationResult(gotPredicateType, signature, statement)
+	if err != nil {
+		return err
+	}
+	if b, err = policyInput(b, signature, attResult, "", result.Digest); err != nil {
+		return err
+	}
+	validationErrors := validatePolicies(ctx, b, gotPredicateType, bindings, c.RegoQuery, c.PolicyData, &attResult)
+	result.Attestations = []AttestationResult{attResult}
+	if len(validationErrors) > 0 {
+		ui.Infof(ctx, "There are %d number of errors occurred during the validation:\n", len(validationErrors))
+		for _, v := range validationErrors {
+			ui.Infof(ctx, "- %v", v)
+		}
+		return fmt.Errorf("%d validation errors occurred", len(validationErrors))
 	}
 
 	fmt.Fprintln(os.Stderr, "Verified OK")
@@ -795,6 +795,41 @@ func TestVerifyBlobCmdWithBundle(t *testing.T) {
 			t.Fatal(err)
 		}
 	})
+	t.Run("Payload digest", func(t *testing.T) {
+		identity := "hello@foo.com"
+		issuer := "issuer"
+		leafCert, _, leafPemCert, signer := keyless.genLeafCert(t, identity, issuer)
+
+		blob := "someblob"
+		sig, err := signer.SignMessage(bytes.NewReader([]byte(blob)))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		entry := genRekorEntry(t, hashedrekord.KIND, hashedrekord.New().DefaultVersion(), []byte(blob), leafPemCert, sig)
+		b := createBundle(t, sig, leafPemCert, keyless.rekorLogID, leafCert.NotBefore.Unix()+1, entry)
+		b.Bundle.SignedEntryTimestamp = keyless.rekorSignPayload(t, b.Bundle.Payload)
+		cmd := VerifyBlobCmd{
+			CertVerifyOptions: options.CertVerifyOptions{
+				CertIdentity:   identity,
+				CertOidcIssuer: issuer,
+			},
+			IgnoreSCT: true,
+		}
+
+		b.PayloadDigest = fmt.Sprintf("sha256:%x", sha256.Sum256([]byte(blob)))
+		cmd.KeyOpts = options.KeyOpts{BundlePath: writeBundleFile(t, keyless.td, b, "bundle.json")}
+		if err := cmd.Exec(context.Background(), writeBlobFile(t, keyless.td, blob, "blob.txt")); err != nil {
+			t.Fatal(err)
+		}
+
+		b.PayloadDigest = fmt.Sprintf("sha256:%x", sha256.Sum256([]byte("otherblob")))
+		cmd.KeyOpts = options.KeyOpts{BundlePath: writeBundleFile(t, keyless.td, b, "bundle.json")}
+		err = cmd.Exec(context.Background(), writeBlobFile(t, keyless.td, blob, "blob.txt"))
+		if err == nil || !strings.Contains(err.Error(), "does not match the digest") {
+			t.Fatalf("expected digest mismatch error, got %v", err)
+		}
+	})
 	t.Run("Mismatched cert/sig", func(t *testing.T) {
 		// This test ensures that the signature and cert at the top level in the LocalSignedPayload must be identical to the ones in the RekorBundle.
 		identity := "hello@foo.com"
@@ -35,6 +35,7 @@ import (
 
 	"github.com/google/go-containerregistry/pkg/name"
 	"github.com/sigstore/cosign/v2/cmd/cosign/cli/options"
+	"github.com/sigstore/cosign/v2/cmd/cosign/cli/sign"
 	"github.com/sigstore/cosign/v2/internal/ui"
 	"github.com/sigstore/cosign/v2/pkg/oci"
 	"github.com/sigstore/cosign/v2/pkg/oci/static"
@@ -198,3 +199,33 @@ func TestVerifyCertMissingIssuer(t *testing.T) {
 		t.Fatal("verify expected 'need --certificate-oidc-issuer'")
 	}
 }
+
+func TestAttachedTo(t *testing.T) {
+	const hex = "0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef"
+	img := name.MustParseReference("ghcr.io/example/app@sha256:" + hex).(name.Digest)
+	other := name.MustParseReference("ghcr.io/example/other@sha256:" + hex).(name.Digest)
+	sbom := name.MustParseReference("ghcr.io/example/app@sha256:fedcba9876543210fedcba9876543210fedcba9876543210fedcba9876543210").(name.Digest)
+	sig := func(annotations map[string]interface{}) oci.Signature {
+		b, err := (&payload.Cosign{Image: sbom, Annotations: annotations}).MarshalJSON()
+		if err != nil {
+			t.Fatal(err)
+		}
+		s, err := static.NewSignature(b, "")
+		if err != nil {
+			t.Fatal(err)
+		}
+		return s
+	}
+	bound := sig(map[string]interface{}{sign.AttachedToAnnotationKey: img.String()})
+	legacy := sig(nil)
+
+	if kept, err := attachedTo(context.Background(), []oci.Signature{bound, legacy}, img); err != nil || len(kept) != 2 {
+		t.Errorf("attachedTo() of the image = %d signatures, %v, want 2", len(kept), err)
+	}
+	if kept, err := attachedTo(context.Background(), []oci.Signature{bound, legacy}, other); err != nil || len(kept) != 1 {
+		t.Errorf("attachedTo() of another image = %d signatures, %v, want the legacy one", len(kept), err)
+	}
+	if _, err := attachedTo(context.Background(), []oci.Signature{bound}, other); err == nil {
+		t.Error("attachedTo() of another image succeeded")
+	}
+}
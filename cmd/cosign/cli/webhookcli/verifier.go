@@ -0,0 +1,73 @@
+//
+// Copyright 2023 The Sigstore Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package webhookcli builds the image verifiers of the long-running
+// commands, cosign-webhook, cosign serve and cosign verify-tree.
+package webhookcli
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/sigstore/cosign/v2/cmd/cosign/cli/fulcio"
+	"github.com/sigstore/cosign/v2/cmd/cosign/cli/options"
+	"github.com/sigstore/cosign/v2/cmd/cosign/cli/rekor"
+	"github.com/sigstore/cosign/v2/pkg/cosign"
+	"github.com/sigstore/cosign/v2/pkg/webhook"
+)
+
+// NewVerifier returns the verifier of the signatures and attestations of
+// images, whose clients and trusted keys are shared by every verification.
+// The Fulcio roots and CT log keys are only loaded when keyless is set.
+func NewVerifier(ctx context.Context, reg options.RegistryOptions, rekorURL string, ignoreTlog, keyless bool) (*webhook.Verifier, error) {
+	ociremoteOpts, err := reg.ClientOpts(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("constructing client options: %w", err)
+	}
+	v := &webhook.Verifier{
+		RegistryClientOpts: ociremoteOpts,
+		IgnoreTlog:         ignoreTlog,
+	}
+	if !ignoreTlog {
+		if v.RekorClient, err = rekor.NewClient(rekorURL); err != nil {
+			return nil, fmt.Errorf("creating Rekor client: %w", err)
+		}
+		if v.RekorPubKeys, err = cosign.GetRekorPubs(ctx); err != nil {
+			return nil, fmt.Errorf("getting Rekor public keys: %w", err)
+		}
+	}
+	if !keyless {
+		return v, nil
+	}
+	if v.CTLogPubKeys, err = cosign.GetCTLogPubs(ctx); err != nil {
+		return nil, fmt.Errorf("getting ctlog public keys: %w", err)
+	}
+	if v.RootCerts, err = fulcio.GetRoots(); err != nil {
+		return nil, fmt.Errorf("getting Fulcio roots: %w", err)
+	}
+	if v.IntermediateCerts, err = fulcio.GetIntermediates(); err != nil {
+		return nil, fmt.Errorf("getting Fulcio intermediates: %w", err)
+	}
+	return v, nil
+}
+
+// NewMetrics returns the metrics of a verifier, which also measure the
+// latency of the requests of its registry, Rekor and Fulcio clients. It must
+// be called before NewVerifier creates the clients.
+func NewMetrics() *webhook.Metrics {
+	metrics := webhook.NewMetrics()
+	options.WrapTransports(metrics.Transport)
+	return metrics
+}
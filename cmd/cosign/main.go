@@ -21,9 +21,12 @@ import (
 	"log"
 	"os"
 	"strings"
+	"time"
 
 	"github.com/sigstore/cosign/v2/cmd/cosign/cli"
+	"github.com/sigstore/cosign/v2/cmd/cosign/cli/options"
 	cosignError "github.com/sigstore/cosign/v2/cmd/cosign/errors"
+	"github.com/sigstore/cosign/v2/internal/tracing"
 	"github.com/sigstore/cosign/v2/internal/ui"
 
 	// Register the provider-specific plugins
@@ -61,7 +64,7 @@ func main() {
 		}
 	}
 
-	if err := cli.New().Execute(); err != nil {
+	if err := execute(ctx); err != nil {
 		// if the error is a `CosignError` then we want to use the exit code that
 		// is related to the type of error that has occurred.
 		var cosignError *cosignError.CosignError
@@ -74,3 +77,28 @@ func main() {
 		log.Fatalf("error during command execution: %v", err)
 	}
 }
+
+// execute runs the command within a span, named after the command by the
+// root command, whose children trace the requests sent to the registry,
+// Rekor and Fulcio.
+func execute(ctx context.Context) error {
+	shutdown, err := tracing.Configure(ctx, "cosign")
+	if err != nil {
+		return err
+	}
+	defer func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if err := shutdown(ctx); err != nil {
+			ui.Warnf(ctx, "exporting traces: %v", err)
+		}
+	}()
+	if tracing.Enabled() {
+		options.WrapTransports(tracing.Transport)
+	}
+
+	ctx, span := tracing.Start(ctx, "cosign")
+	err = cli.New().ExecuteContext(ctx)
+	tracing.End(span, err)
+	return err
+}
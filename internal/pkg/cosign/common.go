@@ -23,6 +23,11 @@ import (
 
 const (
 	DefaultMaxWorkers int = 10
+
+	// DefaultMaxAttestationSize bounds the size of the attestations
+	// verify-attestation fetches, so that a huge attestation can't exhaust
+	// the memory of the verifier.
+	DefaultMaxAttestationSize int64 = 256 << 20
 )
 
 func FileExists(filename string) (bool, error) {
@@ -27,6 +27,7 @@ import (
 
 	"github.com/digitorus/timestamp"
 	"github.com/pkg/errors"
+	"github.com/sigstore/cosign/v2/internal/ui"
 )
 
 // TimestampAuthorityClient should be implemented by clients that want to request timestamp responses
@@ -130,6 +131,7 @@ func (t *TimestampAuthorityClientImpl) GetTimestampResponse(tsq []byte) ([]byte,
 		}
 		client.Transport = tr
 	}
+	client.Transport = ui.NewTransport("tsa", client.Transport)
 
 	req, err := http.NewRequest("POST", t.URL, bytes.NewReader(tsq))
 	if err != nil {
@@ -0,0 +1,133 @@
+//
+// Copyright 2023 The Sigstore Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package daemon resolves images stored by the local Docker daemon to the
+// registry digests they were pushed or pulled with.
+package daemon
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/google/go-containerregistry/pkg/name"
+
+	"github.com/sigstore/cosign/v2/pkg/cosign/env"
+)
+
+const (
+	// Prefix marks an image reference that is resolved by the Docker daemon.
+	Prefix = "docker-daemon://"
+	// ContainerdPrefix marks an image reference in the containerd image store.
+	ContainerdPrefix = "containerd://"
+
+	defaultHost = "unix:///var/run/docker.sock"
+)
+
+// imageInspect is the part of the Docker Engine API image inspection
+// response that is needed to find the digest of an image.
+type imageInspect struct {
+	RepoDigests []string `json:"RepoDigests"`
+}
+
+// ResolveDigest returns the digest that the Docker daemon recorded for the
+// image ref in its repository. The daemon only knows the digest of images
+// that were pushed or pulled, or built with the containerd image store.
+func ResolveDigest(ctx context.Context, ref string, opts ...name.Option) (name.Digest, error) {
+	r, err := name.ParseReference(ref, opts...)
+	if err != nil {
+		return name.Digest{}, fmt.Errorf("parsing reference: %w", err)
+	}
+	if d, ok := r.(name.Digest); ok {
+		return d, nil
+	}
+
+	host := env.Getenv(env.VariableDockerHost)
+	if host == "" {
+		host = defaultHost
+	}
+	client, base, err := newClient(host)
+	if err != nil {
+		return name.Digest{}, err
+	}
+	inspect, err := inspectImage(ctx, client, base, ref)
+	if err != nil {
+		return name.Digest{}, err
+	}
+	for _, rd := range inspect.RepoDigests {
+		d, err := name.NewDigest(rd, opts...)
+		if err != nil {
+			continue
+		}
+		if d.Context().Name() == r.Context().Name() {
+			return d, nil
+		}
+	}
+	return name.Digest{}, fmt.Errorf("the Docker daemon has no digest of %s in %s, push the image first", ref, r.Context().Name())
+}
+
+// newClient returns a client talking to the Docker daemon listening at host,
+// and the base URL of its API.
+func newClient(host string) (*http.Client, string, error) {
+	u, err := url.Parse(host)
+	if err != nil {
+		return nil, "", fmt.Errorf("parsing %s: %w", env.VariableDockerHost, err)
+	}
+	switch u.Scheme {
+	case "unix":
+		var d net.Dialer
+		return &http.Client{
+			Transport: &http.Transport{
+				DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+					return d.DialContext(ctx, "unix", u.Path)
+				},
+			},
+		}, "http://docker", nil
+	case "tcp":
+		return http.DefaultClient, "http://" + u.Host, nil
+	default:
+		return nil, "", fmt.Errorf("unsupported Docker daemon address %s, only unix:// and tcp:// are supported", host)
+	}
+}
+
+func inspectImage(ctx context.Context, client *http.Client, base, ref string) (*imageInspect, error) {
+	// Slashes of the reference are part of the path, as with the docker CLI.
+	u := base + "/images/" + strings.ReplaceAll(url.PathEscape(ref), "%2F", "/") + "/json"
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("contacting the Docker daemon: %w", err)
+	}
+	defer resp.Body.Close()
+	switch resp.StatusCode {
+	case http.StatusOK:
+	case http.StatusNotFound:
+		return nil, fmt.Errorf("image %s not found in the Docker daemon", ref)
+	default:
+		return nil, fmt.Errorf("inspecting image %s: Docker daemon returned %s", ref, resp.Status)
+	}
+	var inspect imageInspect
+	if err := json.NewDecoder(resp.Body).Decode(&inspect); err != nil {
+		return nil, fmt.Errorf("decoding image %s: %w", ref, err)
+	}
+	return &inspect, nil
+}
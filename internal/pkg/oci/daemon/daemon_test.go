@@ -0,0 +1,75 @@
+//
+// Copyright 2023 The Sigstore Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package daemon
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestResolveDigest(t *testing.T) {
+	const digest = "sha256:ec1b05d1eac264d9204a57f4ad9d4dc35e9e756e9fedaea0674aefc7edb1d6a4"
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/images/example.com/app:v1/json":
+			fmt.Fprintf(w, `{"Id": "sha256:abc", "RepoDigests": ["example.com/other@%[1]s", "example.com/app@%[1]s"]}`, digest)
+		case "/images/example.com/app:local/json":
+			fmt.Fprint(w, `{"Id": "sha256:abc", "RepoDigests": []}`)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer s.Close()
+	t.Setenv("DOCKER_HOST", strings.Replace(s.URL, "http://", "tcp://", 1))
+
+	tests := []struct {
+		ref     string
+		want    string
+		wantErr string
+	}{
+		{ref: "example.com/app:v1", want: "example.com/app@" + digest},
+		{ref: "example.com/app@" + digest, want: "example.com/app@" + digest},
+		{ref: "example.com/app:local", wantErr: "push the image first"},
+		{ref: "example.com/app:missing", wantErr: "not found"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.ref, func(t *testing.T) {
+			got, err := ResolveDigest(context.Background(), tt.ref)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("ResolveDigest() = %v, want error containing %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ResolveDigest() = %v", err)
+			}
+			if got.String() != tt.want {
+				t.Errorf("ResolveDigest() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNewClientUnsupported(t *testing.T) {
+	if _, _, err := newClient("npipe:////./pipe/docker_engine"); err == nil {
+		t.Error("newClient() with a named pipe succeeded")
+	}
+}
@@ -32,6 +32,22 @@ import (
 type Env struct {
 	Stderr io.Writer
 	Stdin  io.Reader
+
+	// Level and Format control which messages are logged to Stderr, and how.
+	// The zero values log informational messages as text.
+	Level  Level
+	Format Format
+}
+
+var (
+	defaultLevel  = LevelInfo
+	defaultFormat = FormatText
+)
+
+// Configure sets the verbosity and format of the default environment, from
+// the --verbose, --debug and --log-format flags.
+func Configure(level Level, format Format) {
+	defaultLevel, defaultFormat = level, format
 }
 
 // defaultEnv returns the default environment (writing to os.Stderr and
@@ -40,6 +56,8 @@ func defaultEnv() *Env {
 	return &Env{
 		Stderr: os.Stderr,
 		Stdin:  os.Stdin,
+		Level:  defaultLevel,
+		Format: defaultFormat,
 	}
 }
 
@@ -79,7 +97,7 @@ type callbackFunc func(context.Context, WriteFunc)
 func RunWithTestCtx(callback callbackFunc) string {
 	var stdin bytes.Buffer
 	var stderr bytes.Buffer
-	e := Env{&stderr, &stdin}
+	e := Env{Stderr: &stderr, Stdin: &stdin}
 
 	ctx := WithEnv(context.Background(), &e)
 	write := func(msg string) { stdin.WriteString(msg) }
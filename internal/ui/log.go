@@ -15,12 +15,78 @@ package ui
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"time"
 )
 
-func (w *Env) infof(msg string, a ...any) {
+// Level is the verbosity of the messages an Env logs.
+type Level int
+
+const (
+	// LevelInfo logs informational messages and warnings.
+	LevelInfo Level = iota
+	// LevelDebug also logs debug messages, such as every network call.
+	LevelDebug
+)
+
+// Format is the format messages are logged in.
+type Format string
+
+const (
+	// FormatText logs messages as plain lines of text.
+	FormatText Format = "text"
+	// FormatJSON logs every message as a JSON object on its own line, with
+	// "time", "level" and "msg" keys and any fields of the message.
+	FormatJSON Format = "json"
+)
+
+// ParseFormat parses the value of --log-format.
+func ParseFormat(s string) (Format, error) {
+	switch f := Format(s); f {
+	case FormatText, FormatJSON:
+		return f, nil
+	default:
+		return "", fmt.Errorf("unsupported log format %q, must be one of %s, %s", s, FormatText, FormatJSON)
+	}
+}
+
+// Fields are the structured attributes of a message. In the text format
+// they are appended to the message as key=value pairs.
+type Fields map[string]any
+
+func (w *Env) log(level, prefix string, fields Fields, msg string, a ...any) {
 	msg = fmt.Sprintf(msg, a...)
-	fmt.Fprintln(w.Stderr, msg)
+	if w.Format == FormatJSON {
+		entry := map[string]any{}
+		for k, v := range fields {
+			entry[k] = v
+		}
+		entry["time"] = time.Now().UTC().Format(time.RFC3339Nano)
+		entry["level"] = level
+		entry["msg"] = msg
+		b, err := json.Marshal(entry)
+		if err == nil {
+			fmt.Fprintln(w.Stderr, string(b))
+			return
+		}
+	}
+	if len(fields) > 0 {
+		var sb strings.Builder
+		sb.WriteString(msg)
+		for _, k := range sortedKeys(fields) {
+			fmt.Fprintf(&sb, " %s=%v", k, fields[k])
+		}
+		msg = sb.String()
+	}
+	fmt.Fprintln(w.Stderr, prefix+msg)
+}
+
+func (w *Env) infof(msg string, a ...any) {
+	w.log("info", "", nil, msg, a...)
 }
 
 // Infof logs an informational message. It works like fmt.Printf, except that it
@@ -30,8 +96,7 @@ func Infof(ctx context.Context, msg string, a ...any) {
 }
 
 func (w *Env) warnf(msg string, a ...any) {
-	msg = fmt.Sprintf(msg, a...)
-	fmt.Fprintf(w.Stderr, "WARNING: %s\n", msg)
+	w.log("warning", "WARNING: ", nil, msg, a...)
 }
 
 // Warnf logs a warning message (prefixed by "WARNING:"). It works like
@@ -39,3 +104,43 @@ func (w *Env) warnf(msg string, a ...any) {
 func Warnf(ctx context.Context, msg string, a ...any) {
 	getEnv(ctx).warnf(msg, a...)
 }
+
+// Debugf logs a debug message (prefixed by "DEBUG:") when the environment
+// is at LevelDebug, and nothing otherwise.
+func Debugf(ctx context.Context, msg string, a ...any) {
+	DebugFields(ctx, nil, msg, a...)
+}
+
+// DebugFields is Debugf for a message with structured fields.
+func DebugFields(ctx context.Context, fields Fields, msg string, a ...any) {
+	if w := getEnv(ctx); w.Level >= LevelDebug {
+		w.log("debug", "DEBUG: ", fields, msg, a...)
+	}
+}
+
+// DebugWriter returns a writer that logs everything written to it as debug
+// messages of the default environment, for the loggers of other packages.
+func DebugWriter() io.Writer {
+	return debugWriter{}
+}
+
+type debugWriter struct{}
+
+func (debugWriter) Write(p []byte) (int, error) {
+	Debugf(context.Background(), "%s", strings.TrimRight(string(p), "\n"))
+	return len(p), nil
+}
+
+// DebugEnabled reports whether debug messages are logged in ctx.
+func DebugEnabled(ctx context.Context) bool {
+	return getEnv(ctx).Level >= LevelDebug
+}
+
+func sortedKeys(fields Fields) []string {
+	keys := make([]string, 0, len(fields))
+	for k := range fields {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -14,7 +14,12 @@
 package ui_test
 
 import (
+	"bytes"
 	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
 	"testing"
 
 	"github.com/sigstore/cosign/v2/internal/ui"
@@ -55,3 +60,69 @@ func TestWarnf(t *testing.T) {
 		assert.Equal(t, tc.expected, stderr, "Bad output to STDERR")
 	}
 }
+
+func TestDebugf(t *testing.T) {
+	cases := []struct {
+		name     string
+		level    ui.Level
+		fields   ui.Fields
+		expected string
+	}{
+		{"info level", ui.LevelInfo, nil, ""},
+		{"debug level", ui.LevelDebug, nil, "DEBUG: foo: bar\n"},
+		{"fields", ui.LevelDebug, ui.Fields{"status": 200, "endpoint": "rekor"}, "DEBUG: foo: bar endpoint=rekor status=200\n"},
+	}
+	for _, tc := range cases {
+		var stderr bytes.Buffer
+		ctx := ui.WithEnv(context.Background(), &ui.Env{Stderr: &stderr, Level: tc.level})
+		ui.DebugFields(ctx, tc.fields, "foo: %v", "bar")
+		assert.Equal(t, tc.expected, stderr.String(), tc.name)
+	}
+}
+
+func TestJSONFormat(t *testing.T) {
+	var stderr bytes.Buffer
+	ctx := ui.WithEnv(context.Background(), &ui.Env{Stderr: &stderr, Level: ui.LevelDebug, Format: ui.FormatJSON})
+	ui.Infof(ctx, "foo: %v", "bar")
+	ui.Warnf(ctx, "baz")
+	ui.DebugFields(ctx, ui.Fields{"status": 200}, "qux")
+
+	var got []map[string]any
+	for _, line := range strings.Split(strings.TrimSpace(stderr.String()), "\n") {
+		var entry map[string]any
+		if err := json.Unmarshal([]byte(line), &entry); err != nil {
+			t.Fatalf("line %q is not JSON: %v", line, err)
+		}
+		assert.NotEmpty(t, entry["time"])
+		delete(entry, "time")
+		got = append(got, entry)
+	}
+	assert.Equal(t, []map[string]any{
+		{"level": "info", "msg": "foo: bar"},
+		{"level": "warning", "msg": "baz"},
+		{"level": "debug", "msg": "qux", "status": float64(200)},
+	}, got)
+}
+
+func TestTransport(t *testing.T) {
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusTeapot)
+	}))
+	defer s.Close()
+
+	var stderr bytes.Buffer
+	ctx := ui.WithEnv(context.Background(), &ui.Env{Stderr: &stderr, Level: ui.LevelDebug})
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, s.URL+"/foo", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp, err := ui.NewTransport("registry", nil).RoundTrip(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+
+	out := stderr.String()
+	assert.True(t, strings.HasPrefix(out, "DEBUG: GET "+s.URL+"/foo duration="), out)
+	assert.Contains(t, out, "endpoint=registry status=418")
+}
@@ -0,0 +1,55 @@
+// Copyright 2023 The Sigstore Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ui
+
+import (
+	"net/http"
+	"time"
+)
+
+// NewTransport wraps inner, or http.DefaultTransport when inner is nil, to
+// log every request sent to endpoint along with its status and duration
+// as a debug message.
+func NewTransport(endpoint string, inner http.RoundTripper) http.RoundTripper {
+	if inner == nil {
+		inner = http.DefaultTransport
+	}
+	return &transport{endpoint: endpoint, inner: inner}
+}
+
+type transport struct {
+	endpoint string
+	inner    http.RoundTripper
+}
+
+func (t *transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	ctx := req.Context()
+	if !DebugEnabled(ctx) {
+		return t.inner.RoundTrip(req)
+	}
+	start := time.Now()
+	resp, err := t.inner.RoundTrip(req)
+	fields := Fields{
+		"endpoint": t.endpoint,
+		"duration": time.Since(start).Round(time.Millisecond).String(),
+	}
+	if err != nil {
+		fields["error"] = err.Error()
+	} else {
+		fields["status"] = resp.StatusCode
+	}
+	DebugFields(ctx, fields, "%s %s", req.Method, req.URL.Redacted())
+	return resp, err
+}
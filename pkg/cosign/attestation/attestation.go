@@ -17,6 +17,7 @@ package attestation
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"reflect"
@@ -35,6 +36,14 @@ const (
 
 	// CosignVulnProvenanceV01 specifies the type of VulnerabilityScan Predicate
 	CosignVulnProvenanceV01 = "https://cosign.sigstore.dev/attestation/vuln/v1"
+
+	// CycloneDXBOMFormat is the bomFormat of every CycloneDX JSON BOM.
+	CycloneDXBOMFormat = "CycloneDX"
+
+	// CycloneDXPredicateTypePrefix prefixes the predicate types of CycloneDX
+	// statements that pin the version of the specification, e.g.
+	// https://cyclonedx.org/bom/v1.4.
+	CycloneDXPredicateTypePrefix = "https://cyclonedx.org/bom/"
 )
 
 // CosignPredicate specifies the format of the Custom Predicate.
@@ -100,41 +109,27 @@ type GenerateOpts struct {
 }
 
 // GenerateStatement returns an in-toto statement based on the provided
-// predicate type (custom|slsaprovenance|slsaprovenance02|slsaprovenance1|spdx|spdxjson|cyclonedx|link).
+// predicate type, either the name of a registered predicate type
+// (custom|slsaprovenance|slsaprovenance02|slsaprovenance1|spdx|spdxjson|cyclonedx|link|vuln)
+// or the URI of a custom predicate type.
 func GenerateStatement(opts GenerateOpts) (interface{}, error) {
 	predicate, err := io.ReadAll(opts.Predicate)
 	if err != nil {
 		return nil, err
 	}
 
-	switch opts.Type {
-	case "slsaprovenance":
-		return generateSLSAProvenanceStatementSLSA02(predicate, opts.Digest, opts.Repo)
-	case "slsaprovenance02":
-		return generateSLSAProvenanceStatementSLSA02(predicate, opts.Digest, opts.Repo)
-	case "slsaprovenance1":
-		return generateSLSAProvenanceStatementSLSA1(predicate, opts.Digest, opts.Repo)
-	case "spdx":
-		return generateSPDXStatement(predicate, opts.Digest, opts.Repo, false)
-	case "spdxjson":
-		return generateSPDXStatement(predicate, opts.Digest, opts.Repo, true)
-	case "cyclonedx":
-		return generateCycloneDXStatement(predicate, opts.Digest, opts.Repo)
-	case "link":
-		return generateLinkStatement(predicate, opts.Digest, opts.Repo)
-	case "vuln":
-		return generateVulnStatement(predicate, opts.Digest, opts.Repo)
-	default:
-		stamp := timestamp(opts)
-		predicateType := customType(opts)
-		return generateCustomStatement(predicate, predicateType, opts.Digest, opts.Repo, stamp)
+	pt, ok := LookupPredicateType(opts.Type)
+	if !ok {
+		return generateCustomStatement(predicate, opts.Type, opts.Digest, opts.Repo, timestamp(opts))
+	}
+	if pt.Generate == nil {
+		return generateCustomStatement(predicate, pt.URI, opts.Digest, opts.Repo, timestamp(opts))
 	}
+	return pt.Generate(predicate, opts.Digest, opts.Repo, now(opts))
 }
 
-func generateVulnStatement(predicate []byte, digest string, repo string) (interface{}, error) {
-	var vuln CosignVulnPredicate
-
-	err := json.Unmarshal(predicate, &vuln)
+func generateVulnStatement(predicate []byte, digest string, repo string, now time.Time) (interface{}, error) {
+	vuln, err := generateVulnPredicate(predicate, now)
 	if err != nil {
 		return nil, err
 	}
@@ -146,18 +141,14 @@ func generateVulnStatement(predicate []byte, digest string, repo string) (interf
 }
 
 func timestamp(opts GenerateOpts) string {
-	if opts.Time == nil {
-		opts.Time = time.Now
-	}
-	now := opts.Time()
-	return now.UTC().Format(time.RFC3339)
+	return now(opts).Format(time.RFC3339)
 }
 
-func customType(opts GenerateOpts) string {
-	if opts.Type != "custom" {
-		return opts.Type
+func now(opts GenerateOpts) time.Time {
+	if opts.Time == nil {
+		opts.Time = time.Now
 	}
-	return CosignCustomProvenanceV01
+	return opts.Time().UTC()
 }
 
 func generateStatementHeader(digest, repo, predicateType string) in_toto.StatementHeader {
@@ -203,7 +194,7 @@ func generateCustomPredicate(rawPayload []byte, customType, timestamp string) (i
 	return result, nil
 }
 
-func generateSLSAProvenanceStatementSLSA02(rawPayload []byte, digest string, repo string) (interface{}, error) {
+func generateSLSAProvenanceStatementSLSA02(rawPayload []byte, digest string, repo string, _ time.Time) (interface{}, error) {
 	var predicate slsa02.ProvenancePredicate
 	err := checkRequiredJSONFields(rawPayload, reflect.TypeOf(predicate))
 	if err != nil {
@@ -219,7 +210,7 @@ func generateSLSAProvenanceStatementSLSA02(rawPayload []byte, digest string, rep
 	}, nil
 }
 
-func generateSLSAProvenanceStatementSLSA1(rawPayload []byte, digest string, repo string) (interface{}, error) {
+func generateSLSAProvenanceStatementSLSA1(rawPayload []byte, digest string, repo string, _ time.Time) (interface{}, error) {
 	var predicate slsa1.ProvenancePredicate
 	err := checkRequiredJSONFields(rawPayload, reflect.TypeOf(predicate))
 	if err != nil {
@@ -235,7 +226,7 @@ func generateSLSAProvenanceStatementSLSA1(rawPayload []byte, digest string, repo
 	}, nil
 }
 
-func generateLinkStatement(rawPayload []byte, digest string, repo string) (interface{}, error) {
+func generateLinkStatement(rawPayload []byte, digest string, repo string, _ time.Time) (interface{}, error) {
 	var link in_toto.Link
 	err := checkRequiredJSONFields(rawPayload, reflect.TypeOf(link))
 	if err != nil {
@@ -266,17 +257,32 @@ func generateSPDXStatement(rawPayload []byte, digest string, repo string, parseJ
 	}, nil
 }
 
-func generateCycloneDXStatement(rawPayload []byte, digest string, repo string) (interface{}, error) {
-	var data interface{}
+func generateCycloneDXStatement(rawPayload []byte, digest string, repo string, _ time.Time) (interface{}, error) {
+	var data map[string]interface{}
 	if err := json.Unmarshal(rawPayload, &data); err != nil {
+		return nil, fmt.Errorf("CycloneDX predicate must be a JSON BOM: %w", err)
+	}
+	if err := CheckCycloneDXBOM(data); err != nil {
 		return nil, err
 	}
-	return in_toto.SPDXStatement{
+	return in_toto.CycloneDXStatement{
 		StatementHeader: generateStatementHeader(digest, repo, in_toto.PredicateCycloneDX),
 		Predicate:       data,
 	}, nil
 }
 
+// CheckCycloneDXBOM checks that bom, the predicate of a CycloneDX statement,
+// declares the CycloneDX format and the version of the specification it follows.
+func CheckCycloneDXBOM(bom map[string]interface{}) error {
+	if format, _ := bom["bomFormat"].(string); format != CycloneDXBOMFormat {
+		return fmt.Errorf("CycloneDX predicate: bomFormat must be %q", CycloneDXBOMFormat)
+	}
+	if version, _ := bom["specVersion"].(string); version == "" {
+		return errors.New("CycloneDX predicate: required field specVersion missing")
+	}
+	return nil
+}
+
 func checkRequiredJSONFields(rawPayload []byte, typ reflect.Type) error {
 	var tmp map[string]interface{}
 	if err := json.Unmarshal(rawPayload, &tmp); err != nil {
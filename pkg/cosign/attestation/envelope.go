@@ -0,0 +1,46 @@
+//
+// Copyright 2023 The Sigstore Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package attestation
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+)
+
+// DecodeEnvelopePayload returns the decoded payload, usually an in-toto
+// statement, of the DSSE envelope. The base64 payload is decoded straight
+// into the returned bytes and the other fields of the envelope are skipped,
+// so that large payloads, e.g. SBOMs, aren't copied into intermediate JSON
+// values first.
+func DecodeEnvelopePayload(envelope []byte) ([]byte, error) {
+	var env struct {
+		// encoding/json decodes base64 strings into byte slices.
+		Payload []byte `json:"payload"`
+	}
+	if err := json.Unmarshal(envelope, &env); err != nil {
+		var b64Err base64.CorruptInputError
+		if errors.As(err, &b64Err) {
+			return nil, fmt.Errorf("decoding payload: %w", b64Err)
+		}
+		return nil, fmt.Errorf("unmarshaling envelope: %w", err)
+	}
+	if len(env.Payload) == 0 {
+		return nil, errors.New("could not find payload in envelope")
+	}
+	return env.Payload, nil
+}
@@ -0,0 +1,92 @@
+//
+// Copyright 2023 The Sigstore Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package attestation
+
+import (
+	"encoding/base64"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestDecodeEnvelopePayload(t *testing.T) {
+	statement := `{"_type":"https://in-toto.io/Statement/v0.1","predicateType":"custom","predicate":{}}`
+	encoded := base64.StdEncoding.EncodeToString([]byte(statement))
+
+	tests := []struct {
+		name     string
+		envelope string
+		wantErr  string
+	}{{
+		name:     "payload first",
+		envelope: fmt.Sprintf(`{"payload":%q,"payloadType":"application/vnd.in-toto+json","signatures":[{"sig":"c2ln"}]}`, encoded),
+	}, {
+		name:     "payload last",
+		envelope: fmt.Sprintf(`{"payloadType":"application/vnd.in-toto+json","signatures":[{"sig":"c2ln"}],"payload":%q}`, encoded),
+	}, {
+		name:     "empty",
+		envelope: "",
+		wantErr:  "unmarshaling envelope",
+	}, {
+		name:     "not an object",
+		envelope: `["payload"]`,
+		wantErr:  "unmarshaling envelope",
+	}, {
+		name:     "truncated",
+		envelope: fmt.Sprintf(`{"payload":%q`, encoded),
+		wantErr:  "unmarshaling envelope",
+	}, {
+		name:     "bad base64",
+		envelope: `{"payload":"shou!ln'twork"}`,
+		wantErr:  "decoding payload: illegal base64",
+	}, {
+		name:     "no payload",
+		envelope: `{"payloadType":"application/vnd.in-toto+json"}`,
+		wantErr:  "could not find payload",
+	}}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := DecodeEnvelopePayload([]byte(tc.envelope))
+			if tc.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tc.wantErr) {
+					t.Fatalf("DecodeEnvelopePayload() = %v, want error containing %q", err, tc.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("DecodeEnvelopePayload() = %v", err)
+			}
+			if string(got) != statement {
+				t.Errorf("DecodeEnvelopePayload() = %s, want %s", got, statement)
+			}
+		})
+	}
+}
+
+func BenchmarkDecodeEnvelopePayload(b *testing.B) {
+	// A 64MiB statement, the size of the SBOM of a large image.
+	payload := strings.Repeat("a", 64<<20)
+	envelope := []byte(fmt.Sprintf(`{"payloadType":"application/vnd.in-toto+json","payload":%q,"signatures":[]}`,
+		base64.StdEncoding.EncodeToString([]byte(payload))))
+	b.SetBytes(int64(len(envelope)))
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := DecodeEnvelopePayload(envelope); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
@@ -0,0 +1,244 @@
+//
+// Copyright 2023 The Sigstore Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package attestation
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/in-toto/in-toto-golang/in_toto"
+	slsa02 "github.com/in-toto/in-toto-golang/in_toto/slsa_provenance/v0.2"
+	slsa1 "github.com/in-toto/in-toto-golang/in_toto/slsa_provenance/v1"
+)
+
+// StatementGenerator wraps a raw predicate into an in-toto statement about the
+// image with the given digest in repo.
+type StatementGenerator func(predicate []byte, digest, repo string, now time.Time) (interface{}, error)
+
+// StatementDecoder unmarshals an in-toto statement, validating its predicate,
+// into the value that policies are evaluated against.
+type StatementDecoder func(statement []byte) (interface{}, error)
+
+// PredicateType describes how the statements of a predicate type are
+// generated by `cosign attest` and decoded by `cosign verify-attestation`.
+type PredicateType struct {
+	// URI is the predicateType of the statements.
+	URI string
+	// URIPrefix, if set, also matches the statements whose predicateType
+	// starts with it, e.g. the versioned URIs of a specification.
+	URIPrefix string
+	// Generate creates a statement from a raw predicate. When nil, the
+	// predicate must be a JSON object and is embedded as is.
+	Generate StatementGenerator
+	// Decode unmarshals a statement. When nil, the statement is decoded as a
+	// generic in-toto statement.
+	Decode StatementDecoder
+}
+
+// Matches reports whether a statement with the given predicateType is of
+// this type.
+func (pt PredicateType) Matches(predicateType string) bool {
+	return predicateType == pt.URI || (pt.URIPrefix != "" && strings.HasPrefix(predicateType, pt.URIPrefix))
+}
+
+var (
+	predicateTypesMu sync.RWMutex
+	predicateTypes   = map[string]PredicateType{
+		"custom": {
+			URI: CosignCustomProvenanceV01,
+			Generate: func(predicate []byte, digest, repo string, now time.Time) (interface{}, error) {
+				return generateCustomStatement(predicate, CosignCustomProvenanceV01, digest, repo, now.Format(time.RFC3339))
+			},
+		},
+		"slsaprovenance": {
+			URI:      slsa02.PredicateSLSAProvenance,
+			Generate: generateSLSAProvenanceStatementSLSA02,
+			Decode:   decodeSLSAProvenanceStatementSLSA02,
+		},
+		"slsaprovenance02": {
+			URI:      slsa02.PredicateSLSAProvenance,
+			Generate: generateSLSAProvenanceStatementSLSA02,
+			Decode:   decodeSLSAProvenanceStatementSLSA02,
+		},
+		"slsaprovenance1": {
+			URI:      slsa1.PredicateSLSAProvenance,
+			Generate: generateSLSAProvenanceStatementSLSA1,
+			Decode:   decodeSLSAProvenanceStatementSLSA1,
+		},
+		"spdx": {
+			URI: in_toto.PredicateSPDX,
+			Generate: func(predicate []byte, digest, repo string, _ time.Time) (interface{}, error) {
+				return generateSPDXStatement(predicate, digest, repo, false)
+			},
+			Decode: decodeSPDXStatement,
+		},
+		"spdxjson": {
+			URI: in_toto.PredicateSPDX,
+			Generate: func(predicate []byte, digest, repo string, _ time.Time) (interface{}, error) {
+				return generateSPDXStatement(predicate, digest, repo, true)
+			},
+			Decode: decodeSPDXStatement,
+		},
+		"cyclonedx": {
+			URI:       in_toto.PredicateCycloneDX,
+			URIPrefix: CycloneDXPredicateTypePrefix,
+			Generate:  generateCycloneDXStatement,
+			Decode:    decodeCycloneDXStatement,
+		},
+		"link": {
+			URI:      in_toto.PredicateLinkV1,
+			Generate: generateLinkStatement,
+			Decode:   decodeLinkStatement,
+		},
+		"vuln": {
+			URI:      CosignVulnProvenanceV01,
+			Generate: generateVulnStatement,
+			Decode:   decodeVulnStatement,
+		},
+	}
+)
+
+// RegisterPredicateType makes the predicate type available under name to
+// `cosign attest --type` and `cosign verify-attestation --type`. It is meant
+// to be called from an init function of programs embedding cosign, and fails
+// if name is already registered.
+func RegisterPredicateType(name string, pt PredicateType) error {
+	if name == "" {
+		return errors.New("predicate type name is required")
+	}
+	if pt.URI == "" {
+		return fmt.Errorf("predicate type %s: URI is required", name)
+	}
+	predicateTypesMu.Lock()
+	defer predicateTypesMu.Unlock()
+	if _, ok := predicateTypes[name]; ok {
+		return fmt.Errorf("predicate type %s is already registered", name)
+	}
+	predicateTypes[name] = pt
+	return nil
+}
+
+// LookupPredicateType returns the predicate type registered under name.
+func LookupPredicateType(name string) (PredicateType, bool) {
+	predicateTypesMu.RLock()
+	defer predicateTypesMu.RUnlock()
+	pt, ok := predicateTypes[name]
+	return pt, ok
+}
+
+// LookupPredicateTypeURI returns the registered predicate type matching the
+// predicateType of a statement.
+func LookupPredicateTypeURI(uri string) (PredicateType, bool) {
+	predicateTypesMu.RLock()
+	defer predicateTypesMu.RUnlock()
+	names := make([]string, 0, len(predicateTypes))
+	for name := range predicateTypes {
+		names = append(names, name)
+	}
+	// Several names may share a URI, pick one deterministically.
+	sort.Strings(names)
+	for _, name := range names {
+		if pt := predicateTypes[name]; pt.Matches(uri) {
+			return pt, true
+		}
+	}
+	return PredicateType{}, false
+}
+
+// PredicateTypeNames returns the sorted names of the registered predicate types.
+func PredicateTypeNames() []string {
+	predicateTypesMu.RLock()
+	defer predicateTypesMu.RUnlock()
+	names := make([]string, 0, len(predicateTypes))
+	for name := range predicateTypes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// DecodeStatement decodes statement with the decoder of pt, or as a generic
+// in-toto statement if it has none.
+func (pt PredicateType) DecodeStatement(statement []byte) (interface{}, error) {
+	if pt.Decode != nil {
+		return pt.Decode(statement)
+	}
+	var s in_toto.Statement
+	if err := json.Unmarshal(statement, &s); err != nil {
+		return nil, fmt.Errorf("unmarshal in-toto statement: %w", err)
+	}
+	return s, nil
+}
+
+func decodeSLSAProvenanceStatementSLSA02(statement []byte) (interface{}, error) {
+	var s in_toto.ProvenanceStatementSLSA02
+	if err := json.Unmarshal(statement, &s); err != nil {
+		return nil, fmt.Errorf("unmarshaling ProvenanceStatementSLSA02: %w", err)
+	}
+	return s, nil
+}
+
+func decodeSLSAProvenanceStatementSLSA1(statement []byte) (interface{}, error) {
+	var s in_toto.ProvenanceStatementSLSA1
+	if err := json.Unmarshal(statement, &s); err != nil {
+		return nil, fmt.Errorf("unmarshaling ProvenanceStatementSLSA1: %w", err)
+	}
+	return s, nil
+}
+
+func decodeSPDXStatement(statement []byte) (interface{}, error) {
+	var s in_toto.SPDXStatement
+	if err := json.Unmarshal(statement, &s); err != nil {
+		return nil, fmt.Errorf("unmarshaling SPDXStatement: %w", err)
+	}
+	return s, nil
+}
+
+func decodeCycloneDXStatement(statement []byte) (interface{}, error) {
+	var s in_toto.CycloneDXStatement
+	if err := json.Unmarshal(statement, &s); err != nil {
+		return nil, fmt.Errorf("unmarshaling CycloneDXStatement: %w", err)
+	}
+	bom, ok := s.Predicate.(map[string]interface{})
+	if !ok {
+		return nil, errors.New("CycloneDX predicate is not a JSON BOM")
+	}
+	if err := CheckCycloneDXBOM(bom); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+func decodeLinkStatement(statement []byte) (interface{}, error) {
+	var s in_toto.LinkStatement
+	if err := json.Unmarshal(statement, &s); err != nil {
+		return nil, fmt.Errorf("unmarshaling LinkStatement: %w", err)
+	}
+	return s, nil
+}
+
+func decodeVulnStatement(statement []byte) (interface{}, error) {
+	var s CosignVulnStatement
+	if err := json.Unmarshal(statement, &s); err != nil {
+		return nil, fmt.Errorf("unmarshaling CosignVulnStatement: %w", err)
+	}
+	return s, nil
+}
@@ -0,0 +1,146 @@
+//
+// Copyright 2023 The Sigstore Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package attestation
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/in-toto/in-toto-golang/in_toto"
+	slsa1 "github.com/in-toto/in-toto-golang/in_toto/slsa_provenance/v1"
+)
+
+type testPredicate struct {
+	Team string `json:"team"`
+}
+
+type testStatement struct {
+	in_toto.StatementHeader
+	Predicate testPredicate `json:"predicate"`
+}
+
+func TestRegisterPredicateType(t *testing.T) {
+	const uri = "https://example.com/ownership/v1"
+	pt := PredicateType{
+		URI: uri,
+		Generate: func(predicate []byte, digest, repo string, _ time.Time) (interface{}, error) {
+			var p testPredicate
+			if err := json.Unmarshal(predicate, &p); err != nil {
+				return nil, err
+			}
+			if p.Team == "" {
+				return nil, errors.New("team is required")
+			}
+			return testStatement{StatementHeader: generateStatementHeader(digest, repo, uri), Predicate: p}, nil
+		},
+	}
+	if err := RegisterPredicateType("ownership", pt); err != nil {
+		t.Fatalf("RegisterPredicateType() = %v", err)
+	}
+	if err := RegisterPredicateType("ownership", pt); err == nil {
+		t.Error("expected an error registering a predicate type twice")
+	}
+	if err := RegisterPredicateType("slsaprovenance", pt); err == nil {
+		t.Error("expected an error overriding a built-in predicate type")
+	}
+	if err := RegisterPredicateType("nouri", PredicateType{}); err == nil {
+		t.Error("expected an error registering a predicate type without URI")
+	}
+
+	got, ok := LookupPredicateType("ownership")
+	if !ok || got.URI != uri {
+		t.Fatalf("LookupPredicateType() = %v, %t", got, ok)
+	}
+
+	statement, err := GenerateStatement(GenerateOpts{
+		Predicate: bytes.NewBufferString(`{"team":"platform"}`),
+		Type:      "ownership",
+		Digest:    "deadbeef",
+		Repo:      "example.com/app",
+	})
+	if err != nil {
+		t.Fatalf("GenerateStatement() = %v", err)
+	}
+	s, ok := statement.(testStatement)
+	if !ok {
+		t.Fatalf("GenerateStatement() returned %T", statement)
+	}
+	if s.PredicateType != uri || s.Predicate.Team != "platform" {
+		t.Errorf("unexpected statement %+v", s)
+	}
+
+	if _, err := GenerateStatement(GenerateOpts{
+		Predicate: bytes.NewBufferString(`{}`),
+		Type:      "ownership",
+	}); err == nil {
+		t.Error("expected the generator of the registered type to reject the predicate")
+	}
+}
+
+func TestPredicateTypeMatches(t *testing.T) {
+	cdx, ok := LookupPredicateType("cyclonedx")
+	if !ok {
+		t.Fatal("cyclonedx is not registered")
+	}
+	for _, tt := range []struct {
+		predicateType string
+		want          bool
+	}{
+		{in_toto.PredicateCycloneDX, true},
+		{"https://cyclonedx.org/bom/v1.5", true},
+		{in_toto.PredicateSPDX, false},
+	} {
+		if got := cdx.Matches(tt.predicateType); got != tt.want {
+			t.Errorf("Matches(%q) = %t, want %t", tt.predicateType, got, tt.want)
+		}
+	}
+}
+
+func TestLookupPredicateTypeURI(t *testing.T) {
+	for _, tt := range []struct {
+		uri     string
+		wantURI string
+		wantOK  bool
+	}{
+		{slsa1.PredicateSLSAProvenance, slsa1.PredicateSLSAProvenance, true},
+		{"https://cyclonedx.org/bom/v1.5", in_toto.PredicateCycloneDX, true},
+		{CosignVulnProvenanceV01, CosignVulnProvenanceV01, true},
+		{"https://example.com/unknown/v1", "", false},
+	} {
+		pt, ok := LookupPredicateTypeURI(tt.uri)
+		if ok != tt.wantOK || pt.URI != tt.wantURI {
+			t.Errorf("LookupPredicateTypeURI(%q) = %q, %t, want %q, %t", tt.uri, pt.URI, ok, tt.wantURI, tt.wantOK)
+		}
+	}
+}
+
+func TestPredicateTypeNames(t *testing.T) {
+	names := PredicateTypeNames()
+	for _, want := range []string{"custom", "cyclonedx", "link", "slsaprovenance", "slsaprovenance02", "slsaprovenance1", "spdx", "spdxjson", "vuln"} {
+		found := false
+		for _, n := range names {
+			if n == want {
+				found = true
+			}
+		}
+		if !found {
+			t.Errorf("built-in predicate type %s is not registered", want)
+		}
+	}
+}
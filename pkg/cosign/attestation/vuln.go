@@ -0,0 +1,130 @@
+//
+// Copyright 2023 The Sigstore Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package attestation
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+const (
+	// TrivyScannerURI identifies Trivy as the scanner of a vuln predicate.
+	TrivyScannerURI = "pkg:github/aquasecurity/trivy"
+	// GrypeScannerURI identifies Grype as the scanner of a vuln predicate.
+	GrypeScannerURI = "pkg:github/anchore/grype"
+)
+
+// trivyReport holds the fields of `trivy image -f json` used to fill the
+// predicate. The report itself is kept as the scanner result.
+type trivyReport struct {
+	CreatedAt time.Time `json:"CreatedAt"`
+}
+
+// grypeReport holds the fields of `grype -o json` used to fill the predicate.
+type grypeReport struct {
+	Descriptor struct {
+		Version   string    `json:"version"`
+		Timestamp time.Time `json:"timestamp"`
+		DB        struct {
+			Built         string `json:"built"`
+			SchemaVersion int    `json:"schemaVersion"`
+		} `json:"db"`
+	} `json:"descriptor"`
+}
+
+// generateVulnPredicate returns the vuln predicate for rawPayload, which is
+// either a predicate following the cosign vuln spec or the JSON report of a
+// supported scanner. Scanner reports are kept as a whole in scanner.result so
+// that policies can be written against their list of vulnerabilities.
+func generateVulnPredicate(rawPayload []byte, now time.Time) (CosignVulnPredicate, error) {
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(rawPayload, &fields); err != nil {
+		return CosignVulnPredicate{}, err
+	}
+
+	var result interface{}
+	switch {
+	case hasFields(fields, "SchemaVersion", "Results"):
+		var report trivyReport
+		if err := json.Unmarshal(rawPayload, &report); err != nil {
+			return CosignVulnPredicate{}, fmt.Errorf("unmarshal Trivy report: %w", err)
+		}
+		if err := json.Unmarshal(rawPayload, &result); err != nil {
+			return CosignVulnPredicate{}, err
+		}
+		scanned := now
+		if !report.CreatedAt.IsZero() {
+			scanned = report.CreatedAt
+		}
+		return CosignVulnPredicate{
+			Scanner: Scanner{
+				URI:    TrivyScannerURI,
+				Result: result,
+			},
+			Metadata: Metadata{
+				ScanStartedOn:  scanned,
+				ScanFinishedOn: scanned,
+			},
+		}, nil
+	case hasFields(fields, "matches", "descriptor"):
+		var report grypeReport
+		if err := json.Unmarshal(rawPayload, &report); err != nil {
+			return CosignVulnPredicate{}, fmt.Errorf("unmarshal Grype report: %w", err)
+		}
+		if err := json.Unmarshal(rawPayload, &result); err != nil {
+			return CosignVulnPredicate{}, err
+		}
+		scanned := now
+		if !report.Descriptor.Timestamp.IsZero() {
+			scanned = report.Descriptor.Timestamp
+		}
+		var dbVersion string
+		if report.Descriptor.DB.SchemaVersion != 0 {
+			dbVersion = fmt.Sprintf("v%d", report.Descriptor.DB.SchemaVersion)
+			if report.Descriptor.DB.Built != "" {
+				dbVersion += "-" + report.Descriptor.DB.Built
+			}
+		}
+		return CosignVulnPredicate{
+			Scanner: Scanner{
+				URI:     GrypeScannerURI,
+				Version: report.Descriptor.Version,
+				DB:      DB{Version: dbVersion},
+				Result:  result,
+			},
+			Metadata: Metadata{
+				ScanStartedOn:  scanned,
+				ScanFinishedOn: scanned,
+			},
+		}, nil
+	}
+
+	var vuln CosignVulnPredicate
+	if err := json.Unmarshal(rawPayload, &vuln); err != nil {
+		return CosignVulnPredicate{}, err
+	}
+	return vuln, nil
+}
+
+func hasFields(fields map[string]json.RawMessage, names ...string) bool {
+	for _, n := range names {
+		if _, ok := fields[n]; !ok {
+			return false
+		}
+	}
+	return true
+}
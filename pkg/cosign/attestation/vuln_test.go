@@ -0,0 +1,92 @@
+//
+// Copyright 2023 The Sigstore Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package attestation
+
+import (
+	"testing"
+	"time"
+)
+
+func TestGenerateVulnPredicate(t *testing.T) {
+	now := time.Date(2023, 5, 1, 12, 0, 0, 0, time.UTC)
+	scanned := time.Date(2023, 4, 30, 8, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name        string
+		payload     string
+		wantURI     string
+		wantVersion string
+		wantDB      string
+		wantScanned time.Time
+		wantResult  bool
+	}{{
+		name:        "cosign vuln predicate",
+		payload:     `{"scanner":{"uri":"pkg:github/example/scanner","version":"1.0.0","result":{}},"metadata":{"scanStartedOn":"2023-04-30T08:00:00Z","scanFinishedOn":"2023-04-30T08:00:00Z"}}`,
+		wantURI:     "pkg:github/example/scanner",
+		wantVersion: "1.0.0",
+		wantScanned: scanned,
+		wantResult:  true,
+	}, {
+		name:        "trivy report",
+		payload:     `{"SchemaVersion":2,"CreatedAt":"2023-04-30T08:00:00Z","ArtifactName":"alpine:3.12","Results":[{"Target":"alpine:3.12","Vulnerabilities":[{"VulnerabilityID":"CVE-2021-28831","Severity":"HIGH"}]}]}`,
+		wantURI:     TrivyScannerURI,
+		wantScanned: scanned,
+		wantResult:  true,
+	}, {
+		name:        "trivy report without timestamp",
+		payload:     `{"SchemaVersion":2,"ArtifactName":"alpine:3.12","Results":[]}`,
+		wantURI:     TrivyScannerURI,
+		wantScanned: now,
+		wantResult:  true,
+	}, {
+		name:        "grype report",
+		payload:     `{"matches":[{"vulnerability":{"id":"CVE-2021-28831","severity":"High"}}],"descriptor":{"name":"grype","version":"0.61.0","timestamp":"2023-04-30T08:00:00Z","db":{"built":"2023-04-29T01:20:19Z","schemaVersion":5}}}`,
+		wantURI:     GrypeScannerURI,
+		wantVersion: "0.61.0",
+		wantDB:      "v5-2023-04-29T01:20:19Z",
+		wantScanned: scanned,
+		wantResult:  true,
+	}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := generateVulnPredicate([]byte(tt.payload), now)
+			if err != nil {
+				t.Fatalf("generateVulnPredicate() = %v", err)
+			}
+			if got.Scanner.URI != tt.wantURI {
+				t.Errorf("scanner.uri = %q, wanted %q", got.Scanner.URI, tt.wantURI)
+			}
+			if got.Scanner.Version != tt.wantVersion {
+				t.Errorf("scanner.version = %q, wanted %q", got.Scanner.Version, tt.wantVersion)
+			}
+			if got.Scanner.DB.Version != tt.wantDB {
+				t.Errorf("scanner.db.version = %q, wanted %q", got.Scanner.DB.Version, tt.wantDB)
+			}
+			if !got.Metadata.ScanFinishedOn.Equal(tt.wantScanned) {
+				t.Errorf("metadata.scanFinishedOn = %v, wanted %v", got.Metadata.ScanFinishedOn, tt.wantScanned)
+			}
+			if (got.Scanner.Result != nil) != tt.wantResult {
+				t.Errorf("scanner.result = %v, wanted result %t", got.Scanner.Result, tt.wantResult)
+			}
+		})
+	}
+}
+
+func TestGenerateVulnPredicateInvalid(t *testing.T) {
+	if _, err := generateVulnPredicate([]byte(`not json`), time.Now()); err == nil {
+		t.Error("expected an error for a predicate that is not JSON")
+	}
+}
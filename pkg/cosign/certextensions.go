@@ -17,6 +17,9 @@ package cosign
 
 import (
 	"crypto/x509"
+	"encoding/asn1"
+	"net/url"
+	"strings"
 )
 
 type CertExtensions struct {
@@ -32,6 +35,14 @@ var (
 	CertExtensionGithubWorkflowRepository = "1.3.6.1.4.1.57264.1.5"
 	CertExtensionGithubWorkflowRef        = "1.3.6.1.4.1.57264.1.6"
 
+	// The extensions below supersede the GitHub specific ones above. Their
+	// values are DER-encoded UTF8Strings.
+	CertExtensionOIDCIssuerV2           = "1.3.6.1.4.1.57264.1.8"
+	CertExtensionSourceRepositoryURI    = "1.3.6.1.4.1.57264.1.12"
+	CertExtensionSourceRepositoryDigest = "1.3.6.1.4.1.57264.1.13"
+	CertExtensionSourceRepositoryRef    = "1.3.6.1.4.1.57264.1.14"
+	CertExtensionBuildTrigger           = "1.3.6.1.4.1.57264.1.20"
+
 	CertExtensionMap = map[string]string{
 		CertExtensionOIDCIssuer:               "oidcIssuer",
 		CertExtensionGithubWorkflowTrigger:    "githubWorkflowTrigger",
@@ -55,19 +66,43 @@ func (ce *CertExtensions) certExtensions() map[string]string {
 	return extensions
 }
 
+// derExtension returns the value of a DER-encoded UTF8String extension.
+func (ce *CertExtensions) derExtension(oid string) string {
+	for _, ext := range ce.Cert.Extensions {
+		if ext.Id.String() != oid {
+			continue
+		}
+		var v string
+		if rest, err := asn1.Unmarshal(ext.Value, &v); err != nil || len(rest) != 0 {
+			return ""
+		}
+		return v
+	}
+	return ""
+}
+
 // GetIssuer returns the issuer for a Certificate
 func (ce *CertExtensions) GetIssuer() string {
-	return ce.certExtensions()["oidcIssuer"]
+	if issuer := ce.certExtensions()["oidcIssuer"]; issuer != "" {
+		return issuer
+	}
+	return ce.derExtension(CertExtensionOIDCIssuerV2)
 }
 
 // GetCertExtensionGithubWorkflowTrigger returns the GitHub Workflow Trigger for a Certificate
 func (ce *CertExtensions) GetCertExtensionGithubWorkflowTrigger() string {
-	return ce.certExtensions()["githubWorkflowTrigger"]
+	if trigger := ce.certExtensions()["githubWorkflowTrigger"]; trigger != "" {
+		return trigger
+	}
+	return ce.derExtension(CertExtensionBuildTrigger)
 }
 
 // GetExtensionGithubWorkflowSha returns the GitHub Workflow SHA for a Certificate
 func (ce *CertExtensions) GetExtensionGithubWorkflowSha() string {
-	return ce.certExtensions()["githubWorkflowSha"]
+	if sha := ce.certExtensions()["githubWorkflowSha"]; sha != "" {
+		return sha
+	}
+	return ce.derExtension(CertExtensionSourceRepositoryDigest)
 }
 
 // GetCertExtensionGithubWorkflowName returns the GitHub Workflow Name for a Certificate
@@ -77,10 +112,22 @@ func (ce *CertExtensions) GetCertExtensionGithubWorkflowName() string {
 
 // GetCertExtensionGithubWorkflowRepository returns the GitHub Workflow Repository for a Certificate
 func (ce *CertExtensions) GetCertExtensionGithubWorkflowRepository() string {
-	return ce.certExtensions()["githubWorkflowRepository"]
+	if repo := ce.certExtensions()["githubWorkflowRepository"]; repo != "" {
+		return repo
+	}
+	// The source repository is a URI, e.g. https://github.com/owner/repo,
+	// while the legacy extension only holds owner/repo.
+	u, err := url.Parse(ce.derExtension(CertExtensionSourceRepositoryURI))
+	if err != nil {
+		return ""
+	}
+	return strings.Trim(u.Path, "/")
 }
 
 // GetCertExtensionGithubWorkflowRef returns the GitHub Workflow Ref for a Certificate
 func (ce *CertExtensions) GetCertExtensionGithubWorkflowRef() string {
-	return ce.certExtensions()["githubWorkflowRef"]
+	if ref := ce.certExtensions()["githubWorkflowRef"]; ref != "" {
+		return ref
+	}
+	return ce.derExtension(CertExtensionSourceRepositoryRef)
 }
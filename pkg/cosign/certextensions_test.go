@@ -64,3 +64,48 @@ func TestCertExtensions(t *testing.T) {
 		t.Fatal("CertExtension does not extract field 'githubWorkflowRef' correctly")
 	}
 }
+
+func TestCertExtensionsV2(t *testing.T) {
+	t.Parallel()
+	der := func(v string) []byte {
+		b, err := asn1.MarshalWithParams(v, "utf8")
+		if err != nil {
+			t.Fatal(err)
+		}
+		return b
+	}
+	cert := &x509.Certificate{
+		Extensions: []pkix.Extension{
+			{Id: asn1.ObjectIdentifier{1, 3, 6, 1, 4, 1, 57264, 1, 8}, Value: der("https://token.actions.githubusercontent.com")},
+			{Id: asn1.ObjectIdentifier{1, 3, 6, 1, 4, 1, 57264, 1, 12}, Value: der("https://github.com/myorg/myrepo")},
+			{Id: asn1.ObjectIdentifier{1, 3, 6, 1, 4, 1, 57264, 1, 13}, Value: der("mySha")},
+			{Id: asn1.ObjectIdentifier{1, 3, 6, 1, 4, 1, 57264, 1, 14}, Value: der("refs/heads/main")},
+			{Id: asn1.ObjectIdentifier{1, 3, 6, 1, 4, 1, 57264, 1, 20}, Value: der("push")},
+		},
+	}
+	exts := CertExtensions{Cert: cert}
+
+	for _, tt := range []struct {
+		name string
+		got  string
+		want string
+	}{
+		{"issuer", exts.GetIssuer(), "https://token.actions.githubusercontent.com"},
+		{"trigger", exts.GetCertExtensionGithubWorkflowTrigger(), "push"},
+		{"sha", exts.GetExtensionGithubWorkflowSha(), "mySha"},
+		{"repository", exts.GetCertExtensionGithubWorkflowRepository(), "myorg/myrepo"},
+		{"ref", exts.GetCertExtensionGithubWorkflowRef(), "refs/heads/main"},
+		{"name", exts.GetCertExtensionGithubWorkflowName(), ""},
+	} {
+		if tt.got != tt.want {
+			t.Errorf("%s = %q, want %q", tt.name, tt.got, tt.want)
+		}
+	}
+
+	// The legacy extensions win when both are present.
+	legacy := CertExtensions{Cert: createCert(t)}
+	legacy.Cert.Extensions = append(legacy.Cert.Extensions, cert.Extensions...)
+	if val := legacy.GetCertExtensionGithubWorkflowRepository(); val != "myWorkflowRepository" {
+		t.Errorf("repository = %q, want the legacy extension", val)
+	}
+}
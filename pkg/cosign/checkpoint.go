@@ -0,0 +1,154 @@
+//
+// Copyright 2023 The Sigstore Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cosign
+
+import (
+	"bytes"
+	"context"
+	"crypto"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/sigstore/rekor/pkg/generated/client"
+	"github.com/sigstore/rekor/pkg/generated/client/tlog"
+	"github.com/sigstore/rekor/pkg/generated/models"
+	"github.com/sigstore/rekor/pkg/util"
+	"github.com/sigstore/sigstore/pkg/signature"
+	"github.com/transparency-dev/merkle/proof"
+	"github.com/transparency-dev/merkle/rfc6962"
+)
+
+// ParseCheckpoint parses a signed Rekor checkpoint, the signed tree head of
+// the log, e.g. saved from `rekor-cli loginfo`. Set as CheckOpts.RekorCheckpoint
+// it pins the state of the log that transparency log entries must be
+// consistent with.
+func ParseCheckpoint(raw []byte) (*util.SignedCheckpoint, error) {
+	sth := &util.SignedCheckpoint{}
+	if err := sth.UnmarshalText(raw); err != nil {
+		return nil, fmt.Errorf("parsing checkpoint: %w", err)
+	}
+	return sth, nil
+}
+
+// verifyCheckpointSignature verifies the signature of sth with one of the
+// trusted Rekor public keys.
+func verifyCheckpointSignature(sth *util.SignedCheckpoint, rekorPubKeys *TrustedTransparencyLogPubKeys) error {
+	for _, k := range rekorPubKeys.Keys {
+		verifier, err := signature.LoadVerifier(k.PubKey, crypto.SHA256)
+		if err != nil {
+			continue
+		}
+		if sth.Verify(verifier) {
+			return nil
+		}
+	}
+	return errors.New("checkpoint is not signed by a trusted rekor public key")
+}
+
+// verifyEntryCheckpoint verifies the checkpoint of the inclusion proof of e
+// with the log's public key, and that it signs the root hash and tree size the
+// inclusion was proven against. It returns the verified checkpoint.
+func verifyEntryCheckpoint(e *models.LogEntryAnon, pubKey crypto.PublicKey) (*util.SignedCheckpoint, error) {
+	ip := e.Verification.InclusionProof
+	if ip.Checkpoint == nil {
+		return nil, errors.New("inclusion proof has no checkpoint")
+	}
+	sth, err := ParseCheckpoint([]byte(*ip.Checkpoint))
+	if err != nil {
+		return nil, err
+	}
+	verifier, err := signature.LoadVerifier(pubKey, crypto.SHA256)
+	if err != nil {
+		return nil, err
+	}
+	if !sth.Verify(verifier) {
+		return nil, errors.New("checkpoint signature is invalid")
+	}
+	rootHash, err := hex.DecodeString(*ip.RootHash)
+	if err != nil {
+		return nil, fmt.Errorf("decoding inclusion proof root hash: %w", err)
+	}
+	if !bytes.Equal(rootHash, sth.Hash) || uint64(*ip.TreeSize) != sth.Size {
+		return nil, errors.New("inclusion proof does not match the checkpoint")
+	}
+	return sth, nil
+}
+
+// verifyCheckpointConsistency verifies that the log state of sth is
+// consistent with the pinned checkpoint, asking the log for a consistency
+// proof when their tree sizes differ.
+func verifyCheckpointConsistency(ctx context.Context, rekorClient *client.Rekor, pinned, sth *util.SignedCheckpoint) error {
+	if pinned.Origin != sth.Origin {
+		return fmt.Errorf("checkpoint is for log %q, not the pinned log %q", sth.Origin, pinned.Origin)
+	}
+	older, newer := pinned, sth
+	if older.Size > newer.Size {
+		older, newer = newer, older
+	}
+	if older.Size == newer.Size {
+		if !bytes.Equal(older.Hash, newer.Hash) {
+			return errors.New("checkpoint root hash differs from the pinned checkpoint of the same tree size")
+		}
+		return nil
+	}
+	if rekorClient == nil {
+		return errors.New("rekor client not provided to prove consistency with the pinned checkpoint")
+	}
+
+	firstSize, lastSize := int64(older.Size), int64(newer.Size)
+	params := tlog.NewGetLogProofParamsWithContext(ctx).WithFirstSize(&firstSize).WithLastSize(lastSize)
+	// The origin is "<hostname> - <tree ID>", the tree ID selects the shard.
+	if i := strings.LastIndex(newer.Origin, " - "); i >= 0 {
+		treeID := newer.Origin[i+len(" - "):]
+		params = params.WithTreeID(&treeID)
+	}
+	resp, err := rekorClient.Tlog.GetLogProof(params)
+	if err != nil {
+		return fmt.Errorf("getting consistency proof: %w", err)
+	}
+	hashes := make([][]byte, 0, len(resp.Payload.Hashes))
+	for _, h := range resp.Payload.Hashes {
+		hb, err := hex.DecodeString(h)
+		if err != nil {
+			return fmt.Errorf("decoding consistency proof: %w", err)
+		}
+		hashes = append(hashes, hb)
+	}
+	if err := proof.VerifyConsistency(rfc6962.DefaultHasher, older.Size, newer.Size, hashes, older.Hash, newer.Hash); err != nil {
+		return fmt.Errorf("verifying consistency with the pinned checkpoint: %w", err)
+	}
+	return nil
+}
+
+// verifyPinnedCheckpoint verifies that e, whose inclusion proof has been
+// verified, is included in a log state consistent with the pinned checkpoint.
+func verifyPinnedCheckpoint(ctx context.Context, rekorClient *client.Rekor, rekorPubKeys *TrustedTransparencyLogPubKeys,
+	pinned *util.SignedCheckpoint, e *models.LogEntryAnon) error {
+	if err := verifyCheckpointSignature(pinned, rekorPubKeys); err != nil {
+		return fmt.Errorf("verifying pinned checkpoint: %w", err)
+	}
+	pubKey, ok := rekorPubKeys.Keys[*e.LogID]
+	if !ok {
+		return errors.New("rekor log public key not found for entry")
+	}
+	sth, err := verifyEntryCheckpoint(e, pubKey.PubKey)
+	if err != nil {
+		return fmt.Errorf("verifying checkpoint: %w", err)
+	}
+	return verifyCheckpointConsistency(ctx, rekorClient, pinned, sth)
+}
@@ -0,0 +1,168 @@
+//
+// Copyright 2023 The Sigstore Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cosign
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
+	"testing"
+
+	"github.com/go-openapi/swag"
+	"github.com/sigstore/rekor/pkg/generated/models"
+	"github.com/sigstore/rekor/pkg/util"
+	"github.com/sigstore/sigstore/pkg/signature"
+	"github.com/sigstore/sigstore/pkg/tuf"
+)
+
+func newTestCheckpoint(t *testing.T, signer signature.Signer, treeID int64, size uint64, root []byte) string {
+	t.Helper()
+	raw, err := util.CreateAndSignCheckpoint(context.Background(), "rekor.example.com", treeID, size, root, signer)
+	if err != nil {
+		t.Fatalf("creating checkpoint: %v", err)
+	}
+	return string(raw)
+}
+
+func newTestLogSigner(t *testing.T) (*ecdsa.PrivateKey, signature.Signer) {
+	t.Helper()
+	priv, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	signer, err := signature.LoadECDSASignerVerifier(priv, crypto.SHA256)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return priv, signer
+}
+
+func TestVerifyEntryCheckpoint(t *testing.T) {
+	logKey, logSigner := newTestLogSigner(t)
+	_, otherSigner := newTestLogSigner(t)
+	root := sha256.Sum256([]byte("root"))
+	otherRoot := sha256.Sum256([]byte("other root"))
+
+	entry := func(checkpoint string) *models.LogEntryAnon {
+		return &models.LogEntryAnon{
+			Verification: &models.LogEntryAnonVerification{
+				InclusionProof: &models.InclusionProof{
+					Checkpoint: swag.String(checkpoint),
+					RootHash:   swag.String(hex.EncodeToString(root[:])),
+					TreeSize:   swag.Int64(42),
+				},
+			},
+		}
+	}
+
+	tests := []struct {
+		name       string
+		checkpoint string
+		wantErr    string
+	}{{
+		name:       "valid",
+		checkpoint: newTestCheckpoint(t, logSigner, 1, 42, root[:]),
+	}, {
+		name:       "other root hash",
+		checkpoint: newTestCheckpoint(t, logSigner, 1, 42, otherRoot[:]),
+		wantErr:    "does not match the checkpoint",
+	}, {
+		name:       "other tree size",
+		checkpoint: newTestCheckpoint(t, logSigner, 1, 43, root[:]),
+		wantErr:    "does not match the checkpoint",
+	}, {
+		name:       "signed by another key",
+		checkpoint: newTestCheckpoint(t, otherSigner, 1, 42, root[:]),
+		wantErr:    "signature is invalid",
+	}, {
+		name:       "malformed",
+		checkpoint: "not a checkpoint",
+		wantErr:    "parsing checkpoint",
+	}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := verifyEntryCheckpoint(entry(tt.checkpoint), logKey.Public())
+			switch {
+			case tt.wantErr == "" && err != nil:
+				t.Errorf("verifyEntryCheckpoint() = %v", err)
+			case tt.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tt.wantErr)):
+				t.Errorf("verifyEntryCheckpoint() = %v, want error containing %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestVerifyCheckpointConsistency(t *testing.T) {
+	logKey, logSigner := newTestLogSigner(t)
+	root := sha256.Sum256([]byte("root"))
+	otherRoot := sha256.Sum256([]byte("other root"))
+
+	parse := func(raw string) *util.SignedCheckpoint {
+		sth, err := ParseCheckpoint([]byte(raw))
+		if err != nil {
+			t.Fatal(err)
+		}
+		return sth
+	}
+	pinned := parse(newTestCheckpoint(t, logSigner, 1, 42, root[:]))
+
+	pubKeys := NewTrustedTransparencyLogPubKeys()
+	pubKeys.Keys["log"] = TransparencyLogPubKey{PubKey: logKey.Public(), Status: tuf.Active}
+	if err := verifyCheckpointSignature(pinned, &pubKeys); err != nil {
+		t.Errorf("verifyCheckpointSignature() = %v", err)
+	}
+	_, otherSigner := newTestLogSigner(t)
+	if err := verifyCheckpointSignature(parse(newTestCheckpoint(t, otherSigner, 1, 42, root[:])), &pubKeys); err == nil {
+		t.Error("verifyCheckpointSignature() with an untrusted key succeeded")
+	}
+
+	tests := []struct {
+		name    string
+		sth     *util.SignedCheckpoint
+		wantErr string
+	}{{
+		name: "same tree head",
+		sth:  parse(newTestCheckpoint(t, logSigner, 1, 42, root[:])),
+	}, {
+		name:    "forked tree head",
+		sth:     parse(newTestCheckpoint(t, logSigner, 1, 42, otherRoot[:])),
+		wantErr: "differs from the pinned checkpoint",
+	}, {
+		name:    "other log",
+		sth:     parse(newTestCheckpoint(t, logSigner, 2, 42, root[:])),
+		wantErr: "not the pinned log",
+	}, {
+		name:    "larger tree without a client",
+		sth:     parse(newTestCheckpoint(t, logSigner, 1, 50, otherRoot[:])),
+		wantErr: "rekor client not provided",
+	}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := verifyCheckpointConsistency(context.Background(), nil, pinned, tt.sth)
+			switch {
+			case tt.wantErr == "" && err != nil:
+				t.Errorf("verifyCheckpointConsistency() = %v", err)
+			case tt.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tt.wantErr)):
+				t.Errorf("verifyCheckpointConsistency() = %v, want error containing %q", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -49,7 +49,9 @@ const (
 	VariablePassword         Variable = "COSIGN_PASSWORD"
 	VariablePKCS11Pin        Variable = "COSIGN_PKCS11_PIN"
 	VariablePKCS11ModulePath Variable = "COSIGN_PKCS11_MODULE_PATH"
+	VariableFIDO2Pin         Variable = "COSIGN_FIDO2_PIN"
 	VariableRepository       Variable = "COSIGN_REPOSITORY"
+	VariableConfig           Variable = "COSIGN_CONFIG"
 
 	// Sigstore environment variables
 	VariableSigstoreCTLogPublicKeyFile Variable = "SIGSTORE_CT_LOG_PUBLIC_KEY_FILE"
@@ -71,6 +73,14 @@ const (
 	VariableBuildkiteJobID            Variable = "BUILDKITE_JOB_ID"
 	VariableBuildkiteAgentLogLevel    Variable = "BUILDKITE_AGENT_LOG_LEVEL"
 	VariableSourceDateEpoch           Variable = "SOURCE_DATE_EPOCH"
+	VariableSSHAuthSock               Variable = "SSH_AUTH_SOCK"
+	VariableVaultNamespace            Variable = "VAULT_NAMESPACE"
+	VariableTransitSecretEnginePath   Variable = "TRANSIT_SECRET_ENGINE_PATH"
+	VariableDockerHost                Variable = "DOCKER_HOST"
+
+	// OpenTelemetry environment variables
+	VariableOTelExporterOTLPEndpoint       Variable = "OTEL_EXPORTER_OTLP_ENDPOINT"
+	VariableOTelExporterOTLPTracesEndpoint Variable = "OTEL_EXPORTER_OTLP_TRACES_ENDPOINT"
 )
 
 var (
@@ -102,11 +112,21 @@ var (
 			Expects:     "string with a module-path",
 			Sensitive:   false,
 		},
+		VariableFIDO2Pin: {
+			Description: "to be used if the FIDO2 security key PIN is not provided",
+			Expects:     "string with a PIN",
+			Sensitive:   true,
+		},
 		VariableRepository: {
 			Description: "can be used to store signatures in an alternate location",
 			Expects:     "string with a repository",
 			Sensitive:   false,
 		},
+		VariableConfig: {
+			Description: "overrides the path of the configuration file setting the default values of the flags",
+			Expects:     "string with a path to a YAML file (~/.cosign/config.yaml by default)",
+			Sensitive:   false,
+		},
 
 		VariableSigstoreCTLogPublicKeyFile: {
 			Description: "overrides what is used to validate the SCT coming back from Fulcio",
@@ -122,7 +142,7 @@ var (
 		},
 		VariableSigstoreRekorPublicKey: {
 			Description: "if specified, you can specify an oob Public Key that Rekor uses",
-			Expects:     "path to the public key",
+			Expects:     "path or URL of the PEM public key, or of several keys of a private Rekor instance",
 			Sensitive:   false,
 			External:    true,
 		},
@@ -211,6 +231,43 @@ var (
 			Sensitive:   false,
 			External:    true,
 		},
+		VariableSSHAuthSock: {
+			Description: "is the socket of the SSH agent used for ssh-agent:// keys",
+			Expects:     "path to the SSH agent socket",
+			Sensitive:   false,
+			External:    true,
+		},
+		VariableVaultNamespace: {
+			Description: "is the Vault enterprise namespace of hashivault:// keys",
+			Expects:     "string with a Vault namespace",
+			Sensitive:   false,
+			External:    true,
+		},
+		VariableTransitSecretEnginePath: {
+			Description: "is the path of the Vault transit secrets engine of hashivault:// keys",
+			Expects:     "string with a path (transit by default)",
+			Sensitive:   false,
+			External:    true,
+		},
+		VariableDockerHost: {
+			Description: "is the address of the Docker daemon that docker-daemon:// images are resolved with",
+			Expects:     "unix:// or tcp:// URL of the daemon (unix:///var/run/docker.sock by default)",
+			Sensitive:   false,
+			External:    true,
+		},
+
+		VariableOTelExporterOTLPEndpoint: {
+			Description: "enables the export of the OpenTelemetry traces of cosign to this OTLP/gRPC collector",
+			Expects:     "URL of the collector, e.g. http://localhost:4317",
+			Sensitive:   false,
+			External:    true,
+		},
+		VariableOTelExporterOTLPTracesEndpoint: {
+			Description: "like OTEL_EXPORTER_OTLP_ENDPOINT, for traces only",
+			Expects:     "URL of the collector, e.g. http://localhost:4317",
+			Sensitive:   false,
+			External:    true,
+		},
 	}
 )
 
@@ -43,10 +43,20 @@ type SignedPayload struct {
 	RFC3161Timestamp *bundle.RFC3161Timestamp
 }
 
+// LocalSignedPayload is the bundle written by sign-blob --bundle, holding
+// everything needed to verify the signature of a blob offline.
 type LocalSignedPayload struct {
-	Base64Signature string              `json:"base64Signature"`
-	Cert            string              `json:"cert,omitempty"`
-	Bundle          *bundle.RekorBundle `json:"rekorBundle,omitempty"`
+	Base64Signature string `json:"base64Signature"`
+	// Cert is the base64 encoded PEM certificate or public key of the signer.
+	Cert string `json:"cert,omitempty"`
+	// CertChain is the base64 encoded PEM chain of the certificate, from its
+	// issuer up to the root. The root is only trusted if it is one of the
+	// roots of the verifier.
+	CertChain string              `json:"certChain,omitempty"`
+	Bundle    *bundle.RekorBundle `json:"rekorBundle,omitempty"`
+	// PayloadDigest is the digest of the signed blob, e.g. sha256:abc...
+	PayloadDigest    string                   `json:"payloadDigest,omitempty"`
+	RFC3161Timestamp *bundle.RFC3161Timestamp `json:"rfc3161Timestamp,omitempty"`
 }
 
 type Signatures struct {
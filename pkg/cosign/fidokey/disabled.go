@@ -0,0 +1,34 @@
+//go:build !fidokey || !cgo
+// +build !fidokey !cgo
+
+//
+// Copyright 2023 The Sigstore Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package fidokey
+
+import (
+	"crypto"
+	"errors"
+)
+
+var errNotBuilt = errors.New("this cosign was not built with FIDO2 support, build it with -tags fidokey and cgo")
+
+func Enroll(rpID string) (crypto.PublicKey, error) { //nolint: revive
+	return nil, errNotBuilt
+}
+
+func GetKey(rpID string) (*Key, error) { //nolint: revive
+	return nil, errNotBuilt
+}
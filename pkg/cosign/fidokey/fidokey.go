@@ -0,0 +1,152 @@
+//go:build fidokey && cgo
+// +build fidokey,cgo
+
+//
+// Copyright 2023 The Sigstore Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package fidokey
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"os"
+	"syscall"
+
+	"github.com/keys-pub/go-libfido2"
+	"github.com/sigstore/cosign/v2/pkg/cosign/env"
+	"github.com/sigstore/sigstore/pkg/signature"
+	"golang.org/x/term"
+)
+
+// userID identifies the credentials enrolled by cosign, so that enrolling
+// a relying party again replaces its credential.
+var userID = []byte("cosign")
+
+// Enroll creates a resident credential with the hmac-secret extension for
+// rpID on the attached security key, protected with credProtect so that it
+// can't be used without the PIN, and returns the public key of the signing
+// key derived from it.
+func Enroll(rpID string) (crypto.PublicKey, error) {
+	dev, err := device()
+	if err != nil {
+		return nil, err
+	}
+	pin, err := getPin()
+	if err != nil {
+		return nil, err
+	}
+	clientDataHash, err := random()
+	if err != nil {
+		return nil, err
+	}
+	fmt.Fprintln(os.Stderr, "Please tap security key to enroll it...")
+	if _, err := dev.MakeCredential(clientDataHash,
+		libfido2.RelyingParty{ID: rpID, Name: "cosign"},
+		libfido2.User{ID: userID, Name: "cosign"},
+		libfido2.ES256, pin,
+		&libfido2.MakeCredentialOpts{
+			Extensions:  []libfido2.Extension{libfido2.HMACSecretExtension},
+			RK:          libfido2.True,
+			CredProtect: libfido2.CredProtectUVRequired,
+		}); err != nil {
+		return nil, fmt.Errorf("creating resident credential: %w", err)
+	}
+	priv, err := deriveKey(dev, rpID, pin)
+	if err != nil {
+		return nil, err
+	}
+	return priv.Public(), nil
+}
+
+// GetKey returns the signing key derived from the credential enrolled for
+// rpID on the attached security key, which must be tapped.
+func GetKey(rpID string) (*Key, error) {
+	dev, err := device()
+	if err != nil {
+		return nil, err
+	}
+	pin, err := getPin()
+	if err != nil {
+		return nil, err
+	}
+	priv, err := deriveKey(dev, rpID, pin)
+	if err != nil {
+		return nil, err
+	}
+	sv, err := signature.LoadECDSASignerVerifier(priv, crypto.SHA256)
+	if err != nil {
+		return nil, err
+	}
+	return &Key{SignerVerifier: sv}, nil
+}
+
+func device() (*libfido2.Device, error) {
+	locs, err := libfido2.DeviceLocations()
+	if err != nil {
+		return nil, err
+	}
+	if len(locs) == 0 {
+		return nil, errors.New("no FIDO2 security key found")
+	}
+	if len(locs) > 1 {
+		return nil, fmt.Errorf("found %d FIDO2 security keys, please attach only one", len(locs))
+	}
+	return libfido2.NewDevice(locs[0].Path)
+}
+
+// deriveKey asks the security key for the hmac-secret of hmacSalt, which is
+// only returned for the PIN, and derives the signing key from it.
+func deriveKey(dev *libfido2.Device, rpID, pin string) (*ecdsa.PrivateKey, error) {
+	clientDataHash, err := random()
+	if err != nil {
+		return nil, err
+	}
+	fmt.Fprintln(os.Stderr, "Please tap security key...")
+	assertion, err := dev.Assertion(rpID, clientDataHash, nil, pin, &libfido2.AssertionOpts{
+		Extensions: []libfido2.Extension{libfido2.HMACSecretExtension},
+		UP:         libfido2.True,
+		HMACSalt:   hmacSalt,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("getting hmac-secret of %s, enroll it with 'cosign generate-key-pair --fido': %w", rpID, err)
+	}
+	return DeriveKey(assertion.HMACSecret)
+}
+
+func random() ([]byte, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return nil, err
+	}
+	return b, nil
+}
+
+func getPin() (string, error) {
+	if pin, ok := env.LookupEnv(env.VariableFIDO2Pin); ok {
+		return pin, nil
+	}
+	fmt.Fprint(os.Stderr, "Enter PIN for security key: ")
+	// Unnecessary convert of syscall.Stdin on *nix, but Windows is a uintptr
+	// nolint:unconvert
+	b, err := term.ReadPassword(int(syscall.Stdin))
+	fmt.Fprintln(os.Stderr)
+	if err != nil {
+		return "", err
+	}
+	return string(b), nil
+}
@@ -0,0 +1,89 @@
+//
+// Copyright 2023 The Sigstore Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package fidokey signs with a key derived from the hmac-secret of a FIDO2
+// resident credential, so that inexpensive FIDO2 security keys without PIV
+// support can hold cosign signing keys. Talking to the security keys
+// requires building cosign with the fidokey tag and cgo.
+package fidokey
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/sha256"
+	"fmt"
+	"io"
+	"math/big"
+	"strings"
+
+	"github.com/sigstore/sigstore/pkg/signature"
+	"golang.org/x/crypto/hkdf"
+)
+
+const (
+	// ReferenceScheme selects the key enrolled for a relying party with
+	// 'cosign generate-key-pair --fido', e.g. fido2://sigstore.dev, or
+	// fido2:// for the DefaultRPID.
+	ReferenceScheme = "fido2://"
+	// DefaultRPID is the relying party of the keys enrolled without one.
+	DefaultRPID = "sigstore.dev"
+)
+
+// hmacSalt is the salt the security key computes the hmac-secret of. The
+// signing key is derived from that secret, so changing it changes the keys.
+var hmacSalt = func() []byte {
+	sum := sha256.Sum256([]byte("sigstore.dev/cosign/fido2-signing-key"))
+	return sum[:]
+}()
+
+// Key is the signing key derived from the hmac-secret of a FIDO2 resident
+// credential.
+type Key struct {
+	signature.SignerVerifier
+}
+
+// RPID returns the relying party of the key reference ref, see
+// ReferenceScheme.
+func RPID(ref string) string {
+	if rpID := strings.TrimPrefix(ref, ReferenceScheme); rpID != "" {
+		return rpID
+	}
+	return DefaultRPID
+}
+
+// DeriveKey derives the ECDSA P-256 signing key from the 32 bytes
+// hmac-secret a security key returned for hmacSalt. The same secret always
+// derives the same key.
+func DeriveKey(secret []byte) (*ecdsa.PrivateKey, error) {
+	if len(secret) != 32 {
+		return nil, fmt.Errorf("invalid hmac-secret of %d bytes", len(secret))
+	}
+	// As in FIPS 186-4 B.4.1, reduce 64 more bits than the order to a scalar
+	// in [1, n-1] without bias.
+	curve := elliptic.P256()
+	b := make([]byte, curve.Params().BitSize/8+8)
+	if _, err := io.ReadFull(hkdf.New(sha256.New, secret, hmacSalt, []byte("cosign ecdsa-p256")), b); err != nil {
+		return nil, err
+	}
+	n := new(big.Int).Sub(curve.Params().N, big.NewInt(1))
+	d := new(big.Int).SetBytes(b)
+	d.Mod(d, n)
+	d.Add(d, big.NewInt(1))
+
+	priv := &ecdsa.PrivateKey{D: d}
+	priv.PublicKey.Curve = curve
+	priv.PublicKey.X, priv.PublicKey.Y = curve.ScalarBaseMult(d.FillBytes(make([]byte, 32)))
+	return priv, nil
+}
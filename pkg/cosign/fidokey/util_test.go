@@ -0,0 +1,62 @@
+//
+// Copyright 2023 The Sigstore Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package fidokey
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestDeriveKey(t *testing.T) {
+	secret := bytes.Repeat([]byte{0x42}, 32)
+	k1, err := DeriveKey(secret)
+	if err != nil {
+		t.Fatal(err)
+	}
+	k2, err := DeriveKey(secret)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !k1.Equal(k2) {
+		t.Error("DeriveKey() of the same secret derived different keys")
+	}
+	if !k1.Curve.IsOnCurve(k1.X, k1.Y) {
+		t.Error("DeriveKey() public key is not on the curve")
+	}
+
+	other, err := DeriveKey(bytes.Repeat([]byte{0x43}, 32))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if k1.Equal(other) {
+		t.Error("DeriveKey() of different secrets derived the same key")
+	}
+
+	if _, err := DeriveKey([]byte("short")); err == nil {
+		t.Error("DeriveKey() of a short secret succeeded")
+	}
+}
+
+func TestRPID(t *testing.T) {
+	for ref, want := range map[string]string{
+		"fido2://":            DefaultRPID,
+		"fido2://release.key": "release.key",
+	} {
+		if got := RPID(ref); got != want {
+			t.Errorf("RPID(%q) = %q, want %q", ref, got, want)
+		}
+	}
+}
@@ -81,6 +81,23 @@ func ImportKeyPair(keyPath string, pf PassFunc) (*KeysBytes, error) {
 		return nil, err
 	}
 
+	switch {
+	case isAgeEncrypted(kb):
+		// The decrypted file holds one of the PEM keys below.
+		if kb, err = decryptAge(kb, pf); err != nil {
+			return nil, err
+		}
+	case isPGPPrivateKey(kb):
+		pgpPk, err := loadPGPPrivateKey(kb, pf)
+		if err != nil {
+			return nil, err
+		}
+		if err = cryptoutils.ValidatePubKey(pgpPk.Public()); err != nil {
+			return nil, fmt.Errorf("error validating openpgp key: %w", err)
+		}
+		return marshalKeyPair(SigstorePrivateKeyPemType, Keys{pgpPk, pgpPk.Public()}, pf)
+	}
+
 	p, _ := pem.Decode(kb)
 	if p == nil {
 		return nil, fmt.Errorf("invalid pem block")
@@ -191,6 +208,13 @@ func GenerateKeyPair(pf PassFunc) (*KeysBytes, error) {
 	return marshalKeyPair(SigstorePrivateKeyPemType, Keys{priv, priv.Public()}, pf)
 }
 
+// EncryptPrivateKey returns the key pair of priv, with its private key
+// encrypted with the password of pf like the keys of GenerateKeyPair, e.g. to
+// save the ephemeral key of a Fulcio certificate.
+func EncryptPrivateKey(priv crypto.Signer, pf PassFunc) (*KeysBytes, error) {
+	return marshalKeyPair(SigstorePrivateKeyPemType, Keys{priv, priv.Public()}, pf)
+}
+
 // TODO(jason): Move this to an internal package.
 func PemToECDSAKey(pemBytes []byte) (*ecdsa.PublicKey, error) {
 	pub, err := cryptoutils.UnmarshalPEMToPublicKey(pemBytes)
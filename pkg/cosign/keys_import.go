@@ -0,0 +1,240 @@
+//
+// Copyright 2023 The Sigstore Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cosign
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"golang.org/x/crypto/chacha20poly1305"
+	"golang.org/x/crypto/hkdf"
+	"golang.org/x/crypto/openpgp" //nolint:staticcheck // the only OpenPGP implementation among our dependencies
+	"golang.org/x/crypto/scrypt"
+)
+
+const (
+	// PGPPrivateKeyArmorType is the armor type of an OpenPGP private key.
+	PGPPrivateKeyArmorType = "PGP PRIVATE KEY BLOCK"
+	// AgeArmorPemType is the PEM type of an ASCII armored age file.
+	AgeArmorPemType = "AGE ENCRYPTED FILE"
+
+	ageVersionLine = "age-encryption.org/v1"
+	ageScryptLabel = "age-encryption.org/v1/scrypt"
+	// ageMaxWorkFactor is the highest scrypt work factor accepted, like age.
+	ageMaxWorkFactor = 22
+	ageChunkSize     = 64 * 1024
+)
+
+// isPGPPrivateKey reports whether kb is an ASCII armored OpenPGP private key.
+func isPGPPrivateKey(kb []byte) bool {
+	return bytes.HasPrefix(bytes.TrimSpace(kb), []byte("-----BEGIN "+PGPPrivateKeyArmorType+"-----"))
+}
+
+// loadPGPPrivateKey returns the primary key of an ASCII armored OpenPGP
+// private key, asking pf for the passphrase if it is encrypted.
+func loadPGPPrivateKey(kb []byte, pf PassFunc) (crypto.Signer, error) {
+	entities, err := openpgp.ReadArmoredKeyRing(bytes.NewReader(kb))
+	if err != nil {
+		return nil, fmt.Errorf("error parsing openpgp private key: %w", err)
+	}
+	if len(entities) != 1 || entities[0].PrivateKey == nil {
+		return nil, fmt.Errorf("expected a single openpgp private key, got %d keys", len(entities))
+	}
+	pk := entities[0].PrivateKey
+	if pk.Encrypted {
+		if pf == nil {
+			return nil, errors.New("openpgp private key is encrypted but no passphrase was provided")
+		}
+		pass, err := pf(false)
+		if err != nil {
+			return nil, err
+		}
+		if err := pk.Decrypt(pass); err != nil {
+			return nil, fmt.Errorf("error decrypting openpgp private key: %w", err)
+		}
+	}
+	signer, ok := pk.PrivateKey.(crypto.Signer)
+	if !ok {
+		return nil, fmt.Errorf("unsupported openpgp private key %T", pk.PrivateKey)
+	}
+	return signer, nil
+}
+
+// isAgeEncrypted reports whether kb is an age encrypted file, binary or
+// ASCII armored.
+func isAgeEncrypted(kb []byte) bool {
+	return bytes.HasPrefix(kb, []byte(ageVersionLine+"\n")) ||
+		bytes.HasPrefix(bytes.TrimSpace(kb), []byte("-----BEGIN "+AgeArmorPemType+"-----"))
+}
+
+// decryptAge decrypts an age file encrypted with a passphrase, which is
+// asked to pf. Files encrypted to age recipients are not supported.
+// See https://age-encryption.org/v1 for the format.
+func decryptAge(kb []byte, pf PassFunc) ([]byte, error) {
+	if p, _ := pem.Decode(kb); p != nil && p.Type == AgeArmorPemType {
+		kb = p.Bytes
+	}
+	errMalformed := errors.New("malformed age header")
+
+	rest := kb
+	nextLine := func() (string, error) {
+		i := bytes.IndexByte(rest, '\n')
+		if i < 0 {
+			return "", errMalformed
+		}
+		line := string(rest[:i])
+		rest = rest[i+1:]
+		return line, nil
+	}
+	b64 := base64.RawStdEncoding.Strict()
+
+	if l, err := nextLine(); err != nil || l != ageVersionLine {
+		return nil, errors.New("unsupported age version")
+	}
+	l, err := nextLine()
+	if err != nil {
+		return nil, err
+	}
+	args := strings.Split(strings.TrimPrefix(l, "-> "), " ")
+	if !strings.HasPrefix(l, "-> ") || args[0] != "scrypt" {
+		return nil, errors.New("only passphrase encrypted age files are supported")
+	}
+	if len(args) != 3 {
+		return nil, errMalformed
+	}
+	salt, err := b64.DecodeString(args[1])
+	if err != nil || len(salt) != 16 {
+		return nil, errMalformed
+	}
+	logN, err := strconv.Atoi(args[2])
+	if err != nil || strconv.Itoa(logN) != args[2] || logN <= 0 {
+		return nil, errMalformed
+	}
+	if logN > ageMaxWorkFactor {
+		return nil, fmt.Errorf("age scrypt work factor %d is too large", logN)
+	}
+	// The stanza body is 32 bytes, a single line of base64.
+	bodyLine, err := nextLine()
+	if err != nil {
+		return nil, err
+	}
+	body, err := b64.DecodeString(bodyLine)
+	if err != nil || len(body) != 32 {
+		return nil, errMalformed
+	}
+	// The MAC covers the header up to and including "---".
+	macStart := len(kb) - len(rest)
+	l, err = nextLine()
+	if err != nil {
+		return nil, err
+	}
+	if !strings.HasPrefix(l, "--- ") {
+		return nil, errors.New("only passphrase encrypted age files are supported, with a single recipient")
+	}
+	headerMAC, err := b64.DecodeString(l[4:])
+	if err != nil {
+		return nil, errMalformed
+	}
+	header := kb[:macStart+len("---")]
+
+	if pf == nil {
+		return nil, errors.New("age file is encrypted but no passphrase was provided")
+	}
+	pass, err := pf(false)
+	if err != nil {
+		return nil, err
+	}
+	key, err := scrypt.Key(pass, append([]byte(ageScryptLabel), salt...), 1<<logN, 8, 1, chacha20poly1305.KeySize)
+	if err != nil {
+		return nil, err
+	}
+	aead, err := chacha20poly1305.New(key)
+	if err != nil {
+		return nil, err
+	}
+	fileKey, err := aead.Open(nil, make([]byte, chacha20poly1305.NonceSize), body, nil)
+	if err != nil {
+		return nil, errors.New("incorrect age passphrase")
+	}
+
+	macKey, err := hkdfKey(fileKey, nil, "header")
+	if err != nil {
+		return nil, err
+	}
+	h := hmac.New(sha256.New, macKey)
+	h.Write(header)
+	if !hmac.Equal(h.Sum(nil), headerMAC) {
+		return nil, errors.New("bad age header MAC")
+	}
+
+	if len(rest) < 16 {
+		return nil, errors.New("truncated age payload")
+	}
+	payloadKey, err := hkdfKey(fileKey, rest[:16], "payload")
+	if err != nil {
+		return nil, err
+	}
+	if aead, err = chacha20poly1305.New(payloadKey); err != nil {
+		return nil, err
+	}
+	return openAgePayload(aead, rest[16:])
+}
+
+// openAgePayload decrypts the STREAM encrypted chunks of an age payload.
+func openAgePayload(aead cipher.AEAD, payload []byte) ([]byte, error) {
+	var plaintext []byte
+	nonce := make([]byte, chacha20poly1305.NonceSize)
+	for counter := uint64(0); ; counter++ {
+		chunk := payload
+		last := len(chunk) <= ageChunkSize+aead.Overhead()
+		if !last {
+			chunk = chunk[:ageChunkSize+aead.Overhead()]
+		}
+		payload = payload[len(chunk):]
+
+		binary.BigEndian.PutUint64(nonce[3:11], counter)
+		if last {
+			nonce[11] = 1
+		}
+		p, err := aead.Open(nil, nonce, chunk, nil)
+		if err != nil {
+			return nil, fmt.Errorf("decrypting age payload: %w", err)
+		}
+		plaintext = append(plaintext, p...)
+		if last {
+			return plaintext, nil
+		}
+	}
+}
+
+func hkdfKey(secret, salt []byte, info string) ([]byte, error) {
+	key := make([]byte, chacha20poly1305.KeySize)
+	if _, err := io.ReadFull(hkdf.New(sha256.New, secret, salt, []byte(info)), key); err != nil {
+		return nil, err
+	}
+	return key, nil
+}
@@ -423,9 +423,14 @@ func TestImportPrivateKey(t *testing.T) {
 		// Additional tests
 		{
 			fileName: "invalidkey.key",
-			pemData:  invalidkey,
+			pemData:  "not a key",
 			expected: errors.New("invalid pem block"),
 		},
+		{
+			fileName: "invalidpgp.key",
+			pemData:  invalidkey,
+			expected: errors.New("error decrypting openpgp private key: openpgp: invalid data: private key checksum failure"),
+		},
 	}
 	td := t.TempDir()
 
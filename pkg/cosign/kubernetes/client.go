@@ -26,7 +26,9 @@ import (
 	"k8s.io/client-go/tools/clientcmd"
 )
 
-func client() (kubernetes.Interface, error) {
+// Config returns the REST client config of the current kubeconfig context,
+// or of the cluster cosign runs in when there is no kubeconfig.
+func Config() (*rest.Config, error) {
 	cfg, err := clientcmd.NewNonInteractiveDeferredLoadingClientConfig(
 		clientcmd.NewDefaultClientConfigLoadingRules(), nil).ClientConfig()
 	if clientcmd.IsEmptyConfig(err) {
@@ -37,6 +39,14 @@ func client() (kubernetes.Interface, error) {
 	} else if err != nil {
 		return nil, fmt.Errorf("error creating REST client config: %w", err)
 	}
+	return cfg, nil
+}
+
+func client() (kubernetes.Interface, error) {
+	cfg, err := Config()
+	if err != nil {
+		return nil, err
+	}
 	return kubernetes.NewForConfig(cfg)
 }
 
@@ -0,0 +1,193 @@
+//
+// Copyright 2023 The Sigstore Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cosign
+
+import (
+	"context"
+	"crypto"
+	"crypto/x509"
+	"encoding/asn1"
+	"errors"
+	"fmt"
+
+	"github.com/sigstore/cosign/v2/pkg/oci"
+	"github.com/sigstore/sigstore/pkg/cryptoutils"
+	"github.com/sigstore/sigstore/pkg/signature"
+)
+
+// PIVAttestationAnnotationKey is the annotation of the signatures made with
+// a PIV hardware token, e.g. a Yubikey, holding the PEM encoded attestation
+// of the signing key: the key attestation certificate, then the device
+// attestation certificate that signed it, then its intermediates if any.
+const PIVAttestationAnnotationKey = "dev.sigstore.cosign/piv-attestation"
+
+// Extensions of the Yubico PIV attestation certificates, see
+// https://developers.yubico.com/PIV/Introduction/PIV_attestation.html
+var (
+	pivFirmwareVersionOID = asn1.ObjectIdentifier{1, 3, 6, 1, 4, 1, 41482, 3, 3}
+	pivSerialNumberOID    = asn1.ObjectIdentifier{1, 3, 6, 1, 4, 1, 41482, 3, 7}
+	pivPolicyOID          = asn1.ObjectIdentifier{1, 3, 6, 1, 4, 1, 41482, 3, 8}
+)
+
+// PIVAttestationPolicy, see CheckOpts.PIVAttestation, requires the signing
+// key to be attested as generated on a PIV hardware token.
+type PIVAttestationPolicy struct {
+	// Roots are the attestation root CAs of the token manufacturers, e.g.
+	// https://developers.yubico.com/PIV/Introduction/piv-attestation-ca.pem
+	Roots *x509.CertPool
+	// PINPolicies, if not empty, are the PIN policies allowed for the key:
+	// never, once or always.
+	PINPolicies []string
+	// TouchPolicies, if not empty, are the touch policies allowed for the
+	// key: never, always or cached.
+	TouchPolicies []string
+}
+
+// PIVAttestation is the attestation of a key generated on a PIV hardware
+// token.
+type PIVAttestation struct {
+	Serial      int64
+	Version     string
+	PINPolicy   string
+	TouchPolicy string
+}
+
+// ParsePIVAttestation verifies the PEM encoded attestation certificates of a
+// key, see PIVAttestationAnnotationKey, against roots, and returns the key
+// attestation certificate along with what it attests.
+func ParsePIVAttestation(pemBytes []byte, roots *x509.CertPool) (*x509.Certificate, *PIVAttestation, error) {
+	certs, err := cryptoutils.UnmarshalCertificatesFromPEM(pemBytes)
+	if err != nil {
+		return nil, nil, fmt.Errorf("parsing PIV attestation: %w", err)
+	}
+	if len(certs) < 2 {
+		return nil, nil, errors.New("PIV attestation must hold the key and device attestation certificates")
+	}
+	keyCert, deviceCert := certs[0], certs[1]
+
+	intermediates := x509.NewCertPool()
+	for _, c := range certs[2:] {
+		intermediates.AddCert(c)
+	}
+	if _, err := deviceCert.Verify(x509.VerifyOptions{
+		Roots:         roots,
+		Intermediates: intermediates,
+		KeyUsages:     []x509.ExtKeyUsage{x509.ExtKeyUsageAny},
+	}); err != nil {
+		return nil, nil, &VerificationFailure{fmt.Errorf("verifying PIV device attestation certificate: %w", err)}
+	}
+	// The device certificate isn't always marked as a CA, so the key
+	// certificate signature is checked without CheckSignatureFrom.
+	if err := deviceCert.CheckSignature(keyCert.SignatureAlgorithm, keyCert.RawTBSCertificate, keyCert.Signature); err != nil {
+		return nil, nil, &VerificationFailure{fmt.Errorf("PIV key attestation certificate isn't signed by the device: %w", err)}
+	}
+
+	a := &PIVAttestation{}
+	for _, ext := range keyCert.Extensions {
+		switch {
+		case ext.Id.Equal(pivFirmwareVersionOID):
+			if len(ext.Value) != 3 {
+				return nil, nil, fmt.Errorf("invalid PIV firmware version of %d bytes", len(ext.Value))
+			}
+			a.Version = fmt.Sprintf("%d.%d.%d", ext.Value[0], ext.Value[1], ext.Value[2])
+		case ext.Id.Equal(pivSerialNumberOID):
+			if _, err := asn1.Unmarshal(ext.Value, &a.Serial); err != nil {
+				return nil, nil, fmt.Errorf("parsing PIV serial number: %w", err)
+			}
+		case ext.Id.Equal(pivPolicyOID):
+			if len(ext.Value) != 2 {
+				return nil, nil, fmt.Errorf("invalid PIV policy of %d bytes", len(ext.Value))
+			}
+			a.PINPolicy = pivPINPolicy(ext.Value[0])
+			a.TouchPolicy = pivTouchPolicy(ext.Value[1])
+		}
+	}
+	if a.PINPolicy == "" {
+		return nil, nil, errors.New("PIV key attestation certificate has no PIN and touch policy")
+	}
+	return keyCert, a, nil
+}
+
+func pivPINPolicy(b byte) string {
+	switch b {
+	case 1:
+		return "never"
+	case 2:
+		return "once"
+	case 3:
+		return "always"
+	default:
+		return fmt.Sprintf("unknown (%d)", b)
+	}
+}
+
+func pivTouchPolicy(b byte) string {
+	switch b {
+	case 1:
+		return "never"
+	case 2:
+		return "always"
+	case 3:
+		return "cached"
+	default:
+		return fmt.Sprintf("unknown (%d)", b)
+	}
+}
+
+// checkPIVAttestation checks that sig was made with a key attested by its
+// PIVAttestationAnnotationKey annotation, with the policies p allows.
+func checkPIVAttestation(ctx context.Context, sig oci.Signature, verifyFn signatureVerificationFn, p *PIVAttestationPolicy) error {
+	annotations, err := sig.Annotations()
+	if err != nil {
+		return err
+	}
+	pemBytes, ok := annotations[PIVAttestationAnnotationKey]
+	if !ok {
+		return &VerificationFailure{errors.New("signature has no PIV attestation proving its key was generated on a hardware token")}
+	}
+	keyCert, a, err := ParsePIVAttestation([]byte(pemBytes), p.Roots)
+	if err != nil {
+		return err
+	}
+	// The signature was verified with a trusted key, verifying it with the
+	// attested key too proves they are the same.
+	attested, err := signature.LoadVerifier(keyCert.PublicKey, crypto.SHA256)
+	if err != nil {
+		return fmt.Errorf("loading PIV attested key: %w", err)
+	}
+	if err := verifyFn(ctx, attested, sig); err != nil {
+		return &VerificationFailure{fmt.Errorf("signature wasn't made with the PIV attested key: %w", err)}
+	}
+	if !allowedPolicy(p.PINPolicies, a.PINPolicy) {
+		return &VerificationFailure{fmt.Errorf("PIV key PIN policy %s is not one of %v", a.PINPolicy, p.PINPolicies)}
+	}
+	if !allowedPolicy(p.TouchPolicies, a.TouchPolicy) {
+		return &VerificationFailure{fmt.Errorf("PIV key touch policy %s is not one of %v", a.TouchPolicy, p.TouchPolicies)}
+	}
+	return nil
+}
+
+func allowedPolicy(allowed []string, policy string) bool {
+	if len(allowed) == 0 {
+		return true
+	}
+	for _, a := range allowed {
+		if a == policy {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,207 @@
+//
+// Copyright 2023 The Sigstore Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cosign
+
+import (
+	"bytes"
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"encoding/base64"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/sigstore/cosign/v2/pkg/oci"
+	"github.com/sigstore/cosign/v2/pkg/oci/static"
+	"github.com/sigstore/sigstore/pkg/cryptoutils"
+	"github.com/sigstore/sigstore/pkg/signature"
+)
+
+// pivToken is a fake PIV token: its attestation root, device certificate
+// and a key generated with the PIN and touch policies pin and touch.
+type pivToken struct {
+	roots       *x509.CertPool
+	attestation []byte
+	key         *ecdsa.PrivateKey
+}
+
+func newPIVToken(t *testing.T, pin, touch byte) *pivToken {
+	t.Helper()
+	newCert := func(template, parent *x509.Certificate, pub crypto.PublicKey, priv crypto.Signer) *x509.Certificate {
+		der, err := x509.CreateCertificate(rand.Reader, template, parent, pub, priv)
+		if err != nil {
+			t.Fatal(err)
+		}
+		cert, err := x509.ParseCertificate(der)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return cert
+	}
+	newKey := func() *ecdsa.PrivateKey {
+		k, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return k
+	}
+	now := time.Now()
+
+	rootKey := newKey()
+	rootTemplate := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "Yubico PIV Root CA"},
+		NotBefore:             now.Add(-time.Hour),
+		NotAfter:              now.Add(time.Hour),
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageCertSign,
+	}
+	root := newCert(rootTemplate, rootTemplate, rootKey.Public(), rootKey)
+
+	deviceKey := newKey()
+	device := newCert(&x509.Certificate{
+		SerialNumber:          big.NewInt(2),
+		Subject:               pkix.Name{CommonName: "Yubico PIV Attestation"},
+		NotBefore:             now.Add(-time.Hour),
+		NotAfter:              now.Add(time.Hour),
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageCertSign,
+	}, root, deviceKey.Public(), rootKey)
+
+	serial, err := asn1.Marshal(12345678)
+	if err != nil {
+		t.Fatal(err)
+	}
+	key := newKey()
+	keyCert := newCert(&x509.Certificate{
+		SerialNumber: big.NewInt(3),
+		Subject:      pkix.Name{CommonName: "YubiKey PIV Attestation 9c"},
+		NotBefore:    now.Add(-time.Hour),
+		NotAfter:     now.Add(time.Hour),
+		ExtraExtensions: []pkix.Extension{
+			{Id: pivFirmwareVersionOID, Value: []byte{5, 4, 3}},
+			{Id: pivSerialNumberOID, Value: serial},
+			{Id: pivPolicyOID, Value: []byte{pin, touch}},
+		},
+	}, device, key.Public(), deviceKey)
+
+	attestation, err := cryptoutils.MarshalCertificatesToPEM([]*x509.Certificate{keyCert, device})
+	if err != nil {
+		t.Fatal(err)
+	}
+	roots := x509.NewCertPool()
+	roots.AddCert(root)
+	return &pivToken{roots: roots, attestation: attestation, key: key}
+}
+
+// pivSignature returns a signature made with key, annotated with the PIV
+// attestation if not nil.
+func pivSignature(t *testing.T, key *ecdsa.PrivateKey, attestation []byte) oci.Signature {
+	t.Helper()
+	signer, err := signature.LoadECDSASignerVerifier(key, crypto.SHA256)
+	if err != nil {
+		t.Fatal(err)
+	}
+	payload := []byte(`{"critical":{}}`)
+	sig, err := signer.SignMessage(bytes.NewReader(payload))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var opts []static.Option
+	if attestation != nil {
+		opts = append(opts, static.WithAnnotations(map[string]string{PIVAttestationAnnotationKey: string(attestation)}))
+	}
+	ociSig, err := static.NewSignature(payload, base64.StdEncoding.EncodeToString(sig), opts...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return ociSig
+}
+
+func TestParsePIVAttestation(t *testing.T) {
+	tok := newPIVToken(t, 2, 3)
+	keyCert, a, err := ParsePIVAttestation(tok.attestation, tok.roots)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := cryptoutils.EqualKeys(keyCert.PublicKey, tok.key.Public()); err != nil {
+		t.Errorf("key attestation certificate: %v", err)
+	}
+	want := PIVAttestation{Serial: 12345678, Version: "5.4.3", PINPolicy: "once", TouchPolicy: "cached"}
+	if *a != want {
+		t.Errorf("ParsePIVAttestation() = %+v, want %+v", *a, want)
+	}
+
+	if _, _, err := ParsePIVAttestation(tok.attestation, newPIVToken(t, 2, 3).roots); err == nil {
+		t.Error("ParsePIVAttestation() of another manufacturer's attestation succeeded")
+	}
+}
+
+func TestCheckPIVAttestation(t *testing.T) {
+	tok := newPIVToken(t, 3, 2)
+	other := newPIVToken(t, 3, 2)
+
+	tests := []struct {
+		name    string
+		sig     oci.Signature
+		policy  PIVAttestationPolicy
+		wantErr bool
+	}{{
+		name:   "attested",
+		sig:    pivSignature(t, tok.key, tok.attestation),
+		policy: PIVAttestationPolicy{Roots: tok.roots},
+	}, {
+		name:   "allowed policies",
+		sig:    pivSignature(t, tok.key, tok.attestation),
+		policy: PIVAttestationPolicy{Roots: tok.roots, PINPolicies: []string{"always"}, TouchPolicies: []string{"cached", "always"}},
+	}, {
+		name:    "disallowed touch policy",
+		sig:     pivSignature(t, tok.key, tok.attestation),
+		policy:  PIVAttestationPolicy{Roots: tok.roots, TouchPolicies: []string{"cached"}},
+		wantErr: true,
+	}, {
+		name:    "no attestation",
+		sig:     pivSignature(t, tok.key, nil),
+		policy:  PIVAttestationPolicy{Roots: tok.roots},
+		wantErr: true,
+	}, {
+		name:    "attestation of another key",
+		sig:     pivSignature(t, other.key, tok.attestation),
+		policy:  PIVAttestationPolicy{Roots: tok.roots},
+		wantErr: true,
+	}, {
+		name:    "untrusted attestation",
+		sig:     pivSignature(t, other.key, other.attestation),
+		policy:  PIVAttestationPolicy{Roots: tok.roots},
+		wantErr: true,
+	}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := checkPIVAttestation(context.Background(), tt.sig, verifyOCISignature, &tt.policy)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("checkPIVAttestation() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
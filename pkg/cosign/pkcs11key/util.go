@@ -18,6 +18,7 @@ import (
 	"errors"
 	"fmt"
 	"net/url"
+	"os"
 	"strconv"
 	"strings"
 
@@ -138,6 +139,7 @@ func (conf *Pkcs11UriConfig) Parse(uriString string) error {
 	}
 	modulePath := uriQueryAttributes.Get("module-path")
 	pinValue := uriQueryAttributes.Get("pin-value")
+	pinSource := uriQueryAttributes.Get("pin-source")
 	tokenLabel := uriPathAttributes.Get("token")
 	slotIDStr := uriPathAttributes.Get("slot-id")
 	keyLabel := uriPathAttributes.Get("object")
@@ -162,6 +164,20 @@ func (conf *Pkcs11UriConfig) Parse(uriString string) error {
 		pin = pinValue
 	}
 
+	// pin-source, as defined in RFC 7512, names a file holding the PIN. It
+	// keeps the PIN out of the URI, which may end up in shell histories and logs.
+	if pinSource != "" {
+		if pinValue != "" {
+			return errors.New("invalid uri: only one of pin-value and pin-source can be set")
+		}
+		pinPath := strings.TrimPrefix(pinSource, "file:")
+		b, err := os.ReadFile(pinPath)
+		if err != nil {
+			return fmt.Errorf("reading pin-source: %w", err)
+		}
+		pin = strings.TrimRight(string(b), "\r\n")
+	}
+
 	// module-path should be specified and should point to the absolute path of the PKCS11 module.
 	// If it is not, COSIGN_PKCS11_MODULE_PATH environment variable must be set.
 	if modulePath == "" {
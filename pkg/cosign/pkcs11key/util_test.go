@@ -0,0 +1,77 @@
+//
+// Copyright 2023 The Sigstore Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pkcs11key
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestParsePinSource(t *testing.T) {
+	dir := t.TempDir()
+	pinFile := filepath.Join(dir, "pin")
+	if err := os.WriteFile(pinFile, []byte("1234\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	const base = "pkcs11:token=token;object=key?module-path=/path/to/libmodule.so"
+
+	tests := []struct {
+		name    string
+		uri     string
+		wantPin string
+		wantErr string
+	}{{
+		name:    "pin-value",
+		uri:     base + "&pin-value=5678",
+		wantPin: "5678",
+	}, {
+		name:    "pin-source path",
+		uri:     base + "&pin-source=" + pinFile,
+		wantPin: "1234",
+	}, {
+		name:    "pin-source file: URI",
+		uri:     base + "&pin-source=file:" + pinFile,
+		wantPin: "1234",
+	}, {
+		name:    "missing pin-source file",
+		uri:     base + "&pin-source=" + filepath.Join(dir, "missing"),
+		wantErr: "reading pin-source",
+	}, {
+		name:    "pin-value and pin-source",
+		uri:     base + "&pin-value=5678&pin-source=" + pinFile,
+		wantErr: "only one of pin-value and pin-source can be set",
+	}}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			conf := &Pkcs11UriConfig{}
+			err := conf.Parse(tc.uri)
+			if tc.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tc.wantErr) {
+					t.Fatalf("Parse() = %v, want error containing %q", err, tc.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Parse() = %v", err)
+			}
+			if conf.Pin != tc.wantPin {
+				t.Errorf("Pin = %q, want %q", conf.Pin, tc.wantPin)
+			}
+		})
+	}
+}
@@ -21,8 +21,12 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
+	"strings"
 
+	"github.com/open-policy-agent/opa/ast"
 	"github.com/open-policy-agent/opa/rego"
+	"github.com/open-policy-agent/opa/topdown"
 )
 
 // The query below should meet the following requirements:
@@ -43,14 +47,54 @@ type CosignRuleResult struct {
 	Result  bool   `json:"result,omitempty"`
 }
 
+// BundleExt is the extension of OPA bundle tarballs.
+const BundleExt = ".tar.gz"
+
+// IsBundle reports whether path is an OPA bundle tarball.
+func IsBundle(path string) bool {
+	return strings.HasSuffix(path, BundleExt)
+}
+
+// loadOptions returns the options loading entrypoints. OPA bundles are
+// loaded with their data and manifest, other files and directories with
+// rego.Load, which also loads .json and .yaml files as data documents.
+func loadOptions(entrypoints []string) []func(*rego.Rego) {
+	var opts []func(*rego.Rego)
+	var paths []string
+	for _, e := range entrypoints {
+		if IsBundle(e) {
+			opts = append(opts, rego.LoadBundle(e))
+			continue
+		}
+		paths = append(paths, e)
+	}
+	if len(paths) > 0 {
+		opts = append(opts, rego.Load(paths, nil))
+	}
+	return opts
+}
+
+// ValidateJSON evaluates the default QUERY against the jsonBody with the
+// policies loaded from entrypoints. Entrypoints are Rego files, OPA bundles,
+// JSON or YAML data documents, or directories of those files.
 func ValidateJSON(jsonBody []byte, entrypoints []string) []error {
+	return ValidateJSONWithQuery(jsonBody, entrypoints, QUERY)
+}
+
+// ValidateJSONWithQuery is like ValidateJSON but evaluates the given query,
+// e.g. data.cosign.attestation.allow, instead of the default QUERY. The query
+// must meet the same requirements as QUERY. When the input is denied, the
+// returned errors describe the values the query evaluated to.
+func ValidateJSONWithQuery(jsonBody []byte, entrypoints []string, query string) []error {
 	ctx := context.Background()
 
-	r := rego.New(
-		rego.Query(QUERY),
-		rego.Load(entrypoints, nil))
+	if query == "" {
+		query = QUERY
+	}
 
-	query, err := r.PrepareForEval(ctx)
+	r := rego.New(append(loadOptions(entrypoints), rego.Query(query))...)
+
+	prepared, err := r.PrepareForEval(ctx)
 	if err != nil {
 		return []error{err}
 	}
@@ -62,7 +106,7 @@ func ValidateJSON(jsonBody []byte, entrypoints []string) []error {
 		return []error{err}
 	}
 
-	rs, err := query.Eval(ctx, rego.EvalInput(input))
+	rs, err := prepared.Eval(ctx, rego.EvalInput(input))
 	if err != nil {
 		return []error{err}
 	}
@@ -78,16 +122,117 @@ func ValidateJSON(jsonBody []byte, entrypoints []string) []error {
 		for _, expression := range result.Expressions {
 			errs = append(errs, fmt.Errorf("expression value, %v, is not true", expression))
 		}
+		if len(result.Bindings) > 0 {
+			errs = append(errs, fmt.Errorf("query '%s' must not set bindings, got %v", query, result.Bindings))
+		}
 	}
 
 	// When rs.Allowed() is not true and len(rs) is 0, the result is undefined. This is a policy
 	// check failure.
 	if len(errs) == 0 {
-		errs = append(errs, fmt.Errorf("result is undefined for query '%s'", QUERY))
+		errs = append(errs, fmt.Errorf("result is undefined for query '%s'", query))
 	}
 	return errs
 }
 
+// Trace evaluates query, or QUERY when it is empty, against the jsonBody
+// with the policies loaded from entrypoints, like ValidateJSONWithQuery, and
+// writes the evaluation trace to w, e.g. to find out why a policy denies an
+// input.
+func Trace(w io.Writer, jsonBody []byte, entrypoints []string, query string) error {
+	ctx := context.Background()
+
+	if query == "" {
+		query = QUERY
+	}
+
+	r := rego.New(append(loadOptions(entrypoints), rego.Query(query))...)
+
+	prepared, err := r.PrepareForEval(ctx)
+	if err != nil {
+		return err
+	}
+
+	var input interface{}
+	dec := json.NewDecoder(bytes.NewBuffer(jsonBody))
+	dec.UseNumber()
+	if err := dec.Decode(&input); err != nil {
+		return err
+	}
+
+	tracer := topdown.NewBufferTracer()
+	if _, err := prepared.Eval(ctx, rego.EvalInput(input), rego.EvalQueryTracer(tracer)); err != nil {
+		return err
+	}
+	topdown.PrettyTraceWithLocation(w, *tracer)
+	return nil
+}
+
+// DenyRule is the name of the rule collecting deny messages, following the
+// deny[msg] convention, in the package evaluated by a query.
+const DenyRule = "deny"
+
+// DenyMessages evaluates the DenyRule of the package queried by query, e.g.
+// data.signature.deny for the default QUERY, and returns the messages it
+// produced. Each message is either a string or an object with a "msg" field.
+// No messages are returned when the package does not define the rule.
+func DenyMessages(jsonBody []byte, entrypoints []string, query string) ([]string, error) {
+	ctx := context.Background()
+
+	if query == "" {
+		query = QUERY
+	}
+	idx := strings.LastIndex(query, ".")
+	if idx == -1 {
+		return nil, fmt.Errorf("query '%s' does not reference a package", query)
+	}
+	denyQuery := query[:idx+1] + DenyRule
+
+	r := rego.New(append(loadOptions(entrypoints), rego.Query(denyQuery))...)
+
+	prepared, err := r.PrepareForEval(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var input interface{}
+	dec := json.NewDecoder(bytes.NewBuffer(jsonBody))
+	dec.UseNumber()
+	if err := dec.Decode(&input); err != nil {
+		return nil, err
+	}
+
+	rs, err := prepared.Eval(ctx, rego.EvalInput(input))
+	if err != nil {
+		return nil, err
+	}
+
+	var msgs []string
+	for _, result := range rs {
+		for _, expression := range result.Expressions {
+			values, ok := expression.Value.([]interface{})
+			if !ok {
+				continue
+			}
+			for _, v := range values {
+				switch msg := v.(type) {
+				case string:
+					msgs = append(msgs, msg)
+				case map[string]interface{}:
+					if m, ok := msg["msg"].(string); ok {
+						msgs = append(msgs, m)
+						continue
+					}
+					msgs = append(msgs, fmt.Sprint(msg))
+				default:
+					msgs = append(msgs, fmt.Sprint(msg))
+				}
+			}
+		}
+	}
+	return msgs, nil
+}
+
 // ValidateJSONWithModuleInput takes the body of the results to evaluate and the defined module
 // in a policy to validate against the input data
 func ValidateJSONWithModuleInput(jsonBody []byte, moduleInput string) (warnings error, errors error) {
@@ -130,6 +275,38 @@ func ValidateJSONWithModuleInput(jsonBody []byte, moduleInput string) (warnings
 	return nil, fmt.Errorf("policy is not compliant for query '%s'", query)
 }
 
+// untrustedBuiltin reports whether name is a builtin reaching out of the
+// evaluation, to the network or to the environment of the process.
+func untrustedBuiltin(name string) bool {
+	return name == "http.send" || name == "opa.runtime" || strings.HasPrefix(name, "net.")
+}
+
+// CheckUntrustedModule checks that moduleInput, a module of the form
+// evaluated by ValidateJSONWithModuleInput supplied by an untrusted party,
+// only uses builtins that can't reach the network or read the environment of
+// the process, e.g. http.send or opa.runtime.
+func CheckUntrustedModule(moduleInput string) error {
+	caps := ast.CapabilitiesForThisVersion()
+	builtins := make([]*ast.Builtin, 0, len(caps.Builtins))
+	for _, b := range caps.Builtins {
+		if !untrustedBuiltin(b.Name) {
+			builtins = append(builtins, b)
+		}
+	}
+	caps.Builtins = builtins
+	caps.AllowNet = []string{}
+
+	query := fmt.Sprintf("%s = data.%s.%s", CosignEvaluationRule, CosignRegoPackageName, CosignEvaluationRule)
+	r := rego.New(
+		rego.Query(query),
+		rego.Module(fmt.Sprintf("%s.rego", CosignRegoPackageName), moduleInput),
+		rego.Capabilities(caps))
+	if _, err := r.PrepareForEval(context.Background()); err != nil {
+		return fmt.Errorf("checking untrusted policy: %w", err)
+	}
+	return nil
+}
+
 func evaluateRegoEvalMapResult(query string, response []interface{}) (warning error, error error) {
 	error = fmt.Errorf("policy is not compliant for query %q", query) //nolint: revive
 	for _, r := range response {
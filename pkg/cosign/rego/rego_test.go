@@ -16,8 +16,12 @@
 package rego
 
 import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
 	"fmt"
 	"os"
+	"strings"
 	"testing"
 )
 
@@ -99,6 +103,65 @@ func TestValidationJSON(t *testing.T) {
 	}
 }
 
+func TestValidationJSONWithQuery(t *testing.T) {
+	policy := `
+		package cosign.attestation
+
+		default allow = false
+
+		allow {
+			input.predicateType == "https://slsa.dev/provenance/v0.2"
+		}
+	`
+	cases := []struct {
+		name   string
+		query  string
+		pass   bool
+		errors []string
+	}{
+		{
+			name:  "custom query allows",
+			query: "data.cosign.attestation.allow",
+			pass:  true,
+		},
+		{
+			name:   "default query is undefined for custom package",
+			query:  "",
+			pass:   false,
+			errors: []string{"result is undefined for query 'data.signature.allow'"},
+		},
+		{
+			name:   "custom query undefined rule",
+			query:  "data.cosign.attestation.deny",
+			pass:   false,
+			errors: []string{"result is undefined for query 'data.cosign.attestation.deny'"},
+		},
+	}
+
+	policyFileName := "tmp-query-policy.rego"
+	if err := os.WriteFile(policyFileName, []byte(policy), 0644); err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(policyFileName)
+
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			errs := ValidateJSONWithQuery([]byte(simpleJSONBody), []string{policyFileName}, tt.query)
+			if (errs == nil) != tt.pass {
+				t.Fatalf("Unexpected result: %v", errs)
+			}
+			if len(errs) != len(tt.errors) {
+				t.Fatalf("Expected %d errors, got %d errors: %v", len(tt.errors), len(errs), errs)
+			}
+			for i, err := range errs {
+				if err.Error() != tt.errors[i] {
+					t.Errorf("Expected error %q, got %q", tt.errors[i], err)
+				}
+			}
+		})
+	}
+}
+
 const attestationsJSONBody = `{
 	"authorityMatches": {
 	  "keyatt": {
@@ -240,3 +303,166 @@ func TestValidateJSONWithModuleInput(t *testing.T) {
 		})
 	}
 }
+
+// writeBundle writes an OPA bundle tarball of files to a relative path.
+func writeBundle(t *testing.T, name string, files map[string]string) string {
+	t.Helper()
+	var buf bytes.Buffer
+	gw := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(gw)
+	for path, body := range files {
+		if err := tw.WriteHeader(&tar.Header{Name: path, Mode: 0644, Size: int64(len(body))}); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := tw.Write([]byte(body)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := tw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := gw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(name, buf.Bytes(), 0644); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.Remove(name) })
+	return name
+}
+
+func TestCheckUntrustedModule(t *testing.T) {
+	cases := []struct {
+		name   string
+		policy string
+		pass   bool
+	}{{
+		name: "input only",
+		policy: `
+			package sigstore
+			default isCompliant = false
+			isCompliant {
+				input.predicateType == "https://slsa.dev/provenance/v0.2"
+			}
+		`,
+		pass: true,
+	}, {
+		name: "http.send",
+		policy: `
+			package sigstore
+			default isCompliant = false
+			isCompliant {
+				http.send({"method": "get", "url": "http://169.254.169.254/latest/meta-data/"}).status_code == 200
+			}
+		`,
+	}, {
+		name: "opa.runtime",
+		policy: `
+			package sigstore
+			default isCompliant = false
+			isCompliant {
+				opa.runtime().env.AWS_SECRET_ACCESS_KEY == ""
+			}
+		`,
+	}, {
+		name: "net.lookup_ip_addr",
+		policy: `
+			package sigstore
+			default isCompliant = false
+			isCompliant {
+				count(net.lookup_ip_addr("internal.example.com")) > 0
+			}
+		`,
+	}}
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			err := CheckUntrustedModule(tt.policy)
+			if (err == nil) != tt.pass {
+				t.Errorf("CheckUntrustedModule() = %v, want pass %v", err, tt.pass)
+			}
+		})
+	}
+}
+
+func TestValidateJSONWithData(t *testing.T) {
+	policy := `
+package signature
+
+default allow = false
+
+allow {
+	input.predicateType == data.allowed_predicate_types[_]
+}
+`
+	policyFileName := "tmp-data-policy.rego"
+	if err := os.WriteFile(policyFileName, []byte(policy), 0644); err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(policyFileName)
+
+	allowedFileName := "tmp-allowed.json"
+	if err := os.WriteFile(allowedFileName, []byte(`{"allowed_predicate_types": ["https://slsa.dev/provenance/v0.2"]}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(allowedFileName)
+
+	deniedFileName := "tmp-denied.yaml"
+	if err := os.WriteFile(deniedFileName, []byte("allowed_predicate_types:\n- https://slsa.dev/provenance/v1\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(deniedFileName)
+
+	bundle := writeBundle(t, "tmp-bundle.tar.gz", map[string]string{
+		"policy.rego": policy,
+		"data.json":   `{"allowed_predicate_types": ["https://slsa.dev/provenance/v0.2"]}`,
+	})
+
+	cases := []struct {
+		name        string
+		entrypoints []string
+		pass        bool
+	}{
+		{name: "data document", entrypoints: []string{policyFileName, allowedFileName}, pass: true},
+		{name: "yaml data document", entrypoints: []string{policyFileName, deniedFileName}},
+		{name: "missing data", entrypoints: []string{policyFileName}},
+		{name: "bundle", entrypoints: []string{bundle}, pass: true},
+	}
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			errs := ValidateJSON([]byte(simpleJSONBody), tt.entrypoints)
+			if (errs == nil) != tt.pass {
+				t.Errorf("ValidateJSON() = %v, pass %v", errs, tt.pass)
+			}
+		})
+	}
+}
+
+func TestTrace(t *testing.T) {
+	policy := `
+package signature
+
+default allow = false
+
+allow {
+	startswith(input.predicateType, "https://slsa.dev/provenance/v1")
+}
+`
+	policyFileName := "tmp-trace-policy.rego"
+	if err := os.WriteFile(policyFileName, []byte(policy), 0644); err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(policyFileName)
+
+	var buf bytes.Buffer
+	if err := Trace(&buf, []byte(simpleJSONBody), []string{policyFileName}, ""); err != nil {
+		t.Fatal(err)
+	}
+	// The trace shows the failing expression of the allow rule. A function
+	// call is used since OPA skips rules whose equalities do not match the
+	// input without evaluating them.
+	for _, want := range []string{"Fail", `"https://slsa.dev/provenance/v1"`} {
+		if !strings.Contains(buf.String(), want) {
+			t.Errorf("trace does not contain %s:\n%s", want, buf.String())
+		}
+	}
+}
@@ -127,32 +127,9 @@ func (r *ro) Replace(signatures oci.Signatures, o oci.Signature) (oci.Signatures
 	}
 
 	for _, s := range sigs {
-		var signaturePayload map[string]interface{}
-		p, err := s.Payload()
+		val, err := attestationPredicateType(s)
 		if err != nil {
-			return nil, fmt.Errorf("could not get payload: %w", err)
-		}
-		err = json.Unmarshal(p, &signaturePayload)
-		if err != nil {
-			return nil, fmt.Errorf("unmarshal payload data: %w", err)
-		}
-
-		val, ok := signaturePayload["payload"]
-		if !ok {
-			return nil, fmt.Errorf("could not find 'payload' in payload data")
-		}
-		decodedPayload, err := base64.StdEncoding.DecodeString(val.(string))
-		if err != nil {
-			return nil, fmt.Errorf("could not decode 'payload': %w", err)
-		}
-
-		var payloadData map[string]interface{}
-		if err := json.Unmarshal(decodedPayload, &payloadData); err != nil {
-			return nil, fmt.Errorf("unmarshal payloadData: %w", err)
-		}
-		val, ok = payloadData["predicateType"]
-		if !ok {
-			return nil, fmt.Errorf("could not find 'predicateType' in payload data")
+			return nil, err
 		}
 		if r.predicateURI == val {
 			fmt.Fprintln(os.Stderr, "Replacing attestation predicate:", r.predicateURI)
@@ -168,6 +145,67 @@ func (r *ro) Replace(signatures oci.Signatures, o oci.Signature) (oci.Signatures
 	return ros, nil
 }
 
+// RemoveAttestations returns the attestations in signatures whose predicate
+// type is not predicateURI, along with the number of attestations removed.
+func RemoveAttestations(signatures oci.Signatures, predicateURI string) (oci.Signatures, int, error) {
+	sigs, err := signatures.Get()
+	if err != nil {
+		return nil, 0, err
+	}
+
+	ros := &replaceOCISignatures{Signatures: signatures}
+	removed := 0
+	for _, s := range sigs {
+		val, err := attestationPredicateType(s)
+		if err != nil {
+			return nil, 0, err
+		}
+		if predicateURI == val {
+			removed++
+			continue
+		}
+		ros.attestations = append(ros.attestations, s)
+	}
+	return ros, removed, nil
+}
+
+// attestationPredicateType returns the predicate type of the in-toto
+// statement wrapped in the DSSE envelope of the attestation.
+func attestationPredicateType(s oci.Signature) (string, error) {
+	var signaturePayload map[string]interface{}
+	p, err := s.Payload()
+	if err != nil {
+		return "", fmt.Errorf("could not get payload: %w", err)
+	}
+	err = json.Unmarshal(p, &signaturePayload)
+	if err != nil {
+		return "", fmt.Errorf("unmarshal payload data: %w", err)
+	}
+
+	val, ok := signaturePayload["payload"]
+	if !ok {
+		return "", fmt.Errorf("could not find 'payload' in payload data")
+	}
+	decodedPayload, err := base64.StdEncoding.DecodeString(val.(string))
+	if err != nil {
+		return "", fmt.Errorf("could not decode 'payload': %w", err)
+	}
+
+	var payloadData map[string]interface{}
+	if err := json.Unmarshal(decodedPayload, &payloadData); err != nil {
+		return "", fmt.Errorf("unmarshal payloadData: %w", err)
+	}
+	val, ok = payloadData["predicateType"]
+	if !ok {
+		return "", fmt.Errorf("could not find 'predicateType' in payload data")
+	}
+	predicateType, ok := val.(string)
+	if !ok {
+		return "", fmt.Errorf("'predicateType' is not a string")
+	}
+	return predicateType, nil
+}
+
 type replaceOCISignatures struct {
 	oci.Signatures
 	attestations []oci.Signature
@@ -0,0 +1,99 @@
+//
+// Copyright 2023 The Sigstore Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package remote
+
+import (
+	"encoding/base64"
+	"fmt"
+	"testing"
+
+	"github.com/sigstore/cosign/v2/pkg/oci"
+	"github.com/sigstore/cosign/v2/pkg/oci/empty"
+	"github.com/sigstore/cosign/v2/pkg/oci/mutate"
+	"github.com/sigstore/cosign/v2/pkg/oci/static"
+)
+
+func mustAttestation(t *testing.T, predicateType string) oci.Signature {
+	t.Helper()
+	statement := fmt.Sprintf(`{"_type":"https://in-toto.io/Statement/v0.1","predicateType":%q,"predicate":{}}`, predicateType)
+	envelope := fmt.Sprintf(`{"payloadType":"application/vnd.in-toto+json","payload":%q,"signatures":[]}`,
+		base64.StdEncoding.EncodeToString([]byte(statement)))
+	att, err := static.NewAttestation([]byte(envelope))
+	if err != nil {
+		t.Fatalf("static.NewAttestation() = %v", err)
+	}
+	return att
+}
+
+func TestRemoveAttestations(t *testing.T) {
+	atts, err := mutate.AppendSignatures(empty.Signatures(),
+		mustAttestation(t, "https://slsa.dev/provenance/v0.2"),
+		mustAttestation(t, "https://cyclonedx.org/bom"),
+		mustAttestation(t, "https://slsa.dev/provenance/v0.2"),
+	)
+	if err != nil {
+		t.Fatalf("AppendSignatures() = %v", err)
+	}
+
+	tests := []struct {
+		name          string
+		predicateType string
+		wantRemoved   int
+		wantRemaining int
+	}{{
+		name:          "removes every attestation of the type",
+		predicateType: "https://slsa.dev/provenance/v0.2",
+		wantRemoved:   2,
+		wantRemaining: 1,
+	}, {
+		name:          "keeps attestations of other types",
+		predicateType: "https://cyclonedx.org/bom",
+		wantRemoved:   1,
+		wantRemaining: 2,
+	}, {
+		name:          "no matching type",
+		predicateType: "https://spdx.dev/Document",
+		wantRemoved:   0,
+		wantRemaining: 3,
+	}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			remaining, removed, err := RemoveAttestations(atts, tt.predicateType)
+			if err != nil {
+				t.Fatalf("RemoveAttestations() = %v", err)
+			}
+			if removed != tt.wantRemoved {
+				t.Errorf("removed = %d, wanted %d", removed, tt.wantRemoved)
+			}
+			got, err := remaining.Get()
+			if err != nil {
+				t.Fatalf("Get() = %v", err)
+			}
+			if len(got) != tt.wantRemaining {
+				t.Fatalf("remaining = %d, wanted %d", len(got), tt.wantRemaining)
+			}
+			for _, att := range got {
+				pt, err := attestationPredicateType(att)
+				if err != nil {
+					t.Fatalf("attestationPredicateType() = %v", err)
+				}
+				if pt == tt.predicateType {
+					t.Errorf("attestation of type %s was not removed", pt)
+				}
+			}
+		})
+	}
+}
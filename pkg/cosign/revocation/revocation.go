@@ -0,0 +1,295 @@
+//
+// Copyright 2023 The Sigstore Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package revocation implements revocation lists: documents signed by a
+// trusted key that list the image digests and the signing keys whose
+// signatures must no longer be trusted, so that compromised signatures can be
+// revoked without deleting them from the registry.
+package revocation
+
+import (
+	"bytes"
+	"context"
+	"crypto"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/go-containerregistry/pkg/name"
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
+	"github.com/secure-systems-lab/go-securesystemslib/cjson"
+	"github.com/sigstore/cosign/v2/pkg/cosign/rootpolicy"
+	"github.com/sigstore/cosign/v2/pkg/oci/static"
+	"github.com/sigstore/cosign/v2/pkg/types"
+	"github.com/sigstore/sigstore/pkg/signature"
+)
+
+const (
+	// ListType identifies the revocation list format.
+	ListType = "https://sigstore.dev/cosign/revocation-list/v1"
+	// RegistryPrefix marks the sources of revocation lists stored in a
+	// registry, e.g. oci://ghcr.io/myorg/revocations:latest.
+	RegistryPrefix = "oci://"
+
+	// maxListSize bounds the size of the revocation lists fetched.
+	maxListSize = 16 << 20
+)
+
+// List lists the revoked image digests and keys.
+type List struct {
+	Type    string    `json:"_type"`
+	Issued  time.Time `json:"issued"`
+	Expires time.Time `json:"expires"`
+	// Digests are the revoked image digests, e.g. sha256:abc...
+	Digests []string `json:"digests,omitempty"`
+	// Keys are the key IDs of the revoked public keys, see rootpolicy.KeyID.
+	Keys []string `json:"keys,omitempty"`
+}
+
+// SignedList is a revocation list with the signatures of its publishers.
+type SignedList struct {
+	Signed     List                   `json:"signed"`
+	Signatures []rootpolicy.Signature `json:"signatures"`
+}
+
+// New returns an unsigned revocation list of the digests and key IDs, issued
+// at issued.
+func New(digests, keys []string, issued, expires time.Time) (*SignedList, error) {
+	l := List{
+		Type:    ListType,
+		Issued:  issued.UTC().Truncate(time.Second),
+		Expires: expires.UTC().Truncate(time.Second),
+	}
+	if !l.Expires.After(l.Issued) {
+		return nil, fmt.Errorf("revocation list must expire after it is issued, at %s", l.Issued.Format(time.RFC3339))
+	}
+	for _, d := range digests {
+		if _, err := v1.NewHash(d); err != nil {
+			return nil, fmt.Errorf("invalid digest %q: %w", d, err)
+		}
+	}
+	for _, k := range keys {
+		if b, err := hex.DecodeString(k); err != nil || len(b) != 32 {
+			return nil, fmt.Errorf("invalid key ID %q, expected the hex encoded SHA256 digest of a public key", k)
+		}
+	}
+	l.Digests = dedupe(digests)
+	l.Keys = dedupe(keys)
+	return &SignedList{Signed: l, Signatures: []rootpolicy.Signature{}}, nil
+}
+
+func dedupe(values []string) []string {
+	if len(values) == 0 {
+		return nil
+	}
+	seen := make(map[string]bool, len(values))
+	var out []string
+	for _, v := range values {
+		if !seen[v] {
+			seen[v] = true
+			out = append(out, v)
+		}
+	}
+	sort.Strings(out)
+	return out
+}
+
+// Sign adds the signature of signer, replacing any previous signature of the
+// same key.
+func (sl *SignedList) Sign(signer signature.Signer) error {
+	pub, err := signer.PublicKey()
+	if err != nil {
+		return err
+	}
+	id, err := rootpolicy.KeyID(pub)
+	if err != nil {
+		return err
+	}
+	msg, err := cjson.EncodeCanonical(sl.Signed)
+	if err != nil {
+		return err
+	}
+	sig, err := signer.SignMessage(bytes.NewReader(msg))
+	if err != nil {
+		return err
+	}
+
+	signatures := []rootpolicy.Signature{{KeyID: id, Sig: base64.StdEncoding.EncodeToString(sig)}}
+	for _, s := range sl.Signatures {
+		if s.KeyID != id {
+			signatures = append(signatures, s)
+		}
+	}
+	sort.Slice(signatures, func(i, j int) bool { return signatures[i].KeyID < signatures[j].KeyID })
+	sl.Signatures = signatures
+	return nil
+}
+
+// Verify checks that sl is a revocation list signed by verifier that has not
+// expired at now.
+func (sl *SignedList) Verify(verifier signature.Verifier, now time.Time) error {
+	l := sl.Signed
+	if l.Type != ListType {
+		return fmt.Errorf("unsupported revocation list type %q", l.Type)
+	}
+	if now.After(l.Expires) {
+		return fmt.Errorf("revocation list expired at %s", l.Expires.Format(time.RFC3339))
+	}
+
+	pub, err := verifier.PublicKey()
+	if err != nil {
+		return err
+	}
+	id, err := rootpolicy.KeyID(pub)
+	if err != nil {
+		return err
+	}
+	msg, err := cjson.EncodeCanonical(l)
+	if err != nil {
+		return err
+	}
+	for _, s := range sl.Signatures {
+		if s.KeyID != id {
+			continue
+		}
+		sig, err := base64.StdEncoding.DecodeString(s.Sig)
+		if err != nil {
+			continue
+		}
+		if verifier.VerifySignature(bytes.NewReader(sig), bytes.NewReader(msg)) == nil {
+			return nil
+		}
+	}
+	return fmt.Errorf("revocation list is not signed by key %s", id)
+}
+
+// DigestRevoked reports whether the image digest, e.g. sha256:abc..., is
+// revoked.
+func (l *List) DigestRevoked(digest string) bool {
+	return contains(l.Digests, digest)
+}
+
+// KeyRevoked reports whether pub is revoked.
+func (l *List) KeyRevoked(pub crypto.PublicKey) (bool, error) {
+	id, err := rootpolicy.KeyID(pub)
+	if err != nil {
+		return false, err
+	}
+	return contains(l.Keys, id), nil
+}
+
+func contains(values []string, v string) bool {
+	for _, s := range values {
+		if s == v {
+			return true
+		}
+	}
+	return false
+}
+
+// Parse parses a signed revocation list, without verifying it.
+func Parse(b []byte) (*SignedList, error) {
+	sl := &SignedList{}
+	if err := json.Unmarshal(b, sl); err != nil {
+		return nil, err
+	}
+	return sl, nil
+}
+
+// Fetch reads the revocation list at source, a registry reference prefixed
+// with RegistryPrefix, an http(s) URL or a file, without verifying it. The
+// encoded list is returned along with it.
+func Fetch(ctx context.Context, source string, opts ...remote.Option) (*SignedList, []byte, error) {
+	var b []byte
+	var err error
+	switch {
+	case strings.HasPrefix(source, RegistryPrefix):
+		b, err = fetchRegistry(strings.TrimPrefix(source, RegistryPrefix), opts...)
+	case strings.HasPrefix(source, "https://"), strings.HasPrefix(source, "http://"):
+		b, err = fetchURL(ctx, source)
+	default:
+		b, err = os.ReadFile(filepath.Clean(source))
+	}
+	if err != nil {
+		return nil, nil, fmt.Errorf("fetching revocation list %s: %w", source, err)
+	}
+	sl, err := Parse(b)
+	if err != nil {
+		return nil, nil, fmt.Errorf("parsing revocation list %s: %w", source, err)
+	}
+	return sl, b, nil
+}
+
+func fetchRegistry(s string, opts ...remote.Option) ([]byte, error) {
+	ref, err := name.ParseReference(s)
+	if err != nil {
+		return nil, err
+	}
+	img, err := remote.Image(ref, opts...)
+	if err != nil {
+		return nil, err
+	}
+	layers, err := img.Layers()
+	if err != nil {
+		return nil, err
+	}
+	if len(layers) != 1 {
+		return nil, fmt.Errorf("%d layers, expected 1", len(layers))
+	}
+	rc, err := layers[0].Uncompressed()
+	if err != nil {
+		return nil, err
+	}
+	defer rc.Close()
+	return io.ReadAll(io.LimitReader(rc, maxListSize))
+}
+
+func fetchURL(ctx context.Context, url string) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status %s", resp.Status)
+	}
+	return io.ReadAll(io.LimitReader(resp.Body, maxListSize))
+}
+
+// Upload stores the revocation list in the registry at ref.
+func Upload(ref name.Reference, sl *SignedList, opts ...remote.Option) error {
+	b, err := json.MarshalIndent(sl, "", "  ")
+	if err != nil {
+		return err
+	}
+	img, err := static.NewFile(b, static.WithLayerMediaType(types.RevocationListLayerMediaType), static.WithConfigMediaType(types.RevocationListConfigMediaType))
+	if err != nil {
+		return err
+	}
+	return remote.Write(ref, img, opts...)
+}
@@ -0,0 +1,206 @@
+//
+// Copyright 2023 The Sigstore Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package revocation
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/sigstore/cosign/v2/pkg/cosign/rootpolicy"
+	"github.com/sigstore/sigstore/pkg/signature"
+)
+
+const digest = "sha256:6f8d3c36be2e2a7b3ee43cf9b1bb8ec47a0f6c3e5b8b8ee83c9fae15e0a8d1b7"
+
+func newSigner(t *testing.T) signature.SignerVerifier {
+	t.Helper()
+	priv, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sv, err := signature.LoadECDSASignerVerifier(priv, crypto.SHA256)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return sv
+}
+
+func keyID(t *testing.T, sv signature.SignerVerifier) string {
+	t.Helper()
+	pub, err := sv.PublicKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	id, err := rootpolicy.KeyID(pub)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return id
+}
+
+func TestSignedList(t *testing.T) {
+	publisher, compromised, mallory := newSigner(t), newSigner(t), newSigner(t)
+	now := time.Now()
+
+	tests := []struct {
+		name    string
+		signer  signature.SignerVerifier
+		modify  func(sl *SignedList)
+		now     time.Time
+		wantErr string
+	}{{
+		name:   "signed",
+		signer: publisher,
+	}, {
+		name:    "other key",
+		signer:  mallory,
+		wantErr: "not signed by key",
+	}, {
+		name:    "expired",
+		signer:  publisher,
+		now:     now.Add(48 * time.Hour),
+		wantErr: "revocation list expired",
+	}, {
+		name:   "modified after signing",
+		signer: publisher,
+		modify: func(sl *SignedList) {
+			sl.Signed.Digests = nil
+		},
+		wantErr: "not signed by key",
+	}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sl, err := New([]string{digest, digest}, []string{keyID(t, compromised)}, now, now.Add(24*time.Hour))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := sl.Sign(tt.signer); err != nil {
+				t.Fatalf("Sign() = %v", err)
+			}
+			if tt.modify != nil {
+				tt.modify(sl)
+			}
+
+			// Round trip through JSON, as the list is published.
+			b, err := json.Marshal(sl)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got, err := Parse(b)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			at := tt.now
+			if at.IsZero() {
+				at = now
+			}
+			err = got.Verify(publisher, at)
+			switch {
+			case tt.wantErr == "" && err != nil:
+				t.Errorf("Verify() = %v", err)
+			case tt.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tt.wantErr)):
+				t.Errorf("Verify() = %v, want error containing %q", err, tt.wantErr)
+			}
+		})
+	}
+
+	t.Run("revoked", func(t *testing.T) {
+		sl, err := New([]string{digest}, []string{keyID(t, compromised)}, now, now.Add(time.Hour))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !sl.Signed.DigestRevoked(digest) {
+			t.Errorf("DigestRevoked(%s) = false", digest)
+		}
+		if sl.Signed.DigestRevoked("sha256:" + strings.Repeat("0", 64)) {
+			t.Error("DigestRevoked() of another digest = true")
+		}
+		for _, tc := range []struct {
+			sv   signature.SignerVerifier
+			want bool
+		}{{compromised, true}, {publisher, false}} {
+			pub, err := tc.sv.PublicKey()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got, err := sl.Signed.KeyRevoked(pub); err != nil || got != tc.want {
+				t.Errorf("KeyRevoked() = %v, %v, want %v", got, err, tc.want)
+			}
+		}
+	})
+}
+
+func TestNew(t *testing.T) {
+	now := time.Now()
+	if _, err := New([]string{"latest"}, nil, now, now.Add(time.Hour)); err == nil {
+		t.Error("New() with an invalid digest succeeded")
+	}
+	if _, err := New(nil, []string{"alice.pub"}, now, now.Add(time.Hour)); err == nil {
+		t.Error("New() with an invalid key ID succeeded")
+	}
+	if _, err := New([]string{digest}, nil, now, now); err == nil {
+		t.Error("New() of a list expiring when it is issued succeeded")
+	}
+}
+
+func TestFetch(t *testing.T) {
+	now := time.Now()
+	sl, err := New([]string{digest}, nil, now, now.Add(time.Hour))
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := json.Marshal(sl)
+	if err != nil {
+		t.Fatal(err)
+	}
+	file := filepath.Join(t.TempDir(), "revocations.json")
+	if err := os.WriteFile(file, b, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/revocations.json" {
+			http.NotFound(w, r)
+			return
+		}
+		_, _ = w.Write(b)
+	}))
+	defer server.Close()
+
+	for _, source := range []string{file, server.URL + "/revocations.json"} {
+		got, _, err := Fetch(context.Background(), source)
+		if err != nil {
+			t.Fatalf("Fetch(%s) = %v", source, err)
+		}
+		if !got.Signed.DigestRevoked(digest) {
+			t.Errorf("Fetch(%s) did not return the revoked digest", source)
+		}
+	}
+	if _, _, err := Fetch(context.Background(), server.URL+"/missing.json"); err == nil {
+		t.Error("Fetch() of a missing URL succeeded")
+	}
+}
@@ -0,0 +1,270 @@
+//
+// Copyright 2023 The Sigstore Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package rootpolicy implements root-of-trust policies: documents signed by
+// the maintainers of a registry namespace that list the keys trusted to sign
+// its artifacts.
+package rootpolicy
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"time"
+
+	"github.com/google/go-containerregistry/pkg/name"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
+	"github.com/secure-systems-lab/go-securesystemslib/cjson"
+	"github.com/sigstore/cosign/v2/pkg/oci/static"
+	"github.com/sigstore/cosign/v2/pkg/types"
+	"github.com/sigstore/sigstore/pkg/cryptoutils"
+	"github.com/sigstore/sigstore/pkg/signature"
+)
+
+const (
+	// PolicyType identifies the policy format.
+	PolicyType = "https://sigstore.dev/cosign/root-policy/v1"
+	// Repository is the repository of a namespace the policy is stored in,
+	// under the Tag tag.
+	Repository = "cosign-policy"
+	Tag        = "latest"
+)
+
+// Policy lists the maintainer keys trusted to sign the artifacts of a
+// namespace, and how many of them must sign the policy itself.
+type Policy struct {
+	Type      string    `json:"_type"`
+	Namespace string    `json:"namespace"`
+	Expires   time.Time `json:"expires"`
+	Threshold int       `json:"threshold"`
+	// Maintainers maps the key IDs of the maintainer keys, see KeyID, to the
+	// PEM-encoded keys.
+	Maintainers map[string]string `json:"maintainers"`
+}
+
+// Signature is the signature of a maintainer over the canonical JSON
+// encoding of a policy.
+type Signature struct {
+	KeyID string `json:"keyid"`
+	Sig   string `json:"sig"`
+}
+
+// SignedPolicy is a policy with the signatures of its maintainers.
+type SignedPolicy struct {
+	Signed     Policy      `json:"signed"`
+	Signatures []Signature `json:"signatures"`
+}
+
+// KeyID returns the hex encoded SHA256 digest of the PKIX encoding of pub.
+func KeyID(pub crypto.PublicKey) (string, error) {
+	der, err := x509.MarshalPKIXPublicKey(pub)
+	if err != nil {
+		return "", err
+	}
+	digest := sha256.Sum256(der)
+	return hex.EncodeToString(digest[:]), nil
+}
+
+// New returns an unsigned policy for namespace trusting the maintainer keys,
+// threshold of which must sign the policy.
+func New(namespace string, maintainers []crypto.PublicKey, threshold int, expires time.Time) (*SignedPolicy, error) {
+	p := Policy{
+		Type:        PolicyType,
+		Namespace:   namespace,
+		Expires:     expires.UTC().Truncate(time.Second),
+		Threshold:   threshold,
+		Maintainers: make(map[string]string, len(maintainers)),
+	}
+	for _, pub := range maintainers {
+		id, err := KeyID(pub)
+		if err != nil {
+			return nil, err
+		}
+		pem, err := cryptoutils.MarshalPublicKeyToPEM(pub)
+		if err != nil {
+			return nil, err
+		}
+		p.Maintainers[id] = string(pem)
+	}
+	if len(p.Maintainers) != len(maintainers) {
+		return nil, errors.New("maintainer keys must be distinct")
+	}
+	if threshold < 1 || threshold > len(p.Maintainers) {
+		return nil, fmt.Errorf("threshold must be between 1 and the number of maintainers %d, got %d", len(p.Maintainers), threshold)
+	}
+	return &SignedPolicy{Signed: p, Signatures: []Signature{}}, nil
+}
+
+// Sign adds the signature of signer, which must be a maintainer key,
+// replacing any previous signature of the same key.
+func (sp *SignedPolicy) Sign(signer signature.Signer) error {
+	pub, err := signer.PublicKey()
+	if err != nil {
+		return err
+	}
+	id, err := KeyID(pub)
+	if err != nil {
+		return err
+	}
+	if _, ok := sp.Signed.Maintainers[id]; !ok {
+		return fmt.Errorf("key %s is not a maintainer of the policy", id)
+	}
+	msg, err := cjson.EncodeCanonical(sp.Signed)
+	if err != nil {
+		return err
+	}
+	sig, err := signer.SignMessage(bytes.NewReader(msg))
+	if err != nil {
+		return err
+	}
+
+	signatures := []Signature{{KeyID: id, Sig: base64.StdEncoding.EncodeToString(sig)}}
+	for _, s := range sp.Signatures {
+		if s.KeyID != id {
+			signatures = append(signatures, s)
+		}
+	}
+	sort.Slice(signatures, func(i, j int) bool { return signatures[i].KeyID < signatures[j].KeyID })
+	sp.Signatures = signatures
+	return nil
+}
+
+// Verify checks that sp is a policy for namespace that has not expired at
+// now and is signed by at least its threshold of maintainers.
+func (sp *SignedPolicy) Verify(namespace string, now time.Time) error {
+	p := sp.Signed
+	if p.Type != PolicyType {
+		return fmt.Errorf("unsupported policy type %q", p.Type)
+	}
+	if p.Namespace != namespace {
+		return fmt.Errorf("policy is for namespace %s, not %s", p.Namespace, namespace)
+	}
+	if now.After(p.Expires) {
+		return fmt.Errorf("policy expired at %s", p.Expires.Format(time.RFC3339))
+	}
+	if p.Threshold < 1 {
+		return fmt.Errorf("invalid policy threshold %d", p.Threshold)
+	}
+
+	msg, err := cjson.EncodeCanonical(p)
+	if err != nil {
+		return err
+	}
+	signed := map[string]bool{}
+	for _, s := range sp.Signatures {
+		pem, ok := p.Maintainers[s.KeyID]
+		if !ok || signed[s.KeyID] {
+			continue
+		}
+		v, err := loadVerifier(pem, crypto.SHA256)
+		if err != nil {
+			return fmt.Errorf("loading maintainer key %s: %w", s.KeyID, err)
+		}
+		sig, err := base64.StdEncoding.DecodeString(s.Sig)
+		if err != nil {
+			continue
+		}
+		if v.VerifySignature(bytes.NewReader(sig), bytes.NewReader(msg)) == nil {
+			signed[s.KeyID] = true
+		}
+	}
+	if len(signed) < p.Threshold {
+		return fmt.Errorf("policy is signed by %d of the %d required maintainers", len(signed), p.Threshold)
+	}
+	return nil
+}
+
+// Verifiers returns verifiers for the maintainer keys, sorted by key ID.
+func (p *Policy) Verifiers(hashAlgorithm crypto.Hash) ([]signature.Verifier, error) {
+	ids := make([]string, 0, len(p.Maintainers))
+	for id := range p.Maintainers {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
+	verifiers := make([]signature.Verifier, 0, len(ids))
+	for _, id := range ids {
+		v, err := loadVerifier(p.Maintainers[id], hashAlgorithm)
+		if err != nil {
+			return nil, fmt.Errorf("loading maintainer key %s: %w", id, err)
+		}
+		verifiers = append(verifiers, v)
+	}
+	return verifiers, nil
+}
+
+func loadVerifier(pem string, hashAlgorithm crypto.Hash) (signature.Verifier, error) {
+	pub, err := cryptoutils.UnmarshalPEMToPublicKey([]byte(pem))
+	if err != nil {
+		return nil, err
+	}
+	return signature.LoadVerifier(pub, hashAlgorithm)
+}
+
+// Ref returns the reference the policy of namespace is stored at.
+func Ref(namespace string, opts ...name.Option) (name.Reference, error) {
+	return name.ParseReference(namespace+"/"+Repository+":"+Tag, opts...)
+}
+
+// Fetch downloads the policy of namespace from the registry, without
+// verifying it.
+func Fetch(ref name.Reference, opts ...remote.Option) (*SignedPolicy, error) {
+	img, err := remote.Image(ref, opts...)
+	if err != nil {
+		return nil, fmt.Errorf("fetching policy %s: %w", ref, err)
+	}
+	layers, err := img.Layers()
+	if err != nil {
+		return nil, err
+	}
+	if len(layers) != 1 {
+		return nil, fmt.Errorf("policy %s has %d layers, expected 1", ref, len(layers))
+	}
+	rc, err := layers[0].Uncompressed()
+	if err != nil {
+		return nil, err
+	}
+	defer rc.Close()
+	b, err := io.ReadAll(rc)
+	if err != nil {
+		return nil, err
+	}
+	sp := &SignedPolicy{}
+	if err := json.Unmarshal(b, sp); err != nil {
+		return nil, fmt.Errorf("parsing policy %s: %w", ref, err)
+	}
+	return sp, nil
+}
+
+// Upload stores the policy in the registry at ref.
+func Upload(ref name.Reference, sp *SignedPolicy, opts ...remote.Option) error {
+	b, err := json.MarshalIndent(sp, "", "  ")
+	if err != nil {
+		return err
+	}
+	img, err := static.NewFile(b, static.WithLayerMediaType(types.RootPolicyLayerMediaType), static.WithConfigMediaType(types.RootPolicyConfigMediaType))
+	if err != nil {
+		return err
+	}
+	return remote.Write(ref, img, opts...)
+}
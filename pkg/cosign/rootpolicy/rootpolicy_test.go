@@ -0,0 +1,174 @@
+//
+// Copyright 2023 The Sigstore Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package rootpolicy
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/sigstore/sigstore/pkg/signature"
+)
+
+func newSigner(t *testing.T) signature.SignerVerifier {
+	t.Helper()
+	priv, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sv, err := signature.LoadECDSASignerVerifier(priv, crypto.SHA256)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return sv
+}
+
+func TestSignedPolicy(t *testing.T) {
+	const namespace = "ghcr.io/myorg"
+	alice, bob, carol, mallory := newSigner(t), newSigner(t), newSigner(t), newSigner(t)
+	now := time.Now()
+
+	newPolicy := func(t *testing.T) *SignedPolicy {
+		t.Helper()
+		var pubs []crypto.PublicKey
+		for _, sv := range []signature.SignerVerifier{alice, bob, carol} {
+			pub, err := sv.PublicKey()
+			if err != nil {
+				t.Fatal(err)
+			}
+			pubs = append(pubs, pub)
+		}
+		sp, err := New(namespace, pubs, 2, now.Add(24*time.Hour))
+		if err != nil {
+			t.Fatal(err)
+		}
+		return sp
+	}
+
+	tests := []struct {
+		name      string
+		signers   []signature.SignerVerifier
+		modify    func(sp *SignedPolicy)
+		namespace string
+		now       time.Time
+		wantErr   string
+	}{{
+		name:    "threshold met",
+		signers: []signature.SignerVerifier{alice, carol},
+	}, {
+		name:    "all maintainers",
+		signers: []signature.SignerVerifier{alice, bob, carol},
+	}, {
+		name:    "below threshold",
+		signers: []signature.SignerVerifier{bob},
+		wantErr: "signed by 1 of the 2 required maintainers",
+	}, {
+		name:    "same maintainer twice",
+		signers: []signature.SignerVerifier{bob, bob},
+		wantErr: "signed by 1 of the 2 required maintainers",
+	}, {
+		name:    "expired",
+		signers: []signature.SignerVerifier{alice, bob},
+		now:     now.Add(48 * time.Hour),
+		wantErr: "policy expired",
+	}, {
+		name:      "other namespace",
+		signers:   []signature.SignerVerifier{alice, bob},
+		namespace: "ghcr.io/otherorg",
+		wantErr:   "not ghcr.io/otherorg",
+	}, {
+		name:    "modified after signing",
+		signers: []signature.SignerVerifier{alice, bob},
+		modify: func(sp *SignedPolicy) {
+			sp.Signed.Threshold = 1
+			sp.Signed.Expires = sp.Signed.Expires.Add(365 * 24 * time.Hour)
+		},
+		wantErr: "signed by 0 of the 1 required maintainers",
+	}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sp := newPolicy(t)
+			for _, s := range tt.signers {
+				if err := sp.Sign(s); err != nil {
+					t.Fatalf("Sign() = %v", err)
+				}
+			}
+			if tt.modify != nil {
+				tt.modify(sp)
+			}
+
+			// Round trip through JSON, as the policy is stored in the registry.
+			b, err := json.Marshal(sp)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got := &SignedPolicy{}
+			if err := json.Unmarshal(b, got); err != nil {
+				t.Fatal(err)
+			}
+
+			ns, at := tt.namespace, tt.now
+			if ns == "" {
+				ns = namespace
+			}
+			if at.IsZero() {
+				at = now
+			}
+			err = got.Verify(ns, at)
+			switch {
+			case tt.wantErr == "" && err != nil:
+				t.Errorf("Verify() = %v", err)
+			case tt.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tt.wantErr)):
+				t.Errorf("Verify() = %v, want error containing %q", err, tt.wantErr)
+			}
+		})
+	}
+
+	t.Run("non maintainer", func(t *testing.T) {
+		if err := newPolicy(t).Sign(mallory); err == nil {
+			t.Error("Sign() with a key that is not a maintainer succeeded")
+		}
+	})
+
+	t.Run("verifiers", func(t *testing.T) {
+		verifiers, err := newPolicy(t).Signed.Verifiers(crypto.SHA256)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(verifiers) != 3 {
+			t.Fatalf("Verifiers() returned %d verifiers, want 3", len(verifiers))
+		}
+	})
+}
+
+func TestNew(t *testing.T) {
+	pub, err := newSigner(t).PublicKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	expires := time.Now().Add(time.Hour)
+	if _, err := New("ghcr.io/myorg", []crypto.PublicKey{pub}, 2, expires); err == nil {
+		t.Error("New() with a threshold above the number of maintainers succeeded")
+	}
+	if _, err := New("ghcr.io/myorg", []crypto.PublicKey{pub, pub}, 1, expires); err == nil {
+		t.Error("New() with duplicate maintainers succeeded")
+	}
+}
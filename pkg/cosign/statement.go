@@ -0,0 +1,136 @@
+//
+// Copyright 2023 The Sigstore Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cosign
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/google/go-containerregistry/pkg/name"
+	"github.com/in-toto/in-toto-golang/in_toto"
+
+	"github.com/sigstore/cosign/v2/pkg/cosign/attestation"
+	"github.com/sigstore/cosign/v2/pkg/oci"
+)
+
+// AttestationStatement is the in-toto statement carried by an attestation.
+type AttestationStatement struct {
+	// Attestation is the attestation the statement was taken from.
+	Attestation oci.Signature
+	// Statement is the generic form of the statement.
+	Statement in_toto.Statement
+	// Typed is the statement decoded for its predicate type, e.g. an
+	// in_toto.ProvenanceStatementSLSA02 for SLSA v0.2 provenance. Statements of
+	// a predicate type that is not registered are decoded as in_toto.Statement.
+	Typed interface{}
+}
+
+// DecodeAttestationStatement unwraps the DSSE envelope of att and decodes the
+// in-toto statement in it. The predicate is validated when its type has a
+// decoder registered with attestation.RegisterPredicateType.
+func DecodeAttestationStatement(att oci.Signature) (*AttestationStatement, error) {
+	stBytes, as, err := unwrapAttestationStatement(att)
+	if err != nil {
+		return nil, err
+	}
+	if err := as.decode(stBytes); err != nil {
+		return nil, err
+	}
+	return as, nil
+}
+
+// DecodeAttestationStatements decodes the statements of atts that are of the
+// given predicate type, a name registered with attestation.RegisterPredicateType
+// or a predicate type URI. An empty predicateType selects all the statements.
+func DecodeAttestationStatements(atts []oci.Signature, predicateType string) ([]AttestationStatement, error) {
+	pt, ok := attestation.LookupPredicateType(predicateType)
+	if !ok {
+		pt = attestation.PredicateType{URI: predicateType}
+	}
+
+	var statements []AttestationStatement
+	for _, att := range atts {
+		stBytes, as, err := unwrapAttestationStatement(att)
+		if err != nil {
+			return nil, err
+		}
+		// Only the selected statements have their predicate validated.
+		if predicateType != "" && !pt.Matches(as.Statement.PredicateType) {
+			continue
+		}
+		if err := as.decode(stBytes); err != nil {
+			return nil, err
+		}
+		statements = append(statements, *as)
+	}
+	return statements, nil
+}
+
+// unwrapAttestationStatement returns the statement in the DSSE envelope of
+// att, both raw and decoded as a generic in-toto statement.
+func unwrapAttestationStatement(att oci.Signature) ([]byte, *AttestationStatement, error) {
+	payload, err := att.Payload()
+	if err != nil {
+		return nil, nil, fmt.Errorf("getting payload: %w", err)
+	}
+	stBytes, err := attestation.DecodeEnvelopePayload(payload)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	as := &AttestationStatement{Attestation: att}
+	if err := json.Unmarshal(stBytes, &as.Statement); err != nil {
+		return nil, nil, fmt.Errorf("unmarshal in-toto statement: %w", err)
+	}
+	return stBytes, as, nil
+}
+
+// decode sets as.Typed from the raw statement.
+func (as *AttestationStatement) decode(statement []byte) error {
+	pt, ok := attestation.LookupPredicateTypeURI(as.Statement.PredicateType)
+	if !ok {
+		as.Typed = as.Statement
+		return nil
+	}
+	typed, err := pt.DecodeStatement(statement)
+	if err != nil {
+		return fmt.Errorf("decoding %s statement: %w", as.Statement.PredicateType, err)
+	}
+	as.Typed = typed
+	return nil
+}
+
+// VerifyImageAttestationStatements verifies the attestations of signedImgRef
+// like VerifyImageAttestations and returns the decoded statements of the given
+// predicate type, see DecodeAttestationStatements. It is an error if none of
+// the verified attestations is of that type.
+func VerifyImageAttestationStatements(ctx context.Context, signedImgRef name.Reference, predicateType string, co *CheckOpts) (statements []AttestationStatement, bundleVerified bool, err error) {
+	atts, bundleVerified, err := VerifyImageAttestations(ctx, signedImgRef, co)
+	if err != nil {
+		return nil, false, err
+	}
+	statements, err = DecodeAttestationStatements(atts, predicateType)
+	if err != nil {
+		return nil, false, err
+	}
+	if len(statements) == 0 {
+		return nil, false, &ErrNoMatchingAttestations{
+			fmt.Errorf("none of the attestations matched the predicate type: %s", predicateType),
+		}
+	}
+	return statements, bundleVerified, nil
+}
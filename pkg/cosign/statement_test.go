@@ -0,0 +1,90 @@
+//
+// Copyright 2023 The Sigstore Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cosign
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"testing"
+
+	"github.com/in-toto/in-toto-golang/in_toto"
+	slsa02 "github.com/in-toto/in-toto-golang/in_toto/slsa_provenance/v0.2"
+	"github.com/secure-systems-lab/go-securesystemslib/dsse"
+
+	"github.com/sigstore/cosign/v2/pkg/oci"
+	"github.com/sigstore/cosign/v2/pkg/oci/static"
+	"github.com/sigstore/cosign/v2/pkg/types"
+)
+
+func newTestAttestation(t *testing.T, statement string) oci.Signature {
+	t.Helper()
+	envelope, err := json.Marshal(dsse.Envelope{
+		PayloadType: types.IntotoPayloadType,
+		Payload:     base64.StdEncoding.EncodeToString([]byte(statement)),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	att, err := static.NewAttestation(envelope)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return att
+}
+
+func TestDecodeAttestationStatements(t *testing.T) {
+	provenance := newTestAttestation(t, `{"_type":"https://in-toto.io/Statement/v0.1","predicateType":"https://slsa.dev/provenance/v0.2","subject":[{"name":"image","digest":{"sha256":"deadbeef"}}],"predicate":{"builder":{"id":"https://example.com/builder"}}}`)
+	custom := newTestAttestation(t, `{"_type":"https://in-toto.io/Statement/v0.1","predicateType":"https://example.com/review/v1","subject":[{"name":"image","digest":{"sha256":"deadbeef"}}],"predicate":{"approved":true}}`)
+	// Not a valid CycloneDX BOM, it must only fail when selected.
+	badBOM := newTestAttestation(t, `{"_type":"https://in-toto.io/Statement/v0.1","predicateType":"https://cyclonedx.org/bom","subject":[],"predicate":{"bomFormat":"SPDX"}}`)
+	atts := []oci.Signature{provenance, custom, badBOM}
+
+	statements, err := DecodeAttestationStatements(atts, "slsaprovenance")
+	if err != nil {
+		t.Fatalf("DecodeAttestationStatements() = %v", err)
+	}
+	if len(statements) != 1 {
+		t.Fatalf("got %d statements, want 1", len(statements))
+	}
+	prov, ok := statements[0].Typed.(in_toto.ProvenanceStatementSLSA02)
+	if !ok {
+		t.Fatalf("got a %T statement, want in_toto.ProvenanceStatementSLSA02", statements[0].Typed)
+	}
+	if prov.Predicate.Builder.ID != "https://example.com/builder" {
+		t.Errorf("builder = %q", prov.Predicate.Builder.ID)
+	}
+	if statements[0].Statement.PredicateType != slsa02.PredicateSLSAProvenance || statements[0].Attestation != provenance {
+		t.Errorf("unexpected statement %+v", statements[0])
+	}
+
+	statements, err = DecodeAttestationStatements(atts, "https://example.com/review/v1")
+	if err != nil {
+		t.Fatalf("DecodeAttestationStatements() = %v", err)
+	}
+	if len(statements) != 1 {
+		t.Fatalf("got %d statements, want 1", len(statements))
+	}
+	if _, ok := statements[0].Typed.(in_toto.Statement); !ok {
+		t.Errorf("got a %T statement, want in_toto.Statement", statements[0].Typed)
+	}
+
+	if _, err := DecodeAttestationStatements(atts, ""); err == nil {
+		t.Error("expected an error decoding an invalid CycloneDX predicate")
+	}
+	if _, err := DecodeAttestationStatement(newTestAttestation(t, "not json")); err == nil {
+		t.Error("expected an error decoding a payload that is not a statement")
+	}
+}
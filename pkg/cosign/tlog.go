@@ -23,10 +23,10 @@ import (
 	"crypto/x509"
 	"encoding/base64"
 	"encoding/hex"
+	"encoding/pem"
 	"errors"
 	"fmt"
 	"hash"
-	"os"
 	"strconv"
 	"strings"
 
@@ -36,6 +36,7 @@ import (
 	"github.com/transparency-dev/merkle/rfc6962"
 
 	"github.com/sigstore/cosign/v2/internal/ui"
+	"github.com/sigstore/cosign/v2/pkg/blob"
 	"github.com/sigstore/cosign/v2/pkg/cosign/bundle"
 	"github.com/sigstore/cosign/v2/pkg/cosign/env"
 	"github.com/sigstore/rekor/pkg/generated/client"
@@ -117,34 +118,28 @@ func intotoEntry(ctx context.Context, signature, pubKey []byte) (models.Proposed
 // GetRekorPubs retrieves trusted Rekor public keys from the embedded or cached
 // TUF root. If expired, makes a network call to retrieve the updated targets.
 // There are two Env variable that can be used to override this behaviour:
-// SIGSTORE_REKOR_PUBLIC_KEY - If specified, location of the file that contains
-// the Rekor Public Key on local filesystem
+// SIGSTORE_REKOR_PUBLIC_KEY - If specified, location of the file or URL that
+// contains the Rekor Public Key, see LoadRekorPubs
 func GetRekorPubs(ctx context.Context) (*TrustedTransparencyLogPubKeys, error) {
 	publicKeys := NewTrustedTransparencyLogPubKeys()
 	altRekorPub := env.Getenv(env.VariableSigstoreRekorPublicKey)
 
 	if altRekorPub != "" {
-		raw, err := os.ReadFile(altRekorPub)
-		if err != nil {
-			return nil, fmt.Errorf("error reading alternate Rekor public key file: %w", err)
-		}
-		if err := publicKeys.AddTransparencyLogPubKey(raw, tuf.Active); err != nil {
+		return LoadRekorPubs(altRekorPub)
+	}
+
+	tufClient, err := tuf.NewFromEnv(ctx)
+	if err != nil {
+		return nil, err
+	}
+	targets, err := tufClient.GetTargetsByMeta(tuf.Rekor, []string{rekorTargetStr})
+	if err != nil {
+		return nil, err
+	}
+	for _, t := range targets {
+		if err := publicKeys.AddTransparencyLogPubKey(t.Target, t.Status); err != nil {
 			return nil, fmt.Errorf("AddRekorPubKey: %w", err)
 		}
-	} else {
-		tufClient, err := tuf.NewFromEnv(ctx)
-		if err != nil {
-			return nil, err
-		}
-		targets, err := tufClient.GetTargetsByMeta(tuf.Rekor, []string{rekorTargetStr})
-		if err != nil {
-			return nil, err
-		}
-		for _, t := range targets {
-			if err := publicKeys.AddTransparencyLogPubKey(t.Target, t.Status); err != nil {
-				return nil, fmt.Errorf("AddRekorPubKey: %w", err)
-			}
-		}
 	}
 
 	if len(publicKeys.Keys) == 0 {
@@ -154,6 +149,26 @@ func GetRekorPubs(ctx context.Context) (*TrustedTransparencyLogPubKeys, error) {
 	return &publicKeys, nil
 }
 
+// LoadRekorPubs returns the Rekor public keys in the PEM file or at the URL
+// keyRef, e.g. those of a private Rekor instance. The file may hold several
+// keys, such as the old and new keys of a log whose key was rotated.
+func LoadRekorPubs(keyRef string) (*TrustedTransparencyLogPubKeys, error) {
+	raw, err := blob.LoadFileOrURL(keyRef)
+	if err != nil {
+		return nil, fmt.Errorf("error reading alternate Rekor public key file: %w", err)
+	}
+	publicKeys := NewTrustedTransparencyLogPubKeys()
+	for block, rest := pem.Decode(raw); block != nil; block, rest = pem.Decode(rest) {
+		if err := publicKeys.AddTransparencyLogPubKey(pem.EncodeToMemory(block), tuf.Active); err != nil {
+			return nil, fmt.Errorf("AddRekorPubKey: %w", err)
+		}
+	}
+	if len(publicKeys.Keys) == 0 {
+		return nil, fmt.Errorf("no Rekor public keys found in %s", keyRef)
+	}
+	return &publicKeys, nil
+}
+
 // rekorPubsFromClient returns a RekorPubKey keyed by the log ID from the Rekor client.
 // NOTE: This **must not** be used in the verification path, but may be used in the
 // sign path to validate return responses are consistent from Rekor.
@@ -370,6 +385,48 @@ func GetTlogEntry(ctx context.Context, rekorClient *client.Rekor, entryUUID stri
 	return nil, errors.New("empty response")
 }
 
+// GetTlogEntryByIndex returns the UUID and the entry at logIndex of the
+// transparency log.
+func GetTlogEntryByIndex(ctx context.Context, rekorClient *client.Rekor, logIndex int64) (string, *models.LogEntryAnon, error) {
+	params := entries.NewGetLogEntryByIndexParamsWithContext(ctx)
+	params.SetLogIndex(logIndex)
+	resp, err := rekorClient.Entries.GetLogEntryByIndex(params)
+	if err != nil {
+		return "", nil, err
+	}
+	for k, e := range resp.Payload {
+		// Check that body hash matches UUID
+		if err := verifyUUID(k, e); err != nil {
+			return "", nil, err
+		}
+		return k, &e, nil
+	}
+	return "", nil, errors.New("empty response")
+}
+
+// TlogEntrySigners returns the PEM encoded public keys or certificates that
+// verify the signatures of the transparency log entry e, one for each
+// signature of a DSSE envelope.
+func TlogEntrySigners(e *models.LogEntryAnon) ([][]byte, error) {
+	body, ok := e.Body.(string)
+	if !ok {
+		return nil, errors.New("unexpected entry body")
+	}
+	keys, err := bundleKeys(body)
+	if err != nil {
+		return nil, err
+	}
+	signers := make([][]byte, 0, len(keys))
+	for _, key := range keys {
+		signer, err := base64.StdEncoding.DecodeString(key)
+		if err != nil {
+			return nil, err
+		}
+		signers = append(signers, signer)
+	}
+	return signers, nil
+}
+
 func proposedEntries(b64Sig string, payload, pubKey []byte) ([]models.ProposedEntry, error) {
 	var proposedEntry []models.ProposedEntry
 	signature, err := base64.StdEncoding.DecodeString(b64Sig)
@@ -491,6 +548,13 @@ func VerifyTLogEntryOffline(ctx context.Context, e *models.LogEntryAnon, rekorPu
 	if err != nil {
 		return fmt.Errorf("verifying signedEntryTimestamp: %w", err)
 	}
+	// Verify the log's signature over the tree head the inclusion was proven
+	// against. Entries from before Rekor served checkpoints have none.
+	if e.Verification.InclusionProof.Checkpoint != nil {
+		if _, err := verifyEntryCheckpoint(e, pubKey.PubKey); err != nil {
+			return fmt.Errorf("verifying checkpoint: %w", err)
+		}
+	}
 	if pubKey.Status != tuf.Active {
 		ui.Infof(ctx, "Successfully verified Rekor entry using an expired verification key")
 	}
@@ -17,11 +17,15 @@ package cosign
 import (
 	"context"
 	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
 	"crypto/rand"
 	"crypto/rsa"
 	"crypto/x509"
 	"encoding/hex"
 	"encoding/pem"
+	"os"
+	"path/filepath"
 	"strings"
 	"testing"
 
@@ -56,6 +60,50 @@ func TestGetRekorPubKeys(t *testing.T) {
 	}
 }
 
+func TestLoadRekorPubs(t *testing.T) {
+	var pems []byte
+	for i := 0; i < 2; i++ {
+		priv, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+		if err != nil {
+			t.Fatal(err)
+		}
+		pemBytes, err := cryptoutils.MarshalPublicKeyToPEM(priv.Public())
+		if err != nil {
+			t.Fatal(err)
+		}
+		pems = append(pems, pemBytes...)
+	}
+	path := filepath.Join(t.TempDir(), "rekor.pub")
+	if err := os.WriteFile(path, pems, 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	keys, err := LoadRekorPubs(path)
+	if err != nil {
+		t.Fatalf("LoadRekorPubs() = %v", err)
+	}
+	if len(keys.Keys) != 2 {
+		t.Errorf("expected 2 keys, got %d", len(keys.Keys))
+	}
+
+	t.Setenv("SIGSTORE_REKOR_PUBLIC_KEY", path)
+	keys, err = GetRekorPubs(context.Background())
+	if err != nil {
+		t.Fatalf("GetRekorPubs() = %v", err)
+	}
+	if len(keys.Keys) != 2 {
+		t.Errorf("expected 2 keys from SIGSTORE_REKOR_PUBLIC_KEY, got %d", len(keys.Keys))
+	}
+
+	empty := filepath.Join(t.TempDir(), "empty.pub")
+	if err := os.WriteFile(empty, []byte("no keys here"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := LoadRekorPubs(empty); err == nil {
+		t.Error("expected an error for a file without keys")
+	}
+}
+
 func TestExpectedRekorResponse(t *testing.T) {
 	validUUID := "f794467401d57241b7903737211c721cb3315648d077a9f02ceefb6e404a05de"
 	validUUID1 := "7794467401d57241b7903737211c721cb3315648d077a9f02ceefb6e404a05de"
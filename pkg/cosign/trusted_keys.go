@@ -0,0 +1,95 @@
+//
+// Copyright 2023 The Sigstore Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cosign
+
+import (
+	"bytes"
+	"context"
+	"crypto"
+	"errors"
+	"fmt"
+	"io"
+
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/sigstore/cosign/v2/pkg/oci"
+	"github.com/sigstore/sigstore/pkg/signature"
+)
+
+// TrustedKeys is a set of trusted public keys, a signature verifies if any of
+// them verifies it. Used as CheckOpts.SigVerifier it lets the old and the new
+// keys be trusted at the same time while rotating keys.
+type TrustedKeys []signature.Verifier
+
+var _ signature.Verifier = TrustedKeys(nil)
+
+// PublicKey returns the public key of the only trusted key. It fails when
+// there are several, whose callers must get the public key of each of them.
+func (t TrustedKeys) PublicKey(opts ...signature.PublicKeyOption) (crypto.PublicKey, error) {
+	switch len(t) {
+	case 0:
+		return nil, errors.New("no trusted keys")
+	case 1:
+		return t[0].PublicKey(opts...)
+	default:
+		return nil, fmt.Errorf("%d trusted keys, not a single public key", len(t))
+	}
+}
+
+// VerifySignature verifies sig with each of the trusted keys in turn, and
+// succeeds with the first one that verifies it.
+func (t TrustedKeys) VerifySignature(sig, message io.Reader, opts ...signature.VerifyOption) error {
+	sigBytes, err := io.ReadAll(sig)
+	if err != nil {
+		return err
+	}
+	msgBytes, err := io.ReadAll(message)
+	if err != nil {
+		return err
+	}
+	errs := make([]error, 0, len(t))
+	for _, v := range t {
+		err := v.VerifySignature(bytes.NewReader(sigBytes), bytes.NewReader(msgBytes), opts...)
+		if err == nil {
+			return nil
+		}
+		errs = append(errs, err)
+	}
+	if len(errs) == 0 {
+		return errors.New("no trusted keys")
+	}
+	return errors.Join(errs...)
+}
+
+// verifyWithTrustedKeys runs verifyInternal with each of the trusted keys
+// until one of them verifies sig. Each key is checked on its own, so that the
+// transparency log entry is matched against the key that made the signature.
+func verifyWithTrustedKeys(ctx context.Context, sig oci.Signature, h v1.Hash,
+	verifyFn signatureVerificationFn, keys TrustedKeys, co *CheckOpts) (bool, error) {
+	if len(keys) == 0 {
+		return false, errors.New("no trusted keys")
+	}
+	errs := make([]error, 0, len(keys))
+	for _, key := range keys {
+		keyCo := *co
+		keyCo.SigVerifier = key
+		bundleVerified, err := verifyInternal(ctx, sig, h, verifyFn, &keyCo)
+		if err == nil {
+			return bundleVerified, nil
+		}
+		errs = append(errs, err)
+	}
+	return false, errors.Join(errs...)
+}
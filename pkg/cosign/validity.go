@@ -0,0 +1,82 @@
+//
+// Copyright 2023 The Sigstore Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cosign
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/sigstore/cosign/v2/pkg/oci"
+)
+
+// PredicateExpiryField is the field of an attestation predicate holding the
+// RFC 3339 time after which the attestation must no longer be trusted, see
+// CheckOpts.CheckPredicateExpiry.
+const PredicateExpiryField = "expires"
+
+// checkSignatureAge checks that a signature whose existence is proven at the
+// trusted times, a verified RFC 3161 timestamp or transparency log entry,
+// is at most maxAge old at now. The earliest of the times is used.
+func checkSignatureAge(maxAge time.Duration, now time.Time, trustedTimes ...*time.Time) error {
+	var signedAt *time.Time
+	for _, t := range trustedTimes {
+		if t != nil && (signedAt == nil || t.Before(*signedAt)) {
+			signedAt = t
+		}
+	}
+	if signedAt == nil {
+		return errors.New("a signed timestamp or transparency log entry is required to check the signature age")
+	}
+	if age := now.Sub(*signedAt); age > maxAge {
+		return &VerificationFailure{
+			fmt.Errorf("signature is %s old, older than the maximum age of %s", age.Truncate(time.Second), maxAge),
+		}
+	}
+	return nil
+}
+
+// checkPredicateExpiry rejects the attestation att if its predicate holds a
+// PredicateExpiryField time before now. Predicates without the field are
+// accepted.
+func checkPredicateExpiry(att oci.Signature, now time.Time) error {
+	_, as, err := unwrapAttestationStatement(att)
+	if err != nil {
+		return err
+	}
+	predicate, ok := as.Statement.Predicate.(map[string]interface{})
+	if !ok {
+		return nil
+	}
+	v, ok := predicate[PredicateExpiryField]
+	if !ok {
+		return nil
+	}
+	s, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("predicate field %s is not a string", PredicateExpiryField)
+	}
+	expires, err := time.Parse(time.RFC3339, s)
+	if err != nil {
+		return fmt.Errorf("parsing predicate field %s: %w", PredicateExpiryField, err)
+	}
+	if now.After(expires) {
+		return &VerificationFailure{
+			fmt.Errorf("attestation expired at %s", expires.UTC().Format(time.RFC3339)),
+		}
+	}
+	return nil
+}
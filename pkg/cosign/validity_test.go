@@ -0,0 +1,74 @@
+//
+// Copyright 2023 The Sigstore Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cosign
+
+import (
+	"testing"
+	"time"
+)
+
+func TestCheckSignatureAge(t *testing.T) {
+	now := time.Date(2023, 6, 1, 0, 0, 0, 0, time.UTC)
+	recent := now.Add(-time.Hour)
+	old := now.Add(-30 * 24 * time.Hour)
+
+	tests := []struct {
+		name    string
+		times   []*time.Time
+		wantErr bool
+	}{
+		{name: "recent log entry", times: []*time.Time{nil, &recent}},
+		{name: "recent timestamp", times: []*time.Time{&recent, nil}},
+		{name: "old log entry", times: []*time.Time{nil, &old}, wantErr: true},
+		{name: "earliest time is used", times: []*time.Time{&recent, &old}, wantErr: true},
+		{name: "no trusted time", times: []*time.Time{nil, nil}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := checkSignatureAge(7*24*time.Hour, now, tt.times...)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("checkSignatureAge() = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestCheckPredicateExpiry(t *testing.T) {
+	now := time.Date(2023, 6, 1, 0, 0, 0, 0, time.UTC)
+	statement := func(predicate string) string {
+		return `{"_type":"https://in-toto.io/Statement/v0.1","predicateType":"https://example.com/review/v1","subject":[],"predicate":` + predicate + `}`
+	}
+
+	tests := []struct {
+		name      string
+		predicate string
+		wantErr   bool
+	}{
+		{name: "no expiry", predicate: `{"approved":true}`},
+		{name: "not expired", predicate: `{"expires":"2023-06-08T00:00:00Z"}`},
+		{name: "expired", predicate: `{"expires":"2023-05-25T00:00:00Z"}`, wantErr: true},
+		{name: "malformed expiry", predicate: `{"expires":"next week"}`, wantErr: true},
+		{name: "non-object predicate", predicate: `"text"`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := checkPredicateExpiry(newTestAttestation(t, statement(tt.predicate)), now)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("checkPredicateExpiry() = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
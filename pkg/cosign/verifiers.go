@@ -55,8 +55,23 @@ func SimpleClaimVerifier(sig oci.Signature, imageDigest v1.Hash, annotations map
 	return nil
 }
 
-// IntotoSubjectClaimVerifier verifies that sig.Payload() is an Intoto statement which references the given image digest.
-func IntotoSubjectClaimVerifier(sig oci.Signature, imageDigest v1.Hash, _ map[string]interface{}) error {
+// IntotoSubjectClaimVerifier verifies that sig.Payload() is an Intoto statement which references the given image digest,
+// and that the attestation is annotated with the given annotations.
+func IntotoSubjectClaimVerifier(sig oci.Signature, imageDigest v1.Hash, annotations map[string]interface{}) error {
+	if annotations != nil {
+		have, err := sig.Annotations()
+		if err != nil {
+			return err
+		}
+		attAnnotations := make(map[string]interface{}, len(have))
+		for k, v := range have {
+			attAnnotations[k] = v
+		}
+		if !correctAnnotations(annotations, attAnnotations) {
+			return errors.New("missing or incorrect annotation")
+		}
+	}
+
 	p, err := sig.Payload()
 	if err != nil {
 		return err
@@ -78,3 +78,31 @@ func Test_IntotoSubjectClaimVerifier(t *testing.T) {
 		}
 	}
 }
+
+func Test_IntotoSubjectClaimVerifierAnnotations(t *testing.T) {
+	att, err := static.NewAttestation([]byte(validIntotoStatement), static.WithAnnotations(map[string]string{
+		"predicateType": "cosign.sigstore.dev/attestation/v1",
+		"team":          "platform",
+	}))
+	if err != nil {
+		t.Fatal("Failed to create static.NewAttestation: ", err)
+	}
+	tests := []struct {
+		annotations map[string]interface{}
+		shouldFail  bool
+	}{
+		{annotations: nil},
+		{annotations: map[string]interface{}{"team": "platform"}},
+		{annotations: map[string]interface{}{"team": "security"}, shouldFail: true},
+		{annotations: map[string]interface{}{"env": "prod"}, shouldFail: true},
+	}
+	for _, tc := range tests {
+		got := IntotoSubjectClaimVerifier(att, validDigest, tc.annotations)
+		if got != nil && !tc.shouldFail {
+			t.Errorf("Expected ClaimVerifier to succeed with %v but failed: %v", tc.annotations, got)
+		}
+		if got == nil && tc.shouldFail {
+			t.Errorf("Expected ClaimVerifier to fail with %v but didn't", tc.annotations)
+		}
+	}
+}
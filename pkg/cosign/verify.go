@@ -40,6 +40,7 @@ import (
 	"github.com/nozzle/throttler"
 
 	"github.com/sigstore/cosign/v2/internal/pkg/cosign"
+	"github.com/sigstore/cosign/v2/internal/tracing"
 	"github.com/sigstore/cosign/v2/pkg/blob"
 	cbundle "github.com/sigstore/cosign/v2/pkg/cosign/bundle"
 	"github.com/sigstore/cosign/v2/pkg/oci/static"
@@ -64,22 +65,28 @@ import (
 	intoto_v001 "github.com/sigstore/rekor/pkg/types/intoto/v0.0.1"
 	intoto_v002 "github.com/sigstore/rekor/pkg/types/intoto/v0.0.2"
 	rekord_v001 "github.com/sigstore/rekor/pkg/types/rekord/v0.0.1"
+	"github.com/sigstore/rekor/pkg/util"
 	"github.com/sigstore/sigstore/pkg/cryptoutils"
 	"github.com/sigstore/sigstore/pkg/signature"
 	"github.com/sigstore/sigstore/pkg/signature/dsse"
 	"github.com/sigstore/sigstore/pkg/signature/options"
 	"github.com/sigstore/sigstore/pkg/tuf"
 	tsaverification "github.com/sigstore/timestamp-authority/pkg/verification"
+	"go.opentelemetry.io/otel/attribute"
 )
 
 // Identity specifies an issuer/subject to verify a signature against.
 // Both IssuerRegExp/SubjectRegExp support regexp while Issuer/Subject are for
-// strict matching.
+// strict matching. Subject matches any kind of subject alternative name, while
+// Email and URI/URIRegExp only match an email address or URI SAN respectively.
 type Identity struct {
 	Issuer        string
 	Subject       string
 	IssuerRegExp  string
 	SubjectRegExp string
+	Email         string
+	URI           string
+	URIRegExp     string
 }
 
 // CheckOpts are the options for checking signatures.
@@ -154,6 +161,27 @@ type CheckOpts struct {
 
 	// IgnoreTlog skip tlog verification
 	IgnoreTlog bool
+	// RequireOnlineTlog looks the signature up in the transparency log and
+	// verifies its inclusion even when it has a bundle that verifies offline.
+	RequireOnlineTlog bool
+	// RekorCheckpoint is a pinned checkpoint of the transparency log, see
+	// ParseCheckpoint. Entries are looked up online and must be included in
+	// a log state consistent with it.
+	RekorCheckpoint *util.SignedCheckpoint
+
+	// MaxSignatureAge, if set, rejects signatures whose RFC3161 timestamp or
+	// transparency log entry is older than it.
+	MaxSignatureAge time.Duration
+	// CheckPredicateExpiry rejects attestations whose predicate expired, see
+	// PredicateExpiryField.
+	CheckPredicateExpiry bool
+	// MaxAttestationSize, if set, rejects attestations whose DSSE envelope is
+	// larger than this many bytes before their content is fetched.
+	MaxAttestationSize int64
+	// PIVAttestation, if set, requires signatures to carry the attestation
+	// that their key was generated on a PIV hardware token, e.g. a Yubikey,
+	// with the PIN and touch policies it allows.
+	PIVAttestation *PIVAttestationPolicy
 
 	// The amount of maximum workers for parallel executions.
 	// Defaults to 10.
@@ -344,6 +372,11 @@ func CheckCertificatePolicy(cert *x509.Certificate, co *CheckOpts) error {
 				// No subject constraint on this identity, so checks out
 				subjectMatches = true
 			}
+			if ok, err := sanMatches(cert, identity); err != nil {
+				return err
+			} else if !ok {
+				subjectMatches = false
+			}
 			if subjectMatches && issuerMatches {
 				// If both issuer / subject match, return verified
 				return nil
@@ -356,6 +389,43 @@ func CheckCertificatePolicy(cert *x509.Certificate, co *CheckOpts) error {
 	return nil
 }
 
+// sanMatches checks the typed subject alternative names required by identity.
+func sanMatches(cert *x509.Certificate, identity Identity) (bool, error) {
+	if identity.Email != "" {
+		found := false
+		for _, email := range cert.EmailAddresses {
+			if email == identity.Email {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false, nil
+		}
+	}
+	switch {
+	case identity.URIRegExp != "":
+		regex, err := regexp.Compile(identity.URIRegExp)
+		if err != nil {
+			return false, fmt.Errorf("malformed URI in identity: %s : %w", identity.URIRegExp, err)
+		}
+		for _, uri := range cert.URIs {
+			if regex.MatchString(uri.String()) {
+				return true, nil
+			}
+		}
+		return false, nil
+	case identity.URI != "":
+		for _, uri := range cert.URIs {
+			if uri.String() == identity.URI {
+				return true, nil
+			}
+		}
+		return false, nil
+	}
+	return true, nil
+}
+
 func validateCertExtensions(ce CertExtensions, co *CheckOpts) error {
 	if co.CertGithubWorkflowTrigger != "" {
 		if ce.GetCertExtensionGithubWorkflowTrigger() != co.CertGithubWorkflowTrigger {
@@ -420,7 +490,7 @@ func ValidateAndUnpackCertWithChain(cert *x509.Certificate, chain []*x509.Certif
 }
 
 func tlogValidateEntry(ctx context.Context, client *client.Rekor, rekorPubKeys *TrustedTransparencyLogPubKeys,
-	sig oci.Signature, pem []byte) (*models.LogEntryAnon, error) {
+	pinned *util.SignedCheckpoint, sig oci.Signature, pem []byte) (*models.LogEntryAnon, error) {
 	b64sig, err := sig.Base64Signature()
 	if err != nil {
 		return nil, err
@@ -456,6 +526,11 @@ func tlogValidateEntry(ctx context.Context, client *client.Rekor, rekorPubKeys *
 	if earliestLogEntryTime == nil {
 		return nil, fmt.Errorf("no valid tlog entries found %s", strings.Join(entryVerificationErrs, ", "))
 	}
+	if pinned != nil {
+		if err := verifyPinnedCheckpoint(ctx, client, rekorPubKeys, pinned, &earliestLogEntry); err != nil {
+			return nil, err
+		}
+	}
 	return &earliestLogEntry, nil
 }
 
@@ -471,6 +546,9 @@ func (fos *fakeOCISignatures) Get() ([]oci.Signature, error) {
 // VerifyImageSignatures does all the main cosign checks in a loop, returning the verified signatures.
 // If there were no valid signatures, we return an error.
 func VerifyImageSignatures(ctx context.Context, signedImgRef name.Reference, co *CheckOpts) (checkedSignatures []oci.Signature, bundleVerified bool, err error) {
+	ctx, span := tracing.Start(ctx, "cosign.VerifyImageSignatures", attribute.String("cosign.image", signedImgRef.String()))
+	defer func() { tracing.End(span, err) }()
+
 	// Try first using OCI 1.1 behavior
 	verified, bundleVerified, err := verifyImageSignaturesExperimentalOCI(ctx, signedImgRef, co)
 	if err == nil {
@@ -519,6 +597,32 @@ func VerifyImageSignatures(ctx context.Context, signedImgRef name.Reference, co
 	return verifySignatures(ctx, sigs, h, co)
 }
 
+// referrerSignaturesExperimentalOCI fetches the signatures of the latest
+// manifest that refers to digest with the artifact type of attName, using
+// OCI 1.1+ behavior.
+func referrerSignaturesExperimentalOCI(ctx context.Context, digest name.Digest, attName string, co *CheckOpts) (oci.Signatures, error) {
+	artifactType := ociexperimental.ArtifactType(attName)
+	index, err := ociremote.Referrers(digest, artifactType, co.RegistryClientOpts...)
+	if err != nil {
+		return nil, err
+	}
+	results := index.Manifests
+	numResults := len(results)
+	if numResults == 0 {
+		return nil, fmt.Errorf("unable to locate reference with artifactType %s", artifactType)
+	} else if numResults > 1 {
+		// TODO: if there is more than 1 result.. what does that even mean?
+		ui.Warnf(ctx, "there were a total of %d references with artifactType %s\n", numResults, artifactType)
+	}
+	// TODO: do this smarter using "created" annotations
+	lastResult := results[numResults-1]
+	st, err := name.ParseReference(fmt.Sprintf("%s@%s", digest.Repository, lastResult.Digest.String()))
+	if err != nil {
+		return nil, err
+	}
+	return ociremote.Signatures(st, co.RegistryClientOpts...)
+}
+
 // VerifyLocalImageSignatures verifies signatures from a saved, local image, without any network calls, returning the verified signatures.
 // If there were no valid signatures, we return an error.
 func VerifyLocalImageSignatures(ctx context.Context, path string, co *CheckOpts) (checkedSignatures []oci.Signature, bundleVerified bool, err error) {
@@ -646,6 +750,10 @@ func verifySignatures(ctx context.Context, sigs oci.Signatures, h v1.Hash, co *C
 func verifyInternal(ctx context.Context, sig oci.Signature, h v1.Hash,
 	verifyFn signatureVerificationFn, co *CheckOpts) (
 	bundleVerified bool, err error) {
+	if keys, ok := co.SigVerifier.(TrustedKeys); ok {
+		return verifyWithTrustedKeys(ctx, sig, h, verifyFn, keys, co)
+	}
+
 	var acceptableRFC3161Time, acceptableRekorBundleTime *time.Time // Timestamps for the signature we accept, or nil if not applicable.
 
 	acceptableRFC3161Timestamp, err := VerifyRFC3161Timestamp(sig, co)
@@ -669,7 +777,13 @@ func verifyInternal(ctx context.Context, sig oci.Signature, h v1.Hash,
 				return false, fmt.Errorf("error getting bundle integrated time: %w", err)
 			}
 			acceptableRekorBundleTime = &t
-		} else {
+		}
+		// Only an online lookup returns the inclusion proof needed to check
+		// the entry against the pinned checkpoint.
+		if !bundleVerified || co.RequireOnlineTlog || co.RekorCheckpoint != nil {
+			if co.Offline && (co.RequireOnlineTlog || co.RekorCheckpoint != nil) {
+				return false, errors.New("online transparency log verification is required but offline verification was requested")
+			}
 			// If the --offline flag was specified, fail here. bundleVerified returns false with
 			// no error when there was no bundle provided.
 			if co.Offline {
@@ -686,12 +800,13 @@ func verifyInternal(ctx context.Context, sig oci.Signature, h v1.Hash,
 				return false, err
 			}
 
-			e, err := tlogValidateEntry(ctx, co.RekorClient, co.RekorPubKeys, sig, pemBytes)
+			e, err := tlogValidateEntry(ctx, co.RekorClient, co.RekorPubKeys, co.RekorCheckpoint, sig, pemBytes)
 			if err != nil {
 				return false, err
 			}
 			t := time.Unix(*e.IntegratedTime, 0)
 			acceptableRekorBundleTime = &t
+			ui.Debugf(ctx, "Verified transparency log entry at index %d, integrated at %s", *e.LogIndex, t.UTC().Format(time.RFC3339))
 		}
 	}
 
@@ -736,6 +851,12 @@ func verifyInternal(ctx context.Context, sig oci.Signature, h v1.Hash,
 		return false, err
 	}
 
+	if co.PIVAttestation != nil {
+		if err := checkPIVAttestation(ctx, sig, verifyFn, co.PIVAttestation); err != nil {
+			return false, err
+		}
+	}
+
 	// We can't check annotations without claims, both require unmarshalling the payload.
 	if co.ClaimVerifier != nil {
 		if err := co.ClaimVerifier(sig, h, co.Annotations); err != nil {
@@ -781,6 +902,12 @@ func verifyInternal(ctx context.Context, sig oci.Signature, h v1.Hash,
 		}
 	}
 
+	if co.MaxSignatureAge > 0 {
+		if err := checkSignatureAge(co.MaxSignatureAge, time.Now(), acceptableRFC3161Time, acceptableRekorBundleTime); err != nil {
+			return false, err
+		}
+	}
+
 	return bundleVerified, nil
 }
 
@@ -858,6 +985,15 @@ func loadSignatureFromFile(ctx context.Context, sigRef string, signedImgRef name
 // VerifyImageAttestations does all the main cosign checks in a loop, returning the verified attestations.
 // If there were no valid attestations, we return an error.
 func VerifyImageAttestations(ctx context.Context, signedImgRef name.Reference, co *CheckOpts) (checkedAttestations []oci.Signature, bundleVerified bool, err error) {
+	ctx, span := tracing.Start(ctx, "cosign.VerifyImageAttestations", attribute.String("cosign.image", signedImgRef.String()))
+	defer func() { tracing.End(span, err) }()
+
+	// Try first using OCI 1.1 behavior
+	verified, bundleVerified, err := verifyImageAttestationsExperimentalOCI(ctx, signedImgRef, co)
+	if err == nil {
+		return verified, bundleVerified, nil
+	}
+
 	// Enforce this up front.
 	if co.RootCerts == nil && co.SigVerifier == nil {
 		return nil, false, errors.New("one of verifier or root certs is required")
@@ -886,6 +1022,30 @@ func VerifyImageAttestations(ctx context.Context, signedImgRef name.Reference, c
 	return VerifyImageAttestation(ctx, atts, h, co)
 }
 
+// verifyImageAttestationsExperimentalOCI does all the main cosign checks in a loop, returning the verified attestations.
+// If there were no valid attestations, we return an error, using OCI 1.1+ behavior.
+func verifyImageAttestationsExperimentalOCI(ctx context.Context, signedImgRef name.Reference, co *CheckOpts) (checkedAttestations []oci.Signature, bundleVerified bool, err error) {
+	// Enforce this up front.
+	if co.RootCerts == nil && co.SigVerifier == nil {
+		return nil, false, errors.New("one of verifier or root certs is required")
+	}
+
+	digest, err := ociremote.ResolveDigest(signedImgRef, co.RegistryClientOpts...)
+	if err != nil {
+		return nil, false, err
+	}
+	h, err := v1.NewHash(digest.Identifier())
+	if err != nil {
+		return nil, false, err
+	}
+	atts, err := referrerSignaturesExperimentalOCI(ctx, digest, "att", co)
+	if err != nil {
+		return nil, false, err
+	}
+
+	return VerifyImageAttestation(ctx, atts, h, co)
+}
+
 // VerifyLocalImageAttestations verifies attestations from a saved, local image, without any network calls,
 // returning the verified attestations.
 // If there were no valid signatures, we return an error.
@@ -932,9 +1092,54 @@ func VerifyLocalImageAttestations(ctx context.Context, path string, co *CheckOpt
 	return VerifyImageAttestation(ctx, atts, h, co)
 }
 
+// VerifyImageAttestationBundle verifies the attestation stored in a local
+// bundle, as written by `cosign attest --bundle`, for the image with digest h
+// without accessing the registry. With co.Offline, the transparency log
+// inclusion is verified using the signed entry timestamp of the bundle.
+func VerifyImageAttestationBundle(ctx context.Context, b *LocalSignedPayload, h v1.Hash, co *CheckOpts) (checkedAttestations []oci.Signature, bundleVerified bool, err error) {
+	// Enforce this up front.
+	if co.RootCerts == nil && co.SigVerifier == nil {
+		return nil, false, errors.New("one of verifier or root certs is required")
+	}
+
+	envelope, err := base64.StdEncoding.DecodeString(b.Base64Signature)
+	if err != nil {
+		return nil, false, fmt.Errorf("decoding attestation: %w", err)
+	}
+	opts := []static.Option{static.WithLayerMediaType(types.DssePayloadType)}
+	if b.Bundle != nil {
+		opts = append(opts, static.WithBundle(b.Bundle))
+	}
+	if b.Cert != "" {
+		// The bundle holds either the signing certificate or the public key.
+		pemBytes, err := base64.StdEncoding.DecodeString(b.Cert)
+		if err != nil {
+			return nil, false, fmt.Errorf("decoding certificate: %w", err)
+		}
+		if certs, err := cryptoutils.UnmarshalCertificatesFromPEM(pemBytes); err == nil && len(certs) > 0 {
+			opts = append(opts, static.WithCertChain(pemBytes, nil))
+		}
+	}
+	att, err := static.NewAttestation(envelope, opts...)
+	if err != nil {
+		return nil, false, err
+	}
+
+	return VerifyImageAttestation(ctx, &fakeOCISignatures{signatures: []oci.Signature{att}}, h, co)
+}
+
 func VerifyBlobAttestation(ctx context.Context, att oci.Signature, h v1.Hash, co *CheckOpts) (
 	bool, error) {
-	return verifyInternal(ctx, att, h, verifyOCIAttestation, co)
+	bundleVerified, err := verifyInternal(ctx, att, h, verifyOCIAttestation, co)
+	if err != nil {
+		return false, err
+	}
+	if co.CheckPredicateExpiry {
+		if err := checkPredicateExpiry(att, time.Now()); err != nil {
+			return false, err
+		}
+	}
+	return bundleVerified, nil
 }
 
 func VerifyImageAttestation(ctx context.Context, atts oci.Signatures, h v1.Hash, co *CheckOpts) (checkedAttestations []oci.Signature, bundleVerified bool, err error) {
@@ -953,6 +1158,10 @@ func VerifyImageAttestation(ctx context.Context, atts oci.Signatures, h v1.Hash,
 	t := throttler.New(workers, len(sl))
 	for i, att := range sl {
 		go func(att oci.Signature, index int) {
+			if err := checkAttestationSize(att, co.MaxAttestationSize); err != nil {
+				t.Done(err)
+				return
+			}
 			att, err := static.Copy(att)
 			if err != nil {
 				t.Done(err)
@@ -961,7 +1170,13 @@ func VerifyImageAttestation(ctx context.Context, atts oci.Signatures, h v1.Hash,
 			if err := func(att oci.Signature) error {
 				verified, err := verifyInternal(ctx, att, h, verifyOCIAttestation, co)
 				bundlesVerified[index] = verified
-				return err
+				if err != nil {
+					return err
+				}
+				if co.CheckPredicateExpiry {
+					return checkPredicateExpiry(att, time.Now())
+				}
+				return nil
 			}(att); err != nil {
 				t.Done(err)
 				return
@@ -999,6 +1214,25 @@ func VerifyImageAttestation(ctx context.Context, atts oci.Signatures, h v1.Hash,
 	return checkedAttestations, bundleVerified, nil
 }
 
+// checkAttestationSize rejects att when it is larger than limit bytes. The size
+// comes from the layer descriptor, so oversized attestations are never
+// downloaded.
+func checkAttestationSize(att oci.Signature, limit int64) error {
+	if limit <= 0 {
+		return nil
+	}
+	size, err := att.Size()
+	if err != nil {
+		return err
+	}
+	if size > limit {
+		return &VerificationFailure{
+			fmt.Errorf("attestation of %d bytes exceeds the maximum size of %d bytes", size, limit),
+		}
+	}
+	return nil
+}
+
 // CheckExpiry confirms the time provided is within the valid period of the cert
 func CheckExpiry(cert *x509.Certificate, it time.Time) error {
 	ft := func(t time.Time) string {
@@ -1261,31 +1495,47 @@ func bundleSig(bundleBody string) (string, error) {
 
 // bundleKey extracts the key from the rekor bundle body
 func bundleKey(bundleBody string) (string, error) {
-	ei, err := extractEntryImpl(bundleBody)
+	keys, err := bundleKeys(bundleBody)
 	if err != nil {
 		return "", err
 	}
+	if len(keys) > 1 {
+		return "", errors.New("multiple signatures on DSSE envelopes are not currently supported")
+	}
+	return keys[0], nil
+}
 
+// bundleKeys returns the base64 encoded public keys or certificates of
+// every signature of the entry of bundleBody.
+func bundleKeys(bundleBody string) ([]string, error) {
+	ei, err := extractEntryImpl(bundleBody)
+	if err != nil {
+		return nil, err
+	}
+
+	var keys []string
 	switch entry := ei.(type) {
 	case *dsse_v001.V001Entry:
-		if len(entry.DSSEObj.Signatures) > 1 {
-			return "", errors.New("multiple signatures on DSSE envelopes are not currently supported")
+		for _, sig := range entry.DSSEObj.Signatures {
+			keys = append(keys, sig.Verifier.String())
 		}
-		return entry.DSSEObj.Signatures[0].Verifier.String(), nil
 	case *hashedrekord_v001.V001Entry:
-		return entry.HashedRekordObj.Signature.PublicKey.Content.String(), nil
+		keys = append(keys, entry.HashedRekordObj.Signature.PublicKey.Content.String())
 	case *intoto_v001.V001Entry:
-		return entry.IntotoObj.PublicKey.String(), nil
+		keys = append(keys, entry.IntotoObj.PublicKey.String())
 	case *intoto_v002.V002Entry:
-		if len(entry.IntotoObj.Content.Envelope.Signatures) > 1 {
-			return "", errors.New("multiple signatures on DSSE envelopes are not currently supported")
+		for _, sig := range entry.IntotoObj.Content.Envelope.Signatures {
+			keys = append(keys, sig.PublicKey.String())
 		}
-		return entry.IntotoObj.Content.Envelope.Signatures[0].PublicKey.String(), nil
 	case *rekord_v001.V001Entry:
-		return entry.RekordObj.Signature.PublicKey.Content.String(), nil
+		keys = append(keys, entry.RekordObj.Signature.PublicKey.Content.String())
 	default:
-		return "", errors.New("unsupported type")
+		return nil, errors.New("unsupported type")
 	}
+	if len(keys) == 0 {
+		return nil, errors.New("no signatures")
+	}
+	return keys, nil
 }
 
 func VerifySET(bundlePayload cbundle.RekorPayload, signature []byte, pub *ecdsa.PublicKey) error {
@@ -1357,26 +1607,7 @@ func verifyImageSignaturesExperimentalOCI(ctx context.Context, signedImgRef name
 	var sigs oci.Signatures
 	sigRef := co.SignatureRef
 	if sigRef == "" {
-		artifactType := ociexperimental.ArtifactType("sig")
-		index, err := ociremote.Referrers(digest, artifactType, co.RegistryClientOpts...)
-		if err != nil {
-			return nil, false, err
-		}
-		results := index.Manifests
-		numResults := len(results)
-		if numResults == 0 {
-			return nil, false, fmt.Errorf("unable to locate reference with artifactType %s", artifactType)
-		} else if numResults > 1 {
-			// TODO: if there is more than 1 result.. what does that even mean?
-			ui.Warnf(ctx, "there were a total of %d references with artifactType %s\n", numResults, artifactType)
-		}
-		// TODO: do this smarter using "created" annotations
-		lastResult := results[numResults-1]
-		st, err := name.ParseReference(fmt.Sprintf("%s@%s", digest.Repository, lastResult.Digest.String()))
-		if err != nil {
-			return nil, false, err
-		}
-		sigs, err = ociremote.Signatures(st, co.RegistryClientOpts...)
+		sigs, err = referrerSignaturesExperimentalOCI(ctx, digest, "sig", co)
 		if err != nil {
 			return nil, false, err
 		}
@@ -54,6 +54,7 @@ import (
 	rtypes "github.com/sigstore/rekor/pkg/types"
 	"github.com/sigstore/sigstore/pkg/cryptoutils"
 	"github.com/sigstore/sigstore/pkg/signature"
+	sigdsse "github.com/sigstore/sigstore/pkg/signature/dsse"
 	"github.com/sigstore/sigstore/pkg/signature/options"
 	"github.com/sigstore/sigstore/pkg/tuf"
 	"github.com/stretchr/testify/require"
@@ -135,6 +136,80 @@ func Test_verifyOCIAttestation(t *testing.T) {
 	}
 }
 
+func TestCheckAttestationSize(t *testing.T) {
+	att, err := static.NewAttestation([]byte(`{"payloadType":"application/vnd.in-toto+json","payload":""}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	size, err := att.Size()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, tc := range []struct {
+		limit   int64
+		wantErr bool
+	}{
+		{limit: 0},
+		{limit: size},
+		{limit: size - 1, wantErr: true},
+	} {
+		err := checkAttestationSize(att, tc.limit)
+		if gotErr := err != nil; gotErr != tc.wantErr {
+			t.Errorf("checkAttestationSize(%d) = %v, wanted error: %t", tc.limit, err, tc.wantErr)
+		}
+		var vf *VerificationFailure
+		if tc.wantErr && !errors.As(err, &vf) {
+			t.Errorf("checkAttestationSize(%d) = %v, want a VerificationFailure", tc.limit, err)
+		}
+	}
+}
+
+func TestVerifyImageAttestationBundle(t *testing.T) {
+	ctx := context.Background()
+	sv, _, err := signature.NewECDSASignerVerifier(elliptic.P256(), rand.Reader, crypto.SHA256)
+	if err != nil {
+		t.Fatalf("creating signer: %v", err)
+	}
+	digest := sha256.Sum256([]byte("image"))
+	h := v1.Hash{Algorithm: "sha256", Hex: hex.EncodeToString(digest[:])}
+	stmt, err := json.Marshal(in_toto.Statement{
+		StatementHeader: in_toto.StatementHeader{
+			Type:          in_toto.StatementInTotoV01,
+			PredicateType: "https://example.com/predicate",
+			Subject:       []in_toto.Subject{{Name: "image", Digest: map[string]string{"sha256": h.Hex}}},
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	envelope, err := sigdsse.WrapSigner(sv, types.IntotoPayloadType).SignMessage(bytes.NewReader(stmt))
+	if err != nil {
+		t.Fatal(err)
+	}
+	b := &LocalSignedPayload{Base64Signature: base64.StdEncoding.EncodeToString(envelope)}
+	co := &CheckOpts{
+		SigVerifier:   sv,
+		ClaimVerifier: IntotoSubjectClaimVerifier,
+		IgnoreTlog:    true,
+	}
+
+	verified, _, err := VerifyImageAttestationBundle(ctx, b, h, co)
+	if err != nil {
+		t.Fatalf("VerifyImageAttestationBundle() = %v", err)
+	}
+	if len(verified) != 1 {
+		t.Fatalf("expected 1 verified attestation, got %d", len(verified))
+	}
+
+	other := sha256.Sum256([]byte("other image"))
+	if _, _, err := VerifyImageAttestationBundle(ctx, b, v1.Hash{Algorithm: "sha256", Hex: hex.EncodeToString(other[:])}, co); err == nil {
+		t.Error("expected an error verifying the bundle against another image digest")
+	}
+	if _, _, err := VerifyImageAttestationBundle(ctx, b, h, &CheckOpts{IgnoreTlog: true}); err == nil {
+		t.Error("expected an error without a verifier or root certs")
+	}
+}
+
 func TestVerifyImageSignature(t *testing.T) {
 	rootCert, rootKey, _ := test.GenerateRootCa()
 	subCert, subKey, _ := test.GenerateSubordinateCa(rootCert, rootKey)
@@ -280,6 +355,60 @@ func TestVerifyImageSignatureWithNoChain(t *testing.T) {
 		t.Fatalf("expected verified=true, got verified=false")
 	}
 }
+func TestVerifyImageSignatureRequireOnlineTlog(t *testing.T) {
+	ctx := context.Background()
+	rootCert, rootKey, _ := test.GenerateRootCa()
+	sv, _, err := signature.NewECDSASignerVerifier(elliptic.P256(), rand.Reader, crypto.SHA256)
+	if err != nil {
+		t.Fatalf("creating signer: %v", err)
+	}
+
+	leafCert, privKey, _ := test.GenerateLeafCert("subject@mail.com", "oidc-issuer", rootCert, rootKey)
+	pemLeaf := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: leafCert.Raw})
+
+	rootPool := x509.NewCertPool()
+	rootPool.AddCert(rootCert)
+
+	payload := []byte{1, 2, 3, 4}
+	h := sha256.Sum256(payload)
+	signature, _ := privKey.Sign(rand.Reader, h[:], crypto.SHA256)
+
+	// Create a fake bundle, that verifies offline
+	pe, _ := proposedEntries(base64.StdEncoding.EncodeToString(signature), payload, pemLeaf)
+	entry, _ := rtypes.UnmarshalEntry(pe[0])
+	leaf, _ := entry.Canonicalize(ctx)
+	rekorBundle := CreateTestBundle(ctx, t, sv, leaf)
+	pemBytes, _ := cryptoutils.MarshalPublicKeyToPEM(sv.Public())
+	rekorPubKeys := NewTrustedTransparencyLogPubKeys()
+	rekorPubKeys.AddTransparencyLogPubKey(pemBytes, tuf.Active)
+
+	opts := []static.Option{static.WithCertChain(pemLeaf, []byte{}), static.WithBundle(rekorBundle)}
+	ociSig, _ := static.NewSignature(payload, base64.StdEncoding.EncodeToString(signature), opts...)
+
+	for _, tc := range []struct {
+		name    string
+		offline bool
+		wantErr string
+	}{
+		{name: "online lookup", wantErr: "rekor client not provided"},
+		{name: "offline", offline: true, wantErr: "offline verification was requested"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := VerifyImageSignature(ctx, ociSig, v1.Hash{},
+				&CheckOpts{
+					RootCerts:         rootPool,
+					IgnoreSCT:         true,
+					Identities:        []Identity{{Subject: "subject@mail.com", Issuer: "oidc-issuer"}},
+					RekorPubKeys:      &rekorPubKeys,
+					Offline:           tc.offline,
+					RequireOnlineTlog: true})
+			if err == nil || !strings.Contains(err.Error(), tc.wantErr) {
+				t.Fatalf("expected error containing %q, got %v", tc.wantErr, err)
+			}
+		})
+	}
+}
+
 func TestVerifyImageSignatureWithInvalidPublicKeyType(t *testing.T) {
 	ctx := context.Background()
 	rootCert, rootKey, _ := test.GenerateRootCa()
@@ -1176,6 +1305,31 @@ func TestValidateAndUnpackCertWithIdentities(t *testing.T) {
 			{SubjectRegExp: ".*url.examp.*", IssuerRegExp: ".*accounts.google.*"}},
 			uris:             uriSubjects,
 			wantErrSubstring: ""},
+		{identities: []Identity{ // email SAN
+			{Email: emailSubject, Issuer: oidcIssuer}},
+			emailAddresses: []string{emailSubject}},
+		{identities: []Identity{ // email given as a URI SAN does not match
+			{Email: "mailto:" + emailSubject, Issuer: oidcIssuer}},
+			uris:             []*url.URL{{Scheme: "mailto", Opaque: emailSubject}},
+			wantErrSubstring: "none of the expected identities matched"},
+		{identities: []Identity{ // URI SAN
+			{URI: u.String(), Issuer: oidcIssuer}},
+			uris: uriSubjects},
+		{identities: []Identity{ // URI SAN required on top of the subject regex
+			{SubjectRegExp: ".*example.com", URI: u.String()}},
+			emailAddresses:   []string{emailSubject},
+			wantErrSubstring: "none of the expected identities matched"},
+		{identities: []Identity{ // URI SAN regex
+			{URIRegExp: "^http://url\\.example\\.com$", Issuer: oidcIssuer}},
+			uris: uriSubjects},
+		{identities: []Identity{ // URI SAN regex does not match other SANs
+			{URIRegExp: ".*example.com", Issuer: oidcIssuer}},
+			emailAddresses:   []string{emailSubject},
+			wantErrSubstring: "none of the expected identities matched"},
+		{identities: []Identity{ // illegal regex for URI
+			{URIRegExp: "****", Issuer: oidcIssuer}},
+			uris:             uriSubjects,
+			wantErrSubstring: "malformed URI in identity"},
 		{identities: []Identity{ // regex matches otherName
 			{SubjectRegExp: ".*example.com", IssuerRegExp: ".*accounts.google.*"}},
 			otherName:        otherName,
@@ -0,0 +1,133 @@
+//
+// Copyright 2023 The Sigstore Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package wasm gates the loading of WASM modules stored in OCI registries,
+// e.g. uploaded with `cosign upload wasm`, on the verification of their
+// signatures. Runtimes call Fetch instead of pulling the module themselves,
+// so that a module is only returned once its signatures verified.
+package wasm
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/google/go-containerregistry/pkg/name"
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/sigstore/cosign/v2/pkg/cosign"
+	"github.com/sigstore/cosign/v2/pkg/oci"
+	ociremote "github.com/sigstore/cosign/v2/pkg/oci/remote"
+	"github.com/sigstore/cosign/v2/pkg/types"
+)
+
+// MaxModuleSize bounds the size of the modules fetched.
+const MaxModuleSize = 256 << 20
+
+// magic starts every binary WASM module.
+var magic = []byte("\x00asm")
+
+// Module is a WASM module whose signatures verified.
+type Module struct {
+	// Digest is the digest of the manifest of the module, whose signatures
+	// verified. It doesn't move when the tag of the module does.
+	Digest name.Digest
+	// Content is the binary WASM module.
+	Content []byte
+	// Signatures are the signatures of the module that verified.
+	Signatures []oci.Signature
+	// BundleVerified is true when the signatures were verified offline
+	// against their transparency log bundle.
+	BundleVerified bool
+}
+
+// Fetch verifies the signatures of the WASM module ref against co and
+// returns the module, which is fetched by the digest its signatures were
+// verified for. The registry is accessed with co.RegistryClientOpts.
+func Fetch(ctx context.Context, ref name.Reference, co *cosign.CheckOpts) (*Module, error) {
+	digest, err := ociremote.ResolveDigest(ref, co.RegistryClientOpts...)
+	if err != nil {
+		return nil, fmt.Errorf("resolving digest of %s: %w", ref, err)
+	}
+	if co.ClaimVerifier == nil {
+		// Copy co, which may be shared by the caller.
+		withClaims := *co
+		withClaims.ClaimVerifier = cosign.SimpleClaimVerifier
+		co = &withClaims
+	}
+	verified, bundleVerified, err := cosign.VerifyImageSignatures(ctx, digest, co)
+	if err != nil {
+		return nil, fmt.Errorf("verifying signatures of %s: %w", digest, err)
+	}
+	img, err := ociremote.SignedImage(digest, co.RegistryClientOpts...)
+	if err != nil {
+		return nil, fmt.Errorf("fetching %s: %w", digest, err)
+	}
+	content, err := Content(img)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", digest, err)
+	}
+	return &Module{
+		Digest:         digest,
+		Content:        content,
+		Signatures:     verified,
+		BundleVerified: bundleVerified,
+	}, nil
+}
+
+// Layer returns the layer of the WASM module img, failing when img isn't a
+// WASM module: a single layer of media type types.WasmLayerMediaType.
+func Layer(img v1.Image) (v1.Layer, error) {
+	m, err := img.Manifest()
+	if err != nil {
+		return nil, err
+	}
+	if len(m.Layers) != 1 {
+		return nil, fmt.Errorf("expected a WASM module with a single layer, got %d layers", len(m.Layers))
+	}
+	if mt := m.Layers[0].MediaType; string(mt) != types.WasmLayerMediaType {
+		return nil, fmt.Errorf("expected a WASM module layer of media type %s, got %s", types.WasmLayerMediaType, mt)
+	}
+	if m.Layers[0].Size > MaxModuleSize {
+		return nil, fmt.Errorf("WASM module of %d bytes exceeds the maximum size of %d bytes", m.Layers[0].Size, MaxModuleSize)
+	}
+	return img.LayerByDigest(m.Layers[0].Digest)
+}
+
+// Content returns the binary module of the WASM module img.
+func Content(img v1.Image) ([]byte, error) {
+	layer, err := Layer(img)
+	if err != nil {
+		return nil, err
+	}
+	// The layer is stored as is, and its digest is checked while reading it.
+	rc, err := layer.Compressed()
+	if err != nil {
+		return nil, err
+	}
+	defer rc.Close()
+	b, err := io.ReadAll(io.LimitReader(rc, MaxModuleSize+1))
+	if err != nil {
+		return nil, fmt.Errorf("reading WASM module: %w", err)
+	}
+	if len(b) > MaxModuleSize {
+		return nil, fmt.Errorf("WASM module exceeds the maximum size of %d bytes", MaxModuleSize)
+	}
+	if !bytes.HasPrefix(b, magic) {
+		return nil, errors.New("layer is not a binary WASM module")
+	}
+	return b, nil
+}
@@ -0,0 +1,171 @@
+//
+// Copyright 2023 The Sigstore Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package wasm
+
+import (
+	"bytes"
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"encoding/base64"
+	"io"
+	"log"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/google/go-containerregistry/pkg/name"
+	"github.com/google/go-containerregistry/pkg/registry"
+	"github.com/google/go-containerregistry/pkg/v1/random"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
+	ocitypes "github.com/google/go-containerregistry/pkg/v1/types"
+	"github.com/sigstore/cosign/v2/pkg/cosign"
+	"github.com/sigstore/cosign/v2/pkg/oci/mutate"
+	ociremote "github.com/sigstore/cosign/v2/pkg/oci/remote"
+	"github.com/sigstore/cosign/v2/pkg/oci/static"
+	"github.com/sigstore/cosign/v2/pkg/types"
+	"github.com/sigstore/sigstore/pkg/signature"
+	"github.com/sigstore/sigstore/pkg/signature/payload"
+)
+
+// module is the smallest binary WASM module: its magic and version.
+var module = []byte("\x00asm\x01\x00\x00\x00")
+
+func TestContent(t *testing.T) {
+	tests := []struct {
+		name    string
+		content []byte
+		layer   ocitypes.MediaType
+		wantErr string
+	}{{
+		name:    "module",
+		content: module,
+		layer:   types.WasmLayerMediaType,
+	}, {
+		name:    "not a module",
+		content: []byte("#!/bin/sh"),
+		layer:   types.WasmLayerMediaType,
+		wantErr: "not a binary WASM module",
+	}, {
+		name:    "other media type",
+		content: module,
+		layer:   "text/plain",
+		wantErr: "media type",
+	}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			img, err := static.NewFile(tt.content, static.WithLayerMediaType(tt.layer), static.WithConfigMediaType(types.WasmConfigMediaType))
+			if err != nil {
+				t.Fatal(err)
+			}
+			got, err := Content(img)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("Content() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.Equal(got, tt.content) {
+				t.Errorf("Content() = %x, want %x", got, tt.content)
+			}
+		})
+	}
+
+	img, err := random.Image(10, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Content(img); err == nil || !strings.Contains(err.Error(), "single layer") {
+		t.Errorf("Content() of an image error = %v, want a single layer error", err)
+	}
+}
+
+func TestFetch(t *testing.T) {
+	s := httptest.NewServer(registry.New(registry.Logger(log.New(io.Discard, "", 0))))
+	defer s.Close()
+	ref, err := name.ParseReference(strings.TrimPrefix(s.URL, "http://") + "/modules/hello:v1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	img, err := static.NewFile(module, static.WithLayerMediaType(types.WasmLayerMediaType), static.WithConfigMediaType(types.WasmConfigMediaType))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := remote.Write(ref, img); err != nil {
+		t.Fatal(err)
+	}
+
+	priv, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sv, err := signature.LoadECDSASignerVerifier(priv, crypto.SHA256)
+	if err != nil {
+		t.Fatal(err)
+	}
+	co := &cosign.CheckOpts{SigVerifier: sv, IgnoreTlog: true}
+
+	if _, err := Fetch(context.Background(), ref, co); err == nil {
+		t.Fatal("Fetch() of an unsigned module succeeded")
+	}
+
+	digest, err := ociremote.ResolveDigest(ref)
+	if err != nil {
+		t.Fatal(err)
+	}
+	p, err := payload.Cosign{Image: digest}.MarshalJSON()
+	if err != nil {
+		t.Fatal(err)
+	}
+	sig, err := sv.SignMessage(bytes.NewReader(p))
+	if err != nil {
+		t.Fatal(err)
+	}
+	ociSig, err := static.NewSignature(p, base64.StdEncoding.EncodeToString(sig))
+	if err != nil {
+		t.Fatal(err)
+	}
+	se, err := ociremote.SignedEntity(digest)
+	if err != nil {
+		t.Fatal(err)
+	}
+	se, err = mutate.AttachSignatureToEntity(se, ociSig)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := ociremote.WriteSignatures(digest.Repository, se); err != nil {
+		t.Fatal(err)
+	}
+
+	m, err := Fetch(context.Background(), ref, co)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(m.Content, module) {
+		t.Errorf("Fetch() content = %x, want %x", m.Content, module)
+	}
+	if m.Digest.String() != digest.String() {
+		t.Errorf("Fetch() digest = %s, want %s", m.Digest, digest)
+	}
+	if len(m.Signatures) != 1 {
+		t.Errorf("Fetch() signatures = %d, want 1", len(m.Signatures))
+	}
+}
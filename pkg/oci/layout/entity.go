@@ -0,0 +1,94 @@
+//
+// Copyright 2023 The Sigstore Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package layout
+
+import (
+	"fmt"
+
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/sigstore/cosign/v2/pkg/oci"
+	"github.com/sigstore/cosign/v2/pkg/oci/empty"
+)
+
+// SignedEntity returns the image or image index saved in the layout at path,
+// together with the signatures and attestations saved next to it. New ones
+// attached to it are saved with WriteSignatures and WriteAttestations.
+func SignedEntity(path string) (oci.SignedEntity, error) {
+	sii, err := SignedImageIndex(path)
+	if err != nil {
+		return nil, err
+	}
+	ii, err := sii.SignedImageIndex(v1.Hash{})
+	if err != nil {
+		return nil, err
+	}
+	if ii != nil {
+		h, err := ii.Digest()
+		if err != nil {
+			return nil, err
+		}
+		return &entity{layout: sii, digest: h}, nil
+	}
+	img, err := sii.SignedImage(v1.Hash{})
+	if err != nil {
+		return nil, err
+	}
+	if img == nil {
+		return nil, fmt.Errorf("no image or image index found in %s", path)
+	}
+	h, err := img.Digest()
+	if err != nil {
+		return nil, err
+	}
+	return &entity{layout: sii, digest: h}, nil
+}
+
+// entity is the image or image index of a layout, whose signatures and
+// attestations are stored in the layout rather than in the entity itself.
+type entity struct {
+	layout oci.SignedImageIndex
+	digest v1.Hash
+}
+
+var _ oci.SignedEntity = (*entity)(nil)
+
+// Digest implements oci.SignedEntity
+func (e *entity) Digest() (v1.Hash, error) {
+	return e.digest, nil
+}
+
+// Signatures implements oci.SignedEntity
+func (e *entity) Signatures() (oci.Signatures, error) {
+	sigs, err := e.layout.Signatures()
+	if err != nil || sigs != nil {
+		return sigs, err
+	}
+	return empty.Signatures(), nil
+}
+
+// Attestations implements oci.SignedEntity
+func (e *entity) Attestations() (oci.Signatures, error) {
+	atts, err := e.layout.Attestations()
+	if err != nil || atts != nil {
+		return atts, err
+	}
+	return empty.Signatures(), nil
+}
+
+// Attachment implements oci.SignedEntity
+func (e *entity) Attachment(name string) (oci.File, error) {
+	return e.layout.Attachment(name)
+}
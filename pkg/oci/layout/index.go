@@ -20,6 +20,7 @@ import (
 
 	v1 "github.com/google/go-containerregistry/pkg/v1"
 	"github.com/google/go-containerregistry/pkg/v1/layout"
+	"github.com/google/go-containerregistry/pkg/v1/types"
 	"github.com/sigstore/cosign/v2/pkg/oci"
 	"github.com/sigstore/cosign/v2/pkg/oci/signed"
 )
@@ -93,6 +94,12 @@ func (i *index) SignedImage(h v1.Hash) (oci.SignedImage, error) {
 	var err error
 	if h.String() == ":" {
 		img, err = i.imageByAnnotation(imageAnnotation)
+		if err == nil && img == nil {
+			var d *v1.Descriptor
+			if d, err = i.unannotatedManifest(types.MediaType.IsImage); d != nil {
+				img, err = i.Image(d.Digest)
+			}
+		}
 	} else {
 		img, err = i.Image(h)
 	}
@@ -120,6 +127,28 @@ func (i *index) imageByAnnotation(annotation string) (v1.Image, error) {
 	return nil, nil
 }
 
+// unannotatedManifest returns the only manifest in the index.json without a
+// kind annotation whose media type is accepted, or nil if there is none.
+// Layouts not written by cosign, e.g. by `crane pull --format oci`, hold the
+// image that way.
+func (i *index) unannotatedManifest(accept func(types.MediaType) bool) (*v1.Descriptor, error) {
+	manifest, err := i.IndexManifest()
+	if err != nil {
+		return nil, err
+	}
+	var found *v1.Descriptor
+	for j, m := range manifest.Manifests {
+		if _, ok := m.Annotations[kindAnnotation]; ok || !accept(m.MediaType) {
+			continue
+		}
+		if found != nil {
+			return nil, fmt.Errorf("found more than one manifest of type %s in the layout", m.MediaType)
+		}
+		found = &manifest.Manifests[j]
+	}
+	return found, nil
+}
+
 func (i *index) imageIndexByAnnotation(annotation string) (v1.ImageIndex, error) {
 	manifest, err := i.IndexManifest()
 	if err != nil {
@@ -139,6 +168,12 @@ func (i *index) SignedImageIndex(h v1.Hash) (oci.SignedImageIndex, error) {
 	var err error
 	if h.String() == ":" {
 		ii, err = i.imageIndexByAnnotation(imageIndexAnnotation)
+		if err == nil && ii == nil {
+			var d *v1.Descriptor
+			if d, err = i.unannotatedManifest(types.MediaType.IsIndex); d != nil {
+				ii, err = i.ImageIndex(d.Digest)
+			}
+		}
 	} else {
 		ii, err = i.ImageIndex(h)
 	}
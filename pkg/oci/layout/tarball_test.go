@@ -0,0 +1,97 @@
+//
+// Copyright 2023 The Sigstore Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package layout
+
+import (
+	"archive/tar"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+)
+
+func TestTarballRoundTrip(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("test is flaky on windows, see https://github.com/sigstore/cosign/v2/issues/1389")
+	}
+	si := randomSignedImage(t)
+	dir := t.TempDir()
+	if err := WriteSignedImage(dir, si); err != nil {
+		t.Fatal(err)
+	}
+	tarball := filepath.Join(t.TempDir(), "image.tar")
+	if err := WriteTarball(dir, tarball); err != nil {
+		t.Fatalf("WriteTarball() = %v", err)
+	}
+
+	extracted := t.TempDir()
+	if err := ExtractTarball(tarball, extracted); err != nil {
+		t.Fatalf("ExtractTarball() = %v", err)
+	}
+	imageIndex, err := SignedImageIndex(extracted)
+	if err != nil {
+		t.Fatal(err)
+	}
+	gotSignedImage, err := imageIndex.SignedImage(v1.Hash{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	compareDigests(t, si, gotSignedImage)
+
+	sigImage, err := imageIndex.Signatures()
+	if err != nil {
+		t.Fatal(err)
+	}
+	sigs, err := sigImage.Get()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(sigs) != 6 {
+		t.Errorf("got %d signatures, want 6", len(sigs))
+	}
+}
+
+func TestExtractTarballInvalidPath(t *testing.T) {
+	tarball := filepath.Join(t.TempDir(), "evil.tar")
+	f, err := os.Create(tarball)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tw := tar.NewWriter(f)
+	content := []byte("evil")
+	if err := tw.WriteHeader(&tar.Header{Name: "../evil", Mode: 0644, Size: int64(len(content)), Typeflag: tar.TypeReg}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := tw.Write(content); err != nil {
+		t.Fatal(err)
+	}
+	if err := tw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := f.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	dir := filepath.Join(t.TempDir(), "layout")
+	if err := ExtractTarball(tarball, dir); err == nil {
+		t.Fatal("ExtractTarball() with a path outside of the layout succeeded")
+	}
+	if _, err := os.Stat(filepath.Join(filepath.Dir(dir), "evil")); !os.IsNotExist(err) {
+		t.Errorf("file outside of the layout was written: %v", err)
+	}
+}
@@ -21,6 +21,7 @@ import (
 	v1 "github.com/google/go-containerregistry/pkg/v1"
 	"github.com/google/go-containerregistry/pkg/v1/empty"
 	"github.com/google/go-containerregistry/pkg/v1/layout"
+	"github.com/google/go-containerregistry/pkg/v1/match"
 	"github.com/sigstore/cosign/v2/pkg/oci"
 )
 
@@ -54,6 +55,26 @@ func WriteSignedImageIndex(path string, si oci.SignedImageIndex) error {
 	return writeSignedEntity(layoutPath, si)
 }
 
+// WriteSignatures replaces the signatures saved in the layout at path with
+// those of se, e.g. after signing an entity read with SignedEntity.
+func WriteSignatures(path string, se oci.SignedEntity) error {
+	sigs, err := se.Signatures()
+	if err != nil {
+		return fmt.Errorf("getting signatures: %w", err)
+	}
+	return replaceImage(path, sigs, sigsAnnotation)
+}
+
+// WriteAttestations replaces the attestations saved in the layout at path
+// with those of se.
+func WriteAttestations(path string, se oci.SignedEntity) error {
+	atts, err := se.Attestations()
+	if err != nil {
+		return fmt.Errorf("getting attestations: %w", err)
+	}
+	return replaceImage(path, atts, attsAnnotation)
+}
+
 func writeSignedEntity(path layout.Path, se oci.SignedEntity) error {
 	// write the signatures
 	sigs, err := se.Signatures()
@@ -91,3 +112,13 @@ func appendImage(path layout.Path, img v1.Image, annotation string) error {
 		map[string]string{kindAnnotation: annotation},
 	))
 }
+
+func replaceImage(path string, img v1.Image, annotation string) error {
+	p, err := layout.FromPath(path)
+	if err != nil {
+		return err
+	}
+	return p.ReplaceImage(img, match.Annotation(kindAnnotation, annotation), layout.WithAnnotations(
+		map[string]string{kindAnnotation: annotation},
+	))
+}
@@ -22,6 +22,8 @@ import (
 
 	"github.com/google/go-cmp/cmp"
 	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/empty"
+	"github.com/google/go-containerregistry/pkg/v1/layout"
 	"github.com/google/go-containerregistry/pkg/v1/random"
 	"github.com/sigstore/cosign/v2/pkg/oci"
 	"github.com/sigstore/cosign/v2/pkg/oci/mutate"
@@ -142,3 +144,76 @@ func compareDigests(t *testing.T, img1 oci.SignedImage, img2 oci.SignedImage) {
 		t.Fatalf("digests are different: %s", d)
 	}
 }
+
+func TestSignPlainLayout(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("test is flaky on windows, see https://github.com/sigstore/cosign/v2/issues/1389")
+	}
+	// write an image without cosign annotations, like `crane pull --format oci`
+	img, err := random.Image(300, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tmp := t.TempDir()
+	p, err := layout.Write(tmp, empty.Index)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := p.AppendImage(img); err != nil {
+		t.Fatal(err)
+	}
+
+	se, err := SignedEntity(tmp)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want, err := img.Digest()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, err := se.Digest(); err != nil || got != want {
+		t.Fatalf("Digest() = %v, %v, want %v", got, err, want)
+	}
+
+	// sign it twice, the second signature is added to the saved one
+	for i := 0; i < 2; i++ {
+		se, err = SignedEntity(tmp)
+		if err != nil {
+			t.Fatal(err)
+		}
+		sig, err := static.NewSignature(nil, fmt.Sprintf("%d", i))
+		if err != nil {
+			t.Fatal(err)
+		}
+		se, err = mutate.AttachSignatureToEntity(se, sig)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := WriteSignatures(tmp, se); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	imageIndex, err := SignedImageIndex(tmp)
+	if err != nil {
+		t.Fatal(err)
+	}
+	gotImage, err := imageIndex.SignedImage(v1.Hash{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, err := gotImage.Digest(); err != nil || got != want {
+		t.Errorf("SignedImage().Digest() = %v, %v, want %v", got, err, want)
+	}
+	sigImg, err := imageIndex.Signatures()
+	if err != nil {
+		t.Fatal(err)
+	}
+	sigs, err := sigImg.Get()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(sigs) != 2 {
+		t.Errorf("got %d signatures, want 2", len(sigs))
+	}
+}
@@ -0,0 +1,90 @@
+//
+// Copyright 2023 The Sigstore Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package remote
+
+import (
+	"fmt"
+	"regexp"
+	"sync"
+
+	"github.com/google/go-containerregistry/pkg/name"
+)
+
+// validSuffix matches the suffixes that keep [prefix]sha256-[digest].[suffix]
+// a valid tag.
+var validSuffix = regexp.MustCompile(`^[a-zA-Z0-9_][a-zA-Z0-9_.-]*$`)
+
+var (
+	attachmentsMu sync.RWMutex
+	// attachmentSuffixes maps the names of attachments to the suffix of the
+	// tags they are attached with. Attachments that aren't registered are
+	// attached with their name as suffix.
+	attachmentSuffixes = map[string]string{
+		"sbom": SBOMTagSuffix,
+		"wasm": "wasm",
+	}
+)
+
+// RegisterAttachment registers the attachment name, attached to images with
+// tags suffixed with suffix, so that it can be signed and verified by name.
+func RegisterAttachment(name, suffix string) error {
+	if !validSuffix.MatchString(suffix) {
+		return fmt.Errorf("invalid tag suffix %q of attachment %s", suffix, name)
+	}
+	attachmentsMu.Lock()
+	defer attachmentsMu.Unlock()
+	attachmentSuffixes[name] = suffix
+	return nil
+}
+
+// AttachmentTagSuffix returns the suffix of the tags of the attachment name:
+// the registered one, or name itself. Signatures and attestations aren't
+// attachments.
+func AttachmentTagSuffix(name string) (string, error) {
+	suffix := attachmentSuffix(name)
+	switch {
+	case suffix == SignatureTagSuffix || suffix == AttestationTagSuffix:
+		return "", fmt.Errorf("%s is not an attachment, signatures and attestations are verified by their own commands", name)
+	case !validSuffix.MatchString(suffix):
+		return "", fmt.Errorf("invalid attachment %q, expected a name made of letters, digits, '_', '.' and '-'", name)
+	}
+	return suffix, nil
+}
+
+// attachmentSuffix returns the registered suffix of the attachment name, or
+// name itself.
+func attachmentSuffix(name string) string {
+	attachmentsMu.RLock()
+	defer attachmentsMu.RUnlock()
+	if suffix, ok := attachmentSuffixes[name]; ok {
+		return suffix
+	}
+	return name
+}
+
+// AttachmentTag returns the name.Tag that associates the attachment name,
+// e.g. sbom, with a particular digest.
+func AttachmentTag(ref name.Reference, attachment string, opts ...Option) (name.Tag, error) {
+	o := makeOptions(ref.Context(), opts...)
+	if attachment == SBOMTagSuffix {
+		return suffixTag(ref, o.SBOMSuffix, o)
+	}
+	suffix, err := AttachmentTagSuffix(attachment)
+	if err != nil {
+		return name.Tag{}, err
+	}
+	return suffixTag(ref, suffix, o)
+}
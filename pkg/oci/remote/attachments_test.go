@@ -0,0 +1,76 @@
+//
+// Copyright 2021 The Sigstore Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package remote
+
+import (
+	"testing"
+
+	"github.com/google/go-containerregistry/pkg/name"
+)
+
+func TestAttachmentTag(t *testing.T) {
+	if err := RegisterAttachment("spdx", "spdx.json"); err != nil {
+		t.Fatal(err)
+	}
+	if err := RegisterAttachment("bad", "sha256:bad"); err == nil {
+		t.Error("RegisterAttachment() with an invalid suffix succeeded")
+	}
+
+	ref := name.MustParseReference("gcr.io/distroless/static@sha256:be5d77c62dbe7fedfb0a4e5ec2f91078080800ab1f18358e5f31fcc8faa023c4")
+	tests := []struct {
+		attachment string
+		opts       []Option
+		want       string
+		wantErr    bool
+	}{{
+		attachment: "sbom",
+		want:       "gcr.io/distroless/static:sha256-be5d77c62dbe7fedfb0a4e5ec2f91078080800ab1f18358e5f31fcc8faa023c4.sbom",
+	}, {
+		attachment: "sbom",
+		opts:       []Option{WithSBOMSuffix("spdx")},
+		want:       "gcr.io/distroless/static:sha256-be5d77c62dbe7fedfb0a4e5ec2f91078080800ab1f18358e5f31fcc8faa023c4.spdx",
+	}, {
+		attachment: "wasm",
+		opts:       []Option{WithPrefix("pre")},
+		want:       "gcr.io/distroless/static:presha256-be5d77c62dbe7fedfb0a4e5ec2f91078080800ab1f18358e5f31fcc8faa023c4.wasm",
+	}, {
+		attachment: "spdx",
+		want:       "gcr.io/distroless/static:sha256-be5d77c62dbe7fedfb0a4e5ec2f91078080800ab1f18358e5f31fcc8faa023c4.spdx.json",
+	}, {
+		attachment: "helm-chart",
+		want:       "gcr.io/distroless/static:sha256-be5d77c62dbe7fedfb0a4e5ec2f91078080800ab1f18358e5f31fcc8faa023c4.helm-chart",
+	}, {
+		attachment: "sig",
+		wantErr:    true,
+	}, {
+		attachment: "att",
+		wantErr:    true,
+	}, {
+		attachment: "../sbom",
+		wantErr:    true,
+	}}
+	for _, tt := range tests {
+		t.Run(tt.attachment, func(t *testing.T) {
+			got, err := AttachmentTag(ref, tt.attachment, tt.opts...)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("AttachmentTag() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err == nil && got.String() != tt.want {
+				t.Errorf("AttachmentTag() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
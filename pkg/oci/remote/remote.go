@@ -55,7 +55,9 @@ func NewEntityNotFoundError(err error) error {
 }
 
 // SignedEntity provides access to a remote reference, and its signatures.
-// The SignedEntity will be one of SignedImage or SignedImageIndex.
+// The SignedEntity will be one of SignedImage or SignedImageIndex, or an
+// entity only giving access to the signatures of other OCI artifacts, e.g.
+// those with an OCI artifact manifest.
 func SignedEntity(ref name.Reference, options ...Option) (oci.SignedEntity, error) {
 	o := makeOptions(ref.Context(), options...)
 
@@ -90,7 +92,12 @@ func SignedEntity(ref name.Reference, options ...Option) (oci.SignedEntity, erro
 		}, nil
 
 	default:
-		return nil, fmt.Errorf("unknown mime type: %v", got.MediaType)
+		// Other artifacts, such as OCI artifact manifests, are signed by
+		// digest like images, through the same tag-based discovery.
+		return &unknown{
+			digest: ref.Context().Digest(got.Digest.String()),
+			opt:    o,
+		}, nil
 	}
 }
 
@@ -168,7 +175,7 @@ func attachment(digestable oci.SignedEntity, attName string, o *options) (oci.Fi
 	if err != nil {
 		return nil, err
 	}
-	img, err := SignedImage(o.TargetRepository.Tag(normalize(h, o.TagPrefix, attName)), o.OriginalOptions...)
+	img, err := SignedImage(o.TargetRepository.Tag(normalize(h, o.TagPrefix, attachmentSuffix(attName))), o.OriginalOptions...)
 	if err != nil {
 		return nil, err
 	}
@@ -147,3 +147,34 @@ func TestTagMethodErrors(t *testing.T) {
 		})
 	}
 }
+
+func TestSignedEntityArtifact(t *testing.T) {
+	rg := remoteGet
+	defer func() {
+		remoteGet = rg
+	}()
+	h := v1.Hash{
+		Algorithm: "sha256",
+		Hex:       "be5d77c62dbe7fedfb0a4e5ec2f91078080800ab1f18358e5f31fcc8faa023c4",
+	}
+	remoteGet = func(ref name.Reference, options ...remote.Option) (*remote.Descriptor, error) {
+		return &remote.Descriptor{
+			Descriptor: v1.Descriptor{
+				MediaType: "application/vnd.oci.artifact.manifest.v1+json",
+				Digest:    h,
+			},
+		}, nil
+	}
+
+	se, err := SignedEntity(name.MustParseReference("example.com/charts/app:1.0.0"))
+	if err != nil {
+		t.Fatalf("SignedEntity() = %v", err)
+	}
+	got, err := se.Digest()
+	if err != nil {
+		t.Fatalf("Digest() = %v", err)
+	}
+	if got != h {
+		t.Errorf("Digest() = %s, wanted %s", got, h)
+	}
+}
@@ -138,6 +138,26 @@ func WriteAttestations(repo name.Repository, se oci.SignedEntity, opts ...Option
 // WriteSignaturesExperimentalOCI publishes the signatures attached to the given entity
 // into the provided repository (using OCI 1.1 methods).
 func WriteSignaturesExperimentalOCI(d name.Digest, se oci.SignedEntity, opts ...Option) error {
+	sigs, err := se.Signatures()
+	if err != nil {
+		return err
+	}
+	return writeReferrerExperimentalOCI(d, sigs, "sig", "signature", opts...)
+}
+
+// WriteAttestationsExperimentalOCI publishes the attestations attached to the given entity
+// into the provided repository (using OCI 1.1 methods).
+func WriteAttestationsExperimentalOCI(d name.Digest, se oci.SignedEntity, opts ...Option) error {
+	atts, err := se.Attestations()
+	if err != nil {
+		return err
+	}
+	return writeReferrerExperimentalOCI(d, atts, "att", "attestation", opts...)
+}
+
+// writeReferrerExperimentalOCI pushes sigs as a manifest whose subject is d, so
+// that it is listed by the referrers API with the artifact type of attName.
+func writeReferrerExperimentalOCI(d name.Digest, sigs oci.Signatures, attName, kind string, opts ...Option) error {
 	o := makeOptions(d.Repository, opts...)
 	signTarget := d.String()
 	ref, err := name.ParseReference(signTarget, o.NameOpts...)
@@ -148,10 +168,6 @@ func WriteSignaturesExperimentalOCI(d name.Digest, se oci.SignedEntity, opts ...
 	if err != nil {
 		return err
 	}
-	sigs, err := se.Signatures()
-	if err != nil {
-		return err
-	}
 
 	// Write the signature blobs
 	s, err := sigs.Get()
@@ -188,7 +204,7 @@ func WriteSignaturesExperimentalOCI(d name.Digest, se oci.SignedEntity, opts ...
 		return err
 	}
 
-	artifactType := ociexperimental.ArtifactType("sig")
+	artifactType := ociexperimental.ArtifactType(attName)
 	m.Config.MediaType = types.MediaType(artifactType)
 	m.Subject = desc
 	b, err = json.Marshal(&m)
@@ -204,8 +220,12 @@ func WriteSignaturesExperimentalOCI(d name.Digest, se oci.SignedEntity, opts ...
 		return err
 	}
 	// TODO: use ui.Infof
-	fmt.Fprintf(os.Stderr, "Uploading signature for [%s] to [%s] with config.mediaType [%s] layers[0].mediaType [%s].\n",
-		d.String(), targetRef.String(), artifactType, ctypes.SimpleSigningMediaType)
+	layerMediaType := types.MediaType(ctypes.SimpleSigningMediaType)
+	if len(m.Layers) > 0 {
+		layerMediaType = m.Layers[0].MediaType
+	}
+	fmt.Fprintf(os.Stderr, "Uploading %s for [%s] to [%s] with config.mediaType [%s] layers[0].mediaType [%s].\n",
+		kind, d.String(), targetRef.String(), artifactType, layerMediaType)
 	return remote.Put(targetRef, &taggableManifest{raw: b, mediaType: m.MediaType}, o.ROpt...)
 }
 
@@ -17,12 +17,18 @@ package remote
 
 import (
 	"fmt"
+	"io"
+	"log"
+	"net/http/httptest"
+	"net/url"
 	"testing"
 
 	"github.com/google/go-containerregistry/pkg/name"
+	"github.com/google/go-containerregistry/pkg/registry"
 	v1 "github.com/google/go-containerregistry/pkg/v1"
 	"github.com/google/go-containerregistry/pkg/v1/random"
 	"github.com/google/go-containerregistry/pkg/v1/remote"
+	ociexperimental "github.com/sigstore/cosign/v2/internal/pkg/oci/remote"
 	"github.com/sigstore/cosign/v2/pkg/oci/mutate"
 	"github.com/sigstore/cosign/v2/pkg/oci/signed"
 	"github.com/sigstore/cosign/v2/pkg/oci/static"
@@ -111,3 +117,56 @@ func TestWriteAttestations(t *testing.T) {
 		t.Fatalf("WriteAttestations() = %v", err)
 	}
 }
+
+func TestWriteAttestationsExperimentalOCI(t *testing.T) {
+	s := httptest.NewServer(registry.New(registry.Logger(log.New(io.Discard, "", 0))))
+	defer s.Close()
+	u, err := url.Parse(s.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	i, err := random.Image(300 /* byteSize */, 1 /* layers */)
+	if err != nil {
+		t.Fatalf("random.Image() = %v", err)
+	}
+	ref, err := name.ParseReference(u.Host + "/app:latest")
+	if err != nil {
+		t.Fatalf("ParseReference() = %v", err)
+	}
+	if err := remote.Write(ref, i); err != nil {
+		t.Fatalf("remote.Write() = %v", err)
+	}
+	h, err := i.Digest()
+	if err != nil {
+		t.Fatalf("Digest() = %v", err)
+	}
+	d := ref.Context().Digest(h.String())
+
+	att, err := static.NewAttestation([]byte(`{"predicateType": "https://example.com/test"}`))
+	if err != nil {
+		t.Fatalf("static.NewAttestation() = %v", err)
+	}
+	si, err := mutate.AttachAttestationToImage(signed.Image(i), att)
+	if err != nil {
+		t.Fatalf("AttachAttestationToImage() = %v", err)
+	}
+	if err := WriteAttestationsExperimentalOCI(d, si); err != nil {
+		t.Fatalf("WriteAttestationsExperimentalOCI() = %v", err)
+	}
+
+	index, err := Referrers(d, ociexperimental.ArtifactType("att"))
+	if err != nil {
+		t.Fatalf("Referrers() = %v", err)
+	}
+	if got := len(index.Manifests); got != 1 {
+		t.Errorf("got %d attestation referrers, wanted 1", got)
+	}
+	index, err = Referrers(d, ociexperimental.ArtifactType("sig"))
+	if err != nil {
+		t.Fatalf("Referrers() = %v", err)
+	}
+	if got := len(index.Manifests); got != 0 {
+		t.Errorf("got %d signature referrers, wanted 0", got)
+	}
+}
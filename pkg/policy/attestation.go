@@ -17,12 +17,10 @@ package policy
 
 import (
 	"context"
-	"encoding/base64"
 	"encoding/json"
 	"errors"
 	"fmt"
 
-	"github.com/in-toto/in-toto-golang/in_toto"
 	"github.com/sigstore/cosign/v2/pkg/oci"
 
 	"github.com/sigstore/cosign/v2/cmd/cosign/cli/options"
@@ -37,7 +35,7 @@ import (
 // `VerifyLocalImageAttestations` or `VerifyImageAttestations`
 //
 // If there's no error, and payload is empty means the predicateType did not
-// match the attestation.
+// match the attestation. options.PredicateAll matches any predicate type.
 // Returns the attestation type (PredicateType) if the payload was decoded
 // before the error happened, or in the case the predicateType that was
 // requested does not match. This is useful for callers to be able to provide
@@ -49,104 +47,73 @@ func AttestationToPayloadJSON(_ context.Context, predicateType string, verifiedA
 	if predicateType == "" {
 		return nil, "", errors.New("missing predicate type")
 	}
-	predicateURI, ok := options.PredicateTypeMap[predicateType]
-	if !ok {
-		// Not a custom one, use it as is.
-		predicateURI = predicateType
-	}
-	var payloadData map[string]interface{}
-
 	p, err := verifiedAttestation.Payload()
 	if err != nil {
 		return nil, "", fmt.Errorf("getting payload: %w", err)
 	}
-
-	err = json.Unmarshal(p, &payloadData)
+	statement, err := attestation.DecodeEnvelopePayload(p)
 	if err != nil {
-		return nil, "", fmt.Errorf("unmarshaling payload data")
+		return nil, "", err
 	}
+	return StatementToPayloadJSON(predicateType, statement)
+}
 
-	var decodedPayload []byte
-	if val, ok := payloadData["payload"]; ok {
-		decodedPayload, err = base64.StdEncoding.DecodeString(val.(string))
-		if err != nil {
-			return nil, "", fmt.Errorf("decoding payload: %w", err)
-		}
-	} else {
-		return nil, "", fmt.Errorf("could not find payload in payload data")
+// StatementToPayloadJSON is AttestationToPayloadJSON for an in-toto statement
+// already decoded from its DSSE envelope with
+// attestation.DecodeEnvelopePayload, so that callers matching an attestation
+// against several predicate types decode its envelope only once.
+func StatementToPayloadJSON(predicateType string, statement []byte) ([]byte, string, error) {
+	if predicateType == "" {
+		return nil, "", errors.New("missing predicate type")
+	}
+	pt, ok := attestation.LookupPredicateType(predicateType)
+	if !ok {
+		// Not a registered one, use it as is.
+		pt = attestation.PredicateType{URI: predicateType}
 	}
 
-	// Only apply the policy against the requested predicate type
-	var statement in_toto.Statement
-	if err := json.Unmarshal(decodedPayload, &statement); err != nil {
+	// Only apply the policy against the requested predicate type. Just the
+	// predicate type is decoded here, the predicate is decoded once below.
+	var header struct {
+		PredicateType string `json:"predicateType"`
+	}
+	if err := json.Unmarshal(statement, &header); err != nil {
 		return nil, "", fmt.Errorf("unmarshal in-toto statement: %w", err)
 	}
-	if statement.PredicateType != predicateURI {
+	if predicateType != options.PredicateAll && !pt.Matches(header.PredicateType) {
 		// This is not the predicate we're looking for, so skip it.
-		return nil, statement.PredicateType, nil
+		return nil, header.PredicateType, nil
 	}
 
-	// NB: In many (all?) of these cases, we could just return the
-	// 'json.Marshal', but we check for errors here to decorate them
-	// with more meaningful error message.
-	var payload []byte
-	switch predicateType {
-	case options.PredicateCustom:
-		payload, err = json.Marshal(statement)
-		if err != nil {
-			return nil, statement.PredicateType, fmt.Errorf("generating CosignStatement: %w", err)
-		}
-	case options.PredicateLink:
-		var linkStatement in_toto.LinkStatement
-		if err := json.Unmarshal(decodedPayload, &linkStatement); err != nil {
-			return nil, statement.PredicateType, fmt.Errorf("unmarshaling LinkStatement: %w", err)
-		}
-		payload, err = json.Marshal(linkStatement)
-		if err != nil {
-			return nil, statement.PredicateType, fmt.Errorf("marshaling LinkStatement: %w", err)
-		}
-	case options.PredicateSLSA:
-		var slsaProvenanceStatement in_toto.ProvenanceStatementSLSA02
-		if err := json.Unmarshal(decodedPayload, &slsaProvenanceStatement); err != nil {
-			return nil, statement.PredicateType, fmt.Errorf("unmarshaling ProvenanceStatementSLSA02): %w", err)
-		}
-		payload, err = json.Marshal(slsaProvenanceStatement)
-		if err != nil {
-			return nil, statement.PredicateType, fmt.Errorf("marshaling ProvenanceStatementSLSA02: %w", err)
-		}
-	case options.PredicateSPDX, options.PredicateSPDXJSON:
-		var spdxStatement in_toto.SPDXStatement
-		if err := json.Unmarshal(decodedPayload, &spdxStatement); err != nil {
-			return nil, statement.PredicateType, fmt.Errorf("unmarshaling SPDXStatement: %w", err)
-		}
-		payload, err = json.Marshal(spdxStatement)
-		if err != nil {
-			return nil, statement.PredicateType, fmt.Errorf("marshaling SPDXStatement: %w", err)
-		}
-	case options.PredicateCycloneDX:
-		var cyclonedxStatement in_toto.CycloneDXStatement
-		if err := json.Unmarshal(decodedPayload, &cyclonedxStatement); err != nil {
-			return nil, statement.PredicateType, fmt.Errorf("unmarshaling CycloneDXStatement: %w", err)
-		}
-		payload, err = json.Marshal(cyclonedxStatement)
-		if err != nil {
-			return nil, statement.PredicateType, fmt.Errorf("marshaling CycloneDXStatement: %w", err)
-		}
-	case options.PredicateVuln:
-		var vulnStatement attestation.CosignVulnStatement
-		if err := json.Unmarshal(decodedPayload, &vulnStatement); err != nil {
-			return nil, statement.PredicateType, fmt.Errorf("unmarshaling CosignVulnStatement: %w", err)
-		}
-		payload, err = json.Marshal(vulnStatement)
-		if err != nil {
-			return nil, statement.PredicateType, fmt.Errorf("marshaling CosignVulnStatement: %w", err)
-		}
-	default:
-		// Valid URI type reaches here.
-		payload, err = json.Marshal(statement)
-		if err != nil {
-			return nil, statement.PredicateType, fmt.Errorf("generating Statement: %w", err)
-		}
+	// Decoding the statement with its registered type validates the
+	// predicate and shapes it for the policy engines.
+	decoded, err := pt.DecodeStatement(statement)
+	if err != nil {
+		return nil, header.PredicateType, err
+	}
+	payload, err := json.Marshal(decoded)
+	if err != nil {
+		return nil, header.PredicateType, fmt.Errorf("marshaling statement: %w", err)
+	}
+	return payload, header.PredicateType, nil
+}
+
+// VerificationField is the field of the policy input holding the context the
+// attestation was verified in, next to the fields of the in-toto statement.
+const VerificationField = "verification"
+
+// WithVerification adds verification under VerificationField to the policy
+// input payload returned by StatementToPayloadJSON, so that policies can
+// combine constraints on the predicate and on e.g. the signer identity.
+func WithVerification(payload []byte, verification any) ([]byte, error) {
+	var input map[string]json.RawMessage
+	if err := json.Unmarshal(payload, &input); err != nil {
+		return nil, fmt.Errorf("unmarshal policy input: %w", err)
+	}
+	v, err := json.Marshal(verification)
+	if err != nil {
+		return nil, fmt.Errorf("marshaling verification: %w", err)
 	}
-	return payload, statement.PredicateType, nil
+	input[VerificationField] = v
+	return json.Marshal(input)
 }
@@ -18,6 +18,7 @@ package policy
 import (
 	"context"
 	"crypto/x509"
+	"encoding/base64"
 	"encoding/json"
 	"fmt"
 	"io"
@@ -28,6 +29,7 @@ import (
 	v1 "github.com/google/go-containerregistry/pkg/v1"
 	"github.com/google/go-containerregistry/pkg/v1/types"
 	"github.com/in-toto/in-toto-golang/in_toto"
+	"github.com/sigstore/cosign/v2/cmd/cosign/cli/options"
 	"github.com/sigstore/cosign/v2/pkg/cosign/attestation"
 	"github.com/sigstore/cosign/v2/pkg/cosign/bundle"
 	"github.com/sigstore/cosign/v2/pkg/oci"
@@ -103,8 +105,8 @@ func TestFailures(t *testing.T) {
 		payload          string
 		predicateType    string
 		wantErrSubstring string
-	}{{payload: "", wantErrSubstring: "unmarshaling payload data"}, {payload: "{badness", wantErrSubstring: "unmarshaling payload data"},
-		{payload: `{"payloadType":"notmarshallable}`, wantErrSubstring: "unmarshaling payload data"},
+	}{{payload: "", wantErrSubstring: "unmarshaling envelope"}, {payload: "{badness", wantErrSubstring: "unmarshaling envelope"},
+		{payload: `{"payloadType":"notmarshallable}`, wantErrSubstring: "unmarshaling envelope"},
 		{payload: `{"payload":"shou!ln'twork"}`, wantErrSubstring: "decoding payload"},
 		{payload: `{"payloadType":"finebutnopayload"}`, wantErrSubstring: "could not find payload"},
 		{payload: invalidTotoStatement, wantErrSubstring: "decoding payload: illegal base64"},
@@ -153,6 +155,13 @@ func TestAttestationToPayloadJson(t *testing.T) {
 			}
 			checkPredicateType(t, attestation.CosignCustomProvenanceV01, intoto.PredicateType)
 			checkPredicateType(t, gotPredicateType, intoto.PredicateType)
+		case "cyclonedx":
+			var cyclonedxStatement in_toto.CycloneDXStatement
+			if err := json.Unmarshal(jsonBytes, &cyclonedxStatement); err != nil {
+				t.Fatalf("[%s] Wanted CycloneDX statement, can't unmarshal to it: %v", fileName, err)
+			}
+			checkPredicateType(t, in_toto.PredicateCycloneDX, cyclonedxStatement.PredicateType)
+			checkPredicateType(t, gotPredicateType, cyclonedxStatement.PredicateType)
 		case "vuln":
 			var vulnStatement attestation.CosignVulnStatement
 			if err := json.Unmarshal(jsonBytes, &vulnStatement); err != nil {
@@ -166,6 +175,127 @@ func TestAttestationToPayloadJson(t *testing.T) {
 	}
 }
 
+func TestAttestationToPayloadJsonAll(t *testing.T) {
+	dir := "valid"
+	files := getDirFiles(t, dir)
+	for _, fileName := range files {
+		bytes := readAttestationFromTestFile(t, dir, fileName)
+		ociSig, err := static.NewSignature(bytes, "")
+		if err != nil {
+			t.Fatal("Failed to create static.NewSignature: ", err)
+		}
+		jsonBytes, gotPredicateType, err := AttestationToPayloadJSON(context.TODO(), options.PredicateAll, ociSig)
+		if err != nil {
+			t.Fatalf("Failed to convert : %s", err)
+		}
+		if len(jsonBytes) == 0 {
+			t.Fatalf("[%s] expected %s to match every predicate type", fileName, options.PredicateAll)
+		}
+		var intoto in_toto.Statement
+		if err := json.Unmarshal(jsonBytes, &intoto); err != nil {
+			t.Fatalf("[%s] can't unmarshal to statement: %v", fileName, err)
+		}
+		checkPredicateType(t, gotPredicateType, intoto.PredicateType)
+	}
+}
+
+func TestAttestationToPayloadJsonCycloneDX(t *testing.T) {
+	tests := []struct {
+		name          string
+		predicateType string
+		predicate     string
+		wantMatch     bool
+		wantErr       string
+	}{{
+		name:          "unversioned",
+		predicateType: in_toto.PredicateCycloneDX,
+		predicate:     `{"bomFormat":"CycloneDX","specVersion":"1.4","components":[]}`,
+		wantMatch:     true,
+	}, {
+		name:          "versioned",
+		predicateType: "https://cyclonedx.org/bom/v1.4",
+		predicate:     `{"bomFormat":"CycloneDX","specVersion":"1.4","components":[]}`,
+		wantMatch:     true,
+	}, {
+		name:          "other type",
+		predicateType: in_toto.PredicateSPDX,
+		predicate:     `{"spdxVersion":"SPDX-2.2"}`,
+	}, {
+		name:          "not a BOM",
+		predicateType: in_toto.PredicateCycloneDX,
+		predicate:     `{"spdxVersion":"SPDX-2.2"}`,
+		wantErr:       "bomFormat must be",
+	}, {
+		name:          "missing specVersion",
+		predicateType: in_toto.PredicateCycloneDX,
+		predicate:     `{"bomFormat":"CycloneDX"}`,
+		wantErr:       "specVersion missing",
+	}, {
+		name:          "XML BOM",
+		predicateType: in_toto.PredicateCycloneDX,
+		predicate:     `"<bom xmlns=\"http://cyclonedx.org/schema/bom/1.4\"/>"`,
+		wantErr:       "not a JSON BOM",
+	}}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			statement := fmt.Sprintf(`{"_type":%q,"predicateType":%q,"subject":[],"predicate":%s}`,
+				in_toto.StatementInTotoV01, tc.predicateType, tc.predicate)
+			envelope := fmt.Sprintf(`{"payloadType":"application/vnd.in-toto+json","payload":%q}`,
+				base64.StdEncoding.EncodeToString([]byte(statement)))
+			att, err := static.NewSignature([]byte(envelope), "")
+			if err != nil {
+				t.Fatal("Failed to create static.NewSignature: ", err)
+			}
+			payload, gotPredicateType, err := AttestationToPayloadJSON(context.TODO(), options.PredicateCycloneDX, att)
+			if tc.wantErr != "" {
+				checkFailure(t, tc.wantErr, err)
+				return
+			}
+			if err != nil {
+				t.Fatalf("AttestationToPayloadJSON() = %v", err)
+			}
+			checkPredicateType(t, tc.predicateType, gotPredicateType)
+			if gotMatch := len(payload) != 0; gotMatch != tc.wantMatch {
+				t.Errorf("matched = %t, wanted %t", gotMatch, tc.wantMatch)
+			}
+		})
+	}
+}
+
+func TestAttestationToPayloadJsonRegistered(t *testing.T) {
+	const uri = "https://example.com/review/v1"
+	// The decoder only keeps the predicate, which is what policies of this
+	// type are written against.
+	if err := attestation.RegisterPredicateType("review", attestation.PredicateType{
+		URI: uri,
+		Decode: func(statement []byte) (interface{}, error) {
+			var s in_toto.Statement
+			if err := json.Unmarshal(statement, &s); err != nil {
+				return nil, err
+			}
+			return s.Predicate, nil
+		},
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	statement := fmt.Sprintf(`{"_type":%q,"predicateType":%q,"subject":[],"predicate":{"approved":true}}`, in_toto.StatementInTotoV01, uri)
+	envelope := fmt.Sprintf(`{"payloadType":"application/vnd.in-toto+json","payload":%q}`,
+		base64.StdEncoding.EncodeToString([]byte(statement)))
+	att, err := static.NewSignature([]byte(envelope), "")
+	if err != nil {
+		t.Fatal("Failed to create static.NewSignature: ", err)
+	}
+	payload, gotPredicateType, err := AttestationToPayloadJSON(context.TODO(), "review", att)
+	if err != nil {
+		t.Fatalf("AttestationToPayloadJSON() = %v", err)
+	}
+	checkPredicateType(t, uri, gotPredicateType)
+	if string(payload) != `{"approved":true}` {
+		t.Errorf("payload = %s, wanted the decoded predicate", payload)
+	}
+}
+
 func checkPredicateType(t *testing.T, want, got string) {
 	t.Helper()
 	if want != got {
@@ -193,3 +323,63 @@ func getDirFiles(t *testing.T, dir string) []string {
 	}
 	return ret
 }
+
+// largeSPDXEnvelope returns the envelope of an SPDX statement listing n
+// packages, about 200 bytes each.
+func largeSPDXEnvelope(n int) []byte {
+	var b strings.Builder
+	b.WriteString(`{"spdxVersion":"SPDX-2.3","packages":[`)
+	for i := 0; i < n; i++ {
+		if i > 0 {
+			b.WriteByte(',')
+		}
+		fmt.Fprintf(&b, `{"SPDXID":"SPDXRef-Package-%d","name":"package-%d","versionInfo":"1.0.%d","downloadLocation":"NOASSERTION","licenseConcluded":"Apache-2.0"}`, i, i, i)
+	}
+	b.WriteString(`]}`)
+	statement := fmt.Sprintf(`{"_type":%q,"predicateType":%q,"subject":[],"predicate":%s}`,
+		in_toto.StatementInTotoV01, in_toto.PredicateSPDX, b.String())
+	return []byte(fmt.Sprintf(`{"payloadType":"application/vnd.in-toto+json","payload":%q,"signatures":[]}`,
+		base64.StdEncoding.EncodeToString([]byte(statement))))
+}
+
+func BenchmarkAttestationToPayloadJSON(b *testing.B) {
+	for _, bc := range []struct {
+		name          string
+		predicateType string
+	}{
+		{name: "match", predicateType: options.PredicateSPDX},
+		{name: "skip", predicateType: options.PredicateCycloneDX},
+	} {
+		b.Run(bc.name, func(b *testing.B) {
+			envelope := largeSPDXEnvelope(50000)
+			att, err := static.NewSignature(envelope, "")
+			if err != nil {
+				b.Fatal(err)
+			}
+			b.SetBytes(int64(len(envelope)))
+			b.ReportAllocs()
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				if _, _, err := AttestationToPayloadJSON(context.TODO(), bc.predicateType, att); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
+func TestWithVerification(t *testing.T) {
+	payload := []byte(`{"predicateType":"https://slsa.dev/provenance/v0.2","predicate":{"builder":{"id":"https://example.com/builder"}}}`)
+	got, err := WithVerification(payload, map[string]string{"digest": "sha256:abc"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `{"predicate":{"builder":{"id":"https://example.com/builder"}},"predicateType":"https://slsa.dev/provenance/v0.2","verification":{"digest":"sha256:abc"}}`
+	if string(got) != want {
+		t.Errorf("WithVerification() = %s, want %s", got, want)
+	}
+
+	if _, err := WithVerification([]byte(`"not an object"`), nil); err == nil {
+		t.Error("expected an error for a payload that is not an object")
+	}
+}
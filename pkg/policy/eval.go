@@ -20,6 +20,9 @@ import (
 	"fmt"
 
 	"cuelang.org/go/cue/cuecontext"
+	"go.opentelemetry.io/otel/attribute"
+
+	"github.com/sigstore/cosign/v2/internal/tracing"
 	"github.com/sigstore/cosign/v2/pkg/cosign/rego"
 )
 
@@ -31,6 +34,11 @@ import (
 // policyBody - String representing either cue or rego language
 // jsonBytes - Bytes to evaluate against the policyBody in the given language
 func EvaluatePolicyAgainstJSON(ctx context.Context, name, policyType string, policyBody string, jsonBytes []byte) (warnings error, errors error) {
+	ctx, span := tracing.Start(ctx, "policy.Evaluate",
+		attribute.String("cosign.policy.name", name),
+		attribute.String("cosign.policy.type", policyType))
+	defer func() { tracing.End(span, errors) }()
+
 	switch policyType {
 	case "cue":
 		cueValidationErr := evaluateCue(ctx, jsonBytes, policyBody)
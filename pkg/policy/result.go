@@ -0,0 +1,128 @@
+// Copyright 2023 The Sigstore Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package policy
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	cueerrors "cuelang.org/go/cue/errors"
+
+	"github.com/sigstore/cosign/v2/cmd/cosign/cli/options"
+	"github.com/sigstore/cosign/v2/pkg/cosign/cue"
+	"github.com/sigstore/cosign/v2/pkg/cosign/rego"
+)
+
+// PolicyResult is the outcome of evaluating a single policy file against an
+// attestation payload.
+type PolicyResult struct {
+	Policy     string      `json:"policy"`
+	Engine     string      `json:"engine"`
+	Passed     bool        `json:"passed"`
+	Violations []Violation `json:"violations,omitempty"`
+}
+
+// Violation explains why a policy rejected the payload.
+type Violation struct {
+	// Rule is the Rego rule or the CUE field path that failed.
+	Rule string `json:"rule,omitempty"`
+	// Message is the deny message or the failed expression.
+	Message string `json:"message"`
+}
+
+// Err returns an error summarizing the violations, or nil if the policy passed.
+func (r *PolicyResult) Err() error {
+	if r.Passed {
+		return nil
+	}
+	msgs := make([]string, 0, len(r.Violations))
+	for _, v := range r.Violations {
+		msgs = append(msgs, v.String())
+	}
+	return fmt.Errorf("%s policy %s failed: %s", r.Engine, r.Policy, strings.Join(msgs, "; "))
+}
+
+func (v Violation) String() string {
+	if v.Rule == "" {
+		return v.Message
+	}
+	return fmt.Sprintf("%s: %s", v.Rule, v.Message)
+}
+
+// EvaluateCUE validates payload against the CUE policy at path. Every
+// conflicting field is reported as a separate violation.
+func EvaluateCUE(payload []byte, path string) PolicyResult {
+	result := PolicyResult{
+		Policy: path,
+		Engine: options.PolicyEngineCUE,
+		Passed: true,
+	}
+	err := cue.ValidateJSON(payload, []string{path})
+	if err == nil {
+		return result
+	}
+	result.Passed = false
+
+	var cueErr cueerrors.Error
+	if !errors.As(err, &cueErr) {
+		result.Violations = append(result.Violations, Violation{Message: err.Error()})
+		return result
+	}
+	for _, e := range cueerrors.Errors(err) {
+		format, args := e.Msg()
+		result.Violations = append(result.Violations, Violation{
+			Rule:    strings.Join(e.Path(), "."),
+			Message: fmt.Sprintf(format, args...),
+		})
+	}
+	return result
+}
+
+// EvaluateRego validates payload against the Rego policy or OPA bundle at
+// path using query, or rego.QUERY when it is empty. The data documents are
+// loaded alongside the policy. When the payload is denied, the messages of
+// the package's deny rule are reported alongside the failed query.
+func EvaluateRego(payload []byte, path, query string, data []string) PolicyResult {
+	if query == "" {
+		query = rego.QUERY
+	}
+	result := PolicyResult{
+		Policy: path,
+		Engine: options.PolicyEngineRego,
+		Passed: true,
+	}
+	entrypoints := append([]string{path}, data...)
+	errs := rego.ValidateJSONWithQuery(payload, entrypoints, query)
+	if len(errs) == 0 {
+		return result
+	}
+	result.Passed = false
+	for _, err := range errs {
+		result.Violations = append(result.Violations, Violation{Rule: query, Message: err.Error()})
+	}
+
+	msgs, err := rego.DenyMessages(payload, entrypoints, query)
+	if err != nil {
+		// The failed query has already been reported, the deny messages only
+		// add context.
+		return result
+	}
+	denyRule := query[:strings.LastIndex(query, ".")+1] + rego.DenyRule
+	for _, msg := range msgs {
+		result.Violations = append(result.Violations, Violation{Rule: denyRule, Message: msg})
+	}
+	return result
+}
@@ -0,0 +1,114 @@
+// Copyright 2023 The Sigstore Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package policy
+
+import (
+	"os"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+const slsaStatement = `{
+	"_type": "https://in-toto.io/Statement/v0.1",
+	"predicateType": "https://slsa.dev/provenance/v0.2",
+	"predicate": {
+		"builder": {
+			"id": "https://example.com/untrusted-builder"
+		}
+	}
+}`
+
+// writePolicy writes the policy to a relative path, rego has issues loading
+// policy files from an absolute path on Windows.
+func writePolicy(t *testing.T, name, body string) string {
+	t.Helper()
+	if err := os.WriteFile(name, []byte(body), 0644); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.Remove(name) })
+	return name
+}
+
+func TestEvaluateRego(t *testing.T) {
+	tests := []struct {
+		name   string
+		policy string
+		want   []Violation
+	}{{
+		name: "allowed",
+		policy: `package signature
+
+allow {
+	input.predicateType == "https://slsa.dev/provenance/v0.2"
+}`,
+	}, {
+		name: "denied with messages",
+		policy: `package signature
+
+default allow = false
+
+allow {
+	count(deny) == 0
+}
+
+deny[msg] {
+	not startswith(input.predicate.builder.id, "https://github.com/")
+	msg := sprintf("builder %s is not trusted", [input.predicate.builder.id])
+}
+
+deny[{"msg": msg}] {
+	input.predicateType != "https://slsa.dev/provenance/v1"
+	msg := "provenance must be SLSA v1"
+}`,
+		want: []Violation{
+			{Rule: "data.signature.allow", Message: "expression value, false, is not true"},
+			{Rule: "data.signature.deny", Message: "builder https://example.com/untrusted-builder is not trusted"},
+			{Rule: "data.signature.deny", Message: "provenance must be SLSA v1"},
+		},
+	}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := writePolicy(t, "tmp-result-policy.rego", tt.policy)
+			got := EvaluateRego([]byte(slsaStatement), path, "", nil)
+			if got.Passed != (len(tt.want) == 0) {
+				t.Fatalf("EvaluateRego() passed = %v, violations: %v", got.Passed, got.Violations)
+			}
+			if diff := cmp.Diff(tt.want, got.Violations); diff != "" {
+				t.Errorf("unexpected violations (-want +got):\n%s", diff)
+			}
+			if (got.Err() == nil) != got.Passed {
+				t.Errorf("Err() = %v, passed = %v", got.Err(), got.Passed)
+			}
+		})
+	}
+}
+
+func TestEvaluateCUE(t *testing.T) {
+	path := writePolicy(t, "tmp-result-policy.cue", `predicate: builder: id: "https://github.com/actions/runner"`)
+	got := EvaluateCUE([]byte(slsaStatement), path)
+	if got.Passed {
+		t.Fatal("EvaluateCUE() passed, expected a violation")
+	}
+	if len(got.Violations) != 1 {
+		t.Fatalf("expected 1 violation, got %v", got.Violations)
+	}
+	if got.Violations[0].Rule != "predicate.builder.id" {
+		t.Errorf("unexpected rule %q", got.Violations[0].Rule)
+	}
+	if got.Err() == nil {
+		t.Error("Err() = nil, expected an error")
+	}
+}
@@ -0,0 +1,151 @@
+//
+// Copyright 2023 The Sigstore Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package policy
+
+import (
+	"embed"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"cuelang.org/go/cue"
+	"cuelang.org/go/cue/cuecontext"
+	cueerrors "cuelang.org/go/cue/errors"
+	"cuelang.org/go/cue/load"
+	"cuelang.org/go/cue/token"
+	"github.com/in-toto/in-toto-golang/in_toto"
+	slsa02 "github.com/in-toto/in-toto-golang/in_toto/slsa_provenance/v0.2"
+	slsa1 "github.com/in-toto/in-toto-golang/in_toto/slsa_provenance/v1"
+
+	"github.com/sigstore/cosign/v2/cmd/cosign/cli/options"
+)
+
+//go:embed schemas/*.cue
+var schemas embed.FS
+
+// cueSchemas maps the predicate types, by name or URI, to the schema of
+// their predicate. The SPDX URI is left out as it is used for both text and
+// JSON documents.
+var cueSchemas = map[string]string{
+	options.PredicateSLSA:          "slsaprovenance02.cue",
+	options.PredicateSLSA02:        "slsaprovenance02.cue",
+	slsa02.PredicateSLSAProvenance: "slsaprovenance02.cue",
+	options.PredicateSLSA1:         "slsaprovenance1.cue",
+	slsa1.PredicateSLSAProvenance:  "slsaprovenance1.cue",
+	options.PredicateLink:          "link.cue",
+	in_toto.PredicateLinkV1:        "link.cue",
+	options.PredicateSPDXJSON:      "spdxjson.cue",
+}
+
+// CheckCUESchema checks the CUE policy at path against the schema of the
+// statements of predicateType, so that a field the statements don't have,
+// e.g. a typo in a field path, fails before any evaluation instead of the
+// policy never matching. Each error is reported with its position in the
+// policy. Predicate types without a built-in schema are not checked.
+func CheckCUESchema(path, predicateType string) error {
+	schemaFile, ok := cueSchemas[predicateType]
+	if !ok {
+		return nil
+	}
+	statement, err := schemas.ReadFile("schemas/statement.cue")
+	if err != nil {
+		return err
+	}
+	predicate, err := schemas.ReadFile("schemas/" + schemaFile)
+	if err != nil {
+		return err
+	}
+
+	ctx := cuecontext.New()
+	schema := ctx.CompileBytes(append(append(statement, '\n'), predicate...), cue.Filename(schemaFile))
+	if err := schema.Err(); err != nil {
+		return fmt.Errorf("compiling schema %s: %w", schemaFile, err)
+	}
+	def := schema.LookupPath(cue.ParsePath("#Statement"))
+
+	var errs []error
+	for _, bi := range load.Instances([]string{path}, nil) {
+		if bi.Err != nil {
+			return bi.Err
+		}
+		value := ctx.BuildInstance(bi)
+		if err := value.Err(); err != nil {
+			return err
+		}
+		if err := def.Unify(value).Validate(); err != nil {
+			for _, e := range cueerrors.Errors(err) {
+				errs = append(errs, schemaError(path, e))
+			}
+		}
+	}
+	if len(errs) > 0 {
+		return fmt.Errorf("CUE policy %s does not match the %s statements: %w", path, predicateType, errors.Join(errs...))
+	}
+	return nil
+}
+
+// schemaError formats e with the position of the error in the policy at
+// path, rather than in the schema.
+func schemaError(path string, e cueerrors.Error) error {
+	format, args := e.Msg()
+	msg := fmt.Sprintf(format, args...)
+	if len(e.Path()) > 0 {
+		msg = fmt.Sprintf("%s: %s", strings.Join(e.Path(), "."), msg)
+	}
+	if pos, ok := policyPosition(path, e); ok {
+		return fmt.Errorf("%s:%d:%d: %s", path, pos.Line(), pos.Column(), msg)
+	}
+	return errors.New(msg)
+}
+
+// policyPosition returns the position of e in the policy at path: the first
+// one on a line naming the field of e if any, e.g. for a field that is not
+// allowed, else the first one.
+func policyPosition(path string, e cueerrors.Error) (token.Pos, bool) {
+	want, err := filepath.Abs(path)
+	if err != nil {
+		return token.NoPos, false
+	}
+	var lines []string
+	if b, err := os.ReadFile(path); err == nil {
+		lines = strings.Split(string(b), "\n")
+	}
+	field := ""
+	if p := e.Path(); len(p) > 0 {
+		field = p[len(p)-1]
+	}
+
+	var found []token.Pos
+	for _, pos := range append([]token.Pos{e.Position()}, e.InputPositions()...) {
+		if !pos.IsValid() {
+			continue
+		}
+		if got, err := filepath.Abs(pos.Filename()); err == nil && got == want {
+			found = append(found, pos)
+		}
+	}
+	if len(found) == 0 {
+		return token.NoPos, false
+	}
+	for _, pos := range found {
+		if field != "" && pos.Line() <= len(lines) && strings.Contains(lines[pos.Line()-1], field) {
+			return pos, true
+		}
+	}
+	return found[0], true
+}
@@ -0,0 +1,96 @@
+//
+// Copyright 2023 The Sigstore Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package policy
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestCheckCUESchema(t *testing.T) {
+	tests := []struct {
+		name          string
+		predicateType string
+		policy        string
+		wantErr       []string
+	}{{
+		name:          "valid slsa policy",
+		predicateType: "slsaprovenance",
+		policy:        `predicate: builder: id: "https://example.com/builder"`,
+	}, {
+		name:          "valid slsa policy by uri",
+		predicateType: "https://slsa.dev/provenance/v0.2",
+		policy: `predicateType: "https://slsa.dev/provenance/v0.2"
+predicate: invocation: configSource: uri: =~"^git\\+https://github.com/"`,
+	}, {
+		name:          "typo in field path",
+		predicateType: "slsaprovenance",
+		policy: `predicate: builder: id: "https://example.com/builder"
+predicate: buildr: id: "https://example.com/builder"`,
+		wantErr: []string{"schema_policy.cue:2:", "predicate.buildr: field not allowed"},
+	}, {
+		name:          "wrong type",
+		predicateType: "slsaprovenance1",
+		policy:        `predicate: buildDefinition: buildType: 1`,
+		wantErr:       []string{"predicate.buildDefinition.buildType: conflicting values"},
+	}, {
+		name:          "verification context",
+		predicateType: "slsaprovenance",
+		policy: `predicate: builder: id: "https://example.com/builder"
+verification: identity: issuer: "https://token.actions.githubusercontent.com"`,
+	}, {
+		name:          "valid link policy",
+		predicateType: "link",
+		policy:        `predicate: name: "build"`,
+	}, {
+		name:          "valid spdx json policy",
+		predicateType: "spdxjson",
+		policy:        `predicate: spdxVersion: "SPDX-2.3"`,
+	}, {
+		name:          "typo in spdx json policy",
+		predicateType: "spdxjson",
+		policy:        `predicate: spdxVerison: "SPDX-2.3"`,
+		wantErr:       []string{"predicate.spdxVerison: field not allowed"},
+	}, {
+		name:          "spdx text is not checked",
+		predicateType: "spdx",
+		policy:        `predicate: spdxVerison: "SPDX-2.3"`,
+	}, {
+		name:          "unknown predicate type is not checked",
+		predicateType: "https://example.com/custom/v1",
+		policy:        `predicate: anything: "goes"`,
+	}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := writePolicy(t, "schema_policy.cue", tt.policy)
+			err := CheckCUESchema(path, tt.predicateType)
+			if len(tt.wantErr) == 0 {
+				if err != nil {
+					t.Fatalf("CheckCUESchema() = %v", err)
+				}
+				return
+			}
+			if err == nil {
+				t.Fatalf("CheckCUESchema() = nil, want error containing %q", tt.wantErr)
+			}
+			for _, want := range tt.wantErr {
+				if !strings.Contains(err.Error(), want) {
+					t.Errorf("CheckCUESchema() = %v, want error containing %q", err, want)
+				}
+			}
+		})
+	}
+}
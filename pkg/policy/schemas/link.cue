@@ -0,0 +1,11 @@
+// https://in-toto.io/Link/v1
+
+#Predicate: {
+	"_type"?: string
+	name?:    string
+	materials?: [string]: #DigestSet
+	products?: [string]: #DigestSet
+	byproducts?: {...}
+	command?: [...string]
+	environment?: {...}
+}
@@ -0,0 +1,33 @@
+// https://slsa.dev/provenance/v0.2
+
+#Material: {
+	uri?:    string
+	digest?: #DigestSet
+}
+
+#Predicate: {
+	builder?: id?: string
+	buildType?: string
+	invocation?: {
+		configSource?: {
+			uri?:        string
+			digest?:     #DigestSet
+			entryPoint?: string
+		}
+		parameters?:  _
+		environment?: _
+	}
+	buildConfig?: _
+	metadata?: {
+		buildInvocationID?: string
+		buildStartedOn?:    string
+		buildFinishedOn?:   string
+		completeness?: {
+			parameters?:  bool
+			environment?: bool
+			materials?:   bool
+		}
+		reproducible?: bool
+	}
+	materials?: [...#Material]
+}
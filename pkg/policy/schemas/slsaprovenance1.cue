@@ -0,0 +1,33 @@
+// https://slsa.dev/provenance/v1
+
+#ResourceDescriptor: {
+	uri?:              string
+	digest?:           #DigestSet
+	name?:             string
+	downloadLocation?: string
+	mediaType?:        string
+	content?:          string
+	annotations?: {...}
+}
+
+#Predicate: {
+	buildDefinition?: {
+		buildType?:          string
+		externalParameters?: _
+		internalParameters?: _
+		resolvedDependencies?: [...#ResourceDescriptor]
+	}
+	runDetails?: {
+		builder?: {
+			id?: string
+			version?: [string]: string
+			builderDependencies?: [...#ResourceDescriptor]
+		}
+		metadata?: {
+			invocationID?: string
+			startedOn?:    string
+			finishedOn?:   string
+		}
+		byproducts?: [...#ResourceDescriptor]
+	}
+}
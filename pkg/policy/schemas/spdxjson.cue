@@ -0,0 +1,25 @@
+// https://spdx.dev/Document, as a SPDX 2.x JSON document. Only the fields of
+// the document itself are checked, its elements are left open.
+
+#Predicate: {
+	spdxVersion?:       string
+	dataLicense?:       string
+	SPDXID?:            string
+	name?:              string
+	documentNamespace?: string
+	comment?:           string
+	creationInfo?: {
+		created?: string
+		creators?: [...string]
+		licenseListVersion?: string
+		comment?:            string
+	}
+	externalDocumentRefs?: [...{...}]
+	documentDescribes?: [...string]
+	packages?: [...{...}]
+	files?: [...{...}]
+	snippets?: [...{...}]
+	relationships?: [...{...}]
+	hasExtractedLicensingInfos?: [...{...}]
+	annotations?: [...{...}]
+}
@@ -0,0 +1,28 @@
+// The in-toto statement the CUE policies are evaluated against, with the
+// context it was verified in. The predicate schemas define #Predicate.
+
+#DigestSet: [string]: string
+
+#Statement: {
+	"_type"?:       string
+	predicateType?: string
+	subject?: [...{
+		name?:   string
+		digest?: #DigestSet
+	}]
+	predicate?: #Predicate
+	verification?: {
+		image?:  string
+		digest?: string
+		identity?: {
+			subject?: string
+			issuer?:  string
+		}
+		rekor?: {
+			logIndex?:       int
+			logID?:          string
+			integratedTime?: string
+		}
+		annotations?: [string]: string
+	}
+}
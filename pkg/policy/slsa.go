@@ -0,0 +1,182 @@
+//
+// Copyright 2023 The Sigstore Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package policy
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	slsa02 "github.com/in-toto/in-toto-golang/in_toto/slsa_provenance/v0.2"
+	slsa1 "github.com/in-toto/in-toto-golang/in_toto/slsa_provenance/v1"
+
+	"github.com/sigstore/cosign/v2/cmd/cosign/cli/options"
+)
+
+// SLSARequirements are the built-in checks of SLSA provenance, for the
+// common cases that don't need a CUE or Rego policy.
+type SLSARequirements struct {
+	// BuilderID is the builder.id the provenance must name. An ID without
+	// a version also matches the same ID followed by @ and a version.
+	BuilderID string
+	// SourceURI is the repository the build must have run from, matched
+	// like BuilderID with or without the git+ prefix.
+	SourceURI string
+	// MinLevel is the lowest SLSA build level the provenance must show:
+	//   1. the provenance names the builder and the build type
+	//   2. it also pins the source the build ran from to a digest
+	//   3. it also names BuilderID, the builder being trusted to be hardened
+	MinLevel int
+}
+
+// Enabled tells whether any of the checks is requested.
+func (r SLSARequirements) Enabled() bool {
+	return r.BuilderID != "" || r.SourceURI != "" || r.MinLevel > 0
+}
+
+// Validate checks that the requirements can be evaluated.
+func (r SLSARequirements) Validate() error {
+	if r.MinLevel < 0 || r.MinLevel > 3 {
+		return fmt.Errorf("SLSA level must be between 1 and 3, got %d", r.MinLevel)
+	}
+	if r.MinLevel == 3 && r.BuilderID == "" {
+		return fmt.Errorf("SLSA level 3 requires the trusted builder ID to be set")
+	}
+	return nil
+}
+
+// IsSLSAProvenance tells whether predicateType is one of the SLSA provenance
+// versions the checks understand.
+func IsSLSAProvenance(predicateType string) bool {
+	return predicateType == slsa02.PredicateSLSAProvenance || predicateType == slsa1.PredicateSLSAProvenance
+}
+
+// provenanceSource is a source the build ran from.
+type provenanceSource struct {
+	uri    string
+	pinned bool
+}
+
+// provenance holds the fields of a SLSA provenance predicate the checks
+// look at, whatever its version.
+type provenance struct {
+	builderID string
+	buildType string
+	sources   []provenanceSource
+}
+
+func parseProvenance(payload []byte, predicateType string) (provenance, error) {
+	var p provenance
+	switch predicateType {
+	case slsa02.PredicateSLSAProvenance:
+		var statement struct {
+			Predicate slsa02.ProvenancePredicate `json:"predicate"`
+		}
+		if err := json.Unmarshal(payload, &statement); err != nil {
+			return p, fmt.Errorf("unmarshaling SLSA v0.2 provenance: %w", err)
+		}
+		pred := statement.Predicate
+		p.builderID = pred.Builder.ID
+		p.buildType = pred.BuildType
+		if cs := pred.Invocation.ConfigSource; cs.URI != "" {
+			p.sources = append(p.sources, provenanceSource{uri: cs.URI, pinned: len(cs.Digest) > 0})
+		}
+		for _, m := range pred.Materials {
+			p.sources = append(p.sources, provenanceSource{uri: m.URI, pinned: len(m.Digest) > 0})
+		}
+	case slsa1.PredicateSLSAProvenance:
+		var statement struct {
+			Predicate slsa1.ProvenancePredicate `json:"predicate"`
+		}
+		if err := json.Unmarshal(payload, &statement); err != nil {
+			return p, fmt.Errorf("unmarshaling SLSA v1 provenance: %w", err)
+		}
+		pred := statement.Predicate
+		p.builderID = pred.RunDetails.Builder.ID
+		p.buildType = pred.BuildDefinition.BuildType
+		for _, d := range pred.BuildDefinition.ResolvedDependencies {
+			p.sources = append(p.sources, provenanceSource{uri: d.URI, pinned: len(d.Digest) > 0})
+		}
+	default:
+		return p, fmt.Errorf("unsupported SLSA provenance predicate type %s", predicateType)
+	}
+	return p, nil
+}
+
+// level returns the SLSA build level the provenance shows, trustedBuilder
+// telling whether it was made by the builder trusted to be hardened.
+func (p provenance) level(trustedBuilder bool) int {
+	if p.builderID == "" || p.buildType == "" {
+		return 0
+	}
+	pinned := false
+	for _, s := range p.sources {
+		pinned = pinned || s.pinned
+	}
+	switch {
+	case !pinned:
+		return 1
+	case !trustedBuilder:
+		return 2
+	default:
+		return 3
+	}
+}
+
+// matchesVersioned tells whether got is want, or want followed by @ and a
+// version, e.g. a ref or a tag.
+func matchesVersioned(got, want string) bool {
+	return got == want || strings.HasPrefix(got, want+"@")
+}
+
+// EvaluateSLSA checks the SLSA provenance statement payload of predicateType
+// against r, each failed requirement being reported as a violation.
+func EvaluateSLSA(payload []byte, predicateType string, r SLSARequirements) PolicyResult {
+	result := PolicyResult{
+		Policy: "provenance",
+		Engine: options.PolicyEngineSLSA,
+		Passed: true,
+	}
+	fail := func(rule, format string, args ...interface{}) {
+		result.Passed = false
+		result.Violations = append(result.Violations, Violation{Rule: rule, Message: fmt.Sprintf(format, args...)})
+	}
+
+	p, err := parseProvenance(payload, predicateType)
+	if err != nil {
+		fail("", "%v", err)
+		return result
+	}
+
+	trustedBuilder := r.BuilderID != "" && matchesVersioned(p.builderID, r.BuilderID)
+	if r.BuilderID != "" && !trustedBuilder {
+		fail("builderID", "builder %q does not match %q", p.builderID, r.BuilderID)
+	}
+	if r.SourceURI != "" {
+		want := strings.TrimPrefix(r.SourceURI, "git+")
+		found := false
+		for _, s := range p.sources {
+			found = found || matchesVersioned(strings.TrimPrefix(s.uri, "git+"), want)
+		}
+		if !found {
+			fail("sourceURI", "no source of the build matches %q", r.SourceURI)
+		}
+	}
+	if level := p.level(trustedBuilder); level < r.MinLevel {
+		fail("minLevel", "provenance shows SLSA build level %d, %d required", level, r.MinLevel)
+	}
+	return result
+}
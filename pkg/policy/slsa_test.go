@@ -0,0 +1,139 @@
+//
+// Copyright 2023 The Sigstore Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package policy
+
+import (
+	"testing"
+)
+
+const slsa02Provenance = `{
+  "predicateType": "https://slsa.dev/provenance/v0.2",
+  "predicate": {
+    "builder": {"id": "https://github.com/slsa-framework/slsa-github-generator/.github/workflows/generator_generic_slsa3.yml@refs/tags/v1.9.0"},
+    "buildType": "https://github.com/slsa-framework/slsa-github-generator/generic@v1",
+    "invocation": {
+      "configSource": {
+        "uri": "git+https://github.com/sigstore/cosign@refs/heads/main",
+        "digest": {"sha1": "0123456789abcdef0123456789abcdef01234567"}
+      }
+    }
+  }
+}`
+
+const slsa1Provenance = `{
+  "predicateType": "https://slsa.dev/provenance/v1",
+  "predicate": {
+    "buildDefinition": {
+      "buildType": "https://slsa-framework.github.io/github-actions-buildtypes/workflow/v1",
+      "externalParameters": {},
+      "resolvedDependencies": [{"uri": "git+https://github.com/sigstore/cosign@refs/tags/v2.2.0"}]
+    },
+    "runDetails": {"builder": {"id": "https://github.com/actions/runner"}}
+  }
+}`
+
+func TestEvaluateSLSA(t *testing.T) {
+	const slsaGenerator = "https://github.com/slsa-framework/slsa-github-generator/.github/workflows/generator_generic_slsa3.yml"
+	tests := []struct {
+		name          string
+		payload       string
+		predicateType string
+		req           SLSARequirements
+		wantRules     []string
+	}{{
+		name:          "v0.2 level 3 from trusted builder",
+		payload:       slsa02Provenance,
+		predicateType: "https://slsa.dev/provenance/v0.2",
+		req:           SLSARequirements{BuilderID: slsaGenerator, SourceURI: "https://github.com/sigstore/cosign", MinLevel: 3},
+	}, {
+		name:          "v0.2 level 2 without trusted builder",
+		payload:       slsa02Provenance,
+		predicateType: "https://slsa.dev/provenance/v0.2",
+		req:           SLSARequirements{MinLevel: 2},
+	}, {
+		name:          "v0.2 other builder",
+		payload:       slsa02Provenance,
+		predicateType: "https://slsa.dev/provenance/v0.2",
+		req:           SLSARequirements{BuilderID: "https://github.com/actions/runner", MinLevel: 3},
+		wantRules:     []string{"builderID", "minLevel"},
+	}, {
+		name:          "v0.2 other source",
+		payload:       slsa02Provenance,
+		predicateType: "https://slsa.dev/provenance/v0.2",
+		req:           SLSARequirements{SourceURI: "git+https://github.com/sigstore/rekor"},
+		wantRules:     []string{"sourceURI"},
+	}, {
+		name:          "v0.2 source prefix is not a match",
+		payload:       slsa02Provenance,
+		predicateType: "https://slsa.dev/provenance/v0.2",
+		req:           SLSARequirements{SourceURI: "https://github.com/sigstore/cos"},
+		wantRules:     []string{"sourceURI"},
+	}, {
+		name:          "v1 exact builder and source",
+		payload:       slsa1Provenance,
+		predicateType: "https://slsa.dev/provenance/v1",
+		req:           SLSARequirements{BuilderID: "https://github.com/actions/runner", SourceURI: "git+https://github.com/sigstore/cosign@refs/tags/v2.2.0"},
+	}, {
+		name:          "v1 unpinned source is level 1",
+		payload:       slsa1Provenance,
+		predicateType: "https://slsa.dev/provenance/v1",
+		req:           SLSARequirements{MinLevel: 2},
+		wantRules:     []string{"minLevel"},
+	}, {
+		name:          "not a provenance",
+		payload:       `{"predicate": "x"}`,
+		predicateType: "https://slsa.dev/provenance/v1",
+		req:           SLSARequirements{MinLevel: 1},
+		wantRules:     []string{""},
+	}}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			got := EvaluateSLSA([]byte(tc.payload), tc.predicateType, tc.req)
+			if got.Passed != (len(tc.wantRules) == 0) {
+				t.Fatalf("got passed %t with violations %v", got.Passed, got.Violations)
+			}
+			if len(got.Violations) != len(tc.wantRules) {
+				t.Fatalf("got violations %v, wanted rules %v", got.Violations, tc.wantRules)
+			}
+			for i, v := range got.Violations {
+				if v.Rule != tc.wantRules[i] {
+					t.Errorf("got violation %v, wanted rule %q", v, tc.wantRules[i])
+				}
+			}
+		})
+	}
+}
+
+func TestSLSARequirementsValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		req     SLSARequirements
+		wantErr bool
+	}{
+		{name: "none", req: SLSARequirements{}},
+		{name: "level 2", req: SLSARequirements{MinLevel: 2}},
+		{name: "level 3 with builder", req: SLSARequirements{MinLevel: 3, BuilderID: "https://example.com/builder"}},
+		{name: "level 3 without builder", req: SLSARequirements{MinLevel: 3}, wantErr: true},
+		{name: "level 4", req: SLSARequirements{MinLevel: 4, BuilderID: "https://example.com/builder"}, wantErr: true},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if err := tc.req.Validate(); (err != nil) != tc.wantErr {
+				t.Errorf("got error %v, wanted error %t", err, tc.wantErr)
+			}
+		})
+	}
+}
@@ -17,7 +17,9 @@ package spiffe
 
 import (
 	"context"
+	"fmt"
 	"os"
+	"strings"
 
 	"github.com/spiffe/go-spiffe/v2/svid/jwtsvid"
 
@@ -50,21 +52,38 @@ func getSocketPath() string {
 	return defaultSocketPath
 }
 
+// socketAddr returns the Workload API address of socket, which is either a
+// path or already an address such as unix:///run/spire/sockets/agent.sock,
+// the format of SPIFFE_ENDPOINT_SOCKET.
+func socketAddr(socket string) string {
+	if strings.Contains(socket, "://") {
+		return socket
+	}
+	return "unix://" + socket
+}
+
 // Enabled implements providers.Interface
 func (ga *spiffe) Enabled(_ context.Context) bool {
 	// If we can stat the file without error then this is enabled.
-	_, err := os.Stat(getSocketPath())
+	_, err := os.Stat(strings.TrimPrefix(getSocketPath(), "unix://"))
 	return err == nil
 }
 
 // Provide implements providers.Interface
 func (ga *spiffe) Provide(ctx context.Context, audience string) (string, error) {
-	// Creates a new Workload API client, connecting to provided socket path
 	// Environment variable `SPIFFE_ENDPOINT_SOCKET` is used if given and
 	// defaultSocketPath if not.
-	client, err := workloadapi.New(ctx, workloadapi.WithAddr("unix://"+getSocketPath()))
+	return FetchJWTSVID(ctx, getSocketPath(), audience)
+}
+
+// FetchJWTSVID returns a JWT-SVID for audience, fetched from the SPIFFE
+// Workload API listening on socket, a path or an address such as
+// unix:///run/spire/sockets/agent.sock. It is the ID token of the workload,
+// identified by its SPIFFE ID.
+func FetchJWTSVID(ctx context.Context, socket, audience string) (string, error) {
+	client, err := workloadapi.New(ctx, workloadapi.WithAddr(socketAddr(socket)))
 	if err != nil {
-		return "", err
+		return "", fmt.Errorf("connecting to the SPIFFE Workload API at %s: %w", socket, err)
 	}
 	defer client.Close()
 
@@ -72,7 +91,7 @@ func (ga *spiffe) Provide(ctx context.Context, audience string) (string, error)
 		Audience: audience,
 	})
 	if err != nil {
-		return "", err
+		return "", fmt.Errorf("fetching JWT-SVID: %w", err)
 	}
 
 	return svid.Marshal(), nil
@@ -31,3 +31,16 @@ func TestGetSocketPath(t *testing.T) {
 		t.Errorf("Expected %s got %s", nonDefault, got)
 	}
 }
+
+func TestSocketAddr(t *testing.T) {
+	for socket, want := range map[string]string{
+		nonDefault:                     "unix://" + nonDefault,
+		"unix://" + nonDefault:         "unix://" + nonDefault,
+		"tcp://127.0.0.1:8081":         "tcp://127.0.0.1:8081",
+		"/tmp/spire-agent/public/sock": "unix:///tmp/spire-agent/public/sock",
+	} {
+		if got := socketAddr(socket); got != want {
+			t.Errorf("socketAddr(%s) = %s, want %s", socket, got, want)
+		}
+	}
+}
@@ -20,10 +20,12 @@ import (
 	"crypto/x509"
 	"errors"
 	"fmt"
+	"os"
 	"strings"
 
 	"github.com/sigstore/cosign/v2/pkg/blob"
 	"github.com/sigstore/cosign/v2/pkg/cosign"
+	"github.com/sigstore/cosign/v2/pkg/cosign/fidokey"
 	"github.com/sigstore/cosign/v2/pkg/cosign/git"
 	"github.com/sigstore/cosign/v2/pkg/cosign/git/gitlab"
 	"github.com/sigstore/cosign/v2/pkg/cosign/kubernetes"
@@ -44,7 +46,7 @@ func LoadPublicKey(ctx context.Context, keyRef string) (verifier signature.Verif
 func VerifierForKeyRef(ctx context.Context, keyRef string, hashAlgorithm crypto.Hash) (verifier signature.Verifier, err error) {
 	// The key could be plaintext, in a file, at a URL, or in KMS.
 	var perr *kms.ProviderNotFoundError
-	kmsKey, err := kms.Get(ctx, keyRef, hashAlgorithm)
+	kmsKey, err := KMSFromKeyRef(ctx, keyRef, hashAlgorithm)
 	switch {
 	case err == nil:
 		// KMS specified
@@ -58,16 +60,38 @@ func VerifierForKeyRef(ctx context.Context, keyRef string, hashAlgorithm crypto.
 		return nil, err
 	}
 
+	// A directory of trusted keys.
+	if fi, err := os.Stat(keyRef); err == nil && fi.IsDir() {
+		keys, err := loadTrustedKeysDir(ctx, keyRef, hashAlgorithm)
+		if err != nil {
+			return nil, err
+		}
+		return keys, nil
+	}
+
 	raw, err := blob.LoadFileOrURL(keyRef)
 
 	if err != nil {
 		return nil, err
 	}
 
+	// PEM encoded file with several trusted keys, e.g. while rotating keys.
+	keys, err := loadTrustedKeys(raw, hashAlgorithm)
+	if err != nil {
+		return nil, err
+	}
+	if keys != nil {
+		return keys, nil
+	}
+
 	// PEM encoded file.
 	pubKey, err := cryptoutils.UnmarshalPEMToPublicKey(raw)
 	if err != nil {
-		return nil, fmt.Errorf("pem to public key: %w", err)
+		// OpenSSH public key, e.g. ~/.ssh/id_ed25519.pub.
+		var sshErr error
+		if pubKey, sshErr = parseSSHPublicKey(raw); sshErr != nil {
+			return nil, fmt.Errorf("pem to public key: %w", err)
+		}
 	}
 
 	return signature.LoadVerifier(pubKey, hashAlgorithm)
@@ -78,6 +102,9 @@ func loadKey(keyPath string, pf cosign.PassFunc) (signature.SignerVerifier, erro
 	if err != nil {
 		return nil, err
 	}
+	if isSSHPrivateKey(kb) {
+		return loadSSHPrivateKey(kb, pf)
+	}
 	pass := []byte{}
 	if pf != nil {
 		pass, err = pf(false)
@@ -152,10 +179,18 @@ func SignerVerifierFromKeyRef(ctx context.Context, keyRef string, pf cosign.Pass
 		}
 
 		return cosign.LoadPrivateKey([]byte(pk), []byte(pass))
+	case strings.HasPrefix(keyRef, SSHAgentReferenceScheme):
+		return loadSSHAgentSigner(keyRef)
+	case strings.HasPrefix(keyRef, fidokey.ReferenceScheme):
+		sk, err := fidokey.GetKey(fidokey.RPID(keyRef))
+		if err != nil {
+			return nil, fmt.Errorf("opening fido2 security key: %w", err)
+		}
+		return sk, nil
 	}
 
 	if strings.Contains(keyRef, "://") {
-		sv, err := kms.Get(ctx, keyRef, crypto.SHA256)
+		sv, err := KMSFromKeyRef(ctx, keyRef, crypto.SHA256)
 		if err == nil {
 			return sv, nil
 		}
@@ -222,6 +257,14 @@ func PublicKeyFromKeyRefWithHashAlgo(ctx context.Context, keyRef string, hashAlg
 		if len(pubKey) > 0 {
 			return LoadPublicKeyRaw([]byte(pubKey), hashAlgorithm)
 		}
+	} else if strings.HasPrefix(keyRef, SSHAgentReferenceScheme) {
+		return loadSSHAgentSigner(keyRef)
+	} else if strings.HasPrefix(keyRef, fidokey.ReferenceScheme) {
+		sk, err := fidokey.GetKey(fidokey.RPID(keyRef))
+		if err != nil {
+			return nil, fmt.Errorf("opening fido2 security key: %w", err)
+		}
+		return sk, nil
 	}
 
 	return VerifierForKeyRef(ctx, keyRef, hashAlgorithm)
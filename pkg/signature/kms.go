@@ -0,0 +1,205 @@
+//
+// Copyright 2023 The Sigstore Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package signature
+
+import (
+	"context"
+	"crypto"
+	"fmt"
+	"io"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/sigstore/cosign/v2/internal/ui"
+	"github.com/sigstore/cosign/v2/pkg/cosign/env"
+	"github.com/sigstore/sigstore/pkg/signature"
+	"github.com/sigstore/sigstore/pkg/signature/kms"
+	"github.com/sigstore/sigstore/pkg/signature/options"
+)
+
+const hashivaultScheme = "hashivault://"
+
+// kmsKeyRef is a KMS key reference split into the parts handled by cosign
+// and the reference understood by the KMS provider.
+type kmsKeyRef struct {
+	ref       string
+	namespace string
+	version   string
+}
+
+// parseKMSKeyRef splits the cosign specific parts off a KMS key reference:
+// a ?version=N query pinning the key version, and for hashivault:// a Vault
+// enterprise namespace before the key name, e.g. hashivault://ns1/key?version=3.
+func parseKMSKeyRef(keyRef string) (kmsKeyRef, error) {
+	r := kmsKeyRef{ref: keyRef}
+	if i := strings.Index(keyRef, "?"); i >= 0 {
+		query, err := url.ParseQuery(keyRef[i+1:])
+		if err != nil {
+			return kmsKeyRef{}, fmt.Errorf("parsing key reference %s: %w", keyRef, err)
+		}
+		for k := range query {
+			if k != "version" {
+				return kmsKeyRef{}, fmt.Errorf("unsupported parameter %q in key reference %s", k, keyRef)
+			}
+		}
+		r.version = query.Get("version")
+		if v, err := strconv.ParseUint(r.version, 10, 64); err != nil || v == 0 {
+			return kmsKeyRef{}, fmt.Errorf("key version must be a positive integer, got %q", r.version)
+		}
+		r.ref = keyRef[:i]
+	}
+	if strings.HasPrefix(r.ref, hashivaultScheme) {
+		path := strings.TrimPrefix(r.ref, hashivaultScheme)
+		if i := strings.LastIndex(path, "/"); i >= 0 {
+			r.namespace, path = path[:i], path[i+1:]
+			if r.namespace == "" || path == "" {
+				return kmsKeyRef{}, fmt.Errorf("invalid hashivault key reference %s, expected hashivault://[NAMESPACE/]KEY", keyRef)
+			}
+			r.ref = hashivaultScheme + path
+		}
+	}
+	return r, nil
+}
+
+// KMSFromKeyRef returns the KMS SignerVerifier for keyRef. On top of the
+// references of the KMS providers, it accepts a ?version=N query that pins
+// the key version used for signing and verification, so that signatures
+// can still be verified after the key has been rotated, and Vault enterprise
+// namespaces as in hashivault://[NAMESPACE/]KEY. A kms.ProviderNotFoundError
+// is returned if no provider handles keyRef.
+func KMSFromKeyRef(ctx context.Context, keyRef string, hashAlgorithm crypto.Hash) (kms.SignerVerifier, error) {
+	start := time.Now()
+	sv, err := kmsFromKeyRef(ctx, keyRef, hashAlgorithm)
+	if err != nil || !ui.DebugEnabled(ctx) {
+		return sv, err
+	}
+	ui.DebugFields(ctx, ui.Fields{"endpoint": "kms", "duration": since(start)}, "load key %s", keyRef)
+	return &loggedSignerVerifier{SignerVerifier: sv, ctx: ctx, keyRef: keyRef}, nil
+}
+
+func kmsFromKeyRef(ctx context.Context, keyRef string, hashAlgorithm crypto.Hash) (kms.SignerVerifier, error) {
+	if !isKMSKeyRef(keyRef) {
+		// Let the KMS package report that no provider handles keyRef.
+		return kms.Get(ctx, keyRef, hashAlgorithm)
+	}
+	r, err := parseKMSKeyRef(keyRef)
+	if err != nil {
+		return nil, err
+	}
+	opts, err := kmsOptions(r)
+	if err != nil {
+		return nil, err
+	}
+	sv, err := kms.Get(ctx, r.ref, hashAlgorithm, opts...)
+	if err != nil || r.version == "" {
+		return sv, err
+	}
+	return &versionedSignerVerifier{SignerVerifier: sv, version: r.version}, nil
+}
+
+// kmsOptions returns the options passed to the KMS provider for r.
+func kmsOptions(r kmsKeyRef) ([]signature.RPCOption, error) {
+	var opts []signature.RPCOption
+	if r.namespace != "" {
+		ns := env.Getenv(env.VariableVaultNamespace)
+		if ns != "" && ns != r.namespace {
+			return nil, fmt.Errorf("key reference namespace %s conflicts with %s=%s", r.namespace, env.VariableVaultNamespace, ns)
+		}
+		// The Vault client already sends the namespace of the environment.
+		// Otherwise Vault takes it as a prefix of the path of the requests,
+		// leaving the namespace of the other key references untouched.
+		if ns == "" {
+			transit := env.Getenv(env.VariableTransitSecretEnginePath)
+			if transit == "" {
+				transit = "transit"
+			}
+			opts = append(opts, options.WithRPCAuthOpts(options.RPCAuth{Path: r.namespace + "/" + transit}))
+		}
+	}
+	if r.version != "" {
+		opts = append(opts, options.WithKeyVersion(r.version))
+	}
+	return opts, nil
+}
+
+func isKMSKeyRef(keyRef string) bool {
+	for _, p := range kms.SupportedProviders() {
+		if strings.HasPrefix(keyRef, p) {
+			return true
+		}
+	}
+	return false
+}
+
+// versionedSignerVerifier uses a pinned version of a KMS key.
+type versionedSignerVerifier struct {
+	kms.SignerVerifier
+	version string
+}
+
+func (v *versionedSignerVerifier) PublicKey(opts ...signature.PublicKeyOption) (crypto.PublicKey, error) {
+	return v.SignerVerifier.PublicKey(append(opts, options.WithKeyVersion(v.version))...)
+}
+
+func (v *versionedSignerVerifier) SignMessage(message io.Reader, opts ...signature.SignOption) ([]byte, error) {
+	return v.SignerVerifier.SignMessage(message, append(opts, options.WithKeyVersion(v.version))...)
+}
+
+func (v *versionedSignerVerifier) VerifySignature(sig, message io.Reader, opts ...signature.VerifyOption) error {
+	return v.SignerVerifier.VerifySignature(sig, message, append(opts, options.WithKeyVersion(v.version))...)
+}
+
+// loggedSignerVerifier logs the calls to the KMS with their duration.
+type loggedSignerVerifier struct {
+	kms.SignerVerifier
+	ctx    context.Context
+	keyRef string
+}
+
+func (l *loggedSignerVerifier) log(op string, start time.Time, err error) {
+	fields := ui.Fields{"endpoint": "kms", "duration": since(start)}
+	if err != nil {
+		fields["error"] = err.Error()
+	}
+	ui.DebugFields(l.ctx, fields, "%s %s", op, l.keyRef)
+}
+
+func (l *loggedSignerVerifier) PublicKey(opts ...signature.PublicKeyOption) (crypto.PublicKey, error) {
+	start := time.Now()
+	pk, err := l.SignerVerifier.PublicKey(opts...)
+	l.log("get public key", start, err)
+	return pk, err
+}
+
+func (l *loggedSignerVerifier) SignMessage(message io.Reader, opts ...signature.SignOption) ([]byte, error) {
+	start := time.Now()
+	sig, err := l.SignerVerifier.SignMessage(message, opts...)
+	l.log("sign with", start, err)
+	return sig, err
+}
+
+func (l *loggedSignerVerifier) VerifySignature(sig, message io.Reader, opts ...signature.VerifyOption) error {
+	start := time.Now()
+	err := l.SignerVerifier.VerifySignature(sig, message, opts...)
+	l.log("verify with", start, err)
+	return err
+}
+
+func since(start time.Time) string {
+	return time.Since(start).Round(time.Millisecond).String()
+}
@@ -0,0 +1,122 @@
+//
+// Copyright 2023 The Sigstore Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package signature
+
+import (
+	"context"
+	"crypto"
+	"strings"
+	"sync"
+
+	"github.com/aws/aws-sdk-go-v2/aws/arn"
+	"github.com/aws/aws-sdk-go-v2/config"
+	"github.com/sigstore/cosign/v2/internal/ui"
+	"github.com/sigstore/sigstore/pkg/signature"
+	"github.com/sigstore/sigstore/pkg/signature/kms"
+	"github.com/sigstore/sigstore/pkg/signature/kms/aws"
+	"github.com/sigstore/sigstore/pkg/signature/options"
+	"golang.org/x/sync/singleflight"
+)
+
+// The awskms:// provider of sigstore, registered by the initialization of
+// package aws which runs first, is replaced by one loading each key once and
+// using multi-Region keys through their local replica, see loadAWSKMSKey.
+func init() {
+	kms.AddProvider(aws.ReferenceScheme, func(ctx context.Context, ref string, _ crypto.Hash, _ ...signature.RPCOption) (kms.SignerVerifier, error) {
+		return loadAWSKMSKey(ctx, ref)
+	})
+}
+
+// multiRegionKeyPrefix starts the IDs of AWS KMS multi-Region keys, which
+// share their ID and key material with their replicas in other Regions.
+const multiRegionKeyPrefix = "mrk-"
+
+// awsKMSKeys caches the awskms:// keys by reference, so that the public key
+// of a key verifying many signatures, e.g. of a batch of images, is fetched once
+// and reused for the lifetime of the cache of the provider. The hash
+// algorithm and the RPC options are not part of the cache key: the AWS
+// provider ignores them too, the algorithm comes from the key spec, and
+// pinned key versions are passed on each call.
+var awsKMSKeys = struct {
+	sync.Mutex
+	m     map[string]kms.SignerVerifier
+	group singleflight.Group
+}{m: map[string]kms.SignerVerifier{}}
+
+// loadAWSKMSKey returns the awskms:// key of ref, loading it once. Key and
+// alias ARNs are used in the Region of the ARN, except for multi-Region keys
+// which are used through their replica in the local Region when it exists.
+// Concurrent loads of ref share a single request to KMS, while those of other
+// keys proceed in parallel.
+func loadAWSKMSKey(ctx context.Context, ref string) (kms.SignerVerifier, error) {
+	awsKMSKeys.Lock()
+	sv, ok := awsKMSKeys.m[ref]
+	awsKMSKeys.Unlock()
+	if ok {
+		return sv, nil
+	}
+
+	v, err, _ := awsKMSKeys.group.Do(ref, func() (interface{}, error) {
+		endpoint, keyID, _, err := aws.ParseReference(ref)
+		if err != nil {
+			return nil, err
+		}
+		var sv kms.SignerVerifier
+		if a, arnErr := arn.Parse(keyID); arnErr == nil && endpoint == "" {
+			sv, err = loadAWSKMSARN(ctx, ref, a)
+		} else {
+			sv, err = aws.LoadSignerVerifier(ctx, ref)
+		}
+		if err != nil {
+			return nil, err
+		}
+		awsKMSKeys.Lock()
+		awsKMSKeys.m[ref] = sv
+		awsKMSKeys.Unlock()
+		return sv, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return v.(kms.SignerVerifier), nil
+}
+
+func loadAWSKMSARN(ctx context.Context, ref string, a arn.ARN) (kms.SignerVerifier, error) {
+	cfg, err := config.LoadDefaultConfig(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if replica, ok := awsKMSReplicaRef(a, cfg.Region); ok {
+		sv, err := aws.LoadSignerVerifier(ctx, replica)
+		if err == nil {
+			if _, err = sv.PublicKey(options.WithContext(ctx)); err == nil {
+				return sv, nil
+			}
+		}
+		ui.Debugf(ctx, "no replica of %s in %s, using the key of %s: %v", ref, cfg.Region, a.Region, err)
+	}
+	return aws.LoadSignerVerifier(ctx, ref, config.WithRegion(a.Region))
+}
+
+// awsKMSReplicaRef returns the reference of the replica in region of the
+// multi-Region key of a in another Region.
+func awsKMSReplicaRef(a arn.ARN, region string) (string, bool) {
+	if region == "" || region == a.Region || !strings.HasPrefix(a.Resource, "key/"+multiRegionKeyPrefix) {
+		return "", false
+	}
+	a.Region = region
+	return aws.ReferenceScheme + "/" + a.String(), true
+}
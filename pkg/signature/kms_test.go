@@ -0,0 +1,120 @@
+//
+// Copyright 2023 The Sigstore Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package signature
+
+import (
+	"testing"
+
+	"github.com/sigstore/sigstore/pkg/signature/options"
+)
+
+func TestParseKMSKeyRef(t *testing.T) {
+	tests := []struct {
+		keyRef  string
+		want    kmsKeyRef
+		wantErr bool
+	}{{
+		keyRef: "hashivault://keyname",
+		want:   kmsKeyRef{ref: "hashivault://keyname"},
+	}, {
+		keyRef: "hashivault://ns1/keyname?version=3",
+		want:   kmsKeyRef{ref: "hashivault://keyname", namespace: "ns1", version: "3"},
+	}, {
+		keyRef: "hashivault://ns1/ns2/keyname",
+		want:   kmsKeyRef{ref: "hashivault://keyname", namespace: "ns1/ns2"},
+	}, {
+		keyRef: "gcpkms://projects/p/locations/global/keyRings/r/cryptoKeys/k?version=2",
+		want:   kmsKeyRef{ref: "gcpkms://projects/p/locations/global/keyRings/r/cryptoKeys/k", version: "2"},
+	}, {
+		keyRef:  "hashivault://keyname?version=0",
+		wantErr: true,
+	}, {
+		keyRef:  "hashivault://keyname?version=latest",
+		wantErr: true,
+	}, {
+		keyRef:  "hashivault://keyname?region=eu",
+		wantErr: true,
+	}, {
+		keyRef:  "hashivault://ns1/",
+		wantErr: true,
+	}}
+	for _, tt := range tests {
+		t.Run(tt.keyRef, func(t *testing.T) {
+			got, err := parseKMSKeyRef(tt.keyRef)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseKMSKeyRef() error = %v, wantErr %t", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("parseKMSKeyRef() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestKMSOptions(t *testing.T) {
+	tests := []struct {
+		name        string
+		ref         kmsKeyRef
+		env         map[string]string
+		wantPath    string
+		wantVersion string
+		wantErr     bool
+	}{{
+		name: "no namespace",
+		ref:  kmsKeyRef{ref: "hashivault://keyname"},
+	}, {
+		name:        "namespace and version",
+		ref:         kmsKeyRef{ref: "hashivault://keyname", namespace: "ns1/ns2", version: "3"},
+		wantPath:    "ns1/ns2/transit",
+		wantVersion: "3",
+	}, {
+		name:     "namespace and transit path",
+		ref:      kmsKeyRef{ref: "hashivault://keyname", namespace: "ns1"},
+		env:      map[string]string{"TRANSIT_SECRET_ENGINE_PATH": "sign"},
+		wantPath: "ns1/sign",
+	}, {
+		name: "namespace of the environment",
+		ref:  kmsKeyRef{ref: "hashivault://keyname", namespace: "ns1"},
+		env:  map[string]string{"VAULT_NAMESPACE": "ns1"},
+	}, {
+		name:    "conflicting namespace",
+		ref:     kmsKeyRef{ref: "hashivault://keyname", namespace: "ns1"},
+		env:     map[string]string{"VAULT_NAMESPACE": "ns2"},
+		wantErr: true,
+	}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("VAULT_NAMESPACE", "")
+			t.Setenv("TRANSIT_SECRET_ENGINE_PATH", "")
+			for k, v := range tt.env {
+				t.Setenv(k, v)
+			}
+			opts, err := kmsOptions(tt.ref)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("kmsOptions() error = %v, wantErr %t", err, tt.wantErr)
+			}
+			var auth options.RPCAuth
+			var version string
+			for _, o := range opts {
+				o.ApplyRPCAuthOpts(&auth)
+				o.ApplyKeyVersion(&version)
+			}
+			if auth.Path != tt.wantPath || version != tt.wantVersion {
+				t.Errorf("kmsOptions() path = %q, version = %q, want %q, %q", auth.Path, version, tt.wantPath, tt.wantVersion)
+			}
+		})
+	}
+}
@@ -0,0 +1,188 @@
+//
+// Copyright 2023 The Sigstore Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package signature
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/asn1"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"net"
+	"strings"
+
+	"github.com/sigstore/cosign/v2/pkg/cosign"
+	"github.com/sigstore/cosign/v2/pkg/cosign/env"
+	"github.com/sigstore/sigstore/pkg/signature"
+	"golang.org/x/crypto/ssh"
+	"golang.org/x/crypto/ssh/agent"
+)
+
+const (
+	// SSHAgentReferenceScheme selects a key of the running SSH agent, by its
+	// SHA256 fingerprint or comment, e.g. ssh-agent://SHA256:<FINGERPRINT>.
+	SSHAgentReferenceScheme = "ssh-agent://"
+
+	sshPrivateKeyPemType = "OPENSSH PRIVATE KEY"
+)
+
+// isSSHPrivateKey reports whether raw is a private key in the OpenSSH format.
+func isSSHPrivateKey(raw []byte) bool {
+	return bytes.Contains(raw, []byte("-----BEGIN "+sshPrivateKeyPemType+"-----"))
+}
+
+// loadSSHPrivateKey loads an OpenSSH private key, asking pf for the
+// passphrase if the key is encrypted.
+func loadSSHPrivateKey(raw []byte, pf cosign.PassFunc) (signature.SignerVerifier, error) {
+	pk, err := ssh.ParseRawPrivateKey(raw)
+	var missing *ssh.PassphraseMissingError
+	if errors.As(err, &missing) {
+		if pf == nil {
+			return nil, errors.New("ssh private key is encrypted but no passphrase was provided")
+		}
+		pass, perr := pf(false)
+		if perr != nil {
+			return nil, perr
+		}
+		pk, err = ssh.ParseRawPrivateKeyWithPassphrase(raw, pass)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("parsing ssh private key: %w", err)
+	}
+
+	switch pk := pk.(type) {
+	case *rsa.PrivateKey:
+		return signature.LoadRSAPKCS1v15SignerVerifier(pk, crypto.SHA256)
+	case *ecdsa.PrivateKey:
+		return signature.LoadECDSASignerVerifier(pk, crypto.SHA256)
+	case *ed25519.PrivateKey:
+		return signature.LoadED25519SignerVerifier(*pk)
+	default:
+		return nil, fmt.Errorf("unsupported ssh key type %T", pk)
+	}
+}
+
+// parseSSHPublicKey parses a public key in the authorized_keys format, as
+// found in the .pub files of ssh-keygen.
+func parseSSHPublicKey(raw []byte) (crypto.PublicKey, error) {
+	pub, _, _, _, err := ssh.ParseAuthorizedKey(raw)
+	if err != nil {
+		return nil, err
+	}
+	cpk, ok := pub.(ssh.CryptoPublicKey)
+	if !ok {
+		return nil, fmt.Errorf("unsupported ssh key type %s", pub.Type())
+	}
+	return cpk.CryptoPublicKey(), nil
+}
+
+// loadSSHAgentSigner returns a signer for the key of the SSH agent listening
+// on $SSH_AUTH_SOCK selected by keyRef.
+func loadSSHAgentSigner(keyRef string) (signature.SignerVerifier, error) {
+	sock := env.Getenv(env.VariableSSHAuthSock)
+	if sock == "" {
+		return nil, errors.New("SSH_AUTH_SOCK is not set, is the ssh agent running?")
+	}
+	conn, err := net.Dial("unix", sock)
+	if err != nil {
+		return nil, fmt.Errorf("connecting to the ssh agent: %w", err)
+	}
+	return sshAgentSigner(agent.NewClient(conn), strings.TrimPrefix(keyRef, SSHAgentReferenceScheme))
+}
+
+func sshAgentSigner(a agent.ExtendedAgent, selector string) (signature.SignerVerifier, error) {
+	keys, err := a.List()
+	if err != nil {
+		return nil, fmt.Errorf("listing ssh agent keys: %w", err)
+	}
+	var matched []*agent.Key
+	for _, k := range keys {
+		if selector == "" || selector == ssh.FingerprintSHA256(k) || selector == k.Comment {
+			matched = append(matched, k)
+		}
+	}
+	switch {
+	case len(matched) == 0:
+		return nil, fmt.Errorf("no ssh agent key matches %q", selector)
+	case len(matched) > 1:
+		return nil, fmt.Errorf("%d ssh agent keys match %q, select one by its SHA256 fingerprint", len(matched), selector)
+	}
+
+	key := matched[0]
+	pub, err := ssh.ParsePublicKey(key.Marshal())
+	if err != nil {
+		return nil, err
+	}
+	cpk, ok := pub.(ssh.CryptoPublicKey)
+	if !ok {
+		return nil, fmt.Errorf("unsupported ssh key type %s", key.Type())
+	}
+	// The agent picks the digest from the key type, only P-256 matches the
+	// SHA256 used by cosign.
+	if ec, ok := cpk.CryptoPublicKey().(*ecdsa.PublicKey); ok && ec.Curve != elliptic.P256() {
+		return nil, fmt.Errorf("unsupported ssh key type %s, only P-256 ECDSA keys can be used", key.Type())
+	}
+	verifier, err := signature.LoadVerifier(cpk.CryptoPublicKey(), crypto.SHA256)
+	if err != nil {
+		return nil, err
+	}
+	return &sshAgentSignerVerifier{Verifier: verifier, agent: a, key: key}, nil
+}
+
+// sshAgentSignerVerifier signs with a key held by an SSH agent.
+type sshAgentSignerVerifier struct {
+	signature.Verifier
+	agent agent.ExtendedAgent
+	key   *agent.Key
+}
+
+// SignMessage signs message with the agent and converts the signature from
+// the SSH wire format into the one of the sigstore verifiers.
+func (s *sshAgentSignerVerifier) SignMessage(message io.Reader, _ ...signature.SignOption) ([]byte, error) {
+	data, err := io.ReadAll(message)
+	if err != nil {
+		return nil, err
+	}
+	var flags agent.SignatureFlags
+	if s.key.Type() == ssh.KeyAlgoRSA {
+		flags = agent.SignatureFlagRsaSha256
+	}
+	sig, err := s.agent.SignWithFlags(s.key, data, flags)
+	if err != nil {
+		return nil, fmt.Errorf("signing with the ssh agent: %w", err)
+	}
+
+	switch sig.Format {
+	case ssh.KeyAlgoED25519, ssh.KeyAlgoRSASHA256:
+		return sig.Blob, nil
+	case ssh.KeyAlgoECDSA256:
+		var es struct {
+			R, S *big.Int
+		}
+		if err := ssh.Unmarshal(sig.Blob, &es); err != nil {
+			return nil, fmt.Errorf("parsing ecdsa signature: %w", err)
+		}
+		return asn1.Marshal(es)
+	default:
+		return nil, fmt.Errorf("unsupported ssh signature format %s", sig.Format)
+	}
+}
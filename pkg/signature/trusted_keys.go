@@ -0,0 +1,80 @@
+//
+// Copyright 2023 The Sigstore Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package signature
+
+import (
+	"context"
+	"crypto"
+	"encoding/pem"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/sigstore/cosign/v2/pkg/cosign"
+)
+
+// loadTrustedKeys loads a file holding several PEM-encoded public keys. It
+// returns nil if raw holds fewer than two PEM blocks.
+func loadTrustedKeys(raw []byte, hashAlgorithm crypto.Hash) (cosign.TrustedKeys, error) {
+	var blocks [][]byte
+	for rest := raw; ; {
+		var block *pem.Block
+		if block, rest = pem.Decode(rest); block == nil {
+			break
+		}
+		blocks = append(blocks, pem.EncodeToMemory(block))
+	}
+	if len(blocks) < 2 {
+		return nil, nil
+	}
+
+	keys := make(cosign.TrustedKeys, 0, len(blocks))
+	for i, b := range blocks {
+		v, err := LoadPublicKeyRaw(b, hashAlgorithm)
+		if err != nil {
+			return nil, fmt.Errorf("loading public key %d: %w", i+1, err)
+		}
+		keys = append(keys, v)
+	}
+	return keys, nil
+}
+
+// loadTrustedKeysDir loads the public keys of the .pub and .pem files of dir.
+func loadTrustedKeysDir(ctx context.Context, dir string, hashAlgorithm crypto.Hash) (cosign.TrustedKeys, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	var keys cosign.TrustedKeys
+	for _, e := range entries {
+		if e.IsDir() || (filepath.Ext(e.Name()) != ".pub" && filepath.Ext(e.Name()) != ".pem") {
+			continue
+		}
+		v, err := VerifierForKeyRef(ctx, filepath.Join(dir, e.Name()), hashAlgorithm)
+		if err != nil {
+			return nil, fmt.Errorf("loading %s: %w", e.Name(), err)
+		}
+		if tk, ok := v.(cosign.TrustedKeys); ok {
+			keys = append(keys, tk...)
+		} else {
+			keys = append(keys, v)
+		}
+	}
+	if len(keys) == 0 {
+		return nil, fmt.Errorf("no .pub or .pem public key files in %s", dir)
+	}
+	return keys, nil
+}
@@ -0,0 +1,85 @@
+//
+// Copyright 2023 The Sigstore Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package signature
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/sigstore/cosign/v2/pkg/cosign"
+)
+
+func TestTrustedKeys(t *testing.T) {
+	ctx := context.Background()
+	tmpDir := t.TempDir()
+	oldPriv, oldPub := generateKeyFile(t, tmpDir, pass("hello"))
+	newPriv, newPub := generateKeyFile(t, tmpDir, pass("hello"))
+	otherPriv, _ := generateKeyFile(t, t.TempDir(), pass("hello"))
+
+	var bundle []byte
+	for _, f := range []string{oldPub, newPub} {
+		b, err := os.ReadFile(f)
+		if err != nil {
+			t.Fatal(err)
+		}
+		bundle = append(bundle, b...)
+	}
+	bundleFile := filepath.Join(t.TempDir(), "trusted.pub")
+	if err := os.WriteFile(bundleFile, bundle, 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	message := []byte("message")
+	for _, keyRef := range []string{bundleFile, tmpDir} {
+		t.Run(filepath.Base(keyRef), func(t *testing.T) {
+			verifier, err := PublicKeyFromKeyRef(ctx, keyRef)
+			if err != nil {
+				t.Fatalf("PublicKeyFromKeyRef() = %v", err)
+			}
+			keys, ok := verifier.(cosign.TrustedKeys)
+			if !ok || len(keys) != 2 {
+				t.Fatalf("PublicKeyFromKeyRef() = %T, want 2 trusted keys", verifier)
+			}
+			if _, err := keys.PublicKey(); err == nil {
+				t.Error("PublicKey() of 2 trusted keys expected an error")
+			}
+			if _, err := keys[:1].PublicKey(); err != nil {
+				t.Errorf("PublicKey() of a single trusted key = %v", err)
+			}
+			for _, priv := range []string{oldPriv, newPriv, otherPriv} {
+				signer, err := SignerFromKeyRef(ctx, priv, pass("hello"))
+				if err != nil {
+					t.Fatal(err)
+				}
+				sig, err := signer.SignMessage(bytes.NewReader(message))
+				if err != nil {
+					t.Fatal(err)
+				}
+				err = verifier.VerifySignature(bytes.NewReader(sig), bytes.NewReader(message))
+				if trusted := priv != otherPriv; (err == nil) != trusted {
+					t.Errorf("VerifySignature() with %s = %v", filepath.Base(priv), err)
+				}
+			}
+		})
+	}
+
+	if _, err := PublicKeyFromKeyRef(ctx, t.TempDir()); err == nil {
+		t.Error("PublicKeyFromKeyRef() of a directory without keys expected an error")
+	}
+}
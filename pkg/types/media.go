@@ -30,4 +30,19 @@ const (
 	SPDXJSONMediaType      = "text/spdx+json"
 	WasmLayerMediaType     = "application/vnd.wasm.content.layer.v1+wasm"
 	WasmConfigMediaType    = "application/vnd.wasm.config.v1+json"
+
+	RootPolicyLayerMediaType  = "application/vnd.dev.cosign.root-policy.v1+json"
+	RootPolicyConfigMediaType = "application/vnd.dev.cosign.root-policy.config.v1+json"
+
+	RevocationListLayerMediaType  = "application/vnd.dev.cosign.revocation-list.v1+json"
+	RevocationListConfigMediaType = "application/vnd.dev.cosign.revocation-list.config.v1+json"
+
+	// GzipMediaTypeSuffix is appended to the media type of the layers
+	// compressed with gzip, e.g. text/spdx+json+gzip.
+	GzipMediaTypeSuffix = "+gzip"
+)
+
+const (
+	NoCompression   = "none"
+	GzipCompression = "gzip"
 )
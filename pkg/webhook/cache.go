@@ -0,0 +1,92 @@
+//
+// Copyright 2023 The Sigstore Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+	"time"
+
+	"github.com/google/go-containerregistry/pkg/name"
+)
+
+// NewCachingVerifier wraps v to remember for ttl the digests it verified
+// against a policy spec, so that pods and requests using the same images
+// don't fetch and verify their signatures and attestations again. Failed
+// verifications are not cached. Lookups are counted by m, when not nil.
+func NewCachingVerifier(v ImageVerifier, ttl time.Duration, m *Metrics) ImageVerifier {
+	return &cachingVerifier{
+		ImageVerifier: v,
+		ttl:           ttl,
+		metrics:       m,
+		now:           time.Now,
+		entries:       map[string]cachedVerification{},
+	}
+}
+
+type cachingVerifier struct {
+	ImageVerifier
+	ttl     time.Duration
+	metrics *Metrics
+	now     func() time.Time
+
+	mu      sync.Mutex
+	entries map[string]cachedVerification
+}
+
+type cachedVerification struct {
+	warnings []string
+	expires  time.Time
+}
+
+// Verify implements ImageVerifier.
+func (v *cachingVerifier) Verify(ctx context.Context, namespace string, digest name.Digest, spec *ImagePolicySpec) ([]string, error) {
+	// Secrets are read from namespace, so the same spec may verify
+	// differently in another namespace.
+	b, err := json.Marshal(spec)
+	if err != nil {
+		return v.ImageVerifier.Verify(ctx, namespace, digest, spec)
+	}
+	key := namespace + "\x00" + digest.String() + "\x00" + string(b)
+
+	v.mu.Lock()
+	e, ok := v.entries[key]
+	if ok && !v.now().Before(e.expires) {
+		delete(v.entries, key)
+		ok = false
+	}
+	v.mu.Unlock()
+	v.metrics.cacheLookup(ok)
+	if ok {
+		return e.warnings, nil
+	}
+
+	warnings, err := v.ImageVerifier.Verify(ctx, namespace, digest, spec)
+	if err != nil {
+		return nil, err
+	}
+	now := v.now()
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	for k, e := range v.entries {
+		if !now.Before(e.expires) {
+			delete(v.entries, k)
+		}
+	}
+	v.entries[key] = cachedVerification{warnings: warnings, expires: now.Add(v.ttl)}
+	return warnings, nil
+}
@@ -0,0 +1,195 @@
+//
+// Copyright 2023 The Sigstore Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package webhook
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/google/go-containerregistry/pkg/name"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+
+	"github.com/sigstore/cosign/v2/pkg/cosign"
+	"github.com/sigstore/cosign/v2/pkg/policy"
+)
+
+// Reasons of the verification failures counted by Metrics.
+const (
+	ReasonResolveDigest          = "resolve_digest"
+	ReasonNoSignatures           = "no_signatures"
+	ReasonNoMatchingSignatures   = "no_matching_signatures"
+	ReasonNoMatchingAttestations = "no_matching_attestations"
+	ReasonAttestationPolicy      = "attestation_policy"
+	ReasonOther                  = "other"
+)
+
+// Metrics are the Prometheus metrics of the verifications of a server, and
+// of the requests it sends to the registry and Rekor.
+type Metrics struct {
+	registry      *prometheus.Registry
+	verifications *prometheus.CounterVec
+	failures      *prometheus.CounterVec
+	duration      prometheus.Histogram
+	requests      *prometheus.HistogramVec
+	cache         *prometheus.CounterVec
+}
+
+// NewMetrics returns the metrics of a server, along with the Go runtime and
+// process metrics.
+func NewMetrics() *Metrics {
+	m := &Metrics{
+		registry: prometheus.NewRegistry(),
+		verifications: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "cosign_verifications_total",
+			Help: "Verifications of an image against a policy, by result.",
+		}, []string{"result"}),
+		failures: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "cosign_verification_failures_total",
+			Help: "Failed verifications of an image against a policy, by reason.",
+		}, []string{"reason"}),
+		duration: prometheus.NewHistogram(prometheus.HistogramOpts{
+			Name:    "cosign_verification_duration_seconds",
+			Help:    "Duration of the verifications of an image against a policy.",
+			Buckets: prometheus.DefBuckets,
+		}),
+		requests: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Name:    "cosign_http_request_duration_seconds",
+			Help:    "Duration of the HTTP requests sent to the registry and Rekor, by endpoint and status code.",
+			Buckets: prometheus.DefBuckets,
+		}, []string{"endpoint", "code"}),
+		cache: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "cosign_verification_cache_requests_total",
+			Help: "Lookups of the verification cache, by result (hit or miss).",
+		}, []string{"result"}),
+	}
+	m.registry.MustRegister(
+		m.verifications,
+		m.failures,
+		m.duration,
+		m.requests,
+		m.cache,
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+	)
+	return m
+}
+
+// Handler returns the handler of the /metrics endpoint.
+func (m *Metrics) Handler() http.Handler {
+	return promhttp.HandlerFor(m.registry, promhttp.HandlerOpts{})
+}
+
+// Verifier wraps v to count its verifications and their failures by reason,
+// and to measure their duration.
+func (m *Metrics) Verifier(v ImageVerifier) ImageVerifier {
+	return &instrumentedVerifier{ImageVerifier: v, metrics: m}
+}
+
+// Transport wraps rt to measure the duration of the requests sent to
+// endpoint, e.g. registry or rekor.
+func (m *Metrics) Transport(endpoint string, rt http.RoundTripper) http.RoundTripper {
+	return &instrumentedTransport{endpoint: endpoint, inner: rt, metrics: m}
+}
+
+// cacheLookup counts a lookup of the verification cache. m may be nil.
+func (m *Metrics) cacheLookup(hit bool) {
+	if m == nil {
+		return
+	}
+	result := "miss"
+	if hit {
+		result = "hit"
+	}
+	m.cache.WithLabelValues(result).Inc()
+}
+
+func (m *Metrics) failure(reason string) {
+	m.verifications.WithLabelValues("failed").Inc()
+	m.failures.WithLabelValues(reason).Inc()
+}
+
+type instrumentedVerifier struct {
+	ImageVerifier
+	metrics *Metrics
+}
+
+// ResolveDigest implements ImageVerifier.
+func (v *instrumentedVerifier) ResolveDigest(ref name.Reference) (name.Digest, error) {
+	digest, err := v.ImageVerifier.ResolveDigest(ref)
+	if err != nil {
+		v.metrics.failure(ReasonResolveDigest)
+	}
+	return digest, err
+}
+
+// Verify implements ImageVerifier.
+func (v *instrumentedVerifier) Verify(ctx context.Context, namespace string, digest name.Digest, spec *ImagePolicySpec) ([]string, error) {
+	start := time.Now()
+	warnings, err := v.ImageVerifier.Verify(ctx, namespace, digest, spec)
+	v.metrics.duration.Observe(time.Since(start).Seconds())
+	if err != nil {
+		v.metrics.failure(failureReason(err))
+	} else {
+		v.metrics.verifications.WithLabelValues("verified").Inc()
+	}
+	return warnings, err
+}
+
+// failureReason returns the reason of the verification failure err. When
+// the authorities of a policy fail for different reasons, the reason of the
+// verification that went the furthest wins.
+func failureReason(err error) string {
+	var (
+		evaluationFailure      *policy.EvaluationFailure
+		noMatchingAttestations *cosign.ErrNoMatchingAttestations
+		noMatchingSignatures   *cosign.ErrNoMatchingSignatures
+		noSignatures           *cosign.ErrNoSignaturesFound
+	)
+	switch {
+	case errors.As(err, &evaluationFailure):
+		return ReasonAttestationPolicy
+	case errors.As(err, &noMatchingAttestations):
+		return ReasonNoMatchingAttestations
+	case errors.As(err, &noMatchingSignatures):
+		return ReasonNoMatchingSignatures
+	case errors.As(err, &noSignatures):
+		return ReasonNoSignatures
+	default:
+		return ReasonOther
+	}
+}
+
+type instrumentedTransport struct {
+	endpoint string
+	inner    http.RoundTripper
+	metrics  *Metrics
+}
+
+func (t *instrumentedTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	start := time.Now()
+	resp, err := t.inner.RoundTrip(req)
+	code := "error"
+	if err == nil {
+		code = strconv.Itoa(resp.StatusCode)
+	}
+	t.metrics.requests.WithLabelValues(t.endpoint, code).Observe(time.Since(start).Seconds())
+	return resp, err
+}
@@ -0,0 +1,198 @@
+//
+// Copyright 2023 The Sigstore Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package webhook
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-containerregistry/pkg/name"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+
+	"github.com/sigstore/cosign/v2/pkg/policy"
+)
+
+func TestMetricsVerifier(t *testing.T) {
+	m := NewMetrics()
+	v := m.Verifier(&fakeVerifier{signed: map[string]bool{"ghcr.io/example/signed": true}})
+
+	for _, image := range []string{"ghcr.io/example/signed:v1", "ghcr.io/example/signed:v2", "ghcr.io/example/unsigned:v1"} {
+		ref, err := name.ParseReference(image)
+		if err != nil {
+			t.Fatal(err)
+		}
+		digest, err := v.ResolveDigest(ref)
+		if err != nil {
+			t.Fatal(err)
+		}
+		_, _ = v.Verify(context.Background(), "default", digest, &ImagePolicySpec{})
+	}
+
+	if got := testutil.ToFloat64(m.verifications.WithLabelValues("verified")); got != 2 {
+		t.Errorf("verified verifications = %v, want 2", got)
+	}
+	if got := testutil.ToFloat64(m.verifications.WithLabelValues("failed")); got != 1 {
+		t.Errorf("failed verifications = %v, want 1", got)
+	}
+	if got := testutil.ToFloat64(m.failures.WithLabelValues(ReasonOther)); got != 1 {
+		t.Errorf("%s failures = %v, want 1", ReasonOther, got)
+	}
+	if got := testutil.CollectAndCount(m.duration); got != 1 {
+		t.Errorf("duration histograms = %d, want 1", got)
+	}
+
+	rec := httptest.NewRecorder()
+	m.Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	for _, s := range []string{
+		`cosign_verifications_total{result="verified"} 2`,
+		`cosign_verification_failures_total{reason="other"} 1`,
+		"go_goroutines",
+	} {
+		if !strings.Contains(rec.Body.String(), s) {
+			t.Errorf("/metrics does not contain %q", s)
+		}
+	}
+}
+
+func TestFailureReason(t *testing.T) {
+	_, evaluationFailure := policy.EvaluatePolicyAgainstJSON(context.Background(), "test", "cue", "a: 1", []byte(`{"a": 2}`))
+	if evaluationFailure == nil {
+		t.Fatal("EvaluatePolicyAgainstJSON() succeeded")
+	}
+
+	tests := []struct {
+		name string
+		err  error
+		want string
+	}{{
+		name: "attestation policy",
+		err:  errors.Join(errors.New("authority 0: no matching signatures"), fmt.Errorf("authority 1: attestation 0: %w", evaluationFailure)),
+		want: ReasonAttestationPolicy,
+	}, {
+		name: "other",
+		err:  errors.New("loading key: not found"),
+		want: ReasonOther,
+	}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := failureReason(tt.err); got != tt.want {
+				t.Errorf("failureReason() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+// failingResolver fails to resolve every digest.
+type failingResolver struct {
+	fakeVerifier
+}
+
+func (*failingResolver) ResolveDigest(name.Reference) (name.Digest, error) {
+	return name.Digest{}, errors.New("MANIFEST_UNKNOWN")
+}
+
+func TestMetricsResolveDigest(t *testing.T) {
+	m := NewMetrics()
+	v := m.Verifier(&failingResolver{})
+	if _, err := v.ResolveDigest(name.MustParseReference("ghcr.io/example/app:v1")); err == nil {
+		t.Fatal("ResolveDigest() succeeded")
+	}
+	if got := testutil.ToFloat64(m.failures.WithLabelValues(ReasonResolveDigest)); got != 1 {
+		t.Errorf("%s failures = %v, want 1", ReasonResolveDigest, got)
+	}
+}
+
+func TestMetricsTransport(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer server.Close()
+
+	m := NewMetrics()
+	client := &http.Client{Transport: m.Transport("registry", http.DefaultTransport)}
+	resp, err := client.Get(server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, _ = io.Copy(io.Discard, resp.Body)
+	resp.Body.Close()
+
+	if got := testutil.CollectAndCount(m.requests); got != 1 {
+		t.Fatalf("request histograms = %d, want 1", got)
+	}
+	rec := httptest.NewRecorder()
+	m.Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	if want := `cosign_http_request_duration_seconds_count{code="404",endpoint="registry"} 1`; !strings.Contains(rec.Body.String(), want) {
+		t.Errorf("/metrics does not contain %q", want)
+	}
+}
+
+func TestCachingVerifier(t *testing.T) {
+	fake := &fakeVerifier{signed: map[string]bool{"ghcr.io/example/signed": true}}
+	m := NewMetrics()
+	v := NewCachingVerifier(fake, time.Minute, m).(*cachingVerifier)
+	now := time.Now()
+	v.now = func() time.Time { return now }
+
+	spec := &ImagePolicySpec{Images: []ImagePattern{{Glob: "**"}}}
+	signed := name.MustParseReference("ghcr.io/example/signed@sha256:" + digestHex).(name.Digest)
+	unsigned := name.MustParseReference("ghcr.io/example/unsigned@sha256:" + digestHex).(name.Digest)
+	verify := func(namespace string, digest name.Digest) error {
+		_, err := v.Verify(context.Background(), namespace, digest, spec)
+		return err
+	}
+
+	for i := 0; i < 2; i++ {
+		if err := verify("default", signed); err != nil {
+			t.Fatal(err)
+		}
+		if err := verify("default", unsigned); err == nil {
+			t.Fatal("Verify() of an unsigned image succeeded")
+		}
+	}
+	if got := len(fake.verified); got != 3 {
+		t.Errorf("verifications = %d, want 3: the failed ones aren't cached", got)
+	}
+
+	if err := verify("other", signed); err != nil {
+		t.Fatal(err)
+	}
+	if got := len(fake.verified); got != 4 {
+		t.Errorf("verifications = %d, want 4: namespaces are cached apart", got)
+	}
+
+	now = now.Add(time.Minute)
+	if err := verify("default", signed); err != nil {
+		t.Fatal(err)
+	}
+	if got := len(fake.verified); got != 5 {
+		t.Errorf("verifications = %d, want 5: entries expire", got)
+	}
+
+	if got := testutil.ToFloat64(m.cache.WithLabelValues("hit")); got != 1 {
+		t.Errorf("cache hits = %v, want 1", got)
+	}
+	if got := testutil.ToFloat64(m.cache.WithLabelValues("miss")); got != 5 {
+		t.Errorf("cache misses = %v, want 5", got)
+	}
+}
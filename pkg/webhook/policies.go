@@ -0,0 +1,122 @@
+//
+// Copyright 2023 The Sigstore Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package webhook
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/yaml"
+	"k8s.io/client-go/dynamic"
+)
+
+// PolicyLister lists the ImagePolicy resources of a namespace and the
+// ClusterImagePolicy resources.
+type PolicyLister interface {
+	ImagePolicies(ctx context.Context, namespace string) ([]ImagePolicy, error)
+	ClusterImagePolicies(ctx context.Context) ([]ClusterImagePolicy, error)
+}
+
+// NewPolicyLister returns a PolicyLister reading the policies from the
+// cluster of client.
+func NewPolicyLister(client dynamic.Interface) PolicyLister {
+	return &dynamicLister{client: client}
+}
+
+type dynamicLister struct {
+	client dynamic.Interface
+}
+
+// ImagePolicies implements PolicyLister. Policies are read on every call, so
+// that an updated policy applies to the next admitted pod.
+func (l *dynamicLister) ImagePolicies(ctx context.Context, namespace string) ([]ImagePolicy, error) {
+	list, err := l.client.Resource(ImagePolicyResource).Namespace(namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("listing image policies of namespace %s: %w", namespace, err)
+	}
+	policies := make([]ImagePolicy, len(list.Items))
+	for i, item := range list.Items {
+		if err := runtime.DefaultUnstructuredConverter.FromUnstructured(item.Object, &policies[i]); err != nil {
+			return nil, fmt.Errorf("decoding image policy %s/%s: %w", namespace, item.GetName(), err)
+		}
+	}
+	return policies, nil
+}
+
+// ClusterImagePolicies implements PolicyLister.
+func (l *dynamicLister) ClusterImagePolicies(ctx context.Context) ([]ClusterImagePolicy, error) {
+	list, err := l.client.Resource(ClusterImagePolicyResource).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("listing cluster image policies: %w", err)
+	}
+	policies := make([]ClusterImagePolicy, len(list.Items))
+	for i, item := range list.Items {
+		if err := runtime.DefaultUnstructuredConverter.FromUnstructured(item.Object, &policies[i]); err != nil {
+			return nil, fmt.Errorf("decoding cluster image policy %s: %w", item.GetName(), err)
+		}
+	}
+	return policies, nil
+}
+
+// ReadClusterImagePolicies reads the ClusterImagePolicy documents of a YAML
+// or JSON stream, e.g. to check images against the policies of a cluster
+// before they are deployed.
+func ReadClusterImagePolicies(r io.Reader) ([]ClusterImagePolicy, error) {
+	dec := yaml.NewYAMLOrJSONDecoder(r, 4096)
+	var policies []ClusterImagePolicy
+	for {
+		var p ClusterImagePolicy
+		if err := dec.Decode(&p); err != nil {
+			if errors.Is(err, io.EOF) {
+				return policies, nil
+			}
+			return nil, fmt.Errorf("decoding cluster image policy: %w", err)
+		}
+		switch {
+		case p.Kind == "" && p.Name == "":
+			// An empty document.
+			continue
+		case p.Kind != "ClusterImagePolicy":
+			return nil, fmt.Errorf("%s %s is not a ClusterImagePolicy", p.Kind, p.Name)
+		}
+		if err := p.Validate(); err != nil {
+			return nil, fmt.Errorf("cluster image policy %s: %w", p.Name, err)
+		}
+		policies = append(policies, p)
+	}
+}
+
+// NewStaticPolicyLister returns a PolicyLister of the cluster policies, and
+// no ImagePolicy.
+func NewStaticPolicyLister(cluster []ClusterImagePolicy) PolicyLister {
+	return staticLister(cluster)
+}
+
+type staticLister []ClusterImagePolicy
+
+// ImagePolicies implements PolicyLister.
+func (staticLister) ImagePolicies(context.Context, string) ([]ImagePolicy, error) {
+	return nil, nil
+}
+
+// ClusterImagePolicies implements PolicyLister.
+func (l staticLister) ClusterImagePolicies(context.Context) ([]ClusterImagePolicy, error) {
+	return l, nil
+}
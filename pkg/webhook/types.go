@@ -0,0 +1,288 @@
+//
+// Copyright 2023 The Sigstore Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package webhook
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/google/go-containerregistry/pkg/name"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// ImagePolicyResource is the custom resource holding the ImagePolicy of a
+// namespace.
+var ImagePolicyResource = schema.GroupVersionResource{
+	Group:    "cosign.sigstore.dev",
+	Version:  "v1alpha1",
+	Resource: "imagepolicies",
+}
+
+// ClusterImagePolicyResource is the custom resource holding the
+// ClusterImagePolicy of the cluster.
+var ClusterImagePolicyResource = schema.GroupVersionResource{
+	Group:    "cosign.sigstore.dev",
+	Version:  "v1alpha1",
+	Resource: "clusterimagepolicies",
+}
+
+// ImagePolicy requires the images of the pods of its namespace matching
+// Spec.Images to be signed by one of Spec.Authorities, and to have
+// attestations of each of Spec.Attestations signed by the same authority.
+// Every ImagePolicy matching an image must be satisfied.
+type ImagePolicy struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec ImagePolicySpec `json:"spec"`
+}
+
+// ClusterImagePolicy is the ImagePolicy of the pods of every namespace.
+type ClusterImagePolicy struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec ImagePolicySpec `json:"spec"`
+}
+
+// ImagePolicySpec is the specification of an ImagePolicy or a
+// ClusterImagePolicy.
+type ImagePolicySpec struct {
+	Images       []ImagePattern `json:"images"`
+	Authorities  []Authority    `json:"authorities"`
+	Attestations []Attestation  `json:"attestations,omitempty"`
+}
+
+// ImagePattern matches image references. In Glob, * matches any sequence of
+// characters but /, and ** any sequence of characters. Globs are matched
+// against the fully qualified reference, e.g. index.docker.io/library/nginx
+// for nginx, without its tag or digest.
+type ImagePattern struct {
+	Glob string `json:"glob"`
+}
+
+// Authority is trusted to sign images, either with Key or keylessly with
+// one of the identities of Keyless. A Static authority passes or fails
+// every image without verifying it.
+type Authority struct {
+	Name    string           `json:"name,omitempty"`
+	Key     *KeyRef          `json:"key,omitempty"`
+	Keyless *Keyless         `json:"keyless,omitempty"`
+	Static  *StaticAuthority `json:"static,omitempty"`
+}
+
+// KeyRef is a public key given by exactly one of its PEM encoding, a KMS URI,
+// or a Secret of the namespace of the ImagePolicy holding the key under
+// cosign.pub.
+type KeyRef struct {
+	Data      string           `json:"data,omitempty"`
+	KMS       string           `json:"kms,omitempty"`
+	SecretRef *SecretReference `json:"secretRef,omitempty"`
+}
+
+// SecretReference names a Secret. The Secret of an ImagePolicy is in its
+// namespace, while ClusterImagePolicy resources set Namespace.
+type SecretReference struct {
+	Name      string `json:"name"`
+	Namespace string `json:"namespace,omitempty"`
+}
+
+// Keyless trusts the Fulcio certificates of Identities.
+type Keyless struct {
+	Identities []Identity `json:"identities"`
+}
+
+// Identity is the issuer and subject of a Fulcio certificate, each given
+// exactly or as a regular expression.
+type Identity struct {
+	Issuer        string `json:"issuer,omitempty"`
+	IssuerRegExp  string `json:"issuerRegExp,omitempty"`
+	Subject       string `json:"subject,omitempty"`
+	SubjectRegExp string `json:"subjectRegExp,omitempty"`
+}
+
+// StaticAuthority is an authority whose Action, pass or fail, decides
+// images on its own.
+type StaticAuthority struct {
+	Action string `json:"action"`
+}
+
+// Static authority actions.
+const (
+	StaticPass = "pass"
+	StaticFail = "fail"
+)
+
+// Attestation requires an attestation of PredicateType, which is a predicate
+// type name as accepted by --type, e.g. slsaprovenance, or a URI. Each
+// attestation of the type must pass Policy, when set.
+type Attestation struct {
+	Name          string             `json:"name,omitempty"`
+	PredicateType string             `json:"predicateType"`
+	Policy        *AttestationPolicy `json:"policy,omitempty"`
+}
+
+// AttestationPolicy is a CUE or Rego policy the in-toto statement of an
+// attestation is evaluated against. Rego policies define the isCompliant rule
+// of the sigstore package.
+type AttestationPolicy struct {
+	Type string `json:"type"`
+	Data string `json:"data"`
+}
+
+// Validate checks that the spec of the policy is valid, and that its
+// secrets are in its namespace.
+func (p *ImagePolicy) Validate() error {
+	if err := p.Spec.Validate(); err != nil {
+		return err
+	}
+	for i, a := range p.Spec.Authorities {
+		if a.Key != nil && a.Key.SecretRef != nil && a.Key.SecretRef.Namespace != "" && a.Key.SecretRef.Namespace != p.Namespace {
+			return fmt.Errorf("authority %s: key.secretRef must be in namespace %s", a.name(i), p.Namespace)
+		}
+	}
+	return nil
+}
+
+// Validate checks that the spec of the policy is valid, and that its
+// secrets have a namespace.
+func (p *ClusterImagePolicy) Validate() error {
+	if err := p.Spec.Validate(); err != nil {
+		return err
+	}
+	for i, a := range p.Spec.Authorities {
+		if a.Key != nil && a.Key.SecretRef != nil && a.Key.SecretRef.Namespace == "" {
+			return fmt.Errorf("authority %s: key.secretRef.namespace is required", a.name(i))
+		}
+	}
+	return nil
+}
+
+// Validate checks that the spec is complete and unambiguous.
+func (s *ImagePolicySpec) Validate() error {
+	if len(s.Images) == 0 {
+		return errors.New("at least one image glob is required")
+	}
+	for _, p := range s.Images {
+		if _, err := globRegexp(p.Glob); err != nil {
+			return err
+		}
+	}
+	if len(s.Authorities) == 0 {
+		return errors.New("at least one authority is required")
+	}
+	for i, a := range s.Authorities {
+		if err := a.validate(); err != nil {
+			return fmt.Errorf("authority %s: %w", a.name(i), err)
+		}
+	}
+	for i, a := range s.Attestations {
+		if a.PredicateType == "" {
+			return fmt.Errorf("attestation %s: predicateType is required", a.name(i))
+		}
+		if a.Policy != nil && a.Policy.Type != "cue" && a.Policy.Type != "rego" {
+			return fmt.Errorf("attestation %s: invalid policy type %q, expected cue or rego", a.name(i), a.Policy.Type)
+		}
+	}
+	return nil
+}
+
+// Matches reports whether one of the image globs of the spec matches ref.
+func (s *ImagePolicySpec) Matches(ref name.Reference) bool {
+	image := ref.Context().Name()
+	for _, p := range s.Images {
+		re, err := globRegexp(p.Glob)
+		if err == nil && re.MatchString(image) {
+			return true
+		}
+	}
+	return false
+}
+
+func (a *Authority) validate() error {
+	switch {
+	case countSet(a.Key != nil, a.Keyless != nil, a.Static != nil) != 1:
+		return errors.New("exactly one of key, keyless or static is required")
+	case a.Static != nil:
+		if a.Static.Action != StaticPass && a.Static.Action != StaticFail {
+			return fmt.Errorf("invalid static action %q, expected %s or %s", a.Static.Action, StaticPass, StaticFail)
+		}
+	case a.Key != nil:
+		if countSet(a.Key.Data != "", a.Key.KMS != "", a.Key.SecretRef != nil) != 1 {
+			return errors.New("exactly one of key.data, key.kms or key.secretRef is required")
+		}
+	default:
+		if len(a.Keyless.Identities) == 0 {
+			return errors.New("at least one keyless identity is required")
+		}
+		for _, id := range a.Keyless.Identities {
+			if (id.Issuer == "" && id.IssuerRegExp == "") || (id.Subject == "" && id.SubjectRegExp == "") {
+				return errors.New("keyless identities require an issuer or issuerRegExp, and a subject or subjectRegExp")
+			}
+		}
+	}
+	return nil
+}
+
+func countSet(set ...bool) int {
+	n := 0
+	for _, s := range set {
+		if s {
+			n++
+		}
+	}
+	return n
+}
+
+func (a *Authority) name(i int) string {
+	if a.Name != "" {
+		return a.Name
+	}
+	return fmt.Sprint(i)
+}
+
+func (a *Attestation) name(i int) string {
+	if a.Name != "" {
+		return a.Name
+	}
+	return fmt.Sprint(i)
+}
+
+// globRegexp returns the regular expression matching the references glob
+// matches.
+func globRegexp(glob string) (*regexp.Regexp, error) {
+	if glob == "" {
+		return nil, errors.New("empty image glob")
+	}
+	var sb strings.Builder
+	sb.WriteString("^")
+	for i := 0; i < len(glob); i++ {
+		switch {
+		case strings.HasPrefix(glob[i:], "**"):
+			sb.WriteString(".*")
+			i++
+		case glob[i] == '*':
+			sb.WriteString("[^/]*")
+		default:
+			sb.WriteString(regexp.QuoteMeta(glob[i : i+1]))
+		}
+	}
+	sb.WriteString("$")
+	return regexp.Compile(sb.String())
+}
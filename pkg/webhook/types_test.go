@@ -0,0 +1,144 @@
+//
+// Copyright 2023 The Sigstore Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package webhook
+
+import (
+	"testing"
+
+	"github.com/google/go-containerregistry/pkg/name"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestImagePolicySpecMatches(t *testing.T) {
+	tests := []struct {
+		glob  string
+		image string
+		want  bool
+	}{
+		{glob: "index.docker.io/library/*", image: "nginx:1.25", want: true},
+		{glob: "index.docker.io/library/*", image: "example/nginx", want: false},
+		{glob: "ghcr.io/sigstore/**", image: "ghcr.io/sigstore/cosign/cosign@sha256:" + digestHex, want: true},
+		{glob: "ghcr.io/sigstore/*", image: "ghcr.io/sigstore/cosign/cosign", want: false},
+		{glob: "ghcr.io/sigstore/cosign", image: "ghcr.io/sigstore/cosign:latest", want: true},
+		{glob: "ghcr.io/sigstore/cosign", image: "ghcr.io/sigstore/cosignx", want: false},
+		{glob: "**", image: "registry.example.com:5000/a/b/c", want: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.glob+" "+tt.image, func(t *testing.T) {
+			ref, err := name.ParseReference(tt.image)
+			if err != nil {
+				t.Fatal(err)
+			}
+			spec := &ImagePolicySpec{Images: []ImagePattern{{Glob: tt.glob}}}
+			if got := spec.Matches(ref); got != tt.want {
+				t.Errorf("Matches() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestImagePolicySpecValidate(t *testing.T) {
+	images := []ImagePattern{{Glob: "**"}}
+	keyless := &Keyless{Identities: []Identity{{Issuer: "https://accounts.google.com", SubjectRegExp: ".*@example.com"}}}
+	tests := []struct {
+		name    string
+		spec    ImagePolicySpec
+		wantErr bool
+	}{{
+		name: "key",
+		spec: ImagePolicySpec{Images: images, Authorities: []Authority{{Key: &KeyRef{SecretRef: &SecretReference{Name: "cosign"}}}}},
+	}, {
+		name: "keyless with attestation",
+		spec: ImagePolicySpec{
+			Images:       images,
+			Authorities:  []Authority{{Keyless: keyless}},
+			Attestations: []Attestation{{PredicateType: "slsaprovenance", Policy: &AttestationPolicy{Type: "cue", Data: "predicateType: string"}}},
+		},
+	}, {
+		name:    "no images",
+		spec:    ImagePolicySpec{Authorities: []Authority{{Keyless: keyless}}},
+		wantErr: true,
+	}, {
+		name:    "no authorities",
+		spec:    ImagePolicySpec{Images: images},
+		wantErr: true,
+	}, {
+		name:    "key and keyless",
+		spec:    ImagePolicySpec{Images: images, Authorities: []Authority{{Key: &KeyRef{KMS: "gcpkms://k"}, Keyless: keyless}}},
+		wantErr: true,
+	}, {
+		name:    "two key sources",
+		spec:    ImagePolicySpec{Images: images, Authorities: []Authority{{Key: &KeyRef{KMS: "gcpkms://k", Data: "pem"}}}},
+		wantErr: true,
+	}, {
+		name:    "identity without subject",
+		spec:    ImagePolicySpec{Images: images, Authorities: []Authority{{Keyless: &Keyless{Identities: []Identity{{Issuer: "https://accounts.google.com"}}}}}},
+		wantErr: true,
+	}, {
+		name: "static",
+		spec: ImagePolicySpec{Images: images, Authorities: []Authority{{Static: &StaticAuthority{Action: StaticFail}}}},
+	}, {
+		name:    "unknown static action",
+		spec:    ImagePolicySpec{Images: images, Authorities: []Authority{{Static: &StaticAuthority{Action: "skip"}}}},
+		wantErr: true,
+	}, {
+		name:    "static and keyless",
+		spec:    ImagePolicySpec{Images: images, Authorities: []Authority{{Static: &StaticAuthority{Action: StaticPass}, Keyless: keyless}}},
+		wantErr: true,
+	}, {
+		name: "unknown policy type",
+		spec: ImagePolicySpec{
+			Images:       images,
+			Authorities:  []Authority{{Keyless: keyless}},
+			Attestations: []Attestation{{PredicateType: "spdx", Policy: &AttestationPolicy{Type: "json"}}},
+		},
+		wantErr: true,
+	}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.spec.Validate(); (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestPolicySecretNamespaces(t *testing.T) {
+	spec := func(namespace string) ImagePolicySpec {
+		return ImagePolicySpec{
+			Images:      []ImagePattern{{Glob: "**"}},
+			Authorities: []Authority{{Key: &KeyRef{SecretRef: &SecretReference{Name: "cosign", Namespace: namespace}}}},
+		}
+	}
+	tests := []struct {
+		name    string
+		policy  interface{ Validate() error }
+		wantErr bool
+	}{
+		{name: "image policy", policy: &ImagePolicy{Spec: spec("")}},
+		{name: "image policy in its namespace", policy: &ImagePolicy{ObjectMeta: metav1.ObjectMeta{Namespace: "default"}, Spec: spec("default")}},
+		{name: "image policy in other namespace", policy: &ImagePolicy{ObjectMeta: metav1.ObjectMeta{Namespace: "default"}, Spec: spec("kube-system")}, wantErr: true},
+		{name: "cluster image policy", policy: &ClusterImagePolicy{Spec: spec("cosign-system")}},
+		{name: "cluster image policy without namespace", policy: &ClusterImagePolicy{Spec: spec("")}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.policy.Validate(); (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,206 @@
+//
+// Copyright 2023 The Sigstore Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package webhook
+
+import (
+	"context"
+	"crypto"
+	"crypto/x509"
+	"errors"
+	"fmt"
+
+	"github.com/google/go-containerregistry/pkg/name"
+	"github.com/sigstore/rekor/pkg/generated/client"
+	"github.com/sigstore/sigstore/pkg/signature"
+	"go.opentelemetry.io/otel/attribute"
+
+	"github.com/sigstore/cosign/v2/internal/tracing"
+	"github.com/sigstore/cosign/v2/pkg/cosign"
+	"github.com/sigstore/cosign/v2/pkg/cosign/attestation"
+	"github.com/sigstore/cosign/v2/pkg/cosign/kubernetes"
+	"github.com/sigstore/cosign/v2/pkg/oci"
+	ociremote "github.com/sigstore/cosign/v2/pkg/oci/remote"
+	"github.com/sigstore/cosign/v2/pkg/policy"
+	sigs "github.com/sigstore/cosign/v2/pkg/signature"
+)
+
+// ImageVerifier checks that the digest of an image satisfies the spec of a
+// policy, returning the warnings of its attestation policies. Secrets
+// without a namespace are read from namespace.
+type ImageVerifier interface {
+	ResolveDigest(ref name.Reference) (name.Digest, error)
+	Verify(ctx context.Context, namespace string, digest name.Digest, spec *ImagePolicySpec) ([]string, error)
+}
+
+// Verifier is the ImageVerifier checking images with cosign. The
+// transparency log settings and Fulcio roots apply to every authority.
+type Verifier struct {
+	RegistryClientOpts []ociremote.Option
+	RekorClient        *client.Rekor
+	RekorPubKeys       *cosign.TrustedTransparencyLogPubKeys
+	CTLogPubKeys       *cosign.TrustedTransparencyLogPubKeys
+	RootCerts          *x509.CertPool
+	IntermediateCerts  *x509.CertPool
+	IgnoreTlog         bool
+}
+
+var _ ImageVerifier = (*Verifier)(nil)
+
+// Verify implements ImageVerifier. The image satisfies spec when it is
+// signed by one of its authorities, which also signed attestations of each
+// of its attestations passing their policy, or when one of its authorities
+// is a static pass.
+func (v *Verifier) Verify(ctx context.Context, namespace string, digest name.Digest, spec *ImagePolicySpec) (warnings []string, err error) {
+	ctx, span := tracing.Start(ctx, "webhook.Verify",
+		attribute.String("cosign.image", digest.String()),
+		attribute.String("cosign.namespace", namespace))
+	defer func() { tracing.End(span, err) }()
+
+	var errs []error
+	for i, a := range spec.Authorities {
+		if a.Static != nil {
+			if a.Static.Action == StaticPass {
+				return nil, nil
+			}
+			errs = append(errs, fmt.Errorf("authority %s: static authority fails every image", a.name(i)))
+			continue
+		}
+		co, err := v.checkOpts(ctx, namespace, &a)
+		if err == nil {
+			if warnings, err = verifyAuthority(ctx, digest, co, spec.Attestations); err == nil {
+				return warnings, nil
+			}
+		}
+		errs = append(errs, fmt.Errorf("authority %s: %w", a.name(i), err))
+	}
+	return nil, errors.Join(errs...)
+}
+
+// ResolveDigest implements ImageVerifier.
+func (v *Verifier) ResolveDigest(ref name.Reference) (name.Digest, error) {
+	return ociremote.ResolveDigest(ref, v.RegistryClientOpts...)
+}
+
+// checkOpts returns the options verifying the signatures of authority a.
+func (v *Verifier) checkOpts(ctx context.Context, namespace string, a *Authority) (*cosign.CheckOpts, error) {
+	co := &cosign.CheckOpts{
+		RegistryClientOpts: v.RegistryClientOpts,
+		RekorClient:        v.RekorClient,
+		RekorPubKeys:       v.RekorPubKeys,
+		CTLogPubKeys:       v.CTLogPubKeys,
+		IgnoreTlog:         v.IgnoreTlog,
+	}
+	if a.Key != nil {
+		verifier, err := loadKey(ctx, namespace, a.Key)
+		if err != nil {
+			return nil, fmt.Errorf("loading key: %w", err)
+		}
+		co.SigVerifier = verifier
+		return co, nil
+	}
+	co.RootCerts = v.RootCerts
+	co.IntermediateCerts = v.IntermediateCerts
+	for _, id := range a.Keyless.Identities {
+		co.Identities = append(co.Identities, cosign.Identity{
+			Issuer:        id.Issuer,
+			IssuerRegExp:  id.IssuerRegExp,
+			Subject:       id.Subject,
+			SubjectRegExp: id.SubjectRegExp,
+		})
+	}
+	return co, nil
+}
+
+func loadKey(ctx context.Context, namespace string, key *KeyRef) (signature.Verifier, error) {
+	switch {
+	case key.Data != "":
+		return sigs.LoadPublicKeyRaw([]byte(key.Data), crypto.SHA256)
+	case key.KMS != "":
+		return sigs.PublicKeyFromKeyRef(ctx, key.KMS)
+	default:
+		if key.SecretRef.Namespace != "" {
+			namespace = key.SecretRef.Namespace
+		}
+		return sigs.PublicKeyFromKeyRef(ctx, kubernetes.KeyReference+namespace+"/"+key.SecretRef.Name)
+	}
+}
+
+// verifyAuthority verifies the signatures of digest and its attestations
+// with co.
+func verifyAuthority(ctx context.Context, digest name.Digest, co *cosign.CheckOpts, attestations []Attestation) ([]string, error) {
+	co.ClaimVerifier = cosign.SimpleClaimVerifier
+	if _, _, err := cosign.VerifyImageSignatures(ctx, digest, co); err != nil {
+		return nil, err
+	}
+	if len(attestations) == 0 {
+		return nil, nil
+	}
+
+	co.ClaimVerifier = cosign.IntotoSubjectClaimVerifier
+	verified, _, err := cosign.VerifyImageAttestations(ctx, digest, co)
+	if err != nil {
+		return nil, err
+	}
+	var warnings []string
+	for i, a := range attestations {
+		w, err := checkAttestations(ctx, verified, &a, a.name(i))
+		if err != nil {
+			return nil, fmt.Errorf("attestation %s: %w", a.name(i), err)
+		}
+		warnings = append(warnings, w...)
+	}
+	return warnings, nil
+}
+
+// checkAttestations evaluates the policy of a, named name, against the
+// verified attestations of its predicate type, at least one of which is
+// required.
+func checkAttestations(ctx context.Context, verified []oci.Signature, a *Attestation, name string) ([]string, error) {
+	var warnings []string
+	matched := 0
+	for _, att := range verified {
+		envelope, err := att.Payload()
+		if err != nil {
+			return nil, fmt.Errorf("getting payload: %w", err)
+		}
+		statement, err := attestation.DecodeEnvelopePayload(envelope)
+		if err != nil {
+			return nil, err
+		}
+		payload, _, err := policy.StatementToPayloadJSON(a.PredicateType, statement)
+		if err != nil {
+			return nil, err
+		}
+		if len(payload) == 0 {
+			continue
+		}
+		matched++
+		if a.Policy == nil {
+			continue
+		}
+		warn, err := policy.EvaluatePolicyAgainstJSON(ctx, name, a.Policy.Type, a.Policy.Data, payload)
+		if err != nil {
+			return nil, err
+		}
+		if warn != nil {
+			warnings = append(warnings, warn.Error())
+		}
+	}
+	if matched == 0 {
+		return nil, fmt.Errorf("no attestation of predicate type %s", a.PredicateType)
+	}
+	return warnings, nil
+}
@@ -0,0 +1,339 @@
+//
+// Copyright 2023 The Sigstore Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/google/go-containerregistry/pkg/name"
+	admissionv1 "k8s.io/api/admission/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// maxReviewSize bounds the AdmissionReview requests the webhook decodes.
+const maxReviewSize = 3 << 20
+
+var podResource = metav1.GroupVersionResource{Version: "v1", Resource: "pods"}
+
+// Webhook is the validating admission webhook admitting the pods whose
+// images satisfy the ClusterImagePolicy resources and the ImagePolicy
+// resources of their namespace.
+type Webhook struct {
+	Policies PolicyLister
+	Verifier ImageVerifier
+	// DenyUnmatched denies images no policy matches, which are otherwise
+	// admitted.
+	DenyUnmatched bool
+}
+
+var _ http.Handler = (*Webhook)(nil)
+
+// ServeHTTP handles an AdmissionReview request of the validating webhook.
+func (wh *Webhook) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	serve(w, r, wh.Admit)
+}
+
+// MutatingHandler returns the handler of the mutating webhook, which admits
+// pods like wh and rewrites their verified images to the digests they were
+// verified at.
+func (wh *Webhook) MutatingHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		serve(w, r, wh.Mutate)
+	})
+}
+
+// serve responds to the AdmissionReview request of r with review.
+func serve(w http.ResponseWriter, r *http.Request, review func(context.Context, *admissionv1.AdmissionRequest) *admissionv1.AdmissionResponse) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	var ar admissionv1.AdmissionReview
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxReviewSize)).Decode(&ar); err != nil {
+		http.Error(w, fmt.Sprintf("decoding admission review: %v", err), http.StatusBadRequest)
+		return
+	}
+	if ar.Request == nil {
+		http.Error(w, "admission review without request", http.StatusBadRequest)
+		return
+	}
+	resp := review(r.Context(), ar.Request)
+	resp.UID = ar.Request.UID
+	ar.Request = nil
+	ar.Response = resp
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(&ar); err != nil {
+		http.Error(w, fmt.Sprintf("encoding admission review: %v", err), http.StatusInternalServerError)
+	}
+}
+
+// Admit decides whether the pod of req is admitted. Requests other than the
+// creation or update of a pod, or the update of its ephemeral containers, are
+// always admitted.
+func (wh *Webhook) Admit(ctx context.Context, req *admissionv1.AdmissionRequest) *admissionv1.AdmissionResponse {
+	resp, _, _ := wh.admit(ctx, req)
+	return resp
+}
+
+// Mutate decides whether the pod of req is admitted like Admit, patching the
+// images of its containers matched by a policy to the digests they were
+// verified at. Otherwise, the tag of an image could be moved to an unverified
+// digest between admission and the kubelet pulling it.
+func (wh *Webhook) Mutate(ctx context.Context, req *admissionv1.AdmissionRequest) *admissionv1.AdmissionResponse {
+	resp, pod, digests := wh.admit(ctx, req)
+	if !resp.Allowed || pod == nil {
+		return resp
+	}
+	patch, err := digestPatch(pod, digests)
+	if err != nil {
+		return deny(http.StatusInternalServerError, []string{err.Error()})
+	}
+	if patch != nil {
+		patchType := admissionv1.PatchTypeJSONPatch
+		resp.Patch = patch
+		resp.PatchType = &patchType
+	}
+	return resp
+}
+
+// admit decides whether the pod of req is admitted, returning the pod and
+// the digests its tagged images matched by a policy were verified at.
+// Updates of the ephemeralcontainers subresource, e.g. by kubectl debug, are
+// checked like the pod, which holds the new ephemeral containers.
+func (wh *Webhook) admit(ctx context.Context, req *admissionv1.AdmissionRequest) (*admissionv1.AdmissionResponse, *corev1.Pod, map[string]name.Digest) {
+	if req.Resource != podResource || (req.Operation != admissionv1.Create && req.Operation != admissionv1.Update) {
+		return allow(nil), nil, nil
+	}
+	if req.SubResource != "" && (req.SubResource != "ephemeralcontainers" || req.Operation != admissionv1.Update) {
+		return allow(nil), nil, nil
+	}
+	var pod corev1.Pod
+	if err := json.Unmarshal(req.Object.Raw, &pod); err != nil {
+		return deny(http.StatusBadRequest, []string{fmt.Sprintf("decoding pod: %v", err)}), nil, nil
+	}
+
+	policies, err := wh.policies(ctx, req.Namespace)
+	if err != nil {
+		return deny(http.StatusInternalServerError, []string{err.Error()}), nil, nil
+	}
+	var failures, warnings []string
+	digests := map[string]name.Digest{}
+	for _, image := range podImages(&pod) {
+		w, err := wh.verifyImage(ctx, image, policies, digests)
+		if err != nil {
+			failures = append(failures, fmt.Sprintf("image %s: %v", image, err))
+		}
+		warnings = append(warnings, w...)
+	}
+	if len(failures) > 0 {
+		return deny(http.StatusForbidden, failures), nil, nil
+	}
+	return allow(warnings), &pod, digests
+}
+
+// VerifyImage checks image against the policies of namespace matching it,
+// like the images of the pods admitted by wh, returning the names of the
+// matching policies and their warnings.
+func (wh *Webhook) VerifyImage(ctx context.Context, namespace, image string) ([]string, []string, error) {
+	policies, err := wh.policies(ctx, namespace)
+	if err != nil {
+		return nil, nil, err
+	}
+	ref, err := name.ParseReference(image)
+	if err != nil {
+		return nil, nil, err
+	}
+	var matched []string
+	for _, p := range policies {
+		if p.spec.Matches(ref) {
+			matched = append(matched, p.name)
+		}
+	}
+	warnings, err := wh.verifyImage(ctx, image, policies, map[string]name.Digest{})
+	return matched, warnings, err
+}
+
+// policyConfig is an ImagePolicy or ClusterImagePolicy as checked by the
+// webhook.
+type policyConfig struct {
+	// name is the kind and name of the policy, used in messages.
+	name string
+	// namespace is the namespace of the secrets of the policy without one.
+	namespace string
+	spec      *ImagePolicySpec
+	// invalid is the validation error of the policy, reported for the
+	// images it matches.
+	invalid error
+}
+
+// policies returns the ImagePolicy resources of namespace and the
+// ClusterImagePolicy resources.
+func (wh *Webhook) policies(ctx context.Context, namespace string) ([]policyConfig, error) {
+	cluster, err := wh.Policies.ClusterImagePolicies(ctx)
+	if err != nil {
+		return nil, err
+	}
+	namespaced, err := wh.Policies.ImagePolicies(ctx, namespace)
+	if err != nil {
+		return nil, err
+	}
+	configs := make([]policyConfig, 0, len(cluster)+len(namespaced))
+	for i := range cluster {
+		p := &cluster[i]
+		configs = append(configs, policyConfig{
+			name:    "cluster image policy " + p.Name,
+			spec:    &p.Spec,
+			invalid: p.Validate(),
+		})
+	}
+	for i := range namespaced {
+		p := &namespaced[i]
+		configs = append(configs, policyConfig{
+			name:      "image policy " + p.Name,
+			namespace: namespace,
+			spec:      &p.Spec,
+			invalid:   p.Validate(),
+		})
+	}
+	return configs, nil
+}
+
+// verifyImage checks image against every policy matching it, recording the
+// digest a tagged image was verified at in digests.
+func (wh *Webhook) verifyImage(ctx context.Context, image string, policies []policyConfig, digests map[string]name.Digest) ([]string, error) {
+	ref, err := name.ParseReference(image)
+	if err != nil {
+		return nil, err
+	}
+	var matched []policyConfig
+	for _, p := range policies {
+		if !p.spec.Matches(ref) {
+			continue
+		}
+		if p.invalid != nil {
+			return nil, fmt.Errorf("invalid %s: %w", p.name, p.invalid)
+		}
+		matched = append(matched, p)
+	}
+	if len(matched) == 0 {
+		if wh.DenyUnmatched {
+			return nil, errors.New("no image policy matches")
+		}
+		return nil, nil
+	}
+
+	// Every policy verifies the same digest, whatever the tag points to by
+	// the time the last one is checked.
+	digest, err := wh.Verifier.ResolveDigest(ref)
+	if err != nil {
+		return nil, fmt.Errorf("resolving digest: %w", err)
+	}
+	var warnings []string
+	for _, p := range matched {
+		w, err := wh.Verifier.Verify(ctx, p.namespace, digest, p.spec)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", p.name, err)
+		}
+		for _, warning := range w {
+			warnings = append(warnings, fmt.Sprintf("image %s: %s: %s", image, p.name, warning))
+		}
+	}
+	if _, ok := ref.(name.Tag); ok {
+		digests[image] = digest
+	}
+	return warnings, nil
+}
+
+// jsonPatchOp is an operation of a JSON patch, see RFC 6902.
+type jsonPatchOp struct {
+	Op    string `json:"op"`
+	Path  string `json:"path"`
+	Value string `json:"value"`
+}
+
+// digestPatch returns the JSON patch replacing the images of the containers
+// of pod by their digests, or nil when none has a digest.
+func digestPatch(pod *corev1.Pod, digests map[string]name.Digest) ([]byte, error) {
+	var ops []jsonPatchOp
+	add := func(field string, i int, image string) {
+		digest, ok := digests[image]
+		if !ok {
+			return
+		}
+		ops = append(ops, jsonPatchOp{
+			Op:    "replace",
+			Path:  fmt.Sprintf("/spec/%s/%d/image", field, i),
+			Value: digest.String(),
+		})
+	}
+	for i, c := range pod.Spec.InitContainers {
+		add("initContainers", i, c.Image)
+	}
+	for i, c := range pod.Spec.Containers {
+		add("containers", i, c.Image)
+	}
+	for i, c := range pod.Spec.EphemeralContainers {
+		add("ephemeralContainers", i, c.Image)
+	}
+	if len(ops) == 0 {
+		return nil, nil
+	}
+	return json.Marshal(ops)
+}
+
+// podImages returns the distinct images of the containers of pod.
+func podImages(pod *corev1.Pod) []string {
+	var images []string
+	seen := map[string]bool{}
+	add := func(image string) {
+		if !seen[image] {
+			seen[image] = true
+			images = append(images, image)
+		}
+	}
+	for _, c := range pod.Spec.InitContainers {
+		add(c.Image)
+	}
+	for _, c := range pod.Spec.Containers {
+		add(c.Image)
+	}
+	for _, c := range pod.Spec.EphemeralContainers {
+		add(c.Image)
+	}
+	return images
+}
+
+func allow(warnings []string) *admissionv1.AdmissionResponse {
+	return &admissionv1.AdmissionResponse{Allowed: true, Warnings: warnings}
+}
+
+func deny(code int32, messages []string) *admissionv1.AdmissionResponse {
+	return &admissionv1.AdmissionResponse{
+		Result: &metav1.Status{
+			Status:  metav1.StatusFailure,
+			Code:    code,
+			Reason:  metav1.StatusReason(http.StatusText(int(code))),
+			Message: strings.Join(messages, "; "),
+		},
+	}
+}
@@ -0,0 +1,340 @@
+//
+// Copyright 2023 The Sigstore Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/google/go-containerregistry/pkg/name"
+	admissionv1 "k8s.io/api/admission/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+const digestHex = "0000000000000000000000000000000000000000000000000000000000000000"
+
+type fakeLister struct {
+	policies []ImagePolicy
+	cluster  []ClusterImagePolicy
+}
+
+func (l *fakeLister) ImagePolicies(context.Context, string) ([]ImagePolicy, error) {
+	return l.policies, nil
+}
+
+func (l *fakeLister) ClusterImagePolicies(context.Context) ([]ClusterImagePolicy, error) {
+	return l.cluster, nil
+}
+
+// fakeVerifier resolves every image to the digest sha256:digestHex, and
+// accepts the repositories in signed.
+type fakeVerifier struct {
+	signed     map[string]bool
+	verified   []string
+	namespaces []string
+}
+
+func (v *fakeVerifier) ResolveDigest(ref name.Reference) (name.Digest, error) {
+	if d, ok := ref.(name.Digest); ok {
+		return d, nil
+	}
+	return ref.Context().Digest("sha256:" + digestHex), nil
+}
+
+func (v *fakeVerifier) Verify(_ context.Context, namespace string, digest name.Digest, _ *ImagePolicySpec) ([]string, error) {
+	v.verified = append(v.verified, digest.String())
+	v.namespaces = append(v.namespaces, namespace)
+	if !v.signed[digest.Context().Name()] {
+		return nil, errors.New("no matching signatures")
+	}
+	return []string{"warned"}, nil
+}
+
+func imagePolicy(name, glob string) ImagePolicy {
+	p := ImagePolicy{Spec: ImagePolicySpec{
+		Images:      []ImagePattern{{Glob: glob}},
+		Authorities: []Authority{{Key: &KeyRef{SecretRef: &SecretReference{Name: "cosign"}}}},
+	}}
+	p.Name = name
+	p.Namespace = "default"
+	return p
+}
+
+func clusterImagePolicy(name, glob string) ClusterImagePolicy {
+	p := ClusterImagePolicy{Spec: ImagePolicySpec{
+		Images:      []ImagePattern{{Glob: glob}},
+		Authorities: []Authority{{Key: &KeyRef{SecretRef: &SecretReference{Name: "cosign", Namespace: "cosign-system"}}}},
+	}}
+	p.Name = name
+	return p
+}
+
+func podRequest(t *testing.T, images ...string) *admissionv1.AdmissionRequest {
+	t.Helper()
+	pod := corev1.Pod{}
+	for _, image := range images {
+		pod.Spec.Containers = append(pod.Spec.Containers, corev1.Container{Image: image})
+	}
+	raw, err := json.Marshal(pod)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &admissionv1.AdmissionRequest{
+		UID:       types.UID("uid"),
+		Resource:  podResource,
+		Operation: admissionv1.Create,
+		Namespace: "default",
+		Object:    runtime.RawExtension{Raw: raw},
+	}
+}
+
+func TestAdmit(t *testing.T) {
+	signed := "ghcr.io/example/signed:v1"
+	unsigned := "ghcr.io/example/unsigned:v1"
+	other := "quay.io/example/other:v1"
+	tests := []struct {
+		name          string
+		policies      *fakeLister
+		denyUnmatched bool
+		images        []string
+		want          bool
+		wantWarnings  int
+	}{{
+		name:         "signed",
+		policies:     &fakeLister{policies: []ImagePolicy{imagePolicy("example", "ghcr.io/example/*")}},
+		images:       []string{signed, signed},
+		want:         true,
+		wantWarnings: 1,
+	}, {
+		name:     "unsigned",
+		policies: &fakeLister{policies: []ImagePolicy{imagePolicy("example", "ghcr.io/example/*")}},
+		images:   []string{signed, unsigned},
+	}, {
+		name:     "unmatched allowed",
+		policies: &fakeLister{policies: []ImagePolicy{imagePolicy("example", "ghcr.io/example/*")}},
+		images:   []string{other},
+		want:     true,
+	}, {
+		name:          "unmatched denied",
+		policies:      &fakeLister{policies: []ImagePolicy{imagePolicy("example", "ghcr.io/example/*")}},
+		denyUnmatched: true,
+		images:        []string{other},
+	}, {
+		name:         "every matching policy",
+		policies:     &fakeLister{policies: []ImagePolicy{imagePolicy("example", "ghcr.io/example/*"), imagePolicy("all", "**")}},
+		images:       []string{signed},
+		want:         true,
+		wantWarnings: 2,
+	}, {
+		name:         "cluster and namespace policies",
+		policies:     &fakeLister{policies: []ImagePolicy{imagePolicy("example", "ghcr.io/example/*")}, cluster: []ClusterImagePolicy{clusterImagePolicy("all", "**")}},
+		images:       []string{signed},
+		want:         true,
+		wantWarnings: 2,
+	}, {
+		name:     "cluster policy",
+		policies: &fakeLister{cluster: []ClusterImagePolicy{clusterImagePolicy("all", "**")}},
+		images:   []string{signed, unsigned},
+	}, {
+		name:     "invalid policy",
+		policies: &fakeLister{policies: []ImagePolicy{{Spec: ImagePolicySpec{Images: []ImagePattern{{Glob: "**"}}}}}},
+		images:   []string{signed},
+	}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			wh := &Webhook{
+				Policies:      tt.policies,
+				Verifier:      &fakeVerifier{signed: map[string]bool{"ghcr.io/example/signed": true}},
+				DenyUnmatched: tt.denyUnmatched,
+			}
+			resp := wh.Admit(context.Background(), podRequest(t, tt.images...))
+			if resp.Allowed != tt.want {
+				t.Fatalf("Admit() allowed = %v, want %v: %v", resp.Allowed, tt.want, resp.Result)
+			}
+			if !tt.want && resp.Result.Code != http.StatusForbidden {
+				t.Errorf("Admit() code = %d, want %d", resp.Result.Code, http.StatusForbidden)
+			}
+			if len(resp.Warnings) != tt.wantWarnings {
+				t.Errorf("Admit() warnings = %v, want %d", resp.Warnings, tt.wantWarnings)
+			}
+		})
+	}
+}
+
+func TestAdmitIgnoresOtherRequests(t *testing.T) {
+	v := &fakeVerifier{}
+	wh := &Webhook{Policies: &fakeLister{policies: []ImagePolicy{imagePolicy("all", "**")}}, Verifier: v}
+
+	del := podRequest(t, "ghcr.io/example/unsigned:v1")
+	del.Operation = admissionv1.Delete
+	deployment := podRequest(t, "ghcr.io/example/unsigned:v1")
+	deployment.Resource = metav1.GroupVersionResource{Group: "apps", Version: "v1", Resource: "deployments"}
+	status := podRequest(t, "ghcr.io/example/unsigned:v1")
+	status.SubResource = "status"
+	status.Operation = admissionv1.Update
+
+	for _, req := range []*admissionv1.AdmissionRequest{del, deployment, status} {
+		if resp := wh.Admit(context.Background(), req); !resp.Allowed {
+			t.Errorf("Admit(%s %s) denied: %v", req.Operation, req.Resource.Resource, resp.Result)
+		}
+	}
+	if len(v.verified) != 0 {
+		t.Errorf("verified %v, want none", v.verified)
+	}
+}
+
+// ephemeralContainersRequest returns the request of kubectl debug adding an
+// ephemeral container of image to a pod of the container running.
+func ephemeralContainersRequest(t *testing.T, running, image string) *admissionv1.AdmissionRequest {
+	t.Helper()
+	pod := corev1.Pod{}
+	pod.Spec.Containers = []corev1.Container{{Image: running}}
+	pod.Spec.EphemeralContainers = []corev1.EphemeralContainer{{
+		EphemeralContainerCommon: corev1.EphemeralContainerCommon{Name: "debugger", Image: image},
+	}}
+	raw, err := json.Marshal(pod)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &admissionv1.AdmissionRequest{
+		UID:         types.UID("uid"),
+		Resource:    podResource,
+		SubResource: "ephemeralcontainers",
+		Operation:   admissionv1.Update,
+		Namespace:   "default",
+		Object:      runtime.RawExtension{Raw: raw},
+	}
+}
+
+func TestAdmitEphemeralContainers(t *testing.T) {
+	wh := &Webhook{
+		Policies: &fakeLister{policies: []ImagePolicy{imagePolicy("example", "ghcr.io/example/*")}},
+		Verifier: &fakeVerifier{signed: map[string]bool{"ghcr.io/example/signed": true}},
+	}
+	signed := "ghcr.io/example/signed:v1"
+
+	if resp := wh.Admit(context.Background(), ephemeralContainersRequest(t, signed, "ghcr.io/example/unsigned:v1")); resp.Allowed {
+		t.Error("Admit() allowed an unsigned ephemeral container")
+	}
+	if resp := wh.Admit(context.Background(), ephemeralContainersRequest(t, signed, signed)); !resp.Allowed {
+		t.Errorf("Admit() denied a signed ephemeral container: %v", resp.Result)
+	}
+
+	resp := wh.Mutate(context.Background(), ephemeralContainersRequest(t, "quay.io/example/other:v1", signed))
+	want := `[{"op":"replace","path":"/spec/ephemeralContainers/0/image","value":"ghcr.io/example/signed@sha256:` + digestHex + `"}]`
+	if !resp.Allowed || string(resp.Patch) != want {
+		t.Errorf("Mutate() = %+v, want patch %s", resp, want)
+	}
+}
+
+func TestAdmitSecretNamespaces(t *testing.T) {
+	v := &fakeVerifier{signed: map[string]bool{"ghcr.io/example/signed": true}}
+	wh := &Webhook{
+		Policies: &fakeLister{
+			policies: []ImagePolicy{imagePolicy("all", "**")},
+			cluster:  []ClusterImagePolicy{clusterImagePolicy("all", "**")},
+		},
+		Verifier: v,
+	}
+	if resp := wh.Admit(context.Background(), podRequest(t, "ghcr.io/example/signed:v1")); !resp.Allowed {
+		t.Fatalf("Admit() denied: %v", resp.Result)
+	}
+	// Cluster policies name the namespace of their secrets.
+	if want := []string{"", "default"}; strings.Join(v.namespaces, ",") != strings.Join(want, ",") {
+		t.Errorf("verified in namespaces %q, want %q", v.namespaces, want)
+	}
+}
+
+func TestMutate(t *testing.T) {
+	wh := &Webhook{
+		Policies: &fakeLister{policies: []ImagePolicy{imagePolicy("example", "ghcr.io/example/*")}},
+		Verifier: &fakeVerifier{signed: map[string]bool{"ghcr.io/example/signed": true}},
+	}
+	pinned := "ghcr.io/example/signed@sha256:" + digestHex
+	tests := []struct {
+		name      string
+		images    []string
+		wantPatch string
+	}{{
+		name:      "tag",
+		images:    []string{"quay.io/example/other:v1", "ghcr.io/example/signed:v1"},
+		wantPatch: `[{"op":"replace","path":"/spec/containers/1/image","value":"` + pinned + `"}]`,
+	}, {
+		name:   "digest",
+		images: []string{pinned},
+	}, {
+		name:   "unmatched",
+		images: []string{"quay.io/example/other:v1"},
+	}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp := wh.Mutate(context.Background(), podRequest(t, tt.images...))
+			if !resp.Allowed {
+				t.Fatalf("Mutate() denied: %v", resp.Result)
+			}
+			if string(resp.Patch) != tt.wantPatch {
+				t.Errorf("Mutate() patch = %s, want %s", resp.Patch, tt.wantPatch)
+			}
+			if (resp.PatchType != nil) != (tt.wantPatch != "") {
+				t.Errorf("Mutate() patch type = %v", resp.PatchType)
+			}
+		})
+	}
+
+	resp := wh.Mutate(context.Background(), podRequest(t, "ghcr.io/example/unsigned:v1"))
+	if resp.Allowed || resp.Patch != nil {
+		t.Errorf("Mutate() = %+v, want denial without patch", resp)
+	}
+}
+
+func TestServeHTTP(t *testing.T) {
+	wh := &Webhook{
+		Policies: &fakeLister{policies: []ImagePolicy{imagePolicy("all", "**")}},
+		Verifier: &fakeVerifier{},
+	}
+	body, err := json.Marshal(admissionv1.AdmissionReview{
+		TypeMeta: metav1.TypeMeta{APIVersion: "admission.k8s.io/v1", Kind: "AdmissionReview"},
+		Request:  podRequest(t, "ghcr.io/example/unsigned:v1"),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	rec := httptest.NewRecorder()
+	wh.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/validate", bytes.NewReader(body)))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d: %s", rec.Code, rec.Body)
+	}
+
+	var review admissionv1.AdmissionReview
+	if err := json.Unmarshal(rec.Body.Bytes(), &review); err != nil {
+		t.Fatal(err)
+	}
+	if review.Response == nil || review.Response.UID != "uid" {
+		t.Fatalf("response = %+v, want UID uid", review.Response)
+	}
+	if review.Response.Allowed || !strings.Contains(review.Response.Result.Message, "no matching signatures") {
+		t.Errorf("response = %+v, want denial", review.Response)
+	}
+}
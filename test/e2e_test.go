@@ -228,7 +228,7 @@ func TestSignVerifyClean(t *testing.T) {
 	must(download.SignatureCmd(ctx, options.RegistryOptions{}, imgName), t)
 
 	// Now clean signature from the given image
-	must(cli.CleanCmd(ctx, options.RegistryOptions{}, "all", imgName, true), t)
+	must(cli.CleanCmd(ctx, options.RegistryOptions{}, "all", imgName, true, false), t)
 
 	// It doesn't work
 	mustErr(verify(pubKeyPath, imgName, true, nil, ""), t)
@@ -260,7 +260,7 @@ func TestImportSignVerifyClean(t *testing.T) {
 	must(download.SignatureCmd(ctx, options.RegistryOptions{}, imgName), t)
 
 	// Now clean signature from the given image
-	must(cli.CleanCmd(ctx, options.RegistryOptions{}, "all", imgName, true), t)
+	must(cli.CleanCmd(ctx, options.RegistryOptions{}, "all", imgName, true, false), t)
 
 	// It doesn't work
 	mustErr(verify(pubKeyPath, imgName, true, nil, ""), t)
@@ -1782,7 +1782,7 @@ func TestAttachSBOM(t *testing.T) {
 	out.Reset()
 
 	// Upload it!
-	must(attach.SBOMCmd(ctx, options.RegistryOptions{}, options.RegistryExperimentalOptions{}, "./testdata/bom-go-mod.spdx", "spdx", imgName), t)
+	must(attach.SBOMCmd(ctx, options.RegistryOptions{}, options.RegistryExperimentalOptions{}, "./testdata/bom-go-mod.spdx", "spdx", "", imgName), t)
 
 	sboms, err := download.SBOMCmd(ctx, options.RegistryOptions{}, options.SBOMDownloadOptions{}, imgName, &out)
 	if err != nil {
@@ -1800,6 +1800,17 @@ func TestAttachSBOM(t *testing.T) {
 		t.Errorf("diff: %s", diff)
 	}
 
+	// Replace it with a compressed one, downloaded decompressed.
+	must(attach.SBOMCmd(ctx, options.RegistryOptions{}, options.RegistryExperimentalOptions{}, "./testdata/bom-go-mod.spdx", "spdx", "gzip", imgName), t)
+	out.Reset()
+	sboms, err = download.SBOMCmd(ctx, options.RegistryOptions{}, options.SBOMDownloadOptions{}, imgName, &out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff(string(want), sboms[0]); diff != "" {
+		t.Errorf("diff of the compressed sbom: %s", diff)
+	}
+
 	// Generate key pairs to sign the sbom
 	td1 := t.TempDir()
 	td2 := t.TempDir()
@@ -1886,7 +1897,7 @@ func TestAttachSBOM_bom_flag(t *testing.T) {
 			out.Reset()
 
 			// Upload it!
-			err = attach.SBOMCmd(ctx, options.RegistryOptions{}, options.RegistryExperimentalOptions{}, sbomRef, "spdx", imgName)
+			err = attach.SBOMCmd(ctx, options.RegistryOptions{}, options.RegistryExperimentalOptions{}, sbomRef, "spdx", "", imgName)
 			restoreStdin()
 
 			if testCase.expectedErr {